@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/benvon/smart-todo/internal/config"
@@ -22,7 +23,7 @@ func NewListCmd() *cobra.Command {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			db, err := database.New(cfg.DatabaseURL)
+			db, err := database.NewWithTLS(cfg.DatabaseURL, cfg.DatabaseTLS)
 			if err != nil {
 				return fmt.Errorf("failed to connect to database: %w", err)
 			}
@@ -54,6 +55,15 @@ func NewListCmd() *cobra.Command {
 				if config.JWKSUrl != nil {
 					fmt.Printf("    JWKS URL: %s\n", *config.JWKSUrl)
 				}
+				if config.AuthorizationEndpoint != nil {
+					fmt.Printf("    Authorization endpoint: %s\n", *config.AuthorizationEndpoint)
+				}
+				if config.TokenEndpoint != nil {
+					fmt.Printf("    Token endpoint: %s\n", *config.TokenEndpoint)
+				}
+				if len(config.Audiences) > 0 {
+					fmt.Printf("    Extra audiences: %s\n", strings.Join(config.Audiences, ", "))
+				}
 				fmt.Println()
 			}
 
@@ -3,18 +3,23 @@ package commands
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"github.com/benvon/smart-todo/internal/config"
 	"github.com/benvon/smart-todo/internal/database"
 	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/services/oidc"
 )
 
 // NewOIDCCmd creates the OIDC configuration command
 func NewOIDCCmd() *cobra.Command {
 	var issuer, domain, clientID, clientSecret, redirectURI string
+	var audiences []string
+	var discover bool
 
 	cmd := &cobra.Command{
 		Use:   "oidc <provider-name>",
@@ -38,7 +43,7 @@ func NewOIDCCmd() *cobra.Command {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			db, err := database.New(cfg.DatabaseURL)
+			db, err := database.NewWithTLS(cfg.DatabaseURL, cfg.DatabaseTLS)
 			if err != nil {
 				return fmt.Errorf("failed to connect to database: %w", err)
 			}
@@ -55,7 +60,9 @@ func NewOIDCCmd() *cobra.Command {
 			existing, err := oidcRepo.GetByProvider(ctx, provider)
 			if err == nil && existing != nil {
 				// Update existing
-				existing.Issuer = issuer
+				if err := applyIssuerEndpoints(ctx, existing, issuer, discover); err != nil {
+					return err
+				}
 				if domain != "" {
 					existing.Domain = &domain
 				}
@@ -66,9 +73,7 @@ func NewOIDCCmd() *cobra.Command {
 					existing.ClientSecret = nil
 				}
 				existing.RedirectURI = redirectURI
-				// Try to derive JWKS URL from issuer
-				jwksURL := issuer + "/.well-known/jwks.json"
-				existing.JWKSUrl = &jwksURL
+				existing.Audiences = audiences
 
 				if err := oidcRepo.Update(ctx, existing); err != nil {
 					return fmt.Errorf("failed to update OIDC config: %w", err)
@@ -79,9 +84,12 @@ func NewOIDCCmd() *cobra.Command {
 				config := &models.OIDCConfig{
 					ID:          uuid.New(),
 					Provider:    provider,
-					Issuer:      issuer,
 					ClientID:    clientID,
 					RedirectURI: redirectURI,
+					Audiences:   audiences,
+				}
+				if err := applyIssuerEndpoints(ctx, config, issuer, discover); err != nil {
+					return err
 				}
 				if domain != "" {
 					config.Domain = &domain
@@ -89,9 +97,6 @@ func NewOIDCCmd() *cobra.Command {
 				if clientSecret != "" {
 					config.ClientSecret = &clientSecret
 				}
-				jwksURL := issuer + "/.well-known/jwks.json"
-				config.JWKSUrl = &jwksURL
-
 				if err := oidcRepo.Create(ctx, config); err != nil {
 					return fmt.Errorf("failed to create OIDC config: %w", err)
 				}
@@ -107,6 +112,30 @@ func NewOIDCCmd() *cobra.Command {
 	cmd.Flags().StringVar(&clientID, "client-id", "", "OAuth2 client ID (required)")
 	cmd.Flags().StringVar(&clientSecret, "client-secret", "", "OAuth2 client secret (optional for public clients like Cognito SPAs)")
 	cmd.Flags().StringVar(&redirectURI, "redirect-uri", "", "OAuth2 redirect URI (required)")
+	cmd.Flags().BoolVar(&discover, "discover", false, "Read the issuer, JWKS URL and authorization/token endpoints from the issuer's /.well-known/openid-configuration (e.g., for Auth0 or Google)")
+	cmd.Flags().StringSliceVar(&audiences, "audience", nil, "Token audience accepted besides the client ID (optional, repeatable, e.g., an Auth0 API identifier)")
 
 	return cmd
 }
+
+// applyIssuerEndpoints sets the issuer and JWKS URL of config. With discover they are read, along with the
+// authorization and token endpoints, from the issuer's discovery document; otherwise the JWKS URL is derived
+// from the issuer and the endpoints are left to the server's defaults.
+func applyIssuerEndpoints(ctx context.Context, config *models.OIDCConfig, issuer string, discover bool) error {
+	config.Issuer = issuer
+	config.AuthorizationEndpoint = nil
+	config.TokenEndpoint = nil
+	if !discover {
+		// Try to derive JWKS URL from issuer
+		jwksURL := issuer + "/.well-known/jwks.json"
+		config.JWKSUrl = &jwksURL
+		return nil
+	}
+	discovery, err := oidc.FetchDiscovery(ctx, &http.Client{Timeout: 10 * time.Second}, issuer)
+	if err != nil {
+		return fmt.Errorf("failed to discover OIDC endpoints: %w", err)
+	}
+	discovery.Apply(config)
+	fmt.Printf("Discovered OIDC endpoints for issuer: %s\n", discovery.Issuer)
+	return nil
+}
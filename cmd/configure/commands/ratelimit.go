@@ -32,7 +32,7 @@ func newRatelimitListCmd() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("load config: %w", err)
 			}
-			db, err := database.New(cfg.DatabaseURL)
+			db, err := database.NewWithTLS(cfg.DatabaseURL, cfg.DatabaseTLS)
 			if err != nil {
 				return fmt.Errorf("connect to database: %w", err)
 			}
@@ -68,7 +68,7 @@ func newRatelimitSetCmd() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("load config: %w", err)
 			}
-			db, err := database.New(cfg.DatabaseURL)
+			db, err := database.NewWithTLS(cfg.DatabaseURL, cfg.DatabaseTLS)
 			if err != nil {
 				return fmt.Errorf("connect to database: %w", err)
 			}
@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/benvon/smart-todo/internal/config"
 	"github.com/benvon/smart-todo/internal/database"
+	"github.com/benvon/smart-todo/internal/services/oidc"
 )
 
 // NewTestCmd creates the test command
@@ -30,7 +31,7 @@ func NewTestCmd() *cobra.Command {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			db, err := database.New(cfg.DatabaseURL)
+			db, err := database.NewWithTLS(cfg.DatabaseURL, cfg.DatabaseTLS)
 			if err != nil {
 				return fmt.Errorf("failed to connect to database: %w", err)
 			}
@@ -52,7 +53,7 @@ func NewTestCmd() *cobra.Command {
 			fmt.Printf("Issuer: %s\n", config.Issuer)
 
 			// Test issuer discovery endpoint
-			discoveryURL := config.Issuer + "/.well-known/openid-configuration"
+			discoveryURL := oidc.DiscoveryURL(config.Issuer)
 			fmt.Printf("\nTesting discovery endpoint: %s\n", discoveryURL)
 			client := &http.Client{Timeout: 10 * time.Second}
 			resp, err := client.Get(discoveryURL)
@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -17,13 +18,17 @@ import (
 	"github.com/benvon/smart-todo/internal/database"
 	"github.com/benvon/smart-todo/internal/handlers"
 	"github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/metrics"
 	"github.com/benvon/smart-todo/internal/middleware"
+	"github.com/benvon/smart-todo/internal/models"
 	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/benvon/smart-todo/internal/request"
 	"github.com/benvon/smart-todo/internal/services/ai"
 	"github.com/benvon/smart-todo/internal/services/oidc"
 	"github.com/benvon/smart-todo/internal/telemetry"
-	"github.com/google/uuid"
+	"github.com/benvon/smart-todo/internal/workers"
 	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 	"go.uber.org/zap"
 )
@@ -60,22 +65,40 @@ func main() {
 		zap.String("frontend_url", cfg.FrontendURL),
 		zap.String("ai_provider", cfg.AIProvider),
 		zap.String("ai_model", cfg.AIModel),
+		zap.Duration("ai_request_timeout", cfg.AIRequestTimeout),
 		zap.Bool("otel_enabled", cfg.OTELEnabled),
+		zap.Bool("metrics_enabled", cfg.EnableMetrics),
 	)
 
+	// Configure response timestamp format
+	timestampFormatter, err := handlers.NewTimestampFormatter(cfg.ResponseTimestampFormat, cfg.ResponseTimezone)
+	if err != nil {
+		zapLogger.Fatal("invalid_response_timestamp_config", zap.Error(err))
+	}
+	handlers.SetResponseTimestampFormatter(timestampFormatter)
+
+	// Honor forwarded client IP headers only from the configured reverse proxies
+	request.SetTrustedProxies(cfg.TrustedProxies)
+	if len(cfg.TrustedProxies) == 0 {
+		zapLogger.Info("No trusted proxies configured, ignoring X-Forwarded-For and X-Real-IP")
+	}
+
 	// Initialize OpenTelemetry if enabled
 	var tracerProvider interface{ Shutdown(context.Context) error }
 	if cfg.OTELEnabled {
 		if cfg.OTELEndpoint == "" {
 			zapLogger.Warn("otel_enabled_but_endpoint_not_configured")
 		} else {
-			tp, err := telemetry.InitTracer(context.Background(), "smart-todo-api", cfg.OTELEndpoint)
+			tp, err := telemetry.InitTracer(context.Background(), "smart-todo-api", cfg.OTELEndpoint,
+				telemetry.WithSampleRatio(cfg.OTELTraceSampleRatio),
+			)
 			if err != nil {
 				zapLogger.Warn("failed_to_initialize_otel_tracer", zap.Error(err))
 			} else {
 				tracerProvider = tp
 				zapLogger.Info("otel_tracer_initialized",
 					zap.String("endpoint", cfg.OTELEndpoint),
+					zap.Float64("sample_ratio", cfg.OTELTraceSampleRatio),
 				)
 				defer func() {
 					if tracerProvider == nil {
@@ -92,7 +115,7 @@ func main() {
 	}
 
 	// Connect to database
-	db, err := database.New(cfg.DatabaseURL)
+	db, err := database.NewWithTLS(cfg.DatabaseURL, cfg.DatabaseTLS)
 	if err != nil {
 		zapLogger.Fatal("failed_to_connect_to_database", zap.Error(err))
 	}
@@ -103,6 +126,13 @@ func main() {
 	}()
 
 	zapLogger.Info("connected_to_database")
+	db.SetSlowQueryLog(cfg.DBSlowQueryThreshold, zapLogger)
+
+	expectedSchema, err := database.ExpectedSchemaVersion()
+	if err != nil {
+		zapLogger.Fatal("failed_to_read_expected_schema_version", zap.Error(err))
+	}
+	checkSchemaVersion(db, expectedSchema, zapLogger)
 
 	// Connect to Redis for rate limiting
 	redisLimiter, err := middleware.NewRedisRateLimiter(cfg.RedisURL)
@@ -116,45 +146,14 @@ func main() {
 	}()
 	zapLogger.Info("connected_to_redis")
 
-	// Connect to RabbitMQ for job queue (required)
-	// Retry connection with exponential backoff to handle RabbitMQ startup delays
-	const maxRetries = 10
-	const initialDelay = 2 * time.Second
-	var jobQueue queue.JobQueue
-	var lastErr error
-
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		jobQueue, err = queue.NewRabbitMQQueue(cfg.RabbitMQURL)
-		if err == nil {
-			zapLogger.Info("connected_to_rabbitmq")
-			defer func() {
-				if err := jobQueue.Close(); err != nil {
-					zapLogger.Warn("failed_to_close_rabbitmq_connection", zap.Error(err))
-				}
-			}()
-			break
-		}
-
-		lastErr = err
-		delay := initialDelay * time.Duration(1<<uint(attempt)) // Exponential backoff
-		if delay > 30*time.Second {
-			delay = 30 * time.Second // Cap at 30 seconds
+	// Connect to the job queue (RabbitMQ, Redis streams with QUEUE_BACKEND=redis, or one inside this process
+	// with QUEUE_BACKEND=memory)
+	jobQueue := connectJobQueue(cfg, redisLimiter.Client(), zapLogger)
+	defer func() {
+		if err := jobQueue.Close(); err != nil {
+			zapLogger.Warn("failed_to_close_job_queue", zap.Error(err))
 		}
-		zapLogger.Warn("failed_to_connect_to_rabbitmq_retrying",
-			zap.Int("attempt", attempt+1),
-			zap.Int("max_retries", maxRetries),
-			zap.Error(err),
-			zap.Duration("retry_delay", delay),
-		)
-		time.Sleep(delay)
-	}
-
-	if err != nil {
-		zapLogger.Fatal("failed_to_connect_to_rabbitmq_after_retries",
-			zap.Int("max_retries", maxRetries),
-			zap.Error(lastErr),
-		)
-	}
+	}()
 
 	// Initialize repositories
 	todoRepo := database.NewTodoRepository(db)
@@ -168,63 +167,31 @@ func main() {
 
 	// Set up automatic tag change detection in todo repository
 	todoRepo.SetTagStatsRepo(tagStatsRepo)
-	todoRepo.SetTagChangeHandler(func(ctx context.Context, userID uuid.UUID) error {
-		zapLogger.Debug("tag_change_handler_invoked",
-			zap.String("user_id", userID.String()),
-		)
-
-		// Attempt to mark tag statistics as tainted (ensures stats will be refreshed)
-		var markTaintedErr error
-		_, err := tagStatsRepo.MarkTainted(ctx, userID)
-		if err != nil {
-			zapLogger.Warn("failed_to_mark_tag_statistics_tainted",
-				zap.String("user_id", userID.String()),
-				zap.Error(err),
-			)
-			markTaintedErr = err
-			// Continue to enqueue the job despite this error to avoid inconsistent state
-		}
-
-		// Always enqueue tag analysis job when tags change, even if MarkTainted failed
-		// The job will eventually fix the tainted state, allowing the system to self-heal
-		// Multiple jobs are fine - the analyzer will process them and re-analyze all todos
-		if jobQueue != nil {
-			tagJob := queue.NewJob(queue.JobTypeTagAnalysis, userID, nil)
-			debounceDelay := 5 * time.Second
-			notBefore := time.Now().Add(debounceDelay)
-			tagJob.NotBefore = &notBefore
-			if err := jobQueue.Enqueue(ctx, tagJob); err != nil {
-				zapLogger.Error("failed_to_enqueue_tag_analysis_job",
-					zap.String("user_id", userID.String()),
-					zap.Error(err),
-				)
-				// If both operations failed, return combined error
-				if markTaintedErr != nil {
-					return errors.Join(markTaintedErr, fmt.Errorf("failed to enqueue tag analysis job: %w", err))
-				}
-				return fmt.Errorf("failed to enqueue tag analysis job: %w", err)
-			}
-			zapLogger.Info("enqueued_tag_analysis_job",
-				zap.String("user_id", userID.String()),
-				zap.Duration("debounce_delay", debounceDelay),
-			)
-		} else {
-			zapLogger.Warn("job_queue_not_available",
-				zap.String("user_id", userID.String()),
-			)
-		}
-
-		// If only MarkTainted failed but job was enqueued successfully, ignore the error
-		// The enqueued job will eventually update statistics and fix the tainted state
-		return nil
-	})
+	tagChangeOpts := []workers.TagChangeHandlerOption{
+		workers.WithTagChangeDebounce(cfg.TagAnalysisDebounce, cfg.TagAnalysisDebounceMaxWait),
+	}
+	if cfg.TagStatsIncremental {
+		tagChangeOpts = append(tagChangeOpts, workers.WithTagChangeIncremental(
+			workers.NewIncrementalTagStats(tagStatsRepo, contextRepo, cfg.TagStatsFullAnalysisInterval, zapLogger)))
+	}
+	todoRepo.SetTagChangeHandler(workers.NewTagChangeHandler(tagStatsRepo, jobQueue, zapLogger, tagChangeOpts...).Handle)
 
 	// Initialize services
 	oidcProvider := oidc.NewProvider(oidcConfigRepo)
-	jwksManager := oidc.NewJWKSManager()
+	jwksManager := oidc.NewJWKSManager(oidc.WithJWKSCacheTTL(cfg.JWKSCacheTTL), oidc.WithJWKSMaxStale(cfg.JWKSMaxStale))
 
-	// Initialize AI provider
-	aiProvider, err := createAIProvider(cfg, zapLogger, debugMode)
+	// Initialize AI provider, recording the token usage and estimated cost of its calls
+	aiPrices, err := models.ParseAIPriceTable(cfg.AIPriceTable)
+	if err != nil {
+		zapLogger.Fatal("invalid_ai_price_table", zap.Error(err))
+	}
+	aiUsageRepo := database.NewAIUsageRepository(db)
+	aiUsage := ai.NewUsageTracker(aiUsageRepo, aiPrices, zapLogger)
+	textNormalization, err := ai.ParseTextNormalization(cfg.AITextNormalization)
+	if err != nil {
+		zapLogger.Fatal("invalid_ai_text_normalization", zap.Error(err))
+	}
+	aiProvider, err := createAIProvider(cfg, aiUsage, textNormalization, zapLogger, debugMode)
 	if err != nil {
 		zapLogger.Warn("failed_to_create_ai_provider_ai_features_disabled", zap.Error(err))
 		aiProvider = nil
@@ -236,12 +203,49 @@ func main() {
 	if aiProvider != nil {
 		chatService = ai.NewChatService(aiProvider)
 		contextService = ai.NewContextService(aiProvider, contextRepo)
+		contextService.SetMaxSummaryLength(cfg.AIContextMaxSummaryLength)
 	}
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(oidcProvider, cfg.OIDCProvider)
-	todoHandler := handlers.NewTodoHandler(todoRepo, zapLogger, handlers.WithTodoTagStatsRepo(tagStatsRepo), handlers.WithTodoJobQueue(jobQueue))
-	healthChecker := handlers.NewHealthCheckerWithDeps(db, redisLimiter, jobQueue)
+	authHandler := handlers.NewAuthHandler(oidcProvider, cfg.OIDCProvider, zapLogger)
+	todoHandlerOpts := []handlers.TodoHandlerOption{
+		handlers.WithTodoTagStatsRepo(tagStatsRepo),
+		handlers.WithTodoHistoryRepo(database.NewTodoHistoryRepository(db)),
+		handlers.WithTodoIdempotencyRepo(database.NewIdempotencyRepository(db)),
+		handlers.WithTodoJobQueue(jobQueue),
+		handlers.WithTodoReanalyzeOnTextChange(cfg.ReanalyzeOnTextChange),
+		handlers.WithTodoStrictJSON(cfg.StrictJSONDecoding),
+		handlers.WithTodoFocusWeights(models.FocusWeights{
+			Overdue:      cfg.FocusWeightOverdue,
+			DueToday:     cfg.FocusWeightDueToday,
+			NextHorizon:  cfg.FocusWeightNextHorizon,
+			HighPriority: cfg.FocusWeightHighPriority,
+		}),
+	}
+	if cfg.AIMinManualAnalysisInterval > 0 {
+		manualLimiter := workers.NewRedisAnalysisThrottle(redisLimiter.Client(), workers.ManualAnalysisThrottlePrefix, cfg.AIMinManualAnalysisInterval)
+		todoHandlerOpts = append(todoHandlerOpts, handlers.WithTodoManualAnalysisLimiter(manualLimiter))
+	}
+	analysisPause := workers.NewRedisAnalysisPause(redisLimiter.Client())
+	backpressure := newQueueBackpressure(cfg, jobQueue)
+	if backpressure != nil {
+		todoHandlerOpts = append(todoHandlerOpts, handlers.WithTodoBackpressure(backpressure))
+	}
+	todoHandler := handlers.NewTodoHandler(todoRepo, zapLogger, todoHandlerOpts...)
+	healthOpts := []handlers.HealthCheckerOption{
+		handlers.WithHealthAdminToken(cfg.AdminAPIToken),
+		handlers.WithDependencyInfo("database", db),
+		handlers.WithDependencyInfo("redis", redisLimiter),
+		handlers.WithSchemaCheck(db, expectedSchema),
+		handlers.WithAnalysisPause(analysisPause),
+	}
+	if backpressure != nil {
+		healthOpts = append(healthOpts, handlers.WithQueueBackpressure(backpressure))
+	}
+	if infoProvider, ok := jobQueue.(handlers.DependencyInfoProvider); ok {
+		healthOpts = append(healthOpts, handlers.WithDependencyInfo("rabbitmq", infoProvider))
+	}
+	healthChecker := handlers.NewHealthCheckerWithDeps(db, redisLimiter, jobQueue, healthOpts...)
 
 	var chatHandler *handlers.ChatHandler
 	if chatService != nil && contextService != nil {
@@ -251,49 +255,93 @@ func main() {
 	// Setup router
 	r := mux.NewRouter()
 
-	// Apply middleware (order matters - executed in reverse order of registration)
-	// Note: In gorilla/mux, middleware executes in reverse order of registration
-	// Middleware registered LAST executes FIRST (outermost wrapper)
+	// Global middleware chain. Every available middleware is registered by name and the chain is assembled
+	// in the configured order (MIDDLEWARE_CHAIN, outermost first; default middleware.DefaultChainOrder).
+	// Required middlewares cannot be left out; an invalid chain stops startup.
 	zapLogger.Info("setting_up_middleware")
-
-	// Outermost middleware (executes first):
-	// 0. OpenTelemetry tracing (if enabled)
-	if cfg.OTELEnabled && tracerProvider != nil {
-		r.Use(otelmux.Middleware("smart-todo-api"))
-		zapLogger.Info("otel_middleware_enabled")
-	}
-	// 1. Security headers (should be set on all responses)
-	r.Use(middleware.SecurityHeaders(cfg.EnableHSTS))
-	// 2. CORS (load from DB, hot-reload; fallback to FRONTEND_URL)
-	corsReloader := middleware.NewCORSReloader(corsConfigRepo, cfg.FrontendURL, zapLogger, 1*time.Minute)
-	r.Use(corsReloader.Middleware())
+	corsReloader := middleware.NewCORSReloader(corsConfigRepo, cfg.FrontendURL, zapLogger, cfg.CORSReloadInterval)
 	// Rate limit middleware (applied selectively to specific routes, not globally)
-	rateLimitReloader := middleware.NewRateLimitReloader(redisLimiter.Client(), ratelimitConfigRepo, "5-S", zapLogger, 1*time.Minute)
+	rateLimitReloader := middleware.NewRateLimitReloader(redisLimiter.Client(), ratelimitConfigRepo, "5-S", zapLogger, cfg.RateLimitReloadInterval)
 	if rateLimitReloader == nil {
 		zapLogger.Fatal("failed_to_create_rate_limit_reloader")
 	}
 	rateLimitMW := rateLimitReloader.Middleware()
-	// 3. Request size limits (protects against DoS)
-	r.Use(middleware.MaxRequestSize(middleware.DefaultMaxRequestSize))
-	// 4. Content-Type validation for POST/PATCH/PUT requests
-	r.Use(middleware.ContentType)
-	// 5. Request timeout (30 seconds default)
-	r.Use(middleware.Timeout(30 * time.Second))
-	// 6. Error handler (catches panics)
-	r.Use(middleware.ErrorHandler(zapLogger))
-	// 7. Audit logging (for security events)
-	r.Use(middleware.Audit(zapLogger))
-	// 8. Logging (innermost, executes last before handler)
-	r.Use(middleware.Logging(zapLogger))
-	// 9. Activity tracking (for authenticated requests)
-	r.Use(middleware.ActivityTracking(activityRepo, zapLogger))
+
+	chainRegistry := middleware.NewChainRegistry()
+	// OpenTelemetry tracing (unavailable unless OTEL is enabled)
+	var otelMW func(http.Handler) http.Handler
+	if cfg.OTELEnabled && tracerProvider != nil {
+		otelMW = otelmux.Middleware("smart-todo-api")
+	}
+	chainRegistry.Register(middleware.ChainOTEL, otelMW)
+	// Prometheus request timings (unavailable unless ENABLE_METRICS is set)
+	var metricsMW func(http.Handler) http.Handler
+	if cfg.EnableMetrics {
+		metricsMW = metrics.Middleware(metrics.HTTPRequestDuration)
+	}
+	chainRegistry.Register(middleware.ChainMetrics, metricsMW)
+	// Security headers (should be set on all responses)
+	chainRegistry.RegisterRequired(middleware.ChainSecurityHeaders, middleware.SecurityHeaders(cfg.EnableHSTS))
+	// CORS (load from DB, hot-reload; fallback to FRONTEND_URL)
+	chainRegistry.RegisterRequired(middleware.ChainCORS, corsReloader.Middleware())
+	// Per-client concurrency cap (bounds long-lived requests; health and metrics are exempt)
+	concurrencyLimiter := middleware.NewConcurrencyLimiter(cfg.MaxConcurrentRequestsPerClient, zapLogger, middleware.DefaultConcurrencyExemptPaths...)
+	chainRegistry.Register(middleware.ChainConcurrency, concurrencyLimiter.Middleware())
+	// Request size limits (protects against DoS)
+	chainRegistry.RegisterRequired(middleware.ChainRequestSize, middleware.MaxRequestSize(middleware.DefaultMaxRequestSize))
+	// Content-Type validation for POST/PATCH/PUT requests (JSON, plus markdown for the checklist import)
+	chainRegistry.RegisterRequired(middleware.ChainContentType, middleware.ContentTypeAllowing(map[string][]string{
+		"/api/v1" + handlers.MarkdownImportPath: handlers.MarkdownImportContentTypes,
+	}))
+	// Request timeouts (30 seconds default): tight for health checks, longer for AI chat and the export. The
+	// export and the chat event stream are streamed under their deadline instead of being buffered.
+	requestTimeouts := []middleware.RouteTimeout{
+		{Path: "/healthz", Timeout: 10 * time.Second},
+		{Path: "/readyz", Timeout: 10 * time.Second},
+		{Path: "/health", Timeout: 10 * time.Second},
+		{Path: "/api/v1" + handlers.TodoExportPath, Timeout: 5 * time.Minute, Streaming: true},
+		{Path: "/api/v1/ai/chat", Method: http.MethodGet, Timeout: 10 * time.Minute, Streaming: true},
+		{Path: "/api/v1/ai/chat/message", Timeout: 2 * time.Minute},
+	}
+	chainRegistry.RegisterRequired(middleware.ChainTimeout, middleware.Timeout(middleware.DefaultRequestTimeout, zapLogger, requestTimeouts...))
+	// Error handler (catches panics)
+	chainRegistry.RegisterRequired(middleware.ChainErrorHandler, middleware.ErrorHandler(zapLogger))
+	// Audit logging (for security events, persisted for compliance reviews); events are written in the
+	// background so rejected requests do not wait on the database
+	auditRepo := database.NewAuditRepository(db)
+	auditWriter := middleware.NewAuditWriter(auditRepo, middleware.DefaultAuditBufferSize, zapLogger)
+	chainRegistry.Register(middleware.ChainAudit, middleware.Audit(zapLogger, middleware.WithAuditRecorder(auditWriter)))
+	// Request logging
+	chainRegistry.Register(middleware.ChainLogging, middleware.Logging(zapLogger))
+	// Debug body capture (debug mode only, per request via X-Debug-Capture plus admin token)
+	chainRegistry.Register(middleware.ChainBodyCapture, middleware.BodyCapture(debugMode, cfg.AdminAPIToken, middleware.DefaultMaxCaptureBytes, zapLogger))
+	// Activity tracking (for authenticated requests)
+	chainRegistry.Register(middleware.ChainActivity, middleware.ActivityTracking(activityRepo, zapLogger))
+
+	chainOrder := cfg.MiddlewareChain
+	if len(chainOrder) == 0 {
+		chainOrder = middleware.DefaultChainOrder
+	}
+	chain, err := chainRegistry.Build(chainOrder)
+	if err != nil {
+		zapLogger.Fatal("invalid_middleware_chain", zap.Error(err))
+	}
+	for _, mw := range chain.Middlewares() {
+		r.Use(mw)
+	}
+	zapLogger.Info("middleware_chain_applied", zap.Strings("middlewares", chain.Names()))
 
 	log.Println("Middleware setup complete")
 
 	// Public routes (no rate limiting for health checks)
 	r.HandleFunc("/healthz", healthChecker.HealthCheck).Methods("GET")
+	r.HandleFunc("/readyz", healthChecker.ReadinessCheck).Methods("GET")
 	r.HandleFunc("/health", healthCheck).Methods("GET") // Legacy endpoint
 	r.HandleFunc("/version", versionInfo).Methods("GET")
+	if cfg.EnableMetrics {
+		queue.RegisterDLQDepthGauge(metrics.Default, jobQueue)
+		r.Handle("/metrics", metrics.Default.Handler()).Methods("GET")
+	}
 
 	// OpenAPI spec (public)
 	openAPIPath := filepath.Join("api", "openapi", "openapi.yaml")
@@ -310,48 +358,120 @@ func main() {
 	loginRouter := authRouter.PathPrefix("/oidc").Subrouter()
 	loginRouter.Use(rateLimitMW)
 	loginRouter.HandleFunc("/login", authHandler.GetOIDCLogin).Methods("GET")
+	refreshRouter := authRouter.PathPrefix("/refresh").Subrouter()
+	refreshRouter.Use(rateLimitMW)
+	refreshRouter.HandleFunc("", authHandler.RefreshToken).Methods("POST")
+
+	// User authentication (JWT or API key) for protected routes, setting the user's role on each login
+	authMW := middleware.Auth(db, oidcProvider, jwksManager, cfg.OIDCProvider, zapLogger,
+		middleware.WithAdminEmails(cfg.AdminEmails),
+		middleware.WithRolesClaim(cfg.OIDCRolesClaim),
+	)
+	// Operator routes accept the admin token or an authenticated admin user
+	adminMW := middleware.RequireAdminTokenOr(cfg.AdminAPIToken, authMW, middleware.RequireRole(models.RoleAdmin, zapLogger))
 
 	// Protected auth routes
 	protectedAuthRouter := authRouter.PathPrefix("").Subrouter()
-	protectedAuthRouter.Use(middleware.Auth(db, oidcProvider, jwksManager, cfg.OIDCProvider, zapLogger))
+	protectedAuthRouter.Use(authMW)
 	protectedAuthRouter.Use(rateLimitMW)
 	protectedAuthRouter.HandleFunc("/me", authHandler.GetMe).Methods("GET")
+	handlers.NewAPIKeyHandler(database.NewAPIKeyRepository(db), zapLogger).RegisterRoutes(protectedAuthRouter)
 
 	// Todo routes (protected)
 	todosRouter := apiRouter.PathPrefix("/todos").Subrouter()
-	todosRouter.Use(middleware.Auth(db, oidcProvider, jwksManager, cfg.OIDCProvider, zapLogger))
+	todosRouter.Use(authMW)
 	todosRouter.Use(rateLimitMW)
 	todoHandler.RegisterRoutes(todosRouter)
 
 	// AI routes (protected)
 	aiRouter := apiRouter.PathPrefix("/ai").Subrouter()
-	aiRouter.Use(middleware.Auth(db, oidcProvider, jwksManager, cfg.OIDCProvider, zapLogger))
+	aiRouter.Use(authMW)
 	aiRouter.Use(rateLimitMW)
 
 	// AI Context routes
-	aiContextHandler := handlers.NewAIContextHandler(contextRepo)
+	aiContextOpts := []handlers.AIContextHandlerOption{
+		handlers.WithAIContextMaxSummaryLength(cfg.AIContextMaxSummaryLength),
+		handlers.WithAIContextStrictJSON(cfg.StrictJSONDecoding),
+	}
+	if contextService != nil {
+		aiContextOpts = append(aiContextOpts, handlers.WithAIContextSummarizer(contextService, zapLogger))
+	}
+	aiContextHandler := handlers.NewAIContextHandler(contextRepo, aiContextOpts...)
 	contextRouter := aiRouter.PathPrefix("/context").Subrouter()
 	aiContextHandler.RegisterRoutes(contextRouter)
 
-	// Chat routes (if AI provider available)
+	// AI queue status (pending/processing analysis counts)
+	aiQueueStatusHandler := handlers.NewAIQueueStatusHandler(todoRepo, zapLogger)
+	aiQueueStatusHandler.RegisterRoutes(aiRouter)
+
+	// AI batch status (analysis progress across all todos, with queue depth and estimated completion)
+	queueStats, _ := jobQueue.(queue.StatsReporter)
+	aiBatchStatusHandler := handlers.NewAIBatchStatusHandler(todoRepo, queueStats, zapLogger)
+	aiBatchStatusHandler.RegisterRoutes(aiRouter)
+
+	// Latest weekly summary generated by the worker
+	aiWeeklySummaryHandler := handlers.NewAIWeeklySummaryHandler(database.NewWeeklySummaryRepository(db), zapLogger,
+		handlers.WithWeeklySummaryEnabled(cfg.WeeklySummaryEnabled),
+	)
+	aiWeeklySummaryHandler.RegisterRoutes(aiRouter)
+
+	// Full AI reset (AI tags, tag statistics and AI context), followed by reprocessing
+	aiResetHandler := handlers.NewAIResetHandler(todoRepo, jobQueue, zapLogger)
+	aiResetHandler.RegisterRoutes(aiRouter)
+
+	// Tag alias routes (alias -> canonical tag, applied during AI analysis)
+	tagAliasHandler := handlers.NewTagAliasHandler(contextRepo, zapLogger)
+	tagAliasHandler.RegisterRoutes(aiRouter)
+
+	// Tag weight routes (tag -> priority multiplier for the analysis prompt)
+	tagWeightHandler := handlers.NewTagWeightHandler(contextRepo, zapLogger)
+	tagWeightHandler.RegisterRoutes(aiRouter)
+
+	// Chat routes (without an AI provider they answer 403 Feature Disabled)
 	if chatHandler != nil {
 		chatHandler.RegisterRoutes(aiRouter)
+	} else {
+		handlers.RegisterDisabledChatRoutes(aiRouter)
 	}
 
-	// Catch-all OPTIONS handler for preflight requests
-	// This ensures OPTIONS requests are handled even if routes don't explicitly allow them
+	// Audit log (operators only: admin token or admin role)
+	auditRouter := apiRouter.PathPrefix("/audit").Subrouter()
+	auditRouter.Use(adminMW)
+	handlers.NewAuditHandler(auditRepo, zapLogger).RegisterRoutes(auditRouter)
+
+	// API v2 routes: same handlers and services as v1, differing only in request/response shaping
+	apiV2Router := r.PathPrefix("/api/v2").Subrouter()
+	todosV2Router := apiV2Router.PathPrefix("/todos").Subrouter()
+	todosV2Router.Use(authMW)
+	todosV2Router.Use(rateLimitMW)
+	handlers.NewTodoV2Handler(todoHandler).RegisterRoutes(todosV2Router)
+
+	// Admin routes (X-Admin-Token, or a user with the admin role)
+	adminHandler := handlers.NewAdminHandler(todoRepo, zapLogger,
+		handlers.WithAdminDebug(debugMode),
+		handlers.WithAdminAnalysis(aiProvider, contextRepo, tagStatsRepo),
+		handlers.WithAdminAICost(aiUsageRepo, aiPrices),
+		handlers.WithAdminAnalysisPause(analysisPause),
+		handlers.WithAdminTagRecompute(workers.NewTagRecomputer(database.NewUserRepository(db), jobQueue, cfg.TagRecomputePacing, zapLogger)),
+		handlers.WithAdminConfigReloader("cors", corsReloader),
+		handlers.WithAdminConfigReloader("rate_limit", rateLimitReloader),
+	)
+	adminRouter := r.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(adminMW)
+	adminHandler.RegisterRoutes(adminRouter)
+
+	// Catch-all OPTIONS handler for preflight requests to registered paths; other paths get 404.
 	// The CORS middleware will handle setting headers before this is called
-	r.Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// CORS middleware should have already set headers, just return 204
-		w.WriteHeader(http.StatusNoContent)
-	})
+	handlers.RegisterPreflightRoute(r)
 
-	// Setup server
+	// Setup server (the in-flight counter wraps the whole router so shutdown can report undrained requests; the
+	// write timeout leaves the longest route timeout room to answer, and the timeout middleware tightens it per request)
+	inFlight := middleware.NewInFlight()
 	srv := &http.Server{
 		Addr:           ":" + cfg.ServerPort,
-		Handler:        r,
+		Handler:        inFlight.Middleware(r),
 		ReadTimeout:    15 * time.Second,
-		WriteTimeout:   15 * time.Second,
+		WriteTimeout:   middleware.WriteTimeoutFor(middleware.DefaultRequestTimeout, requestTimeouts...),
 		IdleTimeout:    60 * time.Second,
 		MaxHeaderBytes: 1 << 20, // 1MB max header size
 	}
@@ -361,6 +481,7 @@ func main() {
 	defer reloadCancel()
 	go corsReloader.Start(reloadCtx)
 	go rateLimitReloader.Start(reloadCtx)
+	go jwksManager.Start(reloadCtx)
 
 	// Start DLQ garbage collector if the queue implementation supports it
 	// Run every hour, retain messages for 24 hours
@@ -377,6 +498,14 @@ func main() {
 		)
 	}
 
+	// Audit event writer; stopped after the server has drained so the last events are written
+	auditCtx, auditCancel := context.WithCancel(context.Background())
+	auditDone := make(chan struct{})
+	go func() {
+		defer close(auditDone)
+		auditWriter.Run(auditCtx)
+	}()
+
 	// Start server in a goroutine
 	go func() {
 		zapLogger.Info("server_starting",
@@ -399,53 +528,187 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := inFlight.Shutdown(ctx, srv, zapLogger); err != nil {
 		zapLogger.Fatal("server_forced_to_shutdown", zap.Error(err))
 	}
+	auditCancel()
+	<-auditDone
 
 	zapLogger.Info("server_exited")
 }
 
 // createAIProvider creates an AI provider based on configuration
-func createAIProvider(cfg *config.Config, logger *zap.Logger, debugMode bool) (ai.AIProvider, error) {
-	if cfg.OpenAIKey == "" {
-		return nil, fmt.Errorf("OpenAI API key not configured")
-	}
-
+func createAIProvider(cfg *config.Config, usage ai.UsageRecorder, normalization ai.TextNormalization, logger *zap.Logger, debugMode bool) (ai.AIProvider, error) {
 	// Get provider type
 	providerType := cfg.AIProvider
 	if providerType == "" {
 		providerType = "openai"
 	}
 
+	apiKey := cfg.AIAPIKey(providerType)
+	if apiKey == "" && (providerType == "openai" || providerType == "anthropic") {
+		return nil, fmt.Errorf("%s API key not configured", providerType)
+	}
+
 	// Create provider directly with logger support
-	if providerType == "openai" {
-		return ai.NewOpenAIProviderWithLogger(
-			cfg.OpenAIKey,
+	switch providerType {
+	case "openai":
+		provider := ai.NewOpenAIProviderWithLogger(
+			apiKey,
 			cfg.AIBaseURL,
 			cfg.AIModel,
 			logger,
 			debugMode,
-		), nil
+			cfg.AIRequestTimeout,
+		)
+		tokenizer, err := ai.NewTokenizer(cfg.AITokenizer, cfg.AIModel, logger)
+		if err != nil {
+			return nil, err
+		}
+		provider.SetTokenizer(tokenizer)
+		provider.SetOutputLanguage(cfg.AIOutputLanguage)
+		provider.SetHTTPRetries(cfg.AIMaxHTTPRetries)
+		provider.SetUsageRecorder(usage)
+		provider.SetTextNormalization(normalization)
+		return provider, nil
+	case "anthropic":
+		provider := ai.NewAnthropicProviderWithLogger(
+			apiKey,
+			cfg.AIBaseURL,
+			cfg.AIModel,
+			logger,
+			debugMode,
+			cfg.AIRequestTimeout,
+		)
+		tokenizer, err := ai.NewTokenizer(cfg.AITokenizer, cfg.AIModel, logger)
+		if err != nil {
+			return nil, err
+		}
+		provider.SetTokenizer(tokenizer)
+		provider.SetOutputLanguage(cfg.AIOutputLanguage)
+		provider.SetHTTPRetries(cfg.AIMaxHTTPRetries)
+		provider.SetUsageRecorder(usage)
+		provider.SetTextNormalization(normalization)
+		return provider, nil
+	case "ollama":
+		// A local Ollama server needs no API key
+		provider := ai.NewOllamaProviderWithLogger(cfg.AIBaseURL, cfg.AIModel, logger, debugMode, cfg.AIRequestTimeout)
+		tokenizer, err := ai.NewTokenizer(cfg.AITokenizer, cfg.AIModel, logger)
+		if err != nil {
+			return nil, err
+		}
+		provider.SetTokenizer(tokenizer)
+		provider.SetOutputLanguage(cfg.AIOutputLanguage)
+		provider.SetHTTPRetries(cfg.AIMaxHTTPRetries)
+		provider.SetUsageRecorder(usage)
+		provider.SetTextNormalization(normalization)
+		return provider, nil
 	}
 
 	// Fallback to registry for other providers (without logger)
 	registry := ai.NewProviderRegistry()
 	ai.RegisterOpenAI(registry)
+	ai.RegisterAnthropic(registry)
+	ai.RegisterOllama(registry)
 
 	config := map[string]string{
-		"api_key":  cfg.OpenAIKey,
-		"model":    cfg.AIModel,
-		"base_url": cfg.AIBaseURL,
+		"api_key":         apiKey,
+		"model":           cfg.AIModel,
+		"base_url":        cfg.AIBaseURL,
+		"tokenizer":       cfg.AITokenizer,
+		"output_language": cfg.AIOutputLanguage,
+		"http_retries":    strconv.Itoa(cfg.AIMaxHTTPRetries),
 	}
 
 	return registry.GetProvider(providerType, config)
 }
 
+// checkSchemaVersion logs an error at startup when the database has not been migrated to the version this
+// binary expects. The server keeps running but /readyz fails until migrations are applied.
+func checkSchemaVersion(db *database.DB, expected uint, logger *zap.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := database.CheckSchemaVersion(ctx, db, expected); err != nil {
+		logger.Error("database_schema_check_failed",
+			zap.Uint("expected_version", expected),
+			zap.Error(err),
+		)
+		return
+	}
+	logger.Info("database_schema_current", zap.Uint("expected_version", expected))
+}
+
+// newQueueBackpressure returns the job queue backpressure policy, or nil when QUEUE_HIGH_WATERMARK is unset
+// or the queue cannot report its depth. The settings have been validated by config.Load.
+func newQueueBackpressure(cfg *config.Config, jobQueue queue.JobQueue) *queue.Backpressure {
+	stats, ok := jobQueue.(queue.StatsReporter)
+	if cfg.QueueHighWatermark == 0 || !ok {
+		return nil
+	}
+	mode := queue.BackpressureMode(cfg.QueueBackpressureMode)
+	return queue.NewBackpressure(stats, cfg.QueueHighWatermark, mode, cfg.QueueBackpressureDelay)
+}
+
+// connectJobQueue opens the configured job queue. The Redis queue shares redisClient with rate limiting. The
+// connection to RabbitMQ is retried with exponential backoff to handle RabbitMQ startup delays.
+func connectJobQueue(cfg *config.Config, redisClient *redis.Client, zapLogger *zap.Logger) queue.JobQueue {
+	switch cfg.QueueBackend {
+	case "redis":
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		redisQueue, err := queue.NewRedisQueue(ctx, redisClient)
+		if err != nil {
+			zapLogger.Fatal("failed_to_open_redis_job_queue", zap.Error(err))
+		}
+		redisQueue.SetLogger(zapLogger)
+		zapLogger.Info("using_redis_job_queue")
+		return redisQueue
+	case "memory":
+		// No worker shares this process's memory, so enqueued jobs wait until the server stops
+		zapLogger.Warn("using_in_memory_job_queue",
+			zap.String("detail", "jobs are kept in this process only and are not processed by workers"),
+		)
+		memoryQueue := queue.NewInMemoryQueue()
+		memoryQueue.SetLogger(zapLogger)
+		return memoryQueue
+	}
+
+	const maxRetries = 10
+	const initialDelay = 2 * time.Second
+	var lastErr error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		jobQueue, err := queue.NewRabbitMQQueueWithTLS(cfg.RabbitMQURL, cfg.RabbitMQTLS)
+		if err == nil {
+			zapLogger.Info("connected_to_rabbitmq")
+			return jobQueue
+		}
+
+		lastErr = err
+		delay := initialDelay * time.Duration(1<<uint(attempt)) // Exponential backoff
+		if delay > 30*time.Second {
+			delay = 30 * time.Second // Cap at 30 seconds
+		}
+		zapLogger.Warn("failed_to_connect_to_rabbitmq_retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Int("max_retries", maxRetries),
+			zap.Error(err),
+			zap.Duration("retry_delay", delay),
+		)
+		time.Sleep(delay)
+	}
+
+	zapLogger.Fatal("failed_to_connect_to_rabbitmq_after_retries",
+		zap.Int("max_retries", maxRetries),
+		zap.Error(lastErr),
+	)
+	return nil
+}
+
 func healthCheck(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if _, err := fmt.Fprintf(w, `{"status":"healthy","timestamp":"%s"}`, time.Now().UTC().Format(time.RFC3339)); err != nil {
+	if err := json.NewEncoder(w).Encode(map[string]any{"status": "healthy", "timestamp": handlers.ResponseTimestamp()}); err != nil {
 		// Use standard log here since we don't have logger in this context
 		// This is a fallback for a simple health check endpoint
 		_ = err
@@ -456,7 +719,7 @@ func versionInfo(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	// Only expose minimal version info (sanitized for security)
-	if _, err := fmt.Fprintf(w, `{"version":"1.0.0","timestamp":"%s"}`, time.Now().UTC().Format(time.RFC3339)); err != nil {
+	if err := json.NewEncoder(w).Encode(map[string]any{"version": "1.0.0", "timestamp": handlers.ResponseTimestamp()}); err != nil {
 		// Use standard log here since we don't have logger in this context
 		// This is a fallback for a simple version endpoint
 		_ = err
@@ -3,18 +3,26 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/benvon/smart-todo/internal/config"
 	"github.com/benvon/smart-todo/internal/database"
 	"github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/metrics"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/notify"
 	"github.com/benvon/smart-todo/internal/queue"
 	"github.com/benvon/smart-todo/internal/services/ai"
+	"github.com/benvon/smart-todo/internal/supervisor"
 	"github.com/benvon/smart-todo/internal/workers"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
@@ -51,7 +59,7 @@ func main() {
 	)
 
 	// Initialize database connection
-	db, err := database.New(cfg.DatabaseURL)
+	db, err := database.NewWithTLS(cfg.DatabaseURL, cfg.DatabaseTLS)
 	if err != nil {
 		zapLogger.Fatal("Failed to connect to database", zap.Error(err))
 	}
@@ -62,48 +70,158 @@ func main() {
 	}()
 
 	zapLogger.Info("Connected to database")
+	db.SetSlowQueryLog(cfg.DBSlowQueryThreshold, zapLogger)
+	checkSchemaVersion(db, zapLogger)
 
 	// Initialize repositories
 	todoRepo := database.NewTodoRepository(db)
 	contextRepo := database.NewAIContextRepository(db)
-	activityRepo := database.NewUserActivityRepository(db)
+	activityRepo := database.NewUserActivityRepository(db, database.WithReprocessingWindow(database.ReprocessingWindow{
+		MinIdle: cfg.ReprocessingMinIdle,
+		MaxIdle: cfg.ReprocessingMaxIdle,
+	}))
 	tagStatsRepo := database.NewTagStatisticsRepository(db)
 
-	// Initialize RabbitMQ queue
-	jobQueue, err := queue.NewRabbitMQQueue(cfg.RabbitMQURL)
+	// Connect to Redis for the AI analysis kill switch, the per-user limits that coordinate across workers
+	// and, with QUEUE_BACKEND=redis, the job queue
+	redisClient, err := connectRedis(cfg.RedisURL)
 	if err != nil {
-		zapLogger.Fatal("Failed to connect to RabbitMQ", zap.Error(err))
+		zapLogger.Warn("Redis unavailable, AI analysis pause and per-user analysis limits disabled", zap.Error(err))
+		redisClient = nil
+	} else {
+		defer func() {
+			if err := redisClient.Close(); err != nil {
+				zapLogger.Warn("Failed to close Redis connection", zap.Error(err))
+			}
+		}()
+	}
+
+	// Check the retries and backoff of each job type before connecting to anything else
+	retryPolicies, err := queue.ParseRetryPolicies(cfg.JobRetryPolicies)
+	if err != nil {
+		zapLogger.Fatal("Invalid job retry policies", zap.Error(err))
+	}
+
+	// Initialize the job queue
+	jobQueue, err := openJobQueue(cfg, redisClient, zapLogger)
+	if err != nil {
+		zapLogger.Fatal("Failed to open job queue", zap.String("backend", cfg.QueueBackend), zap.Error(err))
 	}
 	defer func() {
 		if err := jobQueue.Close(); err != nil {
-			zapLogger.Warn("Failed to close RabbitMQ connection", zap.Error(err))
+			zapLogger.Warn("Failed to close job queue", zap.Error(err))
 		}
 	}()
 
-	zapLogger.Info("Connected to RabbitMQ",
-		zap.Int("prefetch", cfg.RabbitMQPrefetch),
-	)
+	// Keep tag statistics current when AI analysis changes a todo's tags, as the server does for user edits
+	todoRepo.SetTagStatsRepo(tagStatsRepo)
+	tagChangeOpts := []workers.TagChangeHandlerOption{
+		workers.WithTagChangeDebounce(cfg.TagAnalysisDebounce, cfg.TagAnalysisDebounceMaxWait),
+	}
+	if cfg.TagStatsIncremental {
+		tagChangeOpts = append(tagChangeOpts, workers.WithTagChangeIncremental(
+			workers.NewIncrementalTagStats(tagStatsRepo, contextRepo, cfg.TagStatsFullAnalysisInterval, zapLogger)))
+	}
+	todoRepo.SetTagChangeHandler(workers.NewTagChangeHandler(tagStatsRepo, jobQueue, zapLogger, tagChangeOpts...).Handle)
 
-	// Create AI provider with logger
-	var aiProvider ai.AIProvider
-	if cfg.AIProvider == "openai" {
-		aiProvider = ai.NewOpenAIProviderWithLogger(
-			cfg.OpenAIKey,
-			cfg.AIBaseURL,
-			cfg.AIModel,
-			zapLogger,
-			debugMode,
-		)
-	} else {
-		zapLogger.Fatal("Unsupported AI provider", zap.String("provider", cfg.AIProvider))
+	// Create AI provider with logger, recording the token usage and estimated cost of its calls; users may
+	// select another allowed model via their AI context
+	aiPrices, err := models.ParseAIPriceTable(cfg.AIPriceTable)
+	if err != nil {
+		zapLogger.Fatal("Invalid AI price table", zap.Error(err))
 	}
+	aiUsage := ai.NewUsageTracker(database.NewAIUsageRepository(db), aiPrices, zapLogger)
+	textNormalization, err := ai.ParseTextNormalization(cfg.AITextNormalization)
+	if err != nil {
+		zapLogger.Fatal("Invalid AI text normalization", zap.Error(err))
+	}
+	buildProvider := func(provider, model string) (ai.AIProvider, error) {
+		apiKey := cfg.AIAPIKey(provider)
+		tokenizer, err := ai.NewTokenizer(cfg.AITokenizer, model, zapLogger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tokenizer: %w", err)
+		}
+		switch provider {
+		case "openai":
+			openAIProvider := ai.NewOpenAIProviderWithLogger(
+				apiKey,
+				cfg.AIBaseURL,
+				model,
+				zapLogger,
+				debugMode,
+				cfg.AIRequestTimeout,
+			)
+			openAIProvider.SetTokenizer(tokenizer)
+			openAIProvider.SetOutputLanguage(cfg.AIOutputLanguage)
+			openAIProvider.SetHTTPRetries(cfg.AIMaxHTTPRetries)
+			openAIProvider.SetUsageRecorder(aiUsage)
+			openAIProvider.SetTextNormalization(textNormalization)
+			return openAIProvider, nil
+		case "anthropic":
+			if apiKey == "" {
+				return nil, fmt.Errorf("anthropic API key not configured")
+			}
+			anthropicProvider := ai.NewAnthropicProviderWithLogger(
+				apiKey,
+				cfg.AIBaseURL,
+				model,
+				zapLogger,
+				debugMode,
+				cfg.AIRequestTimeout,
+			)
+			anthropicProvider.SetTokenizer(tokenizer)
+			anthropicProvider.SetOutputLanguage(cfg.AIOutputLanguage)
+			anthropicProvider.SetHTTPRetries(cfg.AIMaxHTTPRetries)
+			anthropicProvider.SetUsageRecorder(aiUsage)
+			anthropicProvider.SetTextNormalization(textNormalization)
+			return anthropicProvider, nil
+		case "ollama":
+			ollamaProvider := ai.NewOllamaProviderWithLogger(cfg.AIBaseURL, model, zapLogger, debugMode, cfg.AIRequestTimeout)
+			ollamaProvider.SetTokenizer(tokenizer)
+			ollamaProvider.SetOutputLanguage(cfg.AIOutputLanguage)
+			ollamaProvider.SetHTTPRetries(cfg.AIMaxHTTPRetries)
+			ollamaProvider.SetUsageRecorder(aiUsage)
+			ollamaProvider.SetTextNormalization(textNormalization)
+			return ollamaProvider, nil
+		default:
+			return nil, fmt.Errorf("unsupported AI provider: %s", provider)
+		}
+	}
+	aiProvider, err := buildProvider(cfg.AIProvider, cfg.AIModel)
+	if err != nil {
+		zapLogger.Fatal("Failed to create AI provider", zap.String("provider", cfg.AIProvider), zap.Error(err))
+	}
+	providerSelector := ai.NewProviderSelector(aiProvider, cfg.AIProvider, cfg.AIModel, buildProvider, cfg.AIAllowedModels, zapLogger)
 
 	zapLogger.Info("Initialized AI provider",
 		zap.String("provider", cfg.AIProvider),
 		zap.String("model", cfg.AIModel),
+		zap.Strings("allowed_models", cfg.AIAllowedModels),
+		zap.Duration("request_timeout", cfg.AIRequestTimeout),
 	)
 
-	// Create task analyzer
+	// Create task analyzer, throttling analyses per user through Redis when configured
+	taskAnalyzerOpts := []workers.TaskAnalyzerOption{
+		workers.WithAIProviderResolver(providerSelector),
+		workers.WithRetryPolicies(retryPolicies),
+	}
+	if redisClient != nil {
+		taskAnalyzerOpts = append(taskAnalyzerOpts, workers.WithAnalysisPause(workers.NewRedisAnalysisPause(redisClient)))
+	}
+	if redisClient != nil && cfg.AIMinAnalysisInterval > 0 {
+		throttle := workers.NewRedisAnalysisThrottle(redisClient, workers.AnalysisThrottlePrefix, cfg.AIMinAnalysisInterval)
+		taskAnalyzerOpts = append(taskAnalyzerOpts, workers.WithAnalysisThrottle(throttle))
+		zapLogger.Info("Enabled per-user analysis interval",
+			zap.Duration("interval", cfg.AIMinAnalysisInterval),
+		)
+	}
+	if redisClient != nil && cfg.AIAnalysisJobsPerMinute > 0 {
+		budget := workers.NewRedisAnalysisBudget(redisClient, workers.AnalysisBudgetPrefix, cfg.AIAnalysisJobsPerMinute)
+		taskAnalyzerOpts = append(taskAnalyzerOpts, workers.WithAnalysisBudget(budget))
+		zapLogger.Info("Enabled per-user analysis budget",
+			zap.Int("jobs_per_minute", cfg.AIAnalysisJobsPerMinute),
+		)
+	}
 	analyzer := workers.NewTaskAnalyzer(
 		aiProvider,
 		todoRepo,
@@ -112,13 +230,43 @@ func main() {
 		tagStatsRepo,
 		jobQueue,
 		zapLogger,
+		taskAnalyzerOpts...,
 	)
 
-	// Create tag analyzer
+	// Create tag analyzer, coalescing runs per user through Redis when configured
+	tagAnalyzerOpts := []workers.TagAnalyzerOption{workers.WithTagAnalyzerAliases(contextRepo)}
+	if redisClient != nil && cfg.TagAnalysisCoalesceWindow > 0 {
+		coalescer := workers.NewRedisTagAnalysisCoalescer(redisClient, cfg.TagAnalysisCoalesceWindow)
+		tagAnalyzerOpts = append(tagAnalyzerOpts, workers.WithTagAnalysisCoalescing(coalescer, jobQueue))
+		zapLogger.Info("Enabled tag analysis coalescing",
+			zap.Duration("window", cfg.TagAnalysisCoalesceWindow),
+		)
+	}
 	tagAnalyzer := workers.NewTagAnalyzer(
 		todoRepo,
 		tagStatsRepo,
 		zapLogger,
+		tagAnalyzerOpts...,
+	)
+
+	// Create reminder worker for due-date reminder escalation
+	reminderWorker := workers.NewReminderWorker(
+		todoRepo,
+		jobQueue,
+		notify.NewLogNotifier(zapLogger),
+		zapLogger,
+	)
+
+	// Create weekly summary worker; it also finishes jobs left in the queue after the schedule is disabled
+	weeklySummaryRepo := database.NewWeeklySummaryRepository(db)
+	weeklySummaryWorker := workers.NewWeeklySummaryWorker(
+		todoRepo,
+		contextRepo,
+		weeklySummaryRepo,
+		jobQueue,
+		providerSelector,
+		notify.NewLogNotifier(zapLogger),
+		zapLogger,
 	)
 
 	// Create reprocessor for scheduled reprocessing
@@ -128,130 +276,564 @@ func main() {
 		zapLogger,
 	)
 
-	// Create garbage collector for job cleanup
+	// Create garbage collector for job cleanup, expired idempotency keys and old audit events
 	// Run every hour, retain jobs for 24 hours
-	gc := queue.NewGarbageCollector(jobQueue, 1*time.Hour, 24*time.Hour)
+	gcOpts := []queue.GarbageCollectorOption{
+		queue.WithRecordPurger("idempotency keys", database.NewIdempotencyRepository(db), database.IdempotencyKeyTTL),
+	}
+	if cfg.AuditEventRetention > 0 {
+		gcOpts = append(gcOpts, queue.WithRecordPurger("audit events", database.NewAuditRepository(db), cfg.AuditEventRetention))
+	}
+	gc := queue.NewGarbageCollector(jobQueue, 1*time.Hour, 24*time.Hour, gcOpts...)
 
-	// Create context for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	schedulers := []supervisor.Component{
+		supervisor.Func("garbage_collector", func(ctx context.Context) {
+			if err := gc.Start(ctx); err != nil && err != context.Canceled {
+				zapLogger.Error("Garbage collector stopped with error", zap.Error(err))
+			}
+		}),
+		supervisor.Func("reprocessing_scheduler", func(ctx context.Context) {
+			runReprocessingScheduler(ctx, reprocessor, 12*time.Hour, zapLogger)
+		}),
+	}
+
+	// Retire old completed todos when a retention age is configured; an advisory lock in the database
+	// keeps concurrent workers from sweeping at the same time
+	if cfg.CompletedTodoRetentionAge > 0 {
+		sweeper := workers.NewRetentionSweeper(
+			todoRepo,
+			cfg.CompletedTodoRetentionAge,
+			models.RetentionAction(cfg.CompletedTodoRetentionAction),
+			zapLogger,
+		)
+		schedulers = append(schedulers, supervisor.Func("retention_sweeper", func(ctx context.Context) {
+			runRetentionSweeper(ctx, sweeper, cfg.CompletedTodoRetentionInterval, zapLogger)
+		}))
+		zapLogger.Info("Completed todo retention enabled",
+			zap.Duration("age", cfg.CompletedTodoRetentionAge),
+			zap.String("action", cfg.CompletedTodoRetentionAction),
+			zap.Duration("interval", cfg.CompletedTodoRetentionInterval),
+		)
+	}
+
+	// Promote time horizons as due dates approach (no AI call); an advisory lock in the database keeps
+	// concurrent workers from promoting at the same time
+	if cfg.HorizonPromotionInterval > 0 {
+		promoter := workers.NewHorizonPromotionSweeper(todoRepo, zapLogger)
+		schedulers = append(schedulers, supervisor.Func("horizon_promotion_sweeper", func(ctx context.Context) {
+			runHorizonPromotionSweeper(ctx, promoter, cfg.HorizonPromotionInterval, zapLogger)
+		}))
+		zapLogger.Info("Time horizon promotion enabled",
+			zap.Duration("interval", cfg.HorizonPromotionInterval),
+		)
+	}
+
+	// Remind users of todos without a reminder policy as they come due; each todo is marked when its reminder
+	// is scheduled, so concurrent workers remind each due date once
+	if cfg.DueReminderInterval > 0 {
+		dueReminderScheduler := workers.NewDueReminderScheduler(todoRepo, jobQueue, cfg.DueReminderWindow, zapLogger)
+		schedulers = append(schedulers, supervisor.Func("due_reminder_scheduler", func(ctx context.Context) {
+			runDueReminderScheduler(ctx, dueReminderScheduler, cfg.DueReminderInterval, zapLogger)
+		}))
+		zapLogger.Info("Due-date reminders enabled",
+			zap.Duration("interval", cfg.DueReminderInterval),
+			zap.Duration("window", cfg.DueReminderWindow),
+		)
+	}
+
+	// Schedule weekly summaries; an advisory lock in the database keeps concurrent workers from
+	// scheduling the same week twice
+	if cfg.WeeklySummaryEnabled {
+		weeklyScheduler := workers.NewWeeklySummaryScheduler(
+			weeklySummaryRepo,
+			jobQueue,
+			cfg.WeeklySummarySpread,
+			cfg.WeeklySummaryMaxIdle,
+			zapLogger,
+		)
+		schedulers = append(schedulers, supervisor.Func("weekly_summary_scheduler", func(ctx context.Context) {
+			runWeeklySummaryScheduler(ctx, weeklyScheduler, weeklySummaryScheduleInterval, zapLogger)
+		}))
+		zapLogger.Info("Weekly summaries enabled",
+			zap.Duration("spread", cfg.WeeklySummarySpread),
+			zap.Duration("max_idle", cfg.WeeklySummaryMaxIdle),
+		)
+	}
+
+	// Log queue and DLQ counts with the jobs processed and failed by type, feeding the queue gauges
+	if cfg.QueueStatsInterval > 0 {
+		statsReporter := workers.NewQueueStatsReporter(jobQueue, zapLogger)
+		schedulers = append(schedulers, supervisor.Func("queue_stats_reporter", func(ctx context.Context) {
+			runQueueStatsReporter(ctx, statsReporter, cfg.QueueStatsInterval, zapLogger)
+		}))
+	}
+
+	// Serve Prometheus metrics on their own port; started before the consumer so its final counts can
+	// still be scraped while it drains
+	if cfg.EnableMetrics {
+		queue.RegisterDLQDepthGauge(metrics.Default, jobQueue)
+		schedulers = append(schedulers, supervisor.Func("metrics_server", func(ctx context.Context) {
+			runMetricsServer(ctx, ":"+cfg.MetricsPort, zapLogger)
+		}))
+		zapLogger.Info("Metrics enabled", zap.String("port", cfg.MetricsPort))
+	}
+
+	// Skip redelivered copies of jobs that were already processed when their ack was lost to a reconnect
+	var redeliveries *workers.RedeliveryGuard
+	if redisClient != nil {
+		redeliveries = workers.NewRedeliveryGuard(workers.NewRedisProcessedJobMarker(redisClient, workers.DefaultProcessedJobTTL), zapLogger)
+	}
+
+	// Components start in this order and stop in reverse, so the consumer stops taking
+	// messages before the schedulers and garbage collector are shut down
+	sup := supervisor.New(zapLogger, append(schedulers,
+		&queueConsumer{
+			queue:        jobQueue,
+			prefetch:     cfg.RabbitMQPrefetch,
+			gracePeriod:  cfg.WorkerShutdownGracePeriod,
+			logger:       zapLogger,
+			redeliveries: redeliveries,
+			route: func(ctx context.Context, msg queue.MessageInterface) (bool, error) {
+				switch msg.GetJob().Type {
+				case queue.JobTypeTagAnalysis:
+					return true, tagAnalyzer.ProcessJob(ctx, msg)
+				case queue.JobTypeTaskAnalysis, queue.JobTypeReprocessUser:
+					return true, analyzer.ProcessJob(ctx, msg)
+				case queue.JobTypeDueReminder:
+					return true, reminderWorker.ProcessJob(ctx, msg)
+				case queue.JobTypeWeeklySummary:
+					return true, weeklySummaryWorker.ProcessJob(ctx, msg)
+				default:
+					return false, nil
+				}
+			},
+		},
+	), supervisor.WithStopTimeout(cfg.WorkerShutdownGracePeriod+workerStopMargin))
 
 	// Setup signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Start consuming messages
-	msgChan, errChan, err := jobQueue.Consume(ctx, cfg.RabbitMQPrefetch)
+	zapLogger.Info("Worker started, consuming messages from queue")
+	timedOut, err := sup.Run(context.Background(), sigChan)
 	if err != nil {
-		zapLogger.Fatal("Failed to start consuming messages", zap.Error(err))
+		zapLogger.Fatal("Failed to start worker", zap.Error(err))
+	}
+	if len(timedOut) > 0 {
+		zapLogger.Warn("Worker stopped with components still running", zap.Strings("components", timedOut))
+		return
 	}
+	zapLogger.Info("Worker stopped")
+}
 
-	zapLogger.Info("Worker started, consuming messages from queue")
+// workerQueue is the job queue the worker consumes, purges the dead letter queue of and reports on
+type workerQueue interface {
+	queue.JobQueue
+	queue.DLQPurger
+	queue.Inspector
+}
 
-	// Start garbage collector
-	go func() {
-		if err := gc.Start(ctx); err != nil && err != context.Canceled {
-			zapLogger.Error("Garbage collector stopped with error", zap.Error(err))
+// openJobQueue opens the configured job queue. The Redis queue needs redisClient, which is nil when Redis is
+// unavailable. The in-memory queue only carries the jobs this worker enqueues itself, such as reminders,
+// weekly summaries, reprocessing and deferred analyses.
+func openJobQueue(cfg *config.Config, redisClient *redis.Client, zapLogger *zap.Logger) (workerQueue, error) {
+	switch cfg.QueueBackend {
+	case "redis":
+		if redisClient == nil {
+			return nil, fmt.Errorf("redis is unavailable")
 		}
-	}()
-	zapLogger.Info("Started garbage collector",
-		zap.Duration("interval", 1*time.Hour),
-		zap.Duration("retention", 24*time.Hour),
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		redisQueue, err := queue.NewRedisQueue(ctx, redisClient)
+		if err != nil {
+			return nil, err
+		}
+		redisQueue.SetLogger(zapLogger)
+		zapLogger.Info("Using the Redis job queue", zap.Int("prefetch", cfg.RabbitMQPrefetch))
+		return redisQueue, nil
+	case "memory":
+		zapLogger.Warn("Using the in-memory job queue; jobs are lost on restart and jobs from the server are not received")
+		memoryQueue := queue.NewInMemoryQueue()
+		memoryQueue.SetLogger(zapLogger)
+		return memoryQueue, nil
+	}
+	rabbitQueue, err := queue.NewRabbitMQQueueWithTLS(cfg.RabbitMQURL, cfg.RabbitMQTLS)
+	if err != nil {
+		return nil, err
+	}
+	rabbitQueue.SetLogger(zapLogger)
+	zapLogger.Info("Connected to RabbitMQ",
+		zap.Int("prefetch", cfg.RabbitMQPrefetch),
 	)
+	return rabbitQueue, nil
+}
 
-	// Start reprocessor scheduler (runs every 12 hours)
-	go func() {
-		ticker := time.NewTicker(12 * time.Hour)
-		defer ticker.Stop()
+// workerStopMargin is how much longer than the shutdown grace period each component may take to stop,
+// leaving the consumer time to close its channel after abandoning a job
+const workerStopMargin = 5 * time.Second
 
-		// Run once at startup
-		if err := reprocessor.ScheduleReprocessingJobs(ctx); err != nil {
-			zapLogger.Error("Failed to schedule initial reprocessing jobs", zap.Error(err))
-		}
+// checkSchemaVersion logs an error when the database has not been migrated to the version this binary
+// expects, so jobs failing on missing columns can be traced back to a skipped migration
+func checkSchemaVersion(db *database.DB, zapLogger *zap.Logger) {
+	expected, err := database.ExpectedSchemaVersion()
+	if err != nil {
+		zapLogger.Error("Failed to read expected schema version", zap.Error(err))
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := database.CheckSchemaVersion(ctx, db, expected); err != nil {
+		zapLogger.Error("Database schema is not current; run migrations",
+			zap.Uint("expected_version", expected),
+			zap.Error(err),
+		)
+	}
+}
 
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				if err := reprocessor.ScheduleReprocessingJobs(ctx); err != nil {
-					zapLogger.Error("Failed to schedule reprocessing jobs", zap.Error(err))
-				}
+// runReprocessingScheduler schedules reprocessing jobs at startup and then every interval until ctx is done
+func runReprocessingScheduler(ctx context.Context, reprocessor *workers.Reprocessor, interval time.Duration, zapLogger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Run once at startup
+	if err := reprocessor.ScheduleReprocessingJobs(ctx); err != nil {
+		zapLogger.Error("Failed to schedule initial reprocessing jobs", zap.Error(err))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := reprocessor.ScheduleReprocessingJobs(ctx); err != nil {
+				zapLogger.Error("Failed to schedule reprocessing jobs", zap.Error(err))
 			}
 		}
+	}
+}
+
+// runRetentionSweeper retires old completed todos at startup and then every interval until ctx is done
+func runRetentionSweeper(ctx context.Context, sweeper *workers.RetentionSweeper, interval time.Duration, zapLogger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := sweeper.Sweep(ctx); err != nil && ctx.Err() == nil {
+			zapLogger.Error("Failed to sweep completed todos", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runHorizonPromotionSweeper promotes time horizons at startup and then every interval until ctx is done
+func runHorizonPromotionSweeper(ctx context.Context, sweeper *workers.HorizonPromotionSweeper, interval time.Duration, zapLogger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := sweeper.Sweep(ctx); err != nil && ctx.Err() == nil {
+			zapLogger.Error("Failed to promote time horizons", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDueReminderScheduler schedules due-date reminders at startup and then every interval until ctx is done
+func runDueReminderScheduler(ctx context.Context, scheduler *workers.DueReminderScheduler, interval time.Duration, zapLogger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := scheduler.Schedule(ctx); err != nil && ctx.Err() == nil {
+			zapLogger.Error("Failed to schedule due reminders", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// queueStatsTimeout bounds each read of the queue counts
+const queueStatsTimeout = 5 * time.Second
+
+// runQueueStatsReporter reports queue counts at startup and then every interval until ctx is done
+func runQueueStatsReporter(ctx context.Context, reporter *workers.QueueStatsReporter, interval time.Duration, zapLogger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		reportCtx, cancel := context.WithTimeout(ctx, queueStatsTimeout)
+		if _, err := reporter.Report(reportCtx); err != nil && ctx.Err() == nil {
+			zapLogger.Warn("Failed to report queue stats", zap.Error(err))
+		}
+		cancel()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// weeklySummaryScheduleInterval is how often the worker checks for users due a weekly summary; each user is
+// scheduled at most once per week however often this runs
+const weeklySummaryScheduleInterval = time.Hour
+
+// runWeeklySummaryScheduler schedules weekly summaries at startup and then every interval until ctx is done
+func runWeeklySummaryScheduler(ctx context.Context, scheduler *workers.WeeklySummaryScheduler, interval time.Duration, zapLogger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := scheduler.Schedule(ctx); err != nil && ctx.Err() == nil {
+			zapLogger.Error("Failed to schedule weekly summaries", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// queueConsumer consumes jobs and routes them to the processor for their type. route reports
+// false for job types no processor handles, which are nacked without requeue.
+type queueConsumer struct {
+	queue    queue.JobQueue
+	prefetch int
+	// gracePeriod is how long Stop waits for the job in progress before cancelling it
+	gracePeriod time.Duration
+	logger      *zap.Logger
+	route       func(ctx context.Context, msg queue.MessageInterface) (bool, error)
+	// redeliveries is nil when Redis is unavailable; redelivered jobs are then always processed
+	redeliveries *workers.RedeliveryGuard
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// mu guards draining and inFlight so no job is started once Stop begins waiting on the jobs WaitGroup
+	mu       sync.Mutex
+	draining bool
+	inFlight int
+	jobs     sync.WaitGroup
+}
+
+func (c *queueConsumer) Name() string { return "queue_consumer" }
+
+// Start begins consuming; the message and error loops run until Stop is called. The consume context
+// is also the jobs' context: cancelling it closes the queue channel, so a job still running then can no
+// longer be acknowledged and is redelivered.
+func (c *queueConsumer) Start(ctx context.Context) error {
+	ctx, c.cancel = context.WithCancel(ctx)
+	msgChan, errChan, err := c.queue.Consume(ctx, c.prefetch)
+	if err != nil {
+		c.cancel()
+		return err
+	}
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		c.processMessages(ctx, msgChan)
 	}()
-	zapLogger.Info("Started reprocessing scheduler",
-		zap.Duration("interval", 12*time.Hour),
-	)
+	go func() {
+		defer c.wg.Done()
+		c.logErrors(ctx, errChan)
+	}()
+	return nil
+}
+
+// Stop drains the consumer: it stops starting new jobs, waits up to the grace period for the job in
+// progress to finish and be acknowledged, then cancels consumption. Prefetched jobs that were never
+// started are requeued. A job still running after the grace period is abandoned to redelivery.
+func (c *queueConsumer) Stop() {
+	if c.cancel == nil {
+		return
+	}
+	c.mu.Lock()
+	c.draining = true
+	inFlight := c.inFlight
+	c.mu.Unlock()
 
-	// Process messages
+	drained := make(chan struct{})
 	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case msg, ok := <-msgChan:
-				if !ok {
-					zapLogger.Info("Message channel closed")
-					return
-				}
+		c.jobs.Wait()
+		close(drained)
+	}()
+	timer := time.NewTimer(c.gracePeriod)
+	select {
+	case <-drained:
+	case <-timer.C:
+	}
+	timer.Stop()
+
+	c.mu.Lock()
+	abandoned := c.inFlight
+	c.mu.Unlock()
+	logFn := c.logger.Info
+	if abandoned > 0 {
+		logFn = c.logger.Warn
+	}
+	logFn("queue_consumer_drained",
+		zap.Int("jobs_drained", inFlight-abandoned),
+		zap.Int("jobs_abandoned", abandoned),
+		zap.Duration("grace_period", c.gracePeriod),
+	)
 
-				job := msg.GetJob()
-				
-				// Route job to appropriate analyzer based on type
-				var err error
-				switch job.Type {
-				case queue.JobTypeTagAnalysis:
-					err = tagAnalyzer.ProcessJob(ctx, msg)
-				case queue.JobTypeTaskAnalysis, queue.JobTypeReprocessUser:
-					err = analyzer.ProcessJob(ctx, msg)
-				default:
-					zapLogger.Error("Unknown job type",
-						zap.String("job_id", job.ID.String()),
-						zap.String("job_type", string(job.Type)),
-					)
-					// Nack unknown job types
-					if nackErr := msg.Nack(false); nackErr != nil {
-						zapLogger.Error("Failed to nack unknown job type",
-							zap.String("job_id", job.ID.String()),
-							zap.Error(nackErr),
-						)
-					}
-					continue
-				}
+	c.cancel()
+	c.wg.Wait()
+}
 
-				if err != nil {
-					zapLogger.Error("Failed to process job",
-						zap.Error(err),
-						zap.String("job_id", job.ID.String()),
-						zap.String("job_type", string(job.Type)),
-					)
+func (c *queueConsumer) processMessages(ctx context.Context, msgChan <-chan *queue.Message) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-msgChan:
+			if !ok {
+				// The channel also closes when we cancel consumption; only report unexpected closes
+				if ctx.Err() == nil {
+					c.logger.Info("Message channel closed")
 				}
+				return
 			}
+			// Once draining, stop reading: jobs still prefetched are requeued when the channel closes
+			if !c.startJob() {
+				c.requeue(msg)
+				return
+			}
+			c.process(ctx, msg)
+			c.finishJob()
 		}
-	}()
+	}
+}
 
-	// Handle errors
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
+// startJob records a job as in flight, or reports false once Stop has begun draining
+func (c *queueConsumer) startJob() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.draining {
+		return false
+	}
+	c.inFlight++
+	c.jobs.Add(1)
+	return true
+}
+
+func (c *queueConsumer) finishJob() {
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	c.jobs.Done()
+}
+
+// requeue returns a job that arrived while draining to the queue for another worker
+func (c *queueConsumer) requeue(msg queue.MessageInterface) {
+	if err := msg.Nack(true); err != nil {
+		c.logger.Warn("Failed to requeue job while draining",
+			zap.String("job_id", msg.GetJob().ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+func (c *queueConsumer) process(ctx context.Context, msg queue.MessageInterface) {
+	job := msg.GetJob()
+	if c.redeliveries != nil && c.redeliveries.Skip(ctx, msg) {
+		return
+	}
+	handled, err := c.route(ctx, msg)
+	if !handled {
+		c.logger.Error("Unknown job type",
+			zap.String("job_id", job.ID.String()),
+			zap.String("job_type", string(job.Type)),
+		)
+		// Nack unknown job types
+		if nackErr := msg.Nack(false); nackErr != nil {
+			c.logger.Error("Failed to nack unknown job type",
+				zap.String("job_id", job.ID.String()),
+				zap.Error(nackErr),
+			)
+		}
+		return
+	}
+	if err != nil {
+		metrics.JobsFailed.Inc(string(job.Type))
+		c.logger.Error("Failed to process job",
+			zap.Error(err),
+			zap.String("job_id", job.ID.String()),
+			zap.String("job_type", string(job.Type)),
+		)
+		return
+	}
+	metrics.JobsProcessed.Inc(string(job.Type))
+	if c.redeliveries != nil {
+		c.redeliveries.Done(ctx, job)
+	}
+}
+
+func (c *queueConsumer) logErrors(ctx context.Context, errChan <-chan error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-errChan:
+			if !ok {
 				return
-			case err, ok := <-errChan:
-				if !ok {
-					return
-				}
-				zapLogger.Error("Queue error", zap.Error(err))
 			}
+			c.logger.Error("Queue error", zap.Error(err))
 		}
-	}()
+	}
+}
 
-	// Wait for shutdown signal
-	<-sigChan
-	zapLogger.Info("Shutdown signal received, stopping worker...")
+// metricsShutdownTimeout bounds how long the metrics server waits for in-flight scrapes on shutdown
+const metricsShutdownTimeout = 5 * time.Second
+
+// runMetricsServer serves /metrics on addr until ctx is done
+func runMetricsServer(ctx context.Context, addr string, zapLogger *zap.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Default.Handler())
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
 
-	// Cancel context to stop processing
-	cancel()
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.ListenAndServe() }()
+	select {
+	case err := <-errCh:
+		zapLogger.Error("Metrics server failed", zap.String("addr", addr), zap.Error(err))
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			zapLogger.Warn("Metrics server shutdown failed", zap.Error(err))
+		}
+	}
+}
 
-	zapLogger.Info("Worker stopped")
+// connectRedis creates a Redis client from the URL and verifies connectivity
+func connectRedis(redisURL string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return client, nil
 }
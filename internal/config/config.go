@@ -2,8 +2,13 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/tlsconfig"
 )
 
 // Config holds application configuration
@@ -13,6 +18,7 @@ type Config struct {
 	BaseURL          string
 	FrontendURL      string
 	OpenAIKey        string
+	AnthropicKey     string
 	AIProvider       string
 	AIModel          string
 	AIBaseURL        string
@@ -25,6 +31,135 @@ type Config struct {
 	ServerDebugMode  bool
 	OTELEnabled      bool
 	OTELEndpoint     string
+	// EnableMetrics serves Prometheus metrics on /metrics (the server's port, and MetricsPort for the worker)
+	EnableMetrics bool
+	// MetricsPort is the port the worker serves /metrics on when EnableMetrics is set
+	MetricsPort string
+	// WorkerShutdownGracePeriod is how long a stopping worker waits for its in-flight job to finish and be
+	// acknowledged before abandoning it
+	WorkerShutdownGracePeriod time.Duration
+	// ResponseTimestampFormat is the format of timestamps in API responses (rfc3339, rfc3339nano, unix)
+	ResponseTimestampFormat string
+	// ResponseTimezone is the IANA timezone used for string response timestamps
+	ResponseTimezone string
+	// AdminAPIToken enables operator-only endpoints when set (sent via X-Admin-Token)
+	AdminAPIToken string
+	// AdminEmails lists the emails whose users are given the admin role when they log in
+	AdminEmails []string
+	// OIDCRolesClaim names the token claim listing a user's roles or groups; users with "admin" among them
+	// are given the admin role (empty disables)
+	OIDCRolesClaim string
+	// StrictJSONDecoding rejects todo create/update bodies with unknown fields instead of ignoring them
+	StrictJSONDecoding bool
+	// ReanalyzeOnTextChange enqueues a new AI analysis when a todo's text is edited
+	ReanalyzeOnTextChange bool
+	// TagAnalysisCoalesceWindow limits tag analysis to one run per user per window (0 disables coalescing)
+	TagAnalysisCoalesceWindow time.Duration
+	// CORSReloadInterval is how often CORS config is reloaded from the database
+	CORSReloadInterval time.Duration
+	// RateLimitReloadInterval is how often rate limit config is reloaded from the database
+	RateLimitReloadInterval time.Duration
+	// JWKSCacheTTL is how long fetched OIDC signing keys are used before being fetched again; they are
+	// refreshed in the background before they expire
+	JWKSCacheTTL time.Duration
+	// JWKSMaxStale is how long past their expiry cached OIDC signing keys are still used while they cannot be
+	// fetched (0 never uses expired keys)
+	JWKSMaxStale time.Duration
+	// AITokenizer selects how prompt tokens are counted (tiktoken, falling back to heuristic, or heuristic)
+	AITokenizer string
+	// AIAllowedModels lists the models users may select ("model" for AI_PROVIDER, or "provider:model")
+	AIAllowedModels []string
+	// AIOutputLanguage is the language AI-suggested tags are written in ("auto" follows each todo's language)
+	AIOutputLanguage string
+	// AIMaxHTTPRetries is how many times an AI HTTP request is retried after a 5xx response or a transient
+	// network error, with jittered exponential backoff
+	AIMaxHTTPRetries int
+	// AIContextMaxSummaryLength is the maximum length in characters of a user's AI context summary
+	AIContextMaxSummaryLength int
+	// AIRequestTimeout bounds each HTTP call to the AI provider's API (0 uses the provider's default: 30s for
+	// OpenAI and Anthropic, 2m for Ollama)
+	AIRequestTimeout time.Duration
+	// MaxConcurrentRequestsPerClient caps in-flight requests per client IP (0 disables)
+	MaxConcurrentRequestsPerClient int
+	// AIMinAnalysisInterval is the minimum time between background AI analyses for one user (0 disables)
+	AIMinAnalysisInterval time.Duration
+	// AIMinManualAnalysisInterval is the minimum time between manual analyze requests for one user (0 disables)
+	AIMinManualAnalysisInterval time.Duration
+	// AIAnalysisJobsPerMinute is how many background AI analyses one user may run per minute before further
+	// ones are deferred (0 disables)
+	AIAnalysisJobsPerMinute int
+	// TagRecomputePacing is the delay between consecutive users' jobs when tag analysis is re-run for every
+	// user through the admin API (0 makes them all due at once)
+	TagRecomputePacing time.Duration
+	// ReprocessingMinIdle is how long a user must have been idle before scheduled reprocessing includes them (0 disables)
+	ReprocessingMinIdle time.Duration
+	// ReprocessingMaxIdle excludes users idle for longer than this from scheduled reprocessing (0 disables)
+	ReprocessingMaxIdle time.Duration
+	// DBSlowQueryThreshold is the duration at which repository queries are logged as slow (0 disables)
+	DBSlowQueryThreshold time.Duration
+	// QueueBackend selects the job queue: "rabbitmq", "redis" for Redis streams on REDIS_URL, or "memory" for a
+	// queue inside the process that other processes cannot see. It defaults to rabbitmq; memory is only used when
+	// set explicitly.
+	QueueBackend string
+	// QueueHighWatermark is the job queue depth at which analysis enqueues are held back (0 disables)
+	QueueHighWatermark int
+	// QueueBackpressureMode is what happens to analysis jobs over the watermark: "skip" drops them, "delay" defers them
+	QueueBackpressureMode string
+	// QueueBackpressureDelay is how far analysis jobs are deferred in "delay" mode
+	QueueBackpressureDelay time.Duration
+	// FocusWeightOverdue, FocusWeightDueToday, FocusWeightNextHorizon and FocusWeightHighPriority score todos
+	// for the /todos/focus shortlist
+	FocusWeightOverdue      float64
+	FocusWeightDueToday     float64
+	FocusWeightNextHorizon  float64
+	FocusWeightHighPriority float64
+	// TrustedProxies are the reverse proxy address ranges whose X-Forwarded-For / X-Real-IP headers are honored
+	TrustedProxies []netip.Prefix
+	// CompletedTodoRetentionAge is how long after completion a todo is retired by the worker sweep (0 disables)
+	CompletedTodoRetentionAge time.Duration
+	// CompletedTodoRetentionAction is what the sweep does with old completed todos: "archive" or "trash"
+	CompletedTodoRetentionAction string
+	// CompletedTodoRetentionInterval is how often the worker runs the retention sweep
+	CompletedTodoRetentionInterval time.Duration
+	// AuditEventRetention is how long stored audit events are kept before the worker deletes them (0 keeps them)
+	AuditEventRetention time.Duration
+	// WeeklySummaryEnabled turns on the worker's weekly per-user summary job
+	WeeklySummaryEnabled bool
+	// WeeklySummarySpread is the window weekly summary jobs are spread over to pace AI load
+	WeeklySummarySpread time.Duration
+	// WeeklySummaryMaxIdle skips users idle for longer than this when scheduling weekly summaries (0 disables)
+	WeeklySummaryMaxIdle time.Duration
+	// HorizonPromotionInterval is how often the worker promotes time horizons of todos with approaching due dates (0 disables)
+	HorizonPromotionInterval time.Duration
+	// DueReminderInterval is how often the worker scans for todos coming due to remind their users of (0 disables)
+	DueReminderInterval time.Duration
+	// DueReminderWindow is how long before its due date a todo without a reminder policy is reminded of
+	DueReminderWindow time.Duration
+	// QueueStatsInterval is how often the worker logs queue and DLQ counts and processed/failed jobs by type (0 disables)
+	QueueStatsInterval time.Duration
+	// TagStatsIncremental applies tag changes directly to stored tag statistics instead of rescanning all todos
+	TagStatsIncremental bool
+	// TagStatsFullAnalysisInterval is how old the last full tag analysis may get in incremental mode before a
+	// tag change schedules a full one to correct drift
+	TagStatsFullAnalysisInterval time.Duration
+	// TagAnalysisDebounce is how long after a user's last tag change their tag analysis runs (0 runs it at once)
+	TagAnalysisDebounce time.Duration
+	// TagAnalysisDebounceMaxWait caps how long repeated tag changes may delay a tag analysis past the first one
+	TagAnalysisDebounceMaxWait time.Duration
+	// MiddlewareChain is the configured order of the global middleware chain, outermost first (empty uses the default)
+	MiddlewareChain []string
+	// AITextNormalization lists the normalization steps applied before tag similarity is scored (empty: default)
+	AITextNormalization []string
+	// AIPriceTable adds or overrides model prices for AI cost estimates ("model=input/output", USD per million tokens)
+	AIPriceTable []string
+	// JobRetryPolicies sets retries and backoff per job type ("job_type=max_retries/base_delay/max_delay")
+	JobRetryPolicies []string
+	// OTELTraceSampleRatio is the fraction of new traces sampled (0-1); error spans are always exported
+	OTELTraceSampleRatio float64
+	// DatabaseTLS configures client TLS (CA, client certificate) for the PostgreSQL connection
+	DatabaseTLS tlsconfig.Options
+	// RabbitMQTLS configures client TLS (CA, client certificate) for the RabbitMQ connection
+	RabbitMQTLS tlsconfig.Options
 }
 
 // Load loads configuration from environment variables
@@ -35,6 +170,7 @@ func Load() (*Config, error) {
 		BaseURL:          getEnv("BASE_URL", "http://localhost:8080"),
 		FrontendURL:      getEnv("FRONTEND_URL", "http://localhost:3000"),
 		OpenAIKey:        getEnv("OPENAI_API_KEY", ""),
+		AnthropicKey:     getEnv("ANTHROPIC_API_KEY", ""),
 		AIProvider:       getEnv("AI_PROVIDER", "openai"),
 		AIModel:          getEnv("AI_MODEL", ""),
 		AIBaseURL:        getEnv("AI_BASE_URL", ""),
@@ -47,19 +183,373 @@ func Load() (*Config, error) {
 		ServerDebugMode:  getEnvBool("SERVER_DEBUG_MODE", false),
 		OTELEnabled:      getEnvBool("OTEL_ENABLED", false),
 		OTELEndpoint:     getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		EnableMetrics:    getEnvBool("ENABLE_METRICS", false),
+		MetricsPort:      getEnv("METRICS_PORT", "9091"),
+
+		ResponseTimestampFormat: getEnv("RESPONSE_TIMESTAMP_FORMAT", "rfc3339"),
+		ResponseTimezone:        getEnv("RESPONSE_TIMEZONE", "UTC"),
+		AdminAPIToken:           getEnv("ADMIN_API_TOKEN", ""),
+		AdminEmails:             getEnvList("ADMIN_EMAILS"),
+		OIDCRolesClaim:          getEnv("OIDC_ROLES_CLAIM", ""),
+		ReanalyzeOnTextChange:   getEnvBool("REANALYZE_ON_TEXT_CHANGE", true),
+		StrictJSONDecoding:      getEnvBool("STRICT_JSON_DECODING", false),
+		AITokenizer:             getEnv("AI_TOKENIZER", "tiktoken"),
+		AIAllowedModels:         getEnvList("AI_ALLOWED_MODELS"),
+		MiddlewareChain:         getEnvList("MIDDLEWARE_CHAIN"),
+		AIPriceTable:            getEnvList("AI_PRICE_TABLE"),
+		AITextNormalization:     getEnvList("AI_TEXT_NORMALIZATION"),
+		JobRetryPolicies:        getEnvList("JOB_RETRY_POLICIES"),
+		AIOutputLanguage:        getEnv("AI_OUTPUT_LANGUAGE", ""),
+		DatabaseTLS:             getEnvTLS("DATABASE_TLS"),
+		RabbitMQTLS:             getEnvTLS("RABBITMQ_TLS"),
+		AIMaxHTTPRetries:        getEnvInt("AI_MAX_HTTP_RETRIES", 2),
+		QueueBackpressureMode:   getEnv("QUEUE_BACKPRESSURE_MODE", "skip"),
+
+		MaxConcurrentRequestsPerClient: getEnvInt("MAX_CONCURRENT_REQUESTS_PER_CLIENT", 20),
+		AIContextMaxSummaryLength:      getEnvInt("AI_CONTEXT_MAX_SUMMARY_LENGTH", 2000),
 	}
 
 	if cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL is required")
 	}
 
-	if cfg.RabbitMQURL == "" {
-		return nil, fmt.Errorf("RABBITMQ_URL is required for job queueing (AI features require RabbitMQ)")
+	if err := loadQueueBackend(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateResponseTimestamp(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateCounts(cfg); err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(cfg.AITokenizer) {
+	case "tiktoken", "heuristic":
+	default:
+		return nil, fmt.Errorf("AI_TOKENIZER must be one of tiktoken, heuristic (got %q)", cfg.AITokenizer)
+	}
+
+	if err := loadDurations(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := loadQueueBackpressure(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := loadFloats(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateTLS(cfg); err != nil {
+		return nil, err
 	}
 
 	return cfg, nil
 }
 
+// validateCounts checks the integer settings that must not be negative
+func validateCounts(cfg *Config) error {
+	if cfg.AIMaxHTTPRetries < 0 {
+		return fmt.Errorf("AI_MAX_HTTP_RETRIES must not be negative")
+	}
+	if cfg.AIContextMaxSummaryLength <= 0 {
+		return fmt.Errorf("AI_CONTEXT_MAX_SUMMARY_LENGTH must be positive")
+	}
+	if cfg.MaxConcurrentRequestsPerClient < 0 {
+		return fmt.Errorf("MAX_CONCURRENT_REQUESTS_PER_CLIENT must not be negative")
+	}
+	return nil
+}
+
+// loadFloats reads and validates the float-valued settings
+func loadFloats(cfg *Config) error {
+	if err := loadTracing(cfg); err != nil {
+		return err
+	}
+	return loadFocusWeights(cfg)
+}
+
+// loadFocusWeights reads the non-negative weights that score todos for the focus shortlist
+func loadFocusWeights(cfg *Config) error {
+	weights := []struct {
+		key          string
+		defaultValue float64
+		dst          *float64
+	}{
+		{"FOCUS_WEIGHT_OVERDUE", 8, &cfg.FocusWeightOverdue},
+		{"FOCUS_WEIGHT_DUE_TODAY", 4, &cfg.FocusWeightDueToday},
+		{"FOCUS_WEIGHT_NEXT_HORIZON", 2, &cfg.FocusWeightNextHorizon},
+		{"FOCUS_WEIGHT_HIGH_PRIORITY", 1, &cfg.FocusWeightHighPriority},
+	}
+	for _, w := range weights {
+		v, err := getEnvFloat(w.key, w.defaultValue)
+		if err != nil {
+			return err
+		}
+		if v < 0 {
+			return fmt.Errorf("%s must not be negative", w.key)
+		}
+		*w.dst = v
+	}
+	return nil
+}
+
+// loadTracing reads and validates the OpenTelemetry sampling settings
+func loadTracing(cfg *Config) error {
+	ratio, err := getEnvFloat("OTEL_TRACE_SAMPLE_RATIO", 1.0)
+	if err != nil {
+		return err
+	}
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("OTEL_TRACE_SAMPLE_RATIO must be between 0 and 1 (got %v)", ratio)
+	}
+	cfg.OTELTraceSampleRatio = ratio
+	return nil
+}
+
+// validateTLS checks that configured certificate files exist so misconfiguration fails at startup
+func validateTLS(cfg *Config) error {
+	if err := cfg.DatabaseTLS.Validate(); err != nil {
+		return fmt.Errorf("DATABASE_TLS: %w", err)
+	}
+	if err := cfg.RabbitMQTLS.Validate(); err != nil {
+		return fmt.Errorf("RABBITMQ_TLS: %w", err)
+	}
+	if !cfg.RabbitMQTLS.IsZero() && !strings.HasPrefix(strings.ToLower(cfg.RabbitMQURL), "amqps://") {
+		return fmt.Errorf("RABBITMQ_TLS_* settings require an amqps:// RABBITMQ_URL")
+	}
+	return loadTrustedProxies(cfg)
+}
+
+// loadTrustedProxies parses TRUSTED_PROXIES, a list of CIDR ranges or single addresses. Empty trusts no proxy.
+func loadTrustedProxies(cfg *Config) error {
+	for _, entry := range getEnvList("TRUSTED_PROXIES") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(entry)
+			if addrErr != nil {
+				return fmt.Errorf("TRUSTED_PROXIES: invalid CIDR or address %q", entry)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, prefix.Masked())
+	}
+	return nil
+}
+
+// loadDurations reads and validates the duration-valued settings
+func loadDurations(cfg *Config) error {
+	var err error
+	if cfg.TagAnalysisCoalesceWindow, err = getEnvDuration("TAG_ANALYSIS_COALESCE_WINDOW", 30*time.Second); err != nil {
+		return err
+	}
+	if cfg.TagAnalysisCoalesceWindow < 0 {
+		return fmt.Errorf("TAG_ANALYSIS_COALESCE_WINDOW must not be negative")
+	}
+	if cfg.CORSReloadInterval, err = getEnvDuration("CORS_RELOAD_INTERVAL", time.Minute); err != nil {
+		return err
+	}
+	if cfg.CORSReloadInterval <= 0 {
+		return fmt.Errorf("CORS_RELOAD_INTERVAL must be positive")
+	}
+	if cfg.RateLimitReloadInterval, err = getEnvDuration("RATE_LIMIT_RELOAD_INTERVAL", time.Minute); err != nil {
+		return err
+	}
+	if cfg.RateLimitReloadInterval <= 0 {
+		return fmt.Errorf("RATE_LIMIT_RELOAD_INTERVAL must be positive")
+	}
+	if cfg.JWKSCacheTTL, err = getEnvDuration("JWKS_CACHE_TTL", time.Hour); err != nil {
+		return err
+	}
+	if cfg.JWKSCacheTTL <= 0 {
+		return fmt.Errorf("JWKS_CACHE_TTL must be positive")
+	}
+	if cfg.JWKSMaxStale, err = getEnvNonNegativeDuration("JWKS_MAX_STALE", 24*time.Hour); err != nil {
+		return err
+	}
+	if cfg.AIRequestTimeout, err = getEnvDuration("AI_REQUEST_TIMEOUT", 0); err != nil {
+		return err
+	}
+	if cfg.AIRequestTimeout <= 0 && os.Getenv("AI_REQUEST_TIMEOUT") != "" {
+		return fmt.Errorf("AI_REQUEST_TIMEOUT must be positive")
+	}
+	if cfg.DBSlowQueryThreshold, err = getEnvNonNegativeDuration("DB_SLOW_QUERY_THRESHOLD", 0); err != nil {
+		return err
+	}
+	if cfg.WorkerShutdownGracePeriod, err = getEnvNonNegativeDuration("WORKER_SHUTDOWN_GRACE_PERIOD", 30*time.Second); err != nil {
+		return err
+	}
+	return loadAnalysisIntervals(cfg)
+}
+
+// loadAnalysisIntervals reads the per-user minimum intervals between AI analyses, the per-user analysis budget
+// and the tag recompute pacing
+func loadAnalysisIntervals(cfg *Config) error {
+	var err error
+	if cfg.AIMinAnalysisInterval, err = getEnvNonNegativeDuration("AI_MIN_ANALYSIS_INTERVAL", 0); err != nil {
+		return err
+	}
+	if cfg.AIMinManualAnalysisInterval, err = getEnvNonNegativeDuration("AI_MIN_MANUAL_ANALYSIS_INTERVAL", 0); err != nil {
+		return err
+	}
+	if cfg.AIAnalysisJobsPerMinute = getEnvInt("AI_ANALYSIS_JOBS_PER_MINUTE", 0); cfg.AIAnalysisJobsPerMinute < 0 {
+		return fmt.Errorf("AI_ANALYSIS_JOBS_PER_MINUTE must not be negative")
+	}
+	if cfg.TagRecomputePacing, err = getEnvNonNegativeDuration("TAG_RECOMPUTE_PACING", 200*time.Millisecond); err != nil {
+		return err
+	}
+	return loadReprocessingWindow(cfg)
+}
+
+// loadReprocessingWindow reads the idle-time bounds on users eligible for scheduled reprocessing
+func loadReprocessingWindow(cfg *Config) error {
+	var err error
+	if cfg.ReprocessingMinIdle, err = getEnvNonNegativeDuration("REPROCESSING_MIN_IDLE", 0); err != nil {
+		return err
+	}
+	if cfg.ReprocessingMaxIdle, err = getEnvNonNegativeDuration("REPROCESSING_MAX_IDLE", 0); err != nil {
+		return err
+	}
+	if cfg.ReprocessingMaxIdle > 0 && cfg.ReprocessingMinIdle >= cfg.ReprocessingMaxIdle {
+		return fmt.Errorf("REPROCESSING_MIN_IDLE must be less than REPROCESSING_MAX_IDLE")
+	}
+	return loadCompletedRetention(cfg)
+}
+
+// loadCompletedRetention reads the completed-todo retention sweep settings
+func loadCompletedRetention(cfg *Config) error {
+	var err error
+	if cfg.CompletedTodoRetentionAge, err = getEnvNonNegativeDuration("COMPLETED_TODO_RETENTION_AGE", 0); err != nil {
+		return err
+	}
+	cfg.CompletedTodoRetentionAction = getEnv("COMPLETED_TODO_RETENTION_ACTION", "archive")
+	switch cfg.CompletedTodoRetentionAction {
+	case "archive", "trash":
+	default:
+		return fmt.Errorf("COMPLETED_TODO_RETENTION_ACTION must be one of archive, trash (got %q)", cfg.CompletedTodoRetentionAction)
+	}
+	if cfg.CompletedTodoRetentionInterval, err = getEnvDuration("COMPLETED_TODO_RETENTION_INTERVAL", time.Hour); err != nil {
+		return err
+	}
+	if cfg.CompletedTodoRetentionInterval <= 0 {
+		return fmt.Errorf("COMPLETED_TODO_RETENTION_INTERVAL must be positive")
+	}
+	if cfg.AuditEventRetention, err = getEnvNonNegativeDuration("AUDIT_EVENT_RETENTION", 90*24*time.Hour); err != nil {
+		return err
+	}
+	return loadWeeklySummary(cfg)
+}
+
+// loadWeeklySummary reads the weekly summary job settings
+func loadWeeklySummary(cfg *Config) error {
+	var err error
+	cfg.WeeklySummaryEnabled = getEnvBool("WEEKLY_SUMMARY_ENABLED", false)
+	if cfg.WeeklySummarySpread, err = getEnvNonNegativeDuration("WEEKLY_SUMMARY_SPREAD", 6*time.Hour); err != nil {
+		return err
+	}
+	if cfg.WeeklySummaryMaxIdle, err = getEnvNonNegativeDuration("WEEKLY_SUMMARY_MAX_IDLE", 30*24*time.Hour); err != nil {
+		return err
+	}
+	if cfg.HorizonPromotionInterval, err = getEnvNonNegativeDuration("HORIZON_PROMOTION_INTERVAL", time.Hour); err != nil {
+		return err
+	}
+	if cfg.DueReminderInterval, err = getEnvNonNegativeDuration("DUE_REMINDER_INTERVAL", 15*time.Minute); err != nil {
+		return err
+	}
+	if cfg.DueReminderWindow, err = getEnvNonNegativeDuration("DUE_REMINDER_WINDOW", 24*time.Hour); err != nil {
+		return err
+	}
+	if cfg.DueReminderInterval > 0 && cfg.DueReminderWindow == 0 {
+		return fmt.Errorf("DUE_REMINDER_WINDOW must be positive while DUE_REMINDER_INTERVAL is set")
+	}
+	if cfg.QueueStatsInterval, err = getEnvNonNegativeDuration("QUEUE_STATS_INTERVAL", time.Minute); err != nil {
+		return err
+	}
+	return loadTagStatistics(cfg)
+}
+
+// loadTagStatistics reads how tag statistics are kept current on tag changes
+func loadTagStatistics(cfg *Config) error {
+	var err error
+	cfg.TagStatsIncremental = getEnvBool("TAG_STATS_INCREMENTAL", false)
+	if cfg.TagStatsFullAnalysisInterval, err = getEnvDuration("TAG_STATS_FULL_ANALYSIS_INTERVAL", 24*time.Hour); err != nil {
+		return err
+	}
+	if cfg.TagStatsFullAnalysisInterval <= 0 {
+		return fmt.Errorf("TAG_STATS_FULL_ANALYSIS_INTERVAL must be positive")
+	}
+	if cfg.TagAnalysisDebounce, err = getEnvNonNegativeDuration("TAG_ANALYSIS_DEBOUNCE", 5*time.Second); err != nil {
+		return err
+	}
+	if cfg.TagAnalysisDebounceMaxWait, err = getEnvNonNegativeDuration("TAG_ANALYSIS_DEBOUNCE_MAX_WAIT", time.Minute); err != nil {
+		return err
+	}
+	if cfg.TagAnalysisDebounceMaxWait < cfg.TagAnalysisDebounce {
+		return fmt.Errorf("TAG_ANALYSIS_DEBOUNCE_MAX_WAIT must not be less than TAG_ANALYSIS_DEBOUNCE")
+	}
+	return nil
+}
+
+// loadQueueBackend reads and validates the job queue backend, defaulting to RabbitMQ. A missing RABBITMQ_URL
+// fails rather than falling back to the in-process queue, which would leave the worker without the server's jobs.
+func loadQueueBackend(cfg *Config) error {
+	cfg.QueueBackend = getEnv("QUEUE_BACKEND", "rabbitmq")
+	switch cfg.QueueBackend {
+	case "rabbitmq":
+		if cfg.RabbitMQURL == "" {
+			return fmt.Errorf("RABBITMQ_URL is required for QUEUE_BACKEND=rabbitmq (set QUEUE_BACKEND=memory for a queue inside the process)")
+		}
+	case "redis", "memory":
+	default:
+		return fmt.Errorf("QUEUE_BACKEND must be one of rabbitmq, redis, memory (got %q)", cfg.QueueBackend)
+	}
+	return nil
+}
+
+// loadQueueBackpressure reads and validates the job queue high-watermark settings
+func loadQueueBackpressure(cfg *Config) error {
+	var err error
+	cfg.QueueHighWatermark, err = getEnvNonNegativeInt("QUEUE_HIGH_WATERMARK", 0)
+	if err != nil {
+		return err
+	}
+	switch cfg.QueueBackpressureMode {
+	case "skip", "delay":
+	default:
+		return fmt.Errorf("QUEUE_BACKPRESSURE_MODE must be one of skip, delay (got %q)", cfg.QueueBackpressureMode)
+	}
+	cfg.QueueBackpressureDelay, err = getEnvNonNegativeDuration("QUEUE_BACKPRESSURE_DELAY", 5*time.Minute)
+	return err
+}
+
+// validateResponseTimestamp checks RESPONSE_TIMESTAMP_FORMAT and RESPONSE_TIMEZONE
+func validateResponseTimestamp(cfg *Config) error {
+	switch strings.ToLower(cfg.ResponseTimestampFormat) {
+	case "rfc3339", "rfc3339nano", "unix":
+	default:
+		return fmt.Errorf("RESPONSE_TIMESTAMP_FORMAT must be one of rfc3339, rfc3339nano, unix (got %q)", cfg.ResponseTimestampFormat)
+	}
+	if _, err := time.LoadLocation(cfg.ResponseTimezone); err != nil {
+		return fmt.Errorf("RESPONSE_TIMEZONE is not a valid timezone: %w", err)
+	}
+	return nil
+}
+
+// AIAPIKey returns the API key configured for an AI provider ("openai" or "anthropic"), or "" for any other
+func (c *Config) AIAPIKey(provider string) string {
+	switch provider {
+	case "openai":
+		return c.OpenAIKey
+	case "anthropic":
+		return c.AnthropicKey
+	default:
+		return ""
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -67,6 +557,27 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvTLS reads the <prefix>_CA_FILE, _CERT_FILE, _KEY_FILE and _INSECURE_SKIP_VERIFY settings
+func getEnvTLS(prefix string) tlsconfig.Options {
+	return tlsconfig.Options{
+		CAFile:             getEnv(prefix+"_CA_FILE", ""),
+		CertFile:           getEnv(prefix+"_CERT_FILE", ""),
+		KeyFile:            getEnv(prefix+"_KEY_FILE", ""),
+		InsecureSkipVerify: getEnvBool(prefix+"_INSECURE_SKIP_VERIFY", false),
+	}
+}
+
+// getEnvList returns the comma-separated values of key with blanks removed
+func getEnvList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		return value == "true" || value == "1" || value == "yes"
@@ -82,3 +593,56 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvNonNegativeInt reads an integer setting that must not be negative; unlike getEnvInt a malformed
+// value is an error rather than the default
+func getEnvNonNegativeInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer: %w", key, err)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("%s must not be negative", key)
+	}
+	return n, nil
+}
+
+func getEnvFloat(key string, defaultValue float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number: %w", key, err)
+	}
+	return f, nil
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration (e.g. 30s, 5m): %w", key, err)
+	}
+	return d, nil
+}
+
+// getEnvNonNegativeDuration reads a duration setting that must not be negative
+func getEnvNonNegativeDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	d, err := getEnvDuration(key, defaultValue)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%s must not be negative", key)
+	}
+	return d, nil
+}
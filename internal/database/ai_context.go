@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -24,21 +25,30 @@ func NewAIContextRepository(db *DB) *AIContextRepository {
 func (r *AIContextRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.AIContext, error) {
 	aiContext := &models.AIContext{}
 	var preferencesJSON []byte
+	var tagAliasesJSON []byte
+	var tagWeightsJSON []byte
+	var summaryUpdatedAt sql.NullTime
 	
 	query := `
-		SELECT id, user_id, context_summary, preferences, created_at, updated_at
+		SELECT id, user_id, context_summary, preferences, tag_aliases, tag_weights, summary_updated_at, summary_chat_turns, created_at, updated_at
 		FROM ai_context
 		WHERE user_id = $1
 	`
 	
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
-		&aiContext.ID,
-		&aiContext.UserID,
-		&aiContext.ContextSummary,
-		&preferencesJSON,
-		&aiContext.CreatedAt,
-		&aiContext.UpdatedAt,
-	)
+	err := r.db.timedQuery("ai_context.get_by_user_id", func() error {
+		return r.db.QueryRowContext(ctx, query, userID).Scan(
+			&aiContext.ID,
+			&aiContext.UserID,
+			&aiContext.ContextSummary,
+			&preferencesJSON,
+			&tagAliasesJSON,
+			&tagWeightsJSON,
+			&summaryUpdatedAt,
+			&aiContext.SummaryChatTurns,
+			&aiContext.CreatedAt,
+			&aiContext.UpdatedAt,
+		)
+	})
 	
 	if err != nil {
 		return nil, fmt.Errorf("failed to get AI context: %w", err)
@@ -49,6 +59,19 @@ func (r *AIContextRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 			return nil, fmt.Errorf("failed to unmarshal preferences: %w", err)
 		}
 	}
+	if len(tagAliasesJSON) > 0 {
+		if err := json.Unmarshal(tagAliasesJSON, &aiContext.TagAliases); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tag aliases: %w", err)
+		}
+	}
+	if len(tagWeightsJSON) > 0 {
+		if err := json.Unmarshal(tagWeightsJSON, &aiContext.TagWeights); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tag weights: %w", err)
+		}
+	}
+	if summaryUpdatedAt.Valid {
+		aiContext.SummaryUpdatedAt = &summaryUpdatedAt.Time
+	}
 	
 	return aiContext, nil
 }
@@ -56,8 +79,8 @@ func (r *AIContextRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 // Create creates a new AI context
 func (r *AIContextRepository) Create(ctx context.Context, aiContext *models.AIContext) error {
 	query := `
-		INSERT INTO ai_context (id, user_id, context_summary, preferences, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO ai_context (id, user_id, context_summary, preferences, tag_aliases, tag_weights, summary_updated_at, summary_chat_turns, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING created_at, updated_at
 	`
 	
@@ -65,16 +88,30 @@ func (r *AIContextRepository) Create(ctx context.Context, aiContext *models.AICo
 	if err != nil {
 		return fmt.Errorf("failed to marshal preferences: %w", err)
 	}
+	tagAliasesJSON, err := marshalTagAliases(aiContext.TagAliases)
+	if err != nil {
+		return err
+	}
+	tagWeightsJSON, err := marshalTagWeights(aiContext.TagWeights)
+	if err != nil {
+		return err
+	}
 	
 	now := time.Now()
-	err = r.db.QueryRowContext(ctx, query,
-		aiContext.ID,
-		aiContext.UserID,
-		aiContext.ContextSummary,
-		preferencesJSON,
-		now,
-		now,
-	).Scan(&aiContext.CreatedAt, &aiContext.UpdatedAt)
+	err = r.db.timedQuery("ai_context.create", func() error {
+		return r.db.QueryRowContext(ctx, query,
+			aiContext.ID,
+			aiContext.UserID,
+			aiContext.ContextSummary,
+			preferencesJSON,
+			tagAliasesJSON,
+			tagWeightsJSON,
+			aiContext.SummaryUpdatedAt,
+			aiContext.SummaryChatTurns,
+			now,
+			now,
+		).Scan(&aiContext.CreatedAt, &aiContext.UpdatedAt)
+	})
 	
 	if err != nil {
 		return fmt.Errorf("failed to create AI context: %w", err)
@@ -87,7 +124,7 @@ func (r *AIContextRepository) Create(ctx context.Context, aiContext *models.AICo
 func (r *AIContextRepository) Update(ctx context.Context, aiContext *models.AIContext) error {
 	query := `
 		UPDATE ai_context
-		SET context_summary = $2, preferences = $3, updated_at = $4
+		SET context_summary = $2, preferences = $3, tag_aliases = $4, tag_weights = $5, summary_updated_at = $6, summary_chat_turns = $7, updated_at = $8
 		WHERE user_id = $1
 		RETURNING id, created_at, updated_at
 	`
@@ -96,14 +133,28 @@ func (r *AIContextRepository) Update(ctx context.Context, aiContext *models.AICo
 	if err != nil {
 		return fmt.Errorf("failed to marshal preferences: %w", err)
 	}
+	tagAliasesJSON, err := marshalTagAliases(aiContext.TagAliases)
+	if err != nil {
+		return err
+	}
+	tagWeightsJSON, err := marshalTagWeights(aiContext.TagWeights)
+	if err != nil {
+		return err
+	}
 	
 	now := time.Now()
-	err = r.db.QueryRowContext(ctx, query,
-		aiContext.UserID,
-		aiContext.ContextSummary,
-		preferencesJSON,
-		now,
-	).Scan(&aiContext.ID, &aiContext.CreatedAt, &aiContext.UpdatedAt)
+	err = r.db.timedQuery("ai_context.update", func() error {
+		return r.db.QueryRowContext(ctx, query,
+			aiContext.UserID,
+			aiContext.ContextSummary,
+			preferencesJSON,
+			tagAliasesJSON,
+			tagWeightsJSON,
+			aiContext.SummaryUpdatedAt,
+			aiContext.SummaryChatTurns,
+			now,
+		).Scan(&aiContext.ID, &aiContext.CreatedAt, &aiContext.UpdatedAt)
+	})
 	
 	if err != nil {
 		return fmt.Errorf("failed to update AI context: %w", err)
@@ -119,11 +170,15 @@ func (r *AIContextRepository) Upsert(ctx context.Context, aiContext *models.AICo
 	}
 	
 	query := `
-		INSERT INTO ai_context (id, user_id, context_summary, preferences, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO ai_context (id, user_id, context_summary, preferences, tag_aliases, tag_weights, summary_updated_at, summary_chat_turns, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		ON CONFLICT (user_id) DO UPDATE
 		SET context_summary = EXCLUDED.context_summary,
 		    preferences = EXCLUDED.preferences,
+		    tag_aliases = EXCLUDED.tag_aliases,
+		    tag_weights = EXCLUDED.tag_weights,
+		    summary_updated_at = EXCLUDED.summary_updated_at,
+		    summary_chat_turns = EXCLUDED.summary_chat_turns,
 		    updated_at = EXCLUDED.updated_at
 		RETURNING created_at, updated_at
 	`
@@ -132,16 +187,30 @@ func (r *AIContextRepository) Upsert(ctx context.Context, aiContext *models.AICo
 	if err != nil {
 		return fmt.Errorf("failed to marshal preferences: %w", err)
 	}
+	tagAliasesJSON, err := marshalTagAliases(aiContext.TagAliases)
+	if err != nil {
+		return err
+	}
+	tagWeightsJSON, err := marshalTagWeights(aiContext.TagWeights)
+	if err != nil {
+		return err
+	}
 	
 	now := time.Now()
-	err = r.db.QueryRowContext(ctx, query,
-		aiContext.ID,
-		aiContext.UserID,
-		aiContext.ContextSummary,
-		preferencesJSON,
-		now,
-		now,
-	).Scan(&aiContext.CreatedAt, &aiContext.UpdatedAt)
+	err = r.db.timedQuery("ai_context.upsert", func() error {
+		return r.db.QueryRowContext(ctx, query,
+			aiContext.ID,
+			aiContext.UserID,
+			aiContext.ContextSummary,
+			preferencesJSON,
+			tagAliasesJSON,
+			tagWeightsJSON,
+			aiContext.SummaryUpdatedAt,
+			aiContext.SummaryChatTurns,
+			now,
+			now,
+		).Scan(&aiContext.CreatedAt, &aiContext.UpdatedAt)
+	})
 	
 	if err != nil {
 		return fmt.Errorf("failed to upsert AI context: %w", err)
@@ -149,3 +218,27 @@ func (r *AIContextRepository) Upsert(ctx context.Context, aiContext *models.AICo
 	
 	return nil
 }
+
+// marshalTagAliases encodes tag aliases for the JSONB column, storing an empty object rather than null
+func marshalTagAliases(aliases map[string]string) ([]byte, error) {
+	if aliases == nil {
+		aliases = map[string]string{}
+	}
+	data, err := json.Marshal(aliases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tag aliases: %w", err)
+	}
+	return data, nil
+}
+
+// marshalTagWeights encodes tag weights for the JSONB column, storing an empty object rather than null
+func marshalTagWeights(weights map[string]float64) ([]byte, error) {
+	if weights == nil {
+		weights = map[string]float64{}
+	}
+	data, err := json.Marshal(weights)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tag weights: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+)
+
+// AIUsageRepository records the token usage of AI provider calls and sums it for cost reporting
+type AIUsageRepository struct {
+	db *DB
+}
+
+// NewAIUsageRepository creates a new AI usage repository
+func NewAIUsageRepository(db *DB) *AIUsageRepository {
+	return &AIUsageRepository{db: db}
+}
+
+// Record stores the usage of one AI provider call
+func (r *AIUsageRepository) Record(ctx context.Context, usage *models.AIUsage) error {
+	err := r.db.timedQuery("ai_usage.record", func() error {
+		return r.db.QueryRowContext(ctx, `
+			INSERT INTO ai_usage (user_id, operation, model, prompt_tokens, completion_tokens)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING created_at
+		`, usage.UserID, usage.Operation, usage.Model, usage.PromptTokens, usage.CompletionTokens).Scan(&usage.CreatedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record AI usage: %w", err)
+	}
+	return nil
+}
+
+// Totals sums the usage recorded since the given time by operation and model, and by user and model. Usage
+// not attributed to a user is only in the totals by operation.
+func (r *AIUsageRepository) Totals(ctx context.Context, since time.Time) (byOperation, byUser []models.AIUsageTotal, err error) {
+	byOperation, err = r.totals(ctx, "ai_usage.totals_by_operation", `
+		SELECT operation, model, COUNT(*), SUM(prompt_tokens), SUM(completion_tokens)
+		FROM ai_usage
+		WHERE created_at >= $1
+		GROUP BY operation, model
+	`, since, func(rows *sql.Rows, t *models.AIUsageTotal) error {
+		return rows.Scan(&t.Operation, &t.Model, &t.Calls, &t.PromptTokens, &t.CompletionTokens)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	byUser, err = r.totals(ctx, "ai_usage.totals_by_user", `
+		SELECT user_id, model, COUNT(*), SUM(prompt_tokens), SUM(completion_tokens)
+		FROM ai_usage
+		WHERE created_at >= $1 AND user_id IS NOT NULL
+		GROUP BY user_id, model
+	`, since, func(rows *sql.Rows, t *models.AIUsageTotal) error {
+		return rows.Scan(&t.UserID, &t.Model, &t.Calls, &t.PromptTokens, &t.CompletionTokens)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return byOperation, byUser, nil
+}
+
+// totals runs one grouped usage query, scanning each row with scan
+func (r *AIUsageRepository) totals(ctx context.Context, name, query string, since time.Time, scan func(*sql.Rows, *models.AIUsageTotal) error) ([]models.AIUsageTotal, error) {
+	rows, err := timedResult(r.db, name, func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query, since)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query AI usage: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var totals []models.AIUsageTotal
+	for rows.Next() {
+		var t models.AIUsageTotal
+		if err := scan(rows, &t); err != nil {
+			return nil, fmt.Errorf("failed to scan AI usage: %w", err)
+		}
+		totals = append(totals, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate AI usage: %w", err)
+	}
+	return totals, nil
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+)
+
+// APIKeyLastUsedResolution is how stale an API key's last-used time may get; authenticating with a key
+// updates it at most this often, so busy keys do not write on every request
+const APIKeyLastUsedResolution = time.Minute
+
+// APIKeyRepository stores users' API keys by hash
+type APIKeyRepository struct {
+	db *DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create stores an API key under the hash of its key, setting its ID and CreatedAt
+func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey, keyHash string) error {
+	err := r.db.timedQuery("api_keys.create", func() error {
+		return r.db.QueryRowContext(ctx, `
+			INSERT INTO api_keys (user_id, name, hint, scope, key_hash)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at
+		`, key.UserID, key.Name, key.Hint, key.Scope, keyHash).Scan(&key.ID, &key.CreatedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	return nil
+}
+
+// Authenticate returns the unrevoked API key with the given hash and records that it was used. It returns
+// an error wrapping sql.ErrNoRows when no such key exists.
+func (r *APIKeyRepository) Authenticate(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.timedQuery("api_keys.authenticate", func() error {
+		return r.db.QueryRowContext(ctx, `
+			WITH key AS (
+				SELECT id, user_id, name, hint, scope, created_at, last_used_at
+				FROM api_keys
+				WHERE key_hash = $1 AND revoked_at IS NULL
+			), touched AS (
+				UPDATE api_keys SET last_used_at = $2
+				WHERE id = (SELECT id FROM key) AND (last_used_at IS NULL OR last_used_at < $3)
+			)
+			SELECT id, user_id, name, hint, scope, created_at, last_used_at FROM key
+		`, keyHash, time.Now(), time.Now().Add(-APIKeyLastUsedResolution)).Scan(
+			&key.ID, &key.UserID, &key.Name, &key.Hint, &key.Scope, &key.CreatedAt, &key.LastUsedAt,
+		)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("API key not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate API key: %w", err)
+	}
+	return &key, nil
+}
+
+// ListByUser returns the user's unrevoked API keys, newest first
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	rows, err := timedResult(r.db, "api_keys.list_by_user", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, `
+			SELECT id, user_id, name, hint, scope, created_at, last_used_at
+			FROM api_keys
+			WHERE user_id = $1 AND revoked_at IS NULL
+			ORDER BY created_at DESC, id DESC
+		`, userID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	keys := []*models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		if err := rows.Scan(&key.ID, &key.UserID, &key.Name, &key.Hint, &key.Scope, &key.CreatedAt, &key.LastUsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, &key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke revokes the user's API key, reporting false when the user has no such unrevoked key. Revoked keys
+// are kept, with when they were revoked, but no longer authenticate.
+func (r *APIKeyRepository) Revoke(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	result, err := timedResult(r.db, "api_keys.revoke", func() (sql.Result, error) {
+		return r.db.ExecContext(ctx, `
+			UPDATE api_keys SET revoked_at = NOW()
+			WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+		`, id, userID)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to count revoked API keys: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+)
+
+// AuditRepository stores the security events recorded by the audit middleware
+type AuditRepository struct {
+	db *DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Record stores an audit event, setting its ID and CreatedAt
+func (r *AuditRepository) Record(ctx context.Context, event *models.AuditEvent) error {
+	err := r.db.timedQuery("audit_events.record", func() error {
+		return r.db.QueryRowContext(ctx, `
+			INSERT INTO audit_events (user_id, action, method, resource, ip, status_code)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at
+		`, event.UserID, string(event.Action), event.Method, event.Resource, event.IP, event.StatusCode).Scan(&event.ID, &event.CreatedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// DeleteOlderThan deletes the events recorded more than age ago and returns how many were deleted
+func (r *AuditRepository) DeleteOlderThan(ctx context.Context, age time.Duration) (int64, error) {
+	result, err := timedResult(r.db, "audit_events.delete_older_than", func() (sql.Result, error) {
+		return r.db.ExecContext(ctx, `DELETE FROM audit_events WHERE created_at < $1`, time.Now().Add(-age))
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete audit events: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deleted audit events: %w", err)
+	}
+	return n, nil
+}
+
+// List retrieves a page of the audit events matching filter, newest first, along with the total number of
+// matches
+func (r *AuditRepository) List(ctx context.Context, filter models.AuditEventFilter, page, pageSize int) ([]*models.AuditEvent, int, error) {
+	whereClause, args := buildAuditWhereClause(filter)
+
+	var total int
+	if err := r.db.timedQuery("audit_events.count", func() error {
+		return r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events "+whereClause, args...).Scan(&total)
+	}); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, action, method, resource, ip, status_code, created_at
+		FROM audit_events
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
+	args = append(args, pageSize, (page-1)*pageSize)
+
+	rows, err := timedResult(r.db, "audit_events.list", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query, args...)
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	events := []*models.AuditEvent{}
+	for rows.Next() {
+		var event models.AuditEvent
+		if err := rows.Scan(&event.ID, &event.UserID, &event.Action, &event.Method, &event.Resource, &event.IP, &event.StatusCode, &event.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate audit events: %w", err)
+	}
+	return events, total, nil
+}
+
+// buildAuditWhereClause returns the WHERE clause selecting filter's events and its arguments
+func buildAuditWhereClause(filter models.AuditEventFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	if filter.Action != "" {
+		args = append(args, string(filter.Action))
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, filter.Until)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
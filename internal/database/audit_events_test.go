@@ -0,0 +1,56 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+)
+
+func TestBuildAuditWhereClause(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		filter    models.AuditEventFilter
+		wantWhere string
+		wantArgs  []any
+	}{
+		{name: "no filters"},
+		{
+			name:      "action",
+			filter:    models.AuditEventFilter{Action: models.AuditActionForbidden},
+			wantWhere: "WHERE action = $1",
+			wantArgs:  []any{"forbidden"},
+		},
+		{
+			name:      "date range",
+			filter:    models.AuditEventFilter{Since: since, Until: until},
+			wantWhere: "WHERE created_at >= $1 AND created_at < $2",
+			wantArgs:  []any{since, until},
+		},
+		{
+			name:      "action since",
+			filter:    models.AuditEventFilter{Action: models.AuditActionRateLimited, Since: since},
+			wantWhere: "WHERE action = $1 AND created_at >= $2",
+			wantArgs:  []any{"rate_limited", since},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			where, args := buildAuditWhereClause(tt.filter)
+			if where != tt.wantWhere {
+				t.Errorf("where = %q, want %q", where, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -24,19 +24,20 @@ func NewCorsConfigRepository(db *DB) *CorsConfigRepository {
 
 // Get retrieves the default CORS config.
 func (r *CorsConfigRepository) Get(ctx context.Context) (*models.CorsConfig, error) {
-	row := r.db.QueryRowContext(ctx, `
-		SELECT config_key, allowed_origins, allow_credentials, max_age, created_at, updated_at
-		FROM cors_config WHERE config_key = $1
-	`, defaultCorsConfigKey)
 	c := &models.CorsConfig{}
-	err := row.Scan(
-		&c.ConfigKey,
-		&c.AllowedOrigins,
-		&c.AllowCredentials,
-		&c.MaxAge,
-		&c.CreatedAt,
-		&c.UpdatedAt,
-	)
+	err := r.db.timedQuery("cors_config.get", func() error {
+		return r.db.QueryRowContext(ctx, `
+			SELECT config_key, allowed_origins, allow_credentials, max_age, created_at, updated_at
+			FROM cors_config WHERE config_key = $1
+		`, defaultCorsConfigKey).Scan(
+			&c.ConfigKey,
+			&c.AllowedOrigins,
+			&c.AllowCredentials,
+			&c.MaxAge,
+			&c.CreatedAt,
+			&c.UpdatedAt,
+		)
+	})
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -52,15 +53,17 @@ func (r *CorsConfigRepository) Set(ctx context.Context, c *models.CorsConfig) er
 		return fmt.Errorf("allowed_origins cannot be empty")
 	}
 	now := time.Now()
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO cors_config (config_key, allowed_origins, allow_credentials, max_age, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (config_key) DO UPDATE SET
-			allowed_origins = EXCLUDED.allowed_origins,
-			allow_credentials = EXCLUDED.allow_credentials,
-			max_age = EXCLUDED.max_age,
-			updated_at = EXCLUDED.updated_at
-	`, defaultCorsConfigKey, strings.TrimSpace(c.AllowedOrigins), c.AllowCredentials, c.MaxAge, now, now)
+	_, err := timedResult(r.db, "cors_config.set", func() (sql.Result, error) {
+		return r.db.ExecContext(ctx, `
+			INSERT INTO cors_config (config_key, allowed_origins, allow_credentials, max_age, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (config_key) DO UPDATE SET
+				allowed_origins = EXCLUDED.allowed_origins,
+				allow_credentials = EXCLUDED.allow_credentials,
+				max_age = EXCLUDED.max_age,
+				updated_at = EXCLUDED.updated_at
+		`, defaultCorsConfigKey, strings.TrimSpace(c.AllowedOrigins), c.AllowCredentials, c.MaxAge, now, now)
+	})
 	if err != nil {
 		return fmt.Errorf("set cors config: %w", err)
 	}
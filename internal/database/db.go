@@ -1,20 +1,34 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"sync/atomic"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/benvon/smart-todo/internal/metrics"
+	"github.com/benvon/smart-todo/internal/tlsconfig"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
 )
 
 // DB wraps the database connection
 type DB struct {
 	*sql.DB
+	slowQueryThreshold time.Duration
+	logger             *zap.Logger
 }
 
 // New creates a new database connection
 func New(databaseURL string) (*DB, error) {
-	db, err := sql.Open("postgres", databaseURL)
+	return NewWithTLS(databaseURL, tlsconfig.Options{})
+}
+
+// NewWithTLS creates a new database connection using the given client TLS options.
+// With zero options the sslmode from the URL applies unchanged.
+func NewWithTLS(databaseURL string, tlsOpts tlsconfig.Options) (*DB, error) {
+	db, err := openPostgres(databaseURL, tlsOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -23,10 +37,98 @@ func New(databaseURL string) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &DB{db}, nil
+	return &DB{DB: db}, nil
+}
+
+// openPostgres opens the pool, registering a custom tls.Config with lib/pq when TLS options are set
+func openPostgres(databaseURL string, tlsOpts tlsconfig.Options) (*sql.DB, error) {
+	tlsCfg, err := tlsOpts.Build()
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg == nil {
+		return sql.Open("postgres", databaseURL)
+	}
+
+	pqCfg, err := pq.NewConfig(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("smart-todo-%d", tlsConfigSeq.Add(1))
+	if err := pq.RegisterTLSConfig(key, tlsCfg); err != nil {
+		return nil, err
+	}
+	pqCfg.SSLMode = pq.SSLMode("pqgo-" + key)
+
+	connector, err := pq.NewConnectorConfig(pqCfg)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// tlsConfigSeq keeps lib/pq TLS registration keys unique per connection pool
+var tlsConfigSeq atomic.Uint64
+
+// SetSlowQueryLog logs repository queries that take at least threshold at warn level (0 disables).
+// Call before the DB is shared with repositories.
+func (db *DB) SetSlowQueryLog(threshold time.Duration, logger *zap.Logger) {
+	db.slowQueryThreshold = threshold
+	db.logger = logger
+}
+
+// timedQuery runs fn and, when it takes at least the slow-query threshold, logs and counts it under name.
+// Only the name (e.g. "todos.get_by_id") is recorded, never the SQL or its arguments, so no user data leaks.
+func (db *DB) timedQuery(name string, fn func() error) error {
+	defer db.observeQuery(name, time.Now())
+	return fn()
+}
+
+// timedResult is timedQuery for calls that return a value, such as *sql.Rows or sql.Result
+func timedResult[T any](db *DB, name string, fn func() (T, error)) (T, error) {
+	defer db.observeQuery(name, time.Now())
+	return fn()
+}
+
+// observeQuery logs and counts the query if it ran for at least the slow-query threshold since start.
+// Transactions defer it directly to be timed as a whole.
+func (db *DB) observeQuery(name string, start time.Time) {
+	if db.slowQueryThreshold <= 0 || db.logger == nil {
+		return
+	}
+	elapsed := time.Since(start)
+	if elapsed < db.slowQueryThreshold {
+		return
+	}
+	db.logger.Warn("slow_query",
+		zap.String("query", name),
+		zap.Int64("duration_ms", elapsed.Milliseconds()),
+		zap.Int64("threshold_ms", db.slowQueryThreshold.Milliseconds()),
+	)
+	metrics.DBSlowQueries.Inc(name)
+}
+
+// tryAdvisoryXactLock takes the transaction-scoped Postgres advisory lock for key without waiting. It reports
+// false when another session holds the lock; a held lock is released when tx commits or rolls back, so it
+// coordinates work across every API and worker process sharing the database.
+func tryAdvisoryXactLock(ctx context.Context, tx *sql.Tx, key int64) (bool, error) {
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, key).Scan(&locked); err != nil {
+		return false, fmt.Errorf("failed to take advisory lock: %w", err)
+	}
+	return locked, nil
 }
 
 // Close closes the database connection
 func (db *DB) Close() error {
 	return db.DB.Close()
 }
+
+// DependencyInfo reports the PostgreSQL server version
+func (db *DB) DependencyInfo(ctx context.Context) (map[string]string, error) {
+	var version string
+	if err := db.QueryRowContext(ctx, "SHOW server_version").Scan(&version); err != nil {
+		return nil, fmt.Errorf("failed to query server version: %w", err)
+	}
+	return map[string]string{"version": version}, nil
+}
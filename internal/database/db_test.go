@@ -0,0 +1,86 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/metrics"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDB_TimedQuery_SlowQueryLog(t *testing.T) {
+	t.Parallel()
+
+	errQuery := errors.New("query failed")
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		sleep     time.Duration
+		err       error
+		wantLog   bool
+	}{
+		{name: "slow query is logged", threshold: 5 * time.Millisecond, sleep: 20 * time.Millisecond, wantLog: true},
+		{name: "slow failing query is logged", threshold: 5 * time.Millisecond, sleep: 20 * time.Millisecond, err: errQuery, wantLog: true},
+		{name: "fast query is not logged", threshold: time.Second, wantLog: false},
+		{name: "disabled threshold", threshold: 0, sleep: 20 * time.Millisecond, wantLog: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			core, logs := observer.New(zapcore.WarnLevel)
+			db := &DB{}
+			db.SetSlowQueryLog(tt.threshold, zap.New(core))
+
+			err := db.timedQuery("todos.get_by_id", func() error {
+				time.Sleep(tt.sleep)
+				return tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("timedQuery() error = %v, want %v", err, tt.err)
+			}
+
+			entries := logs.FilterMessage("slow_query").All()
+			if got := len(entries) == 1; got != tt.wantLog {
+				t.Fatalf("slow_query logs = %d, want logged = %v", len(entries), tt.wantLog)
+			}
+			if !tt.wantLog {
+				return
+			}
+			fields := entries[0].ContextMap()
+			if fields["query"] != "todos.get_by_id" {
+				t.Errorf("query = %v, want todos.get_by_id", fields["query"])
+			}
+			if ms, _ := fields["duration_ms"].(int64); ms < tt.sleep.Milliseconds() {
+				t.Errorf("duration_ms = %v, want at least %d", fields["duration_ms"], tt.sleep.Milliseconds())
+			}
+		})
+	}
+}
+
+func TestTimedResult_SlowQueryLog(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	db := &DB{}
+	db.SetSlowQueryLog(time.Millisecond, zap.New(core))
+	before := metrics.DBSlowQueries.Value("todos.list_by_user_id")
+
+	got, err := timedResult(db, "todos.list_by_user_id", func() (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 42, nil
+	})
+	if err != nil || got != 42 {
+		t.Fatalf("timedResult() = %d, %v; want 42, nil", got, err)
+	}
+	if logs.FilterMessage("slow_query").FilterField(zap.String("query", "todos.list_by_user_id")).Len() != 1 {
+		t.Errorf("expected one slow_query log for todos.list_by_user_id, got %v", logs.All())
+	}
+	if got := metrics.DBSlowQueries.Value("todos.list_by_user_id") - before; got != 1 {
+		t.Errorf("slow query counter rose by %v, want 1", got)
+	}
+}
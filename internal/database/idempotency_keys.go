@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyTTL is how long an Idempotency-Key maps a retried todo creation to the original todo. Older
+// keys are ignored by lookups and deleted by the worker's garbage collector.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// IdempotencyRepository stores the todo created for each user's Idempotency-Key
+type IdempotencyRepository struct {
+	db *DB
+}
+
+// NewIdempotencyRepository creates a new idempotency key repository
+func NewIdempotencyRepository(db *DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// GetTodoID returns the todo created with the user's key, or ok=false when the key is unused or older than
+// IdempotencyKeyTTL
+func (r *IdempotencyRepository) GetTodoID(ctx context.Context, userID uuid.UUID, key string) (todoID uuid.UUID, ok bool, err error) {
+	err = r.db.timedQuery("idempotency_keys.get_todo_id", func() error {
+		return r.db.QueryRowContext(ctx, `
+			SELECT todo_id FROM idempotency_keys
+			WHERE user_id = $1 AND key = $2 AND created_at > $3
+		`, userID, key, time.Now().Add(-IdempotencyKeyTTL)).Scan(&todoID)
+	})
+	if err == sql.ErrNoRows {
+		return uuid.Nil, false, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return todoID, true, nil
+}
+
+// CreateTodo creates todo and saves the user's key for it in one transaction. An expired key is taken over.
+// When a live key exists, e.g. saved by a concurrent creation with the same key that won the race, nothing
+// is created and the todo that key created is returned with created=false.
+func (r *IdempotencyRepository) CreateTodo(ctx context.Context, userID uuid.UUID, key string, todo *models.Todo) (todoID uuid.UUID, created bool, err error) {
+	defer r.db.observeQuery("idempotency_keys.create_todo", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := insertTodo(ctx, tx, todo); err != nil {
+		return uuid.Nil, false, err
+	}
+	// A concurrent insert of the same key blocks this one until it commits, then conflicts
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (user_id, key, todo_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, key) DO UPDATE SET todo_id = EXCLUDED.todo_id, created_at = NOW()
+		WHERE idempotency_keys.created_at <= $4
+	`, userID, key, todo.ID, time.Now().Add(-IdempotencyKeyTTL))
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to count saved idempotency keys: %w", err)
+	}
+	if n == 0 {
+		// The key is live; roll the todo back and replay the one it created
+		err := tx.QueryRowContext(ctx, `
+			SELECT todo_id FROM idempotency_keys WHERE user_id = $1 AND key = $2
+		`, userID, key).Scan(&todoID)
+		if err != nil {
+			return uuid.Nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+		return todoID, false, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return todo.ID, true, nil
+}
+
+// DeleteOlderThan deletes the keys saved more than age ago and returns how many were deleted
+func (r *IdempotencyRepository) DeleteOlderThan(ctx context.Context, age time.Duration) (int64, error) {
+	result, err := timedResult(r.db, "idempotency_keys.delete_older_than", func() (sql.Result, error) {
+		return r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at <= $1`, time.Now().Add(-age))
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete idempotency keys: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deleted idempotency keys: %w", err)
+	}
+	return n, nil
+}
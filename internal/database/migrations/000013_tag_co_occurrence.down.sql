@@ -0,0 +1 @@
+ALTER TABLE tag_statistics DROP COLUMN IF EXISTS co_occurrence;
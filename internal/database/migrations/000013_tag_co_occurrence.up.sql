@@ -0,0 +1,2 @@
+-- Store the strongest co-occurring tag pairs alongside per-tag statistics
+ALTER TABLE tag_statistics ADD COLUMN co_occurrence JSONB NOT NULL DEFAULT '[]';
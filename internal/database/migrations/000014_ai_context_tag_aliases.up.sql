@@ -0,0 +1,2 @@
+-- Per-user tag aliases (alias -> canonical tag) applied when storing AI-suggested tags
+ALTER TABLE ai_context ADD COLUMN tag_aliases JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+-- Drop todo_history table
+DROP TABLE IF EXISTS todo_history;
@@ -0,0 +1,11 @@
+-- Field-level change records for todos (text, tags, time horizon, status, due date), pruned per todo
+CREATE TABLE todo_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor VARCHAR(20) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_todo_history_todo_id_created_at ON todo_history(todo_id, created_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE ai_context DROP COLUMN IF EXISTS tag_weights;
@@ -0,0 +1,2 @@
+-- Per-user tag weights (tag -> multiplier) that raise or lower a tag's priority in the analysis prompt
+ALTER TABLE ai_context ADD COLUMN tag_weights JSONB NOT NULL DEFAULT '{}';
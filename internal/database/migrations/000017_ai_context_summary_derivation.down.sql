@@ -0,0 +1,2 @@
+ALTER TABLE ai_context DROP COLUMN IF EXISTS summary_chat_turns;
+ALTER TABLE ai_context DROP COLUMN IF EXISTS summary_updated_at;
@@ -0,0 +1,3 @@
+-- Track when the AI context summary was last rewritten and how many chat turns it was derived from
+ALTER TABLE ai_context ADD COLUMN summary_updated_at TIMESTAMP;
+ALTER TABLE ai_context ADD COLUMN summary_chat_turns INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_todos_retention_candidates;
+ALTER TABLE todos DROP COLUMN IF EXISTS trashed_at;
+ALTER TABLE todos DROP COLUMN IF EXISTS archived_at;
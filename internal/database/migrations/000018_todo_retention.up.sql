@@ -0,0 +1,5 @@
+-- Completed todos retired by the retention sweep are archived or trashed rather than deleted
+ALTER TABLE todos ADD COLUMN archived_at TIMESTAMP;
+ALTER TABLE todos ADD COLUMN trashed_at TIMESTAMP;
+
+CREATE INDEX idx_todos_retention_candidates ON todos(completed_at) WHERE status = 'completed' AND trashed_at IS NULL;
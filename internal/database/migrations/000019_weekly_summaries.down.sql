@@ -0,0 +1,2 @@
+ALTER TABLE user_activity DROP COLUMN IF EXISTS weekly_summary_week;
+DROP TABLE IF EXISTS weekly_summaries;
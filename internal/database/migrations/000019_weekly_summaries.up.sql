@@ -0,0 +1,13 @@
+-- Weekly AI summaries of what each user completed and what is still open, one per user per week
+CREATE TABLE weekly_summaries (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    week_start DATE NOT NULL,
+    summary TEXT NOT NULL,
+    completed_count INTEGER NOT NULL DEFAULT 0,
+    pending_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, week_start)
+);
+
+-- The week a summary was last scheduled for, so each user is enqueued at most once per week
+ALTER TABLE user_activity ADD COLUMN weekly_summary_week DATE;
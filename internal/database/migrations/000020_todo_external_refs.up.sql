@@ -0,0 +1,2 @@
+-- Look up todos by the external items (GitHub issues, Jira tickets) they link to
+CREATE INDEX idx_todos_external_refs ON todos USING GIN ((metadata->'external_refs') jsonb_path_ops);
@@ -0,0 +1,12 @@
+-- Token usage of each AI provider call, for cost attribution by operation and user
+CREATE TABLE ai_usage (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    operation VARCHAR(64) NOT NULL,
+    model VARCHAR(255) NOT NULL,
+    prompt_tokens BIGINT NOT NULL DEFAULT 0,
+    completion_tokens BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_usage_created_at ON ai_usage(created_at);
@@ -0,0 +1,8 @@
+-- Explicit user priority, independent of the AI-managed time horizon; NULL when the user has not set one
+CREATE TYPE todo_priority AS ENUM ('low', 'medium', 'high', 'urgent');
+
+ALTER TABLE todos ADD COLUMN priority todo_priority;
+
+-- Carry over the free-form metadata priority where it names a valid priority
+UPDATE todos SET priority = LOWER(TRIM(metadata->>'priority'))::todo_priority
+WHERE LOWER(TRIM(metadata->>'priority')) IN ('low', 'medium', 'high', 'urgent');
@@ -0,0 +1,2 @@
+-- Hash of the tag inputs the statistics were last aggregated from, so unchanged inputs skip the rewrite
+ALTER TABLE tag_statistics ADD COLUMN content_hash TEXT;
@@ -0,0 +1,14 @@
+-- Security events recorded by the audit middleware, kept for compliance reviews
+CREATE TABLE audit_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(64) NOT NULL,
+    method VARCHAR(16) NOT NULL,
+    resource TEXT NOT NULL,
+    ip VARCHAR(64) NOT NULL,
+    status_code INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at);
+CREATE INDEX idx_audit_events_action_created_at ON audit_events(action, created_at);
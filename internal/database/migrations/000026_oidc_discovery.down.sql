@@ -0,0 +1,3 @@
+ALTER TABLE oidc_config DROP COLUMN IF EXISTS audiences;
+ALTER TABLE oidc_config DROP COLUMN IF EXISTS token_endpoint;
+ALTER TABLE oidc_config DROP COLUMN IF EXISTS authorization_endpoint;
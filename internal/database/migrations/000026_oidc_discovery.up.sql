@@ -0,0 +1,4 @@
+-- Endpoints read from the issuer's OpenID discovery document, and extra token audiences accepted besides the client ID
+ALTER TABLE oidc_config ADD COLUMN authorization_endpoint TEXT;
+ALTER TABLE oidc_config ADD COLUMN token_endpoint TEXT;
+ALTER TABLE oidc_config ADD COLUMN audiences TEXT[] NOT NULL DEFAULT '{}';
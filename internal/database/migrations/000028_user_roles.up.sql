@@ -0,0 +1,2 @@
+-- Authorization role of each user; existing users are regular users
+ALTER TABLE users ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin'));
@@ -0,0 +1,2 @@
+-- What requests an API key may make: read keys may only read, full keys may do anything their user can
+ALTER TABLE api_keys ADD COLUMN scope VARCHAR(16) NOT NULL DEFAULT 'full' CHECK (scope IN ('read', 'full'));
@@ -0,0 +1,2 @@
+-- Free-form values that were not a valid priority cannot be restored
+UPDATE todos SET metadata = jsonb_set(metadata, '{priority}', to_jsonb(priority::text)) WHERE priority IS NOT NULL;
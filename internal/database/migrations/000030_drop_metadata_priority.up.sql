@@ -0,0 +1,6 @@
+-- The priority column replaces the free-form metadata priority. Carry over valid values written since the
+-- column was added, then drop the metadata key so the two cannot disagree.
+UPDATE todos SET priority = LOWER(TRIM(metadata->>'priority'))::todo_priority
+WHERE priority IS NULL AND LOWER(TRIM(metadata->>'priority')) IN ('low', 'medium', 'high', 'urgent');
+
+UPDATE todos SET metadata = metadata - 'priority' WHERE metadata ? 'priority';
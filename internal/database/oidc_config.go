@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/benvon/smart-todo/internal/models"
+	"github.com/lib/pq"
 )
 
 // OIDCConfigRepository handles OIDC configuration database operations
@@ -22,24 +23,29 @@ func NewOIDCConfigRepository(db *DB) *OIDCConfigRepository {
 // Create creates a new OIDC configuration
 func (r *OIDCConfigRepository) Create(ctx context.Context, config *models.OIDCConfig) error {
 	query := `
-		INSERT INTO oidc_config (id, provider, issuer, domain, client_id, client_secret, redirect_uri, jwks_url, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO oidc_config (id, provider, issuer, domain, client_id, client_secret, redirect_uri, jwks_url, authorization_endpoint, token_endpoint, audiences, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING created_at, updated_at
 	`
 	
 	now := time.Now()
-	err := r.db.QueryRowContext(ctx, query,
-		config.ID,
-		config.Provider,
-		config.Issuer,
-		config.Domain,
-		config.ClientID,
-		config.ClientSecret,
-		config.RedirectURI,
-		config.JWKSUrl,
-		now,
-		now,
-	).Scan(&config.CreatedAt, &config.UpdatedAt)
+	err := r.db.timedQuery("oidc_config.create", func() error {
+		return r.db.QueryRowContext(ctx, query,
+			config.ID,
+			config.Provider,
+			config.Issuer,
+			config.Domain,
+			config.ClientID,
+			config.ClientSecret,
+			config.RedirectURI,
+			config.JWKSUrl,
+			config.AuthorizationEndpoint,
+			config.TokenEndpoint,
+			pq.Array(nonNilStrings(config.Audiences)),
+			now,
+			now,
+		).Scan(&config.CreatedAt, &config.UpdatedAt)
+	})
 	
 	if err != nil {
 		return fmt.Errorf("failed to create OIDC config: %w", err)
@@ -52,23 +58,28 @@ func (r *OIDCConfigRepository) Create(ctx context.Context, config *models.OIDCCo
 func (r *OIDCConfigRepository) GetByProvider(ctx context.Context, provider string) (*models.OIDCConfig, error) {
 	config := &models.OIDCConfig{}
 	query := `
-		SELECT id, provider, issuer, domain, client_id, client_secret, redirect_uri, jwks_url, created_at, updated_at
+		SELECT id, provider, issuer, domain, client_id, client_secret, redirect_uri, jwks_url, authorization_endpoint, token_endpoint, audiences, created_at, updated_at
 		FROM oidc_config
 		WHERE provider = $1
 	`
 	
-	err := r.db.QueryRowContext(ctx, query, provider).Scan(
-		&config.ID,
-		&config.Provider,
-		&config.Issuer,
-		&config.Domain,
-		&config.ClientID,
-		&config.ClientSecret,
-		&config.RedirectURI,
-		&config.JWKSUrl,
-		&config.CreatedAt,
-		&config.UpdatedAt,
-	)
+	err := r.db.timedQuery("oidc_config.get_by_provider", func() error {
+		return r.db.QueryRowContext(ctx, query, provider).Scan(
+			&config.ID,
+			&config.Provider,
+			&config.Issuer,
+			&config.Domain,
+			&config.ClientID,
+			&config.ClientSecret,
+			&config.RedirectURI,
+			&config.JWKSUrl,
+			&config.AuthorizationEndpoint,
+			&config.TokenEndpoint,
+			pq.Array(&config.Audiences),
+			&config.CreatedAt,
+			&config.UpdatedAt,
+		)
+	})
 	
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("OIDC config not found for provider %s: %w", provider, err)
@@ -83,12 +94,14 @@ func (r *OIDCConfigRepository) GetByProvider(ctx context.Context, provider strin
 // GetAll retrieves all OIDC configurations
 func (r *OIDCConfigRepository) GetAll(ctx context.Context) ([]*models.OIDCConfig, error) {
 	query := `
-		SELECT id, provider, issuer, domain, client_id, client_secret, redirect_uri, jwks_url, created_at, updated_at
+		SELECT id, provider, issuer, domain, client_id, client_secret, redirect_uri, jwks_url, authorization_endpoint, token_endpoint, audiences, created_at, updated_at
 		FROM oidc_config
 		ORDER BY provider
 	`
 	
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := timedResult(r.db, "oidc_config.get_all", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to query OIDC configs: %w", err)
 	}
@@ -113,6 +126,9 @@ func (r *OIDCConfigRepository) GetAll(ctx context.Context) ([]*models.OIDCConfig
 			&config.ClientSecret,
 			&config.RedirectURI,
 			&config.JWKSUrl,
+			&config.AuthorizationEndpoint,
+			&config.TokenEndpoint,
+			pq.Array(&config.Audiences),
 			&config.CreatedAt,
 			&config.UpdatedAt,
 		)
@@ -133,22 +149,28 @@ func (r *OIDCConfigRepository) GetAll(ctx context.Context) ([]*models.OIDCConfig
 func (r *OIDCConfigRepository) Update(ctx context.Context, config *models.OIDCConfig) error {
 	query := `
 		UPDATE oidc_config
-		SET issuer = $2, domain = $3, client_id = $4, client_secret = $5, redirect_uri = $6, jwks_url = $7, updated_at = $8
+		SET issuer = $2, domain = $3, client_id = $4, client_secret = $5, redirect_uri = $6, jwks_url = $7,
+			authorization_endpoint = $8, token_endpoint = $9, audiences = $10, updated_at = $11
 		WHERE provider = $1
 		RETURNING updated_at
 	`
 	
 	now := time.Now()
-	err := r.db.QueryRowContext(ctx, query,
-		config.Provider,
-		config.Issuer,
-		config.Domain,
-		config.ClientID,
-		config.ClientSecret,
-		config.RedirectURI,
-		config.JWKSUrl,
-		now,
-	).Scan(&config.UpdatedAt)
+	err := r.db.timedQuery("oidc_config.update", func() error {
+		return r.db.QueryRowContext(ctx, query,
+			config.Provider,
+			config.Issuer,
+			config.Domain,
+			config.ClientID,
+			config.ClientSecret,
+			config.RedirectURI,
+			config.JWKSUrl,
+			config.AuthorizationEndpoint,
+			config.TokenEndpoint,
+			pq.Array(nonNilStrings(config.Audiences)),
+			now,
+		).Scan(&config.UpdatedAt)
+	})
 	
 	if err == sql.ErrNoRows {
 		return fmt.Errorf("OIDC config not found")
@@ -164,7 +186,9 @@ func (r *OIDCConfigRepository) Update(ctx context.Context, config *models.OIDCCo
 func (r *OIDCConfigRepository) Delete(ctx context.Context, provider string) error {
 	query := `DELETE FROM oidc_config WHERE provider = $1`
 	
-	result, err := r.db.ExecContext(ctx, query, provider)
+	result, err := timedResult(r.db, "oidc_config.delete", func() (sql.Result, error) {
+		return r.db.ExecContext(ctx, query, provider)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete OIDC config: %w", err)
 	}
@@ -180,3 +204,11 @@ func (r *OIDCConfigRepository) Delete(ctx context.Context, provider string) erro
 	
 	return nil
 }
+
+// nonNilStrings returns s, or an empty slice for nil so NOT NULL array columns store '{}'
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
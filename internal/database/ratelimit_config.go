@@ -24,12 +24,13 @@ func NewRatelimitConfigRepository(db *DB) *RatelimitConfigRepository {
 
 // Get retrieves the default rate limit config.
 func (r *RatelimitConfigRepository) Get(ctx context.Context) (*models.RatelimitConfig, error) {
-	row := r.db.QueryRowContext(ctx, `
-		SELECT config_key, rate, created_at, updated_at
-		FROM ratelimit_config WHERE config_key = $1
-	`, defaultRatelimitConfigKey)
 	c := &models.RatelimitConfig{}
-	err := row.Scan(&c.ConfigKey, &c.Rate, &c.CreatedAt, &c.UpdatedAt)
+	err := r.db.timedQuery("ratelimit_config.get", func() error {
+		return r.db.QueryRowContext(ctx, `
+			SELECT config_key, rate, created_at, updated_at
+			FROM ratelimit_config WHERE config_key = $1
+		`, defaultRatelimitConfigKey).Scan(&c.ConfigKey, &c.Rate, &c.CreatedAt, &c.UpdatedAt)
+	})
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -46,13 +47,15 @@ func (r *RatelimitConfigRepository) Set(ctx context.Context, c *models.Ratelimit
 		return fmt.Errorf("rate cannot be empty")
 	}
 	now := time.Now()
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO ratelimit_config (config_key, rate, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (config_key) DO UPDATE SET
-			rate = EXCLUDED.rate,
-			updated_at = EXCLUDED.updated_at
-	`, defaultRatelimitConfigKey, rate, now, now)
+	_, err := timedResult(r.db, "ratelimit_config.set", func() (sql.Result, error) {
+		return r.db.ExecContext(ctx, `
+			INSERT INTO ratelimit_config (config_key, rate, created_at, updated_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (config_key) DO UPDATE SET
+				rate = EXCLUDED.rate,
+				updated_at = EXCLUDED.updated_at
+		`, defaultRatelimitConfigKey, rate, now, now)
+	})
 	if err != nil {
 		return fmt.Errorf("set ratelimit config: %w", err)
 	}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/benvon/smart-todo/internal/models"
 	"github.com/google/uuid"
@@ -10,11 +11,27 @@ import (
 // TodoRepositoryInterface defines the interface for todo repository operations
 // This interface enables better testability by allowing mock implementations
 type TodoRepositoryInterface interface {
+	Create(ctx context.Context, todo *models.Todo) error
+	CreateBatch(ctx context.Context, todos []*models.Todo) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Todo, error)
 	GetByUserIDAndID(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Todo, error)
 	Update(ctx context.Context, todo *models.Todo, oldTags []string) error
 	Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
 	GetByUserIDPaginated(ctx context.Context, userID uuid.UUID, timeHorizon *models.TimeHorizon, status *models.TodoStatus, page, pageSize int) ([]*models.Todo, int, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID, filter TodoListFilter, page, pageSize int) ([]*models.Todo, int, error)
+	ListByUserIDAfter(ctx context.Context, userID uuid.UUID, filter TodoListFilter, after *TodoCursor, limit int) ([]*models.Todo, error)
+	ListOpenByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*models.Todo, error)
+	CountByStatusAndHorizon(ctx context.Context, userID uuid.UUID) (*models.TodoCounts, error)
+	GetDueWithin(ctx context.Context, window time.Duration) ([]*models.Todo, error)
+	MarkReminderSent(ctx context.Context, userID, id uuid.UUID, dueDate, sentAt time.Time) (bool, error)
+	GetByExternalRef(ctx context.Context, userID uuid.UUID, system, id string) (*models.Todo, error)
+	ResetAITags(ctx context.Context, userID uuid.UUID, requeue bool) (int, error)
+	RenameTag(ctx context.Context, userID uuid.UUID, from []string, to string) (int, error)
+	RemoveTagFromAll(ctx context.Context, userID uuid.UUID, tag string) (int, error)
+	BulkUpdateDueDates(ctx context.Context, userID uuid.UUID, sel TodoBulkSelection, apply func(todo *models.Todo) bool) ([]*models.Todo, error)
+	BulkUpdateStatus(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, status models.TodoStatus) ([]uuid.UUID, error)
+	BulkDelete(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]uuid.UUID, error)
+	MergeTodos(ctx context.Context, userID, targetID, sourceID uuid.UUID, merge func(target, source *models.Todo) error) (*models.Todo, error)
 	SetTagStatsRepo(repo TagStatisticsRepositoryInterface) // Optional: for tag change detection
 	SetTagChangeHandler(handler TagChangeHandler)          // Optional: callback when tags change
 }
@@ -38,10 +55,45 @@ type TagStatisticsRepositoryInterface interface {
 	MarkTainted(ctx context.Context, userID uuid.UUID) (bool, error)
 }
 
+// TodoHistoryRepositoryInterface defines the interface for reading todo change history
+type TodoHistoryRepositoryInterface interface {
+	ListByTodo(ctx context.Context, userID uuid.UUID, todoID uuid.UUID) ([]*models.TodoHistoryEntry, error)
+}
+
+// AIUsageRepositoryInterface defines the interface for reading recorded AI usage
+type AIUsageRepositoryInterface interface {
+	Totals(ctx context.Context, since time.Time) (byOperation, byUser []models.AIUsageTotal, err error)
+}
+
+// IdempotencyRepositoryInterface defines the interface for mapping Idempotency-Key headers to created todos
+type IdempotencyRepositoryInterface interface {
+	GetTodoID(ctx context.Context, userID uuid.UUID, key string) (todoID uuid.UUID, ok bool, err error)
+	CreateTodo(ctx context.Context, userID uuid.UUID, key string, todo *models.Todo) (todoID uuid.UUID, created bool, err error)
+}
+
+// AuditRepositoryInterface defines the interface for recording and listing audit events
+type AuditRepositoryInterface interface {
+	Record(ctx context.Context, event *models.AuditEvent) error
+	List(ctx context.Context, filter models.AuditEventFilter, page, pageSize int) ([]*models.AuditEvent, int, error)
+}
+
+// APIKeyRepositoryInterface defines the interface for users' API keys
+type APIKeyRepositoryInterface interface {
+	Create(ctx context.Context, key *models.APIKey, keyHash string) error
+	Authenticate(ctx context.Context, keyHash string) (*models.APIKey, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error)
+	Revoke(ctx context.Context, userID, id uuid.UUID) (bool, error)
+}
+
 // Ensure concrete types implement the interfaces
 var (
 	_ TodoRepositoryInterface          = (*TodoRepository)(nil)
 	_ AIContextRepositoryInterface     = (*AIContextRepository)(nil)
 	_ UserActivityRepositoryInterface  = (*UserActivityRepository)(nil)
 	_ TagStatisticsRepositoryInterface = (*TagStatisticsRepository)(nil)
+	_ TodoHistoryRepositoryInterface   = (*TodoHistoryRepository)(nil)
+	_ AIUsageRepositoryInterface       = (*AIUsageRepository)(nil)
+	_ IdempotencyRepositoryInterface   = (*IdempotencyRepository)(nil)
+	_ AuditRepositoryInterface         = (*AuditRepository)(nil)
+	_ APIKeyRepositoryInterface        = (*APIKeyRepository)(nil)
 )
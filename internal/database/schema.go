@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// migrationFiles are the migrations applied by the migration runner (golang-migrate), embedded so the
+// binary knows which schema version it was built against
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// ExpectedSchemaVersion returns the highest migration version shipped with this binary
+func ExpectedSchemaVersion() (uint, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	var latest uint
+	for _, entry := range entries {
+		version, ok := migrationVersion(entry.Name())
+		if ok && version > latest {
+			latest = version
+		}
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("no embedded migrations found")
+	}
+	return latest, nil
+}
+
+// migrationVersion parses the version prefix of a golang-migrate up file name (e.g. 000015_todo_history.up.sql)
+func migrationVersion(name string) (uint, bool) {
+	if !strings.HasSuffix(name, ".up.sql") {
+		return 0, false
+	}
+	prefix, _, found := strings.Cut(name, "_")
+	if !found {
+		return 0, false
+	}
+	version, err := strconv.ParseUint(prefix, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(version), true
+}
+
+// SchemaVersionReader reports the schema version applied to the database and whether the last migration
+// was left dirty (failed partway)
+type SchemaVersionReader interface {
+	SchemaVersion(ctx context.Context) (version uint, dirty bool, err error)
+}
+
+// SchemaVersion reads the applied migration version from golang-migrate's schema_migrations table.
+// A database that was never migrated reports version 0.
+func (db *DB) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "42P01" { // undefined_table: never migrated
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
+
+// ErrSchemaMismatch is returned by CheckSchemaVersion when the database schema is not usable by this binary
+var ErrSchemaMismatch = errors.New("database schema does not match the binary")
+
+// CheckSchemaVersion verifies that the database has at least the expected migration version applied and
+// that the last migration completed. A newer schema is accepted so older binaries keep serving during a
+// rolling deploy, since migrations are additive.
+func CheckSchemaVersion(ctx context.Context, reader SchemaVersionReader, expected uint) error {
+	version, dirty, err := reader.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w: migration %d is dirty and needs manual repair", ErrSchemaMismatch, version)
+	}
+	if version < expected {
+		return fmt.Errorf("%w: database is at migration %d, binary expects %d (run migrations)", ErrSchemaMismatch, version, expected)
+	}
+	return nil
+}
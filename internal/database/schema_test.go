@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestExpectedSchemaVersion_MatchesMigrations(t *testing.T) {
+	t.Parallel()
+
+	version, err := ExpectedSchemaVersion()
+	if err != nil {
+		t.Fatalf("ExpectedSchemaVersion() error = %v", err)
+	}
+	if version < 15 {
+		t.Errorf("ExpectedSchemaVersion() = %d, want at least 15", version)
+	}
+}
+
+func TestMigrationVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		want   uint
+		wantOK bool
+	}{
+		{"000015_todo_history.up.sql", 15, true},
+		{"000015_todo_history.down.sql", 0, false},
+		{"README.md", 0, false},
+		{"abc_name.up.sql", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := migrationVersion(tt.name)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("migrationVersion(%q) = %d, %v; want %d, %v", tt.name, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+type stubSchemaReader struct {
+	version uint
+	dirty   bool
+}
+
+func (s stubSchemaReader) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	return s.version, s.dirty, nil
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		reader       stubSchemaReader
+		wantMismatch bool
+	}{
+		{"current", stubSchemaReader{version: 15}, false},
+		{"newer schema from a rolling deploy", stubSchemaReader{version: 16}, false},
+		{"stale schema", stubSchemaReader{version: 14}, true},
+		{"never migrated", stubSchemaReader{}, true},
+		{"dirty", stubSchemaReader{version: 15, dirty: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := CheckSchemaVersion(context.Background(), tt.reader, 15)
+			if errors.Is(err, ErrSchemaMismatch) != tt.wantMismatch {
+				t.Errorf("CheckSchemaVersion() error = %v, want mismatch = %v", err, tt.wantMismatch)
+			}
+		})
+	}
+}
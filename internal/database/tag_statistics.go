@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -24,24 +25,29 @@ func NewTagStatisticsRepository(db *DB) *TagStatisticsRepository {
 // GetByUserID retrieves tag statistics by user ID
 func (r *TagStatisticsRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.TagStatistics, error) {
 	stats := &models.TagStatistics{}
-	var tagStatsJSON []byte
+	var tagStatsJSON, coOccurrenceJSON []byte
 	var lastAnalyzedAt sql.NullTime
+	var contentHash sql.NullString
 
 	query := `
-		SELECT user_id, tag_stats, tainted, last_analyzed_at, analysis_version, created_at, updated_at
+		SELECT user_id, tag_stats, co_occurrence, tainted, last_analyzed_at, analysis_version, content_hash, created_at, updated_at
 		FROM tag_statistics
 		WHERE user_id = $1
 	`
 
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
-		&stats.UserID,
-		&tagStatsJSON,
-		&stats.Tainted,
-		&lastAnalyzedAt,
-		&stats.AnalysisVersion,
-		&stats.CreatedAt,
-		&stats.UpdatedAt,
-	)
+	err := r.db.timedQuery("tag_statistics.get_by_user_id", func() error {
+		return r.db.QueryRowContext(ctx, query, userID).Scan(
+			&stats.UserID,
+			&tagStatsJSON,
+			&coOccurrenceJSON,
+			&stats.Tainted,
+			&lastAnalyzedAt,
+			&stats.AnalysisVersion,
+			&contentHash,
+			&stats.CreatedAt,
+			&stats.UpdatedAt,
+		)
+	})
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -50,17 +56,10 @@ func (r *TagStatisticsRepository) GetByUserID(ctx context.Context, userID uuid.U
 		return nil, fmt.Errorf("failed to get tag statistics: %w", err)
 	}
 
-	if len(tagStatsJSON) > 0 {
-		if err := json.Unmarshal(tagStatsJSON, &stats.TagStats); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal tag_stats: %w", err)
-		}
-	} else {
-		stats.TagStats = make(map[string]models.TagStats)
-	}
-
-	if lastAnalyzedAt.Valid {
-		stats.LastAnalyzedAt = &lastAnalyzedAt.Time
+	if err := unmarshalTagStatistics(stats, tagStatsJSON, coOccurrenceJSON, lastAnalyzedAt); err != nil {
+		return nil, err
 	}
+	stats.ContentHash = contentHash.String
 
 	return stats, nil
 }
@@ -92,14 +91,14 @@ func (r *TagStatisticsRepository) GetByUserIDOrCreate(ctx context.Context, userI
 // Create creates a new tag statistics record
 func (r *TagStatisticsRepository) Create(ctx context.Context, stats *models.TagStatistics) error {
 	query := `
-		INSERT INTO tag_statistics (user_id, tag_stats, tainted, last_analyzed_at, analysis_version, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO tag_statistics (user_id, tag_stats, co_occurrence, tainted, last_analyzed_at, analysis_version, created_at, updated_at, content_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING created_at, updated_at
 	`
 
-	tagStatsJSON, err := json.Marshal(stats.TagStats)
+	tagStatsJSON, coOccurrenceJSON, err := marshalTagStatistics(stats)
 	if err != nil {
-		return fmt.Errorf("failed to marshal tag_stats: %w", err)
+		return err
 	}
 
 	var lastAnalyzedAt sql.NullTime
@@ -108,15 +107,19 @@ func (r *TagStatisticsRepository) Create(ctx context.Context, stats *models.TagS
 	}
 
 	now := time.Now()
-	err = r.db.QueryRowContext(ctx, query,
-		stats.UserID,
-		tagStatsJSON,
-		stats.Tainted,
-		lastAnalyzedAt,
-		stats.AnalysisVersion,
-		now,
-		now,
-	).Scan(&stats.CreatedAt, &stats.UpdatedAt)
+	err = r.db.timedQuery("tag_statistics.create", func() error {
+		return r.db.QueryRowContext(ctx, query,
+			stats.UserID,
+			tagStatsJSON,
+			coOccurrenceJSON,
+			stats.Tainted,
+			lastAnalyzedAt,
+			stats.AnalysisVersion,
+			now,
+			now,
+			contentHashNullString(stats.ContentHash),
+		).Scan(&stats.CreatedAt, &stats.UpdatedAt)
+	})
 
 	if err != nil {
 		return fmt.Errorf("failed to create tag statistics: %w", err)
@@ -126,18 +129,20 @@ func (r *TagStatisticsRepository) Create(ctx context.Context, stats *models.TagS
 }
 
 // UpdateStatistics atomically updates tag statistics with version check
-// Returns true if update succeeded, false if version conflict occurred
+// Returns true if update succeeded, false if version conflict occurred or the stored statistics are
+// already untainted and aggregated from inputs with the same ContentHash, e.g. written by a concurrent worker
 func (r *TagStatisticsRepository) UpdateStatistics(ctx context.Context, stats *models.TagStatistics) (bool, error) {
 	query := `
 		UPDATE tag_statistics
-		SET tag_stats = $1, tainted = false, last_analyzed_at = $2, analysis_version = analysis_version + 1, updated_at = $3
-		WHERE user_id = $4 AND analysis_version = $5
+		SET tag_stats = $1, co_occurrence = $2, tainted = false, last_analyzed_at = $3, analysis_version = analysis_version + 1, updated_at = $4,
+			content_hash = $7
+		WHERE user_id = $5 AND analysis_version = $6 AND (tainted OR content_hash IS DISTINCT FROM $7)
 		RETURNING analysis_version, created_at, updated_at
 	`
 
-	tagStatsJSON, err := json.Marshal(stats.TagStats)
+	tagStatsJSON, coOccurrenceJSON, err := marshalTagStatistics(stats)
 	if err != nil {
-		return false, fmt.Errorf("failed to marshal tag_stats: %w", err)
+		return false, err
 	}
 
 	now := time.Now()
@@ -149,17 +154,21 @@ func (r *TagStatisticsRepository) UpdateStatistics(ctx context.Context, stats *m
 	}
 
 	var newVersion int
-	err = r.db.QueryRowContext(ctx, query,
-		tagStatsJSON,
-		lastAnalyzedAt,
-		now,
-		stats.UserID,
-		stats.AnalysisVersion,
-	).Scan(&newVersion, &stats.CreatedAt, &stats.UpdatedAt)
+	err = r.db.timedQuery("tag_statistics.update_statistics", func() error {
+		return r.db.QueryRowContext(ctx, query,
+			tagStatsJSON,
+			coOccurrenceJSON,
+			lastAnalyzedAt,
+			now,
+			stats.UserID,
+			stats.AnalysisVersion,
+			contentHashNullString(stats.ContentHash),
+		).Scan(&newVersion, &stats.CreatedAt, &stats.UpdatedAt)
+	})
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			// Version conflict - another update occurred
+			// Version conflict - another update occurred - or nothing to change
 			return false, nil
 		}
 		return false, fmt.Errorf("failed to update tag statistics: %w", err)
@@ -185,7 +194,9 @@ func (r *TagStatisticsRepository) MarkTainted(ctx context.Context, userID uuid.U
 	`
 
 	var resultID uuid.UUID
-	err := r.db.QueryRowContext(ctx, query, time.Now(), userID).Scan(&resultID)
+	err := r.db.timedQuery("tag_statistics.mark_tainted", func() error {
+		return r.db.QueryRowContext(ctx, query, time.Now(), userID).Scan(&resultID)
+	})
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -199,7 +210,9 @@ func (r *TagStatisticsRepository) MarkTainted(ctx context.Context, userID uuid.U
 				WHERE tag_statistics.tainted = false
 				RETURNING user_id
 			`
-			err = r.db.QueryRowContext(ctx, upsertQuery, userID, time.Now()).Scan(&resultID)
+			err = r.db.timedQuery("tag_statistics.mark_tainted_upsert", func() error {
+				return r.db.QueryRowContext(ctx, upsertQuery, userID, time.Now()).Scan(&resultID)
+			})
 			if err != nil {
 				if err == sql.ErrNoRows {
 					// Already tainted, no transition
@@ -220,20 +233,22 @@ func (r *TagStatisticsRepository) MarkTainted(ctx context.Context, userID uuid.U
 // Upsert creates or updates tag statistics
 func (r *TagStatisticsRepository) Upsert(ctx context.Context, stats *models.TagStatistics) error {
 	query := `
-		INSERT INTO tag_statistics (user_id, tag_stats, tainted, last_analyzed_at, analysis_version, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO tag_statistics (user_id, tag_stats, co_occurrence, tainted, last_analyzed_at, analysis_version, created_at, updated_at, content_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (user_id) DO UPDATE
 		SET tag_stats = EXCLUDED.tag_stats,
+		    co_occurrence = EXCLUDED.co_occurrence,
 		    tainted = EXCLUDED.tainted,
 		    last_analyzed_at = EXCLUDED.last_analyzed_at,
 		    analysis_version = EXCLUDED.analysis_version,
-		    updated_at = EXCLUDED.updated_at
+		    updated_at = EXCLUDED.updated_at,
+		    content_hash = EXCLUDED.content_hash
 		RETURNING created_at, updated_at
 	`
 
-	tagStatsJSON, err := json.Marshal(stats.TagStats)
+	tagStatsJSON, coOccurrenceJSON, err := marshalTagStatistics(stats)
 	if err != nil {
-		return fmt.Errorf("failed to marshal tag_stats: %w", err)
+		return err
 	}
 
 	var lastAnalyzedAt sql.NullTime
@@ -242,15 +257,19 @@ func (r *TagStatisticsRepository) Upsert(ctx context.Context, stats *models.TagS
 	}
 
 	now := time.Now()
-	err = r.db.QueryRowContext(ctx, query,
-		stats.UserID,
-		tagStatsJSON,
-		stats.Tainted,
-		lastAnalyzedAt,
-		stats.AnalysisVersion,
-		now,
-		now,
-	).Scan(&stats.CreatedAt, &stats.UpdatedAt)
+	err = r.db.timedQuery("tag_statistics.upsert", func() error {
+		return r.db.QueryRowContext(ctx, query,
+			stats.UserID,
+			tagStatsJSON,
+			coOccurrenceJSON,
+			stats.Tainted,
+			lastAnalyzedAt,
+			stats.AnalysisVersion,
+			now,
+			now,
+			contentHashNullString(stats.ContentHash),
+		).Scan(&stats.CreatedAt, &stats.UpdatedAt)
+	})
 
 	if err != nil {
 		return fmt.Errorf("failed to upsert tag statistics: %w", err)
@@ -258,3 +277,115 @@ func (r *TagStatisticsRepository) Upsert(ctx context.Context, stats *models.TagS
 
 	return nil
 }
+
+// ApplyTagDeltas applies per-todo tag changes to the user's stored statistics in one transaction instead
+// of rebuilding them from every todo. The update is version checked and bumps analysis_version, so a full
+// analysis that read the statistics before the deltas were applied is discarded rather than overwriting them.
+// Returns nil statistics without an error when there is nothing current to apply the deltas to: the user has
+// no statistics yet, or they are tainted and waiting for a full analysis.
+func (r *TagStatisticsRepository) ApplyTagDeltas(ctx context.Context, userID uuid.UUID, deltas []models.TagDelta) (*models.TagStatistics, error) {
+	defer r.db.observeQuery("tag_statistics.apply_tag_deltas", time.Now())
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stats := &models.TagStatistics{}
+	var tagStatsJSON, coOccurrenceJSON []byte
+	var lastAnalyzedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		SELECT user_id, tag_stats, co_occurrence, tainted, last_analyzed_at, analysis_version, created_at, updated_at
+		FROM tag_statistics
+		WHERE user_id = $1
+		FOR UPDATE
+	`, userID).Scan(
+		&stats.UserID,
+		&tagStatsJSON,
+		&coOccurrenceJSON,
+		&stats.Tainted,
+		&lastAnalyzedAt,
+		&stats.AnalysisVersion,
+		&stats.CreatedAt,
+		&stats.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tag statistics: %w", err)
+	}
+	if stats.Tainted {
+		return nil, nil
+	}
+	if err := unmarshalTagStatistics(stats, tagStatsJSON, coOccurrenceJSON, lastAnalyzedAt); err != nil {
+		return nil, err
+	}
+
+	for _, delta := range deltas {
+		stats.ApplyTagDelta(delta)
+	}
+	tagStatsJSON, coOccurrenceJSON, err = marshalTagStatistics(stats)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.QueryRowContext(ctx, `
+		UPDATE tag_statistics
+		SET tag_stats = $1, co_occurrence = $2, analysis_version = analysis_version + 1, updated_at = $3, content_hash = NULL
+		WHERE user_id = $4 AND analysis_version = $5
+		RETURNING analysis_version, updated_at
+	`, tagStatsJSON, coOccurrenceJSON, time.Now(), userID, stats.AnalysisVersion).Scan(&stats.AnalysisVersion, &stats.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tag statistics: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return stats, nil
+}
+
+// unmarshalTagStatistics decodes the JSONB and nullable columns of a tag statistics record into stats
+func unmarshalTagStatistics(stats *models.TagStatistics, tagStatsJSON, coOccurrenceJSON []byte, lastAnalyzedAt sql.NullTime) error {
+	if len(tagStatsJSON) > 0 {
+		if err := json.Unmarshal(tagStatsJSON, &stats.TagStats); err != nil {
+			return fmt.Errorf("failed to unmarshal tag_stats: %w", err)
+		}
+	}
+	if stats.TagStats == nil {
+		stats.TagStats = make(map[string]models.TagStats)
+	}
+	if len(coOccurrenceJSON) > 0 {
+		if err := json.Unmarshal(coOccurrenceJSON, &stats.CoOccurrence); err != nil {
+			return fmt.Errorf("failed to unmarshal co_occurrence: %w", err)
+		}
+	}
+	if lastAnalyzedAt.Valid {
+		stats.LastAnalyzedAt = &lastAnalyzedAt.Time
+	}
+	return nil
+}
+
+// marshalTagStatistics encodes the JSONB columns of a tag statistics record
+func marshalTagStatistics(stats *models.TagStatistics) (tagStatsJSON, coOccurrenceJSON []byte, err error) {
+	tagStatsJSON, err = json.Marshal(stats.TagStats)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal tag_stats: %w", err)
+	}
+	pairs := stats.CoOccurrence
+	if pairs == nil {
+		pairs = []models.TagPair{}
+	}
+	coOccurrenceJSON, err = json.Marshal(pairs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal co_occurrence: %w", err)
+	}
+	return tagStatsJSON, coOccurrenceJSON, nil
+}
+
+// contentHashNullString stores an empty content hash as NULL
+func contentHashNullString(hash string) sql.NullString {
+	return sql.NullString{String: hash, Valid: hash != ""}
+}
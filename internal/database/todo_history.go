@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+)
+
+// MaxTodoHistoryEntries is how many history entries are kept per todo; older entries are pruned on write
+const MaxTodoHistoryEntries = 50
+
+type changeActorKey struct{}
+
+// WithChangeActor returns a context that attributes todo updates made with it to actor
+func WithChangeActor(ctx context.Context, actor models.ChangeActor) context.Context {
+	return context.WithValue(ctx, changeActorKey{}, actor)
+}
+
+// ChangeActorFromContext returns the actor set by WithChangeActor, defaulting to ChangeActorSystem
+func ChangeActorFromContext(ctx context.Context) models.ChangeActor {
+	if actor, ok := ctx.Value(changeActorKey{}).(models.ChangeActor); ok && actor != "" {
+		return actor
+	}
+	return models.ChangeActorSystem
+}
+
+// TodoHistoryRepository reads the change history recorded by TodoRepository.Update
+type TodoHistoryRepository struct {
+	db *DB
+}
+
+// NewTodoHistoryRepository creates a new todo history repository
+func NewTodoHistoryRepository(db *DB) *TodoHistoryRepository {
+	return &TodoHistoryRepository{db: db}
+}
+
+// ListByTodo returns a todo's history entries oldest first. Enforces tenant scope at the DB layer.
+func (r *TodoHistoryRepository) ListByTodo(ctx context.Context, userID uuid.UUID, todoID uuid.UUID) ([]*models.TodoHistoryEntry, error) {
+	rows, err := timedResult(r.db, "todo_history.list_by_todo", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, `
+			SELECT id, todo_id, actor, changes, created_at
+			FROM todo_history
+			WHERE todo_id = $1 AND user_id = $2
+			ORDER BY created_at ASC, id ASC
+		`, todoID, userID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query todo history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := []*models.TodoHistoryEntry{}
+	for rows.Next() {
+		entry := &models.TodoHistoryEntry{}
+		var changesJSON []byte
+		if err := rows.Scan(&entry.ID, &entry.TodoID, &entry.Actor, &changesJSON, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan todo history: %w", err)
+		}
+		if err := json.Unmarshal(changesJSON, &entry.Changes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal todo history changes: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate todo history: %w", err)
+	}
+	return entries, nil
+}
+
+// selectTodoStateForUpdate loads the stored fields tracked by history, locking the row
+func selectTodoStateForUpdate(ctx context.Context, tx *sql.Tx, userID, todoID uuid.UUID) (*models.Todo, error) {
+	prev := &models.Todo{ID: todoID, UserID: userID}
+	var metadataJSON []byte
+	var dueDate sql.NullTime
+	var priority sql.NullString
+	err := tx.QueryRowContext(ctx,
+		`SELECT text, time_horizon, status, metadata, due_date, priority FROM todos WHERE id = $1 AND user_id = $2 FOR UPDATE`,
+		todoID, userID,
+	).Scan(&prev.Text, &prev.TimeHorizon, &prev.Status, &metadataJSON, &dueDate, &priority)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTodoNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load todo: %w", err)
+	}
+	if err := json.Unmarshal(metadataJSON, &prev.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+	if dueDate.Valid {
+		prev.DueDate = &dueDate.Time
+	}
+	prev.Priority = models.Priority(priority.String)
+	return prev, nil
+}
+
+// recordTodoHistory stores changes for todo and prunes entries beyond MaxTodoHistoryEntries
+func recordTodoHistory(ctx context.Context, tx *sql.Tx, todo *models.Todo, actor models.ChangeActor, changes []models.FieldChange) error {
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal todo history: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO todo_history (todo_id, user_id, actor, changes, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		todo.ID, todo.UserID, actor, changesJSON, todo.UpdatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to insert todo history: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM todo_history
+		WHERE id IN (
+			SELECT id FROM todo_history
+			WHERE todo_id = $1
+			ORDER BY created_at DESC, id DESC
+			OFFSET $2
+		)
+	`, todo.ID, MaxTodoHistoryEntries); err != nil {
+		return fmt.Errorf("failed to prune todo history: %w", err)
+	}
+	return nil
+}
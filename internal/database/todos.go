@@ -6,23 +6,45 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/benvon/smart-todo/internal/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
 // ErrTodoNotFound is returned when a todo is not found (e.g. by id or by user_id+id).
 var ErrTodoNotFound = errors.New("todo not found")
 
+// ErrConcurrentModification is returned when a todo update expected an updated_at the todo no longer has,
+// because another update was written first.
+var ErrConcurrentModification = errors.New("todo was modified concurrently")
+
+type expectedUpdatedAtKey struct{}
+
+// WithExpectedUpdatedAt returns a context whose todo updates only apply while the todo's updated_at is still
+// updatedAt, failing with ErrConcurrentModification otherwise
+func WithExpectedUpdatedAt(ctx context.Context, updatedAt time.Time) context.Context {
+	return context.WithValue(ctx, expectedUpdatedAtKey{}, updatedAt)
+}
+
+// ExpectedUpdatedAtFromContext returns the updated_at set by WithExpectedUpdatedAt
+func ExpectedUpdatedAtFromContext(ctx context.Context) (time.Time, bool) {
+	updatedAt, ok := ctx.Value(expectedUpdatedAtKey{}).(time.Time)
+	return updatedAt, ok
+}
+
 const (
 	// MaxPageSize is the maximum page size for pagination queries
 	MaxPageSize = 500
 )
 
-// TagChangeHandler handles tag change events (callback to avoid circular dependencies)
-type TagChangeHandler func(ctx context.Context, userID uuid.UUID) error
+// TagChangeHandler handles tag change events (callback to avoid circular dependencies).
+// deltas holds each changed todo's tags before and after the change; it is nil when the change was not
+// tracked per todo, such as a bulk tag reset, and the user's statistics must be rebuilt in full.
+type TagChangeHandler func(ctx context.Context, userID uuid.UUID, deltas []models.TagDelta) error
 
 // TodoRepository handles todo database operations
 type TodoRepository struct {
@@ -54,9 +76,21 @@ func (r *TodoRepository) SetTagStatsRepo(repo TagStatisticsRepositoryInterface)
 
 // Create creates a new todo
 func (r *TodoRepository) Create(ctx context.Context, todo *models.Todo) error {
+	return r.db.timedQuery("todos.create", func() error {
+		return insertTodo(ctx, r.db, todo)
+	})
+}
+
+// rowQuerier runs single-row queries on a *DB or inside a *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// insertTodo inserts a new todo with q, setting its CreatedAt and UpdatedAt
+func insertTodo(ctx context.Context, q rowQuerier, todo *models.Todo) error {
 	query := `
-		INSERT INTO todos (id, user_id, text, time_horizon, status, metadata, due_date, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO todos (id, user_id, text, time_horizon, status, metadata, due_date, priority, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING created_at, updated_at
 	`
 
@@ -71,7 +105,7 @@ func (r *TodoRepository) Create(ctx context.Context, todo *models.Todo) error {
 	}
 
 	now := time.Now()
-	err = r.db.QueryRowContext(ctx, query,
+	err = q.QueryRowContext(ctx, query,
 		todo.ID,
 		todo.UserID,
 		todo.Text,
@@ -79,6 +113,7 @@ func (r *TodoRepository) Create(ctx context.Context, todo *models.Todo) error {
 		todo.Status,
 		metadataJSON,
 		dueDate,
+		todoPriorityNullString(todo.Priority),
 		now,
 		now,
 	).Scan(&todo.CreatedAt, &todo.UpdatedAt)
@@ -90,31 +125,73 @@ func (r *TodoRepository) Create(ctx context.Context, todo *models.Todo) error {
 	return nil
 }
 
+// CreateBatch creates todos in one transaction, so either all of them are saved or none are.
+// Unlike Create it also stores CompletedAt, so already-completed todos can be imported.
+func (r *TodoRepository) CreateBatch(ctx context.Context, todos []*models.Todo) error {
+	defer r.db.observeQuery("todos.create_batch", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO todos (id, user_id, text, time_horizon, status, metadata, due_date, completed_at, priority, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+		RETURNING created_at, updated_at
+	`
+	now := time.Now()
+	for _, todo := range todos {
+		metadataJSON, err := json.Marshal(todo.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		err = tx.QueryRowContext(ctx, query,
+			todo.ID, todo.UserID, todo.Text, todo.TimeHorizon, todo.Status, metadataJSON,
+			todoDueDateNullTime(todo.DueDate), todoCompletedAtNullTime(todo.CompletedAt), todoPriorityNullString(todo.Priority), now,
+		).Scan(&todo.CreatedAt, &todo.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create todo: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 // GetByID retrieves a todo by ID
 func (r *TodoRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Todo, error) {
 	todo := &models.Todo{}
 	var metadataJSON []byte
-	var completedAt sql.NullTime
+	var completedAt, archivedAt, trashedAt sql.NullTime
 	var dueDate sql.NullTime
+	var priority sql.NullString
 
 	query := `
-		SELECT id, user_id, text, time_horizon, status, metadata, due_date, created_at, updated_at, completed_at
+		SELECT id, user_id, text, time_horizon, status, metadata, due_date, priority, created_at, updated_at, completed_at, archived_at, trashed_at
 		FROM todos
 		WHERE id = $1
 	`
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&todo.ID,
-		&todo.UserID,
-		&todo.Text,
-		&todo.TimeHorizon,
-		&todo.Status,
-		&metadataJSON,
-		&dueDate,
-		&todo.CreatedAt,
-		&todo.UpdatedAt,
-		&completedAt,
-	)
+	err := r.db.timedQuery("todos.get_by_id", func() error {
+		return r.db.QueryRowContext(ctx, query, id).Scan(
+			&todo.ID,
+			&todo.UserID,
+			&todo.Text,
+			&todo.TimeHorizon,
+			&todo.Status,
+			&metadataJSON,
+			&dueDate,
+			&priority,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&completedAt,
+			&archivedAt,
+			&trashedAt,
+		)
+	})
 
 	if err == sql.ErrNoRows {
 		return nil, ErrTodoNotFound
@@ -139,6 +216,9 @@ func (r *TodoRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Tod
 	if completedAt.Valid {
 		todo.CompletedAt = &completedAt.Time
 	}
+	todo.Priority = models.Priority(priority.String)
+	todo.ArchivedAt = timePtrFromNull(archivedAt)
+	todo.TrashedAt = timePtrFromNull(trashedAt)
 
 	return todo, nil
 }
@@ -148,27 +228,33 @@ func (r *TodoRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Tod
 func (r *TodoRepository) GetByUserIDAndID(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Todo, error) {
 	todo := &models.Todo{}
 	var metadataJSON []byte
-	var completedAt sql.NullTime
+	var completedAt, archivedAt, trashedAt sql.NullTime
 	var dueDate sql.NullTime
+	var priority sql.NullString
 
 	query := `
-		SELECT id, user_id, text, time_horizon, status, metadata, due_date, created_at, updated_at, completed_at
+		SELECT id, user_id, text, time_horizon, status, metadata, due_date, priority, created_at, updated_at, completed_at, archived_at, trashed_at
 		FROM todos
 		WHERE user_id = $1 AND id = $2
 	`
 
-	err := r.db.QueryRowContext(ctx, query, userID, id).Scan(
-		&todo.ID,
-		&todo.UserID,
-		&todo.Text,
-		&todo.TimeHorizon,
-		&todo.Status,
-		&metadataJSON,
-		&dueDate,
-		&todo.CreatedAt,
-		&todo.UpdatedAt,
-		&completedAt,
-	)
+	err := r.db.timedQuery("todos.get_by_user_id_and_id", func() error {
+		return r.db.QueryRowContext(ctx, query, userID, id).Scan(
+			&todo.ID,
+			&todo.UserID,
+			&todo.Text,
+			&todo.TimeHorizon,
+			&todo.Status,
+			&metadataJSON,
+			&dueDate,
+			&priority,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&completedAt,
+			&archivedAt,
+			&trashedAt,
+		)
+	})
 
 	if err == sql.ErrNoRows {
 		return nil, ErrTodoNotFound
@@ -192,6 +278,9 @@ func (r *TodoRepository) GetByUserIDAndID(ctx context.Context, userID uuid.UUID,
 	if completedAt.Valid {
 		todo.CompletedAt = &completedAt.Time
 	}
+	todo.Priority = models.Priority(priority.String)
+	todo.ArchivedAt = timePtrFromNull(archivedAt)
+	todo.TrashedAt = timePtrFromNull(trashedAt)
 
 	return todo, nil
 }
@@ -203,25 +292,126 @@ func (r *TodoRepository) GetByUserID(ctx context.Context, userID uuid.UUID, time
 	return todos, err
 }
 
-// GetByUserIDPaginated retrieves todos for a user with pagination support
+// TodoListFilter narrows a todo listing. Nil fields do not filter.
+type TodoListFilter struct {
+	TimeHorizon *models.TimeHorizon
+	Status      *models.TodoStatus
+	Priority    *models.Priority
+	// Analyzed selects todos whose status is in (true) or outside (false) models.AnalyzedTodoStatuses
+	Analyzed *bool
+	// IncludeRetired also lists todos the retention sweep has archived or trashed, which are hidden by default
+	IncludeRetired bool
+	// Sort orders the listing; the zero value lists newest first. Cursor listing always lists newest first.
+	Sort TodoSort
+}
+
+// TodoSortField names a field todo listings can be ordered by
+type TodoSortField string
+
+const (
+	TodoSortCreatedAt   TodoSortField = "created_at"
+	TodoSortDueDate     TodoSortField = "due_date"
+	TodoSortUpdatedAt   TodoSortField = "updated_at"
+	TodoSortTimeHorizon TodoSortField = "time_horizon"
+	TodoSortPriority    TodoSortField = "priority"
+)
+
+// TodoSortFields lists the fields todo listings can be ordered by
+var TodoSortFields = []TodoSortField{TodoSortCreatedAt, TodoSortDueDate, TodoSortUpdatedAt, TodoSortTimeHorizon, TodoSortPriority}
+
+// SortOrder is the direction of a sorted listing
+type SortOrder string
+
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// DefaultOrder returns the order f sorts in when none is given: most urgent first for due dates, time
+// horizons and priorities, newest first for timestamps
+func (f TodoSortField) DefaultOrder() SortOrder {
+	switch f {
+	case TodoSortDueDate, TodoSortTimeHorizon, TodoSortPriority:
+		return SortAscending
+	default:
+		return SortDescending
+	}
+}
+
+// todoSortExpressions maps each sortable field to its SQL expression. ORDER BY clauses are only ever built
+// from these, never from request input. Time horizons and priorities sort by urgency rather than
+// alphabetically.
+var todoSortExpressions = map[TodoSortField]string{
+	TodoSortCreatedAt:   "created_at",
+	TodoSortDueDate:     "due_date",
+	TodoSortUpdatedAt:   "updated_at",
+	TodoSortTimeHorizon: "CASE time_horizon WHEN 'next' THEN 0 WHEN 'soon' THEN 1 ELSE 2 END",
+	TodoSortPriority:    "CASE priority WHEN 'urgent' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 WHEN 'low' THEN 3 END",
+}
+
+// TodoSort orders a todo listing. An empty Order uses the field's default order.
+type TodoSort struct {
+	Field TodoSortField
+	Order SortOrder
+}
+
+// NewestFirst reports whether s lists newest first, the only order cursor listing supports
+func (s TodoSort) NewestFirst() bool {
+	return (s.Field == "" || s.Field == TodoSortCreatedAt) && s.Order != SortAscending
+}
+
+// orderByClause returns the ORDER BY expression list for s. Todos without a due date or priority sort last
+// in either direction, and ties are broken newest first (then by id) so pages are stable. Unknown fields list newest
+// first.
+func (s TodoSort) orderByClause() string {
+	expr, ok := todoSortExpressions[s.Field]
+	if !ok || s.Field == TodoSortCreatedAt {
+		if s.Order == SortAscending {
+			return "created_at ASC, id ASC"
+		}
+		return "created_at DESC, id DESC"
+	}
+	order := s.Order
+	if order == "" {
+		order = s.Field.DefaultOrder()
+	}
+	clause := expr + " ASC"
+	if order == SortDescending {
+		clause = expr + " DESC"
+	}
+	if s.Field == TodoSortDueDate || s.Field == TodoSortPriority {
+		clause += " NULLS LAST"
+	}
+	return clause + ", created_at DESC, id DESC"
+}
+
+// GetByUserIDPaginated retrieves todos for a user with pagination support, including retired todos so
+// tag statistics and reprocessing still see them
 func (r *TodoRepository) GetByUserIDPaginated(ctx context.Context, userID uuid.UUID, timeHorizon *models.TimeHorizon, status *models.TodoStatus, page, pageSize int) ([]*models.Todo, int, error) {
-	whereClause, countQuery, countArgs, argIndex := buildTodoListWhereClause(userID, timeHorizon, status)
+	return r.ListByUserID(ctx, userID, TodoListFilter{TimeHorizon: timeHorizon, Status: status, IncludeRetired: true}, page, pageSize)
+}
+
+// ListByUserID retrieves a page of a user's todos matching filter, along with the total number of matches
+func (r *TodoRepository) ListByUserID(ctx context.Context, userID uuid.UUID, filter TodoListFilter, page, pageSize int) ([]*models.Todo, int, error) {
+	whereClause, countQuery, countArgs, argIndex := buildTodoListWhereClause(userID, filter)
 
 	var total int
-	if err := r.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+	if err := r.db.timedQuery("todos.count_by_user_id", func() error { return r.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total) }); err != nil {
 		return nil, 0, fmt.Errorf("failed to count todos: %w", err)
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, user_id, text, time_horizon, status, metadata, due_date, created_at, updated_at, completed_at
+		SELECT id, user_id, text, time_horizon, status, metadata, due_date, priority, created_at, updated_at, completed_at, archived_at, trashed_at
 		FROM todos
 		%s
-		ORDER BY created_at DESC
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, argIndex, argIndex+1)
+	`, whereClause, filter.Sort.orderByClause(), argIndex, argIndex+1)
 	args := append(append([]any(nil), countArgs...), pageSize, (page-1)*pageSize)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := timedResult(r.db, "todos.list_by_user_id", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query, args...)
+	})
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query todos: %w", err)
 	}
@@ -234,24 +424,417 @@ func (r *TodoRepository) GetByUserIDPaginated(ctx context.Context, userID uuid.U
 	return todos, total, nil
 }
 
+// TodoCursor marks a position in a user's todo listing (ordered newest first); listing resumes after it
+type TodoCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// ListByUserIDAfter retrieves up to limit of a user's todos matching filter that come after the cursor
+// (newest first, ties broken by id). A nil cursor starts from the newest todo.
+func (r *TodoRepository) ListByUserIDAfter(ctx context.Context, userID uuid.UUID, filter TodoListFilter, after *TodoCursor, limit int) ([]*models.Todo, error) {
+	whereClause, _, args, argIndex := buildTodoListWhereClause(userID, filter)
+	if after != nil {
+		whereClause += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", argIndex, argIndex+1)
+		args = append(args, after.CreatedAt, after.ID)
+		argIndex += 2
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, text, time_horizon, status, metadata, due_date, priority, created_at, updated_at, completed_at, archived_at, trashed_at
+		FROM todos
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, whereClause, argIndex)
+	args = append(args, limit)
+
+	rows, err := timedResult(r.db, "todos.list_by_user_id_after", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query, args...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query todos: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanTodoRows(rows)
+}
+
+// CountByStatusAndHorizon counts a user's todos by status and time horizon in one query, along with how many
+// are overdue (past their due date and not completed). Archived and trashed todos are not counted.
+func (r *TodoRepository) CountByStatusAndHorizon(ctx context.Context, userID uuid.UUID) (*models.TodoCounts, error) {
+	query := `
+		SELECT status, time_horizon, COUNT(*),
+			COUNT(*) FILTER (WHERE due_date < $2 AND status != $3)
+		FROM todos
+		WHERE user_id = $1 AND archived_at IS NULL AND trashed_at IS NULL
+		GROUP BY status, time_horizon
+	`
+	rows, err := timedResult(r.db, "todos.count_by_status_and_horizon", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query, userID, time.Now().UTC(), string(models.TodoStatusCompleted))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count todos: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := models.NewTodoCounts()
+	for rows.Next() {
+		var status models.TodoStatus
+		var horizon models.TimeHorizon
+		var count, overdue int
+		if err := rows.Scan(&status, &horizon, &count, &overdue); err != nil {
+			return nil, fmt.Errorf("failed to scan todo counts: %w", err)
+		}
+		counts.Add(status, horizon, count, overdue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate todo counts: %w", err)
+	}
+	return counts, nil
+}
+
+// ListOpenByUserID retrieves up to limit of a user's todos that are not completed, soonest due first (todos
+// without a due date last), then newest first
+func (r *TodoRepository) ListOpenByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*models.Todo, error) {
+	query := `
+		SELECT id, user_id, text, time_horizon, status, metadata, due_date, priority, created_at, updated_at, completed_at, archived_at, trashed_at
+		FROM todos
+		WHERE user_id = $1 AND status != $2
+		ORDER BY due_date ASC NULLS LAST, created_at DESC, id DESC
+		LIMIT $3
+	`
+	rows, err := timedResult(r.db, "todos.list_open_by_user_id", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query, userID, string(models.TodoStatusCompleted), limit)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open todos: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanTodoRows(rows)
+}
+
+// GetByExternalRef returns the user's most recently created todo linked to the external item system/id,
+// including archived todos but not trashed ones, or ErrTodoNotFound. The containment match is served by
+// idx_todos_external_refs.
+func (r *TodoRepository) GetByExternalRef(ctx context.Context, userID uuid.UUID, system, id string) (*models.Todo, error) {
+	refJSON, err := json.Marshal([]map[string]string{{"system": system, "id": id}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal external ref: %w", err)
+	}
+	query := `
+		SELECT id, user_id, text, time_horizon, status, metadata, due_date, priority, created_at, updated_at, completed_at, archived_at, trashed_at
+		FROM todos
+		WHERE user_id = $1 AND metadata->'external_refs' @> $2::jsonb AND trashed_at IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+	rows, err := timedResult(r.db, "todos.get_by_external_ref", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query, userID, string(refJSON))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query todo by external ref: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	todos, err := scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(todos) == 0 {
+		return nil, ErrTodoNotFound
+	}
+	return todos[0], nil
+}
+
+// ListCompletedBetween returns up to limit of the user's todos completed in [from, to), most recent first,
+// including retired ones
+func (r *TodoRepository) ListCompletedBetween(ctx context.Context, userID uuid.UUID, from, to time.Time, limit int) ([]*models.Todo, error) {
+	query := `
+		SELECT id, user_id, text, time_horizon, status, metadata, due_date, priority, created_at, updated_at, completed_at, archived_at, trashed_at
+		FROM todos
+		WHERE user_id = $1 AND status = $2 AND completed_at >= $3 AND completed_at < $4
+		ORDER BY completed_at DESC, id DESC
+		LIMIT $5
+	`
+	rows, err := timedResult(r.db, "todos.list_completed_between", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query, userID, string(models.TodoStatusCompleted), from, to, limit)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completed todos: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanTodoRows(rows)
+}
+
+// GetDueWithin returns the open todos of all users that come due within window from now and have neither a
+// reminder policy (those remind through their escalation chain) nor a due-soon reminder sent, soonest due first
+func (r *TodoRepository) GetDueWithin(ctx context.Context, window time.Duration) ([]*models.Todo, error) {
+	now := time.Now().UTC()
+	query := `
+		SELECT id, user_id, text, time_horizon, status, metadata, due_date, priority, created_at, updated_at, completed_at, archived_at, trashed_at
+		FROM todos
+		WHERE status != $1 AND archived_at IS NULL AND trashed_at IS NULL
+			AND due_date > $2 AND due_date <= $3
+			AND metadata->'reminder_policy' IS NULL AND metadata->'reminder_sent_at' IS NULL
+		ORDER BY due_date ASC, id ASC
+	`
+	rows, err := timedResult(r.db, "todos.get_due_within", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query, string(models.TodoStatusCompleted), now, now.Add(window))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query todos coming due: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanTodoRows(rows)
+}
+
+// MarkReminderSent records in the todo's metadata that its due-soon reminder was sent at sentAt. It reports
+// false without marking when the reminder was already marked or the todo is no longer due at dueDate, so
+// concurrent schedulers remind each due date once. The todo's updated_at is left alone.
+func (r *TodoRepository) MarkReminderSent(ctx context.Context, userID, id uuid.UUID, dueDate, sentAt time.Time) (bool, error) {
+	query := `
+		UPDATE todos
+		SET metadata = jsonb_set(metadata, '{reminder_sent_at}', to_jsonb($4::text))
+		WHERE user_id = $1 AND id = $2 AND due_date = $3 AND metadata->'reminder_sent_at' IS NULL
+	`
+	result, err := timedResult(r.db, "todos.mark_reminder_sent", func() (sql.Result, error) {
+		return r.db.ExecContext(ctx, query, userID, id, dueDate, sentAt.UTC().Format(time.RFC3339Nano))
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to mark reminder sent: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// CountAnalysisQueue returns the number of the user's todos per status that are still awaiting
+// AI analysis (pending or processing), using a single aggregate query.
+func (r *TodoRepository) CountAnalysisQueue(ctx context.Context, userID uuid.UUID) (map[models.TodoStatus]int, error) {
+	query := `
+		SELECT status, COUNT(*)
+		FROM todos
+		WHERE user_id = $1 AND status IN ($2, $3)
+		GROUP BY status
+	`
+	rows, err := timedResult(r.db, "todos.count_analysis_queue", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query, userID, string(models.TodoStatusPending), string(models.TodoStatusProcessing))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count analysis queue: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[models.TodoStatus]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan analysis queue count: %w", err)
+		}
+		counts[models.TodoStatus(status)] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate analysis queue counts: %w", err)
+	}
+	return counts, nil
+}
+
+// CountAnalysisBatch returns aggregate analysis counts over all of the user's todos in a single query.
+// Todos still processing since before stalledBefore count as failed; processed todos updated since
+// recentSince count as recently analyzed.
+func (r *TodoRepository) CountAnalysisBatch(ctx context.Context, userID uuid.UUID, stalledBefore, recentSince time.Time) (models.AnalysisBatchCounts, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status IN ($2, $3)),
+			COUNT(*) FILTER (WHERE status = $4),
+			COUNT(*) FILTER (WHERE status = $5 AND updated_at >= $6),
+			COUNT(*) FILTER (WHERE status = $5 AND updated_at < $6),
+			COUNT(*) FILTER (WHERE status = $2 AND updated_at >= $7)
+		FROM todos
+		WHERE user_id = $1
+	`
+	var counts models.AnalysisBatchCounts
+	err := r.db.timedQuery("todos.count_analysis_batch", func() error {
+		return r.db.QueryRowContext(ctx, query, userID,
+			string(models.TodoStatusProcessed), string(models.TodoStatusCompleted),
+			string(models.TodoStatusPending), string(models.TodoStatusProcessing),
+			stalledBefore, recentSince,
+		).Scan(&counts.Analyzed, &counts.Pending, &counts.Processing, &counts.Failed, &counts.RecentlyAnalyzed)
+	})
+	if err != nil {
+		return models.AnalysisBatchCounts{}, fmt.Errorf("failed to count analysis batch: %w", err)
+	}
+	return counts, nil
+}
+
+// completedRetentionLockKey is the advisory lock key held while a retention batch runs, so only one worker
+// sweeps at a time. The value is arbitrary but must not be reused for another lock.
+const completedRetentionLockKey int64 = 0x736d74645f726574
+
+// RetireCompletedBefore applies action to up to limit todos completed before cutoff, oldest first, skipping
+// users whose auto_archive preference is false. It runs under the retention advisory lock and reports
+// locked=false, retiring nothing, when another worker is already sweeping.
+func (r *TodoRepository) RetireCompletedBefore(ctx context.Context, action models.RetentionAction, cutoff time.Time, limit int) (retired int, locked bool, err error) {
+	query, err := buildRetentionSweepQuery(action)
+	if err != nil {
+		return 0, false, err
+	}
+	defer r.db.observeQuery("todos.retire_completed_before", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if locked, err = tryAdvisoryXactLock(ctx, tx, completedRetentionLockKey); err != nil || !locked {
+		return 0, false, err
+	}
+	result, err := tx.ExecContext(ctx, query, time.Now(), cutoff, limit)
+	if err != nil {
+		return 0, true, fmt.Errorf("failed to retire completed todos: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, true, fmt.Errorf("failed to count retired todos: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, true, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return int(n), true, nil
+}
+
+// buildRetentionSweepQuery builds the UPDATE that retires completed todos for action. Its arguments are the
+// retirement time, the completion cutoff and the batch size. Trashing also takes already-archived todos.
+func buildRetentionSweepQuery(action models.RetentionAction) (string, error) {
+	var column, notRetired string
+	switch action {
+	case models.RetentionActionArchive:
+		column, notRetired = "archived_at", "archived_at IS NULL AND trashed_at IS NULL"
+	case models.RetentionActionTrash:
+		column, notRetired = "trashed_at", "trashed_at IS NULL"
+	default:
+		return "", fmt.Errorf("unknown retention action %q", action)
+	}
+	return fmt.Sprintf(`
+		UPDATE todos SET %s = $1, updated_at = $1
+		WHERE id IN (
+			SELECT id FROM todos
+			WHERE status = '%s' AND completed_at < $2 AND %s
+				AND NOT EXISTS (
+					SELECT 1 FROM ai_context c
+					WHERE c.user_id = todos.user_id AND c.preferences->>'%s' = 'false'
+				)
+			ORDER BY completed_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+	`, column, models.TodoStatusCompleted, notRetired, models.PreferenceAutoArchive), nil
+}
+
+// horizonPromotionLockKey is the advisory lock key held while a horizon promotion batch runs, so only one
+// worker promotes at a time. The value is arbitrary but must not be reused for another lock.
+const horizonPromotionLockKey int64 = 0x736d74645f687a6e
+
+// PromoteTimeHorizonsByDueDate moves up to limit open todos to the more urgent time horizon their due date
+// calls for as of now (see models.Todo.PromotedTimeHorizon), soonest due first, recording the changes in
+// their history as system changes. Todos whose horizon the user set are skipped. It runs under the horizon
+// promotion advisory lock and reports locked=false, promoting nothing, when another worker holds it.
+func (r *TodoRepository) PromoteTimeHorizonsByDueDate(ctx context.Context, now time.Time, limit int) (promoted int, locked bool, err error) {
+	defer r.db.observeQuery("todos.promote_time_horizons", time.Now())
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if locked, err = tryAdvisoryXactLock(ctx, tx, horizonPromotionLockKey); err != nil || !locked {
+		return 0, false, err
+	}
+	// A due date less than N+1 days away is at most N whole days away
+	soonBefore := now.Add((models.HorizonSoonMaxDaysUntilDue + 1) * 24 * time.Hour)
+	nextBefore := now.Add((models.HorizonNextMaxDaysUntilDue + 1) * 24 * time.Hour)
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, user_id, text, time_horizon, status, metadata, due_date, priority, created_at, updated_at, completed_at, archived_at, trashed_at
+		FROM todos
+		WHERE status <> $1 AND archived_at IS NULL AND trashed_at IS NULL
+			AND ((time_horizon = $2 AND due_date < $3) OR (time_horizon = $4 AND due_date < $5))
+			AND COALESCE(metadata->>'time_horizon_user_override', 'false') <> 'true'
+		ORDER BY due_date
+		LIMIT $6
+		FOR UPDATE SKIP LOCKED
+	`, models.TodoStatusCompleted, models.TimeHorizonLater, soonBefore, models.TimeHorizonSoon, nextBefore, limit)
+	if err != nil {
+		return 0, true, fmt.Errorf("failed to query todos to promote: %w", err)
+	}
+	todos, err := scanTodoRows(rows)
+	_ = rows.Close()
+	if err != nil {
+		return 0, true, err
+	}
+
+	for _, todo := range todos {
+		horizon, ok := todo.PromotedTimeHorizon(now)
+		if !ok {
+			continue
+		}
+		prev := *todo
+		todo.TimeHorizon = horizon
+		if err := saveBulkUpdatedTodo(ctx, tx, &prev, todo, models.ChangeActorSystem); err != nil {
+			return 0, true, err
+		}
+		promoted++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, true, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return promoted, true, nil
+}
+
 // buildTodoListWhereClause builds WHERE clause and count query for todo list filtering.
-func buildTodoListWhereClause(userID uuid.UUID, timeHorizon *models.TimeHorizon, status *models.TodoStatus) (whereClause, countQuery string, args []any, nextArgIndex int) {
+func buildTodoListWhereClause(userID uuid.UUID, filter TodoListFilter) (whereClause, countQuery string, args []any, nextArgIndex int) {
 	whereClause = "WHERE user_id = $1"
-	countQuery = "SELECT COUNT(*) FROM todos WHERE user_id = $1"
 	args = []any{userID}
 	nextArgIndex = 2
-	if timeHorizon != nil {
+	if filter.TimeHorizon != nil {
 		whereClause += fmt.Sprintf(" AND time_horizon = $%d", nextArgIndex)
-		countQuery += fmt.Sprintf(" AND time_horizon = $%d", nextArgIndex)
-		args = append(args, string(*timeHorizon))
+		args = append(args, string(*filter.TimeHorizon))
 		nextArgIndex++
 	}
-	if status != nil {
+	if filter.Status != nil {
 		whereClause += fmt.Sprintf(" AND status = $%d", nextArgIndex)
-		countQuery += fmt.Sprintf(" AND status = $%d", nextArgIndex)
-		args = append(args, string(*status))
+		args = append(args, string(*filter.Status))
 		nextArgIndex++
 	}
+	if filter.Priority != nil {
+		whereClause += fmt.Sprintf(" AND priority = $%d", nextArgIndex)
+		args = append(args, string(*filter.Priority))
+		nextArgIndex++
+	}
+	if filter.Analyzed != nil {
+		placeholders := make([]string, len(models.AnalyzedTodoStatuses))
+		for i, st := range models.AnalyzedTodoStatuses {
+			placeholders[i] = fmt.Sprintf("$%d", nextArgIndex)
+			args = append(args, string(st))
+			nextArgIndex++
+		}
+		op := "IN"
+		if !*filter.Analyzed {
+			op = "NOT IN"
+		}
+		whereClause += fmt.Sprintf(" AND status %s (%s)", op, strings.Join(placeholders, ", "))
+	}
+	if !filter.IncludeRetired {
+		whereClause += " AND archived_at IS NULL AND trashed_at IS NULL"
+	}
+	countQuery = "SELECT COUNT(*) FROM todos " + whereClause
 	return whereClause, countQuery, args, nextArgIndex
 }
 
@@ -275,8 +858,9 @@ func scanTodoRows(rows *sql.Rows) ([]*models.Todo, error) {
 func scanTodoRow(rows *sql.Rows) (*models.Todo, error) {
 	todo := &models.Todo{}
 	var metadataJSON []byte
-	var completedAt sql.NullTime
+	var completedAt, archivedAt, trashedAt sql.NullTime
 	var dueDate sql.NullTime
+	var priority sql.NullString
 	if err := rows.Scan(
 		&todo.ID,
 		&todo.UserID,
@@ -285,9 +869,12 @@ func scanTodoRow(rows *sql.Rows) (*models.Todo, error) {
 		&todo.Status,
 		&metadataJSON,
 		&dueDate,
+		&priority,
 		&todo.CreatedAt,
 		&todo.UpdatedAt,
 		&completedAt,
+		&archivedAt,
+		&trashedAt,
 	); err != nil {
 		return nil, fmt.Errorf("failed to scan todo: %w", err)
 	}
@@ -303,43 +890,595 @@ func scanTodoRow(rows *sql.Rows) (*models.Todo, error) {
 	if completedAt.Valid {
 		todo.CompletedAt = &completedAt.Time
 	}
+	todo.Priority = models.Priority(priority.String)
+	todo.ArchivedAt = timePtrFromNull(archivedAt)
+	todo.TrashedAt = timePtrFromNull(trashedAt)
 	return todo, nil
 }
 
 // Update updates an existing todo
 // oldTags should be the CategoryTags from the existing todo before the update (pass nil to skip tag change detection)
+// Changes to tracked fields are diffed against the stored row and recorded in todo_history, attributed to the
+// actor set with WithChangeActor.
 func (r *TodoRepository) Update(ctx context.Context, todo *models.Todo, oldTags []string) error {
+	defer r.db.observeQuery("todos.update", time.Now())
 	tagsChanged := r.detectAndLogTagChange(todo, oldTags)
 
 	metadataJSON, err := json.Marshal(todo.Metadata)
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
-	dueDate := todoDueDateNullTime(todo.DueDate)
-	completedAt := todoCompletedAtNullTime(todo.CompletedAt)
-	now := time.Now()
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	prev, err := selectTodoStateForUpdate(ctx, tx, todo.UserID, todo.ID)
+	if err != nil {
+		return err
+	}
+	if err := updateTodoRow(ctx, tx, todo, metadataJSON); err != nil {
+		return err
+	}
+	if changes := models.DiffTodos(prev, todo); len(changes) > 0 {
+		if err := recordTodoHistory(ctx, tx, todo, ChangeActorFromContext(ctx), changes); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	var deltas []models.TagDelta
+	if tagsChanged {
+		deltas = []models.TagDelta{models.NewTagDelta(&prev.Metadata, &todo.Metadata)}
+	}
+	r.invokeTagChangeHandlerIfNeeded(ctx, todo, tagsChanged, deltas)
+	return nil
+}
+
+// updateTodoRow writes the todo's mutable fields and refreshes UpdatedAt. The row must already be locked by
+// the transaction, so no matching row under an updated_at expected by WithExpectedUpdatedAt means the todo was
+// modified since.
+func updateTodoRow(ctx context.Context, tx *sql.Tx, todo *models.Todo, metadataJSON []byte) error {
 	query := `
 		UPDATE todos
-		SET text = $2, time_horizon = $3, status = $4, metadata = $5, due_date = $6, updated_at = $7, completed_at = $8
+		SET text = $2, time_horizon = $3, status = $4, metadata = $5, due_date = $6, updated_at = $7, completed_at = $8,
+			archived_at = $10, trashed_at = $11, priority = $12
 		WHERE id = $1 AND user_id = $9
-		RETURNING updated_at
 	`
-	err = r.db.QueryRowContext(ctx, query,
+	args := []any{
 		todo.ID, todo.Text, todo.TimeHorizon, todo.Status,
-		metadataJSON, dueDate, now, completedAt, todo.UserID,
-	).Scan(&todo.UpdatedAt)
-	if err == sql.ErrNoRows {
+		metadataJSON, todoDueDateNullTime(todo.DueDate), time.Now(), todoCompletedAtNullTime(todo.CompletedAt), todo.UserID,
+		nullTimeFromPtr(todo.ArchivedAt), nullTimeFromPtr(todo.TrashedAt), todoPriorityNullString(todo.Priority),
+	}
+	expected, conditional := ExpectedUpdatedAtFromContext(ctx)
+	if conditional {
+		query += " AND updated_at = $13"
+		args = append(args, expected)
+	}
+	err := tx.QueryRowContext(ctx, query+" RETURNING updated_at", args...).Scan(&todo.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) && conditional {
+		return ErrConcurrentModification
+	}
+	if errors.Is(err, sql.ErrNoRows) {
 		return ErrTodoNotFound
 	}
 	if err != nil {
 		return fmt.Errorf("failed to update todo: %w", err)
 	}
+	return nil
+}
+
+// ResetAITags removes AI-generated tags from all of a user's todos in one transaction, keeping user tags.
+// When requeue is true, processed todos that are not opted out of analysis are also returned to pending
+// so reprocessing analyzes them again. The tag change handler is invoked once if any tags were removed.
+// Returns the number of todos whose tags changed.
+func (r *TodoRepository) ResetAITags(ctx context.Context, userID uuid.UUID, requeue bool) (int, error) {
+	defer r.db.observeQuery("todos.reset_ai_tags", time.Now())
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	changed, err := resetAITagsTx(ctx, tx, userID, requeue)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if changed > 0 && r.tagChangeHandler != nil {
+		if err := r.tagChangeHandler(ctx, userID, nil); err != nil && r.logger != nil {
+			r.logger.Warn("tag_change_handler_failed",
+				zap.String("user_id", userID.String()),
+				zap.String("operation", "reset_ai_tags"),
+				zap.Error(err),
+			)
+		}
+	}
+	return changed, nil
+}
+
+// RenameTag replaces the tags in from with to across all of a user's todos in one transaction, merging them
+// where a todo has several (see models.Metadata.RenameTags), and records the todos' history like Update. The
+// tag change handler is then invoked once without deltas, so the tag statistics are marked tainted and
+// reanalyzed. Returns the number of todos updated.
+func (r *TodoRepository) RenameTag(ctx context.Context, userID uuid.UUID, from []string, to string) (int, error) {
+	defer r.db.observeQuery("todos.rename_tag", time.Now())
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	todos, err := selectTodosForBulkUpdate(ctx, tx, userID, TodoBulkSelection{
+		Filter:  TodoListFilter{IncludeRetired: true},
+		AnyTags: from,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	actor := ChangeActorFromContext(ctx)
+	updated := 0
+	for _, todo := range todos {
+		prev := *todo
+		if !todo.Metadata.RenameTags(from, to) {
+			continue
+		}
+		if err := saveBulkUpdatedTodo(ctx, tx, &prev, todo, actor); err != nil {
+			return 0, err
+		}
+		updated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if updated > 0 && r.tagChangeHandler != nil {
+		if err := r.tagChangeHandler(ctx, userID, nil); err != nil && r.logger != nil {
+			r.logger.Warn("tag_change_handler_failed",
+				zap.String("user_id", userID.String()),
+				zap.String("operation", "rename_tag"),
+				zap.Error(err),
+			)
+		}
+	}
+	return updated, nil
+}
+
+// RemoveTagFromAll removes tag and its recorded source from all of a user's todos in one transaction, keeping
+// the order of the remaining tags, and records the todos' history like Update. The tag change handler is then
+// invoked once without deltas, so the tag statistics are marked tainted and reanalyzed. Returns the number of
+// todos that had the tag; removing a tag no todo has any longer changes nothing and returns 0.
+func (r *TodoRepository) RemoveTagFromAll(ctx context.Context, userID uuid.UUID, tag string) (int, error) {
+	defer r.db.observeQuery("todos.remove_tag_from_all", time.Now())
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	todos, err := selectTodosForBulkUpdate(ctx, tx, userID, TodoBulkSelection{
+		Filter:  TodoListFilter{IncludeRetired: true},
+		AnyTags: []string{tag},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	actor := ChangeActorFromContext(ctx)
+	updated := 0
+	for _, todo := range todos {
+		prev := *todo
+		if !todo.Metadata.RemoveTags([]string{tag}) {
+			continue
+		}
+		if err := saveBulkUpdatedTodo(ctx, tx, &prev, todo, actor); err != nil {
+			return 0, err
+		}
+		updated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if updated > 0 && r.tagChangeHandler != nil {
+		if err := r.tagChangeHandler(ctx, userID, nil); err != nil && r.logger != nil {
+			r.logger.Warn("tag_change_handler_failed",
+				zap.String("user_id", userID.String()),
+				zap.String("operation", "remove_tag_from_all"),
+				zap.Error(err),
+			)
+		}
+	}
+	return updated, nil
+}
+
+// AIMemoryReset summarizes what ResetAIMemory cleared
+type AIMemoryReset struct {
+	// TodosUpdated is the number of todos whose AI tags were removed
+	TodosUpdated int
+	// TagStatsCleared is the number of tags removed from the tag statistics
+	TagStatsCleared int
+	// AIContextCleared reports whether a stored AI context was deleted
+	AIContextCleared bool
+}
+
+// ResetAIMemory clears everything the AI has learned about the user in one transaction, keeping their todos:
+// AI tags are removed from every todo (user tags stay), the tag statistics are replaced by an empty, tainted
+// record, and the AI context (summary, preferences, tag aliases and weights) is deleted. With requeue,
+// processed todos are returned to pending like ResetAITags. The tag change handler is not invoked; the
+// caller is expected to enqueue reprocessing.
+func (r *TodoRepository) ResetAIMemory(ctx context.Context, userID uuid.UUID, requeue bool) (AIMemoryReset, error) {
+	defer r.db.observeQuery("todos.reset_ai_memory", time.Now())
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return AIMemoryReset{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var reset AIMemoryReset
+	if reset.TodosUpdated, err = resetAITagsTx(ctx, tx, userID, requeue); err != nil {
+		return AIMemoryReset{}, err
+	}
+	if reset.TagStatsCleared, err = resetTagStatisticsTx(ctx, tx, userID); err != nil {
+		return AIMemoryReset{}, err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM ai_context WHERE user_id = $1`, userID)
+	if err != nil {
+		return AIMemoryReset{}, fmt.Errorf("failed to delete AI context: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		reset.AIContextCleared = n > 0
+	}
+
+	if err := tx.Commit(); err != nil {
+		return AIMemoryReset{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return reset, nil
+}
+
+// resetTagStatisticsTx replaces the user's tag statistics with an empty, tainted record within tx, creating
+// it if missing. The analysis version is bumped so a tag analysis already in flight cannot write back stale
+// statistics. Returns how many tags were cleared.
+func resetTagStatisticsTx(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (int, error) {
+	stats := &models.TagStatistics{UserID: userID}
+	var tagStatsJSON []byte
+	err := tx.QueryRowContext(ctx, `SELECT tag_stats FROM tag_statistics WHERE user_id = $1 FOR UPDATE`, userID).Scan(&tagStatsJSON)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to get tag statistics: %w", err)
+	}
+	if len(tagStatsJSON) > 0 {
+		if err := json.Unmarshal(tagStatsJSON, &stats.TagStats); err != nil {
+			return 0, fmt.Errorf("failed to unmarshal tag_stats: %w", err)
+		}
+	}
+	cleared := stats.Reset()
+
+	emptyStatsJSON, emptyPairsJSON, err := marshalTagStatistics(stats)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO tag_statistics (user_id, tag_stats, co_occurrence, tainted, analysis_version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 0, $5, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET tag_stats = EXCLUDED.tag_stats,
+		    co_occurrence = EXCLUDED.co_occurrence,
+		    tainted = EXCLUDED.tainted,
+		    last_analyzed_at = NULL,
+		    content_hash = NULL,
+		    analysis_version = tag_statistics.analysis_version + 1,
+		    updated_at = EXCLUDED.updated_at
+	`, userID, emptyStatsJSON, emptyPairsJSON, stats.Tainted, time.Now()); err != nil {
+		return 0, fmt.Errorf("failed to reset tag statistics: %w", err)
+	}
+	return cleared, nil
+}
+
+// resetAITagsTx removes AI tags from the user's todos within tx and, with requeue, returns processed todos to
+// pending. Returns how many todos lost tags.
+func resetAITagsTx(ctx context.Context, tx *sql.Tx, userID uuid.UUID, requeue bool) (int, error) {
+	todos, err := selectTodoMetadataForUpdate(ctx, tx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	now := time.Now()
+	for _, todo := range todos {
+		tagsRemoved := todo.Metadata.RemoveAITags()
+		requeued := requeue && todo.Status == models.TodoStatusProcessed && !todo.Metadata.AnalysisDisabled
+		if requeued {
+			todo.Status = models.TodoStatusPending
+		}
+		if !tagsRemoved && !requeued {
+			continue
+		}
+		if tagsRemoved {
+			changed++
+		}
+		metadataJSON, err := json.Marshal(todo.Metadata)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE todos SET metadata = $1, status = $2, updated_at = $3 WHERE id = $4 AND user_id = $5`,
+			metadataJSON, todo.Status, now, todo.ID, userID,
+		); err != nil {
+			return 0, fmt.Errorf("failed to update todo: %w", err)
+		}
+	}
+	return changed, nil
+}
+
+// TodoBulkSelection selects the todos a bulk operation applies to: the listed IDs when IDs is non-empty,
+// otherwise every todo matching Filter. Either way only the user's own todos are selected.
+type TodoBulkSelection struct {
+	IDs    []uuid.UUID
+	Filter TodoListFilter
+	// AnyTags further limits the selection to todos with at least one of these category tags
+	AnyTags []string
+}
+
+// BulkUpdateDueDates applies apply to each selected todo in one transaction and saves the todos for which
+// it reports a change, recording their history like Update. Returns the updated todos.
+func (r *TodoRepository) BulkUpdateDueDates(ctx context.Context, userID uuid.UUID, sel TodoBulkSelection, apply func(todo *models.Todo) bool) ([]*models.Todo, error) {
+	defer r.db.observeQuery("todos.bulk_update_due_dates", time.Now())
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	todos, err := selectTodosForBulkUpdate(ctx, tx, userID, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := ChangeActorFromContext(ctx)
+	var updated []*models.Todo
+	for _, todo := range todos {
+		prev := *todo
+		if !apply(todo) {
+			continue
+		}
+		if err := saveBulkUpdatedTodo(ctx, tx, &prev, todo, actor); err != nil {
+			return nil, err
+		}
+		updated = append(updated, todo)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return updated, nil
+}
+
+// BulkUpdateStatus moves the listed todos of a user to status in one transaction, recording their history
+// like Update. It returns the IDs of the todos now in status, including those already in it; IDs that are
+// missing, belong to another user or cannot move to status are left out. Status changes do not change tags,
+// so the tag change handler is not invoked.
+func (r *TodoRepository) BulkUpdateStatus(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, status models.TodoStatus) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	defer r.db.observeQuery("todos.bulk_update_status", time.Now())
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	todos, err := selectTodosForBulkUpdate(ctx, tx, userID, TodoBulkSelection{IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	actor := ChangeActorFromContext(ctx)
+	now := time.Now()
+	updated := make([]uuid.UUID, 0, len(todos))
+	for _, todo := range todos {
+		if todo.Status == status {
+			updated = append(updated, todo.ID)
+			continue
+		}
+		prev := *todo
+		if err := todo.TransitionTo(status, now); err != nil {
+			continue
+		}
+		if err := saveBulkUpdatedTodo(ctx, tx, &prev, todo, actor); err != nil {
+			return nil, err
+		}
+		updated = append(updated, todo.ID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return updated, nil
+}
+
+// BulkDelete deletes the listed todos of a user in one transaction and returns the IDs deleted; IDs that are
+// missing or belong to another user are left out. When any deleted todo had tags, the tag change handler is
+// invoked once with their removal.
+func (r *TodoRepository) BulkDelete(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	defer r.db.observeQuery("todos.bulk_delete", time.Now())
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	todos, err := selectTodosForBulkUpdate(ctx, tx, userID, TodoBulkSelection{IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+	if len(todos) == 0 {
+		return nil, nil
+	}
+
+	deleted := make([]uuid.UUID, 0, len(todos))
+	args := []any{userID}
+	placeholders := make([]string, 0, len(todos))
+	var deltas []models.TagDelta
+	for _, todo := range todos {
+		deleted = append(deleted, todo.ID)
+		args = append(args, todo.ID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		if len(todo.Metadata.CategoryTags) > 0 {
+			deltas = append(deltas, models.TagDelta{Before: todo.Metadata.TagSnapshot()})
+		}
+	}
+	query := fmt.Sprintf(`DELETE FROM todos WHERE user_id = $1 AND id IN (%s)`, strings.Join(placeholders, ", "))
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to delete todos: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if len(deltas) > 0 {
+		r.invokeTagChangeHandlerIfNeeded(ctx, todos[0], true, deltas)
+	}
+	return deleted, nil
+}
+
+// MergeTodos loads the user's target and source todos in one transaction, applies merge to them and saves
+// both, recording their history like Update. It returns ErrTodoNotFound unless both todos belong to the
+// user, and any error from merge unchanged. The tag change handler is invoked once after the merge.
+func (r *TodoRepository) MergeTodos(ctx context.Context, userID, targetID, sourceID uuid.UUID, merge func(target, source *models.Todo) error) (*models.Todo, error) {
+	defer r.db.observeQuery("todos.merge", time.Now())
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
 
-	r.invokeTagChangeHandlerIfNeeded(ctx, todo, tagsChanged)
+	todos, err := selectTodosForBulkUpdate(ctx, tx, userID, TodoBulkSelection{IDs: []uuid.UUID{targetID, sourceID}})
+	if err != nil {
+		return nil, err
+	}
+	var target, source *models.Todo
+	for _, todo := range todos {
+		switch todo.ID {
+		case targetID:
+			target = todo
+		case sourceID:
+			source = todo
+		}
+	}
+	if target == nil || source == nil {
+		return nil, ErrTodoNotFound
+	}
+
+	prevTarget, prevSource := *target, *source
+	if err := merge(target, source); err != nil {
+		return nil, err
+	}
+	actor := ChangeActorFromContext(ctx)
+	if err := saveBulkUpdatedTodo(ctx, tx, &prevTarget, target, actor); err != nil {
+		return nil, err
+	}
+	if err := saveBulkUpdatedTodo(ctx, tx, &prevSource, source, actor); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.invokeTagChangeHandlerIfNeeded(ctx, target, true, []models.TagDelta{models.NewTagDelta(&prevTarget.Metadata, &target.Metadata)})
+	return target, nil
+}
+
+// saveBulkUpdatedTodo writes a todo changed by a bulk operation and records its history
+func saveBulkUpdatedTodo(ctx context.Context, tx *sql.Tx, prev, todo *models.Todo, actor models.ChangeActor) error {
+	metadataJSON, err := json.Marshal(todo.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err := updateTodoRow(ctx, tx, todo, metadataJSON); err != nil {
+		return err
+	}
+	if changes := models.DiffTodos(prev, todo); len(changes) > 0 {
+		return recordTodoHistory(ctx, tx, todo, actor, changes)
+	}
 	return nil
 }
 
+// selectTodosForBulkUpdate loads the selected todos of a user, locking the rows
+func selectTodosForBulkUpdate(ctx context.Context, tx *sql.Tx, userID uuid.UUID, sel TodoBulkSelection) ([]*models.Todo, error) {
+	whereClause, _, args, argIndex := buildTodoListWhereClause(userID, sel.Filter)
+	if len(sel.IDs) > 0 {
+		whereClause, args = "WHERE user_id = $1", []any{userID}
+		argIndex = 2
+		placeholders := make([]string, len(sel.IDs))
+		for i, id := range sel.IDs {
+			placeholders[i] = fmt.Sprintf("$%d", argIndex)
+			args = append(args, id)
+			argIndex++
+		}
+		whereClause += fmt.Sprintf(" AND id IN (%s)", strings.Join(placeholders, ", "))
+	}
+	if len(sel.AnyTags) > 0 {
+		whereClause += fmt.Sprintf(" AND metadata->'category_tags' ?| $%d", argIndex)
+		args = append(args, pq.Array(sel.AnyTags))
+	}
+	query := fmt.Sprintf(`
+		SELECT id, user_id, text, time_horizon, status, metadata, due_date, priority, created_at, updated_at, completed_at, archived_at, trashed_at
+		FROM todos
+		%s
+		ORDER BY created_at DESC, id DESC
+		FOR UPDATE
+	`, whereClause)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query todos: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	return scanTodoRows(rows)
+}
+
+// selectTodoMetadataForUpdate loads the id, status and metadata of a user's todos, locking the rows
+func selectTodoMetadataForUpdate(ctx context.Context, tx *sql.Tx, userID uuid.UUID) ([]*models.Todo, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, status, metadata FROM todos WHERE user_id = $1 FOR UPDATE`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query todos: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var todos []*models.Todo
+	for rows.Next() {
+		todo := &models.Todo{UserID: userID}
+		var metadataJSON []byte
+		if err := rows.Scan(&todo.ID, &todo.Status, &metadataJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan todo: %w", err)
+		}
+		if err := json.Unmarshal(metadataJSON, &todo.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+		todos = append(todos, todo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate todos: %w", err)
+	}
+	return todos, nil
+}
+
 func (r *TodoRepository) detectAndLogTagChange(todo *models.Todo, oldTags []string) bool {
 	if r.tagStatsRepo == nil || oldTags == nil {
 		return false
@@ -370,7 +1509,28 @@ func todoCompletedAtNullTime(t *time.Time) sql.NullTime {
 	return sql.NullTime{Time: *t, Valid: true}
 }
 
-func (r *TodoRepository) invokeTagChangeHandlerIfNeeded(ctx context.Context, todo *models.Todo, tagsChanged bool) {
+// nullTimeFromPtr returns t as a NullTime that is NULL when t is nil
+// todoPriorityNullString converts an unset priority to NULL
+func todoPriorityNullString(p models.Priority) sql.NullString {
+	return sql.NullString{String: string(p), Valid: p != ""}
+}
+
+func nullTimeFromPtr(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// timePtrFromNull returns the time of a valid NullTime, or nil
+func timePtrFromNull(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func (r *TodoRepository) invokeTagChangeHandlerIfNeeded(ctx context.Context, todo *models.Todo, tagsChanged bool, deltas []models.TagDelta) {
 	if !tagsChanged || r.tagChangeHandler == nil {
 		return
 	}
@@ -380,7 +1540,7 @@ func (r *TodoRepository) invokeTagChangeHandlerIfNeeded(ctx context.Context, tod
 			zap.String("user_id", todo.UserID.String()),
 		)
 	}
-	if err := r.tagChangeHandler(ctx, todo.UserID); err != nil {
+	if err := r.tagChangeHandler(ctx, todo.UserID, deltas); err != nil {
 		if r.logger != nil {
 			r.logger.Warn("tag_change_handler_failed",
 				zap.String("user_id", todo.UserID.String()),
@@ -442,7 +1602,9 @@ func tagCountsEqual(a, b map[string]int) bool {
 func (r *TodoRepository) Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
 	query := `DELETE FROM todos WHERE id = $1 AND user_id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, id, userID)
+	result, err := timedResult(r.db, "todos.delete", func() (sql.Result, error) {
+		return r.db.ExecContext(ctx, query, id, userID)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete todo: %w", err)
 	}
@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/benvon/smart-todo/internal/models"
@@ -145,6 +147,88 @@ func TestTagsEqual(t *testing.T) {
 	}
 }
 
+// TestTodoRepository_TagChangeHandlerOnlyOnGenuineChange verifies that write paths which leave tags
+// untouched (completion, identical tag reassignment, no-op AI merges) never fire the tag change handler
+func TestTodoRepository_TagChangeHandlerOnlyOnGenuineChange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		mutate      func(todo *models.Todo)
+		wantInvoked bool
+	}{
+		{
+			name: "complete without tag change",
+			mutate: func(todo *models.Todo) {
+				todo.Status = models.TodoStatusCompleted
+			},
+			wantInvoked: false,
+		},
+		{
+			name: "same tags reassigned by user",
+			mutate: func(todo *models.Todo) {
+				todo.Metadata.SetUserTags([]string{"work", "errands"})
+			},
+			wantInvoked: false,
+		},
+		{
+			name: "identical AI tags merged",
+			mutate: func(todo *models.Todo) {
+				todo.Metadata.MergeTags([]string{"errands"}, todo.Metadata.GetUserTags())
+			},
+			wantInvoked: false,
+		},
+		{
+			name: "user tags reordered",
+			mutate: func(todo *models.Todo) {
+				todo.Metadata.SetUserTags([]string{"errands", "work"})
+			},
+			wantInvoked: false,
+		},
+		{
+			name: "new tag added",
+			mutate: func(todo *models.Todo) {
+				todo.Metadata.SetUserTags([]string{"work", "urgent"})
+			},
+			wantInvoked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			invoked := 0
+			repo := &TodoRepository{tagStatsRepo: &mockTagStatsRepoForTodosTest{}}
+			repo.SetTagChangeHandler(func(ctx context.Context, userID uuid.UUID, deltas []models.TagDelta) error {
+				invoked++
+				return nil
+			})
+			todo := &models.Todo{
+				ID:     uuid.New(),
+				UserID: uuid.New(),
+				Status: models.TodoStatusProcessed,
+				Metadata: models.Metadata{
+					CategoryTags: []string{"work", "errands"},
+					TagSources: map[string]models.TagSource{
+						"work":    models.TagSourceUser,
+						"errands": models.TagSourceAI,
+					},
+				},
+			}
+			oldTags := todo.Metadata.CategoryTags
+
+			tt.mutate(todo)
+			changed := repo.detectAndLogTagChange(todo, oldTags)
+			repo.invokeTagChangeHandlerIfNeeded(context.Background(), todo, changed, nil)
+
+			if (invoked > 0) != tt.wantInvoked {
+				t.Errorf("tag change handler invoked %d times, wantInvoked %v", invoked, tt.wantInvoked)
+			}
+		})
+	}
+}
+
 // mockTagStatsRepoForTodosTest is a minimal mock for testing
 type mockTagStatsRepoForTodosTest struct{}
 
@@ -165,3 +249,153 @@ func (m *mockTagStatsRepoForTodosTest) MarkTainted(ctx context.Context, userID u
 }
 
 var _ TagStatisticsRepositoryInterface = (*mockTagStatsRepoForTodosTest)(nil)
+
+func TestBuildTodoListWhereClause(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	next := models.TimeHorizonNext
+	pending := models.TodoStatusPending
+	urgent := models.PriorityUrgent
+	analyzed, notAnalyzed := true, false
+
+	tests := []struct {
+		name      string
+		filter    TodoListFilter
+		wantWhere string
+		wantArgs  []any
+	}{
+		{
+			name:      "no filters",
+			wantWhere: "WHERE user_id = $1 AND archived_at IS NULL AND trashed_at IS NULL",
+			wantArgs:  []any{userID},
+		},
+		{
+			name:      "including retired",
+			filter:    TodoListFilter{IncludeRetired: true},
+			wantWhere: "WHERE user_id = $1",
+			wantArgs:  []any{userID},
+		},
+		{
+			name:      "analyzed with time horizon",
+			filter:    TodoListFilter{TimeHorizon: &next, Analyzed: &analyzed},
+			wantWhere: "WHERE user_id = $1 AND time_horizon = $2 AND status IN ($3, $4) AND archived_at IS NULL AND trashed_at IS NULL",
+			wantArgs:  []any{userID, "next", "processed", "completed"},
+		},
+		{
+			name:      "not analyzed with time horizon and status",
+			filter:    TodoListFilter{TimeHorizon: &next, Status: &pending, Analyzed: &notAnalyzed, IncludeRetired: true},
+			wantWhere: "WHERE user_id = $1 AND time_horizon = $2 AND status = $3 AND status NOT IN ($4, $5)",
+			wantArgs:  []any{userID, "next", "pending", "processed", "completed"},
+		},
+		{
+			name:      "priority with status",
+			filter:    TodoListFilter{Status: &pending, Priority: &urgent},
+			wantWhere: "WHERE user_id = $1 AND status = $2 AND priority = $3 AND archived_at IS NULL AND trashed_at IS NULL",
+			wantArgs:  []any{userID, "pending", "urgent"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			where, count, args, next := buildTodoListWhereClause(userID, tt.filter)
+			if where != tt.wantWhere {
+				t.Errorf("where = %q, want %q", where, tt.wantWhere)
+			}
+			if count != "SELECT COUNT(*) FROM todos "+tt.wantWhere {
+				t.Errorf("count query = %q does not match where clause", count)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+			if next != len(tt.wantArgs)+1 {
+				t.Errorf("next arg index = %d, want %d", next, len(tt.wantArgs)+1)
+			}
+		})
+	}
+}
+
+func TestBuildRetentionSweepQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		action      models.RetentionAction
+		wantSet     string
+		wantPending string
+		wantErr     bool
+	}{
+		{
+			name:        "archive skips archived and trashed todos",
+			action:      models.RetentionActionArchive,
+			wantSet:     "SET archived_at = $1, updated_at = $1",
+			wantPending: "AND archived_at IS NULL AND trashed_at IS NULL",
+		},
+		{
+			name:        "trash also takes archived todos",
+			action:      models.RetentionActionTrash,
+			wantSet:     "SET trashed_at = $1, updated_at = $1",
+			wantPending: "AND trashed_at IS NULL\n",
+		},
+		{name: "unknown action", action: "delete", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			query, err := buildRetentionSweepQuery(tt.action)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("buildRetentionSweepQuery(%q) should fail", tt.action)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildRetentionSweepQuery(%q) error = %v", tt.action, err)
+			}
+			for _, want := range []string{
+				tt.wantSet,
+				tt.wantPending,
+				"WHERE status = 'completed' AND completed_at < $2",
+				"c.preferences->>'auto_archive' = 'false'",
+				"ORDER BY completed_at",
+				"LIMIT $3",
+				"FOR UPDATE SKIP LOCKED",
+			} {
+				if !strings.Contains(query, want) {
+					t.Errorf("query does not contain %q:\n%s", want, query)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoSort_OrderByClause(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		sort TodoSort
+		want string
+	}{
+		{"zero value lists newest first", TodoSort{}, "created_at DESC, id DESC"},
+		{"created_at ascending", TodoSort{Field: TodoSortCreatedAt, Order: SortAscending}, "created_at ASC, id ASC"},
+		{"due date ascending keeps undated last", TodoSort{Field: TodoSortDueDate, Order: SortAscending}, "due_date ASC NULLS LAST, created_at DESC, id DESC"},
+		{"due date descending keeps undated last", TodoSort{Field: TodoSortDueDate, Order: SortDescending}, "due_date DESC NULLS LAST, created_at DESC, id DESC"},
+		{"updated_at default order", TodoSort{Field: TodoSortUpdatedAt}, "updated_at DESC, created_at DESC, id DESC"},
+		{"time horizon by urgency", TodoSort{Field: TodoSortTimeHorizon}, "CASE time_horizon WHEN 'next' THEN 0 WHEN 'soon' THEN 1 ELSE 2 END ASC, created_at DESC, id DESC"},
+		{"priority by urgency keeps unset last", TodoSort{Field: TodoSortPriority}, "CASE priority WHEN 'urgent' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 WHEN 'low' THEN 3 END ASC NULLS LAST, created_at DESC, id DESC"},
+		{"priority least urgent first keeps unset last", TodoSort{Field: TodoSortPriority, Order: SortDescending}, "CASE priority WHEN 'urgent' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 WHEN 'low' THEN 3 END DESC NULLS LAST, created_at DESC, id DESC"},
+		{"unknown field falls back to newest first", TodoSort{Field: "text; DROP TABLE todos"}, "created_at DESC, id DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.sort.orderByClause(); got != tt.want {
+				t.Errorf("orderByClause() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -11,12 +12,34 @@ import (
 
 // UserActivityRepository handles user activity database operations
 type UserActivityRepository struct {
-	db *DB
+	db     *DB
+	window ReprocessingWindow
+}
+
+// ReprocessingWindow limits reprocessing to users whose last API interaction is at least MinIdle and at
+// most MaxIdle ago, so users who are still editing and dormant users are both skipped. Zero disables a bound.
+type ReprocessingWindow struct {
+	MinIdle time.Duration
+	MaxIdle time.Duration
+}
+
+// UserActivityRepositoryOption configures a UserActivityRepository
+type UserActivityRepositoryOption func(*UserActivityRepository)
+
+// WithReprocessingWindow sets the activity window used by GetEligibleUsersForReprocessing
+func WithReprocessingWindow(window ReprocessingWindow) UserActivityRepositoryOption {
+	return func(r *UserActivityRepository) {
+		r.window = window
+	}
 }
 
 // NewUserActivityRepository creates a new user activity repository
-func NewUserActivityRepository(db *DB) *UserActivityRepository {
-	return &UserActivityRepository{db: db}
+func NewUserActivityRepository(db *DB, opts ...UserActivityRepositoryOption) *UserActivityRepository {
+	r := &UserActivityRepository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // GetByUserID retrieves user activity by user ID
@@ -29,13 +52,15 @@ func (r *UserActivityRepository) GetByUserID(ctx context.Context, userID uuid.UU
 		WHERE user_id = $1
 	`
 	
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
-		&activity.UserID,
-		&activity.LastAPIInteraction,
-		&activity.ReprocessingPaused,
-		&activity.CreatedAt,
-		&activity.UpdatedAt,
-	)
+	err := r.db.timedQuery("user_activity.get_by_user_id", func() error {
+		return r.db.QueryRowContext(ctx, query, userID).Scan(
+			&activity.UserID,
+			&activity.LastAPIInteraction,
+			&activity.ReprocessingPaused,
+			&activity.CreatedAt,
+			&activity.UpdatedAt,
+		)
+	})
 	
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user activity: %w", err)
@@ -57,13 +82,15 @@ func (r *UserActivityRepository) Upsert(ctx context.Context, activity *models.Us
 	`
 	
 	now := time.Now()
-	err := r.db.QueryRowContext(ctx, query,
-		activity.UserID,
-		activity.LastAPIInteraction,
-		activity.ReprocessingPaused,
-		now,
-		now,
-	).Scan(&activity.CreatedAt, &activity.UpdatedAt)
+	err := r.db.timedQuery("user_activity.upsert", func() error {
+		return r.db.QueryRowContext(ctx, query,
+			activity.UserID,
+			activity.LastAPIInteraction,
+			activity.ReprocessingPaused,
+			now,
+			now,
+		).Scan(&activity.CreatedAt, &activity.UpdatedAt)
+	})
 	
 	if err != nil {
 		return fmt.Errorf("failed to upsert user activity: %w", err)
@@ -84,7 +111,9 @@ func (r *UserActivityRepository) UpdateLastInteraction(ctx context.Context, user
 	`
 	
 	now := time.Now()
-	_, err := r.db.ExecContext(ctx, query, userID, now, now)
+	_, err := timedResult(r.db, "user_activity.update_last_interaction", func() (sql.Result, error) {
+		return r.db.ExecContext(ctx, query, userID, now, now)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to update last interaction: %w", err)
 	}
@@ -100,7 +129,9 @@ func (r *UserActivityRepository) SetReprocessingPaused(ctx context.Context, user
 		WHERE user_id = $3
 	`
 	
-	_, err := r.db.ExecContext(ctx, query, paused, time.Now(), userID)
+	_, err := timedResult(r.db, "user_activity.set_reprocessing_paused", func() (sql.Result, error) {
+		return r.db.ExecContext(ctx, query, paused, time.Now(), userID)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to set reprocessing paused: %w", err)
 	}
@@ -111,13 +142,11 @@ func (r *UserActivityRepository) SetReprocessingPaused(ctx context.Context, user
 // GetEligibleUsersForReprocessing returns users who are eligible for reprocessing
 // (not paused, within activity window)
 func (r *UserActivityRepository) GetEligibleUsersForReprocessing(ctx context.Context) ([]uuid.UUID, error) {
-	query := `
-		SELECT user_id
-		FROM user_activity
-		WHERE reprocessing_paused = false
-	`
+	query, args := eligibleUsersQuery(r.window, time.Now())
 	
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := timedResult(r.db, "user_activity.get_eligible_users_for_reprocessing", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query, args...)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to query eligible users: %w", err)
 	}
@@ -153,7 +182,9 @@ func (r *UserActivityRepository) GetUsersNeedingReprocessingPause(ctx context.Co
 		  AND reprocessing_paused = false
 	`
 	
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := timedResult(r.db, "user_activity.get_users_needing_reprocessing_pause", func() (*sql.Rows, error) {
+		return r.db.QueryContext(ctx, query)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to query users needing pause: %w", err)
 	}
@@ -179,3 +210,21 @@ func (r *UserActivityRepository) GetUsersNeedingReprocessingPause(ctx context.Co
 	
 	return userIDs, nil
 }
+
+// eligibleUsersQuery builds the eligible-users query for the window, with cutoffs relative to now
+func eligibleUsersQuery(window ReprocessingWindow, now time.Time) (string, []any) {
+	query := `
+		SELECT user_id
+		FROM user_activity
+		WHERE reprocessing_paused = false`
+	var args []any
+	if window.MinIdle > 0 {
+		args = append(args, now.Add(-window.MinIdle))
+		query += fmt.Sprintf("\n\t\t  AND last_api_interaction <= $%d", len(args))
+	}
+	if window.MaxIdle > 0 {
+		args = append(args, now.Add(-window.MaxIdle))
+		query += fmt.Sprintf("\n\t\t  AND last_api_interaction >= $%d", len(args))
+	}
+	return query, args
+}
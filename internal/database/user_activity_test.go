@@ -0,0 +1,70 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestEligibleUsersQuery checks the query's bounds: both are inclusive, so a user idle for exactly MinIdle
+// or MaxIdle is eligible
+func TestEligibleUsersQuery(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		window    ReprocessingWindow
+		wantConds []string
+		wantArgs  []time.Time
+	}{
+		{
+			name: "no window keeps every unpaused user",
+		},
+		{
+			name:      "min idle only",
+			window:    ReprocessingWindow{MinIdle: time.Hour},
+			wantConds: []string{"last_api_interaction <= $1"},
+			wantArgs:  []time.Time{now.Add(-time.Hour)},
+		},
+		{
+			name:      "max idle only",
+			window:    ReprocessingWindow{MaxIdle: 7 * 24 * time.Hour},
+			wantConds: []string{"last_api_interaction >= $1"},
+			wantArgs:  []time.Time{now.Add(-7 * 24 * time.Hour)},
+		},
+		{
+			name:      "both bounds",
+			window:    ReprocessingWindow{MinIdle: time.Hour, MaxIdle: 7 * 24 * time.Hour},
+			wantConds: []string{"last_api_interaction <= $1", "last_api_interaction >= $2"},
+			wantArgs:  []time.Time{now.Add(-time.Hour), now.Add(-7 * 24 * time.Hour)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			query, args := eligibleUsersQuery(tt.window, now)
+			if !strings.Contains(query, "reprocessing_paused = false") {
+				t.Errorf("query does not exclude paused users: %s", query)
+			}
+			if got := strings.Count(query, "last_api_interaction"); got != len(tt.wantConds) {
+				t.Errorf("query has %d activity conditions, want %d: %s", got, len(tt.wantConds), query)
+			}
+			for _, cond := range tt.wantConds {
+				if !strings.Contains(query, cond) {
+					t.Errorf("query missing %q: %s", cond, query)
+				}
+			}
+			if len(args) != len(tt.wantArgs) {
+				t.Fatalf("args = %v, want %v", args, tt.wantArgs)
+			}
+			for i, want := range tt.wantArgs {
+				if got, ok := args[i].(time.Time); !ok || !got.Equal(want) {
+					t.Errorf("args[%d] = %v, want %v", i, args[i], want)
+				}
+			}
+		})
+	}
+}
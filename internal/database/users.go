@@ -23,21 +23,27 @@ func NewUserRepository(db *DB) *UserRepository {
 // Create creates a new user
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
-		INSERT INTO users (id, email, provider_id, name, email_verified, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO users (id, email, provider_id, name, email_verified, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at, updated_at
 	`
 	
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
 	now := time.Now()
-	err := r.db.QueryRowContext(ctx, query,
-		user.ID,
-		user.Email,
-		user.ProviderID,
-		user.Name,
-		user.EmailVerified,
-		now,
-		now,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	err := r.db.timedQuery("users.create", func() error {
+		return r.db.QueryRowContext(ctx, query,
+			user.ID,
+			user.Email,
+			user.ProviderID,
+			user.Name,
+			user.EmailVerified,
+			user.Role,
+			now,
+			now,
+		).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	})
 	
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
@@ -50,20 +56,23 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, email, provider_id, name, email_verified, created_at, updated_at
+		SELECT id, email, provider_id, name, email_verified, role, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
 	
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.ProviderID,
-		&user.Name,
-		&user.EmailVerified,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err := r.db.timedQuery("users.get_by_id", func() error {
+		return r.db.QueryRowContext(ctx, query, id).Scan(
+			&user.ID,
+			&user.Email,
+			&user.ProviderID,
+			&user.Name,
+			&user.EmailVerified,
+			&user.Role,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+	})
 	
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("user not found: %w", err)
@@ -79,20 +88,23 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, email, provider_id, name, email_verified, created_at, updated_at
+		SELECT id, email, provider_id, name, email_verified, role, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
 	
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.ProviderID,
-		&user.Name,
-		&user.EmailVerified,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err := r.db.timedQuery("users.get_by_email", func() error {
+		return r.db.QueryRowContext(ctx, query, email).Scan(
+			&user.ID,
+			&user.Email,
+			&user.ProviderID,
+			&user.Name,
+			&user.EmailVerified,
+			&user.Role,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+	})
 	
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("user not found: %w", err)
@@ -108,20 +120,23 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 func (r *UserRepository) GetByProviderID(ctx context.Context, providerID string) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, email, provider_id, name, email_verified, created_at, updated_at
+		SELECT id, email, provider_id, name, email_verified, role, created_at, updated_at
 		FROM users
 		WHERE provider_id = $1
 	`
 	
-	err := r.db.QueryRowContext(ctx, query, providerID).Scan(
-		&user.ID,
-		&user.Email,
-		&user.ProviderID,
-		&user.Name,
-		&user.EmailVerified,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err := r.db.timedQuery("users.get_by_provider_id", func() error {
+		return r.db.QueryRowContext(ctx, query, providerID).Scan(
+			&user.ID,
+			&user.Email,
+			&user.ProviderID,
+			&user.Name,
+			&user.EmailVerified,
+			&user.Role,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+	})
 	
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("user not found: %w", err)
@@ -137,20 +152,23 @@ func (r *UserRepository) GetByProviderID(ctx context.Context, providerID string)
 func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users
-		SET email = $2, provider_id = $3, name = $4, email_verified = $5, updated_at = $6
+		SET email = $2, provider_id = $3, name = $4, email_verified = $5, role = $6, updated_at = $7
 		WHERE id = $1
 		RETURNING updated_at
 	`
 	
 	now := time.Now()
-	err := r.db.QueryRowContext(ctx, query,
-		user.ID,
-		user.Email,
-		user.ProviderID,
-		user.Name,
-		user.EmailVerified,
-		now,
-	).Scan(&user.UpdatedAt)
+	err := r.db.timedQuery("users.update", func() error {
+		return r.db.QueryRowContext(ctx, query,
+			user.ID,
+			user.Email,
+			user.ProviderID,
+			user.Name,
+			user.EmailVerified,
+			user.Role,
+			now,
+		).Scan(&user.UpdatedAt)
+	})
 	
 	if err == sql.ErrNoRows {
 		return fmt.Errorf("user not found")
@@ -166,7 +184,9 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM users WHERE id = $1`
 	
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := timedResult(r.db, "users.delete", func() (sql.Result, error) {
+		return r.db.ExecContext(ctx, query, id)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -182,3 +202,43 @@ func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	
 	return nil
 }
+
+// tagRecomputeLockKey is the advisory lock key held while tag analysis is enqueued for every user, so only one
+// recompute runs at a time. The value is arbitrary but must not be reused for another lock.
+const tagRecomputeLockKey int64 = 0x736d74645f746167
+
+// ForEachIDForTagRecompute streams every user ID, in ID order, to fn under the tag recompute advisory lock. It
+// reports locked=false without calling fn when another recompute holds the lock. An error from fn stops the
+// stream and is returned.
+func (r *UserRepository) ForEachIDForTagRecompute(ctx context.Context, fn func(uuid.UUID) error) (locked bool, err error) {
+	defer r.db.observeQuery("users.for_each_id_for_tag_recompute", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if locked, err = tryAdvisoryXactLock(ctx, tx, tagRecomputeLockKey); err != nil || !locked {
+		return false, err
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM users ORDER BY id`)
+	if err != nil {
+		return true, fmt.Errorf("failed to query user IDs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return true, fmt.Errorf("failed to scan user ID: %w", err)
+		}
+		if err := fn(id); err != nil {
+			return true, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return true, fmt.Errorf("failed to iterate user IDs: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrWeeklySummaryNotFound is returned when a user has no stored weekly summary
+var ErrWeeklySummaryNotFound = errors.New("weekly summary not found")
+
+// weeklySummaryScheduleLockKey is the advisory lock key held while weekly summaries are scheduled, so only one
+// worker enqueues them at a time
+const weeklySummaryScheduleLockKey int64 = 0x736d74645f776b73
+
+// WeeklySummaryRepository handles weekly summary database operations
+type WeeklySummaryRepository struct {
+	db *DB
+}
+
+// NewWeeklySummaryRepository creates a new weekly summary repository
+func NewWeeklySummaryRepository(db *DB) *WeeklySummaryRepository {
+	return &WeeklySummaryRepository{db: db}
+}
+
+// ClaimWeeklySummaryUsers marks the users due a summary for weekStart as scheduled and returns them: users
+// not yet scheduled for weekStart whose reprocessing is not paused, who have not opted out with the
+// weekly_summary preference and, unless activeSince is zero, who used the API since activeSince. It reports
+// locked=false without claiming anyone when another worker is already scheduling.
+func (r *WeeklySummaryRepository) ClaimWeeklySummaryUsers(ctx context.Context, weekStart, activeSince time.Time) (claimed []uuid.UUID, locked bool, err error) {
+	defer r.db.observeQuery("weekly_summaries.claim_users", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if locked, err = tryAdvisoryXactLock(ctx, tx, weeklySummaryScheduleLockKey); err != nil || !locked {
+		return nil, false, err
+	}
+	var since sql.NullTime
+	if !activeSince.IsZero() {
+		since = sql.NullTime{Time: activeSince, Valid: true}
+	}
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE user_activity ua SET weekly_summary_week = $1
+		WHERE (ua.weekly_summary_week IS NULL OR ua.weekly_summary_week < $1)
+			AND NOT ua.reprocessing_paused
+			AND ($2::timestamp IS NULL OR ua.last_api_interaction >= $2)
+			AND NOT EXISTS (
+				SELECT 1 FROM ai_context ac
+				WHERE ac.user_id = ua.user_id AND ac.preferences->>'`+models.PreferenceWeeklySummary+`' = 'false'
+			)
+		RETURNING ua.user_id
+	`, weekStart, since)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to claim weekly summary users: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, true, fmt.Errorf("failed to scan weekly summary user: %w", err)
+		}
+		claimed = append(claimed, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, true, fmt.Errorf("failed to iterate weekly summary users: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, true, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return claimed, true, nil
+}
+
+// UnclaimWeeklySummaryUser undoes the user's claim for weekStart, e.g. when their job could not be enqueued,
+// so the next scheduling run claims them again
+func (r *WeeklySummaryRepository) UnclaimWeeklySummaryUser(ctx context.Context, userID uuid.UUID, weekStart time.Time) error {
+	_, err := timedResult(r.db, "weekly_summaries.unclaim_user", func() (sql.Result, error) {
+		return r.db.ExecContext(ctx,
+			`UPDATE user_activity SET weekly_summary_week = NULL WHERE user_id = $1 AND weekly_summary_week = $2`,
+			userID, weekStart,
+		)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unclaim weekly summary user: %w", err)
+	}
+	return nil
+}
+
+// Save stores a weekly summary, replacing any earlier summary for the same user and week
+func (r *WeeklySummaryRepository) Save(ctx context.Context, summary *models.WeeklySummary) error {
+	query := `
+		INSERT INTO weekly_summaries (user_id, week_start, summary, completed_count, pending_count, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, week_start) DO UPDATE SET
+			summary = EXCLUDED.summary,
+			completed_count = EXCLUDED.completed_count,
+			pending_count = EXCLUDED.pending_count,
+			created_at = EXCLUDED.created_at
+	`
+	return r.db.timedQuery("weekly_summaries.save", func() error {
+		if _, err := r.db.ExecContext(ctx, query,
+			summary.UserID, summary.WeekStart, summary.Summary, summary.CompletedCount, summary.PendingCount, summary.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to save weekly summary: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetLatestByUserID returns the user's most recent weekly summary, or ErrWeeklySummaryNotFound
+func (r *WeeklySummaryRepository) GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*models.WeeklySummary, error) {
+	query := `
+		SELECT user_id, week_start, summary, completed_count, pending_count, created_at
+		FROM weekly_summaries
+		WHERE user_id = $1
+		ORDER BY week_start DESC
+		LIMIT 1
+	`
+	summary := &models.WeeklySummary{}
+	err := r.db.timedQuery("weekly_summaries.get_latest_by_user_id", func() error {
+		return r.db.QueryRowContext(ctx, query, userID).Scan(
+			&summary.UserID, &summary.WeekStart, &summary.Summary, &summary.CompletedCount, &summary.PendingCount, &summary.CreatedAt,
+		)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWeeklySummaryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly summary: %w", err)
+	}
+	return summary, nil
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/database"
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/services/ai"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// ConfigReloader is implemented by hot-reloaded config (e.g. CORS, rate limits) that can be reloaded on demand.
+type ConfigReloader interface {
+	Reload(ctx context.Context) error
+}
+
+// AnalysisPauseSwitch reads and toggles the global AI analysis kill switch
+type AnalysisPauseSwitch interface {
+	Status(ctx context.Context) (models.AIAnalysisPause, error)
+	SetPaused(ctx context.Context, paused bool, reason string) (models.AIAnalysisPause, error)
+}
+
+// TagRecomputeRunner enqueues tag analysis for every user; *workers.TagRecomputer implements it
+type TagRecomputeRunner interface {
+	RecomputeAll(ctx context.Context) (models.TagRecomputeResult, error)
+}
+
+// AdminHandler handles operator-only support endpoints. Routes must be mounted behind admin auth.
+type AdminHandler struct {
+	todoRepo     database.TodoRepositoryInterface
+	contextRepo  database.AIContextRepositoryInterface
+	tagStatsRepo database.TagStatisticsRepositoryInterface
+	aiProvider   ai.AIProvider
+	aiUsageRepo  database.AIUsageRepositoryInterface
+	aiPrices     models.AIPriceTable
+	pauseSwitch  AnalysisPauseSwitch
+	tagRecompute TagRecomputeRunner
+	reloaders    map[string]ConfigReloader
+	debugMode    bool
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// AdminHandlerOption configures an AdminHandler.
+type AdminHandlerOption func(*AdminHandler)
+
+// WithAdminAnalysis enables the debug-analyze endpoint using the given provider and the user's
+// AI context and tag statistics, mirroring the inputs the analysis worker uses.
+func WithAdminAnalysis(provider ai.AIProvider, contextRepo database.AIContextRepositoryInterface, tagStatsRepo database.TagStatisticsRepositoryInterface) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.aiProvider = provider
+		h.contextRepo = contextRepo
+		h.tagStatsRepo = tagStatsRepo
+	}
+}
+
+// WithAdminDebug enables the debug-analyze endpoint when the server runs in debug mode; without it the
+// endpoint reports the feature disabled even to admins
+func WithAdminDebug(debugMode bool) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.debugMode = debugMode
+	}
+}
+
+// WithAdminAICost enables the AI cost summary, pricing the recorded AI usage with prices
+func WithAdminAICost(usageRepo database.AIUsageRepositoryInterface, prices models.AIPriceTable) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.aiUsageRepo = usageRepo
+		h.aiPrices = prices
+	}
+}
+
+// WithAdminAnalysisPause enables the endpoint that pauses and resumes AI analysis for all users
+func WithAdminAnalysisPause(pauseSwitch AnalysisPauseSwitch) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.pauseSwitch = pauseSwitch
+	}
+}
+
+// WithAdminTagRecompute enables the endpoint that re-runs tag analysis for every user
+func WithAdminTagRecompute(runner TagRecomputeRunner) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.tagRecompute = runner
+	}
+}
+
+// WithAdminConfigReloader registers a named config reloader for the config reload endpoint.
+func WithAdminConfigReloader(name string, reloader ConfigReloader) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		if reloader != nil {
+			h.reloaders[name] = reloader
+		}
+	}
+}
+
+// NewAdminHandler creates a new admin handler. Options enable individual admin features.
+func NewAdminHandler(todoRepo database.TodoRepositoryInterface, logger *zap.Logger, opts ...AdminHandlerOption) *AdminHandler {
+	h := &AdminHandler{todoRepo: todoRepo, logger: logger, reloaders: make(map[string]ConfigReloader), now: time.Now}
+	for _, o := range opts {
+		o(h)
+	}
+	return h
+}
+
+// RegisterRoutes registers admin routes on the given router
+// The router should already have the /admin prefix and admin auth middleware applied
+func (h *AdminHandler) RegisterRoutes(r *mux.Router) {
+	// Only register debug-analyze in debug mode and if the provider can trace its analysis; outside debug
+	// mode or without any AI provider the route reports the feature disabled
+	if !h.debugMode {
+		r.HandleFunc("/todos/{id}/debug-analyze", FeatureDisabledHandler("Debug analysis")).Methods("POST")
+	} else if _, ok := h.aiProvider.(ai.AIProviderWithTrace); ok {
+		r.HandleFunc("/todos/{id}/debug-analyze", h.DebugAnalyzeTodo).Methods("POST")
+	} else if h.aiProvider == nil {
+		r.HandleFunc("/todos/{id}/debug-analyze", FeatureDisabledHandler("AI analysis")).Methods("POST")
+	}
+	if len(h.reloaders) > 0 {
+		r.HandleFunc("/config/reload", h.ReloadConfig).Methods("POST")
+	}
+	if h.aiUsageRepo != nil {
+		r.HandleFunc("/ai/cost", h.AICostSummary).Methods("GET")
+	}
+	if h.pauseSwitch != nil {
+		r.HandleFunc("/ai/pause", h.SetAnalysisPause).Methods("PUT")
+	}
+	if h.tagRecompute != nil {
+		r.HandleFunc("/tags/recompute-all", h.RecomputeAllTags).Methods("POST")
+	}
+}
+
+// RecomputeAllTags enqueues a tag analysis job for every user, paced apart, and returns how many were
+// enqueued. Only one run may be in progress across all servers; a concurrent request gets 409.
+func (h *AdminHandler) RecomputeAllTags(w http.ResponseWriter, r *http.Request) {
+	result, err := h.tagRecompute.RecomputeAll(r.Context())
+	if err != nil {
+		h.logger.Error("admin_tag_recompute_failed",
+			zap.Int("enqueued", result.Enqueued),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error",
+			fmt.Sprintf("Failed to enqueue tag analysis after %d jobs", result.Enqueued))
+		return
+	}
+	if !result.Locked {
+		respondJSONError(w, http.StatusConflict, "Conflict", "A tag recompute is already in progress")
+		return
+	}
+	h.logger.Info("admin_tag_recompute_enqueued", zap.Int("enqueued", result.Enqueued))
+	respondJSON(w, http.StatusOK, result)
+}
+
+// SetAnalysisPauseRequest pauses (paused true) or resumes AI analysis; reason is an optional operator note
+type SetAnalysisPauseRequest struct {
+	Paused *bool  `json:"paused"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SetAnalysisPause pauses or resumes AI analysis for all users and returns the new state. While paused,
+// todos are still created but stay pending; workers hold their analysis jobs until analysis resumes.
+func (h *AdminHandler) SetAnalysisPause(w http.ResponseWriter, r *http.Request) {
+	var req SetAnalysisPauseRequest
+	if err := decodeJSONBody(r, &req, false); err != nil {
+		respondBodyDecodeError(w, err)
+		return
+	}
+	if req.Paused == nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "paused is required")
+		return
+	}
+	if len(req.Reason) > models.MaxAIPauseReasonLength {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", fmt.Sprintf("reason must be at most %d characters", models.MaxAIPauseReasonLength))
+		return
+	}
+
+	state, err := h.pauseSwitch.SetPaused(r.Context(), *req.Paused, req.Reason)
+	if err != nil {
+		h.logger.Error("admin_ai_pause_failed",
+			zap.Bool("paused", *req.Paused),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to update AI analysis pause")
+		return
+	}
+	h.logger.Info("admin_ai_analysis_pause_changed", zap.Bool("paused", state.Paused))
+	respondJSON(w, http.StatusOK, state)
+}
+
+// DefaultAICostTopUsers and MaxAICostTopUsers bound the number of users in the AI cost summary
+const (
+	DefaultAICostTopUsers = 10
+	MaxAICostTopUsers     = 100
+)
+
+// AICostSummary reports the estimated AI cost of the last 30 days by operation and for the top users by cost
+// (top, default 10). Costs are estimated from the recorded token usage and the configured price table.
+func (h *AdminHandler) AICostSummary(w http.ResponseWriter, r *http.Request) {
+	top := DefaultAICostTopUsers
+	if v := r.URL.Query().Get("top"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxAICostTopUsers {
+			respondJSONError(w, http.StatusBadRequest, "Bad Request", fmt.Sprintf("top must be between 1 and %d", MaxAICostTopUsers))
+			return
+		}
+		top = n
+	}
+
+	until := h.now().UTC()
+	since := until.Add(-models.AICostWindow)
+	byOperation, byUser, err := h.aiUsageRepo.Totals(r.Context(), since)
+	if err != nil {
+		h.logger.Error("admin_ai_cost_summary_failed", zap.String("error", logpkg.SanitizeError(err)))
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to load AI usage")
+		return
+	}
+	respondJSON(w, http.StatusOK, models.NewAICostSummary(since, until, byOperation, byUser, h.aiPrices, top))
+}
+
+// ReloadConfig forces every registered config reloader to reload now instead of waiting for its next tick.
+// Each reloader's result is reported; a failed reload still applies that reloader's fallback config.
+func (h *AdminHandler) ReloadConfig(w http.ResponseWriter, r *http.Request) {
+	results := make(map[string]string, len(h.reloaders))
+	status := http.StatusOK
+	for name, reloader := range h.reloaders {
+		if err := reloader.Reload(r.Context()); err != nil {
+			h.logger.Warn("admin_config_reload_failed",
+				zap.String("config", name),
+				zap.String("error", logpkg.SanitizeError(err)),
+			)
+			results[name] = "failed: " + sanitizeErrorMessage(err.Error())
+			status = http.StatusInternalServerError
+			continue
+		}
+		results[name] = "reloaded"
+	}
+	h.logger.Info("admin_config_reloaded", zap.Int("reloaders", len(h.reloaders)))
+	respondJSON(w, status, results)
+}
+
+// DebugAnalyzeResponse is the response body for a synchronous debug analysis
+type DebugAnalyzeResponse struct {
+	TodoID uuid.UUID         `json:"todo_id"`
+	UserID uuid.UUID         `json:"user_id"`
+	Text   string            `json:"text"`
+	Trace  *ai.AnalysisTrace `json:"trace"`
+}
+
+// DebugAnalyzeTodo runs the AI analysis for any user's todo synchronously with debug logging forced on
+// and returns the full prompt, raw response, parse result and timings. Nothing is persisted. As with the
+// user endpoint, use_tag_stats=false leaves the user's tag statistics out of the prompt.
+func (h *AdminHandler) DebugAnalyzeTodo(w http.ResponseWriter, r *http.Request) {
+	tracer, ok := h.aiProvider.(ai.AIProviderWithTrace)
+	if !ok {
+		respondJSONError(w, http.StatusNotImplemented, "Not Implemented", "AI provider does not support debug analysis")
+		return
+	}
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid todo ID")
+		return
+	}
+	useTagStats, err := parseUseTagStats(r)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	todo, err := h.todoRepo.GetByID(ctx, id)
+	if err != nil {
+		respondJSONError(w, http.StatusNotFound, "Not Found", "Todo not found")
+		return
+	}
+
+	userContext, tagStats := h.analysisInputs(ctx, todo.UserID)
+	if !useTagStats {
+		tagStats = nil
+	}
+	ctxWithIDs := context.WithValue(ctx, ai.UserIDContextKey(), todo.UserID)
+	ctxWithIDs = context.WithValue(ctxWithIDs, ai.TodoIDContextKey(), todo.ID)
+	ctxWithIDs = context.WithValue(ctxWithIDs, ai.DueDateOnlyContextKey(), todo.Metadata.DueDateOnly)
+	ctxWithIDs = context.WithValue(ctxWithIDs, ai.PriorityContextKey(), todo.Priority)
+
+	trace, err := tracer.TraceAnalysis(ctxWithIDs, todo.Text, todo.DueDate, todo.EnteredAt(), userContext, tagStats)
+	if err != nil {
+		h.logger.Error("debug_analysis_failed",
+			zap.String("operation", "debug_analyze_todo"),
+			zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+			zap.String("user_id", logpkg.SanitizeUserID(todo.UserID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to run debug analysis")
+		return
+	}
+
+	h.logger.Info("debug_analysis_completed",
+		zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+		zap.String("user_id", logpkg.SanitizeUserID(todo.UserID.String())),
+	)
+	respondJSON(w, http.StatusOK, DebugAnalyzeResponse{
+		TodoID: todo.ID,
+		UserID: todo.UserID,
+		Text:   todo.Text,
+		Trace:  trace,
+	})
+}
+
+// analysisInputs loads the user's AI context and tag statistics. Missing data is not an error;
+// the analysis worker proceeds without them too.
+func (h *AdminHandler) analysisInputs(ctx context.Context, userID uuid.UUID) (*models.AIContext, *models.TagStatistics) {
+	var userContext *models.AIContext
+	var tagStats *models.TagStatistics
+	if h.contextRepo != nil {
+		userContext, _ = h.contextRepo.GetByUserID(ctx, userID)
+	}
+	if h.tagStatsRepo != nil {
+		tagStats, _ = h.tagStatsRepo.GetByUserID(ctx, userID)
+	}
+	return userContext, tagStats
+}
@@ -0,0 +1,671 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/database"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/services/ai"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// mockTodoRepoForHandlers is a minimal TodoRepositoryInterface for handlers that take the interface
+type mockTodoRepoForHandlers struct {
+	t                     *testing.T
+	getByIDFunc           func(ctx context.Context, id uuid.UUID) (*models.Todo, error)
+	getByUserIDAndIDFunc  func(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Todo, error)
+	resetAITagsFunc       func(ctx context.Context, userID uuid.UUID, requeue bool) (int, error)
+	updateFunc            func(ctx context.Context, todo *models.Todo, oldTags []string) error
+	listByUserIDFunc      func(ctx context.Context, userID uuid.UUID, filter database.TodoListFilter, page, pageSize int) ([]*models.Todo, int, error)
+	listByUserIDAfterFunc func(ctx context.Context, userID uuid.UUID, filter database.TodoListFilter, after *database.TodoCursor, limit int) ([]*models.Todo, error)
+	listOpenByUserIDFunc  func(ctx context.Context, userID uuid.UUID, limit int) ([]*models.Todo, error)
+	countByStatusFunc     func(ctx context.Context, userID uuid.UUID) (*models.TodoCounts, error)
+	getByExternalRefFunc  func(ctx context.Context, userID uuid.UUID, system, id string) (*models.Todo, error)
+	createCalls           []*models.Todo
+	updateCalls           []*models.Todo
+	// bulkTodos backs BulkUpdateDueDates, BulkUpdateStatus, BulkDelete and MergeTodos, which select from it by ID or filter like the repository
+	bulkTodos      []*models.Todo
+	bulkSelections []database.TodoBulkSelection
+	// renameTagCalls counts RenameTag calls, which rename tags in bulkTodos unless renameTagErr is set
+	renameTagCalls int
+	renameTagErr   error
+	// removeTagCalls counts RemoveTagFromAll calls, which remove the tag from bulkTodos unless removeTagErr is set
+	removeTagCalls int
+	removeTagErr   error
+}
+
+func (m *mockTodoRepoForHandlers) Create(ctx context.Context, todo *models.Todo) error {
+	m.createCalls = append(m.createCalls, todo)
+	return nil
+}
+
+func (m *mockTodoRepoForHandlers) CreateBatch(ctx context.Context, todos []*models.Todo) error {
+	m.createCalls = append(m.createCalls, todos...)
+	return nil
+}
+
+func (m *mockTodoRepoForHandlers) GetByID(ctx context.Context, id uuid.UUID) (*models.Todo, error) {
+	if m.getByIDFunc == nil {
+		m.t.Fatal("GetByID called but not configured in test - mock requires explicit setup")
+	}
+	return m.getByIDFunc(ctx, id)
+}
+
+func (m *mockTodoRepoForHandlers) GetByUserIDAndID(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Todo, error) {
+	if m.getByUserIDAndIDFunc == nil {
+		m.t.Fatal("GetByUserIDAndID called but not configured in test - mock requires explicit setup")
+	}
+	return m.getByUserIDAndIDFunc(ctx, userID, id)
+}
+
+func (m *mockTodoRepoForHandlers) Update(ctx context.Context, todo *models.Todo, oldTags []string) error {
+	m.updateCalls = append(m.updateCalls, todo)
+	if m.updateFunc != nil {
+		return m.updateFunc(ctx, todo, oldTags)
+	}
+	return nil
+}
+
+func (m *mockTodoRepoForHandlers) Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	m.t.Fatal("Delete should not be called")
+	return nil
+}
+
+func (m *mockTodoRepoForHandlers) GetByUserIDPaginated(ctx context.Context, userID uuid.UUID, timeHorizon *models.TimeHorizon, status *models.TodoStatus, page, pageSize int) ([]*models.Todo, int, error) {
+	m.t.Fatal("GetByUserIDPaginated should not be called")
+	return nil, 0, nil
+}
+
+func (m *mockTodoRepoForHandlers) ListByUserID(ctx context.Context, userID uuid.UUID, filter database.TodoListFilter, page, pageSize int) ([]*models.Todo, int, error) {
+	if m.listByUserIDFunc == nil {
+		m.t.Fatal("ListByUserID called but not configured in test - mock requires explicit setup")
+	}
+	return m.listByUserIDFunc(ctx, userID, filter, page, pageSize)
+}
+
+func (m *mockTodoRepoForHandlers) ListByUserIDAfter(ctx context.Context, userID uuid.UUID, filter database.TodoListFilter, after *database.TodoCursor, limit int) ([]*models.Todo, error) {
+	if m.listByUserIDAfterFunc == nil {
+		m.t.Fatal("ListByUserIDAfter called but not configured in test - mock requires explicit setup")
+	}
+	return m.listByUserIDAfterFunc(ctx, userID, filter, after, limit)
+}
+
+func (m *mockTodoRepoForHandlers) ListOpenByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*models.Todo, error) {
+	if m.listOpenByUserIDFunc == nil {
+		m.t.Fatal("ListOpenByUserID called but not configured in test - mock requires explicit setup")
+	}
+	return m.listOpenByUserIDFunc(ctx, userID, limit)
+}
+
+func (m *mockTodoRepoForHandlers) CountByStatusAndHorizon(ctx context.Context, userID uuid.UUID) (*models.TodoCounts, error) {
+	if m.countByStatusFunc == nil {
+		m.t.Fatal("CountByStatusAndHorizon called but not configured in test - mock requires explicit setup")
+	}
+	return m.countByStatusFunc(ctx, userID)
+}
+
+func (m *mockTodoRepoForHandlers) GetDueWithin(ctx context.Context, window time.Duration) ([]*models.Todo, error) {
+	m.t.Fatal("GetDueWithin should not be called")
+	return nil, nil
+}
+
+func (m *mockTodoRepoForHandlers) MarkReminderSent(ctx context.Context, userID, id uuid.UUID, dueDate, sentAt time.Time) (bool, error) {
+	m.t.Fatal("MarkReminderSent should not be called")
+	return false, nil
+}
+
+func (m *mockTodoRepoForHandlers) GetByExternalRef(ctx context.Context, userID uuid.UUID, system, id string) (*models.Todo, error) {
+	if m.getByExternalRefFunc == nil {
+		m.t.Fatal("GetByExternalRef called but not configured in test - mock requires explicit setup")
+	}
+	return m.getByExternalRefFunc(ctx, userID, system, id)
+}
+
+func (m *mockTodoRepoForHandlers) ResetAITags(ctx context.Context, userID uuid.UUID, requeue bool) (int, error) {
+	if m.resetAITagsFunc == nil {
+		m.t.Fatal("ResetAITags called but not configured in test - mock requires explicit setup")
+	}
+	return m.resetAITagsFunc(ctx, userID, requeue)
+}
+
+func (m *mockTodoRepoForHandlers) BulkUpdateDueDates(ctx context.Context, userID uuid.UUID, sel database.TodoBulkSelection, apply func(todo *models.Todo) bool) ([]*models.Todo, error) {
+	m.bulkSelections = append(m.bulkSelections, sel)
+	var updated []*models.Todo
+	for _, todo := range m.bulkTodos {
+		if todo.UserID != userID || !bulkSelectionMatches(sel, todo) {
+			continue
+		}
+		if apply(todo) {
+			updated = append(updated, todo)
+		}
+	}
+	return updated, nil
+}
+
+func (m *mockTodoRepoForHandlers) BulkUpdateStatus(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, status models.TodoStatus) ([]uuid.UUID, error) {
+	sel := database.TodoBulkSelection{IDs: ids}
+	m.bulkSelections = append(m.bulkSelections, sel)
+	var updated []uuid.UUID
+	for _, todo := range m.bulkTodos {
+		if todo.UserID != userID || !bulkSelectionMatches(sel, todo) {
+			continue
+		}
+		if err := todo.TransitionTo(status, time.Now()); err == nil {
+			updated = append(updated, todo.ID)
+		}
+	}
+	return updated, nil
+}
+
+func (m *mockTodoRepoForHandlers) BulkDelete(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]uuid.UUID, error) {
+	sel := database.TodoBulkSelection{IDs: ids}
+	m.bulkSelections = append(m.bulkSelections, sel)
+	var deleted []uuid.UUID
+	kept := m.bulkTodos[:0]
+	for _, todo := range m.bulkTodos {
+		if todo.UserID == userID && bulkSelectionMatches(sel, todo) {
+			deleted = append(deleted, todo.ID)
+			continue
+		}
+		kept = append(kept, todo)
+	}
+	m.bulkTodos = kept
+	return deleted, nil
+}
+
+func (m *mockTodoRepoForHandlers) MergeTodos(ctx context.Context, userID, targetID, sourceID uuid.UUID, merge func(target, source *models.Todo) error) (*models.Todo, error) {
+	var target, source *models.Todo
+	for _, todo := range m.bulkTodos {
+		switch {
+		case todo.UserID != userID:
+		case todo.ID == targetID:
+			target = todo
+		case todo.ID == sourceID:
+			source = todo
+		}
+	}
+	if target == nil || source == nil {
+		return nil, database.ErrTodoNotFound
+	}
+	if err := merge(target, source); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+func (m *mockTodoRepoForHandlers) RenameTag(ctx context.Context, userID uuid.UUID, from []string, to string) (int, error) {
+	m.renameTagCalls++
+	if m.renameTagErr != nil {
+		return 0, m.renameTagErr
+	}
+	updated := 0
+	for _, todo := range m.bulkTodos {
+		if todo.UserID == userID && todo.Metadata.RenameTags(from, to) {
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (m *mockTodoRepoForHandlers) RemoveTagFromAll(ctx context.Context, userID uuid.UUID, tag string) (int, error) {
+	m.removeTagCalls++
+	if m.removeTagErr != nil {
+		return 0, m.removeTagErr
+	}
+	removed := 0
+	for _, todo := range m.bulkTodos {
+		if todo.UserID == userID && todo.Metadata.RemoveTags([]string{tag}) {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func bulkSelectionMatches(sel database.TodoBulkSelection, todo *models.Todo) bool {
+	if len(sel.IDs) > 0 {
+		for _, id := range sel.IDs {
+			if id == todo.ID {
+				return true
+			}
+		}
+		return false
+	}
+	if sel.Filter.TimeHorizon != nil && *sel.Filter.TimeHorizon != todo.TimeHorizon {
+		return false
+	}
+	return sel.Filter.Status == nil || *sel.Filter.Status == todo.Status
+}
+
+func (m *mockTodoRepoForHandlers) SetTagStatsRepo(repo database.TagStatisticsRepositoryInterface) {}
+
+func (m *mockTodoRepoForHandlers) SetTagChangeHandler(handler database.TagChangeHandler) {}
+
+var _ database.TodoRepositoryInterface = (*mockTodoRepoForHandlers)(nil)
+
+// mockTracingProvider implements ai.AIProviderWithTrace and records the inputs it was given
+type mockTracingProvider struct {
+	ai.AIProvider
+	trace       *ai.AnalysisTrace
+	gotText     string
+	gotTagStats *models.TagStatistics
+}
+
+func (m *mockTracingProvider) TraceAnalysis(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) (*ai.AnalysisTrace, error) {
+	m.gotText = text
+	m.gotTagStats = tagStats
+	return m.trace, nil
+}
+
+type mockAIContextRepoForHandlers struct{}
+
+func (m *mockAIContextRepoForHandlers) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.AIContext, error) {
+	return nil, errors.New("not found")
+}
+
+func TestAdminHandler_DebugAnalyzeTodo(t *testing.T) {
+	t.Parallel()
+
+	todo := &models.Todo{ID: uuid.New(), UserID: uuid.New(), Text: "Call the bank about the mortgage", CreatedAt: time.Now()}
+	stats := &models.TagStatistics{UserID: todo.UserID, TagStats: map[string]models.TagStats{"finance": {Total: 4}}}
+
+	tests := []struct {
+		name         string
+		todoID       string
+		query        string
+		wantStatus   int
+		wantTagStats bool
+	}{
+		{"returns trace without persisting", todo.ID.String(), "", http.StatusOK, true},
+		{"tag stats disabled", todo.ID.String(), "?use_tag_stats=false", http.StatusOK, false},
+		{"invalid use_tag_stats", todo.ID.String(), "?use_tag_stats=maybe", http.StatusBadRequest, false},
+		{"unknown todo", uuid.New().String(), "", http.StatusNotFound, false},
+		{"invalid id", "not-a-uuid", "", http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := &mockTodoRepoForHandlers{
+				t: t,
+				getByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Todo, error) {
+					if id != todo.ID {
+						return nil, errors.New("todo not found")
+					}
+					return todo, nil
+				},
+			}
+			tagStatsRepo := &mockTagStatisticsRepoForHandlers{
+				t: t,
+				getByUserIDFunc: func(ctx context.Context, userID uuid.UUID) (*models.TagStatistics, error) {
+					return stats, nil
+				},
+			}
+			provider := &mockTracingProvider{trace: &ai.AnalysisTrace{
+				Prompt:      "prompt",
+				RawResponse: `{"tags":["finance"],"time_horizon":"soon"}`,
+				Tags:        []string{"finance"},
+				TimeHorizon: models.TimeHorizonSoon,
+			}}
+			h := NewAdminHandler(repo, zap.NewNop(), WithAdminDebug(true), WithAdminAnalysis(provider, &mockAIContextRepoForHandlers{}, tagStatsRepo))
+			router := mux.NewRouter()
+			h.RegisterRoutes(router)
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+tt.todoID+"/debug-analyze"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if len(repo.updateCalls) != 0 {
+				t.Errorf("debug analysis persisted %d updates, want none", len(repo.updateCalls))
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body struct {
+				Data DebugAnalyzeResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.Data.TodoID != todo.ID || body.Data.Trace == nil || body.Data.Trace.RawResponse != provider.trace.RawResponse {
+				t.Errorf("unexpected response: %+v", body.Data)
+			}
+			if provider.gotText != todo.Text {
+				t.Errorf("analyzed text = %q, want %q", provider.gotText, todo.Text)
+			}
+			if (provider.gotTagStats == stats) != tt.wantTagStats || (!tt.wantTagStats && provider.gotTagStats != nil) {
+				t.Errorf("tag stats = %v, want owner's stats = %v", provider.gotTagStats, tt.wantTagStats)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_DebugAnalyzeRouteDisabled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []AdminHandlerOption
+	}{
+		{name: "no AI provider", opts: []AdminHandlerOption{WithAdminDebug(true)}},
+		{name: "not in debug mode", opts: []AdminHandlerOption{WithAdminAnalysis(&mockTracingProvider{}, &mockAIContextRepoForHandlers{}, nil)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := NewAdminHandler(&mockTodoRepoForHandlers{t: t}, zap.NewNop(), tt.opts...)
+			router := mux.NewRouter()
+			h.RegisterRoutes(router)
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+uuid.New().String()+"/debug-analyze", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want 403", w.Code)
+			}
+			if w.Header().Get("Retry-After") != "" {
+				t.Error("a disabled feature should not send Retry-After")
+			}
+			if !strings.Contains(w.Body.String(), ErrorTypeFeatureDisabled) {
+				t.Errorf("body = %s, want error %q", w.Body.String(), ErrorTypeFeatureDisabled)
+			}
+		})
+	}
+}
+
+type fakeConfigReloader struct {
+	err   error
+	calls int
+}
+
+func (f *fakeConfigReloader) Reload(ctx context.Context) error {
+	f.calls++
+	return f.err
+}
+
+func TestAdminHandler_ReloadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		corsErr    error
+		wantStatus int
+		wantCORS   string
+	}{
+		{"all reloaded", nil, http.StatusOK, "reloaded"},
+		{"one reloader fails", errors.New("db down"), http.StatusInternalServerError, "failed: db down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cors := &fakeConfigReloader{err: tt.corsErr}
+			rateLimit := &fakeConfigReloader{}
+			h := NewAdminHandler(&mockTodoRepoForHandlers{t: t}, zap.NewNop(),
+				WithAdminConfigReloader("cors", cors),
+				WithAdminConfigReloader("rate_limit", rateLimit),
+			)
+			router := mux.NewRouter()
+			h.RegisterRoutes(router)
+
+			req := httptest.NewRequest(http.MethodPost, "/config/reload", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if cors.calls != 1 || rateLimit.calls != 1 {
+				t.Errorf("reload calls cors=%d rate_limit=%d, want 1 each", cors.calls, rateLimit.calls)
+			}
+			var body struct {
+				Success bool              `json:"success"`
+				Data    map[string]string `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.Success != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("success = %v with status %d", body.Success, w.Code)
+			}
+			if body.Data["cors"] != tt.wantCORS || body.Data["rate_limit"] != "reloaded" {
+				t.Errorf("results = %v", body.Data)
+			}
+		})
+	}
+}
+
+type fakeAIUsageRepo struct {
+	since       time.Time
+	byOperation []models.AIUsageTotal
+	byUser      []models.AIUsageTotal
+	err         error
+}
+
+func (f *fakeAIUsageRepo) Totals(ctx context.Context, since time.Time) ([]models.AIUsageTotal, []models.AIUsageTotal, error) {
+	f.since = since
+	return f.byOperation, f.byUser, f.err
+}
+
+func TestAdminHandler_AICostSummary(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	prices := models.AIPriceTable{"model-a": {InputPerMillion: 1, OutputPerMillion: 4}}
+	users := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	tests := []struct {
+		name       string
+		query      string
+		repoErr    error
+		wantStatus int
+		wantUsers  int
+	}{
+		{name: "default top users", wantStatus: http.StatusOK, wantUsers: 3},
+		{name: "top N", query: "?top=2", wantStatus: http.StatusOK, wantUsers: 2},
+		{name: "top out of range", query: "?top=0", wantStatus: http.StatusBadRequest},
+		{name: "top not a number", query: "?top=all", wantStatus: http.StatusBadRequest},
+		{name: "repository error", repoErr: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := &fakeAIUsageRepo{
+				byOperation: []models.AIUsageTotal{
+					{Operation: "analyze_task", Model: "model-a", Calls: 10, PromptTokens: 1_000_000, CompletionTokens: 250_000},
+					{Operation: "chat", Model: "model-a", Calls: 2, PromptTokens: 500_000, CompletionTokens: 0},
+				},
+				err: tt.repoErr,
+			}
+			for i, userID := range users {
+				repo.byUser = append(repo.byUser, models.AIUsageTotal{UserID: userID, Model: "model-a", Calls: 1, PromptTokens: int64(i+1) * 1000})
+			}
+			h := NewAdminHandler(&mockTodoRepoForHandlers{t: t}, zap.NewNop(), WithAdminAICost(repo, prices))
+			h.now = func() time.Time { return now }
+			router := mux.NewRouter()
+			h.RegisterRoutes(router)
+
+			req := httptest.NewRequest(http.MethodGet, "/ai/cost"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if want := now.Add(-models.AICostWindow); !repo.since.Equal(want) {
+				t.Errorf("usage since %v, want %v", repo.since, want)
+			}
+			var body struct {
+				Data models.AICostSummary `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.Data.TotalEstimatedCostUSD != 2.5 {
+				t.Errorf("total cost = %v, want 2.5", body.Data.TotalEstimatedCostUSD)
+			}
+			if len(body.Data.ByOperation) != 2 || body.Data.ByOperation[0].Operation != "analyze_task" {
+				t.Errorf("by operation = %+v, want analyze_task first", body.Data.ByOperation)
+			}
+			if len(body.Data.TopUsers) != tt.wantUsers || *body.Data.TopUsers[0].UserID != users[2] {
+				t.Errorf("top users = %+v, want %d users led by the costliest", body.Data.TopUsers, tt.wantUsers)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_AICostRouteRequiresUsageRepo(t *testing.T) {
+	t.Parallel()
+
+	h := NewAdminHandler(&mockTodoRepoForHandlers{t: t}, zap.NewNop())
+	router := mux.NewRouter()
+	h.RegisterRoutes(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ai/cost", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 without AI usage tracking", w.Code)
+	}
+}
+
+type fakeAnalysisPauseSwitch struct {
+	state models.AIAnalysisPause
+	err   error
+}
+
+func (f *fakeAnalysisPauseSwitch) Status(ctx context.Context) (models.AIAnalysisPause, error) {
+	return f.state, f.err
+}
+
+func (f *fakeAnalysisPauseSwitch) SetPaused(ctx context.Context, paused bool, reason string) (models.AIAnalysisPause, error) {
+	if f.err != nil {
+		return models.AIAnalysisPause{}, f.err
+	}
+	f.state = models.AIAnalysisPause{Paused: paused}
+	if paused {
+		f.state.Reason = reason
+	}
+	return f.state, nil
+}
+
+func TestAdminHandler_SetAnalysisPause(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		switchErr  error
+		wantStatus int
+		wantPaused bool
+	}{
+		{name: "pause", body: `{"paused": true, "reason": "provider outage"}`, wantStatus: http.StatusOK, wantPaused: true},
+		{name: "resume", body: `{"paused": false}`, wantStatus: http.StatusOK},
+		{name: "missing paused", body: `{"reason": "outage"}`, wantStatus: http.StatusBadRequest},
+		{name: "reason too long", body: `{"paused": true, "reason": "` + strings.Repeat("x", models.MaxAIPauseReasonLength+1) + `"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid JSON", body: `{"paused":`, wantStatus: http.StatusBadRequest},
+		{name: "switch fails", body: `{"paused": true}`, switchErr: errors.New("redis down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sw := &fakeAnalysisPauseSwitch{err: tt.switchErr}
+			h := NewAdminHandler(&mockTodoRepoForHandlers{t: t}, zap.NewNop(), WithAdminAnalysisPause(sw))
+			router := mux.NewRouter()
+			h.RegisterRoutes(router)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/ai/pause", strings.NewReader(tt.body)))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body struct {
+				Data models.AIAnalysisPause `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.Data.Paused != tt.wantPaused || sw.state.Paused != tt.wantPaused {
+				t.Errorf("paused = %v (switch %v), want %v", body.Data.Paused, sw.state.Paused, tt.wantPaused)
+			}
+		})
+	}
+}
+
+type fakeTagRecomputeRunner struct {
+	result models.TagRecomputeResult
+	err    error
+}
+
+func (f *fakeTagRecomputeRunner) RecomputeAll(ctx context.Context) (models.TagRecomputeResult, error) {
+	return f.result, f.err
+}
+
+func TestAdminHandler_RecomputeAllTags(t *testing.T) {
+	t.Parallel()
+
+	lastRunAt := time.Date(2026, 10, 16, 12, 5, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		result       models.TagRecomputeResult
+		err          error
+		wantStatus   int
+		wantEnqueued int
+	}{
+		{name: "enqueued", result: models.TagRecomputeResult{Locked: true, Enqueued: 42, LastRunAt: &lastRunAt}, wantStatus: http.StatusOK, wantEnqueued: 42},
+		{name: "no users", result: models.TagRecomputeResult{Locked: true}, wantStatus: http.StatusOK},
+		{name: "already running", result: models.TagRecomputeResult{}, wantStatus: http.StatusConflict},
+		{name: "enqueue fails", result: models.TagRecomputeResult{Locked: true, Enqueued: 3}, err: errors.New("broker down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := NewAdminHandler(&mockTodoRepoForHandlers{t: t}, zap.NewNop(),
+				WithAdminTagRecompute(&fakeTagRecomputeRunner{result: tt.result, err: tt.err}),
+			)
+			router := mux.NewRouter()
+			h.RegisterRoutes(router)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tags/recompute-all", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body struct {
+				Data models.TagRecomputeResult `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.Data.Enqueued != tt.wantEnqueued || (body.Data.LastRunAt == nil) != (tt.result.LastRunAt == nil) {
+				t.Errorf("response = %+v, want %d enqueued and last_run_at %v", body.Data, tt.wantEnqueued, tt.result.LastRunAt)
+			}
+		})
+	}
+}
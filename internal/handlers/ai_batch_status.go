@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// AnalysisBatchCounter aggregates a user's todos by analysis state in one query
+type AnalysisBatchCounter interface {
+	CountAnalysisBatch(ctx context.Context, userID uuid.UUID, stalledBefore, recentSince time.Time) (models.AnalysisBatchCounts, error)
+}
+
+// AIBatchStatusHandler reports overall AI analysis progress for the current user, e.g. after a large import
+type AIBatchStatusHandler struct {
+	counter AnalysisBatchCounter
+	stats   queue.StatsReporter
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+// NewAIBatchStatusHandler creates a new AI batch status handler. A nil stats reporter omits the queue depth.
+func NewAIBatchStatusHandler(counter AnalysisBatchCounter, stats queue.StatsReporter, logger *zap.Logger) *AIBatchStatusHandler {
+	return &AIBatchStatusHandler{counter: counter, stats: stats, logger: logger, now: time.Now}
+}
+
+// RegisterRoutes registers AI batch status routes
+// The router should already have the /ai prefix
+func (h *AIBatchStatusHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/batch-status", h.GetBatchStatus).Methods("GET")
+}
+
+// GetBatchStatus returns how many of the user's todos are analyzed, pending, processing or failed, with the
+// shared queue depth and an estimated completion time based on the user's recent analysis throughput
+func (h *AIBatchStatusHandler) GetBatchStatus(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+
+	ctx := r.Context()
+	now := h.now()
+	counts, err := h.counter.CountAnalysisBatch(ctx, user.ID, now.Add(-models.AnalysisStallTimeout), now.Add(-models.AnalysisThroughputWindow))
+	if err != nil {
+		h.logger.Error("failed_to_count_analysis_batch",
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to get AI batch status")
+		return
+	}
+
+	status := models.NewAnalysisBatchStatus(counts, models.AnalysisThroughputWindow, now)
+	if h.stats != nil {
+		if stats, err := h.stats.QueueStats(ctx); err != nil {
+			h.logger.Warn("failed_to_get_queue_stats",
+				zap.String("operation", "get_batch_status"),
+				zap.String("error", logpkg.SanitizeError(err)),
+			)
+		} else {
+			status.QueueDepth = &stats.Depth
+		}
+	}
+	respondJSON(w, http.StatusOK, status)
+}
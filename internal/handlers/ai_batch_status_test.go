@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type stubAnalysisBatchCounter struct {
+	counts        models.AnalysisBatchCounts
+	err           error
+	userID        uuid.UUID
+	stalledBefore time.Time
+	recentSince   time.Time
+}
+
+func (s *stubAnalysisBatchCounter) CountAnalysisBatch(ctx context.Context, userID uuid.UUID, stalledBefore, recentSince time.Time) (models.AnalysisBatchCounts, error) {
+	s.userID, s.stalledBefore, s.recentSince = userID, stalledBefore, recentSince
+	return s.counts, s.err
+}
+
+// failingQueueStats is a queue that cannot report its depth
+type failingQueueStats struct{}
+
+func (failingQueueStats) QueueStats(ctx context.Context) (queue.QueueStats, error) {
+	return queue.QueueStats{}, errors.New("channel closed")
+}
+
+func TestAIBatchStatusHandler_GetBatchStatus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	depth := 120
+	mixed := models.AnalysisBatchCounts{Analyzed: 40, Pending: 8, Processing: 2, Failed: 1, RecentlyAnalyzed: 20}
+	tests := []struct {
+		name           string
+		counter        *stubAnalysisBatchCounter
+		stats          queue.StatsReporter
+		withUser       bool
+		wantStatus     int
+		wantDepth      *int
+		wantCompletion *time.Time
+	}{
+		{
+			name:           "mixed statuses with queue depth",
+			counter:        &stubAnalysisBatchCounter{counts: mixed},
+			stats:          stubQueueStats{depth: 120},
+			withUser:       true,
+			wantStatus:     http.StatusOK,
+			wantDepth:      &depth,
+			wantCompletion: timePtr(now.Add(7*time.Minute + 30*time.Second)),
+		},
+		{
+			name:           "queue stats unavailable",
+			counter:        &stubAnalysisBatchCounter{counts: mixed},
+			stats:          failingQueueStats{},
+			withUser:       true,
+			wantStatus:     http.StatusOK,
+			wantCompletion: timePtr(now.Add(7*time.Minute + 30*time.Second)),
+		},
+		{
+			name:       "no queue stats reporter and nothing recent",
+			counter:    &stubAnalysisBatchCounter{counts: models.AnalysisBatchCounts{Pending: 3}},
+			withUser:   true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "repository error",
+			counter:    &stubAnalysisBatchCounter{err: errors.New("connection refused")},
+			withUser:   true,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "no user",
+			counter:    &stubAnalysisBatchCounter{},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler := NewAIBatchStatusHandler(tt.counter, tt.stats, zap.NewNop())
+			handler.now = func() time.Time { return now }
+			user := &models.User{ID: uuid.New()}
+			req := httptest.NewRequest("GET", "/api/v1/ai/batch-status", nil)
+			if tt.withUser {
+				req = setUserInRequestContext(req, user)
+			}
+			w := httptest.NewRecorder()
+			handler.GetBatchStatus(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if tt.counter.userID != user.ID {
+				t.Errorf("counted batch for user %s, want %s", tt.counter.userID, user.ID)
+			}
+			if !tt.counter.stalledBefore.Equal(now.Add(-models.AnalysisStallTimeout)) || !tt.counter.recentSince.Equal(now.Add(-models.AnalysisThroughputWindow)) {
+				t.Errorf("stalledBefore = %v, recentSince = %v", tt.counter.stalledBefore, tt.counter.recentSince)
+			}
+			var resp struct {
+				Data models.AnalysisBatchStatus `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			got := resp.Data
+			c := tt.counter.counts
+			if got.Analyzed != c.Analyzed || got.Pending != c.Pending || got.Processing != c.Processing || got.Failed != c.Failed {
+				t.Errorf("data = %+v, want counts %+v", got, c)
+			}
+			switch {
+			case tt.wantDepth == nil && got.QueueDepth != nil:
+				t.Errorf("queue_depth = %d, want omitted", *got.QueueDepth)
+			case tt.wantDepth != nil && (got.QueueDepth == nil || *got.QueueDepth != *tt.wantDepth):
+				t.Errorf("queue_depth = %v, want %d", got.QueueDepth, *tt.wantDepth)
+			}
+			switch {
+			case tt.wantCompletion == nil && got.EstimatedCompletionAt != nil:
+				t.Errorf("estimated_completion_at = %v, want omitted", *got.EstimatedCompletionAt)
+			case tt.wantCompletion != nil && (got.EstimatedCompletionAt == nil || !got.EstimatedCompletionAt.Equal(*tt.wantCompletion)):
+				t.Errorf("estimated_completion_at = %v, want %v", got.EstimatedCompletionAt, *tt.wantCompletion)
+			}
+		})
+	}
+}
@@ -1,26 +1,66 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
-	"github.com/benvon/smart-todo/internal/database"
 	"github.com/benvon/smart-todo/internal/models"
 	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"go.uber.org/zap"
 )
 
+// AIContextStore loads and saves a user's AI context
+type AIContextStore interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.AIContext, error)
+	Upsert(ctx context.Context, aiContext *models.AIContext) error
+}
+
 // AIContextHandler handles AI context-related requests
 type AIContextHandler struct {
-	contextRepo *database.AIContextRepository
+	contextRepo      AIContextStore
+	maxSummaryLength int
+	strictJSON       bool
+	summarizer       ContextSummarizer
+	logger           *zap.Logger
+}
+
+// AIContextHandlerOption configures an AIContextHandler.
+type AIContextHandlerOption func(*AIContextHandler)
+
+// WithAIContextMaxSummaryLength sets the maximum length in characters of a context summary written through
+// PUT (default models.DefaultMaxContextSummaryLength).
+func WithAIContextMaxSummaryLength(maxLength int) AIContextHandlerOption {
+	return func(h *AIContextHandler) {
+		h.maxSummaryLength = maxLength
+	}
+}
+
+// WithAIContextStrictJSON rejects summarize request bodies with unknown fields (400 naming the field), as
+// WithTodoStrictJSON does for todos.
+func WithAIContextStrictJSON(enabled bool) AIContextHandlerOption {
+	return func(h *AIContextHandler) {
+		h.strictJSON = enabled
+	}
 }
 
 // NewAIContextHandler creates a new AI context handler
-func NewAIContextHandler(contextRepo *database.AIContextRepository) *AIContextHandler {
-	return &AIContextHandler{
-		contextRepo: contextRepo,
+func NewAIContextHandler(contextRepo AIContextStore, opts ...AIContextHandlerOption) *AIContextHandler {
+	h := &AIContextHandler{
+		contextRepo:      contextRepo,
+		maxSummaryLength: models.DefaultMaxContextSummaryLength,
+		logger:           zap.NewNop(),
 	}
+	for _, o := range opts {
+		o(h)
+	}
+	return h
 }
 
 // RegisterRoutes registers AI context routes on the given router
@@ -28,15 +68,22 @@ func NewAIContextHandler(contextRepo *database.AIContextRepository) *AIContextHa
 func (h *AIContextHandler) RegisterRoutes(r *mux.Router) {
 	r.HandleFunc("", h.GetContext).Methods("GET")
 	r.HandleFunc("", h.UpdateContext).Methods("PUT")
+	r.HandleFunc("", h.DeleteContext).Methods("DELETE")
+	r.HandleFunc("/summarize", h.SummarizeContext).Methods("POST")
 }
 
-// GetContextRequest represents a get context request (empty for now, but could be extended)
+// GetContextResponse is the user's AI context summary and how it was derived
 type GetContextResponse struct {
-	ContextSummary string         `json:"context_summary,omitempty"`
+	ContextSummary string         `json:"context_summary"`
 	Preferences    map[string]any `json:"preferences,omitempty"`
+	// SummaryUpdatedAt is when the summary was last rewritten, by chat or by the user
+	SummaryUpdatedAt *time.Time `json:"summary_updated_at,omitempty"`
+	// SummaryChatTurns is how many user chat messages the summary was derived from (0 if written by the user)
+	SummaryChatTurns int `json:"summary_chat_turns"`
 }
 
-// GetContext returns the current user's AI context
+// GetContext returns the current user's AI context summary, when it was last updated and how many chat
+// turns contributed to it. A user without a stored context gets an empty summary.
 func (h *AIContextHandler) GetContext(w http.ResponseWriter, r *http.Request) {
 	user := request.UserFromContext(r)
 	if user == nil {
@@ -44,28 +91,24 @@ func (h *AIContextHandler) GetContext(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := r.Context()
-
-	// Get or create context
-	aiContext, err := h.contextRepo.GetByUserID(ctx, user.ID)
-	if err != nil {
-		// Create new context if not found
-		aiContext = &models.AIContext{
-			UserID:      user.ID,
-			Preferences: make(map[string]any),
-		}
-		if err := h.contextRepo.Create(ctx, aiContext); err != nil {
-			respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to create AI context")
-			return
-		}
+	aiContext, err := h.contextRepo.GetByUserID(r.Context(), user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		aiContext = &models.AIContext{UserID: user.ID}
+	} else if err != nil {
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to get AI context")
+		return
 	}
 
-	response := GetContextResponse{
-		ContextSummary: aiContext.ContextSummary,
-		Preferences:    aiContext.Preferences,
-	}
+	respondJSON(w, http.StatusOK, contextResponse(aiContext))
+}
 
-	respondJSON(w, http.StatusOK, response)
+func contextResponse(aiContext *models.AIContext) GetContextResponse {
+	return GetContextResponse{
+		ContextSummary:   aiContext.ContextSummary,
+		Preferences:      aiContext.Preferences,
+		SummaryUpdatedAt: aiContext.SummaryUpdatedAt,
+		SummaryChatTurns: aiContext.SummaryChatTurns,
+	}
 }
 
 // UpdateContextRequest represents an update context request
@@ -93,6 +136,12 @@ func (h *AIContextHandler) UpdateContext(w http.ResponseWriter, r *http.Request)
 		respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid request body")
 		return
 	}
+	if req.ContextSummary != nil {
+		if err := models.ValidateContextSummaryLength(*req.ContextSummary, h.maxSummaryLength); err != nil {
+			respondJSONError(w, http.StatusBadRequest, "Bad Request", fmt.Sprintf("context_summary must be at most %d characters", h.maxSummaryLength))
+			return
+		}
+	}
 
 	ctx := r.Context()
 
@@ -106,9 +155,9 @@ func (h *AIContextHandler) UpdateContext(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	// Update context summary if provided
+	// Update context summary if provided; a user-written summary no longer derives from chat
 	if req.ContextSummary != nil {
-		aiContext.ContextSummary = *req.ContextSummary
+		aiContext.SetContextSummary(*req.ContextSummary, 0, time.Now())
 	}
 
 	// Update preferences if provided (merge with existing)
@@ -128,10 +177,35 @@ func (h *AIContextHandler) UpdateContext(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	response := GetContextResponse{
-		ContextSummary: aiContext.ContextSummary,
-		Preferences:    aiContext.Preferences,
+	respondJSON(w, http.StatusOK, contextResponse(aiContext))
+}
+
+// DeleteContext clears the current user's context summary and preferences, so analysis proceeds without
+// them. Tag aliases and weights are kept; they have their own endpoints. Responds 204 whether or not a
+// context was stored.
+func (h *AIContextHandler) DeleteContext(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
 	}
 
-	respondJSON(w, http.StatusOK, response)
+	ctx := r.Context()
+	aiContext, err := h.contextRepo.GetByUserID(ctx, user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to get AI context")
+		return
+	}
+
+	aiContext.SetContextSummary("", 0, time.Now())
+	aiContext.Preferences = make(map[string]any)
+	if err := h.contextRepo.Upsert(ctx, aiContext); err != nil {
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to clear AI context")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/benvon/smart-todo/internal/services/ai"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// MaxSummarizeMessages is the maximum number of messages one summarize request may send
+const MaxSummarizeMessages = 100
+
+// ContextSummarizer replaces a user's context summary with an AI summary of a conversation.
+// *ai.ContextService implements it.
+type ContextSummarizer interface {
+	SummarizeConversation(ctx context.Context, userID uuid.UUID, conversationHistory []ai.ChatMessage) (*models.AIContext, error)
+}
+
+// WithAIContextSummarizer enables POST /summarize through summarizer; without it the route answers
+// 403 Feature Disabled.
+func WithAIContextSummarizer(summarizer ContextSummarizer, logger *zap.Logger) AIContextHandlerOption {
+	return func(h *AIContextHandler) {
+		h.summarizer = summarizer
+		h.logger = logger
+	}
+}
+
+// SummarizeContextRequest is the conversation to derive the user's context summary from
+type SummarizeContextRequest struct {
+	Messages []ai.ChatMessage `json:"messages"`
+}
+
+// SummarizeContext replaces the current user's context summary with an AI summary of the conversation in
+// the body and returns the updated context, so clients can offer an explicit "update my preferences" action
+func (h *AIContextHandler) SummarizeContext(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	if h.summarizer == nil {
+		respondFeatureDisabled(w, "AI context summaries")
+		return
+	}
+
+	var req SummarizeContextRequest
+	if err := decodeJSONBody(r, &req, h.strictJSON); err != nil {
+		respondBodyDecodeError(w, err)
+		return
+	}
+	if err := validateSummarizeMessages(req.Messages); err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), ai.UserIDContextKey(), user.ID)
+	aiContext, err := h.summarizer.SummarizeConversation(ctx, user.ID, req.Messages)
+	if err != nil {
+		h.respondSummarizeError(w, user.ID, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, contextResponse(aiContext))
+}
+
+// validateSummarizeMessages checks that messages is a non-empty conversation of user and assistant turns
+// with at least one user message
+func validateSummarizeMessages(messages []ai.ChatMessage) error {
+	if len(messages) == 0 || len(messages) > MaxSummarizeMessages {
+		return fmt.Errorf("messages must list 1 to %d messages", MaxSummarizeMessages)
+	}
+	hasUser := false
+	for _, msg := range messages {
+		switch msg.Role {
+		case "user":
+			hasUser = true
+		case "assistant":
+		default:
+			return fmt.Errorf("message role must be user or assistant (got %q)", msg.Role)
+		}
+	}
+	if !hasUser {
+		return errors.New("messages must include at least one user message")
+	}
+	return nil
+}
+
+// respondSummarizeError responds to a failed summary: 422 when the summary came out too long, 503 with a
+// Retry-After hint when the AI provider is having a transient outage, 500 otherwise
+func (h *AIContextHandler) respondSummarizeError(w http.ResponseWriter, userID uuid.UUID, err error) {
+	h.logger.Warn("failed_to_summarize_context",
+		zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+		zap.String("error_kind", string(ai.ClassifyError(err))),
+		zap.String("error", logpkg.SanitizeError(err)),
+	)
+	switch {
+	case errors.Is(err, models.ErrContextSummaryTooLong):
+		respondJSONError(w, http.StatusUnprocessableEntity, "Unprocessable Entity", "The summary of this conversation is too long; send a shorter conversation")
+	case ai.IsTransient(err):
+		retryAfter := DefaultUnavailableRetryAfter
+		if hint := ai.RetryAfterHint(err); hint != nil {
+			retryAfter = *hint
+		}
+		respondUnavailable(w, "The AI provider is temporarily unavailable; try again later", retryAfter)
+	default:
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to summarize context")
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/services/ai"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// stubContextSummarizer records the conversation it was asked to summarize and returns summary or err
+type stubContextSummarizer struct {
+	summary      string
+	err          error
+	conversation []ai.ChatMessage
+}
+
+func (s *stubContextSummarizer) SummarizeConversation(ctx context.Context, userID uuid.UUID, conversationHistory []ai.ChatMessage) (*models.AIContext, error) {
+	s.conversation = conversationHistory
+	if s.err != nil {
+		return nil, s.err
+	}
+	aiContext := &models.AIContext{UserID: userID}
+	aiContext.SetContextSummary(s.summary, 1, time.Now())
+	return aiContext, nil
+}
+
+func TestAIContextHandler_SummarizeContext(t *testing.T) {
+	t.Parallel()
+
+	hint := 5 * time.Second
+	tests := []struct {
+		name           string
+		body           string
+		noSummarizer   bool
+		strict         bool
+		err            error
+		wantStatus     int
+		wantSummary    string
+		wantRetryAfter string
+	}{
+		{name: "saves the summary", body: `{"messages":[{"role":"user","content":"I work nights"},{"role":"assistant","content":"Noted"}]}`, wantStatus: http.StatusOK, wantSummary: "Works nights"},
+		{name: "no AI provider", body: `{"messages":[{"role":"user","content":"hi"}]}`, noSummarizer: true, wantStatus: http.StatusForbidden},
+		{name: "no messages", body: `{"messages":[]}`, wantStatus: http.StatusBadRequest},
+		{name: "too many messages", body: `{"messages":[` + strings.Repeat(`{"role":"user","content":"hi"},`, MaxSummarizeMessages) + `{"role":"user","content":"hi"}]}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown role", body: `{"messages":[{"role":"system","content":"hi"}]}`, wantStatus: http.StatusBadRequest},
+		{name: "no user message", body: `{"messages":[{"role":"assistant","content":"hi"}]}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid body", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "strict rejects unknown field", body: `{"messages":[{"role":"user","content":"hi"}],"mesages":[]}`, strict: true, wantStatus: http.StatusBadRequest},
+		{name: "lenient ignores unknown field", body: `{"messages":[{"role":"user","content":"I work nights"},{"role":"assistant","content":"Noted"}],"mesages":[]}`, wantStatus: http.StatusOK, wantSummary: "Works nights"},
+		{name: "summary too long", body: `{"messages":[{"role":"user","content":"hi"}]}`, err: fmt.Errorf("%w: 2001 characters", models.ErrContextSummaryTooLong), wantStatus: http.StatusUnprocessableEntity},
+		{
+			name: "provider outage", body: `{"messages":[{"role":"user","content":"hi"}]}`,
+			err:        &ai.ProviderError{Kind: ai.AIErrorRateLimit, Provider: "openai", RetryAfter: &hint, Err: errors.New("slow down")},
+			wantStatus: http.StatusServiceUnavailable, wantRetryAfter: "5",
+		},
+		{name: "provider failure", body: `{"messages":[{"role":"user","content":"hi"}]}`, err: errors.New("boom"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			summarizer := &stubContextSummarizer{summary: tt.wantSummary, err: tt.err}
+			opts := []AIContextHandlerOption{WithAIContextStrictJSON(tt.strict)}
+			if !tt.noSummarizer {
+				opts = append(opts, WithAIContextSummarizer(summarizer, zap.NewNop()))
+			}
+			router := mux.NewRouter()
+			NewAIContextHandler(&mockTagAliasStore{}, opts...).RegisterRoutes(router.PathPrefix("/ai/context").Subrouter())
+
+			req := httptest.NewRequest("POST", "/ai/context/summarize", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, setUserInRequestContext(req, &models.User{ID: uuid.New()}))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if len(summarizer.conversation) != 2 || summarizer.conversation[0].Content != "I work nights" {
+				t.Errorf("summarized conversation = %+v", summarizer.conversation)
+			}
+			var resp struct {
+				Data GetContextResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Data.ContextSummary != tt.wantSummary || resp.Data.SummaryChatTurns != 1 {
+				t.Errorf("response = %+v, want summary %q from 1 turn", resp.Data, tt.wantSummary)
+			}
+		})
+	}
+}
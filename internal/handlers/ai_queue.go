@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// AnalysisQueueCounter counts a user's todos that are still awaiting AI analysis, keyed by status
+type AnalysisQueueCounter interface {
+	CountAnalysisQueue(ctx context.Context, userID uuid.UUID) (map[models.TodoStatus]int, error)
+}
+
+// AIQueueStatusHandler reports how much AI analysis work is outstanding for the current user
+type AIQueueStatusHandler struct {
+	counter AnalysisQueueCounter
+	logger  *zap.Logger
+}
+
+// NewAIQueueStatusHandler creates a new AI queue status handler
+func NewAIQueueStatusHandler(counter AnalysisQueueCounter, logger *zap.Logger) *AIQueueStatusHandler {
+	return &AIQueueStatusHandler{counter: counter, logger: logger}
+}
+
+// RegisterRoutes registers AI queue status routes
+// The router should already have the /ai prefix
+func (h *AIQueueStatusHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/queue-status", h.GetQueueStatus).Methods("GET")
+}
+
+// GetQueueStatus returns the number of the user's todos pending or undergoing AI analysis
+func (h *AIQueueStatusHandler) GetQueueStatus(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+
+	counts, err := h.counter.CountAnalysisQueue(r.Context(), user.ID)
+	if err != nil {
+		h.logger.Error("failed_to_count_analysis_queue",
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to get AI queue status")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.NewAnalysisQueueStatus(counts))
+}
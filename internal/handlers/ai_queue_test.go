@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type mockAnalysisQueueCounter struct {
+	counts map[models.TodoStatus]int
+	err    error
+	userID uuid.UUID
+}
+
+func (m *mockAnalysisQueueCounter) CountAnalysisQueue(ctx context.Context, userID uuid.UUID) (map[models.TodoStatus]int, error) {
+	m.userID = userID
+	return m.counts, m.err
+}
+
+func TestAIQueueStatusHandler_GetQueueStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		counter    *mockAnalysisQueueCounter
+		withUser   bool
+		wantStatus int
+		want       models.AnalysisQueueStatus
+	}{
+		{
+			name: "mixed statuses",
+			counter: &mockAnalysisQueueCounter{counts: map[models.TodoStatus]int{
+				models.TodoStatusPending:    2,
+				models.TodoStatusProcessing: 1,
+			}},
+			withUser:   true,
+			wantStatus: http.StatusOK,
+			want:       models.AnalysisQueueStatus{Pending: 2, Processing: 1, Total: 3},
+		},
+		{
+			name:       "nothing outstanding",
+			counter:    &mockAnalysisQueueCounter{counts: map[models.TodoStatus]int{}},
+			withUser:   true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "repository error",
+			counter:    &mockAnalysisQueueCounter{err: errors.New("connection refused")},
+			withUser:   true,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "no user",
+			counter:    &mockAnalysisQueueCounter{},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler := NewAIQueueStatusHandler(tt.counter, zap.NewNop())
+			user := &models.User{ID: uuid.New()}
+			req := httptest.NewRequest("GET", "/api/v1/ai/queue-status", nil)
+			if tt.withUser {
+				req = setUserInRequestContext(req, user)
+			}
+			w := httptest.NewRecorder()
+			handler.GetQueueStatus(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if tt.counter.userID != user.ID {
+				t.Errorf("counted queue for user %s, want %s", tt.counter.userID, user.ID)
+			}
+			var resp struct {
+				Data models.AnalysisQueueStatus `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Data != tt.want {
+				t.Errorf("data = %+v, want %+v", resp.Data, tt.want)
+			}
+		})
+	}
+}
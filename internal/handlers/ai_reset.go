@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/benvon/smart-todo/internal/database"
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// AIMemoryResetter clears a user's AI tags, tag statistics and AI context, keeping their todos
+type AIMemoryResetter interface {
+	ResetAIMemory(ctx context.Context, userID uuid.UUID, requeue bool) (database.AIMemoryReset, error)
+}
+
+// AIResetHandler lets a user wipe what the AI has learned about them and start over
+type AIResetHandler struct {
+	resetter AIMemoryResetter
+	jobQueue queue.JobQueue
+	logger   *zap.Logger
+}
+
+// NewAIResetHandler creates a new AI reset handler. A nil job queue resets without reprocessing.
+func NewAIResetHandler(resetter AIMemoryResetter, jobQueue queue.JobQueue, logger *zap.Logger) *AIResetHandler {
+	return &AIResetHandler{resetter: resetter, jobQueue: jobQueue, logger: logger}
+}
+
+// RegisterRoutes registers AI reset routes
+// The router should already have the /ai prefix
+func (h *AIResetHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/reset", h.ResetAI).Methods("POST")
+}
+
+// AIResetResponse summarizes what an AI reset cleared
+type AIResetResponse struct {
+	TodosUpdated       int  `json:"todos_updated"`
+	TagStatsCleared    int  `json:"tag_stats_cleared"`
+	AIContextCleared   bool `json:"ai_context_cleared"`
+	ReanalysisEnqueued bool `json:"reanalysis_enqueued"`
+}
+
+// ResetAI clears the user's categorization memory in one transaction: AI tags on every todo (user tags stay),
+// tag statistics (left empty and tainted) and the AI context. A reprocessing job is then enqueued unless
+// ?reanalyze=false is given. This is a heavier "start over" than POST /todos/tags/reset-ai.
+func (h *AIResetHandler) ResetAI(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	reanalyze, err := parseReanalyze(r)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	requeue := reanalyze && h.jobQueue != nil
+
+	ctx := r.Context()
+	reset, err := h.resetter.ResetAIMemory(ctx, user.ID, requeue)
+	if err != nil {
+		h.logger.Error("failed_to_reset_ai_memory",
+			zap.String("operation", "reset_ai"),
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to reset AI memory")
+		return
+	}
+
+	resp := AIResetResponse{
+		TodosUpdated:     reset.TodosUpdated,
+		TagStatsCleared:  reset.TagStatsCleared,
+		AIContextCleared: reset.AIContextCleared,
+	}
+	if requeue {
+		job := queue.NewJob(queue.JobTypeReprocessUser, user.ID, nil)
+		if err := h.jobQueue.Enqueue(ctx, job); err != nil {
+			h.logger.Error("failed_to_enqueue_reprocess_after_ai_reset",
+				zap.String("operation", "reset_ai"),
+				zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+				zap.String("error", logpkg.SanitizeError(err)),
+			)
+		} else {
+			resp.ReanalysisEnqueued = true
+		}
+	}
+
+	h.logger.Info("reset_ai_memory",
+		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+		zap.Int("todos_updated", resp.TodosUpdated),
+		zap.Int("tag_stats_cleared", resp.TagStatsCleared),
+		zap.Bool("ai_context_cleared", resp.AIContextCleared),
+		zap.Bool("reanalysis_enqueued", resp.ReanalysisEnqueued),
+	)
+	respondJSON(w, http.StatusOK, resp)
+}
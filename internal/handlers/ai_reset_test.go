@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/database"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// stubAIMemoryResetter records ResetAIMemory calls and returns a fixed result
+type stubAIMemoryResetter struct {
+	reset    database.AIMemoryReset
+	err      error
+	calls    int
+	requeues []bool
+}
+
+func (s *stubAIMemoryResetter) ResetAIMemory(ctx context.Context, userID uuid.UUID, requeue bool) (database.AIMemoryReset, error) {
+	s.calls++
+	s.requeues = append(s.requeues, requeue)
+	return s.reset, s.err
+}
+
+func TestAIResetHandler_ResetAI(t *testing.T) {
+	t.Parallel()
+
+	cleared := database.AIMemoryReset{TodosUpdated: 3, TagStatsCleared: 7, AIContextCleared: true}
+	tests := []struct {
+		name        string
+		query       string
+		withQueue   bool
+		resetErr    error
+		wantStatus  int
+		wantRequeue bool
+		wantJobs    int
+	}{
+		{name: "clears and enqueues a reprocess", withQueue: true, wantStatus: http.StatusOK, wantRequeue: true, wantJobs: 1},
+		{name: "reanalyze=false skips the reprocess", query: "?reanalyze=false", withQueue: true, wantStatus: http.StatusOK},
+		{name: "no job queue", wantStatus: http.StatusOK},
+		{name: "invalid reanalyze", query: "?reanalyze=maybe", withQueue: true, wantStatus: http.StatusBadRequest},
+		{name: "reset fails", withQueue: true, resetErr: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantRequeue: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			userID := uuid.New()
+			resetter := &stubAIMemoryResetter{reset: cleared, err: tt.resetErr}
+			jobQueue := &mockJobQueueForHandlers{}
+			var q queue.JobQueue
+			if tt.withQueue {
+				q = jobQueue
+			}
+			router := mux.NewRouter()
+			NewAIResetHandler(resetter, q, zap.NewNop()).RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/reset"+tt.query, nil)
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if len(jobQueue.enqueueCalls) != tt.wantJobs {
+				t.Fatalf("enqueued %d jobs, want %d", len(jobQueue.enqueueCalls), tt.wantJobs)
+			}
+			if tt.wantStatus == http.StatusBadRequest {
+				if resetter.calls != 0 {
+					t.Error("reset should not run for an invalid request")
+				}
+				return
+			}
+			if resetter.calls != 1 || resetter.requeues[0] != tt.wantRequeue {
+				t.Fatalf("ResetAIMemory calls = %d with requeue %v, want 1 with %v", resetter.calls, resetter.requeues, tt.wantRequeue)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if tt.wantJobs > 0 {
+				job := jobQueue.enqueueCalls[0]
+				if job.Type != queue.JobTypeReprocessUser || job.UserID != userID {
+					t.Errorf("job = %s for %s, want %s for %s", job.Type, job.UserID, queue.JobTypeReprocessUser, userID)
+				}
+			}
+
+			var resp struct {
+				Data AIResetResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			want := AIResetResponse{TodosUpdated: 3, TagStatsCleared: 7, AIContextCleared: true, ReanalysisEnqueued: tt.wantJobs > 0}
+			if resp.Data != want {
+				t.Errorf("response = %+v, want %+v", resp.Data, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/benvon/smart-todo/internal/database"
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// WeeklySummaryGetter reads a user's most recent weekly summary
+type WeeklySummaryGetter interface {
+	GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*models.WeeklySummary, error)
+}
+
+// AIWeeklySummaryHandler serves the weekly summaries generated by the worker
+type AIWeeklySummaryHandler struct {
+	summaries WeeklySummaryGetter
+	logger    *zap.Logger
+	disabled  bool
+}
+
+// AIWeeklySummaryHandlerOption configures an AIWeeklySummaryHandler.
+type AIWeeklySummaryHandlerOption func(*AIWeeklySummaryHandler)
+
+// WithWeeklySummaryEnabled reports whether the worker generates weekly summaries. When it does not, the
+// endpoint answers 403 Feature Disabled instead of 404, so clients stop waiting for a summary.
+func WithWeeklySummaryEnabled(enabled bool) AIWeeklySummaryHandlerOption {
+	return func(h *AIWeeklySummaryHandler) {
+		h.disabled = !enabled
+	}
+}
+
+// NewAIWeeklySummaryHandler creates a new AI weekly summary handler
+func NewAIWeeklySummaryHandler(summaries WeeklySummaryGetter, logger *zap.Logger, opts ...AIWeeklySummaryHandlerOption) *AIWeeklySummaryHandler {
+	h := &AIWeeklySummaryHandler{summaries: summaries, logger: logger}
+	for _, o := range opts {
+		o(h)
+	}
+	return h
+}
+
+// RegisterRoutes registers AI weekly summary routes
+// The router should already have the /ai prefix
+func (h *AIWeeklySummaryHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/weekly-summary", h.GetWeeklySummary).Methods("GET")
+}
+
+// GetWeeklySummary returns the user's most recent weekly summary, or 404 if none has been generated yet.
+// Responds 403 Feature Disabled when weekly summaries are switched off.
+func (h *AIWeeklySummaryHandler) GetWeeklySummary(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	if h.disabled {
+		respondFeatureDisabled(w, "Weekly summaries")
+		return
+	}
+
+	summary, err := h.summaries.GetLatestByUserID(r.Context(), user.ID)
+	if errors.Is(err, database.ErrWeeklySummaryNotFound) {
+		respondJSONError(w, http.StatusNotFound, "Not Found", "No weekly summary has been generated yet")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed_to_get_weekly_summary",
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to get weekly summary")
+		return
+	}
+	respondJSON(w, http.StatusOK, summary)
+}
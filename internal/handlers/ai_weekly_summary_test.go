@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/database"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// stubWeeklySummaryGetter returns a fixed summary or error
+type stubWeeklySummaryGetter struct {
+	summary *models.WeeklySummary
+	err     error
+}
+
+func (s *stubWeeklySummaryGetter) GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*models.WeeklySummary, error) {
+	return s.summary, s.err
+}
+
+func TestAIWeeklySummaryHandler_GetWeeklySummary(t *testing.T) {
+	t.Parallel()
+
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		getter     *stubWeeklySummaryGetter
+		disabled   bool
+		wantStatus int
+	}{
+		{
+			name:       "latest summary",
+			getter:     &stubWeeklySummaryGetter{summary: &models.WeeklySummary{WeekStart: weekStart, Summary: "Great week!", CompletedCount: 4, PendingCount: 2}},
+			wantStatus: http.StatusOK,
+		},
+		{name: "none yet", getter: &stubWeeklySummaryGetter{err: database.ErrWeeklySummaryNotFound}, wantStatus: http.StatusNotFound},
+		{name: "lookup fails", getter: &stubWeeklySummaryGetter{err: errors.New("db down")}, wantStatus: http.StatusInternalServerError},
+		{name: "feature disabled", getter: &stubWeeklySummaryGetter{err: database.ErrWeeklySummaryNotFound}, disabled: true, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			router := mux.NewRouter()
+			NewAIWeeklySummaryHandler(tt.getter, zap.NewNop(), WithWeeklySummaryEnabled(!tt.disabled)).RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/weekly-summary", nil)
+			req = setUserInRequestContext(req, &models.User{ID: uuid.New()})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Data models.WeeklySummary `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Data.Summary != "Great week!" || resp.Data.CompletedCount != 4 || !resp.Data.WeekStart.Equal(weekStart) {
+				t.Errorf("response = %+v, want the stored summary", resp.Data)
+			}
+		})
+	}
+}
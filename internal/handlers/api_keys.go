@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// APIKeyStore stores users' API keys
+type APIKeyStore interface {
+	Create(ctx context.Context, key *models.APIKey, keyHash string) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error)
+	Revoke(ctx context.Context, userID, id uuid.UUID) (bool, error)
+}
+
+// APIKeyHandler lets users mint, list and revoke API keys for non-interactive clients
+type APIKeyHandler struct {
+	store  APIKeyStore
+	logger *zap.Logger
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(store APIKeyStore, logger *zap.Logger) *APIKeyHandler {
+	return &APIKeyHandler{store: store, logger: logger}
+}
+
+// RegisterRoutes registers API key routes
+// The router should already have the /api/v1/auth prefix and auth middleware applied
+func (h *APIKeyHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/api-keys", h.CreateAPIKey).Methods("POST")
+	r.HandleFunc("/api-keys", h.ListAPIKeys).Methods("GET")
+	r.HandleFunc("/api-keys/{id}", h.RevokeAPIKey).Methods("DELETE")
+}
+
+// CreateAPIKeyRequest is the body of POST /auth/api-keys
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+	// Scope is "full" (the default) or "read"
+	Scope string `json:"scope"`
+}
+
+// CreateAPIKeyResponse is a newly minted API key, with the key itself
+type CreateAPIKeyResponse struct {
+	*models.APIKey
+	// Key is only ever returned here; it cannot be retrieved again. Its JSON name keeps it out of debug
+	// body captures.
+	Key string `json:"api_key"`
+}
+
+// ListAPIKeysResponse lists a user's API keys, newest first
+type ListAPIKeysResponse struct {
+	APIKeys []*models.APIKey `json:"api_keys"`
+}
+
+// CreateAPIKey mints an API key for the current user. Keys can only be minted from an interactive login,
+// so a leaked key cannot be used to mint more.
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	if _, ok := request.APIKeyIDFromContext(r); ok {
+		respondJSONError(w, http.StatusForbidden, "Forbidden", "API keys cannot be created with an API key")
+		return
+	}
+	var req CreateAPIKeyRequest
+	if err := decodeJSONBody(r, &req, true); err != nil {
+		respondBodyDecodeError(w, err)
+		return
+	}
+	name, err := models.NormalizeAPIKeyName(req.Name)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	scope, err := models.ParseAPIKeyScope(req.Scope)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+
+	key, hint, err := models.GenerateAPIKey()
+	if err != nil {
+		h.logError("failed_to_generate_api_key", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to create API key")
+		return
+	}
+	apiKey := &models.APIKey{UserID: user.ID, Name: name, Hint: hint, Scope: scope}
+	if err := h.store.Create(r.Context(), apiKey, models.HashAPIKey(key)); err != nil {
+		h.logError("failed_to_create_api_key", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to create API key")
+		return
+	}
+	h.logger.Info("api_key_created",
+		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+		zap.String("api_key_id", apiKey.ID.String()),
+		zap.String("scope", string(apiKey.Scope)),
+	)
+	// The key must not be kept by caches along the way
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, http.StatusCreated, CreateAPIKeyResponse{APIKey: apiKey, Key: key})
+}
+
+// ListAPIKeys lists the current user's unrevoked API keys, without the keys themselves
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	keys, err := h.store.ListByUser(r.Context(), user.ID)
+	if err != nil {
+		h.logError("failed_to_list_api_keys", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to list API keys")
+		return
+	}
+	respondJSON(w, http.StatusOK, ListAPIKeysResponse{APIKeys: keys})
+}
+
+// RevokeAPIKey revokes one of the current user's API keys; requests with it are rejected from then on
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid API key ID")
+		return
+	}
+	revoked, err := h.store.Revoke(r.Context(), user.ID, id)
+	if err != nil {
+		h.logError("failed_to_revoke_api_key", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to revoke API key")
+		return
+	}
+	if !revoked {
+		respondJSONError(w, http.StatusNotFound, "Not Found", "API key not found")
+		return
+	}
+	h.logger.Info("api_key_revoked",
+		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+		zap.String("api_key_id", id.String()),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIKeyHandler) logError(event string, userID uuid.UUID, err error) {
+	h.logger.Error(event,
+		zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+		zap.String("error", logpkg.SanitizeError(err)),
+	)
+}
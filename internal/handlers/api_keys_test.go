@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// fakeAPIKeyStore keeps API keys in memory by hash
+type fakeAPIKeyStore struct {
+	mu     sync.Mutex
+	keys   map[string]*models.APIKey
+	err    error
+	hashes []string
+}
+
+func newFakeAPIKeyStore() *fakeAPIKeyStore {
+	return &fakeAPIKeyStore{keys: make(map[string]*models.APIKey)}
+}
+
+func (f *fakeAPIKeyStore) Create(ctx context.Context, key *models.APIKey, keyHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	key.ID = uuid.New()
+	f.keys[keyHash] = key
+	f.hashes = append(f.hashes, keyHash)
+	return nil
+}
+
+func (f *fakeAPIKeyStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := []*models.APIKey{}
+	for _, hash := range f.hashes {
+		if key, ok := f.keys[hash]; ok && key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, f.err
+}
+
+func (f *fakeAPIKeyStore) Revoke(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return false, f.err
+	}
+	for hash, key := range f.keys {
+		if key.ID == id && key.UserID == userID {
+			delete(f.keys, hash)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newAPIKeyRouter(store APIKeyStore) *mux.Router {
+	router := mux.NewRouter()
+	NewAPIKeyHandler(store, zap.NewNop()).RegisterRoutes(router)
+	return router
+}
+
+func TestAPIKeyHandler_CreateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		viaAPIKey  bool
+		storeErr   error
+		wantScope  models.APIKeyScope
+		wantStatus int
+	}{
+		{name: "created", body: `{"name":" CI deploy "}`, wantScope: models.APIKeyScopeFull, wantStatus: http.StatusCreated},
+		{name: "created read-only", body: `{"name":" CI deploy ","scope":"read"}`, wantScope: models.APIKeyScopeRead, wantStatus: http.StatusCreated},
+		{name: "unknown scope", body: `{"name":"CI","scope":"admin"}`, wantStatus: http.StatusBadRequest},
+		{name: "missing name", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown field", body: `{"name":"CI","scopes":["admin"]}`, wantStatus: http.StatusBadRequest},
+		{name: "minted with an API key", body: `{"name":"CI"}`, viaAPIKey: true, wantStatus: http.StatusForbidden},
+		{name: "store error", body: `{"name":"CI"}`, storeErr: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newFakeAPIKeyStore()
+			store.err = tt.storeErr
+			user := &models.User{ID: uuid.New()}
+			req := httptest.NewRequest(http.MethodPost, "/api-keys", strings.NewReader(tt.body))
+			ctx := request.WithUser(req.Context(), user)
+			if tt.viaAPIKey {
+				ctx = request.WithAPIKeyID(ctx, uuid.New())
+			}
+			w := httptest.NewRecorder()
+			newAPIKeyRouter(store).ServeHTTP(w, req.WithContext(ctx))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			if got := w.Header().Get("Cache-Control"); got != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", got)
+			}
+			var resp struct {
+				Data CreateAPIKeyResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			// Debug body capture redacts api_key fields, so the key must be sent as one
+			if !strings.Contains(w.Body.String(), `"api_key":"`+resp.Data.Key+`"`) {
+				t.Errorf("response %s does not return the key as api_key", w.Body.String())
+			}
+			stored, ok := store.keys[models.HashAPIKey(resp.Data.Key)]
+			if !ok {
+				t.Fatalf("returned key %q is not stored by its hash", resp.Data.Key)
+			}
+			if stored.UserID != user.ID || stored.Name != "CI deploy" || !strings.HasPrefix(resp.Data.Key, stored.Hint) {
+				t.Errorf("stored key = %+v, want user %s named %q with the key's hint", stored, user.ID, "CI deploy")
+			}
+			if stored.Scope != tt.wantScope || resp.Data.Scope != tt.wantScope {
+				t.Errorf("scope = %q stored, %q returned; want %q", stored.Scope, resp.Data.Scope, tt.wantScope)
+			}
+			if resp.Data.APIKey == nil || resp.Data.ID != stored.ID {
+				t.Errorf("response key = %+v, want ID %s", resp.Data.APIKey, stored.ID)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHandler_ListAndRevoke(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeAPIKeyStore()
+	user := &models.User{ID: uuid.New()}
+	other := &models.User{ID: uuid.New()}
+	mine := &models.APIKey{UserID: user.ID, Name: "mine"}
+	theirs := &models.APIKey{UserID: other.ID, Name: "theirs"}
+	for i, key := range []*models.APIKey{mine, theirs} {
+		if err := store.Create(context.Background(), key, strings.Repeat(string(rune('a'+i)), 64)); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	router := newAPIKeyRouter(store)
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req.WithContext(request.WithUser(req.Context(), user)))
+		return w
+	}
+
+	w := serve(http.MethodGet, "/api-keys")
+	var resp struct {
+		Data ListAPIKeysResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if w.Code != http.StatusOK || len(resp.Data.APIKeys) != 1 || resp.Data.APIKeys[0].ID != mine.ID {
+		t.Fatalf("list = %d %s, want only the user's key", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{name: "another user's key", id: theirs.ID.String(), wantStatus: http.StatusNotFound},
+		{name: "invalid ID", id: "not-a-uuid", wantStatus: http.StatusBadRequest},
+		{name: "own key", id: mine.ID.String(), wantStatus: http.StatusNoContent},
+		{name: "already revoked", id: mine.ID.String(), wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := serve(http.MethodDelete, "/api-keys/"+tt.id); w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+	if len(store.keys) != 1 {
+		t.Errorf("%d keys left, want only the other user's", len(store.keys))
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// AuditEventLister reads a page of the recorded audit events
+type AuditEventLister interface {
+	List(ctx context.Context, filter models.AuditEventFilter, page, pageSize int) ([]*models.AuditEvent, int, error)
+}
+
+// AuditHandler serves the audit log for compliance reviews. Routes must be mounted behind admin auth.
+type AuditHandler struct {
+	events AuditEventLister
+	logger *zap.Logger
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(events AuditEventLister, logger *zap.Logger) *AuditHandler {
+	return &AuditHandler{events: events, logger: logger}
+}
+
+// ListAuditEventsResponse is a page of audit events, newest first
+type ListAuditEventsResponse struct {
+	Events     []*models.AuditEvent `json:"events"`
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"page_size"`
+	Total      int                  `json:"total"`
+	TotalPages int                  `json:"total_pages"`
+}
+
+// RegisterRoutes registers audit routes
+// The router should already have the /audit prefix and admin auth middleware applied
+func (h *AuditHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("", h.ListAuditEvents).Methods("GET")
+}
+
+// ListAuditEvents lists recorded audit events, newest first, paginated with page and page_size. action
+// selects one kind of event and since/until (RFC 3339) bound when they were recorded.
+func (h *AuditHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditEventFilter(r)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	page := parsePage(r.URL.Query().Get("page"))
+	pageSize := parsePageSize(r.URL.Query().Get("page_size"))
+
+	events, total, err := h.events.List(r.Context(), filter, page, pageSize)
+	if err != nil {
+		h.logger.Error("failed_to_list_audit_events", zap.String("error", logpkg.SanitizeError(err)))
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to list audit events")
+		return
+	}
+	respondJSON(w, http.StatusOK, ListAuditEventsResponse{
+		Events:     events,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	})
+}
+
+// parseAuditEventFilter parses the action, since and until query params
+func parseAuditEventFilter(r *http.Request) (models.AuditEventFilter, error) {
+	var filter models.AuditEventFilter
+	query := r.URL.Query()
+	if v := query.Get("action"); v != "" {
+		action, err := models.ParseAuditAction(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Action = action
+	}
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		v := query.Get(bound.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s %q (expected an RFC 3339 timestamp)", bound.name, v)
+		}
+		*bound.dst = t.UTC()
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Until.After(filter.Since) {
+		return filter, fmt.Errorf("until must be after since")
+	}
+	return filter, nil
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type fakeAuditEventLister struct {
+	events   []*models.AuditEvent
+	total    int
+	err      error
+	filter   models.AuditEventFilter
+	page     int
+	pageSize int
+}
+
+func (f *fakeAuditEventLister) List(ctx context.Context, filter models.AuditEventFilter, page, pageSize int) ([]*models.AuditEvent, int, error) {
+	f.filter, f.page, f.pageSize = filter, page, pageSize
+	return f.events, f.total, f.err
+}
+
+func TestAuditHandler_ListAuditEvents(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		query        string
+		repoErr      error
+		wantStatus   int
+		wantFilter   models.AuditEventFilter
+		wantPage     int
+		wantPageSize int
+	}{
+		{name: "defaults", wantStatus: http.StatusOK, wantPage: 1, wantPageSize: DefaultPageSize},
+		{
+			name:         "filtered page",
+			query:        "?action=forbidden&since=2026-10-01T00:00:00Z&until=2026-10-16T02:00:00%2B02:00&page=2&page_size=10",
+			wantStatus:   http.StatusOK,
+			wantFilter:   models.AuditEventFilter{Action: models.AuditActionForbidden, Since: since, Until: until},
+			wantPage:     2,
+			wantPageSize: 10,
+		},
+		{name: "unknown action", query: "?action=login", wantStatus: http.StatusBadRequest},
+		{name: "invalid since", query: "?since=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "until before since", query: "?since=2026-10-16T00:00:00Z&until=2026-10-01T00:00:00Z", wantStatus: http.StatusBadRequest},
+		{name: "repository error", repoErr: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			repo := &fakeAuditEventLister{
+				events: []*models.AuditEvent{{ID: uuid.New(), UserID: &userID, Action: models.AuditActionForbidden, StatusCode: http.StatusForbidden}},
+				total:  21,
+				err:    tt.repoErr,
+			}
+			router := mux.NewRouter()
+			NewAuditHandler(repo, zap.NewNop()).RegisterRoutes(router.PathPrefix("/audit").Subrouter())
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/audit"+tt.query, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if repo.filter != tt.wantFilter || repo.page != tt.wantPage || repo.pageSize != tt.wantPageSize {
+				t.Errorf("listed %+v page %d size %d, want %+v page %d size %d",
+					repo.filter, repo.page, repo.pageSize, tt.wantFilter, tt.wantPage, tt.wantPageSize)
+			}
+			var body struct {
+				Data ListAuditEventsResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(body.Data.Events) != 1 || body.Data.Total != 21 || body.Data.TotalPages != (21+tt.wantPageSize-1)/tt.wantPageSize {
+				t.Errorf("response = %+v, want 1 event of 21 in %d pages", body.Data, (21+tt.wantPageSize-1)/tt.wantPageSize)
+			}
+		})
+	}
+}
@@ -1,24 +1,37 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 
+	logpkg "github.com/benvon/smart-todo/internal/logger"
 	"github.com/benvon/smart-todo/internal/request"
 	"github.com/benvon/smart-todo/internal/services/oidc"
 	"github.com/gorilla/mux"
+	"go.uber.org/zap"
 )
 
+// AuthProvider is the OIDC provider the auth routes serve logins and token refreshes from
+type AuthProvider interface {
+	GetLoginConfig(ctx context.Context, providerName string) (*oidc.LoginConfig, error)
+	RefreshTokens(ctx context.Context, providerName, refreshToken string) (*oidc.TokenSet, error)
+}
+
 // AuthHandler handles authentication-related requests
 type AuthHandler struct {
-	oidcProvider *oidc.Provider
+	oidcProvider AuthProvider
 	providerName string
+	logger       *zap.Logger
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(oidcProvider *oidc.Provider, providerName string) *AuthHandler {
+func NewAuthHandler(oidcProvider AuthProvider, providerName string, logger *zap.Logger) *AuthHandler {
 	return &AuthHandler{
 		oidcProvider: oidcProvider,
 		providerName: providerName,
+		logger:       logger,
 	}
 }
 
@@ -26,6 +39,7 @@ func NewAuthHandler(oidcProvider *oidc.Provider, providerName string) *AuthHandl
 // The router should already have the /api/v1/auth prefix
 func (h *AuthHandler) RegisterRoutes(r *mux.Router) {
 	r.HandleFunc("/oidc/login", h.GetOIDCLogin).Methods("GET")
+	r.HandleFunc("/refresh", h.RefreshToken).Methods("POST")
 	r.HandleFunc("/me", h.GetMe).Methods("GET")
 }
 
@@ -43,6 +57,48 @@ func (h *AuthHandler) GetOIDCLogin(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, loginConfig)
 }
 
+// RefreshTokenRequest is the body of POST /auth/refresh
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken exchanges a refresh token at the OIDC provider's token endpoint for new access and ID tokens.
+// A refresh token the provider rejects answers 401, so the frontend can send the user to log in again; a
+// provider that cannot be reached answers 503.
+func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req RefreshTokenRequest
+	if err := decodeJSONBody(r, &req, true); err != nil {
+		respondBodyDecodeError(w, err)
+		return
+	}
+	if req.RefreshToken == "" {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "refresh_token is required")
+		return
+	}
+	if len(req.RefreshToken) > oidc.MaxTokenSize {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", fmt.Sprintf("refresh_token exceeds maximum size of %d bytes", oidc.MaxTokenSize))
+		return
+	}
+
+	tokens, err := h.oidcProvider.RefreshTokens(r.Context(), h.providerName, req.RefreshToken)
+	if errors.Is(err, oidc.ErrInvalidRefreshToken) {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "Refresh token is invalid or expired")
+		return
+	}
+	if err != nil {
+		h.logger.Warn("failed_to_refresh_tokens",
+			zap.String("operation", "refresh_token"),
+			zap.String("provider", logpkg.SanitizeString(h.providerName, logpkg.MaxGeneralStringLength)),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondUnavailable(w, "Identity provider unavailable", DefaultUnavailableRetryAfter)
+		return
+	}
+	// Tokens must not be kept by caches along the way
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, http.StatusOK, tokens)
+}
+
 // GetMe returns current user information
 func (h *AuthHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 	user := request.UserFromContext(r)
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/services/oidc"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// fakeAuthProvider refreshes the refresh token "valid" and fails others with refreshErr
+type fakeAuthProvider struct {
+	refreshErr error
+}
+
+func (f *fakeAuthProvider) GetLoginConfig(ctx context.Context, providerName string) (*oidc.LoginConfig, error) {
+	return &oidc.LoginConfig{ClientID: "client-id"}, nil
+}
+
+func (f *fakeAuthProvider) RefreshTokens(ctx context.Context, providerName, refreshToken string) (*oidc.TokenSet, error) {
+	if refreshToken != "valid" {
+		return nil, f.refreshErr
+	}
+	return &oidc.TokenSet{AccessToken: "access", IDToken: "id", RefreshToken: "valid", TokenType: "Bearer", ExpiresIn: 3600}, nil
+}
+
+func TestAuthHandler_RefreshToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		refreshErr error
+		wantStatus int
+	}{
+		{name: "refreshed", body: `{"refresh_token":"valid"}`, wantStatus: http.StatusOK},
+		{name: "rejected refresh token", body: `{"refresh_token":"revoked"}`, refreshErr: oidc.ErrInvalidRefreshToken, wantStatus: http.StatusUnauthorized},
+		{name: "provider unavailable", body: `{"refresh_token":"other"}`, refreshErr: errors.New("connection refused"), wantStatus: http.StatusServiceUnavailable},
+		{name: "missing refresh token", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "oversized refresh token", body: `{"refresh_token":"` + strings.Repeat("x", oidc.MaxTokenSize+1) + `"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown field", body: `{"refresh_token":"valid","grant_type":"password"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid JSON", body: `{`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler := NewAuthHandler(&fakeAuthProvider{refreshErr: tt.refreshErr}, "auth0", zap.NewNop())
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/refresh", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusServiceUnavailable && w.Header().Get("Retry-After") == "" {
+				t.Error("503 without Retry-After")
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := w.Header().Get("Cache-Control"); got != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", got)
+			}
+			var resp struct {
+				Data oidc.TokenSet `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Data.AccessToken != "access" || resp.Data.IDToken != "id" {
+				t.Errorf("tokens = %+v", resp.Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrorTypeFeatureDisabled is the error type of responses for features switched off by server configuration
+const ErrorTypeFeatureDisabled = "Feature Disabled"
+
+// DefaultUnavailableRetryAfter is the Retry-After hint sent with a 503 when the cause gives no better one
+const DefaultUnavailableRetryAfter = 30 * time.Second
+
+// respondUnavailable responds 503 for a transient outage, with a Retry-After hint (in whole seconds, at least
+// one) telling clients when to try again
+func respondUnavailable(w http.ResponseWriter, message string, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	respondJSONError(w, http.StatusServiceUnavailable, "Service Unavailable", message)
+}
+
+// respondFeatureDisabled responds 403 for a feature the server is configured without. No Retry-After is sent:
+// retrying cannot succeed until an operator enables the feature.
+func respondFeatureDisabled(w http.ResponseWriter, feature string) {
+	respondJSONError(w, http.StatusForbidden, ErrorTypeFeatureDisabled, fmt.Sprintf("%s is disabled on this server", feature))
+}
+
+// FeatureDisabledHandler answers every request with the 403 Feature Disabled response for feature, for
+// registering the routes of a feature the server is configured without
+func FeatureDisabledHandler(feature string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondFeatureDisabled(w, feature)
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/services/ai"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func TestChatHandler_RespondChatError(t *testing.T) {
+	t.Parallel()
+
+	hint := 90 * time.Second
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name:           "transient outage with provider hint",
+			err:            &ai.ProviderError{Kind: ai.AIErrorRateLimit, Provider: "openai", RetryAfter: &hint, Err: errors.New("slow down")},
+			wantStatus:     http.StatusServiceUnavailable,
+			wantRetryAfter: "90",
+		},
+		{
+			name:           "transient outage without hint",
+			err:            &ai.ProviderError{Kind: ai.AIErrorServer, Provider: "openai", StatusCode: 502, Err: errors.New("bad gateway")},
+			wantStatus:     http.StatusServiceUnavailable,
+			wantRetryAfter: "30",
+		},
+		{
+			name:       "rejected request",
+			err:        &ai.ProviderError{Kind: ai.AIErrorInvalidRequest, Provider: "openai", StatusCode: 400, Err: errors.New("bad request")},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := &ChatHandler{logger: zap.NewNop()}
+			w := httptest.NewRecorder()
+			h.respondChatError(w, uuid.New(), tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestRegisterDisabledChatRoutes(t *testing.T) {
+	t.Parallel()
+
+	router := mux.NewRouter()
+	RegisterDisabledChatRoutes(router)
+
+	for _, tt := range []struct{ method, path string }{{"GET", "/chat"}, {"POST", "/chat/message"}} {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s %s status = %d, want 403", tt.method, tt.path, w.Code)
+		}
+		if got := w.Header().Get("Retry-After"); got != "" {
+			t.Errorf("%s %s sent Retry-After %q for a disabled feature", tt.method, tt.path, got)
+		}
+		var resp struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if resp.Error != ErrorTypeFeatureDisabled || resp.Message != "AI chat is disabled on this server" {
+			t.Errorf("%s %s response = %+v, want AI chat feature disabled", tt.method, tt.path, resp)
+		}
+	}
+}
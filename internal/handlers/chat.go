@@ -37,8 +37,19 @@ func NewChatHandler(chatService *ai.ChatService, contextService *ai.ContextServi
 
 // RegisterRoutes registers chat routes
 func (h *ChatHandler) RegisterRoutes(r *mux.Router) {
-	r.HandleFunc("/chat", h.StartChat).Methods("GET")
-	r.HandleFunc("/chat/message", h.SendMessage).Methods("POST")
+	registerChatRoutes(r, h.StartChat, h.SendMessage)
+}
+
+// RegisterDisabledChatRoutes registers the chat routes answering 403 Feature Disabled, for servers without
+// an AI provider, so clients can tell chat is switched off rather than missing or down
+func RegisterDisabledChatRoutes(r *mux.Router) {
+	disabled := FeatureDisabledHandler("AI chat")
+	registerChatRoutes(r, disabled, disabled)
+}
+
+func registerChatRoutes(r *mux.Router, startChat, sendMessage http.HandlerFunc) {
+	r.HandleFunc("/chat", startChat).Methods("GET")
+	r.HandleFunc("/chat/message", sendMessage).Methods("POST")
 }
 
 // ChatMessageRequest represents a chat message request
@@ -166,7 +177,7 @@ func (h *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 	// Get AI response
 	response, err := h.chatService.GetResponse(ctxWithUserID, session, userContext)
 	if err != nil {
-		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to get AI response")
+		h.respondChatError(w, user.ID, err)
 		return
 	}
 
@@ -194,6 +205,25 @@ func (h *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// respondChatError responds to a failed AI response: 503 with a Retry-After hint when the AI provider is
+// having a transient outage, 500 otherwise
+func (h *ChatHandler) respondChatError(w http.ResponseWriter, userID uuid.UUID, err error) {
+	h.logger.Warn("failed_to_get_chat_response",
+		zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+		zap.String("error_kind", string(ai.ClassifyError(err))),
+		zap.String("error", logpkg.SanitizeError(err)),
+	)
+	if !ai.IsTransient(err) {
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to get AI response")
+		return
+	}
+	retryAfter := DefaultUnavailableRetryAfter
+	if hint := ai.RetryAfterHint(err); hint != nil {
+		retryAfter = *hint
+	}
+	respondUnavailable(w, "The AI provider is temporarily unavailable; try again later", retryAfter)
+}
+
 // formatSSEMessage formats a message for SSE
 func (h *ChatHandler) formatSSEMessage(event string, data any) string {
 	jsonData, err := json.Marshal(data)
@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/benvon/smart-todo/internal/database"
+	"github.com/benvon/smart-todo/internal/models"
 	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/benvon/smart-todo/internal/request"
 )
 
 // Pinger is implemented by dependencies that support a connectivity check (e.g. Redis).
@@ -15,32 +18,92 @@ type Pinger interface {
 	Ping(ctx context.Context) error
 }
 
+// DependencyInfoProvider is implemented by dependencies that can report version details (e.g. server version).
+type DependencyInfoProvider interface {
+	DependencyInfo(ctx context.Context) (map[string]string, error)
+}
+
+// AnalysisPauseStatus reports the global AI analysis kill switch
+type AnalysisPauseStatus interface {
+	Status(ctx context.Context) (models.AIAnalysisPause, error)
+}
+
 // HealthChecker handles health check requests
 type HealthChecker struct {
-	db          *database.DB
-	redisPinger Pinger
-	jobQueue    queue.JobQueue
+	db            *database.DB
+	redisPinger   Pinger
+	jobQueue      queue.JobQueue
+	adminToken    string
+	infoProviders map[string]DependencyInfoProvider
+	schemaReader  database.SchemaVersionReader
+	schemaVersion uint
+	backpressure  *queue.Backpressure
+	analysisPause AnalysisPauseStatus
+}
+
+// HealthCheckerOption configures a HealthChecker.
+type HealthCheckerOption func(*HealthChecker)
+
+// WithHealthAdminToken sets the admin token required for verbose health output.
+func WithHealthAdminToken(token string) HealthCheckerOption {
+	return func(h *HealthChecker) { h.adminToken = token }
+}
+
+// WithDependencyInfo registers a dependency whose version details are reported in verbose health output.
+func WithDependencyInfo(name string, provider DependencyInfoProvider) HealthCheckerOption {
+	return func(h *HealthChecker) {
+		if provider != nil {
+			h.infoProviders[name] = provider
+		}
+	}
+}
+
+// WithSchemaCheck makes readiness and extended health fail while the database schema is behind the
+// migration version this binary expects
+func WithSchemaCheck(reader database.SchemaVersionReader, expected uint) HealthCheckerOption {
+	return func(h *HealthChecker) {
+		h.schemaReader = reader
+		h.schemaVersion = expected
+	}
+}
+
+// WithQueueBackpressure reports the job queue depth and whether backpressure is active in extended health.
+// Backpressure does not make the service unhealthy.
+func WithQueueBackpressure(bp *queue.Backpressure) HealthCheckerOption {
+	return func(h *HealthChecker) { h.backpressure = bp }
+}
+
+// WithAnalysisPause reports whether AI analysis is paused in extended health. A pause does not make the
+// service unhealthy.
+func WithAnalysisPause(pause AnalysisPauseStatus) HealthCheckerOption {
+	return func(h *HealthChecker) { h.analysisPause = pause }
 }
 
 // NewHealthChecker creates a new health checker
 func NewHealthChecker(db *database.DB) *HealthChecker {
-	return &HealthChecker{db: db}
+	return &HealthChecker{db: db, infoProviders: make(map[string]DependencyInfoProvider)}
 }
 
 // NewHealthCheckerWithDeps creates a new health checker with Redis and RabbitMQ dependencies
-func NewHealthCheckerWithDeps(db *database.DB, redisPinger Pinger, jobQueue queue.JobQueue) *HealthChecker {
-	return &HealthChecker{
-		db:          db,
-		redisPinger: redisPinger,
-		jobQueue:    jobQueue,
+func NewHealthCheckerWithDeps(db *database.DB, redisPinger Pinger, jobQueue queue.JobQueue, opts ...HealthCheckerOption) *HealthChecker {
+	h := &HealthChecker{
+		db:            db,
+		redisPinger:   redisPinger,
+		jobQueue:      jobQueue,
+		infoProviders: make(map[string]DependencyInfoProvider),
+	}
+	for _, o := range opts {
+		o(h)
 	}
+	return h
 }
 
 // HealthResponse represents the health check response
 type HealthResponse struct {
-	Status    string            `json:"status"`
-	Timestamp string            `json:"timestamp"`
-	Checks    map[string]string `json:"checks,omitempty"`
+	Status       string                       `json:"status"`
+	Timestamp    any                          `json:"timestamp"`
+	Checks       map[string]string            `json:"checks,omitempty"`
+	Dependencies map[string]map[string]string `json:"dependencies,omitempty"`
 }
 
 // runExtendedChecks runs database, Redis, and RabbitMQ checks and returns checks map and overall status.
@@ -48,7 +111,9 @@ func (h *HealthChecker) runExtendedChecks(ctx context.Context) (map[string]strin
 	checks := make(map[string]string)
 	status := "healthy"
 
-	if err := h.checkDatabase(ctx); err != nil {
+	if h.db == nil {
+		checks["database"] = "not configured"
+	} else if err := h.checkDatabase(ctx); err != nil {
 		status = "unhealthy"
 		checks["database"] = "unhealthy"
 	} else {
@@ -77,33 +142,139 @@ func (h *HealthChecker) runExtendedChecks(ctx context.Context) (map[string]strin
 		checks["rabbitmq"] = "not configured"
 	}
 
+	if h.schemaReader != nil {
+		if err := h.checkSchema(ctx); err != nil {
+			status = "unhealthy"
+			checks["schema"] = "unhealthy"
+		} else {
+			checks["schema"] = "healthy"
+		}
+	}
+
+	h.addBackpressureChecks(ctx, checks)
+	h.addAnalysisPauseCheck(ctx, checks)
+
 	return checks, status
 }
 
+// addBackpressureChecks adds the job queue depth and backpressure state to checks when backpressure is configured
+func (h *HealthChecker) addBackpressureChecks(ctx context.Context, checks map[string]string) {
+	if h.backpressure == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	status, err := h.backpressure.Status(ctx)
+	if err != nil {
+		checks["backpressure"] = "unknown"
+		return
+	}
+	checks["queue_depth"] = strconv.Itoa(status.Depth)
+	checks["backpressure"] = "inactive"
+	if status.Active {
+		checks["backpressure"] = "active"
+	}
+}
+
+// addAnalysisPauseCheck adds the AI analysis state ("active" or "paused") to checks when the kill switch is configured
+func (h *HealthChecker) addAnalysisPauseCheck(ctx context.Context, checks map[string]string) {
+	if h.analysisPause == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	state, err := h.analysisPause.Status(ctx)
+	if err != nil {
+		checks["ai_analysis"] = "unknown"
+		return
+	}
+	checks["ai_analysis"] = "active"
+	if state.Paused {
+		checks["ai_analysis"] = "paused"
+	}
+}
+
+// collectDependencyInfo gathers version details from each registered provider with a short timeout.
+// Failures are reported inline so one unreachable dependency does not hide the others.
+func (h *HealthChecker) collectDependencyInfo(ctx context.Context) map[string]map[string]string {
+	deps := make(map[string]map[string]string, len(h.infoProviders))
+	for name, provider := range h.infoProviders {
+		infoCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		info, err := provider.DependencyInfo(infoCtx)
+		cancel()
+		if err != nil {
+			deps[name] = map[string]string{"error": sanitizeErrorMessage(err.Error())}
+			continue
+		}
+		deps[name] = info
+	}
+	return deps
+}
+
 // writeHealthResponse writes a HealthResponse with the given status and optional checks.
-func (h *HealthChecker) writeHealthResponse(w http.ResponseWriter, status string, checks map[string]string) {
+func (h *HealthChecker) writeHealthResponse(w http.ResponseWriter, status string, checks map[string]string, deps map[string]map[string]string) {
 	code := http.StatusOK
 	if status == "unhealthy" {
 		code = http.StatusServiceUnavailable
 	}
 	resp := HealthResponse{
-		Status:    status,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
-		Checks:    checks,
+		Status:       status,
+		Timestamp:    ResponseTimestamp(),
+		Checks:       checks,
+		Dependencies: deps,
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
-// HealthCheck handles the /healthz endpoint
+// HealthCheck handles the /healthz endpoint.
+// ?mode=extended adds dependency checks; ?verbose=true (admin only) also reports dependency versions.
 func (h *HealthChecker) HealthCheck(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("verbose") == "true" {
+		if !request.HasAdminToken(r, h.adminToken) {
+			respondJSONError(w, http.StatusForbidden, "Forbidden", "Verbose health output requires admin access")
+			return
+		}
+		checks, status := h.runExtendedChecks(r.Context())
+		h.writeHealthResponse(w, status, checks, h.collectDependencyInfo(r.Context()))
+		return
+	}
 	if r.URL.Query().Get("mode") == "extended" {
 		checks, status := h.runExtendedChecks(r.Context())
-		h.writeHealthResponse(w, status, checks)
+		h.writeHealthResponse(w, status, checks, nil)
 		return
 	}
-	h.writeHealthResponse(w, "healthy", nil)
+	h.writeHealthResponse(w, "healthy", nil, nil)
+}
+
+// ReadinessCheck handles the /readyz endpoint. The instance is ready once the database is reachable and,
+// with WithSchemaCheck, migrated to the version this binary expects; otherwise it responds 503.
+func (h *HealthChecker) ReadinessCheck(w http.ResponseWriter, r *http.Request) {
+	checks := make(map[string]string)
+	status := "healthy"
+	if h.db != nil {
+		checks["database"] = "healthy"
+		if err := h.checkDatabase(r.Context()); err != nil {
+			status = "unhealthy"
+			checks["database"] = "unhealthy"
+		}
+	}
+	if h.schemaReader != nil {
+		checks["schema"] = "healthy"
+		if err := h.checkSchema(r.Context()); err != nil {
+			status = "unhealthy"
+			checks["schema"] = "unhealthy"
+		}
+	}
+	h.writeHealthResponse(w, status, checks, nil)
+}
+
+// checkSchema verifies the applied migration version
+func (h *HealthChecker) checkSchema(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return database.CheckSchemaVersion(ctx, h.schemaReader, h.schemaVersion)
 }
 
 // checkDatabase verifies the database connection
@@ -1,9 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/benvon/smart-todo/internal/request"
 )
 
 func TestHealthChecker_BasicMode(t *testing.T) {
@@ -162,3 +173,203 @@ func TestHealthResponse_NotConfigured(t *testing.T) {
 		t.Errorf("Expected rabbitmq check to be 'not configured', got %s", unmarshaled.Checks["rabbitmq"])
 	}
 }
+
+type mockDependencyInfo struct {
+	info map[string]string
+	err  error
+}
+
+func (m *mockDependencyInfo) DependencyInfo(ctx context.Context) (map[string]string, error) {
+	return m.info, m.err
+}
+
+func TestHealthChecker_Verbose(t *testing.T) {
+	t.Parallel()
+
+	const token = "admin-secret"
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+		wantDeps   map[string]map[string]string
+	}{
+		{
+			name:       "admin token reports dependency versions",
+			token:      token,
+			wantStatus: http.StatusOK,
+			wantDeps: map[string]map[string]string{
+				"redis":    {"version": "7.2.4", "mode": "standalone"},
+				"rabbitmq": {"error": "dial tcp 10.0.0.1:5672: connection refused"},
+			},
+		},
+		{name: "missing token is forbidden", wantStatus: http.StatusForbidden},
+		{name: "wrong token is forbidden", token: "nope", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := NewHealthCheckerWithDeps(nil, nil, nil,
+				WithHealthAdminToken(token),
+				WithDependencyInfo("redis", &mockDependencyInfo{info: map[string]string{"version": "7.2.4", "mode": "standalone"}}),
+				WithDependencyInfo("rabbitmq", &mockDependencyInfo{err: errors.New("dial tcp 10.0.0.1:5672: connection refused")}),
+			)
+			req := httptest.NewRequest(http.MethodGet, "/healthz?verbose=true", nil)
+			if tt.token != "" {
+				req.Header.Set(request.AdminTokenHeader, tt.token)
+			}
+			w := httptest.NewRecorder()
+			h.HealthCheck(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantDeps == nil {
+				if strings.Contains(w.Body.String(), "dependencies") {
+					t.Error("forbidden response must not include dependency details")
+				}
+				return
+			}
+			var resp HealthResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Checks["database"] != "not configured" {
+				t.Errorf("database check = %q, want not configured", resp.Checks["database"])
+			}
+			if !reflect.DeepEqual(resp.Dependencies, tt.wantDeps) {
+				t.Errorf("dependencies = %v, want %v", resp.Dependencies, tt.wantDeps)
+			}
+		})
+	}
+}
+
+type stubSchemaVersion struct {
+	version uint
+	dirty   bool
+	err     error
+}
+
+func (s *stubSchemaVersion) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	return s.version, s.dirty, s.err
+}
+
+func TestHealthChecker_ReadinessSchemaCheck(t *testing.T) {
+	t.Parallel()
+
+	const expected = 15
+	tests := []struct {
+		name       string
+		schema     *stubSchemaVersion
+		wantStatus int
+		wantCheck  string
+	}{
+		{"current schema is ready", &stubSchemaVersion{version: expected}, http.StatusOK, "healthy"},
+		{"newer schema is ready", &stubSchemaVersion{version: expected + 1}, http.StatusOK, "healthy"},
+		{"stale schema is not ready", &stubSchemaVersion{version: expected - 2}, http.StatusServiceUnavailable, "unhealthy"},
+		{"never migrated is not ready", &stubSchemaVersion{}, http.StatusServiceUnavailable, "unhealthy"},
+		{"dirty migration is not ready", &stubSchemaVersion{version: expected, dirty: true}, http.StatusServiceUnavailable, "unhealthy"},
+		{"version read error is not ready", &stubSchemaVersion{err: errors.New("connection refused")}, http.StatusServiceUnavailable, "unhealthy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := NewHealthCheckerWithDeps(nil, nil, nil, WithSchemaCheck(tt.schema, expected))
+
+			for _, target := range []string{"/readyz", "/healthz?mode=extended"} {
+				req := httptest.NewRequest(http.MethodGet, target, nil)
+				w := httptest.NewRecorder()
+				if target == "/readyz" {
+					h.ReadinessCheck(w, req)
+				} else {
+					h.HealthCheck(w, req)
+				}
+
+				if w.Code != tt.wantStatus {
+					t.Fatalf("%s status = %d, want %d", target, w.Code, tt.wantStatus)
+				}
+				var resp HealthResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.Checks["schema"] != tt.wantCheck {
+					t.Errorf("%s schema check = %q, want %q", target, resp.Checks["schema"], tt.wantCheck)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthChecker_ExtendedBackpressure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		depth            int
+		wantBackpressure string
+	}{
+		{"below watermark", 4, "inactive"},
+		{"at watermark", 10, "active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			bp := queue.NewBackpressure(stubQueueStats{depth: tt.depth}, 10, queue.BackpressureModeSkip, 0)
+			h := NewHealthCheckerWithDeps(nil, nil, nil, WithQueueBackpressure(bp))
+			req := httptest.NewRequest(http.MethodGet, "/healthz?mode=extended", nil)
+			w := httptest.NewRecorder()
+			h.HealthCheck(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d (backpressure must not fail health)", w.Code, http.StatusOK)
+			}
+			var resp HealthResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Checks["backpressure"] != tt.wantBackpressure {
+				t.Errorf("backpressure = %q, want %q", resp.Checks["backpressure"], tt.wantBackpressure)
+			}
+			if resp.Checks["queue_depth"] != strconv.Itoa(tt.depth) {
+				t.Errorf("queue_depth = %q, want %d", resp.Checks["queue_depth"], tt.depth)
+			}
+		})
+	}
+}
+
+func TestHealthChecker_ExtendedAnalysisPause(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		pause *fakeAnalysisPauseSwitch
+		want  string
+	}{
+		{"running", &fakeAnalysisPauseSwitch{}, "active"},
+		{"paused", &fakeAnalysisPauseSwitch{state: models.AIAnalysisPause{Paused: true}}, "paused"},
+		{"state unavailable", &fakeAnalysisPauseSwitch{err: errors.New("redis down")}, "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := NewHealthCheckerWithDeps(nil, nil, nil, WithAnalysisPause(tt.pause))
+			req := httptest.NewRequest(http.MethodGet, "/healthz?mode=extended", nil)
+			w := httptest.NewRecorder()
+			h.HealthCheck(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d (a pause must not fail health)", w.Code, http.StatusOK)
+			}
+			var resp HealthResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Checks["ai_analysis"] != tt.want {
+				t.Errorf("ai_analysis = %q, want %q", resp.Checks["ai_analysis"], tt.want)
+			}
+		})
+	}
+}
@@ -2,19 +2,21 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"time"
+	"strconv"
+	"strings"
 )
 
-// respondJSON sends a JSON response
+// respondJSON sends a JSON response; success is false unless status is 2xx
 func respondJSON(w http.ResponseWriter, status int, data any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
 	response := map[string]any{
-		"success":   true,
+		"success":   status >= 200 && status < 300,
 		"data":      data,
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"timestamp": ResponseTimestamp(),
 	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -22,6 +24,17 @@ func respondJSON(w http.ResponseWriter, status int, data any) {
 	}
 }
 
+// respondJSONUnwrapped sends data as the JSON response body without the success/data/timestamp envelope
+// (API v2 and later)
+func respondJSONUnwrapped(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
 // sanitizeErrorMessage removes internal details from error messages
 func sanitizeErrorMessage(message string) string {
 	// Remove file paths (common patterns)
@@ -49,10 +62,68 @@ func respondJSONError(w http.ResponseWriter, status int, errorType, message stri
 		"success":   false,
 		"error":     errorType,
 		"message":   sanitizedMessage,
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"timestamp": ResponseTimestamp(),
 	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
+
+// UnknownFieldError is returned by strict request body decoding for a field the request type does not define
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q in request body", e.Field)
+}
+
+// decodeJSONBody decodes the request body into dst. With strict set, a body field dst does not define is
+// rejected with an *UnknownFieldError instead of being silently ignored.
+func decodeJSONBody(r *http.Request, dst any, strict bool) error {
+	decoder := json.NewDecoder(r.Body)
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	err := decoder.Decode(dst)
+	if err == nil {
+		return nil
+	}
+	// encoding/json reports unknown fields only through the message: json: unknown field "name"
+	if quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if field, unquoteErr := strconv.Unquote(quoted); unquoteErr == nil {
+			return &UnknownFieldError{Field: field}
+		}
+	}
+	return err
+}
+
+// respondBodyDecodeError maps a request body decoding error to 413 for oversized bodies and 400 otherwise,
+// naming the field when strict decoding rejected an unknown one
+func respondBodyDecodeError(w http.ResponseWriter, err error) {
+	if maxBytesErr, ok := err.(*http.MaxBytesError); ok {
+		respondJSONError(w, http.StatusRequestEntityTooLarge, "Request Entity Too Large", fmt.Sprintf("Request body exceeds maximum size of %d bytes", maxBytesErr.Limit))
+		return
+	}
+	if unknownErr, ok := err.(*UnknownFieldError); ok {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", fmt.Sprintf("Invalid request body: %s", unknownErr.Error()))
+		return
+	}
+	respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid request body")
+}
+
+// etagMatches reports whether an If-None-Match header matches etag using weak comparison
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
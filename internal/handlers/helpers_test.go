@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -226,3 +228,73 @@ func TestRespondJSONTimestamp(t *testing.T) {
 		t.Errorf("Timestamp '%s' is not valid RFC3339: %v", timestamp, err)
 	}
 }
+
+func TestEtagMatches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		etag        string
+		want        bool
+	}{
+		{"empty header", "", `W/"v1"`, false},
+		{"exact match", `W/"v1"`, `W/"v1"`, true},
+		{"strong header matches weak etag", `"v1"`, `W/"v1"`, true},
+		{"match in list", `"v0", W/"v1"`, `W/"v1"`, true},
+		{"wildcard", "*", `W/"v1"`, true},
+		{"no match", `W/"v2"`, `W/"v1"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := etagMatches(tt.ifNoneMatch, tt.etag); got != tt.want {
+				t.Errorf("etagMatches(%q, %q) = %v, want %v", tt.ifNoneMatch, tt.etag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONBody(t *testing.T) {
+	t.Parallel()
+
+	type body struct {
+		Text   string `json:"text"`
+		Nested struct {
+			Count int `json:"count"`
+		} `json:"nested"`
+	}
+	tests := []struct {
+		name        string
+		body        string
+		strict      bool
+		wantErr     bool
+		wantUnknown string
+	}{
+		{name: "lenient ignores unknown field", body: `{"text":"a","txet":"b"}`},
+		{name: "strict accepts known fields", body: `{"text":"a","nested":{"count":1}}`, strict: true},
+		{name: "strict rejects unknown field", body: `{"text":"a","txet":"b"}`, strict: true, wantErr: true, wantUnknown: "txet"},
+		{name: "strict rejects nested unknown field", body: `{"nested":{"cuont":1}}`, strict: true, wantErr: true, wantUnknown: "cuont"},
+		{name: "malformed body", body: `{"text":`, strict: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			var dst body
+			err := decodeJSONBody(r, &dst, tt.strict)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeJSONBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var unknownErr *UnknownFieldError
+			if gotUnknown := errors.As(err, &unknownErr); gotUnknown != (tt.wantUnknown != "") {
+				t.Fatalf("decodeJSONBody() error = %v, want unknown field %q", err, tt.wantUnknown)
+			}
+			if unknownErr != nil && unknownErr.Field != tt.wantUnknown {
+				t.Errorf("unknown field = %q, want %q", unknownErr.Field, tt.wantUnknown)
+			}
+		})
+	}
+}
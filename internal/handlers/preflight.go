@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// preflightProbeMethods are the methods a preflight request may be asking about
+var preflightProbeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+type preflightMatchKey struct{}
+
+// RegisterPreflightRoute adds a catch-all OPTIONS route to r that answers 204 for paths another route of r
+// serves. Router middleware (CORS in particular) runs for those requests as for any matched route. OPTIONS
+// requests for paths no route serves fall through to the router's 404, so preflight is not granted for
+// endpoints that do not exist. Register it after all other routes.
+func RegisterPreflightRoute(r *mux.Router) {
+	r.Methods(http.MethodOptions).MatcherFunc(registeredPathMatcher(r)).HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// CORS middleware has already set the headers
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// registeredPathMatcher matches requests whose path some route of router serves for one of the
+// preflightProbeMethods. Probe copies of the request are marked so the preflight route skips itself.
+// (mux's own method-mismatch reporting cannot be used: a later route in the same subrouter clears it.)
+func registeredPathMatcher(router *mux.Router) mux.MatcherFunc {
+	return func(req *http.Request, _ *mux.RouteMatch) bool {
+		if req.Context().Value(preflightMatchKey{}) != nil {
+			return false
+		}
+		probe := req.Clone(context.WithValue(req.Context(), preflightMatchKey{}, true))
+		for _, method := range preflightProbeMethods {
+			probe.Method = method
+			var match mux.RouteMatch
+			if router.Match(probe, &match) && match.MatchErr == nil {
+				return true
+			}
+		}
+		return false
+	}
+}
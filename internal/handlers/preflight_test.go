@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/middleware"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func TestRegisterPreflightRoute(t *testing.T) {
+	t.Parallel()
+
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	r := mux.NewRouter()
+	r.Use(middleware.CORS([]string{"https://app.example.com"}, zap.NewNop(), false))
+	r.HandleFunc("/healthz", ok).Methods("GET")
+	todos := r.PathPrefix("/api/v1/todos").Subrouter()
+	todos.HandleFunc("", ok).Methods("GET", "POST")
+	todos.HandleFunc("/{id}", ok).Methods("PATCH")
+	RegisterPreflightRoute(r)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantCORS   bool
+	}{
+		{"top-level route", http.MethodOptions, "/healthz", http.StatusNoContent, true},
+		{"subrouter route", http.MethodOptions, "/api/v1/todos", http.StatusNoContent, true},
+		{"route with path variable", http.MethodOptions, "/api/v1/todos/123", http.StatusNoContent, true},
+		{"nonexistent path", http.MethodOptions, "/api/v1/nope", http.StatusNotFound, false},
+		{"nonexistent path under a subrouter", http.MethodOptions, "/api/v1/todos/123/nope", http.StatusNotFound, false},
+		{"other methods are unaffected", http.MethodGet, "/healthz", http.StatusOK, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Origin", "https://app.example.com")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin") != ""; got != tt.wantCORS {
+				t.Errorf("CORS headers set = %v, want %v", got, tt.wantCORS)
+			}
+		})
+	}
+}
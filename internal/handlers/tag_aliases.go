@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// TagAliasStore loads and saves the AI context that holds a user's tag aliases
+type TagAliasStore interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.AIContext, error)
+	Upsert(ctx context.Context, aiContext *models.AIContext) error
+}
+
+// TagAliasHandler handles CRUD for user-defined tag aliases
+type TagAliasHandler struct {
+	store  TagAliasStore
+	logger *zap.Logger
+}
+
+// NewTagAliasHandler creates a new tag alias handler
+func NewTagAliasHandler(store TagAliasStore, logger *zap.Logger) *TagAliasHandler {
+	return &TagAliasHandler{store: store, logger: logger}
+}
+
+// RegisterRoutes registers tag alias routes
+// The router should already have the /ai prefix
+func (h *TagAliasHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/tag-aliases", h.ListAliases).Methods("GET")
+	r.HandleFunc("/tag-aliases/{alias}", h.SetAlias).Methods("PUT")
+	r.HandleFunc("/tag-aliases/{alias}", h.DeleteAlias).Methods("DELETE")
+}
+
+// TagAliasesResponse lists a user's tag aliases, keyed by alias
+type TagAliasesResponse struct {
+	Aliases map[string]string `json:"aliases"`
+}
+
+// SetTagAliasRequest maps an alias to a canonical tag
+type SetTagAliasRequest struct {
+	Canonical string `json:"canonical"`
+}
+
+// ListAliases returns the current user's tag aliases
+func (h *TagAliasHandler) ListAliases(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	aiContext, err := h.loadContext(r.Context(), user.ID)
+	if err != nil {
+		h.logError("failed_to_load_tag_aliases", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to get tag aliases")
+		return
+	}
+	respondJSON(w, http.StatusOK, tagAliasesResponse(aiContext))
+}
+
+// SetAlias creates or replaces the alias in the path with the canonical tag from the body
+func (h *TagAliasHandler) SetAlias(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	var req SetTagAliasRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondJSONError(w, http.StatusRequestEntityTooLarge, "Request Entity Too Large", fmt.Sprintf("Request body exceeds maximum size of %d bytes", maxBytesErr.Limit))
+			return
+		}
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid request body")
+		return
+	}
+	ctx := r.Context()
+	aiContext, err := h.loadContext(ctx, user.ID)
+	if err != nil {
+		h.logError("failed_to_load_tag_aliases", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to update tag alias")
+		return
+	}
+	if err := aiContext.SetTagAlias(mux.Vars(r)["alias"], req.Canonical); err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	if err := h.store.Upsert(ctx, aiContext); err != nil {
+		h.logError("failed_to_save_tag_aliases", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to update tag alias")
+		return
+	}
+	respondJSON(w, http.StatusOK, tagAliasesResponse(aiContext))
+}
+
+// DeleteAlias removes the alias in the path
+func (h *TagAliasHandler) DeleteAlias(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	ctx := r.Context()
+	aiContext, err := h.loadContext(ctx, user.ID)
+	if err != nil {
+		h.logError("failed_to_load_tag_aliases", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to delete tag alias")
+		return
+	}
+	if !aiContext.RemoveTagAlias(mux.Vars(r)["alias"]) {
+		respondJSONError(w, http.StatusNotFound, "Not Found", "Tag alias not found")
+		return
+	}
+	if err := h.store.Upsert(ctx, aiContext); err != nil {
+		h.logError("failed_to_save_tag_aliases", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to delete tag alias")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadContext returns the user's AI context, or a new empty one if the user has none yet
+func (h *TagAliasHandler) loadContext(ctx context.Context, userID uuid.UUID) (*models.AIContext, error) {
+	aiContext, err := h.store.GetByUserID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.AIContext{UserID: userID, Preferences: make(map[string]any)}, nil
+	}
+	return aiContext, err
+}
+
+func (h *TagAliasHandler) logError(event string, userID uuid.UUID, err error) {
+	h.logger.Error(event,
+		zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+		zap.String("error", logpkg.SanitizeError(err)),
+	)
+}
+
+func tagAliasesResponse(aiContext *models.AIContext) TagAliasesResponse {
+	aliases := aiContext.TagAliases
+	if aliases == nil {
+		aliases = map[string]string{}
+	}
+	return TagAliasesResponse{Aliases: aliases}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type mockTagAliasStore struct {
+	context *models.AIContext
+	saved   *models.AIContext
+}
+
+func (m *mockTagAliasStore) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.AIContext, error) {
+	if m.context == nil {
+		return nil, fmt.Errorf("failed to get AI context: %w", sql.ErrNoRows)
+	}
+	return m.context, nil
+}
+
+func (m *mockTagAliasStore) Upsert(ctx context.Context, aiContext *models.AIContext) error {
+	m.saved = aiContext
+	return nil
+}
+
+func serveTagAliases(t *testing.T, store *mockTagAliasStore, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := mux.NewRouter()
+	NewTagAliasHandler(store, zap.NewNop()).RegisterRoutes(router)
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req = setUserInRequestContext(req, &models.User{ID: uuid.New()})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTagAliasHandler_SetAlias(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		existing    *models.AIContext
+		alias       string
+		body        string
+		wantStatus  int
+		wantAliases map[string]string
+	}{
+		{
+			name:        "creates context for new user",
+			alias:       "Chores",
+			body:        `{"canonical":"errands"}`,
+			wantStatus:  http.StatusOK,
+			wantAliases: map[string]string{"chores": "errands"},
+		},
+		{
+			name:        "keeps existing aliases and summary",
+			existing:    &models.AIContext{ContextSummary: "likes lists", TagAliases: map[string]string{"job": "work"}},
+			alias:       "chores",
+			body:        `{"canonical":"errands"}`,
+			wantStatus:  http.StatusOK,
+			wantAliases: map[string]string{"job": "work", "chores": "errands"},
+		},
+		{
+			name:       "rejects chained alias",
+			existing:   &models.AIContext{TagAliases: map[string]string{"chores": "errands"}},
+			alias:      "tasks",
+			body:       `{"canonical":"chores"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid body",
+			alias:      "chores",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := &mockTagAliasStore{context: tt.existing}
+			w := serveTagAliases(t, store, "PUT", "/tag-aliases/"+tt.alias, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if store.saved != nil {
+					t.Error("expected nothing saved on error")
+				}
+				return
+			}
+			var resp struct {
+				Data TagAliasesResponse `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if fmt.Sprint(resp.Data.Aliases) != fmt.Sprint(tt.wantAliases) {
+				t.Errorf("aliases = %v, want %v", resp.Data.Aliases, tt.wantAliases)
+			}
+			if tt.existing != nil && store.saved.ContextSummary != tt.existing.ContextSummary {
+				t.Errorf("context summary = %q, want it preserved", store.saved.ContextSummary)
+			}
+		})
+	}
+}
+
+func TestTagAliasHandler_DeleteAlias(t *testing.T) {
+	t.Parallel()
+
+	store := &mockTagAliasStore{context: &models.AIContext{TagAliases: map[string]string{"chores": "errands"}}}
+	if w := serveTagAliases(t, store, "DELETE", "/tag-aliases/chores", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if len(store.saved.TagAliases) != 0 {
+		t.Errorf("aliases after delete = %v, want none", store.saved.TagAliases)
+	}
+	if w := serveTagAliases(t, store, "DELETE", "/tag-aliases/chores", ""); w.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
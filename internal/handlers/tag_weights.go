@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// TagWeightStore loads and saves the AI context that holds a user's tag weights
+type TagWeightStore interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.AIContext, error)
+	Upsert(ctx context.Context, aiContext *models.AIContext) error
+}
+
+// TagWeightHandler handles CRUD for user-defined tag weights
+type TagWeightHandler struct {
+	store  TagWeightStore
+	logger *zap.Logger
+}
+
+// NewTagWeightHandler creates a new tag weight handler
+func NewTagWeightHandler(store TagWeightStore, logger *zap.Logger) *TagWeightHandler {
+	return &TagWeightHandler{store: store, logger: logger}
+}
+
+// RegisterRoutes registers tag weight routes
+// The router should already have the /ai prefix
+func (h *TagWeightHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/tag-weights", h.ListWeights).Methods("GET")
+	r.HandleFunc("/tag-weights/{tag}", h.SetWeight).Methods("PUT")
+	r.HandleFunc("/tag-weights/{tag}", h.DeleteWeight).Methods("DELETE")
+}
+
+// TagWeightsResponse lists a user's tag weights, keyed by tag. Unlisted tags have weight 1.
+type TagWeightsResponse struct {
+	Weights map[string]float64 `json:"weights"`
+}
+
+// SetTagWeightRequest sets the weight of a tag
+type SetTagWeightRequest struct {
+	Weight *float64 `json:"weight"`
+}
+
+// ListWeights returns the current user's tag weights
+func (h *TagWeightHandler) ListWeights(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	aiContext, err := h.loadContext(r.Context(), user.ID)
+	if err != nil {
+		h.logError("failed_to_load_tag_weights", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to get tag weights")
+		return
+	}
+	respondJSON(w, http.StatusOK, tagWeightsResponse(aiContext))
+}
+
+// SetWeight sets the weight of the tag in the path from the body. A weight of 1 resets the tag to the default.
+func (h *TagWeightHandler) SetWeight(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	var req SetTagWeightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondJSONError(w, http.StatusRequestEntityTooLarge, "Request Entity Too Large", fmt.Sprintf("Request body exceeds maximum size of %d bytes", maxBytesErr.Limit))
+			return
+		}
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid request body")
+		return
+	}
+	if req.Weight == nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "weight is required")
+		return
+	}
+	ctx := r.Context()
+	aiContext, err := h.loadContext(ctx, user.ID)
+	if err != nil {
+		h.logError("failed_to_load_tag_weights", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to update tag weight")
+		return
+	}
+	if err := aiContext.SetTagWeight(mux.Vars(r)["tag"], *req.Weight); err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	if err := h.store.Upsert(ctx, aiContext); err != nil {
+		h.logError("failed_to_save_tag_weights", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to update tag weight")
+		return
+	}
+	respondJSON(w, http.StatusOK, tagWeightsResponse(aiContext))
+}
+
+// DeleteWeight resets the tag in the path to the default weight
+func (h *TagWeightHandler) DeleteWeight(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	ctx := r.Context()
+	aiContext, err := h.loadContext(ctx, user.ID)
+	if err != nil {
+		h.logError("failed_to_load_tag_weights", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to delete tag weight")
+		return
+	}
+	if !aiContext.RemoveTagWeight(mux.Vars(r)["tag"]) {
+		respondJSONError(w, http.StatusNotFound, "Not Found", "Tag weight not found")
+		return
+	}
+	if err := h.store.Upsert(ctx, aiContext); err != nil {
+		h.logError("failed_to_save_tag_weights", user.ID, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to delete tag weight")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadContext returns the user's AI context, or a new empty one if the user has none yet
+func (h *TagWeightHandler) loadContext(ctx context.Context, userID uuid.UUID) (*models.AIContext, error) {
+	aiContext, err := h.store.GetByUserID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.AIContext{UserID: userID, Preferences: make(map[string]any)}, nil
+	}
+	return aiContext, err
+}
+
+func (h *TagWeightHandler) logError(event string, userID uuid.UUID, err error) {
+	h.logger.Error(event,
+		zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+		zap.String("error", logpkg.SanitizeError(err)),
+	)
+}
+
+func tagWeightsResponse(aiContext *models.AIContext) TagWeightsResponse {
+	weights := aiContext.TagWeights
+	if weights == nil {
+		weights = map[string]float64{}
+	}
+	return TagWeightsResponse{Weights: weights}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func serveTagWeights(t *testing.T, store *mockTagAliasStore, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := mux.NewRouter()
+	NewTagWeightHandler(store, zap.NewNop()).RegisterRoutes(router)
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req = setUserInRequestContext(req, &models.User{ID: uuid.New()})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTagWeightHandler_SetWeight(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		existing    *models.AIContext
+		tag         string
+		body        string
+		wantStatus  int
+		wantWeights map[string]float64
+	}{
+		{
+			name:        "creates context for new user",
+			tag:         "Work",
+			body:        `{"weight":3}`,
+			wantStatus:  http.StatusOK,
+			wantWeights: map[string]float64{"work": 3},
+		},
+		{
+			name:        "keeps existing weights and aliases",
+			existing:    &models.AIContext{TagAliases: map[string]string{"job": "work"}, TagWeights: map[string]float64{"home": 0.5}},
+			tag:         "work",
+			body:        `{"weight":2.5}`,
+			wantStatus:  http.StatusOK,
+			wantWeights: map[string]float64{"home": 0.5, "work": 2.5},
+		},
+		{
+			name:       "missing weight",
+			tag:        "work",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "weight out of range",
+			tag:        "work",
+			body:       `{"weight":0}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := &mockTagAliasStore{context: tt.existing}
+			w := serveTagWeights(t, store, "PUT", "/tag-weights/"+tt.tag, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if store.saved != nil {
+					t.Error("expected nothing saved on error")
+				}
+				return
+			}
+			var resp struct {
+				Data TagWeightsResponse `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if fmt.Sprint(resp.Data.Weights) != fmt.Sprint(tt.wantWeights) {
+				t.Errorf("weights = %v, want %v", resp.Data.Weights, tt.wantWeights)
+			}
+			if tt.existing != nil && len(store.saved.TagAliases) != len(tt.existing.TagAliases) {
+				t.Errorf("tag aliases = %v, want them preserved", store.saved.TagAliases)
+			}
+		})
+	}
+}
+
+func TestTagWeightHandler_DeleteWeight(t *testing.T) {
+	t.Parallel()
+
+	store := &mockTagAliasStore{context: &models.AIContext{TagWeights: map[string]float64{"work": 3}}}
+	if w := serveTagWeights(t, store, "DELETE", "/tag-weights/work", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if len(store.saved.TagWeights) != 0 {
+		t.Errorf("weights after delete = %v, want none", store.saved.TagWeights)
+	}
+	if w := serveTagWeights(t, store, "DELETE", "/tag-weights/work", ""); w.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
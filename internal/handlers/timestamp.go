@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// TimestampFormatRFC3339 renders response timestamps as RFC3339 strings (default)
+	TimestampFormatRFC3339 = "rfc3339"
+	// TimestampFormatRFC3339Nano renders response timestamps as RFC3339 strings with nanosecond precision
+	TimestampFormatRFC3339Nano = "rfc3339nano"
+	// TimestampFormatUnix renders response timestamps as integer Unix epoch seconds
+	TimestampFormatUnix = "unix"
+)
+
+// TimestampFormatter formats the timestamps included in API responses
+type TimestampFormatter struct {
+	format   string
+	location *time.Location
+}
+
+// NewTimestampFormatter creates a formatter for the given format name and IANA timezone.
+// An empty format defaults to RFC3339 and an empty timezone defaults to UTC.
+func NewTimestampFormatter(format, timezone string) (*TimestampFormatter, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = TimestampFormatRFC3339
+	}
+	switch format {
+	case TimestampFormatRFC3339, TimestampFormatRFC3339Nano, TimestampFormatUnix:
+	default:
+		return nil, fmt.Errorf("unsupported timestamp format %q (expected %s, %s or %s)",
+			format, TimestampFormatRFC3339, TimestampFormatRFC3339Nano, TimestampFormatUnix)
+	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+	return &TimestampFormatter{format: format, location: location}, nil
+}
+
+// Format renders t in the configured format. Unix timestamps are returned as int64, others as strings.
+func (f *TimestampFormatter) Format(t time.Time) any {
+	switch f.format {
+	case TimestampFormatUnix:
+		return t.Unix()
+	case TimestampFormatRFC3339Nano:
+		return t.In(f.location).Format(time.RFC3339Nano)
+	default:
+		return t.In(f.location).Format(time.RFC3339)
+	}
+}
+
+var responseTimestampFormatter atomic.Pointer[TimestampFormatter]
+
+func init() {
+	responseTimestampFormatter.Store(&TimestampFormatter{format: TimestampFormatRFC3339, location: time.UTC})
+}
+
+// SetResponseTimestampFormatter sets the formatter used for all response timestamps.
+// It should be called once at startup before serving requests.
+func SetResponseTimestampFormatter(f *TimestampFormatter) {
+	if f != nil {
+		responseTimestampFormatter.Store(f)
+	}
+}
+
+// ResponseTimestamp returns the current time formatted for inclusion in an API response
+func ResponseTimestamp() any {
+	return responseTimestampFormatter.Load().Format(time.Now())
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimestampFormatter_Format(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 3, 15, 14, 30, 0, 123456789, time.UTC)
+	tests := []struct {
+		name     string
+		format   string
+		timezone string
+		want     any
+	}{
+		{"default", "", "", "2024-03-15T14:30:00Z"},
+		{"rfc3339", TimestampFormatRFC3339, "UTC", "2024-03-15T14:30:00Z"},
+		{"rfc3339nano", TimestampFormatRFC3339Nano, "UTC", "2024-03-15T14:30:00.123456789Z"},
+		{"unix", TimestampFormatUnix, "UTC", int64(1710513000)},
+		{"unix ignores timezone", TimestampFormatUnix, "America/New_York", int64(1710513000)},
+		{"rfc3339 with timezone", TimestampFormatRFC3339, "America/New_York", "2024-03-15T10:30:00-04:00"},
+		{"case insensitive", "RFC3339Nano", "", "2024-03-15T14:30:00.123456789Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := NewTimestampFormatter(tt.format, tt.timezone)
+			if err != nil {
+				t.Fatalf("NewTimestampFormatter() error = %v", err)
+			}
+			if got := f.Format(ts); got != tt.want {
+				t.Errorf("Format() = %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTimestampFormatter_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		format   string
+		timezone string
+	}{
+		{"unknown format", "iso9999", "UTC"},
+		{"unknown timezone", TimestampFormatRFC3339, "Mars/Olympus_Mons"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewTimestampFormatter(tt.format, tt.timezone); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/benvon/smart-todo/internal/database"
@@ -23,10 +25,22 @@ import (
 
 // TodoHandler handles todo-related requests
 type TodoHandler struct {
-	todoRepo     *database.TodoRepository
-	tagStatsRepo database.TagStatisticsRepositoryInterface
-	jobQueue     queue.JobQueue
-	logger       *zap.Logger
+	todoRepo              database.TodoRepositoryInterface
+	tagStatsRepo          database.TagStatisticsRepositoryInterface
+	historyRepo           database.TodoHistoryRepositoryInterface
+	idempotencyRepo       database.IdempotencyRepositoryInterface
+	jobQueue              queue.JobQueue
+	backpressure          *queue.Backpressure
+	manualAnalysisLimiter ManualAnalysisLimiter
+	reanalyzeOnTextChange bool
+	focusWeights          models.FocusWeights
+	strictJSON            bool
+	logger                *zap.Logger
+}
+
+// ManualAnalysisLimiter enforces a minimum interval between a user's manual analyze requests
+type ManualAnalysisLimiter interface {
+	Reserve(ctx context.Context, userID uuid.UUID) (ok bool, next time.Time, err error)
 }
 
 // TodoHandlerOption configures a TodoHandler.
@@ -37,14 +51,45 @@ func WithTodoJobQueue(q queue.JobQueue) TodoHandlerOption {
 	return func(h *TodoHandler) { h.jobQueue = q }
 }
 
+// WithTodoStrictJSON rejects todo request bodies (create, update, merge and the like) with unknown fields
+// (400 naming the field) instead of ignoring them, so typos such as "duedate" surface to the client.
+func WithTodoStrictJSON(enabled bool) TodoHandlerOption {
+	return func(h *TodoHandler) { h.strictJSON = enabled }
+}
+
+// WithTodoBackpressure holds back analysis jobs while the job queue is over its high-watermark.
+func WithTodoBackpressure(bp *queue.Backpressure) TodoHandlerOption {
+	return func(h *TodoHandler) { h.backpressure = bp }
+}
+
 // WithTodoTagStatsRepo sets the tag statistics repository for /tags/stats.
 func WithTodoTagStatsRepo(r database.TagStatisticsRepositoryInterface) TodoHandlerOption {
 	return func(h *TodoHandler) { h.tagStatsRepo = r }
 }
 
+// WithTodoHistoryRepo sets the todo history repository for /{id}/history.
+func WithTodoHistoryRepo(r database.TodoHistoryRepositoryInterface) TodoHandlerOption {
+	return func(h *TodoHandler) { h.historyRepo = r }
+}
+
+// WithTodoIdempotencyRepo lets CreateTodo honour the Idempotency-Key header.
+func WithTodoIdempotencyRepo(r database.IdempotencyRepositoryInterface) TodoHandlerOption {
+	return func(h *TodoHandler) { h.idempotencyRepo = r }
+}
+
+// WithTodoReanalyzeOnTextChange sets whether editing a todo's text enqueues a new analysis (default true).
+func WithTodoReanalyzeOnTextChange(enabled bool) TodoHandlerOption {
+	return func(h *TodoHandler) { h.reanalyzeOnTextChange = enabled }
+}
+
+// WithTodoManualAnalysisLimiter limits how often a user may call /{id}/analyze.
+func WithTodoManualAnalysisLimiter(l ManualAnalysisLimiter) TodoHandlerOption {
+	return func(h *TodoHandler) { h.manualAnalysisLimiter = l }
+}
+
 // NewTodoHandler creates a new todo handler. Options add job queue and/or tag stats support.
-func NewTodoHandler(todoRepo *database.TodoRepository, logger *zap.Logger, opts ...TodoHandlerOption) *TodoHandler {
-	h := &TodoHandler{todoRepo: todoRepo, logger: logger}
+func NewTodoHandler(todoRepo database.TodoRepositoryInterface, logger *zap.Logger, opts ...TodoHandlerOption) *TodoHandler {
+	h := &TodoHandler{todoRepo: todoRepo, logger: logger, reanalyzeOnTextChange: true, focusWeights: models.DefaultFocusWeights}
 	for _, o := range opts {
 		o(h)
 	}
@@ -59,12 +104,27 @@ func (h *TodoHandler) RegisterRoutes(r *mux.Router) {
 	// Only register tag stats route if tagStatsRepo is available
 	if h.tagStatsRepo != nil {
 		r.HandleFunc("/tags/stats", h.GetTagStats).Methods("GET")
-	}
+		r.HandleFunc("/tags/related", h.GetRelatedTags).Methods("GET")
+	}
+	r.HandleFunc("/tags/reset-ai", h.ResetAITags).Methods("POST")
+	r.HandleFunc("/tags/merge", h.MergeTags).Methods("POST")
+	r.HandleFunc("/tags/{tag}", h.DeleteTag).Methods("DELETE")
+	r.HandleFunc("/bulk", h.BulkTodoAction).Methods("POST")
+	r.HandleFunc("/bulk/due-date", h.BulkSetDueDates).Methods("POST")
+	r.HandleFunc("/import/markdown", h.ImportMarkdown).Methods("POST")
+	r.HandleFunc("/focus", h.GetFocusTodos).Methods("GET")
+	r.HandleFunc("/export", h.ExportTodos).Methods("GET")
+	r.HandleFunc("/stats", h.GetTodoStats).Methods("GET")
 	r.HandleFunc("/{id}", h.GetTodo).Methods("GET")
 	r.HandleFunc("/{id}", h.UpdateTodo).Methods("PATCH")
 	r.HandleFunc("/{id}", h.DeleteTodo).Methods("DELETE")
 	r.HandleFunc("/{id}/complete", h.CompleteTodo).Methods("POST")
 	r.HandleFunc("/{id}/analyze", h.AnalyzeTodo).Methods("POST")
+	r.HandleFunc("/{id}/duplicate", h.DuplicateTodo).Methods("POST")
+	r.HandleFunc("/{id}/merge", h.MergeTodo).Methods("POST")
+	if h.historyRepo != nil {
+		r.HandleFunc("/{id}/history", h.GetTodoHistory).Methods("GET")
+	}
 }
 
 const (
@@ -76,21 +136,38 @@ const (
 	DefaultPageSize = 100
 	// MaxPageSize is the maximum page size for pagination
 	MaxPageSize = 500
+	// DefaultRelatedTagsLimit is the default number of related tags returned by /tags/related
+	DefaultRelatedTagsLimit = 10
+	// MaxRelatedTagsLimit is the maximum number of related tags returned by /tags/related
+	MaxRelatedTagsLimit = 50
+	// TagStatsCacheMaxAge is how long clients may reuse a /tags/stats response before revalidating
+	TagStatsCacheMaxAge = 10 * time.Second
 )
 
 // CreateTodoRequest represents a create todo request
 type CreateTodoRequest struct {
-	Text    string  `json:"text" validate:"required,min=1,max=10000"`
-	DueDate *string `json:"due_date,omitempty"` // ISO 8601 (RFC3339) format, e.g., "2024-03-15T14:30:00Z"
+	Text           string                 `json:"text" validate:"required,min=1,max=10000"`
+	DueDate        *string                `json:"due_date,omitempty"`        // ISO 8601 (RFC3339) format, e.g., "2024-03-15T14:30:00Z", or a date, e.g., "2024-03-15"
+	Priority       *string                `json:"priority,omitempty"`        // low, medium, high or urgent
+	ReminderPolicy *models.ReminderPolicy `json:"reminder_policy,omitempty"` // Optional reminder escalation for the due date
+	ExternalRefs   []models.ExternalRef   `json:"external_refs,omitempty"`   // Links to items in external systems
 }
 
 // UpdateTodoRequest represents an update todo request
 type UpdateTodoRequest struct {
-	Text        *string            `json:"text,omitempty"`
-	TimeHorizon *string            `json:"time_horizon,omitempty"` // Empty string to clear user override and let AI manage
-	Status      *models.TodoStatus `json:"status,omitempty"`
-	Tags        *[]string          `json:"tags,omitempty"`     // User-defined tags (overrides AI tags)
-	DueDate     *string            `json:"due_date,omitempty"` // ISO 8601 (RFC3339) format, e.g., "2024-03-15T14:30:00Z", empty string to clear
+	Text           *string                `json:"text,omitempty"`
+	TimeHorizon    *string                `json:"time_horizon,omitempty"` // Empty string to clear user override and let AI manage
+	Status         *models.TodoStatus     `json:"status,omitempty"`
+	Tags           *[]string              `json:"tags,omitempty"`            // User-defined tags (overrides AI tags)
+	AddTags        []string               `json:"add_tags,omitempty"`        // Tags to add as user-defined, keeping the others; not with tags
+	RemoveTags     []string               `json:"remove_tags,omitempty"`     // Tags to remove, keeping the others; not with tags
+	DueDate        *string                `json:"due_date,omitempty"`        // ISO 8601 (RFC3339) format, e.g., "2024-03-15T14:30:00Z", or a date, e.g., "2024-03-15"; empty string to clear
+	Priority       *string                `json:"priority,omitempty"`        // low, medium, high or urgent; empty string to clear
+	ReminderPolicy *models.ReminderPolicy `json:"reminder_policy,omitempty"` // Empty object disables reminders
+	// AnalysisDisabled opts the todo out of (or back into) AI analysis
+	AnalysisDisabled *bool `json:"analysis_disabled,omitempty"`
+	// ExternalRefs replaces the todo's links to external items; an empty list removes them
+	ExternalRefs *[]models.ExternalRef `json:"external_refs,omitempty"`
 }
 
 // ListTodosResponse represents the paginated response for listing todos
@@ -102,12 +179,25 @@ type ListTodosResponse struct {
 	TotalPages int            `json:"total_pages"`
 }
 
+// ListTodosCursorResponse is the listing returned when ?cursor= selects cursor pagination. NextCursor is set
+// when more todos follow; pass it back as ?cursor= for the following page.
+type ListTodosCursorResponse struct {
+	Todos      []*models.Todo `json:"todos"`
+	PageSize   int            `json:"page_size"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
 // listParams holds parsed list query parameters.
 type listParams struct {
 	page        int
 	pageSize    int
 	timeHorizon *models.TimeHorizon
 	status      *models.TodoStatus
+	priority    *models.Priority
+	analyzed    *bool
+	// includeRetired also lists todos archived or trashed by the retention sweep
+	includeRetired bool
+	sort           database.TodoSort
 }
 
 // parseListParams parses and validates list query params from r. Returns an error for invalid values.
@@ -126,9 +216,58 @@ func parseListParams(r *http.Request) (listParams, error) {
 		return listParams{}, err
 	}
 	out.status = st
+	if out.priority, err = parsePriority(r.URL.Query().Get("priority")); err != nil {
+		return listParams{}, err
+	}
+	analyzed, err := parseAnalyzed(r.URL.Query().Get("analyzed"))
+	if err != nil {
+		return listParams{}, err
+	}
+	out.analyzed = analyzed
+	if ir := r.URL.Query().Get("include_retired"); ir != "" {
+		if out.includeRetired, err = strconv.ParseBool(ir); err != nil {
+			return listParams{}, fmt.Errorf("invalid include_retired value %q (expected true or false)", ir)
+		}
+	}
+	if out.sort, err = parseSort(r.URL.Query().Get("sort"), r.URL.Query().Get("order")); err != nil {
+		return listParams{}, err
+	}
 	return out, nil
 }
 
+// parseSort parses the sort field and order; an empty field lists newest first and an empty order uses the
+// field's default order
+func parseSort(field, order string) (database.TodoSort, error) {
+	sort := database.TodoSort{Field: database.TodoSortCreatedAt}
+	if field != "" {
+		sort.Field = database.TodoSortField(field)
+		if !slices.Contains(database.TodoSortFields, sort.Field) {
+			return database.TodoSort{}, fmt.Errorf("invalid sort value %q (expected created_at, due_date, updated_at, time_horizon or priority)", field)
+		}
+	}
+	switch database.SortOrder(order) {
+	case "":
+		sort.Order = sort.Field.DefaultOrder()
+	case database.SortAscending, database.SortDescending:
+		sort.Order = database.SortOrder(order)
+	default:
+		return database.TodoSort{}, fmt.Errorf("invalid order value %q (expected asc or desc)", order)
+	}
+	return sort, nil
+}
+
+// parseAnalyzed parses the analyzed filter; empty means no filtering
+func parseAnalyzed(a string) (*bool, error) {
+	if a == "" {
+		return nil, nil
+	}
+	analyzed, err := strconv.ParseBool(a)
+	if err != nil {
+		return nil, fmt.Errorf("invalid analyzed value %q (expected true or false)", a)
+	}
+	return &analyzed, nil
+}
+
 func parsePage(p string) int {
 	if p == "" {
 		return 1
@@ -176,47 +315,180 @@ func parseStatus(s string) (*models.TodoStatus, error) {
 	return &st, nil
 }
 
-// ListTodos lists todos for the authenticated user with pagination
+func parsePriority(p string) (*models.Priority, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if err := validation.ValidateTodoPriority(p); err != nil {
+		return nil, err
+	}
+	priority := models.Priority(p)
+	return &priority, nil
+}
+
+// ListTodos lists todos for the authenticated user with pagination. A ?cursor= param, empty for the first page,
+// selects keyset pagination over (created_at, id) instead of page offsets, which stays fast on large accounts.
 func (h *TodoHandler) ListTodos(w http.ResponseWriter, r *http.Request) {
 	user := request.UserFromContext(r)
 	if user == nil {
 		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
 		return
 	}
+	system, externalID, lookup, err := parseExternalRefLookup(r)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	if lookup {
+		h.getTodoByExternalRef(w, r, user.ID, system, externalID)
+		return
+	}
 	params, err := parseListParams(r)
 	if err != nil {
 		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
 		return
 	}
-	ctx := r.Context()
-	todos, total, err := h.todoRepo.GetByUserIDPaginated(ctx, user.ID, params.timeHorizon, params.status, params.page, params.pageSize)
+	if r.URL.Query().Has("cursor") {
+		h.listTodosByCursor(w, r, user.ID, params)
+		return
+	}
+	result, err := h.listTodos(r.Context(), user.ID, todoListQuery{params: params})
 	if err != nil {
 		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to retrieve todos")
 		return
 	}
-	totalPages := (total + params.pageSize - 1) / params.pageSize
-	if totalPages == 0 {
-		totalPages = 1
-	}
 	respondJSON(w, http.StatusOK, ListTodosResponse{
-		Todos:      todos,
+		Todos:      result.todos,
 		Page:       params.page,
 		PageSize:   params.pageSize,
-		Total:      total,
-		TotalPages: totalPages,
+		Total:      result.total,
+		TotalPages: result.totalPages(params.pageSize),
 	})
 }
 
-// CreateTodo creates a new todo
+// listTodosByCursor serves the ?cursor= variant of ListTodos
+func (h *TodoHandler) listTodosByCursor(w http.ResponseWriter, r *http.Request, userID uuid.UUID, params listParams) {
+	cursor := r.URL.Query().Get("cursor")
+	if r.URL.Query().Get("page") != "" {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "cursor and page cannot be combined")
+		return
+	}
+	q, err := cursorTodoListQuery(params, cursor)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	result, err := h.listTodos(r.Context(), userID, q)
+	if err != nil {
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to retrieve todos")
+		return
+	}
+	resp := ListTodosCursorResponse{Todos: result.todos, PageSize: params.pageSize, NextCursor: encodeTodoCursor(result.next)}
+	if resp.Todos == nil {
+		resp.Todos = []*models.Todo{}
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// parseExternalRefLookup reads the external_system/external_id lookup params. lookup is false when neither
+// is given; giving only one is an error.
+func parseExternalRefLookup(r *http.Request) (system, id string, lookup bool, err error) {
+	system = models.NormalizeExternalRefSystem(r.URL.Query().Get("external_system"))
+	id = strings.TrimSpace(r.URL.Query().Get("external_id"))
+	if system == "" && id == "" {
+		return "", "", false, nil
+	}
+	if system == "" || id == "" {
+		return "", "", false, fmt.Errorf("external_system and external_id must be given together")
+	}
+	return system, id, true, nil
+}
+
+// getTodoByExternalRef responds with the todo linked to the external item, letting external systems sync
+// idempotently by checking for an existing todo before creating one
+func (h *TodoHandler) getTodoByExternalRef(w http.ResponseWriter, r *http.Request, userID uuid.UUID, system, id string) {
+	todo, err := h.todoRepo.GetByExternalRef(r.Context(), userID, system, id)
+	if errors.Is(err, database.ErrTodoNotFound) {
+		respondJSONError(w, http.StatusNotFound, "Not Found", "No todo is linked to that external item")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed_to_get_todo_by_external_ref",
+			zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+			zap.String("external_system", system),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to retrieve todo")
+		return
+	}
+	respondJSON(w, http.StatusOK, todo)
+}
+
+// todoListQuery is a version-independent todo listing request. With cursorMode set the listing
+// resumes after the cursor (nil for the first page) instead of using params.page.
+type todoListQuery struct {
+	params     listParams
+	cursorMode bool
+	after      *database.TodoCursor
+}
+
+// todoListResult is a page of todos. total is only known in page mode; next is only set in cursor mode
+// when more todos follow.
+type todoListResult struct {
+	todos []*models.Todo
+	total int
+	next  *database.TodoCursor
+}
+
+// totalPages returns the number of pages of pageSize needed for the result's total (at least 1)
+func (res todoListResult) totalPages(pageSize int) int {
+	return max((res.total+pageSize-1)/pageSize, 1)
+}
+
+// listTodos is the listing logic shared by every API version; versions differ only in parsing and shaping
+func (h *TodoHandler) listTodos(ctx context.Context, userID uuid.UUID, q todoListQuery) (todoListResult, error) {
+	filter := database.TodoListFilter{
+		TimeHorizon:    q.params.timeHorizon,
+		Status:         q.params.status,
+		Priority:       q.params.priority,
+		Analyzed:       q.params.analyzed,
+		IncludeRetired: q.params.includeRetired,
+		Sort:           q.params.sort,
+	}
+	if !q.cursorMode {
+		todos, total, err := h.todoRepo.ListByUserID(ctx, userID, filter, q.params.page, q.params.pageSize)
+		return todoListResult{todos: todos, total: total}, err
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	todos, err := h.todoRepo.ListByUserIDAfter(ctx, userID, filter, q.after, q.params.pageSize+1)
+	if err != nil {
+		return todoListResult{}, err
+	}
+	result := todoListResult{todos: todos}
+	if len(todos) > q.params.pageSize {
+		result.todos = todos[:q.params.pageSize]
+		last := result.todos[len(result.todos)-1]
+		result.next = &database.TodoCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	return result, nil
+}
+
+// CreateTodo creates a new todo. With an Idempotency-Key header, a repeat of a create the user already made
+// with the same key returns the original todo with 200 instead of creating another one.
 func (h *TodoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 	user := request.UserFromContext(r)
 	if user == nil {
 		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
 		return
 	}
-	req, err := decodeCreateTodoRequest(r)
-	if err != nil {
-		respondCreateTodoDecodeError(w, err)
+	idempotencyKey, replayed := h.replayIdempotentCreate(w, r, user)
+	if replayed {
+		return
+	}
+	var req CreateTodoRequest
+	if err := decodeJSONBody(r, &req, h.strictJSON); err != nil {
+		respondBodyDecodeError(w, err)
 		return
 	}
 	if err := validateCreateTodoRequest(w, &req); err != nil {
@@ -227,26 +499,22 @@ func (h *TodoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
 		return
 	}
-	if err := h.todoRepo.Create(r.Context(), todo); err != nil {
+	todo, created, err := h.createTodo(r.Context(), user, idempotencyKey, todo)
+	if err != nil {
+		h.logger.Error("failed_to_create_todo",
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
 		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to create todo")
 		return
 	}
-	h.enqueueCreateTodoJob(r.Context(), user, todo)
-	respondJSON(w, http.StatusCreated, todo)
-}
-
-func decodeCreateTodoRequest(r *http.Request) (CreateTodoRequest, error) {
-	var req CreateTodoRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
-}
-
-func respondCreateTodoDecodeError(w http.ResponseWriter, err error) {
-	if maxBytesErr, ok := err.(*http.MaxBytesError); ok {
-		respondJSONError(w, http.StatusRequestEntityTooLarge, "Request Entity Too Large", fmt.Sprintf("Request body exceeds maximum size of %d bytes", maxBytesErr.Limit))
+	if !created {
+		h.respondReplayedCreate(w, user, todo)
 		return
 	}
-	respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid request body")
+	h.enqueueCreateTodoJob(r.Context(), user, todo)
+	h.scheduleReminderChain(r.Context(), todo)
+	respondJSON(w, http.StatusCreated, todo)
 }
 
 func validateCreateTodoRequest(w http.ResponseWriter, req *CreateTodoRequest) error {
@@ -273,27 +541,40 @@ func validateCreateTodoRequest(w http.ResponseWriter, req *CreateTodoRequest) er
 }
 
 func buildTodoFromCreateRequest(req *CreateTodoRequest, user *models.User) (*models.Todo, error) {
-	now := time.Now()
-	timeEntered := now.Format(time.RFC3339)
-	todo := &models.Todo{
+	timeEntered := time.Now().Format(time.RFC3339)
+	todo := newTodo(user, req.Text, &timeEntered)
+	if req.DueDate != nil && *req.DueDate != "" {
+		dueDate, dateOnly, err := models.ParseDueDate(*req.DueDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid due_date format: %w", err)
+		}
+		todo.SetDueDate(&dueDate, dateOnly)
+	}
+	if err := applyPriorityUpdate(todo, req.Priority); err != nil {
+		return nil, err
+	}
+	if err := applyReminderPolicyUpdate(todo, req.ReminderPolicy); err != nil {
+		return nil, err
+	}
+	if err := applyExternalRefsUpdate(todo, &req.ExternalRefs); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// newTodo returns a new pending todo for user with the default time horizon
+func newTodo(user *models.User, text string, timeEntered *string) *models.Todo {
+	return &models.Todo{
 		ID:          uuid.New(),
 		UserID:      user.ID,
-		Text:        req.Text,
+		Text:        text,
 		TimeHorizon: models.TimeHorizonSoon,
 		Status:      models.TodoStatusPending,
 		Metadata: models.Metadata{
 			TagSources:  make(map[string]models.TagSource),
-			TimeEntered: &timeEntered,
+			TimeEntered: timeEntered,
 		},
 	}
-	if req.DueDate != nil && *req.DueDate != "" {
-		dueDate, err := time.Parse(time.RFC3339, *req.DueDate)
-		if err != nil {
-			return nil, fmt.Errorf("invalid due_date format. Expected RFC3339 format (e.g., 2024-03-15T14:30:00Z): %v", err)
-		}
-		todo.DueDate = &dueDate
-	}
-	return todo, nil
 }
 
 func (h *TodoHandler) enqueueCreateTodoJob(ctx context.Context, user *models.User, todo *models.Todo) {
@@ -305,6 +586,9 @@ func (h *TodoHandler) enqueueCreateTodoJob(ctx context.Context, user *models.Use
 		return
 	}
 	job := queue.NewJob(queue.JobTypeTaskAnalysis, user.ID, &todo.ID)
+	if !h.admitAnalysisJob(ctx, job, "create_todo") {
+		return
+	}
 	if err := h.jobQueue.Enqueue(ctx, job); err != nil {
 		h.logger.Warn("failed_to_enqueue_ai_analysis_job",
 			zap.String("operation", "create_todo"),
@@ -320,7 +604,68 @@ func (h *TodoHandler) enqueueCreateTodoJob(ctx context.Context, user *models.Use
 	)
 }
 
-// GetTodo retrieves a todo by ID
+// admitAnalysisJob applies queue backpressure to an analysis job, reporting whether it should be enqueued.
+// Over the high-watermark the job is either dropped or given a NotBefore delay, depending on the mode.
+// If the queue depth cannot be read the job is let through.
+func (h *TodoHandler) admitAnalysisJob(ctx context.Context, job *queue.Job, operation string) bool {
+	if h.backpressure == nil {
+		return true
+	}
+	action, depth, err := h.backpressure.Apply(ctx, job)
+	if err != nil {
+		h.logger.Warn("failed_to_read_queue_depth",
+			zap.String("operation", operation),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return true
+	}
+	switch action {
+	case queue.BackpressureSkip:
+		h.logger.Warn("ai_analysis_job_skipped_backpressure",
+			zap.String("operation", operation),
+			zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+			zap.Int("queue_depth", depth),
+		)
+		return false
+	case queue.BackpressureDelay:
+		h.logger.Warn("ai_analysis_job_delayed_backpressure",
+			zap.String("operation", operation),
+			zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+			zap.Int("queue_depth", depth),
+			zap.Time("not_before", *job.NotBefore),
+		)
+	}
+	return true
+}
+
+// scheduleReminderChain enqueues the next reminder of the todo's escalation chain, if any.
+// Previously scheduled chains are not removed from the queue; they are dropped by the worker
+// once their reminder key no longer matches the todo.
+func (h *TodoHandler) scheduleReminderChain(ctx context.Context, todo *models.Todo) {
+	if h.jobQueue == nil {
+		return
+	}
+	step, ok := todo.NextReminderStep(time.Now())
+	if !ok {
+		return
+	}
+	job := queue.NewDueReminderJob(todo.UserID, todo.ID, todo.ReminderKey(), step.Index, step.At)
+	if err := h.jobQueue.Enqueue(ctx, job); err != nil {
+		h.logger.Warn("failed_to_enqueue_due_reminder_job",
+			zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+			zap.String("user_id", logpkg.SanitizeUserID(todo.UserID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return
+	}
+	h.logger.Debug("enqueued_due_reminder_job",
+		zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+		zap.String("kind", string(step.Kind)),
+		zap.Time("not_before", step.At),
+	)
+}
+
+// GetTodo retrieves a todo by ID, with an ETag of its version for conditional updates
 func (h *TodoHandler) GetTodo(w http.ResponseWriter, r *http.Request) {
 	user := request.UserFromContext(r)
 	if user == nil {
@@ -342,18 +687,10 @@ func (h *TodoHandler) GetTodo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setTodoETag(w, todo)
 	respondJSON(w, http.StatusOK, todo)
 }
 
-// parseAndValidateUpdateRequest decodes the JSON body into UpdateTodoRequest.
-func parseAndValidateUpdateRequest(r *http.Request) (UpdateTodoRequest, error) {
-	var req UpdateTodoRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		return UpdateTodoRequest{}, err
-	}
-	return req, nil
-}
-
 // applyUpdatesToTodo applies req fields to todo. Validates and returns an error on invalid values.
 func applyUpdatesToTodo(todo *models.Todo, req *UpdateTodoRequest) error {
 	if todo.Metadata.TagSources == nil {
@@ -368,10 +705,38 @@ func applyUpdatesToTodo(todo *models.Todo, req *UpdateTodoRequest) error {
 	if err := applyStatusUpdate(todo, req.Status); err != nil {
 		return err
 	}
-	if req.Tags != nil {
-		todo.Metadata.SetUserTags(*req.Tags)
+	if err := applyTagsUpdate(todo, req); err != nil {
+		return err
+	}
+	if err := applyDueDateUpdate(todo, req.DueDate); err != nil {
+		return err
+	}
+	if err := applyPriorityUpdate(todo, req.Priority); err != nil {
+		return err
+	}
+	if req.AnalysisDisabled != nil {
+		todo.Metadata.AnalysisDisabled = *req.AnalysisDisabled
+	}
+	if err := applyExternalRefsUpdate(todo, req.ExternalRefs); err != nil {
+		return err
+	}
+	return applyReminderPolicyUpdate(todo, req.ReminderPolicy)
+}
+
+// applyExternalRefsUpdate validates refs and replaces the todo's external references with them
+func applyExternalRefsUpdate(todo *models.Todo, refs *[]models.ExternalRef) error {
+	if refs == nil {
+		return nil
+	}
+	normalized, err := models.NormalizeExternalRefs(*refs)
+	if err != nil {
+		return err
 	}
-	return applyDueDateUpdate(todo, req.DueDate)
+	if len(normalized) == 0 {
+		normalized = nil
+	}
+	todo.Metadata.ExternalRefs = normalized
+	return nil
 }
 
 func applyTextUpdate(todo *models.Todo, text *string) error {
@@ -389,6 +754,27 @@ func applyTextUpdate(todo *models.Todo, text *string) error {
 	return nil
 }
 
+// applyTagsUpdate replaces the todo's tags with req.Tags, or adds and removes individual tags. The full
+// replacement cannot be combined with add_tags/remove_tags, and a tag cannot be both added and removed.
+func applyTagsUpdate(todo *models.Todo, req *UpdateTodoRequest) error {
+	partial := len(req.AddTags) > 0 || len(req.RemoveTags) > 0
+	if req.Tags != nil {
+		if partial {
+			return fmt.Errorf("tags cannot be combined with add_tags or remove_tags")
+		}
+		todo.Metadata.SetUserTags(*req.Tags)
+		return nil
+	}
+	for _, tag := range req.AddTags {
+		if slices.Contains(req.RemoveTags, tag) {
+			return fmt.Errorf("tag %q is in both add_tags and remove_tags", tag)
+		}
+	}
+	todo.Metadata.RemoveTags(req.RemoveTags)
+	todo.Metadata.AddUserTags(req.AddTags)
+	return nil
+}
+
 func applyTimeHorizonUpdate(todo *models.Todo, th *string) error {
 	if th == nil {
 		return nil
@@ -414,8 +800,15 @@ func applyStatusUpdate(todo *models.Todo, status *models.TodoStatus) error {
 	if err := validation.ValidateTodoStatus(string(*status)); err != nil {
 		return err
 	}
-	todo.Status = *status
-	return nil
+	next := *status
+	if !next.ClientSettable() {
+		return fmt.Errorf("%w: %s", models.ErrSystemTodoStatus, next)
+	}
+	// A completed todo was already analyzed, so reopening it returns it to processed rather than pending
+	if next == models.TodoStatusPending && todo.Status == models.TodoStatusCompleted {
+		next = models.TodoStatusProcessed
+	}
+	return todo.TransitionTo(next, time.Now())
 }
 
 func applyDueDateUpdate(todo *models.Todo, dueDate *string) error {
@@ -423,18 +816,50 @@ func applyDueDateUpdate(todo *models.Todo, dueDate *string) error {
 		return nil
 	}
 	if *dueDate == "" {
-		todo.DueDate = nil
+		todo.SetDueDate(nil, false)
 		return nil
 	}
-	parsed, err := time.Parse(time.RFC3339, *dueDate)
+	parsed, dateOnly, err := models.ParseDueDate(*dueDate)
 	if err != nil {
-		return fmt.Errorf("invalid due_date format, expected RFC3339 (e.g. 2024-03-15T14:30:00Z): %w", err)
+		return fmt.Errorf("invalid due_date format: %w", err)
+	}
+	todo.SetDueDate(&parsed, dateOnly)
+	return nil
+}
+
+// applyPriorityUpdate sets the todo's priority; an empty string clears it
+func applyPriorityUpdate(todo *models.Todo, priority *string) error {
+	if priority == nil {
+		return nil
+	}
+	if *priority == "" {
+		todo.Priority = ""
+		return nil
+	}
+	if err := validation.ValidateTodoPriority(*priority); err != nil {
+		return err
+	}
+	todo.Priority = models.Priority(*priority)
+	return nil
+}
+
+func applyReminderPolicyUpdate(todo *models.Todo, policy *models.ReminderPolicy) error {
+	if policy == nil {
+		return nil
 	}
-	todo.DueDate = &parsed
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if *policy == (models.ReminderPolicy{}) {
+		todo.Metadata.ReminderPolicy = nil
+		return nil
+	}
+	todo.Metadata.ReminderPolicy = policy
 	return nil
 }
 
-// UpdateTodo updates an existing todo
+// UpdateTodo updates an existing todo. With If-Match, the update only applies to the version named by the
+// ETag, answering 412 when another write got there first.
 func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 	user := request.UserFromContext(r)
 	if user == nil {
@@ -453,27 +878,79 @@ func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 		respondJSONError(w, http.StatusNotFound, "Not Found", "Todo not found")
 		return
 	}
-	oldTags := todo.Metadata.CategoryTags
-	req, err := parseAndValidateUpdateRequest(r)
-	if err != nil {
-		if maxBytesErr, ok := err.(*http.MaxBytesError); ok {
-			respondJSONError(w, http.StatusRequestEntityTooLarge, "Request Entity Too Large", fmt.Sprintf("Request body exceeds maximum size of %d bytes", maxBytesErr.Limit))
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if !ifMatchesTodo(ifMatch, todo) {
+			respondJSONError(w, http.StatusPreconditionFailed, "Precondition Failed", "Todo was modified since it was read")
 			return
 		}
-		respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid request body")
+		// The repository re-checks the version under its row lock, catching a write between this read and the update
+		ctx = database.WithExpectedUpdatedAt(ctx, todo.UpdatedAt)
+	}
+	oldTags := todo.Metadata.CategoryTags
+	oldReminderKey := todo.ReminderKey()
+	oldText := todo.Text
+	var req UpdateTodoRequest
+	if err := decodeJSONBody(r, &req, h.strictJSON); err != nil {
+		respondBodyDecodeError(w, err)
 		return
 	}
 	if err := applyUpdatesToTodo(todo, &req); err != nil {
+		if errors.Is(err, models.ErrInvalidStatusTransition) {
+			respondJSONError(w, http.StatusConflict, "Conflict", err.Error())
+			return
+		}
 		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
 		return
 	}
-	if err := h.todoRepo.Update(ctx, todo, oldTags); err != nil {
+	if err := h.todoRepo.Update(database.WithChangeActor(ctx, models.ChangeActorUser), todo, oldTags); err != nil {
+		if errors.Is(err, database.ErrConcurrentModification) {
+			respondJSONError(w, http.StatusPreconditionFailed, "Precondition Failed", "Todo was modified since it was read")
+			return
+		}
 		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to update todo")
 		return
 	}
+	if todo.ReminderKey() != oldReminderKey {
+		h.scheduleReminderChain(ctx, todo)
+	}
+	h.enqueueReanalysisOnTextChange(ctx, todo, oldText)
+	setTodoETag(w, todo)
 	respondJSON(w, http.StatusOK, todo)
 }
 
+// enqueueReanalysisOnTextChange enqueues a task analysis when an update materially changed the todo's text,
+// so tags and time horizon follow the new wording. Whitespace-only edits and todos opted out of analysis are skipped.
+func (h *TodoHandler) enqueueReanalysisOnTextChange(ctx context.Context, todo *models.Todo, oldText string) {
+	if !h.reanalyzeOnTextChange || h.jobQueue == nil || todo.Metadata.AnalysisDisabled {
+		return
+	}
+	if !textMateriallyChanged(oldText, todo.Text) {
+		return
+	}
+	job := queue.NewJob(queue.JobTypeTaskAnalysis, todo.UserID, &todo.ID)
+	if !h.admitAnalysisJob(ctx, job, "update_todo") {
+		return
+	}
+	if err := h.jobQueue.Enqueue(ctx, job); err != nil {
+		h.logger.Warn("failed_to_enqueue_ai_analysis_job",
+			zap.String("operation", "update_todo"),
+			zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+			zap.String("user_id", logpkg.SanitizeUserID(todo.UserID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return
+	}
+	h.logger.Info("enqueued_ai_analysis_job_text_changed",
+		zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+		zap.String("user_id", logpkg.SanitizeUserID(todo.UserID.String())),
+	)
+}
+
+// textMateriallyChanged reports whether two texts differ after collapsing whitespace
+func textMateriallyChanged(oldText, newText string) bool {
+	return strings.Join(strings.Fields(oldText), " ") != strings.Join(strings.Fields(newText), " ")
+}
+
 // DeleteTodo deletes a todo
 func (h *TodoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 	user := request.UserFromContext(r)
@@ -528,11 +1005,12 @@ func (h *TodoHandler) CompleteTodo(w http.ResponseWriter, r *http.Request) {
 	oldTags := todo.Metadata.CategoryTags
 
 	// Mark as completed
-	now := time.Now()
-	todo.Status = models.TodoStatusCompleted
-	todo.CompletedAt = &now
+	if err := todo.TransitionTo(models.TodoStatusCompleted, time.Now()); err != nil {
+		respondJSONError(w, http.StatusConflict, "Conflict", err.Error())
+		return
+	}
 
-	if err := h.todoRepo.Update(ctx, todo, oldTags); err != nil {
+	if err := h.todoRepo.Update(database.WithChangeActor(ctx, models.ChangeActorUser), todo, oldTags); err != nil {
 		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to complete todo")
 		return
 	}
@@ -540,7 +1018,8 @@ func (h *TodoHandler) CompleteTodo(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, todo)
 }
 
-// AnalyzeTodo manually triggers AI analysis for a todo
+// AnalyzeTodo manually triggers AI analysis for a todo. The optional use_tag_stats=false query parameter
+// runs the analysis without the user's tag statistics guidance.
 func (h *TodoHandler) AnalyzeTodo(w http.ResponseWriter, r *http.Request) {
 	user := request.UserFromContext(r)
 	if user == nil {
@@ -554,6 +1033,11 @@ func (h *TodoHandler) AnalyzeTodo(w http.ResponseWriter, r *http.Request) {
 		respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid todo ID")
 		return
 	}
+	useTagStats, err := parseUseTagStats(r)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
 
 	ctx := r.Context()
 	todo, err := h.todoRepo.GetByUserIDAndID(ctx, user.ID, id)
@@ -564,7 +1048,14 @@ func (h *TodoHandler) AnalyzeTodo(w http.ResponseWriter, r *http.Request) {
 
 	// Enqueue AI analysis job if job queue is available
 	if h.jobQueue != nil {
-		job := queue.NewJob(queue.JobTypeTaskAnalysis, user.ID, &todo.ID)
+		job := newManualAnalysisJob(user.ID, todo.ID, useTagStats)
+		if !h.admitAnalysisJob(ctx, job, "analyze_todo") {
+			respondUnavailable(w, "Analysis queue is busy; try again later", DefaultUnavailableRetryAfter)
+			return
+		}
+		if !h.reserveManualAnalysis(w, r, user.ID) {
+			return
+		}
 		if err := h.jobQueue.Enqueue(ctx, job); err != nil {
 			h.logger.Error("failed_to_enqueue_ai_analysis_job_manual",
 				zap.String("operation", "analyze_todo"),
@@ -592,7 +1083,136 @@ func (h *TodoHandler) AnalyzeTodo(w http.ResponseWriter, r *http.Request) {
 		zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
 		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
 	)
-	respondJSONError(w, http.StatusServiceUnavailable, "Service Unavailable", "AI analysis is not available")
+	respondUnavailable(w, "AI analysis is not available", DefaultUnavailableRetryAfter)
+}
+
+// parseUseTagStats reads the optional use_tag_stats query parameter, which defaults to true
+func parseUseTagStats(r *http.Request) (bool, error) {
+	raw := r.URL.Query().Get("use_tag_stats")
+	if raw == "" {
+		return true, nil
+	}
+	use, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("use_tag_stats must be true or false")
+	}
+	return use, nil
+}
+
+// newManualAnalysisJob builds a user-requested task analysis job
+func newManualAnalysisJob(userID, todoID uuid.UUID, useTagStats bool) *queue.Job {
+	job := queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoID)
+	job.Metadata[queue.MetadataManualAnalysis] = true
+	if !useTagStats {
+		job.Metadata[queue.MetadataSkipTagStats] = true
+	}
+	return job
+}
+
+// reserveManualAnalysis claims the user's manual analysis slot, responding 429 with Retry-After when it is
+// taken. Limiter errors fail open so a Redis outage does not block analysis.
+func (h *TodoHandler) reserveManualAnalysis(w http.ResponseWriter, r *http.Request, userID uuid.UUID) bool {
+	if h.manualAnalysisLimiter == nil {
+		return true
+	}
+	ok, next, err := h.manualAnalysisLimiter.Reserve(r.Context(), userID)
+	if err != nil {
+		h.logger.Warn("manual_analysis_limiter_failed",
+			zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return true
+	}
+	if ok {
+		return true
+	}
+	retryAfter := int(math.Ceil(time.Until(next).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	respondJSONError(w, http.StatusTooManyRequests, "Too Many Requests",
+		fmt.Sprintf("Manual analysis was requested too recently; retry in %d seconds", retryAfter))
+	return false
+}
+
+// DuplicateTodo copies one of the user's todos into a new pending todo and enqueues its analysis.
+// An optional ?shift= duration (e.g. 168h) moves the copied due date.
+func (h *TodoHandler) DuplicateTodo(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid todo ID")
+		return
+	}
+	var shift time.Duration
+	if v := r.URL.Query().Get("shift"); v != "" {
+		shift, err = time.ParseDuration(v)
+		if err != nil {
+			respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid shift duration (e.g. 168h)")
+			return
+		}
+	}
+
+	ctx := r.Context()
+	source, err := h.todoRepo.GetByUserIDAndID(ctx, user.ID, id)
+	if err != nil {
+		respondJSONError(w, http.StatusNotFound, "Not Found", "Todo not found")
+		return
+	}
+
+	todo := duplicateTodo(source, shift, time.Now())
+	if err := h.todoRepo.Create(ctx, todo); err != nil {
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to duplicate todo")
+		return
+	}
+	h.enqueueCreateTodoJob(ctx, user, todo)
+	h.scheduleReminderChain(ctx, todo)
+	respondJSON(w, http.StatusCreated, todo)
+}
+
+// duplicateTodo builds a new pending todo from source. Text, priority, due date (moved by shift) and
+// user-authored metadata are copied; all tags become user tags. Completion state and AI-assigned fields are not copied,
+// except a time horizon the user set explicitly.
+func duplicateTodo(source *models.Todo, shift time.Duration, now time.Time) *models.Todo {
+	timeEntered := now.Format(time.RFC3339)
+	todo := &models.Todo{
+		ID:          uuid.New(),
+		UserID:      source.UserID,
+		Text:        source.Text,
+		TimeHorizon: models.TimeHorizonSoon,
+		Status:      models.TodoStatusPending,
+		Priority:    source.Priority,
+		Metadata: models.Metadata{
+			TagSources:       make(map[string]models.TagSource),
+			Context:          append([]string(nil), source.Metadata.Context...),
+			Duration:         source.Metadata.Duration,
+			TimeEntered:      &timeEntered,
+			AnalysisDisabled: source.Metadata.AnalysisDisabled,
+		},
+	}
+	if len(source.Metadata.CategoryTags) > 0 {
+		todo.Metadata.SetUserTags(append([]string(nil), source.Metadata.CategoryTags...))
+	}
+	if source.Metadata.TimeHorizonUserOverride != nil && *source.Metadata.TimeHorizonUserOverride {
+		override := true
+		todo.TimeHorizon = source.TimeHorizon
+		todo.Metadata.TimeHorizonUserOverride = &override
+	}
+	if source.Metadata.ReminderPolicy != nil {
+		policy := *source.Metadata.ReminderPolicy
+		todo.Metadata.ReminderPolicy = &policy
+	}
+	if source.DueDate != nil {
+		due := source.DueDate.Add(shift)
+		todo.SetDueDate(&due, source.Metadata.DueDateOnly)
+	}
+	return todo
 }
 
 // TagStatsResponse represents the response for tag statistics
@@ -626,6 +1246,14 @@ func (h *TodoHandler) GetTagStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	etag := tagStatsETag(stats)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(TagStatsCacheMaxAge.Seconds())))
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	response := TagStatsResponse{
 		TagStats:       stats.TagStats,
 		Tainted:        stats.Tainted,
@@ -634,3 +1262,172 @@ func (h *TodoHandler) GetTagStats(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, response)
 }
+
+// tagStatsETag derives a weak ETag from the analysis version and tainted flag. UpdateStatistics bumps the
+// version and MarkTainted flips the flag, so the tag changes whenever the statistics do.
+func tagStatsETag(stats *models.TagStatistics) string {
+	return fmt.Sprintf(`W/"tag-stats-%d-%t"`, stats.AnalysisVersion, stats.Tainted)
+}
+
+// RelatedTagsResponse represents the response for related tags
+type RelatedTagsResponse struct {
+	Tag     string              `json:"tag"`
+	Related []models.RelatedTag `json:"related"`
+	Tainted bool                `json:"tainted"`
+}
+
+// GetRelatedTags returns the tags that most often appear together with ?tag= for the authenticated user
+func (h *TodoHandler) GetRelatedTags(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+
+	if h.tagStatsRepo == nil {
+		respondJSONError(w, http.StatusServiceUnavailable, "Service Unavailable", "Tag statistics are not available")
+		return
+	}
+
+	tag := r.URL.Query().Get("tag")
+	if tag == "" {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "tag query parameter is required")
+		return
+	}
+	limit := DefaultRelatedTagsLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxRelatedTagsLimit {
+			respondJSONError(w, http.StatusBadRequest, "Bad Request", fmt.Sprintf("limit must be between 1 and %d", MaxRelatedTagsLimit))
+			return
+		}
+		limit = n
+	}
+
+	stats, err := h.tagStatsRepo.GetByUserIDOrCreate(r.Context(), user.ID)
+	if err != nil {
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to retrieve tag statistics")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, RelatedTagsResponse{
+		Tag:     tag,
+		Related: stats.RelatedTags(tag, limit),
+		Tainted: stats.Tainted,
+	})
+}
+
+// ResetAITagsResponse reports the outcome of clearing AI-generated tags
+type ResetAITagsResponse struct {
+	TodosUpdated       int  `json:"todos_updated"`
+	ReanalysisEnqueued bool `json:"reanalysis_enqueued"`
+}
+
+// ResetAITags removes AI-generated tags from all of the user's todos, keeping user tags, and enqueues
+// reprocessing unless ?reanalyze=false is given
+func (h *TodoHandler) ResetAITags(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+
+	reanalyze, err := parseReanalyze(r)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	requeue := reanalyze && h.jobQueue != nil
+
+	ctx := r.Context()
+	updated, err := h.todoRepo.ResetAITags(ctx, user.ID, requeue)
+	if err != nil {
+		h.logger.Error("failed_to_reset_ai_tags",
+			zap.String("operation", "reset_ai_tags"),
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to reset AI tags")
+		return
+	}
+
+	resp := ResetAITagsResponse{TodosUpdated: updated}
+	if requeue {
+		job := queue.NewJob(queue.JobTypeReprocessUser, user.ID, nil)
+		if err := h.jobQueue.Enqueue(ctx, job); err != nil {
+			h.logger.Error("failed_to_enqueue_reprocess_after_ai_tag_reset",
+				zap.String("operation", "reset_ai_tags"),
+				zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+				zap.String("error", logpkg.SanitizeError(err)),
+			)
+		} else {
+			resp.ReanalysisEnqueued = true
+		}
+	}
+
+	h.logger.Info("reset_ai_tags",
+		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+		zap.Int("todos_updated", updated),
+		zap.Bool("reanalysis_enqueued", resp.ReanalysisEnqueued),
+	)
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// parseReanalyze parses the reanalyze query parameter of the reset endpoints; it defaults to true
+func parseReanalyze(r *http.Request) (bool, error) {
+	v := r.URL.Query().Get("reanalyze")
+	if v == "" {
+		return true, nil
+	}
+	reanalyze, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid reanalyze value %q (expected true or false)", v)
+	}
+	return reanalyze, nil
+}
+
+// TodoHistoryResponse lists a todo's recorded field-level changes, oldest first
+type TodoHistoryResponse struct {
+	TodoID  uuid.UUID                  `json:"todo_id"`
+	Entries []*models.TodoHistoryEntry `json:"entries"`
+}
+
+// GetTodoHistory returns the field-level change history of a todo owned by the authenticated user
+func (h *TodoHandler) GetTodoHistory(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+
+	if h.historyRepo == nil {
+		respondJSONError(w, http.StatusServiceUnavailable, "Service Unavailable", "Todo history is not available")
+		return
+	}
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid todo ID")
+		return
+	}
+
+	ctx := r.Context()
+	if _, err := h.todoRepo.GetByUserIDAndID(ctx, user.ID, id); err != nil {
+		respondJSONError(w, http.StatusNotFound, "Not Found", "Todo not found")
+		return
+	}
+
+	entries, err := h.historyRepo.ListByTodo(ctx, user.ID, id)
+	if err != nil {
+		h.logger.Error("failed_to_get_todo_history",
+			zap.String("operation", "get_todo_history"),
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("todo_id", logpkg.SanitizeUserID(id.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to retrieve todo history")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, TodoHistoryResponse{TodoID: id, Entries: entries})
+}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/database"
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/benvon/smart-todo/internal/validation"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// BulkDueDateOperationSet sets the due date of every selected todo
+	BulkDueDateOperationSet = "set"
+	// BulkDueDateOperationShift moves the due date of every selected todo that has one
+	BulkDueDateOperationShift = "shift"
+	// MaxBulkTodoIDs is the maximum number of explicit IDs accepted by a bulk request
+	MaxBulkTodoIDs = MaxPageSize
+
+	// BulkTodoActionComplete marks every listed todo completed
+	BulkTodoActionComplete = "complete"
+	// BulkTodoActionDelete deletes every listed todo
+	BulkTodoActionDelete = "delete"
+	// MaxBulkActionIDs is the maximum number of IDs accepted by a bulk complete or delete request
+	MaxBulkActionIDs = 200
+
+	// BulkTodoResultSucceeded reports that the action was applied to the todo
+	BulkTodoResultSucceeded = "succeeded"
+	// BulkTodoResultNotFound reports that the todo does not exist or is not owned by the user
+	BulkTodoResultNotFound = "not_found"
+)
+
+// BulkTodoFilter selects todos by field for a bulk operation. At least one field must be set.
+type BulkTodoFilter struct {
+	TimeHorizon *string `json:"time_horizon,omitempty"`
+	Status      *string `json:"status,omitempty"`
+}
+
+// BulkDueDateRequest changes the due dates of several todos at once. Exactly one of IDs or Filter selects
+// the todos. Operation "set" applies DueDate (RFC3339 or a date; empty string clears); "shift" adds the Shift
+// duration (e.g. "24h") to todos that have a due date.
+type BulkDueDateRequest struct {
+	IDs       []string        `json:"ids,omitempty"`
+	Filter    *BulkTodoFilter `json:"filter,omitempty"`
+	Operation string          `json:"operation"`
+	DueDate   *string         `json:"due_date,omitempty"`
+	Shift     string          `json:"shift,omitempty"`
+}
+
+// BulkDueDateResponse reports how many todos had their due date changed
+type BulkDueDateResponse struct {
+	TodosUpdated int `json:"todos_updated"`
+}
+
+// BulkSetDueDates sets or shifts the due dates of the selected todos in one transaction. Todos selected by a
+// filter without a status are skipped once completed, and todos already at the requested due date are not
+// counted. Reminder chains are rescheduled for the todos that changed.
+func (h *TodoHandler) BulkSetDueDates(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+
+	var req BulkDueDateRequest
+	if err := decodeJSONBody(r, &req, h.strictJSON); err != nil {
+		respondBodyDecodeError(w, err)
+		return
+	}
+	sel, err := parseBulkSelection(&req)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	apply, err := bulkDueDateApplier(&req)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	if len(sel.IDs) == 0 && sel.Filter.Status == nil {
+		apply = skipCompleted(apply)
+	}
+
+	ctx := database.WithChangeActor(r.Context(), models.ChangeActorUser)
+	updated, err := h.todoRepo.BulkUpdateDueDates(ctx, user.ID, sel, apply)
+	if err != nil {
+		h.logger.Error("failed_to_bulk_update_due_dates",
+			zap.String("operation", "bulk_set_due_dates"),
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to update due dates")
+		return
+	}
+	for _, todo := range updated {
+		h.scheduleReminderChain(ctx, todo)
+	}
+
+	h.logger.Info("bulk_updated_due_dates",
+		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+		zap.String("bulk_operation", req.Operation),
+		zap.Int("todos_updated", len(updated)),
+	)
+	respondJSON(w, http.StatusOK, BulkDueDateResponse{TodosUpdated: len(updated)})
+}
+
+// BulkTodoActionRequest applies one action to several todos at once
+type BulkTodoActionRequest struct {
+	Action string   `json:"action"`
+	IDs    []string `json:"ids"`
+}
+
+// BulkTodoActionResult is the outcome of a bulk action for one requested todo
+type BulkTodoActionResult struct {
+	ID     string `json:"id"`
+	Result string `json:"result"`
+}
+
+// BulkTodoActionResponse reports the outcome for each requested todo, in request order
+type BulkTodoActionResponse struct {
+	Action    string                 `json:"action"`
+	Succeeded int                    `json:"succeeded"`
+	Results   []BulkTodoActionResult `json:"results"`
+}
+
+// BulkTodoAction completes or deletes the listed todos in one transaction. IDs that do not exist or belong to
+// another user are reported as not found rather than failing the request; repeated IDs are reported once.
+func (h *TodoHandler) BulkTodoAction(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+
+	var req BulkTodoActionRequest
+	if err := decodeJSONBody(r, &req, h.strictJSON); err != nil {
+		respondBodyDecodeError(w, err)
+		return
+	}
+	if req.Action != BulkTodoActionComplete && req.Action != BulkTodoActionDelete {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request",
+			fmt.Sprintf("action must be %q or %q", BulkTodoActionComplete, BulkTodoActionDelete))
+		return
+	}
+	ids, err := parseBulkActionIDs(req.IDs)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+
+	ctx := database.WithChangeActor(r.Context(), models.ChangeActorUser)
+	var applied []uuid.UUID
+	if req.Action == BulkTodoActionComplete {
+		applied, err = h.todoRepo.BulkUpdateStatus(ctx, user.ID, ids, models.TodoStatusCompleted)
+	} else {
+		applied, err = h.todoRepo.BulkDelete(ctx, user.ID, ids)
+	}
+	if err != nil {
+		h.logger.Error("failed_to_apply_bulk_todo_action",
+			zap.String("operation", "bulk_todo_action"),
+			zap.String("bulk_action", req.Action),
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to apply bulk action")
+		return
+	}
+
+	succeeded := make(map[uuid.UUID]bool, len(applied))
+	for _, id := range applied {
+		succeeded[id] = true
+	}
+	resp := BulkTodoActionResponse{Action: req.Action, Results: make([]BulkTodoActionResult, 0, len(ids))}
+	for _, id := range ids {
+		result := BulkTodoResultNotFound
+		if succeeded[id] {
+			result = BulkTodoResultSucceeded
+			resp.Succeeded++
+		}
+		resp.Results = append(resp.Results, BulkTodoActionResult{ID: id.String(), Result: result})
+	}
+
+	h.logger.Info("applied_bulk_todo_action",
+		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+		zap.String("bulk_action", req.Action),
+		zap.Int("todos_requested", len(ids)),
+		zap.Int("todos_succeeded", resp.Succeeded),
+	)
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// parseBulkActionIDs validates and de-duplicates the IDs of a bulk action, keeping their order
+func parseBulkActionIDs(raw []string) ([]uuid.UUID, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("ids is required")
+	}
+	if len(raw) > MaxBulkActionIDs {
+		return nil, fmt.Errorf("at most %d ids may be given", MaxBulkActionIDs)
+	}
+	sel, err := parseBulkIDs(raw)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[uuid.UUID]bool, len(sel.IDs))
+	ids := make([]uuid.UUID, 0, len(sel.IDs))
+	for _, id := range sel.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// parseBulkSelection validates the request's IDs or filter into a repository selection
+func parseBulkSelection(req *BulkDueDateRequest) (database.TodoBulkSelection, error) {
+	var sel database.TodoBulkSelection
+	switch {
+	case len(req.IDs) > 0 && req.Filter != nil:
+		return sel, fmt.Errorf("provide either ids or filter, not both")
+	case len(req.IDs) > 0:
+		return parseBulkIDs(req.IDs)
+	case req.Filter != nil:
+		filter, err := parseBulkFilter(req.Filter)
+		sel.Filter = filter
+		return sel, err
+	default:
+		return sel, fmt.Errorf("ids or filter is required")
+	}
+}
+
+func parseBulkIDs(raw []string) (database.TodoBulkSelection, error) {
+	var sel database.TodoBulkSelection
+	if len(raw) > MaxBulkTodoIDs {
+		return sel, fmt.Errorf("at most %d ids may be given", MaxBulkTodoIDs)
+	}
+	sel.IDs = make([]uuid.UUID, 0, len(raw))
+	for _, s := range raw {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return sel, fmt.Errorf("invalid todo ID %q", s)
+		}
+		sel.IDs = append(sel.IDs, id)
+	}
+	return sel, nil
+}
+
+func parseBulkFilter(f *BulkTodoFilter) (database.TodoListFilter, error) {
+	var filter database.TodoListFilter
+	if f.TimeHorizon == nil && f.Status == nil {
+		return filter, fmt.Errorf("filter must set time_horizon or status")
+	}
+	if f.TimeHorizon != nil {
+		if err := validation.ValidateTimeHorizon(*f.TimeHorizon); err != nil {
+			return filter, err
+		}
+		th := models.TimeHorizon(*f.TimeHorizon)
+		filter.TimeHorizon = &th
+	}
+	if f.Status != nil {
+		if err := validation.ValidateTodoStatus(*f.Status); err != nil {
+			return filter, err
+		}
+		st := models.TodoStatus(*f.Status)
+		filter.Status = &st
+	}
+	return filter, nil
+}
+
+// bulkDueDateApplier returns the per-todo change for the request's operation, reporting whether the todo changed
+func bulkDueDateApplier(req *BulkDueDateRequest) (func(todo *models.Todo) bool, error) {
+	switch req.Operation {
+	case BulkDueDateOperationSet:
+		if req.DueDate == nil {
+			return nil, fmt.Errorf("due_date is required for the set operation")
+		}
+		return setDueDateApplier(*req.DueDate)
+	case BulkDueDateOperationShift:
+		shift, err := time.ParseDuration(req.Shift)
+		if err != nil || shift == 0 {
+			return nil, fmt.Errorf("shift must be a non-zero duration (e.g. 24h, -168h) for the shift operation")
+		}
+		return func(todo *models.Todo) bool {
+			if todo.DueDate == nil {
+				return false
+			}
+			due := todo.DueDate.Add(shift)
+			todo.SetDueDate(&due, todo.Metadata.DueDateOnly)
+			return true
+		}, nil
+	default:
+		return nil, fmt.Errorf("operation must be %q or %q", BulkDueDateOperationSet, BulkDueDateOperationShift)
+	}
+}
+
+func setDueDateApplier(raw string) (func(todo *models.Todo) bool, error) {
+	var due *time.Time
+	dateOnly := false
+	if raw != "" {
+		parsed, only, err := models.ParseDueDate(raw)
+		if err != nil {
+			return nil, err
+		}
+		due, dateOnly = &parsed, only
+	}
+	return func(todo *models.Todo) bool {
+		if sameDueDate(todo.DueDate, due) && todo.Metadata.DueDateOnly == dateOnly {
+			return false
+		}
+		todo.SetDueDate(due, dateOnly)
+		return true
+	}, nil
+}
+
+func sameDueDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// skipCompleted wraps apply so completed todos are left alone
+func skipCompleted(apply func(todo *models.Todo) bool) func(todo *models.Todo) bool {
+	return func(todo *models.Todo) bool {
+		if todo.Status == models.TodoStatusCompleted {
+			return false
+		}
+		return apply(todo)
+	}
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func TestTodoHandler_BulkSetDueDates(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	base := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	tomorrow := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	// newTodos returns a fresh set of todos for each case:
+	// 0: next, pending, due base   1: next, processed, no due date
+	// 2: next, completed, due base 3: soon, pending, due base     4: another user's next todo
+	newTodos := func() []*models.Todo {
+		mk := func(owner uuid.UUID, th models.TimeHorizon, st models.TodoStatus, due *time.Time) *models.Todo {
+			todo := &models.Todo{ID: uuid.New(), UserID: owner, Text: "todo", TimeHorizon: th, Status: st}
+			if due != nil {
+				d := *due
+				todo.SetDueDate(&d, false)
+			}
+			return todo
+		}
+		return []*models.Todo{
+			mk(userID, models.TimeHorizonNext, models.TodoStatusPending, &base),
+			mk(userID, models.TimeHorizonNext, models.TodoStatusProcessed, nil),
+			mk(userID, models.TimeHorizonNext, models.TodoStatusCompleted, &base),
+			mk(userID, models.TimeHorizonSoon, models.TodoStatusPending, &base),
+			mk(uuid.New(), models.TimeHorizonNext, models.TodoStatusPending, &base),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		body        func(todos []*models.Todo) string
+		wantStatus  int
+		wantUpdated int
+		// wantDue maps todo index to its expected due date after the request (nil = no due date)
+		wantDue map[int]*time.Time
+	}{
+		{
+			name: "set by ids",
+			body: func(todos []*models.Todo) string {
+				return `{"ids":["` + todos[0].ID.String() + `","` + todos[1].ID.String() + `"],"operation":"set","due_date":"2026-10-17"}`
+			},
+			wantStatus:  http.StatusOK,
+			wantUpdated: 2,
+			wantDue:     map[int]*time.Time{0: &tomorrow, 1: &tomorrow, 3: &base},
+		},
+		{
+			name: "set by filter skips completed and other horizons",
+			body: func(todos []*models.Todo) string {
+				return `{"filter":{"time_horizon":"next"},"operation":"set","due_date":"2026-10-17"}`
+			},
+			wantStatus:  http.StatusOK,
+			wantUpdated: 2,
+			wantDue:     map[int]*time.Time{0: &tomorrow, 1: &tomorrow, 2: &base, 3: &base, 4: &base},
+		},
+		{
+			name: "shift by filter only moves todos with a due date",
+			body: func(todos []*models.Todo) string {
+				return `{"filter":{"time_horizon":"next"},"operation":"shift","shift":"24h"}`
+			},
+			wantStatus:  http.StatusOK,
+			wantUpdated: 1,
+			wantDue:     map[int]*time.Time{0: timePtr(base.Add(24 * time.Hour)), 1: nil, 2: &base},
+		},
+		{
+			name: "shift by ids includes completed todos",
+			body: func(todos []*models.Todo) string {
+				return `{"ids":["` + todos[2].ID.String() + `","` + todos[3].ID.String() + `"],"operation":"shift","shift":"-48h"}`
+			},
+			wantStatus:  http.StatusOK,
+			wantUpdated: 2,
+			wantDue:     map[int]*time.Time{2: timePtr(base.Add(-48 * time.Hour)), 3: timePtr(base.Add(-48 * time.Hour))},
+		},
+		{
+			name: "set to the current due date is not counted",
+			body: func(todos []*models.Todo) string {
+				return `{"ids":["` + todos[0].ID.String() + `"],"operation":"set","due_date":"2026-10-16T09:00:00Z"}`
+			},
+			wantStatus:  http.StatusOK,
+			wantUpdated: 0,
+		},
+		{
+			name: "ids and filter together",
+			body: func(todos []*models.Todo) string {
+				return `{"ids":["` + todos[0].ID.String() + `"],"filter":{"status":"pending"},"operation":"set","due_date":""}`
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "empty filter",
+			body:       func(todos []*models.Todo) string { return `{"filter":{},"operation":"set","due_date":""}` },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown operation",
+			body:       func(todos []*models.Todo) string { return `{"filter":{"time_horizon":"next"},"operation":"clear"}` },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "zero shift",
+			body: func(todos []*models.Todo) string {
+				return `{"filter":{"time_horizon":"next"},"operation":"shift","shift":"0s"}`
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			todos := newTodos()
+			todoRepo := &mockTodoRepoForHandlers{t: t, bulkTodos: todos}
+			handler := NewTodoHandler(todoRepo, zap.NewNop())
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/bulk/due-date", strings.NewReader(tt.body(todos)))
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if len(todoRepo.bulkSelections) != 0 {
+					t.Error("expected no repository call for an invalid request")
+				}
+				return
+			}
+
+			var resp struct {
+				Data BulkDueDateResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Data.TodosUpdated != tt.wantUpdated {
+				t.Errorf("todos_updated = %d, want %d", resp.Data.TodosUpdated, tt.wantUpdated)
+			}
+			for i, want := range tt.wantDue {
+				got := todos[i].DueDate
+				if (got == nil) != (want == nil) || (got != nil && !got.Equal(*want)) {
+					t.Errorf("todo %d due date = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoHandler_BulkTodoAction(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	// newTodos returns a fresh set of todos for each case:
+	// 0: pending   1: completed   2: another user's pending todo
+	newTodos := func() []*models.Todo {
+		return []*models.Todo{
+			{ID: uuid.New(), UserID: userID, Text: "todo", Status: models.TodoStatusPending},
+			{ID: uuid.New(), UserID: userID, Text: "todo", Status: models.TodoStatusCompleted},
+			{ID: uuid.New(), UserID: uuid.New(), Text: "todo", Status: models.TodoStatusPending},
+		}
+	}
+	type bulkResult struct {
+		todo   int
+		result string
+	}
+	missing := uuid.New()
+	tooMany := make([]string, MaxBulkActionIDs+1)
+	for i := range tooMany {
+		tooMany[i] = `"` + uuid.New().String() + `"`
+	}
+
+	tests := []struct {
+		name       string
+		body       func(todos []*models.Todo) string
+		wantStatus int
+		// wantResults lists the expected result per requested ID, by todo index (-1 = missing)
+		wantResults   []bulkResult
+		wantStatuses  map[int]models.TodoStatus
+		wantRemaining int
+	}{
+		{
+			name: "complete reports missing and foreign todos as not found",
+			body: func(todos []*models.Todo) string {
+				return `{"action":"complete","ids":["` + todos[0].ID.String() + `","` + todos[1].ID.String() + `","` +
+					todos[2].ID.String() + `","` + missing.String() + `","` + todos[0].ID.String() + `"]}`
+			},
+			wantStatus:    http.StatusOK,
+			wantResults:   []bulkResult{{0, BulkTodoResultSucceeded}, {1, BulkTodoResultSucceeded}, {2, BulkTodoResultNotFound}, {-1, BulkTodoResultNotFound}},
+			wantStatuses:  map[int]models.TodoStatus{0: models.TodoStatusCompleted, 1: models.TodoStatusCompleted, 2: models.TodoStatusPending},
+			wantRemaining: 3,
+		},
+		{
+			name: "delete removes only the user's todos",
+			body: func(todos []*models.Todo) string {
+				return `{"action":"delete","ids":["` + todos[1].ID.String() + `","` + todos[2].ID.String() + `"]}`
+			},
+			wantStatus:    http.StatusOK,
+			wantResults:   []bulkResult{{1, BulkTodoResultSucceeded}, {2, BulkTodoResultNotFound}},
+			wantRemaining: 2,
+		},
+		{
+			name: "unknown action",
+			body: func(todos []*models.Todo) string {
+				return `{"action":"archive","ids":["` + todos[0].ID.String() + `"]}`
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "no ids",
+			body:       func(todos []*models.Todo) string { return `{"action":"delete","ids":[]}` },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid id",
+			body:       func(todos []*models.Todo) string { return `{"action":"delete","ids":["not-a-uuid"]}` },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "too many ids",
+			body: func(todos []*models.Todo) string {
+				return `{"action":"complete","ids":[` + strings.Join(tooMany, ",") + `]}`
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			todos := newTodos()
+			todoRepo := &mockTodoRepoForHandlers{t: t, bulkTodos: append([]*models.Todo(nil), todos...)}
+			handler := NewTodoHandler(todoRepo, zap.NewNop())
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/bulk", strings.NewReader(tt.body(todos)))
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if len(todoRepo.bulkSelections) != 0 {
+					t.Error("expected no repository call for an invalid request")
+				}
+				return
+			}
+			if len(todoRepo.bulkSelections) != 1 {
+				t.Errorf("repository called %d times, want once", len(todoRepo.bulkSelections))
+			}
+
+			var resp struct {
+				Data BulkTodoActionResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if len(resp.Data.Results) != len(tt.wantResults) {
+				t.Fatalf("got %d results, want %d: %+v", len(resp.Data.Results), len(tt.wantResults), resp.Data.Results)
+			}
+			succeeded := 0
+			for i, want := range tt.wantResults {
+				wantID := missing.String()
+				if want.todo >= 0 {
+					wantID = todos[want.todo].ID.String()
+				}
+				got := resp.Data.Results[i]
+				if got.ID != wantID || got.Result != want.result {
+					t.Errorf("result %d = %+v, want {ID:%s Result:%s}", i, got, wantID, want.result)
+				}
+				if want.result == BulkTodoResultSucceeded {
+					succeeded++
+				}
+			}
+			if resp.Data.Succeeded != succeeded {
+				t.Errorf("succeeded = %d, want %d", resp.Data.Succeeded, succeeded)
+			}
+			if len(todoRepo.bulkTodos) != tt.wantRemaining {
+				t.Errorf("%d todos remain, want %d", len(todoRepo.bulkTodos), tt.wantRemaining)
+			}
+			for i, want := range tt.wantStatuses {
+				if todos[i].Status != want {
+					t.Errorf("todo %d status = %s, want %s", i, todos[i].Status, want)
+				}
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
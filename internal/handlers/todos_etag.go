@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/benvon/smart-todo/internal/models"
+)
+
+// todoETag returns the entity tag of todo's current version, derived from its updated_at
+func todoETag(todo *models.Todo) string {
+	return `"` + strconv.FormatInt(todo.UpdatedAt.UnixMicro(), 10) + `"`
+}
+
+// setTodoETag sets the ETag header for todo, which clients send back in If-Match to update it conditionally
+func setTodoETag(w http.ResponseWriter, todo *models.Todo) {
+	w.Header().Set("ETag", todoETag(todo))
+}
+
+// ifMatchesTodo reports whether an If-Match header value names todo's current version. "*" matches any
+// version; weak tags never match, as If-Match uses strong comparison.
+func ifMatchesTodo(ifMatch string, todo *models.Todo) bool {
+	etag := todoETag(todo)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		if tag = strings.TrimSpace(tag); tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/database"
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// TodoExportPath is the route of the todo export, relative to /api/v1. The server exempts it from response
+	// buffering by the request timeout middleware so the export can stream.
+	TodoExportPath = "/todos/export"
+	// TodoExportPageSize is how many todos the export reads per query
+	TodoExportPageSize = 200
+)
+
+// TodoExport is the document written by ExportTodos
+type TodoExport struct {
+	ExportedAt    time.Time                  `json:"exported_at"`
+	UserID        uuid.UUID                  `json:"user_id"`
+	TagStatistics map[string]models.TagStats `json:"tag_statistics"`
+	Todos         []*models.Todo             `json:"todos"`
+}
+
+// ExportTodos downloads all of the authenticated user's todos, including archived and trashed ones, with their
+// metadata and the user's tag statistics, as a TodoExport. Todos are read newest first a page at a time and
+// flushed as they are written, so memory stays bounded for large accounts. Once the body has started the
+// status can no longer change, so a failure or the request deadline ends the export early; the truncated
+// document then fails to parse rather than passing for a complete one.
+func (h *TodoHandler) ExportTodos(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	ctx := r.Context()
+	export := TodoExport{ExportedAt: time.Now().UTC(), UserID: user.ID, TagStatistics: map[string]models.TagStats{}, Todos: []*models.Todo{}}
+	if h.tagStatsRepo != nil {
+		stats, err := h.tagStatsRepo.GetByUserIDOrCreate(ctx, user.ID)
+		if err != nil {
+			h.logExportError("failed_to_export_tag_statistics", user.ID, 0, err)
+			respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to export todos")
+			return
+		}
+		if stats.TagStats != nil {
+			export.TagStatistics = stats.TagStats
+		}
+	}
+
+	filter := database.TodoListFilter{IncludeRetired: true}
+	page, err := h.todoRepo.ListByUserIDAfter(ctx, user.ID, filter, nil, TodoExportPageSize)
+	if err != nil {
+		h.logExportError("failed_to_export_todos", user.ID, 0, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to export todos")
+		return
+	}
+	// The document is written as the encoding of export with no todos, up to its empty todos array, then the
+	// todos one at a time, so the streamed fields always match TodoExport
+	head, err := json.Marshal(export)
+	if err != nil {
+		h.logExportError("failed_to_export_todos", user.ID, 0, err)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to export todos")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="smart-todo-export-%s.json"`, export.ExportedAt.Format(time.DateOnly)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(bytes.TrimSuffix(head, []byte("]}"))); err != nil {
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	exported := 0
+	for {
+		for _, todo := range page {
+			data, err := json.Marshal(todo)
+			if err != nil {
+				h.logExportError("failed_to_export_todos", user.ID, exported, err)
+				return
+			}
+			if exported > 0 {
+				data = append([]byte{','}, data...)
+			}
+			if _, err := w.Write(data); err != nil {
+				return
+			}
+			exported++
+		}
+		_ = rc.Flush()
+		if len(page) < TodoExportPageSize {
+			break
+		}
+		last := page[len(page)-1]
+		if page, err = h.todoRepo.ListByUserIDAfter(ctx, user.ID, filter, &database.TodoCursor{CreatedAt: last.CreatedAt, ID: last.ID}, TodoExportPageSize); err != nil {
+			h.logExportError("todo_export_interrupted", user.ID, exported, err)
+			return
+		}
+	}
+	if _, err := w.Write([]byte("]}\n")); err != nil {
+		return
+	}
+	h.logger.Info("todos_exported",
+		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+		zap.Int("todos", exported),
+	)
+}
+
+func (h *TodoHandler) logExportError(event string, userID uuid.UUID, exported int, err error) {
+	h.logger.Error(event,
+		zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+		zap.Int("todos_written", exported),
+		zap.String("error", logpkg.SanitizeError(err)),
+	)
+}
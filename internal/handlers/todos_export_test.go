@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/database"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestTodoHandler_ExportTodos(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: uuid.New()}
+	base := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	newTodos := func(n int) []*models.Todo {
+		todos := make([]*models.Todo, n)
+		for i := range todos {
+			todos[i] = &models.Todo{
+				ID: uuid.New(), UserID: user.ID, Text: "todo", Status: models.TodoStatusProcessed, Priority: models.PriorityHigh,
+				Metadata:  models.Metadata{CategoryTags: []string{"work"}, TagSources: map[string]models.TagSource{"work": models.TagSourceAI}},
+				CreatedAt: base.Add(-time.Duration(i) * time.Minute),
+			}
+		}
+		return todos
+	}
+
+	tests := []struct {
+		name string
+		// todos are listed newest first; failAfter > 0 fails the query resuming after that many todos
+		todos         []*models.Todo
+		failFirst     bool
+		failAfter     int
+		wantStatus    int
+		wantTodos     int
+		wantTruncated bool
+	}{
+		{name: "no todos", wantStatus: http.StatusOK},
+		{name: "one partial page", todos: newTodos(3), wantStatus: http.StatusOK, wantTodos: 3},
+		{name: "several pages", todos: newTodos(2*TodoExportPageSize + 1), wantStatus: http.StatusOK, wantTodos: 2*TodoExportPageSize + 1},
+		{name: "exactly one page", todos: newTodos(TodoExportPageSize), wantStatus: http.StatusOK, wantTodos: TodoExportPageSize},
+		{name: "first query fails", todos: newTodos(3), failFirst: true, wantStatus: http.StatusInternalServerError},
+		{name: "later query fails", todos: newTodos(TodoExportPageSize + 5), failAfter: TodoExportPageSize, wantStatus: http.StatusOK, wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var filters []database.TodoListFilter
+			repo := &mockTodoRepoForHandlers{
+				t: t,
+				listByUserIDAfterFunc: func(ctx context.Context, userID uuid.UUID, filter database.TodoListFilter, after *database.TodoCursor, limit int) ([]*models.Todo, error) {
+					filters = append(filters, filter)
+					start := 0
+					if after != nil {
+						for i, todo := range tt.todos {
+							if todo.ID == after.ID {
+								start = i + 1
+							}
+						}
+					}
+					if (tt.failFirst && after == nil) || (tt.failAfter > 0 && start == tt.failAfter) {
+						return nil, errors.New("db down")
+					}
+					return tt.todos[start:min(start+limit, len(tt.todos))], nil
+				},
+			}
+			tagStats := &mockTagStatisticsRepoForHandlers{
+				t: t,
+				getByUserIDOrCreateFunc: func(ctx context.Context, userID uuid.UUID) (*models.TagStatistics, error) {
+					return &models.TagStatistics{UserID: userID, TagStats: map[string]models.TagStats{"work": {Total: len(tt.todos), AI: len(tt.todos)}}}, nil
+				},
+			}
+			handler := NewTodoHandler(repo, zap.NewNop(), WithTodoTagStatsRepo(tagStats))
+
+			w := httptest.NewRecorder()
+			handler.ExportTodos(w, setUserInRequestContext(httptest.NewRequest("GET", "/api/v1/todos/export", nil), user))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if cd := w.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, `attachment; filename="smart-todo-export-`) {
+				t.Errorf("Content-Disposition = %q, want an attachment", cd)
+			}
+			for _, f := range filters {
+				if !f.IncludeRetired {
+					t.Errorf("filter = %+v, want retired todos included", f)
+				}
+			}
+
+			var export TodoExport
+			err := json.Unmarshal(w.Body.Bytes(), &export)
+			if tt.wantTruncated {
+				if err == nil {
+					t.Fatal("decoded a complete export after a failed query, want a truncated document")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decode export: %v (%s)", err, w.Body.String())
+			}
+			if export.UserID != user.ID || export.ExportedAt.IsZero() {
+				t.Errorf("export user = %v at %v, want %v with a timestamp", export.UserID, export.ExportedAt, user.ID)
+			}
+			if len(export.Todos) != tt.wantTodos {
+				t.Fatalf("exported %d todos, want %d", len(export.Todos), tt.wantTodos)
+			}
+			for i, todo := range export.Todos {
+				if todo.ID != tt.todos[i].ID || todo.Priority != models.PriorityHigh || todo.Metadata.TagSources["work"] != models.TagSourceAI {
+					t.Errorf("todo %d = %+v, want %+v", i, todo, tt.todos[i])
+				}
+			}
+			if export.TagStatistics["work"].Total != len(tt.todos) {
+				t.Errorf("tag statistics = %+v, want work used %d times", export.TagStatistics, len(tt.todos))
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/database"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func TestTodoHandler_ListTodos_ExternalRefLookup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantLookup bool
+	}{
+		{name: "finds the linked todo", query: "?external_system=github&external_id=benvon/smart-todo%2342", wantStatus: http.StatusOK, wantLookup: true},
+		{name: "system is case-insensitive", query: "?external_system=GitHub&external_id=benvon/smart-todo%2342", wantStatus: http.StatusOK, wantLookup: true},
+		{name: "no linked todo", query: "?external_system=jira&external_id=PROJ-1", wantStatus: http.StatusNotFound, wantLookup: true},
+		{name: "system without id", query: "?external_system=github", wantStatus: http.StatusBadRequest},
+		{name: "id without system", query: "?external_id=PROJ-1", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			linked := &models.Todo{
+				ID:     uuid.New(),
+				UserID: userID,
+				Text:   "Fix login bug",
+				Metadata: models.Metadata{ExternalRefs: []models.ExternalRef{
+					{System: "github", ID: "benvon/smart-todo#42", URL: "https://github.com/benvon/smart-todo/issues/42"},
+				}},
+			}
+			var lookups int
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				getByExternalRefFunc: func(ctx context.Context, gotUserID uuid.UUID, system, id string) (*models.Todo, error) {
+					lookups++
+					if gotUserID != userID {
+						t.Errorf("GetByExternalRef user = %s, want %s", gotUserID, userID)
+					}
+					for _, ref := range linked.Metadata.ExternalRefs {
+						if ref.System == system && ref.ID == id {
+							return linked, nil
+						}
+					}
+					return nil, database.ErrTodoNotFound
+				},
+			}
+			handler := NewTodoHandler(todoRepo, zap.NewNop())
+
+			req := httptest.NewRequest("GET", "/api/v1/todos"+tt.query, nil)
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			handler.ListTodos(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if (lookups == 1) != tt.wantLookup {
+				t.Errorf("GetByExternalRef called %d times, want lookup %v", lookups, tt.wantLookup)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Data models.Todo `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Data.ID != linked.ID || len(resp.Data.Metadata.ExternalRefs) != 1 {
+				t.Errorf("response = %+v, want the linked todo %s", resp.Data, linked.ID)
+			}
+		})
+	}
+}
+
+func TestTodoHandler_ExternalRefsOnCreateAndUpdate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantRefs   []models.ExternalRef
+	}{
+		{
+			name:       "create with refs",
+			method:     "POST",
+			body:       `{"text":"Fix login bug","external_refs":[{"system":" GitHub ","id":"benvon/smart-todo#42","url":"https://github.com/benvon/smart-todo/issues/42"}]}`,
+			wantStatus: http.StatusCreated,
+			wantRefs:   []models.ExternalRef{{System: "github", ID: "benvon/smart-todo#42", URL: "https://github.com/benvon/smart-todo/issues/42"}},
+		},
+		{
+			name:       "create with invalid url",
+			method:     "POST",
+			body:       `{"text":"Fix login bug","external_refs":[{"system":"github","id":"42","url":"javascript:alert(1)"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "update replaces refs",
+			method:     "PATCH",
+			body:       `{"external_refs":[{"system":"jira","id":"PROJ-7"}]}`,
+			wantStatus: http.StatusOK,
+			wantRefs:   []models.ExternalRef{{System: "jira", ID: "PROJ-7"}},
+		},
+		{
+			name:       "update with duplicate refs",
+			method:     "PATCH",
+			body:       `{"external_refs":[{"system":"jira","id":"PROJ-7"},{"system":"JIRA","id":"PROJ-7"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "update clears refs",
+			method:     "PATCH",
+			body:       `{"external_refs":[]}`,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todo := &models.Todo{
+				ID:       uuid.New(),
+				UserID:   userID,
+				Text:     "Fix login bug",
+				Status:   models.TodoStatusProcessed,
+				Metadata: models.Metadata{ExternalRefs: []models.ExternalRef{{System: "github", ID: "1"}}},
+			}
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				getByUserIDAndIDFunc: func(ctx context.Context, uid uuid.UUID, id uuid.UUID) (*models.Todo, error) {
+					return todo, nil
+				},
+			}
+			handler := NewTodoHandler(todoRepo, zap.NewNop())
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			path := "/api/v1/todos"
+			if tt.method == "PATCH" {
+				path = "/" + todo.ID.String()
+			}
+			req := httptest.NewRequest(tt.method, path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			if tt.method == "POST" {
+				handler.CreateTodo(w, req)
+			} else {
+				router.ServeHTTP(w, req)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			saved := append(todoRepo.createCalls, todoRepo.updateCalls...)
+			if tt.wantStatus != http.StatusCreated && tt.wantStatus != http.StatusOK {
+				if len(saved) != 0 {
+					t.Errorf("saved %d todos, want none", len(saved))
+				}
+				return
+			}
+			if len(saved) != 1 {
+				t.Fatalf("saved %d todos, want 1", len(saved))
+			}
+			got := saved[0].Metadata.ExternalRefs
+			if len(got) != len(tt.wantRefs) {
+				t.Fatalf("external_refs = %+v, want %+v", got, tt.wantRefs)
+			}
+			for i := range got {
+				if got[i] != tt.wantRefs[i] {
+					t.Errorf("external_refs[%d] = %+v, want %+v", i, got[i], tt.wantRefs[i])
+				}
+			}
+		})
+	}
+}
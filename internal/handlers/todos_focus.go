@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultFocusLimit is the number of todos returned by /focus when no limit is given
+	DefaultFocusLimit = 5
+	// MaxFocusLimit is the maximum number of todos returned by /focus
+	MaxFocusLimit = 50
+	// FocusCandidateLimit caps the open todos scored for /focus; soonest due are considered first
+	FocusCandidateLimit = 1000
+)
+
+// FocusTodosResponse is the shortlist of todos to do today
+type FocusTodosResponse struct {
+	// Date is the calendar day the shortlist is for (YYYY-MM-DD, in the requested timezone)
+	Date  string         `json:"date"`
+	Todos []*models.Todo `json:"todos"`
+}
+
+// WithTodoFocusWeights sets the scoring weights of /focus (default models.DefaultFocusWeights).
+func WithTodoFocusWeights(w models.FocusWeights) TodoHandlerOption {
+	return func(h *TodoHandler) { h.focusWeights = w }
+}
+
+// GetFocusTodos returns the open todos the user should do today, best first: overdue, then due today, then in
+// the next time horizon, then high priority, as weighted by the handler's focus weights. Todos matching none
+// of these are left out. "Today" is in the IANA timezone given by tz (default UTC).
+func (h *TodoHandler) GetFocusTodos(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	limit, err := parseFocusLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	loc, err := parseFocusTimezone(r.URL.Query().Get("tz"))
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+
+	todos, err := h.todoRepo.ListOpenByUserID(r.Context(), user.ID, FocusCandidateLimit)
+	if err != nil {
+		h.logger.Error("failed_to_list_focus_todos",
+			zap.String("operation", "focus_todos"),
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to retrieve todos")
+		return
+	}
+	now := time.Now().In(loc)
+	respondJSON(w, http.StatusOK, FocusTodosResponse{
+		Date:  now.Format(time.DateOnly),
+		Todos: models.RankFocus(todos, now, h.focusWeights, limit),
+	})
+}
+
+func parseFocusLimit(l string) (int, error) {
+	if l == "" {
+		return DefaultFocusLimit, nil
+	}
+	limit, err := strconv.Atoi(l)
+	if err != nil || limit < 1 || limit > MaxFocusLimit {
+		return 0, fmt.Errorf("limit must be an integer between 1 and %d", MaxFocusLimit)
+	}
+	return limit, nil
+}
+
+func parseFocusTimezone(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tz %q (expected an IANA timezone such as Europe/Berlin)", tz)
+	}
+	return loc, nil
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func TestTodoHandler_GetFocusTodos(t *testing.T) {
+	t.Parallel()
+
+	overdue := time.Now().Add(-48 * time.Hour)
+	openTodos := func(userID uuid.UUID) []*models.Todo {
+		return []*models.Todo{
+			{ID: uuid.New(), UserID: userID, Text: "high", TimeHorizon: models.TimeHorizonLater, Status: models.TodoStatusProcessed, Priority: models.PriorityHigh},
+			{ID: uuid.New(), UserID: userID, Text: "next", TimeHorizon: models.TimeHorizonNext, Status: models.TodoStatusProcessed},
+			{ID: uuid.New(), UserID: userID, Text: "someday", TimeHorizon: models.TimeHorizonLater, Status: models.TodoStatusProcessed},
+			{ID: uuid.New(), UserID: userID, Text: "overdue", TimeHorizon: models.TimeHorizonSoon, Status: models.TodoStatusPending, DueDate: &overdue},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		opts       []TodoHandlerOption
+		wantStatus int
+		wantTexts  []string
+	}{
+		{name: "default weights", wantStatus: http.StatusOK, wantTexts: []string{"overdue", "next", "high"}},
+		{name: "limit", query: "?limit=2", wantStatus: http.StatusOK, wantTexts: []string{"overdue", "next"}},
+		{
+			name:       "configured weights",
+			opts:       []TodoHandlerOption{WithTodoFocusWeights(models.FocusWeights{Overdue: 1, NextHorizon: 1, HighPriority: 5})},
+			wantStatus: http.StatusOK,
+			wantTexts:  []string{"high", "overdue", "next"},
+		},
+		{name: "timezone", query: "?tz=Asia/Tokyo", wantStatus: http.StatusOK, wantTexts: []string{"overdue", "next", "high"}},
+		{name: "limit zero", query: "?limit=0", wantStatus: http.StatusBadRequest},
+		{name: "limit too large", query: "?limit=51", wantStatus: http.StatusBadRequest},
+		{name: "limit not a number", query: "?limit=five", wantStatus: http.StatusBadRequest},
+		{name: "invalid timezone", query: "?tz=Mars/Olympus", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			userID := uuid.New()
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				listOpenByUserIDFunc: func(ctx context.Context, gotUserID uuid.UUID, limit int) ([]*models.Todo, error) {
+					if gotUserID != userID || limit != FocusCandidateLimit {
+						t.Errorf("ListOpenByUserID(%s, %d), want (%s, %d)", gotUserID, limit, userID, FocusCandidateLimit)
+					}
+					return openTodos(userID), nil
+				},
+			}
+			handler := NewTodoHandler(todoRepo, zap.NewNop(), tt.opts...)
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/focus"+tt.query, nil)
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Data FocusTodosResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if _, err := time.Parse(time.DateOnly, resp.Data.Date); err != nil {
+				t.Errorf("date = %q, want YYYY-MM-DD", resp.Data.Date)
+			}
+			if len(resp.Data.Todos) != len(tt.wantTexts) {
+				t.Fatalf("got %d todos, want %d", len(resp.Data.Todos), len(tt.wantTexts))
+			}
+			for i, todo := range resp.Data.Todos {
+				if todo.Text != tt.wantTexts[i] {
+					t.Errorf("todo %d = %q, want %q", i, todo.Text, tt.wantTexts[i])
+				}
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"maps"
 	"net/http/httptest"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/benvon/smart-todo/internal/database"
 	"github.com/benvon/smart-todo/internal/models"
 	"github.com/google/uuid"
 )
@@ -56,6 +60,98 @@ func TestParseListParams(t *testing.T) {
 	}
 }
 
+func TestParseListParams_Analyzed(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		query        string
+		wantAnalyzed *bool
+		wantErr      bool
+	}{
+		{"absent", "", nil, false},
+		{"true with time_horizon", "analyzed=true&time_horizon=next", boolPtr(true), false},
+		{"false with status", "analyzed=false&status=pending", boolPtr(false), false},
+		{"invalid", "analyzed=maybe", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest("GET", "http://test/?"+tt.query, nil)
+			got, err := parseListParams(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseListParams() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got.analyzed == nil) != (tt.wantAnalyzed == nil) || (got.analyzed != nil && *got.analyzed != *tt.wantAnalyzed) {
+				t.Errorf("analyzed = %v, want %v", got.analyzed, tt.wantAnalyzed)
+			}
+		})
+	}
+}
+
+func TestParseListParams_IncludeRetired(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		query   string
+		want    bool
+		wantErr bool
+	}{
+		{"absent", "", false, false},
+		{"true", "include_retired=true&status=completed", true, false},
+		{"false", "include_retired=false", false, false},
+		{"invalid", "include_retired=sometimes", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest("GET", "http://test/?"+tt.query, nil)
+			got, err := parseListParams(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseListParams() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.includeRetired != tt.want {
+				t.Errorf("includeRetired = %v, want %v", got.includeRetired, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseListParams_Sort(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		query   string
+		want    database.TodoSort
+		wantErr bool
+	}{
+		{"absent lists newest first", "", database.TodoSort{Field: database.TodoSortCreatedAt, Order: database.SortDescending}, false},
+		{"due date defaults to soonest first", "sort=due_date", database.TodoSort{Field: database.TodoSortDueDate, Order: database.SortAscending}, false},
+		{"time horizon defaults to most urgent first", "sort=time_horizon", database.TodoSort{Field: database.TodoSortTimeHorizon, Order: database.SortAscending}, false},
+		{"priority defaults to most urgent first", "sort=priority", database.TodoSort{Field: database.TodoSortPriority, Order: database.SortAscending}, false},
+		{"updated_at descending", "sort=updated_at&order=desc", database.TodoSort{Field: database.TodoSortUpdatedAt, Order: database.SortDescending}, false},
+		{"order without sort", "order=asc", database.TodoSort{Field: database.TodoSortCreatedAt, Order: database.SortAscending}, false},
+		{"invalid sort field", "sort=text", database.TodoSort{}, true},
+		{"sql in sort field", "sort=created_at%3BDROP%20TABLE%20todos", database.TodoSort{}, true},
+		{"invalid order", "sort=due_date&order=up", database.TodoSort{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest("GET", "http://test/?"+tt.query, nil)
+			got, err := parseListParams(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseListParams() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.sort != tt.want {
+				t.Errorf("sort = %+v, want %+v", got.sort, tt.want)
+			}
+		})
+	}
+}
+
 func TestApplyUpdatesToTodo(t *testing.T) {
 	t.Parallel()
 	todo := &models.Todo{
@@ -80,6 +176,68 @@ func TestApplyUpdatesToTodo(t *testing.T) {
 	}
 }
 
+func TestParseListParams_Priority(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		query   string
+		want    models.Priority
+		wantErr bool
+	}{
+		{"absent does not filter", "", "", false},
+		{"urgent", "priority=urgent", models.PriorityUrgent, false},
+		{"low", "priority=low", models.PriorityLow, false},
+		{"unknown priority", "priority=critical", "", true},
+		{"wrong case", "priority=High", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest("GET", "http://test/?"+tt.query, nil)
+			got, err := parseListParams(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseListParams() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got.priority == nil) != (tt.want == "") || (got.priority != nil && *got.priority != tt.want) {
+				t.Errorf("priority = %v, want %q", got.priority, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyUpdatesToTodo_Priority(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		current  models.Priority
+		priority *string
+		want     models.Priority
+		wantErr  bool
+	}{
+		{name: "absent keeps the priority", current: models.PriorityHigh, want: models.PriorityHigh},
+		{name: "sets a priority", priority: stringPtr("urgent"), want: models.PriorityUrgent},
+		{name: "changes the priority", current: models.PriorityUrgent, priority: stringPtr("low"), want: models.PriorityLow},
+		{name: "empty string clears it", current: models.PriorityMedium, priority: stringPtr("")},
+		{name: "invalid priority", current: models.PriorityMedium, priority: stringPtr("asap"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todo := &models.Todo{Priority: tt.current}
+			err := applyUpdatesToTodo(todo, &UpdateTodoRequest{Priority: tt.priority})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyUpdatesToTodo() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && todo.Priority != tt.want {
+				t.Errorf("Priority = %q, want %q", todo.Priority, tt.want)
+			}
+		})
+	}
+}
+
 func TestApplyUpdatesToTodo_EmptyTimeHorizonClearsOverride(t *testing.T) {
 	t.Parallel()
 	override := true
@@ -106,3 +264,122 @@ func TestApplyUpdatesToTodo_InvalidTimeHorizon(t *testing.T) {
 		t.Error("expected validation error for invalid time_horizon")
 	}
 }
+
+func TestApplyUpdatesToTodo_DueDate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		dueDate      string
+		wantDue      string
+		wantDateOnly bool
+		wantErr      bool
+	}{
+		{"date only", "2024-03-15", "2024-03-15T00:00:00Z", true, false},
+		{"offset timestamp stored in UTC", "2024-03-15T00:00:00+09:00", "2024-03-14T15:00:00Z", false, false},
+		{"clear", "", "", false, false},
+		{"invalid", "15/03/2024", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			due := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			todo := &models.Todo{DueDate: &due, Metadata: models.Metadata{DueDateOnly: true}}
+			err := applyUpdatesToTodo(todo, &UpdateTodoRequest{DueDate: stringPtr(tt.dueDate)})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyUpdatesToTodo err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := ""
+			if todo.DueDate != nil {
+				got = todo.DueDate.Format(time.RFC3339)
+			}
+			if got != tt.wantDue {
+				t.Errorf("DueDate = %q, want %q", got, tt.wantDue)
+			}
+			if todo.Metadata.DueDateOnly != tt.wantDateOnly {
+				t.Errorf("DueDateOnly = %v, want %v", todo.Metadata.DueDateOnly, tt.wantDateOnly)
+			}
+		})
+	}
+}
+
+func TestApplyUpdatesToTodo_PartialTags(t *testing.T) {
+	t.Parallel()
+
+	tags := []string{"home"}
+	tests := []struct {
+		name        string
+		req         UpdateTodoRequest
+		wantErr     bool
+		wantTags    []string
+		wantSources map[string]models.TagSource
+	}{
+		{
+			name:        "add only",
+			req:         UpdateTodoRequest{AddTags: []string{"urgent", "work"}},
+			wantTags:    []string{"work", "errands", "urgent"},
+			wantSources: map[string]models.TagSource{"work": models.TagSourceUser, "errands": models.TagSourceAI, "urgent": models.TagSourceUser},
+		},
+		{
+			name:        "add an AI tag makes it user-defined",
+			req:         UpdateTodoRequest{AddTags: []string{"errands"}},
+			wantTags:    []string{"work", "errands"},
+			wantSources: map[string]models.TagSource{"work": models.TagSourceUser, "errands": models.TagSourceUser},
+		},
+		{
+			name:        "remove only",
+			req:         UpdateTodoRequest{RemoveTags: []string{"errands", "missing"}},
+			wantTags:    []string{"work"},
+			wantSources: map[string]models.TagSource{"work": models.TagSourceUser},
+		},
+		{
+			name:        "add and remove",
+			req:         UpdateTodoRequest{AddTags: []string{"urgent"}, RemoveTags: []string{"work"}},
+			wantTags:    []string{"errands", "urgent"},
+			wantSources: map[string]models.TagSource{"errands": models.TagSourceAI, "urgent": models.TagSourceUser},
+		},
+		{
+			name:    "tags with add_tags",
+			req:     UpdateTodoRequest{Tags: &tags, AddTags: []string{"urgent"}},
+			wantErr: true,
+		},
+		{
+			name:    "tags with remove_tags",
+			req:     UpdateTodoRequest{Tags: &tags, RemoveTags: []string{"work"}},
+			wantErr: true,
+		},
+		{
+			name:    "same tag added and removed",
+			req:     UpdateTodoRequest{AddTags: []string{"urgent"}, RemoveTags: []string{"urgent"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todo := &models.Todo{Metadata: models.Metadata{
+				CategoryTags: []string{"work", "errands"},
+				TagSources:   map[string]models.TagSource{"work": models.TagSourceUser, "errands": models.TagSourceAI},
+			}}
+			err := applyUpdatesToTodo(todo, &tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyUpdatesToTodo err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !slices.Equal(todo.Metadata.CategoryTags, []string{"work", "errands"}) {
+					t.Errorf("tags changed on error: %v", todo.Metadata.CategoryTags)
+				}
+				return
+			}
+			if !slices.Equal(todo.Metadata.CategoryTags, tt.wantTags) {
+				t.Errorf("tags = %v, want %v", todo.Metadata.CategoryTags, tt.wantTags)
+			}
+			if !maps.Equal(todo.Metadata.TagSources, tt.wantSources) {
+				t.Errorf("tag sources = %v, want %v", todo.Metadata.TagSources, tt.wantSources)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/benvon/smart-todo/internal/database"
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// IdempotencyKeyHeader names the header clients set to retry CreateTodo without creating the todo twice
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// MaxIdempotencyKeyLength is the maximum length of an Idempotency-Key
+	MaxIdempotencyKeyLength = 255
+)
+
+// replayIdempotentCreate returns the request's Idempotency-Key, or "" when it has none or the handler keeps no
+// keys. When the user already created a todo with the key, or the key is unusable, it writes the response and
+// reports done.
+func (h *TodoHandler) replayIdempotentCreate(w http.ResponseWriter, r *http.Request, user *models.User) (key string, done bool) {
+	key = r.Header.Get(IdempotencyKeyHeader)
+	if h.idempotencyRepo == nil || key == "" {
+		return "", false
+	}
+	if len(key) > MaxIdempotencyKeyLength {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", fmt.Sprintf("%s exceeds maximum length of %d characters", IdempotencyKeyHeader, MaxIdempotencyKeyLength))
+		return "", true
+	}
+	todoID, ok, err := h.idempotencyRepo.GetTodoID(r.Context(), user.ID, key)
+	if err != nil {
+		h.logger.Error("failed_to_get_idempotency_key",
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to create todo")
+		return "", true
+	}
+	if !ok {
+		return key, false
+	}
+	todo, err := h.todoRepo.GetByUserIDAndID(r.Context(), user.ID, todoID)
+	if errors.Is(err, database.ErrTodoNotFound) {
+		// Deleting the todo deletes its key as well; this only races with that delete
+		return key, false
+	}
+	if err != nil {
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to create todo")
+		return "", true
+	}
+	h.respondReplayedCreate(w, user, todo)
+	return "", true
+}
+
+// respondReplayedCreate answers a repeated create with the todo its Idempotency-Key created
+func (h *TodoHandler) respondReplayedCreate(w http.ResponseWriter, user *models.User, todo *models.Todo) {
+	h.logger.Debug("todo_create_replayed",
+		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+		zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+	)
+	respondJSON(w, http.StatusOK, todo)
+}
+
+// createTodo creates todo, saving key for it in the same transaction when given. When a concurrent create
+// with the key won the race, nothing is created and the todo that create made is returned with
+// created=false.
+func (h *TodoHandler) createTodo(ctx context.Context, user *models.User, key string, todo *models.Todo) (result *models.Todo, created bool, err error) {
+	if key == "" {
+		return todo, true, h.todoRepo.Create(ctx, todo)
+	}
+	todoID, created, err := h.idempotencyRepo.CreateTodo(ctx, user.ID, key, todo)
+	if err != nil || created {
+		return todo, created, err
+	}
+	existing, err := h.todoRepo.GetByUserIDAndID(ctx, user.ID, todoID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get the todo created with the idempotency key: %w", err)
+	}
+	return existing, false, nil
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/database"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type mockIdempotencyRepo struct {
+	keys    map[string]uuid.UUID
+	getErr  error
+	saveErr error
+	// raced is the todo a concurrent create saved the key for after GetTodoID missed it (none when nil)
+	raced   uuid.UUID
+	created []*models.Todo
+}
+
+func (m *mockIdempotencyRepo) GetTodoID(ctx context.Context, userID uuid.UUID, key string) (uuid.UUID, bool, error) {
+	if m.getErr != nil {
+		return uuid.Nil, false, m.getErr
+	}
+	id, ok := m.keys[userID.String()+"/"+key]
+	return id, ok, nil
+}
+
+func (m *mockIdempotencyRepo) CreateTodo(ctx context.Context, userID uuid.UUID, key string, todo *models.Todo) (uuid.UUID, bool, error) {
+	if m.saveErr != nil {
+		return uuid.Nil, false, m.saveErr
+	}
+	if m.raced != uuid.Nil {
+		m.keys[userID.String()+"/"+key] = m.raced
+		return m.raced, false, nil
+	}
+	m.keys[userID.String()+"/"+key] = todo.ID
+	m.created = append(m.created, todo)
+	return todo.ID, true, nil
+}
+
+func TestTodoHandler_CreateTodo_IdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: uuid.New()}
+	original := &models.Todo{ID: uuid.New(), UserID: user.ID, Text: "Buy milk", Status: models.TodoStatusPending}
+
+	tests := []struct {
+		name string
+		key  string
+		// stored maps keys of user to todo IDs before the request
+		stored      map[string]uuid.UUID
+		noRepo      bool
+		getErr      error
+		saveErr     error
+		raced       bool
+		wantStatus  int
+		wantCreated bool
+		wantTodoID  uuid.UUID
+		wantSaved   bool
+	}{
+		{name: "no key", wantStatus: http.StatusCreated, wantCreated: true},
+		{name: "new key", key: "retry-1", wantStatus: http.StatusCreated, wantCreated: true, wantSaved: true},
+		{name: "repeated key", key: "retry-1", stored: map[string]uuid.UUID{"retry-1": original.ID}, wantStatus: http.StatusOK, wantTodoID: original.ID, wantSaved: true},
+		{name: "key of a deleted todo", key: "retry-1", stored: map[string]uuid.UUID{"retry-1": uuid.New()}, wantStatus: http.StatusCreated, wantCreated: true, wantSaved: true},
+		{name: "key too long", key: strings.Repeat("k", MaxIdempotencyKeyLength+1), wantStatus: http.StatusBadRequest},
+		{name: "lookup fails", key: "retry-1", getErr: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+		{name: "key taken by a concurrent create", key: "retry-1", raced: true, wantStatus: http.StatusOK, wantTodoID: original.ID, wantSaved: true},
+		{name: "save fails", key: "retry-1", saveErr: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+		{name: "keys not kept", key: "retry-1", noRepo: true, wantStatus: http.StatusCreated, wantCreated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := &mockTodoRepoForHandlers{
+				t: t,
+				getByUserIDAndIDFunc: func(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Todo, error) {
+					if userID == user.ID && id == original.ID {
+						return original, nil
+					}
+					return nil, database.ErrTodoNotFound
+				},
+			}
+			keys := &mockIdempotencyRepo{keys: map[string]uuid.UUID{}, getErr: tt.getErr, saveErr: tt.saveErr}
+			if tt.raced {
+				keys.raced = original.ID
+			}
+			for k, id := range tt.stored {
+				keys.keys[user.ID.String()+"/"+k] = id
+			}
+			var opts []TodoHandlerOption
+			if !tt.noRepo {
+				opts = append(opts, WithTodoIdempotencyRepo(keys))
+			}
+			handler := NewTodoHandler(repo, zap.NewNop(), opts...)
+
+			req := httptest.NewRequest("POST", "/api/v1/todos", strings.NewReader(`{"text":"Buy milk"}`))
+			if tt.key != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+			handler.CreateTodo(w, setUserInRequestContext(req, user))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			// With a key the todo is created in the key's transaction, otherwise by the todo repository
+			createCalls := append(repo.createCalls, keys.created...)
+			if created := len(createCalls) == 1; created != tt.wantCreated {
+				t.Errorf("created = %v, want %v", created, tt.wantCreated)
+			}
+			saved, ok := keys.keys[user.ID.String()+"/"+tt.key]
+			if ok != tt.wantSaved {
+				t.Errorf("key saved = %v, want %v", ok, tt.wantSaved)
+			}
+			if tt.wantCreated && tt.wantSaved && saved != createCalls[0].ID {
+				t.Errorf("key saved for %v, want the created todo %v", saved, createCalls[0].ID)
+			}
+			if tt.wantTodoID != uuid.Nil {
+				var resp struct {
+					Data models.Todo `json:"data"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if resp.Data.ID != tt.wantTodoID {
+					t.Errorf("returned todo %v, want %v", resp.Data.ID, tt.wantTodoID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/benvon/smart-todo/internal/validation"
+	"go.uber.org/zap"
+)
+
+const (
+	// MarkdownImportPath is the route of the markdown checklist import, relative to /api/v1
+	MarkdownImportPath = "/todos/import/markdown"
+	// MaxMarkdownImportItems is the maximum number of checklist items accepted by one import
+	MaxMarkdownImportItems = 500
+)
+
+// MarkdownImportContentTypes are the media types accepted by the markdown import
+var MarkdownImportContentTypes = []string{"text/markdown", "text/plain"}
+
+// MarkdownImportResponse reports the todos created by a markdown import
+type MarkdownImportResponse struct {
+	TodosCreated   int            `json:"todos_created"`
+	TodosCompleted int            `json:"todos_completed"`
+	Todos          []*models.Todo `json:"todos"`
+}
+
+// ImportMarkdown creates todos from the task items of a markdown checklist ("- [ ] task", "- [x] done"), such as
+// an Obsidian or Notion export. Checked items are created completed. Todos have no subtasks, so nested items
+// become todos of their own, in document order after their parent. All todos are created in one transaction;
+// unchecked ones are queued for analysis.
+func (h *TodoHandler) ImportMarkdown(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	if !isMarkdownImportContentType(r.Header.Get("Content-Type")) {
+		respondJSONError(w, http.StatusUnsupportedMediaType, "Unsupported Media Type", "Content-Type must be text/markdown or text/plain")
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondJSONError(w, http.StatusRequestEntityTooLarge, "Request Entity Too Large", fmt.Sprintf("Request body exceeds maximum size of %d bytes", maxBytesErr.Limit))
+			return
+		}
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid request body")
+		return
+	}
+	todos, err := todosFromChecklist(models.ParseMarkdownChecklist(string(body)), user, time.Now())
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.todoRepo.CreateBatch(ctx, todos); err != nil {
+		h.logger.Error("failed_to_import_markdown_todos",
+			zap.String("operation", "import_markdown"),
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to import todos")
+		return
+	}
+	resp := MarkdownImportResponse{TodosCreated: len(todos), Todos: todos}
+	for _, todo := range todos {
+		if todo.Status == models.TodoStatusCompleted {
+			resp.TodosCompleted++
+			continue
+		}
+		h.enqueueCreateTodoJob(ctx, user, todo)
+	}
+
+	h.logger.Info("imported_markdown_todos",
+		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+		zap.Int("todos_created", resp.TodosCreated),
+		zap.Int("todos_completed", resp.TodosCompleted),
+	)
+	respondJSON(w, http.StatusCreated, resp)
+}
+
+func isMarkdownImportContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range MarkdownImportContentTypes {
+		if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// todosFromChecklist builds new todos for the checklist items, rejecting empty and oversized imports
+func todosFromChecklist(items []models.ChecklistItem, user *models.User, now time.Time) ([]*models.Todo, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no checklist items found (expected lines like \"- [ ] task\")")
+	}
+	if len(items) > MaxMarkdownImportItems {
+		return nil, fmt.Errorf("at most %d checklist items may be imported at once (got %d)", MaxMarkdownImportItems, len(items))
+	}
+	timeEntered := now.Format(time.RFC3339)
+	todos := make([]*models.Todo, 0, len(items))
+	for _, item := range items {
+		text := validation.SanitizeText(item.Text)
+		if len(text) > MaxTodoTextLength {
+			return nil, fmt.Errorf("line %d: text exceeds maximum length of %d characters", item.Line, MaxTodoTextLength)
+		}
+		entered := timeEntered
+		todo := newTodo(user, text, &entered)
+		if item.Checked {
+			if err := todo.TransitionTo(models.TodoStatusCompleted, now); err != nil {
+				return nil, err
+			}
+		}
+		todos = append(todos, todo)
+	}
+	return todos, nil
+}
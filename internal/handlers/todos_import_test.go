@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func TestTodoHandler_ImportMarkdown(t *testing.T) {
+	t.Parallel()
+
+	const checklist = "# Weekend\n" +
+		"- [ ] Plan **trip** to [Lisbon](https://example.com)\n" +
+		"  - [x] Book flights\n" +
+		"  - [ ] Find hotel\n" +
+		"- [X] ~~Renew~~ passport\n" +
+		"Notes that are not tasks\n"
+
+	tests := []struct {
+		name          string
+		contentType   string
+		body          string
+		wantStatus    int
+		wantTexts     []string
+		wantCompleted []bool
+		wantJobs      int
+	}{
+		{
+			name:          "nested and mixed checklist",
+			contentType:   "text/markdown; charset=utf-8",
+			body:          checklist,
+			wantStatus:    http.StatusCreated,
+			wantTexts:     []string{"Plan trip to Lisbon", "Book flights", "Find hotel", "Renew passport"},
+			wantCompleted: []bool{false, true, false, true},
+			wantJobs:      2,
+		},
+		{
+			name:          "plain text is accepted",
+			contentType:   "text/plain",
+			body:          "- [ ] one\n",
+			wantStatus:    http.StatusCreated,
+			wantTexts:     []string{"one"},
+			wantCompleted: []bool{false},
+			wantJobs:      1,
+		},
+		{name: "json is rejected", contentType: "application/json", body: checklist, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "no checklist items", contentType: "text/markdown", body: "just text\n- bullet\n", wantStatus: http.StatusBadRequest},
+		{
+			name:        "item too long",
+			contentType: "text/markdown",
+			body:        "- [ ] " + strings.Repeat("a", MaxTodoTextLength+1) + "\n",
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			userID := uuid.New()
+			todoRepo := &mockTodoRepoForHandlers{t: t}
+			jobQueue := &mockJobQueueForHandlers{}
+			handler := NewTodoHandler(todoRepo, zap.NewNop(), WithTodoJobQueue(jobQueue))
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/import/markdown", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				if len(todoRepo.createCalls) != 0 || len(jobQueue.enqueueCalls) != 0 {
+					t.Error("expected no todos or jobs for a rejected import")
+				}
+				return
+			}
+
+			var resp struct {
+				Data MarkdownImportResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Data.TodosCreated != len(tt.wantTexts) {
+				t.Errorf("todos_created = %d, want %d", resp.Data.TodosCreated, len(tt.wantTexts))
+			}
+			if len(todoRepo.createCalls) != len(tt.wantTexts) {
+				t.Fatalf("created %d todos, want %d", len(todoRepo.createCalls), len(tt.wantTexts))
+			}
+			completed := 0
+			for i, todo := range todoRepo.createCalls {
+				if todo.Text != tt.wantTexts[i] {
+					t.Errorf("todo %d text = %q, want %q", i, todo.Text, tt.wantTexts[i])
+				}
+				if todo.UserID != userID {
+					t.Errorf("todo %d user = %s, want %s", i, todo.UserID, userID)
+				}
+				isCompleted := todo.Status == models.TodoStatusCompleted
+				if isCompleted != tt.wantCompleted[i] || isCompleted != (todo.CompletedAt != nil) {
+					t.Errorf("todo %d status = %s (completed_at %v), want completed = %v", i, todo.Status, todo.CompletedAt, tt.wantCompleted[i])
+				}
+				if isCompleted {
+					completed++
+				}
+			}
+			if resp.Data.TodosCompleted != completed {
+				t.Errorf("todos_completed = %d, want %d", resp.Data.TodosCompleted, completed)
+			}
+			if len(jobQueue.enqueueCalls) != tt.wantJobs {
+				t.Errorf("enqueued %d analysis jobs, want %d (unchecked items only)", len(jobQueue.enqueueCalls), tt.wantJobs)
+			}
+		})
+	}
+}
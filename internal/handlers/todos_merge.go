@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/database"
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// MergeTodoRequest names the duplicate todo to fold into the target. Reanalyze enqueues an AI analysis of
+// the merged todo.
+type MergeTodoRequest struct {
+	SourceID  string `json:"source_id"`
+	Reanalyze bool   `json:"reanalyze,omitempty"`
+}
+
+// MergeTodo merges the source todo into the todo in the path and moves the source to the trash, in one
+// transaction. See models.MergeTodos for how fields are combined. Responds with the merged todo.
+func (h *TodoHandler) MergeTodo(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+
+	targetID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "Invalid todo ID")
+		return
+	}
+	var req MergeTodoRequest
+	if err := decodeJSONBody(r, &req, h.strictJSON); err != nil {
+		respondBodyDecodeError(w, err)
+		return
+	}
+	sourceID, err := uuid.Parse(req.SourceID)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "source_id must be a todo ID")
+		return
+	}
+	if sourceID == targetID {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "A todo cannot be merged into itself")
+		return
+	}
+
+	ctx := database.WithChangeActor(r.Context(), models.ChangeActorUser)
+	now := time.Now()
+	merged, err := h.todoRepo.MergeTodos(ctx, user.ID, targetID, sourceID, func(target, source *models.Todo) error {
+		return models.MergeTodos(target, source, now)
+	})
+	switch {
+	case errors.Is(err, database.ErrTodoNotFound):
+		respondJSONError(w, http.StatusNotFound, "Not Found", "Todo not found")
+		return
+	case errors.Is(err, models.ErrInvalidMerge):
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	case err != nil:
+		h.logger.Error("failed_to_merge_todos",
+			zap.String("operation", "merge_todos"),
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to merge todos")
+		return
+	}
+
+	h.logger.Info("merged_todos",
+		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+		zap.String("todo_id", logpkg.SanitizeUserID(merged.ID.String())),
+		zap.String("source_todo_id", logpkg.SanitizeUserID(sourceID.String())),
+	)
+	h.scheduleReminderChain(ctx, merged)
+	if req.Reanalyze {
+		h.enqueueMergedTodoAnalysis(ctx, merged)
+	}
+	respondJSON(w, http.StatusOK, merged)
+}
+
+// enqueueMergedTodoAnalysis enqueues a task analysis of a merged todo unless it is opted out of analysis
+func (h *TodoHandler) enqueueMergedTodoAnalysis(ctx context.Context, todo *models.Todo) {
+	if h.jobQueue == nil || todo.Metadata.AnalysisDisabled {
+		return
+	}
+	job := queue.NewJob(queue.JobTypeTaskAnalysis, todo.UserID, &todo.ID)
+	if !h.admitAnalysisJob(ctx, job, "merge_todos") {
+		return
+	}
+	if err := h.jobQueue.Enqueue(ctx, job); err != nil {
+		h.logger.Warn("failed_to_enqueue_ai_analysis_job",
+			zap.String("operation", "merge_todos"),
+			zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+			zap.String("user_id", logpkg.SanitizeUserID(todo.UserID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func TestTodoHandler_MergeTodo(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	// newTodos returns the target, a duplicate of it and another user's todo
+	newTodos := func() []*models.Todo {
+		return []*models.Todo{
+			{ID: uuid.New(), UserID: userID, Text: "Book dentist", Status: models.TodoStatusProcessed, Metadata: models.Metadata{
+				CategoryTags: []string{"health"}, TagSources: map[string]models.TagSource{"health": models.TagSourceAI},
+			}},
+			{ID: uuid.New(), UserID: userID, Text: "dentist appointment", Status: models.TodoStatusPending, Metadata: models.Metadata{
+				CategoryTags: []string{"calls"}, TagSources: map[string]models.TagSource{"calls": models.TagSourceUser},
+				ExternalRefs: []models.ExternalRef{{System: "jira", ID: "HOME-3"}},
+			}},
+			{ID: uuid.New(), UserID: uuid.New(), Text: "Someone else's todo", Status: models.TodoStatusPending},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		target      int
+		body        func(todos []*models.Todo) string
+		wantStatus  int
+		wantEnqueue int
+	}{
+		{"merges duplicate", 0, func(todos []*models.Todo) string { return `{"source_id":"` + todos[1].ID.String() + `"}` }, http.StatusOK, 0},
+		{"merges and re-analyzes", 0, func(todos []*models.Todo) string {
+			return `{"source_id":"` + todos[1].ID.String() + `","reanalyze":true}`
+		}, http.StatusOK, 1},
+		{"source owned by another user", 0, func(todos []*models.Todo) string { return `{"source_id":"` + todos[2].ID.String() + `"}` }, http.StatusNotFound, 0},
+		{"target owned by another user", 2, func(todos []*models.Todo) string { return `{"source_id":"` + todos[1].ID.String() + `"}` }, http.StatusNotFound, 0},
+		{"merge into itself", 0, func(todos []*models.Todo) string { return `{"source_id":"` + todos[0].ID.String() + `"}` }, http.StatusBadRequest, 0},
+		{"invalid source_id", 0, func(todos []*models.Todo) string { return `{"source_id":"nope"}` }, http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todos := newTodos()
+			todoRepo := &mockTodoRepoForHandlers{t: t, bulkTodos: todos}
+			jobQueue := &mockJobQueueForHandlers{}
+			handler := NewTodoHandler(todoRepo, zap.NewNop(), WithTodoJobQueue(jobQueue))
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/"+todos[tt.target].ID.String()+"/merge", strings.NewReader(tt.body(todos)))
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if len(jobQueue.enqueueCalls) != tt.wantEnqueue {
+				t.Fatalf("enqueued %d jobs, want %d", len(jobQueue.enqueueCalls), tt.wantEnqueue)
+			}
+			if tt.wantEnqueue > 0 && (jobQueue.enqueueCalls[0].Type != queue.JobTypeTaskAnalysis || *jobQueue.enqueueCalls[0].TodoID != todos[0].ID) {
+				t.Errorf("enqueued %+v, want analysis of the merged todo", jobQueue.enqueueCalls[0])
+			}
+			if tt.wantStatus != http.StatusOK {
+				if todos[1].TrashedAt != nil {
+					t.Error("source was trashed by a rejected merge")
+				}
+				return
+			}
+
+			var resp struct {
+				Data models.Todo `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Data.ID != todos[0].ID || !slices.Equal(resp.Data.Metadata.CategoryTags, []string{"health", "calls"}) {
+				t.Errorf("merged todo = %s with tags %v, want the target with both tags", resp.Data.ID, resp.Data.Metadata.CategoryTags)
+			}
+			if len(resp.Data.Metadata.ExternalRefs) != 1 || resp.Data.Metadata.ExternalRefs[0].ID != "HOME-3" {
+				t.Errorf("external refs = %+v, want the source's ref", resp.Data.Metadata.ExternalRefs)
+			}
+			if todos[1].TrashedAt == nil {
+				t.Error("source todo was not moved to the trash")
+			}
+		})
+	}
+}
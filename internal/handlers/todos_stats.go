@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/request"
+	"go.uber.org/zap"
+)
+
+// GetTodoStats returns how many todos the user has by status and time horizon, and how many are overdue.
+// Archived and trashed todos are not counted.
+func (h *TodoHandler) GetTodoStats(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+
+	counts, err := h.todoRepo.CountByStatusAndHorizon(r.Context(), user.ID)
+	if err != nil {
+		h.logger.Error("failed_to_get_todo_stats",
+			zap.String("operation", "todo_stats"),
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to retrieve todo stats")
+		return
+	}
+	respondJSON(w, http.StatusOK, counts)
+}
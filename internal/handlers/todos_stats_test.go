@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func TestTodoHandler_GetTodoStats(t *testing.T) {
+	t.Parallel()
+
+	counts := models.NewTodoCounts()
+	counts.Add(models.TodoStatusPending, models.TimeHorizonNext, 2, 1)
+	counts.Add(models.TodoStatusCompleted, models.TimeHorizonSoon, 3, 0)
+
+	tests := []struct {
+		name       string
+		repoErr    error
+		noUser     bool
+		wantStatus int
+	}{
+		{name: "counts", wantStatus: http.StatusOK},
+		{name: "repository error", repoErr: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+		{name: "no user", noUser: true, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			userID := uuid.New()
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				countByStatusFunc: func(ctx context.Context, gotUserID uuid.UUID) (*models.TodoCounts, error) {
+					if gotUserID != userID {
+						t.Errorf("CountByStatusAndHorizon(%s), want %s", gotUserID, userID)
+					}
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+					return counts, nil
+				},
+			}
+			handler := NewTodoHandler(todoRepo, zap.NewNop())
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/stats", nil)
+			if !tt.noUser {
+				req = setUserInRequestContext(req, &models.User{ID: userID})
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Data models.TodoCounts `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if !reflect.DeepEqual(&resp.Data, counts) {
+				t.Errorf("stats = %+v, want %+v", resp.Data, *counts)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/benvon/smart-todo/internal/database"
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// DeleteTagResponse reports how many todos a tag was removed from
+type DeleteTagResponse struct {
+	TodosUpdated int `json:"todos_updated"`
+}
+
+// DeleteTag removes the tag in the path from all of the user's todos, whatever its source. Tag statistics are
+// marked tainted and reanalyzed through the tag change handler. Responds 404 when no todo has the tag, so
+// repeating a deletion changes nothing.
+func (h *TodoHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+
+	tag := models.NormalizeTagName(mux.Vars(r)["tag"])
+	if tag == "" {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "Tag is required")
+		return
+	}
+
+	ctx := database.WithChangeActor(r.Context(), models.ChangeActorUser)
+	updated, err := h.todoRepo.RemoveTagFromAll(ctx, user.ID, tag)
+	if err != nil {
+		h.logger.Error("failed_to_delete_tag",
+			zap.String("operation", "delete_tag"),
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to delete tag")
+		return
+	}
+	if updated == 0 {
+		respondJSONError(w, http.StatusNotFound, "Not Found", "No todo has this tag")
+		return
+	}
+
+	h.logger.Info("deleted_tag",
+		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+		zap.Int("todos_updated", updated),
+	)
+	respondJSON(w, http.StatusOK, DeleteTagResponse{TodosUpdated: updated})
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func TestTodoHandler_DeleteTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		path        string
+		repoErr     error
+		wantStatus  int
+		wantUpdated int
+	}{
+		{name: "removes the tag", path: "/tags/errands", wantStatus: http.StatusOK, wantUpdated: 2},
+		{name: "normalizes the tag", path: "/tags/Errands", wantStatus: http.StatusOK, wantUpdated: 2},
+		{name: "no todo has the tag", path: "/tags/garden", wantStatus: http.StatusNotFound},
+		{name: "repository fails", path: "/tags/errands", repoErr: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todos := []*models.Todo{
+				{ID: uuid.New(), UserID: userID, Metadata: models.Metadata{
+					CategoryTags: []string{"errands", "home"},
+					TagSources:   map[string]models.TagSource{"errands": models.TagSourceUser, "home": models.TagSourceAI},
+				}},
+				{ID: uuid.New(), UserID: userID, Metadata: models.Metadata{
+					CategoryTags: []string{"work", "errands"},
+					TagSources:   map[string]models.TagSource{"errands": models.TagSourceAI},
+				}},
+				{ID: uuid.New(), UserID: uuid.New(), Metadata: models.Metadata{CategoryTags: []string{"errands"}}},
+			}
+			todoRepo := &mockTodoRepoForHandlers{t: t, bulkTodos: todos, removeTagErr: tt.repoErr}
+			router := mux.NewRouter()
+			NewTodoHandler(todoRepo, zap.NewNop()).RegisterRoutes(router)
+
+			req := httptest.NewRequest("DELETE", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, setUserInRequestContext(req, &models.User{ID: userID}))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Data DeleteTagResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Data.TodosUpdated != tt.wantUpdated {
+				t.Errorf("todos_updated = %d, want %d", resp.Data.TodosUpdated, tt.wantUpdated)
+			}
+			if !slices.Equal(todos[0].Metadata.CategoryTags, []string{"home"}) || !slices.Equal(todos[1].Metadata.CategoryTags, []string{"work"}) {
+				t.Errorf("tags after delete = %v, %v", todos[0].Metadata.CategoryTags, todos[1].Metadata.CategoryTags)
+			}
+			if !slices.Equal(todos[2].Metadata.CategoryTags, []string{"errands"}) {
+				t.Error("tag removed from another user's todo")
+			}
+
+			// Deleting the tag again changes nothing
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, setUserInRequestContext(httptest.NewRequest("DELETE", tt.path, nil), &models.User{ID: userID}))
+			if w.Code != http.StatusNotFound {
+				t.Errorf("repeated delete status = %d, want %d", w.Code, http.StatusNotFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/benvon/smart-todo/internal/database"
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"go.uber.org/zap"
+)
+
+// MaxTagMergeSources is the maximum number of tags one merge may fold into its target
+const MaxTagMergeSources = 100
+
+// MergeTagsRequest names the tags to rename to To across all of the user's todos
+type MergeTagsRequest struct {
+	From []string `json:"from"`
+	To   string   `json:"to"`
+}
+
+// MergeTagsResponse reports how many todos a tag merge rewrote
+type MergeTagsResponse struct {
+	TodosUpdated int `json:"todos_updated"`
+}
+
+// MergeTags renames the tags in from to the tag in to across all of the user's todos in one transaction,
+// e.g. to fold near-duplicates such as "grocery" into "groceries". The merged tag is user-defined if any
+// merged instance was. Tag statistics are marked tainted and reanalyzed through the tag change handler.
+func (h *TodoHandler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+
+	var req MergeTagsRequest
+	if err := decodeJSONBody(r, &req, h.strictJSON); err != nil {
+		respondBodyDecodeError(w, err)
+		return
+	}
+	to := models.NormalizeTags([]string{req.To})
+	if len(to) == 0 {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", fmt.Sprintf("to must be a tag of 1 to %d printable characters", models.MaxTagLength))
+		return
+	}
+	if len(req.From) > MaxTagMergeSources {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", fmt.Sprintf("from must list at most %d tags", MaxTagMergeSources))
+		return
+	}
+	from := slices.DeleteFunc(models.NormalizeTags(req.From), func(tag string) bool { return tag == to[0] })
+	if len(from) == 0 {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", "from must list at least one tag other than to")
+		return
+	}
+
+	ctx := database.WithChangeActor(r.Context(), models.ChangeActorUser)
+	updated, err := h.todoRepo.RenameTag(ctx, user.ID, from, to[0])
+	if err != nil {
+		h.logger.Error("failed_to_merge_tags",
+			zap.String("operation", "merge_tags"),
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to merge tags")
+		return
+	}
+
+	h.logger.Info("merged_tags",
+		zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+		zap.Int("merged_tags", len(from)),
+		zap.Int("todos_updated", updated),
+	)
+	respondJSON(w, http.StatusOK, MergeTagsResponse{TodosUpdated: updated})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func TestTodoHandler_MergeTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		body        string
+		repoErr     error
+		wantStatus  int
+		wantUpdated int
+		wantCalled  bool
+	}{
+		{name: "merges near-duplicates", body: `{"from":["grocery"],"to":"groceries"}`, wantStatus: http.StatusOK, wantUpdated: 2, wantCalled: true},
+		{name: "normalizes tags", body: `{"from":[" Grocery ","groceries"],"to":"Groceries"}`, wantStatus: http.StatusOK, wantUpdated: 2, wantCalled: true},
+		{name: "no todo has the tag", body: `{"from":["errands"],"to":"groceries"}`, wantStatus: http.StatusOK, wantCalled: true},
+		{name: "only the target", body: `{"from":["groceries"],"to":"groceries"}`, wantStatus: http.StatusBadRequest},
+		{name: "missing target", body: `{"from":["grocery"]}`, wantStatus: http.StatusBadRequest},
+		{name: "target too long", body: `{"from":["grocery"],"to":"` + strings.Repeat("g", models.MaxTagLength+1) + `"}`, wantStatus: http.StatusBadRequest},
+		{name: "too many sources", body: `{"from":["` + strings.Repeat(`a","`, MaxTagMergeSources) + `b"],"to":"groceries"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid body", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "repository fails", body: `{"from":["grocery"],"to":"groceries"}`, repoErr: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantCalled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todos := []*models.Todo{
+				{ID: uuid.New(), UserID: userID, Metadata: models.Metadata{
+					CategoryTags: []string{"grocery", "home"},
+					TagSources:   map[string]models.TagSource{"grocery": models.TagSourceUser, "home": models.TagSourceAI},
+				}},
+				{ID: uuid.New(), UserID: userID, Metadata: models.Metadata{
+					CategoryTags: []string{"groceries", "grocery"},
+					TagSources:   map[string]models.TagSource{"groceries": models.TagSourceAI, "grocery": models.TagSourceAI},
+				}},
+				{ID: uuid.New(), UserID: userID, Metadata: models.Metadata{CategoryTags: []string{"work"}}},
+			}
+			todoRepo := &mockTodoRepoForHandlers{t: t, bulkTodos: todos, renameTagErr: tt.repoErr}
+			router := mux.NewRouter()
+			NewTodoHandler(todoRepo, zap.NewNop()).RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/tags/merge", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, setUserInRequestContext(req, &models.User{ID: userID}))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if called := todoRepo.renameTagCalls == 1; called != tt.wantCalled {
+				t.Errorf("RenameTag called = %v, want %v", called, tt.wantCalled)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Data MergeTagsResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Data.TodosUpdated != tt.wantUpdated {
+				t.Errorf("todos_updated = %d, want %d", resp.Data.TodosUpdated, tt.wantUpdated)
+			}
+			if tt.wantUpdated == 0 {
+				return
+			}
+			if !slices.Equal(todos[0].Metadata.CategoryTags, []string{"groceries", "home"}) || todos[0].Metadata.TagSources["groceries"] != models.TagSourceUser {
+				t.Errorf("first todo tags = %v %v, want user tag groceries", todos[0].Metadata.CategoryTags, todos[0].Metadata.TagSources)
+			}
+			if !slices.Equal(todos[1].Metadata.CategoryTags, []string{"groceries"}) || todos[1].Metadata.TagSources["groceries"] != models.TagSourceAI {
+				t.Errorf("second todo tags = %v %v, want AI tag groceries", todos[1].Metadata.CategoryTags, todos[1].Metadata.TagSources)
+			}
+		})
+	}
+}
@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/benvon/smart-todo/internal/database"
 	"github.com/benvon/smart-todo/internal/middleware"
 	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
@@ -236,6 +239,74 @@ func TestTodoHandler_GetTagStats_Unauthorized(t *testing.T) {
 	}
 }
 
+func TestTodoHandler_GetTagStats_ETag(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	stats := &models.TagStatistics{
+		UserID:          userID,
+		TagStats:        map[string]models.TagStats{"work": {Total: 1, AI: 1}},
+		AnalysisVersion: 3,
+	}
+	mockTagStatsRepo := &mockTagStatisticsRepoForHandlers{
+		t: t,
+		getByUserIDOrCreateFunc: func(ctx context.Context, uid uuid.UUID) (*models.TagStatistics, error) {
+			return stats, nil
+		},
+	}
+	handler := NewTodoHandler(nil, zap.NewNop(), WithTodoTagStatsRepo(mockTagStatsRepo))
+	user := &models.User{ID: userID}
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/todos/tags/stats", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		req = setUserInRequestContext(req, user)
+		w := httptest.NewRecorder()
+		handler.GetTagStats(w, req)
+		return w
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("first request: status %d, ETag %q; want 200 with ETag", first.Code, etag)
+	}
+	if cc := first.Header().Get("Cache-Control"); !strings.HasPrefix(cc, "private, max-age=") {
+		t.Errorf("Cache-Control = %q, want private max-age", cc)
+	}
+
+	notModified := get(etag)
+	if notModified.Code != http.StatusNotModified {
+		t.Fatalf("matching If-None-Match: status %d, want 304", notModified.Code)
+	}
+	if notModified.Body.Len() != 0 {
+		t.Errorf("304 response has body %q", notModified.Body.String())
+	}
+
+	// MarkTainted flips the flag
+	stats.Tainted = true
+	tainted := get(etag)
+	if tainted.Code != http.StatusOK || tainted.Header().Get("ETag") == etag {
+		t.Fatalf("after taint: status %d, ETag %q; want 200 with new ETag", tainted.Code, tainted.Header().Get("ETag"))
+	}
+
+	// UpdateStatistics bumps the version and clears the flag
+	stats.Tainted = false
+	stats.AnalysisVersion++
+	updated := get(etag)
+	if updated.Code != http.StatusOK {
+		t.Fatalf("after update: status %d, want 200", updated.Code)
+	}
+	newETag := updated.Header().Get("ETag")
+	if newETag == etag || newETag == tainted.Header().Get("ETag") {
+		t.Errorf("after update: ETag %q not changed", newETag)
+	}
+	if get(newETag).Code != http.StatusNotModified {
+		t.Error("expected 304 for the new ETag")
+	}
+}
+
 func TestTodoHandler_GetTagStats_DatabaseError(t *testing.T) {
 	t.Parallel()
 
@@ -438,3 +509,906 @@ func TestTodoHandler_GetTagStats_DefensiveNilCheck(t *testing.T) {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func TestTodoHandler_GetRelatedTags(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	stats := &models.TagStatistics{
+		UserID: userID,
+		CoOccurrence: []models.TagPair{
+			{A: "urgent", B: "work", Count: 5},
+			{A: "email", B: "work", Count: 2},
+			{A: "garden", B: "home", Count: 4},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantTags   []string
+	}{
+		{"related tags strongest first", "?tag=work", http.StatusOK, []string{"urgent", "email"}},
+		{"limit applied", "?tag=work&limit=1", http.StatusOK, []string{"urgent"}},
+		{"unknown tag has no related tags", "?tag=travel", http.StatusOK, []string{}},
+		{"missing tag", "", http.StatusBadRequest, nil},
+		{"invalid limit", "?tag=work&limit=0", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockTagStatsRepo := &mockTagStatisticsRepoForHandlers{
+				t: t,
+				getByUserIDOrCreateFunc: func(ctx context.Context, uid uuid.UUID) (*models.TagStatistics, error) {
+					return stats, nil
+				},
+			}
+			handler := NewTodoHandler(nil, zap.NewNop(), WithTodoTagStatsRepo(mockTagStatsRepo))
+
+			req := httptest.NewRequest("GET", "/api/v1/todos/tags/related"+tt.query, nil)
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			handler.GetRelatedTags(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantTags == nil {
+				return
+			}
+			var wrapper struct {
+				Data RelatedTagsResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &wrapper); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if len(wrapper.Data.Related) != len(tt.wantTags) {
+				t.Fatalf("Expected %d related tags, got %v", len(tt.wantTags), wrapper.Data.Related)
+			}
+			for i, tag := range tt.wantTags {
+				if wrapper.Data.Related[i].Tag != tag {
+					t.Errorf("related[%d] = %s, want %s", i, wrapper.Data.Related[i].Tag, tag)
+				}
+			}
+		})
+	}
+}
+
+// mockJobQueueForHandlers records enqueued jobs
+type mockJobQueueForHandlers struct {
+	enqueueCalls []*queue.Job
+}
+
+func (m *mockJobQueueForHandlers) Enqueue(ctx context.Context, job *queue.Job) error {
+	m.enqueueCalls = append(m.enqueueCalls, job)
+	return nil
+}
+
+func (m *mockJobQueueForHandlers) Dequeue(ctx context.Context) (*queue.Message, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockJobQueueForHandlers) Consume(ctx context.Context, prefetchCount int) (<-chan *queue.Message, <-chan error, error) {
+	return nil, nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockJobQueueForHandlers) Close() error { return nil }
+
+func (m *mockJobQueueForHandlers) HealthCheck(ctx context.Context) error { return nil }
+
+var _ queue.JobQueue = (*mockJobQueueForHandlers)(nil)
+
+func TestTodoHandler_ReanalysisOnTextChange(t *testing.T) {
+	t.Parallel()
+
+	completed := models.TodoStatusCompleted
+	tests := []struct {
+		name             string
+		req              UpdateTodoRequest
+		reanalyze        bool
+		analysisDisabled bool
+		wantJobs         int
+	}{
+		{"text change enqueues one job", UpdateTodoRequest{Text: stringPtr("Buy oat milk")}, true, false, 1},
+		{"status-only change enqueues none", UpdateTodoRequest{Status: &completed}, true, false, 0},
+		{"whitespace-only change enqueues none", UpdateTodoRequest{Text: stringPtr("Buy   milk ")}, true, false, 0},
+		{"disabled by config", UpdateTodoRequest{Text: stringPtr("Buy oat milk")}, false, false, 0},
+		{"todo opted out of analysis", UpdateTodoRequest{Text: stringPtr("Buy oat milk")}, true, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			jobQueue := &mockJobQueueForHandlers{}
+			handler := NewTodoHandler(nil, zap.NewNop(), WithTodoJobQueue(jobQueue), WithTodoReanalyzeOnTextChange(tt.reanalyze))
+			todo := &models.Todo{
+				ID:       uuid.New(),
+				UserID:   uuid.New(),
+				Text:     "Buy milk",
+				Status:   models.TodoStatusProcessed,
+				Metadata: models.Metadata{AnalysisDisabled: tt.analysisDisabled},
+			}
+			oldText := todo.Text
+			if err := applyUpdatesToTodo(todo, &tt.req); err != nil {
+				t.Fatalf("applyUpdatesToTodo() error = %v", err)
+			}
+
+			handler.enqueueReanalysisOnTextChange(context.Background(), todo, oldText)
+
+			if len(jobQueue.enqueueCalls) != tt.wantJobs {
+				t.Fatalf("enqueued %d jobs, want %d", len(jobQueue.enqueueCalls), tt.wantJobs)
+			}
+			if tt.wantJobs == 1 {
+				job := jobQueue.enqueueCalls[0]
+				if job.Type != queue.JobTypeTaskAnalysis || job.TodoID == nil || *job.TodoID != todo.ID {
+					t.Errorf("unexpected job %+v", job)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoHandler_DuplicateTodo(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	due := time.Date(2026, 3, 6, 17, 0, 0, 0, time.UTC)
+	completedAt := due.Add(-time.Hour)
+	override := true
+	source := &models.Todo{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Text:        "Send weekly report",
+		TimeHorizon: models.TimeHorizonNext,
+		Status:      models.TodoStatusCompleted,
+		DueDate:     &due,
+		CompletedAt: &completedAt,
+		Priority:    models.PriorityHigh,
+		Metadata: models.Metadata{
+			CategoryTags:            []string{"work", "reports"},
+			TagSources:              map[string]models.TagSource{"work": models.TagSourceUser, "reports": models.TagSourceAI},
+			TimeHorizonUserOverride: &override,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantDue    time.Time
+	}{
+		{"copy without shift", "", http.StatusCreated, due},
+		{"copy shifted by a week", "?shift=168h", http.StatusCreated, due.Add(7 * 24 * time.Hour)},
+		{"invalid shift", "?shift=week", http.StatusBadRequest, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var lookedUp struct{ userID, id uuid.UUID }
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				getByUserIDAndIDFunc: func(ctx context.Context, uid uuid.UUID, id uuid.UUID) (*models.Todo, error) {
+					lookedUp.userID, lookedUp.id = uid, id
+					return source, nil
+				},
+			}
+			jobQueue := &mockJobQueueForHandlers{}
+			handler := NewTodoHandler(todoRepo, zap.NewNop(), WithTodoJobQueue(jobQueue))
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/"+source.ID.String()+"/duplicate"+tt.query, nil)
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				if len(todoRepo.createCalls) != 0 {
+					t.Error("expected no todo created")
+				}
+				return
+			}
+			if lookedUp.userID != userID || lookedUp.id != source.ID {
+				t.Errorf("looked up todo %v for user %v, want ownership-scoped lookup", lookedUp.id, lookedUp.userID)
+			}
+			if len(todoRepo.createCalls) != 1 {
+				t.Fatalf("created %d todos, want 1", len(todoRepo.createCalls))
+			}
+			dup := todoRepo.createCalls[0]
+			if dup.ID == source.ID || dup.UserID != userID || dup.Text != source.Text {
+				t.Errorf("duplicate identity/text = %v/%v/%q", dup.ID, dup.UserID, dup.Text)
+			}
+			if dup.Status != models.TodoStatusPending || dup.CompletedAt != nil {
+				t.Errorf("duplicate status = %s, completed_at = %v; want pending and not completed", dup.Status, dup.CompletedAt)
+			}
+			if dup.DueDate == nil || !dup.DueDate.Equal(tt.wantDue) {
+				t.Errorf("duplicate due date = %v, want %v", dup.DueDate, tt.wantDue)
+			}
+			if dup.TimeHorizon != models.TimeHorizonNext {
+				t.Errorf("duplicate time horizon = %s, want user override preserved", dup.TimeHorizon)
+			}
+			for _, tag := range source.Metadata.CategoryTags {
+				if dup.Metadata.TagSources[tag] != models.TagSourceUser {
+					t.Errorf("tag %q source = %q, want user", tag, dup.Metadata.TagSources[tag])
+				}
+			}
+			if dup.Priority != models.PriorityHigh {
+				t.Errorf("duplicate priority = %q, want %q", dup.Priority, models.PriorityHigh)
+			}
+			if dup.Metadata.TimeEntered == nil || *dup.Metadata.TimeEntered == "" {
+				t.Error("expected fresh time_entered on duplicate")
+			}
+			if len(jobQueue.enqueueCalls) != 1 {
+				t.Fatalf("enqueued %d jobs, want 1", len(jobQueue.enqueueCalls))
+			}
+			job := jobQueue.enqueueCalls[0]
+			if job.Type != queue.JobTypeTaskAnalysis || job.TodoID == nil || *job.TodoID != dup.ID {
+				t.Errorf("unexpected job %+v", job)
+			}
+		})
+	}
+}
+
+func TestTodoHandler_UpdateTodo_StatusTransitions(t *testing.T) {
+	t.Parallel()
+
+	completedAt := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name       string
+		from       models.TodoStatus
+		body       string
+		wantStatus int
+		wantUpdate bool
+	}{
+		{"complete processed todo", models.TodoStatusProcessed, `{"status":"completed"}`, http.StatusOK, true},
+		{"complete pending todo", models.TodoStatusPending, `{"status":"completed"}`, http.StatusOK, true},
+		{"reopen completed todo", models.TodoStatusCompleted, `{"status":"pending"}`, http.StatusOK, true},
+		{"system status processed rejected", models.TodoStatusCompleted, `{"status":"processed"}`, http.StatusBadRequest, false},
+		{"system status processing rejected", models.TodoStatusPending, `{"status":"processing"}`, http.StatusBadRequest, false},
+		{"invalid status", models.TodoStatusPending, `{"status":"archived"}`, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todo := &models.Todo{ID: uuid.New(), UserID: userID, Text: "File taxes", Status: tt.from}
+			if tt.from == models.TodoStatusCompleted {
+				todo.CompletedAt = &completedAt
+			}
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				getByUserIDAndIDFunc: func(ctx context.Context, uid uuid.UUID, id uuid.UUID) (*models.Todo, error) {
+					return todo, nil
+				},
+			}
+			handler := NewTodoHandler(todoRepo, zap.NewNop())
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("PATCH", "/"+todo.ID.String(), strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := len(todoRepo.updateCalls) == 1; got != tt.wantUpdate {
+				t.Fatalf("updated = %v, want %v", got, tt.wantUpdate)
+			}
+			if !tt.wantUpdate {
+				return
+			}
+			updated := todoRepo.updateCalls[0]
+			if (updated.Status == models.TodoStatusCompleted) != (updated.CompletedAt != nil) {
+				t.Errorf("status %s with completed_at %v", updated.Status, updated.CompletedAt)
+			}
+			if tt.from == models.TodoStatusCompleted && updated.Status != models.TodoStatusProcessed {
+				t.Errorf("reopened status = %s, want processed", updated.Status)
+			}
+		})
+	}
+}
+
+func TestTodoHandler_StrictJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		method      string
+		body        string
+		strict      bool
+		wantStatus  int
+		wantMessage string
+	}{
+		{"lenient create ignores unknown field", "POST", `{"text":"Call mom","duedate":"2026-03-20"}`, false, http.StatusCreated, ""},
+		{"strict create rejects unknown field", "POST", `{"text":"Call mom","duedate":"2026-03-20"}`, true, http.StatusBadRequest, `unknown field "duedate"`},
+		{"strict create accepts known fields", "POST", `{"text":"Call mom","due_date":"2026-03-20"}`, true, http.StatusCreated, ""},
+		{"strict create rejects nested unknown field", "POST", `{"text":"Call mom","reminder_policy":{"ofsets":["1h"]}}`, true, http.StatusBadRequest, `unknown field "ofsets"`},
+		{"lenient update ignores unknown field", "PATCH", `{"text":"Call dad","time_horizn":"next"}`, false, http.StatusOK, ""},
+		{"strict update rejects unknown field", "PATCH", `{"text":"Call dad","time_horizn":"next"}`, true, http.StatusBadRequest, `unknown field "time_horizn"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todo := &models.Todo{ID: uuid.New(), UserID: userID, Text: "Call mom", Status: models.TodoStatusProcessed}
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				getByUserIDAndIDFunc: func(ctx context.Context, uid uuid.UUID, id uuid.UUID) (*models.Todo, error) {
+					return todo, nil
+				},
+			}
+			handler := NewTodoHandler(todoRepo, zap.NewNop(), WithTodoStrictJSON(tt.strict))
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			path := "/api/v1/todos"
+			if tt.method == "PATCH" {
+				path = "/" + todo.ID.String()
+			}
+			req := httptest.NewRequest(tt.method, path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			if tt.method == "POST" {
+				handler.CreateTodo(w, req)
+			} else {
+				router.ServeHTTP(w, req)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantMessage == "" {
+				return
+			}
+			var resp struct {
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if !strings.Contains(resp.Message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", resp.Message, tt.wantMessage)
+			}
+			if len(todoRepo.createCalls)+len(todoRepo.updateCalls) != 0 {
+				t.Error("a rejected request should not be saved")
+			}
+		})
+	}
+}
+
+func TestTodoHandler_StrictJSONActions(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New().String()
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"merge", "/" + id + "/merge", `{"source_id":"` + uuid.New().String() + `","reanalyse":true}`},
+		{"tag merge", "/tags/merge", `{"from":["work"],"into":"job"}`},
+		{"bulk due date", "/bulk/due-date", `{"ids":["` + id + `"],"operation":"shift","shift_by":"1d"}`},
+		{"bulk action", "/bulk", `{"action":"complete","todo_ids":["` + id + `"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todoRepo := &mockTodoRepoForHandlers{t: t}
+			handler := NewTodoHandler(todoRepo, zap.NewNop(), WithTodoStrictJSON(true))
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req = setUserInRequestContext(req, &models.User{ID: uuid.New()})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "unknown field") {
+				t.Errorf("status = %d, want 400 naming the unknown field: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTodoHandler_ResetAITags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		query       string
+		wantStatus  int
+		wantRequeue bool
+		wantJobs    int
+	}{
+		{"reanalyzes by default", "", http.StatusOK, true, 1},
+		{"reanalyze disabled", "?reanalyze=false", http.StatusOK, false, 0},
+		{"invalid reanalyze", "?reanalyze=maybe", http.StatusBadRequest, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todos := []*models.Todo{
+				{ID: uuid.New(), UserID: userID, Status: models.TodoStatusProcessed, Metadata: models.Metadata{
+					CategoryTags: []string{"work", "urgent"},
+					TagSources:   map[string]models.TagSource{"work": models.TagSourceUser, "urgent": models.TagSourceAI},
+				}},
+				{ID: uuid.New(), UserID: userID, Status: models.TodoStatusProcessed, Metadata: models.Metadata{
+					CategoryTags: []string{"home"},
+					TagSources:   map[string]models.TagSource{"home": models.TagSourceUser},
+				}},
+			}
+			var gotRequeue *bool
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				resetAITagsFunc: func(ctx context.Context, uid uuid.UUID, requeue bool) (int, error) {
+					if uid != userID {
+						t.Errorf("ResetAITags user = %v, want %v", uid, userID)
+					}
+					gotRequeue = &requeue
+					changed := 0
+					for _, todo := range todos {
+						if todo.Metadata.RemoveAITags() {
+							changed++
+						}
+					}
+					return changed, nil
+				},
+			}
+			jobQueue := &mockJobQueueForHandlers{}
+			handler := NewTodoHandler(todoRepo, zap.NewNop(), WithTodoJobQueue(jobQueue))
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/tags/reset-ai"+tt.query, nil)
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if len(jobQueue.enqueueCalls) != tt.wantJobs {
+				t.Fatalf("enqueued %d jobs, want %d", len(jobQueue.enqueueCalls), tt.wantJobs)
+			}
+			if tt.wantJobs == 1 && (jobQueue.enqueueCalls[0].Type != queue.JobTypeReprocessUser || jobQueue.enqueueCalls[0].UserID != userID) {
+				t.Errorf("unexpected job %+v", jobQueue.enqueueCalls[0])
+			}
+			if tt.wantStatus != http.StatusOK {
+				if gotRequeue != nil {
+					t.Error("expected ResetAITags not to be called")
+				}
+				return
+			}
+			if gotRequeue == nil || *gotRequeue != tt.wantRequeue {
+				t.Errorf("requeue = %v, want %v", gotRequeue, tt.wantRequeue)
+			}
+			if !slices.Equal(todos[0].Metadata.CategoryTags, []string{"work"}) || !slices.Equal(todos[1].Metadata.CategoryTags, []string{"home"}) {
+				t.Errorf("tags after reset = %v, %v; want user tags only", todos[0].Metadata.CategoryTags, todos[1].Metadata.CategoryTags)
+			}
+
+			var resp struct {
+				Data ResetAITagsResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Data.TodosUpdated != 1 || resp.Data.ReanalysisEnqueued != (tt.wantJobs == 1) {
+				t.Errorf("response = %+v", resp.Data)
+			}
+		})
+	}
+}
+
+type mockTodoHistoryRepo struct {
+	entries []*models.TodoHistoryEntry
+	calls   int
+}
+
+func (m *mockTodoHistoryRepo) ListByTodo(ctx context.Context, userID uuid.UUID, todoID uuid.UUID) ([]*models.TodoHistoryEntry, error) {
+	m.calls++
+	return m.entries, nil
+}
+
+func TestTodoHandler_GetTodoHistory(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	todoID := uuid.New()
+	entries := []*models.TodoHistoryEntry{
+		{ID: uuid.New(), TodoID: todoID, Actor: models.ChangeActorAI, Changes: []models.FieldChange{
+			{Field: models.HistoryFieldTags, Old: []string{}, New: []string{"work"}},
+		}},
+		{ID: uuid.New(), TodoID: todoID, Actor: models.ChangeActorUser, Changes: []models.FieldChange{
+			{Field: models.HistoryFieldText, Old: "a", New: "b"},
+		}},
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		found       bool
+		wantStatus  int
+		wantEntries int
+	}{
+		{"returns entries", "/" + todoID.String() + "/history", true, http.StatusOK, 2},
+		{"unknown todo", "/" + todoID.String() + "/history", false, http.StatusNotFound, 0},
+		{"invalid id", "/not-a-uuid/history", true, http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				getByUserIDAndIDFunc: func(ctx context.Context, uid, id uuid.UUID) (*models.Todo, error) {
+					if !tt.found {
+						return nil, database.ErrTodoNotFound
+					}
+					return &models.Todo{ID: id, UserID: uid}, nil
+				},
+			}
+			historyRepo := &mockTodoHistoryRepo{entries: entries}
+			handler := NewTodoHandler(todoRepo, zap.NewNop(), WithTodoHistoryRepo(historyRepo))
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if historyRepo.calls != 0 {
+					t.Error("expected ListByTodo not to be called")
+				}
+				return
+			}
+
+			var resp struct {
+				Data TodoHistoryResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Data.TodoID != todoID || len(resp.Data.Entries) != tt.wantEntries {
+				t.Fatalf("response = %+v", resp.Data)
+			}
+			if resp.Data.Entries[0].Actor != models.ChangeActorAI || resp.Data.Entries[1].Changes[0].Field != models.HistoryFieldText {
+				t.Errorf("unexpected entries %+v, %+v", resp.Data.Entries[0], resp.Data.Entries[1])
+			}
+		})
+	}
+}
+
+func TestTodoHandler_UpdateTodo_AttributesChangesToUser(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	var gotCtx context.Context
+	todoRepo := &mockTodoRepoForHandlers{
+		t: t,
+		getByUserIDAndIDFunc: func(ctx context.Context, uid, id uuid.UUID) (*models.Todo, error) {
+			return &models.Todo{ID: id, UserID: uid, Text: "old", Status: models.TodoStatusPending}, nil
+		},
+		updateFunc: func(ctx context.Context, todo *models.Todo, oldTags []string) error {
+			gotCtx = ctx
+			return nil
+		},
+	}
+	handler := NewTodoHandler(todoRepo, zap.NewNop(), WithTodoReanalyzeOnTextChange(false))
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest("PATCH", "/"+uuid.New().String(), strings.NewReader(`{"text":"new"}`))
+	req = setUserInRequestContext(req, &models.User{ID: userID})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if gotCtx == nil || database.ChangeActorFromContext(gotCtx) != models.ChangeActorUser {
+		t.Error("expected Update to be called with the user change actor")
+	}
+}
+
+func TestTodoHandler_UpdateTodo_IfMatch(t *testing.T) {
+	t.Parallel()
+
+	updatedAt := time.Date(2026, 3, 15, 12, 0, 0, 123456000, time.UTC)
+	current := fmt.Sprintf(`"%d"`, updatedAt.UnixMicro())
+	tests := []struct {
+		name         string
+		ifMatch      string
+		updateErr    error
+		wantStatus   int
+		wantUpdate   bool
+		wantExpected bool
+	}{
+		{name: "unconditional", wantStatus: http.StatusOK, wantUpdate: true},
+		{name: "current version", ifMatch: current, wantStatus: http.StatusOK, wantUpdate: true, wantExpected: true},
+		{name: "current version in a list", ifMatch: `"1", ` + current, wantStatus: http.StatusOK, wantUpdate: true, wantExpected: true},
+		{name: "any version", ifMatch: "*", wantStatus: http.StatusOK, wantUpdate: true, wantExpected: true},
+		{name: "stale version", ifMatch: `"1"`, wantStatus: http.StatusPreconditionFailed},
+		{name: "weak tag", ifMatch: "W/" + current, wantStatus: http.StatusPreconditionFailed},
+		{name: "modified before the write", ifMatch: current, updateErr: database.ErrConcurrentModification, wantStatus: http.StatusPreconditionFailed, wantUpdate: true, wantExpected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			var expected time.Time
+			var conditional bool
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				getByUserIDAndIDFunc: func(ctx context.Context, uid, id uuid.UUID) (*models.Todo, error) {
+					return &models.Todo{ID: id, UserID: uid, Text: "old", Status: models.TodoStatusPending, UpdatedAt: updatedAt}, nil
+				},
+				updateFunc: func(ctx context.Context, todo *models.Todo, oldTags []string) error {
+					expected, conditional = database.ExpectedUpdatedAtFromContext(ctx)
+					todo.UpdatedAt = updatedAt.Add(time.Second)
+					return tt.updateErr
+				},
+			}
+			handler := NewTodoHandler(todoRepo, zap.NewNop(), WithTodoReanalyzeOnTextChange(false))
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("PATCH", "/"+uuid.New().String(), strings.NewReader(`{"text":"new"}`))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, setUserInRequestContext(req, &models.User{ID: userID}))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if updated := len(todoRepo.updateCalls) == 1; updated != tt.wantUpdate {
+				t.Errorf("updated = %v, want %v", updated, tt.wantUpdate)
+			}
+			if conditional != tt.wantExpected || (conditional && !expected.Equal(updatedAt)) {
+				t.Errorf("expected updated_at = %v (%v), want %v (%v)", expected, conditional, updatedAt, tt.wantExpected)
+			}
+			wantETag := ""
+			if tt.wantStatus == http.StatusOK {
+				wantETag = fmt.Sprintf(`"%d"`, updatedAt.Add(time.Second).UnixMicro())
+			}
+			if got := w.Header().Get("ETag"); got != wantETag {
+				t.Errorf("ETag = %q, want %q", got, wantETag)
+			}
+		})
+	}
+}
+
+func TestTodoHandler_GetTodo_ETag(t *testing.T) {
+	t.Parallel()
+
+	updatedAt := time.Date(2026, 3, 15, 12, 0, 0, 123456000, time.UTC)
+	todoRepo := &mockTodoRepoForHandlers{
+		t: t,
+		getByUserIDAndIDFunc: func(ctx context.Context, uid, id uuid.UUID) (*models.Todo, error) {
+			return &models.Todo{ID: id, UserID: uid, Text: "Plan trip", UpdatedAt: updatedAt}, nil
+		},
+	}
+	handler := NewTodoHandler(todoRepo, zap.NewNop())
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest("GET", "/"+uuid.New().String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, setUserInRequestContext(req, &models.User{ID: uuid.New()}))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if got, want := w.Header().Get("ETag"), fmt.Sprintf(`"%d"`, updatedAt.UnixMicro()); got != want {
+		t.Errorf("ETag = %q, want %q", got, want)
+	}
+}
+
+type stubManualAnalysisLimiter struct {
+	ok   bool
+	next time.Time
+	err  error
+}
+
+func (s *stubManualAnalysisLimiter) Reserve(ctx context.Context, userID uuid.UUID) (bool, time.Time, error) {
+	return s.ok, s.next, s.err
+}
+
+func TestTodoHandler_AnalyzeTodo_ManualLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		limiter    *stubManualAnalysisLimiter
+		wantStatus int
+		wantJobs   int
+	}{
+		{"no limiter configured", nil, http.StatusAccepted, 1},
+		{"slot available", &stubManualAnalysisLimiter{ok: true}, http.StatusAccepted, 1},
+		{"too soon after previous request", &stubManualAnalysisLimiter{next: time.Now().Add(90 * time.Second)}, http.StatusTooManyRequests, 0},
+		{"limiter error fails open", &stubManualAnalysisLimiter{err: fmt.Errorf("redis down")}, http.StatusAccepted, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todo := &models.Todo{ID: uuid.New(), UserID: userID, Text: "Plan trip", Status: models.TodoStatusProcessed}
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				getByUserIDAndIDFunc: func(ctx context.Context, uid uuid.UUID, id uuid.UUID) (*models.Todo, error) {
+					return todo, nil
+				},
+			}
+			jobQueue := &mockJobQueueForHandlers{}
+			opts := []TodoHandlerOption{WithTodoJobQueue(jobQueue)}
+			if tt.limiter != nil {
+				opts = append(opts, WithTodoManualAnalysisLimiter(tt.limiter))
+			}
+			handler := NewTodoHandler(todoRepo, zap.NewNop(), opts...)
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/"+todo.ID.String()+"/analyze", nil)
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if len(jobQueue.enqueueCalls) != tt.wantJobs {
+				t.Fatalf("enqueued %d jobs, want %d", len(jobQueue.enqueueCalls), tt.wantJobs)
+			}
+			if tt.wantStatus == http.StatusTooManyRequests {
+				if got := w.Header().Get("Retry-After"); got != "90" {
+					t.Errorf("Retry-After = %q, want 90", got)
+				}
+				return
+			}
+			if manual, _ := jobQueue.enqueueCalls[0].Metadata[queue.MetadataManualAnalysis].(bool); !manual {
+				t.Error("expected manual analysis job to be marked manual")
+			}
+		})
+	}
+}
+
+func TestTodoHandler_AnalyzeTodo_UseTagStats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantSkip   bool
+	}{
+		{"default uses tag stats", "", http.StatusAccepted, false},
+		{"explicit true", "?use_tag_stats=true", http.StatusAccepted, false},
+		{"disabled", "?use_tag_stats=false", http.StatusAccepted, true},
+		{"invalid value", "?use_tag_stats=maybe", http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todo := &models.Todo{ID: uuid.New(), UserID: userID, Text: "Plan trip", Status: models.TodoStatusProcessed}
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				getByUserIDAndIDFunc: func(ctx context.Context, uid uuid.UUID, id uuid.UUID) (*models.Todo, error) {
+					return todo, nil
+				},
+			}
+			jobQueue := &mockJobQueueForHandlers{}
+			handler := NewTodoHandler(todoRepo, zap.NewNop(), WithTodoJobQueue(jobQueue))
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/"+todo.ID.String()+"/analyze"+tt.query, nil)
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusAccepted {
+				if len(jobQueue.enqueueCalls) != 0 {
+					t.Error("expected no job for an invalid request")
+				}
+				return
+			}
+			if len(jobQueue.enqueueCalls) != 1 {
+				t.Fatalf("enqueued %d jobs, want 1", len(jobQueue.enqueueCalls))
+			}
+			skip, _ := jobQueue.enqueueCalls[0].Metadata[queue.MetadataSkipTagStats].(bool)
+			if skip != tt.wantSkip {
+				t.Errorf("skip_tag_stats = %v, want %v", skip, tt.wantSkip)
+			}
+		})
+	}
+}
+
+type stubQueueStats struct {
+	depth int
+}
+
+func (s stubQueueStats) QueueStats(ctx context.Context) (queue.QueueStats, error) {
+	return queue.QueueStats{Depth: s.depth}, nil
+}
+
+func TestTodoHandler_AnalyzeTodo_Backpressure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		depth       int
+		mode        queue.BackpressureMode
+		wantStatus  int
+		wantJob     bool
+		wantDelayed bool
+	}{
+		{name: "below watermark", depth: 3, mode: queue.BackpressureModeSkip, wantStatus: http.StatusAccepted, wantJob: true},
+		{name: "over watermark skips", depth: 10, mode: queue.BackpressureModeSkip, wantStatus: http.StatusServiceUnavailable},
+		{name: "over watermark delays", depth: 10, mode: queue.BackpressureModeDelay, wantStatus: http.StatusAccepted, wantJob: true, wantDelayed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todo := &models.Todo{ID: uuid.New(), UserID: userID, Text: "Plan trip", Status: models.TodoStatusProcessed}
+			todoRepo := &mockTodoRepoForHandlers{
+				t: t,
+				getByUserIDAndIDFunc: func(ctx context.Context, uid uuid.UUID, id uuid.UUID) (*models.Todo, error) {
+					return todo, nil
+				},
+			}
+			jobQueue := &mockJobQueueForHandlers{}
+			bp := queue.NewBackpressure(stubQueueStats{depth: tt.depth}, 10, tt.mode, time.Minute)
+			handler := NewTodoHandler(todoRepo, zap.NewNop(), WithTodoJobQueue(jobQueue), WithTodoBackpressure(bp))
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/"+todo.ID.String()+"/analyze", nil)
+			req = setUserInRequestContext(req, &models.User{ID: userID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := len(jobQueue.enqueueCalls) == 1; got != tt.wantJob {
+				t.Fatalf("enqueued %d jobs, want job = %v", len(jobQueue.enqueueCalls), tt.wantJob)
+			}
+			if tt.wantStatus == http.StatusServiceUnavailable && w.Header().Get("Retry-After") == "" {
+				t.Error("a busy queue should send Retry-After")
+			}
+			if tt.wantJob && (jobQueue.enqueueCalls[0].NotBefore != nil) != tt.wantDelayed {
+				t.Errorf("not_before = %v, want delayed = %v", jobQueue.enqueueCalls[0].NotBefore, tt.wantDelayed)
+			}
+		})
+	}
+}
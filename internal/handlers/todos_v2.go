@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/database"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// TodoV2Handler serves the /api/v2 todo routes. It shares TodoHandler's listing logic and differs only in
+// request and response shaping: responses are not wrapped in the v1 envelope and listings use cursor
+// pagination unless ?page= is given.
+type TodoV2Handler struct {
+	todos *TodoHandler
+}
+
+// NewTodoV2Handler creates a v2 todo handler backed by the same TodoHandler that serves v1
+func NewTodoV2Handler(todos *TodoHandler) *TodoV2Handler {
+	return &TodoV2Handler{todos: todos}
+}
+
+// RegisterRoutes registers v2 todo routes on the given router
+// The router should already have the /todos prefix (e.g., from apiV2Router.PathPrefix("/todos"))
+func (h *TodoV2Handler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("", h.ListTodos).Methods("GET")
+}
+
+// ListTodosV2Response is the v2 todo listing. NextCursor is set when more todos follow; Page and Total
+// are only set when ?page= selects offset pagination.
+type ListTodosV2Response struct {
+	Todos      []*models.Todo `json:"todos"`
+	PageSize   int            `json:"page_size"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	Page       *int           `json:"page,omitempty"`
+	Total      *int           `json:"total,omitempty"`
+}
+
+// ListTodos lists todos for the authenticated user, newest first. Pass next_cursor back as ?cursor= for
+// the following page.
+func (h *TodoV2Handler) ListTodos(w http.ResponseWriter, r *http.Request) {
+	user := request.UserFromContext(r)
+	if user == nil {
+		respondJSONError(w, http.StatusUnauthorized, "Unauthorized", "User not found in context")
+		return
+	}
+	q, err := parseTodoListQueryV2(r)
+	if err != nil {
+		respondJSONError(w, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+	result, err := h.todos.listTodos(r.Context(), user.ID, q)
+	if err != nil {
+		respondJSONError(w, http.StatusInternalServerError, "Internal Server Error", "Failed to retrieve todos")
+		return
+	}
+
+	resp := ListTodosV2Response{Todos: result.todos, PageSize: q.params.pageSize}
+	if resp.Todos == nil {
+		resp.Todos = []*models.Todo{}
+	}
+	if q.cursorMode {
+		resp.NextCursor = encodeTodoCursor(result.next)
+	} else {
+		resp.Page, resp.Total = &q.params.page, &result.total
+	}
+	respondJSONUnwrapped(w, http.StatusOK, resp)
+}
+
+// parseTodoListQueryV2 parses v2 listing params: the v1 filters, with cursor pagination by default
+func parseTodoListQueryV2(r *http.Request) (todoListQuery, error) {
+	params, err := parseListParams(r)
+	if err != nil {
+		return todoListQuery{}, err
+	}
+	query := r.URL.Query()
+	cursor := query.Get("cursor")
+	if query.Get("page") != "" {
+		if cursor != "" {
+			return todoListQuery{}, errors.New("cursor and page cannot be combined")
+		}
+		return todoListQuery{params: params}, nil
+	}
+	return cursorTodoListQuery(params, cursor)
+}
+
+// cursorTodoListQuery returns a cursor-mode listing resuming after cursor, or from the start when cursor is ""
+func cursorTodoListQuery(params listParams, cursor string) (todoListQuery, error) {
+	if !params.sort.NewestFirst() {
+		return todoListQuery{}, errors.New("sort and order other than newest first require page pagination")
+	}
+	q := todoListQuery{params: params, cursorMode: true}
+	if cursor != "" {
+		var err error
+		if q.after, err = decodeTodoCursor(cursor); err != nil {
+			return todoListQuery{}, err
+		}
+	}
+	return q, nil
+}
+
+// errInvalidCursor is returned for cursors not produced by encodeTodoCursor
+var errInvalidCursor = errors.New("invalid cursor")
+
+// encodeTodoCursor renders c as an opaque URL-safe token, or "" when c is nil. The token is the unpadded
+// URL-safe base64 of the RFC 3339 created_at and the ID joined by "|"; clients must not rely on that.
+func encodeTodoCursor(c *database.TodoCursor) string {
+	if c == nil {
+		return ""
+	}
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeTodoCursor parses a token produced by encodeTodoCursor
+func decodeTodoCursor(s string) (*database.TodoCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, errInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	todoID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	return &database.TodoCursor{CreatedAt: createdAt, ID: todoID}, nil
+}
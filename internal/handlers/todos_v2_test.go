@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/database"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// newVersionedTodoRouter mounts v1 and v2 todo routes over the same repository, as the server does
+func newVersionedTodoRouter(t *testing.T, user *models.User, todos []*models.Todo) *mux.Router {
+	t.Helper()
+	repo := &mockTodoRepoForHandlers{
+		t: t,
+		listByUserIDFunc: func(ctx context.Context, userID uuid.UUID, filter database.TodoListFilter, page, pageSize int) ([]*models.Todo, int, error) {
+			start := min((page-1)*pageSize, len(todos))
+			return todos[start:min(start+pageSize, len(todos))], len(todos), nil
+		},
+		listByUserIDAfterFunc: func(ctx context.Context, userID uuid.UUID, filter database.TodoListFilter, after *database.TodoCursor, limit int) ([]*models.Todo, error) {
+			start := 0
+			if after != nil {
+				for i, todo := range todos {
+					if todo.ID == after.ID {
+						start = i + 1
+					}
+				}
+			}
+			return todos[start:min(start+limit, len(todos))], nil
+		},
+	}
+	todoHandler := NewTodoHandler(repo, zap.NewNop())
+
+	r := mux.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, setUserInRequestContext(req, user))
+		})
+	})
+	todoHandler.RegisterRoutes(r.PathPrefix("/api/v1/todos").Subrouter())
+	NewTodoV2Handler(todoHandler).RegisterRoutes(r.PathPrefix("/api/v2/todos").Subrouter())
+	return r
+}
+
+func getJSON(t *testing.T, r http.Handler, path string, out any) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+		t.Fatalf("GET %s: decode response: %v (%s)", path, err, w.Body.String())
+	}
+	return w.Code
+}
+
+func TestTodoAPIVersions_Coexist(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: uuid.New()}
+	base := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	todos := make([]*models.Todo, 3)
+	for i := range todos {
+		todos[i] = &models.Todo{ID: uuid.New(), UserID: user.ID, Text: "todo", Status: models.TodoStatusPending, CreatedAt: base.Add(-time.Duration(i) * time.Hour)}
+	}
+	router := newVersionedTodoRouter(t, user, todos)
+
+	// v1 keeps the envelope and page-based pagination
+	var v1 struct {
+		Success bool              `json:"success"`
+		Data    ListTodosResponse `json:"data"`
+	}
+	if code := getJSON(t, router, "/api/v1/todos?page_size=2", &v1); code != http.StatusOK {
+		t.Fatalf("v1 status = %d", code)
+	}
+	if !v1.Success || len(v1.Data.Todos) != 2 || v1.Data.Total != 3 || v1.Data.TotalPages != 2 || v1.Data.Page != 1 {
+		t.Errorf("v1 response = %+v", v1)
+	}
+
+	// v2 returns the listing itself with a cursor to the next page
+	var raw map[string]json.RawMessage
+	if code := getJSON(t, router, "/api/v2/todos?page_size=2", &raw); code != http.StatusOK {
+		t.Fatalf("v2 status = %d", code)
+	}
+	for _, key := range []string{"success", "data", "timestamp", "page", "total"} {
+		if _, ok := raw[key]; ok {
+			t.Errorf("v2 response has unexpected %q field", key)
+		}
+	}
+	var v2 ListTodosV2Response
+	getJSON(t, router, "/api/v2/todos?page_size=2", &v2)
+	if len(v2.Todos) != 2 || v2.Todos[0].ID != v1.Data.Todos[0].ID || v2.Todos[1].ID != v1.Data.Todos[1].ID || v2.NextCursor == "" {
+		t.Fatalf("v2 first page = %+v", v2)
+	}
+
+	var page2 ListTodosV2Response
+	getJSON(t, router, "/api/v2/todos?page_size=2&cursor="+v2.NextCursor, &page2)
+	if len(page2.Todos) != 1 || page2.Todos[0].ID != todos[2].ID || page2.NextCursor != "" {
+		t.Errorf("v2 second page = %+v", page2)
+	}
+
+	// v2 still supports page-based pagination on request
+	var paged ListTodosV2Response
+	getJSON(t, router, "/api/v2/todos?page=2&page_size=2", &paged)
+	if paged.Page == nil || *paged.Page != 2 || paged.Total == nil || *paged.Total != 3 || len(paged.Todos) != 1 || paged.NextCursor != "" {
+		t.Errorf("v2 paged response = %+v", paged)
+	}
+}
+
+func TestTodoHandler_ListTodos_Cursor(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: uuid.New()}
+	base := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	todos := make([]*models.Todo, 5)
+	for i := range todos {
+		todos[i] = &models.Todo{ID: uuid.New(), UserID: user.ID, Text: "todo", Status: models.TodoStatusPending, CreatedAt: base.Add(-time.Duration(i) * time.Hour)}
+	}
+	router := newVersionedTodoRouter(t, user, todos)
+
+	// An empty ?cursor= starts the cursor variant of the v1 listing; pages keep the envelope
+	var seen []uuid.UUID
+	path := "/api/v1/todos?page_size=2&cursor="
+	for pages := 0; ; pages++ {
+		if pages == len(todos) {
+			t.Fatalf("cursor pagination did not end after %d pages", pages)
+		}
+		var resp struct {
+			Success bool                    `json:"success"`
+			Data    ListTodosCursorResponse `json:"data"`
+		}
+		if code := getJSON(t, router, path, &resp); code != http.StatusOK || !resp.Success {
+			t.Fatalf("GET %s: status = %d, response = %+v", path, code, resp)
+		}
+		if resp.Data.PageSize != 2 || len(resp.Data.Todos) > 2 {
+			t.Errorf("GET %s: page = %+v, want at most 2 todos", path, resp.Data)
+		}
+		for _, todo := range resp.Data.Todos {
+			seen = append(seen, todo.ID)
+		}
+		if resp.Data.NextCursor == "" {
+			break
+		}
+		path = "/api/v1/todos?page_size=2&cursor=" + resp.Data.NextCursor
+	}
+	if len(seen) != len(todos) {
+		t.Fatalf("listed %d todos over all pages, want %d", len(seen), len(todos))
+	}
+	for i, id := range seen {
+		if id != todos[i].ID {
+			t.Errorf("todo %d = %v, want %v", i, id, todos[i].ID)
+		}
+	}
+
+	for _, bad := range []string{
+		"/api/v1/todos?cursor=not-base64!",
+		"/api/v1/todos?page=1&cursor=",
+		"/api/v1/todos?cursor=&sort=due_date",
+	} {
+		var resp map[string]any
+		if code := getJSON(t, router, bad, &resp); code != http.StatusBadRequest {
+			t.Errorf("GET %s: status = %d, want 400 (%v)", bad, code, resp)
+		}
+	}
+}
+
+func TestTodoV2Handler_ListTodos_BadRequests(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: uuid.New()}
+	router := newVersionedTodoRouter(t, user, nil)
+	validCursor := encodeTodoCursor(&database.TodoCursor{CreatedAt: time.Now(), ID: uuid.New()})
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"malformed cursor", "/api/v2/todos?cursor=not-base64!"},
+		{"cursor without separator", "/api/v2/todos?cursor=" + base64.RawURLEncoding.EncodeToString([]byte("garbage"))},
+		{"cursor with bad id", "/api/v2/todos?cursor=" + base64.RawURLEncoding.EncodeToString([]byte("2026-03-15T12:00:00Z|nope"))},
+		{"cursor and page", "/api/v2/todos?page=1&cursor=" + validCursor},
+		{"invalid status filter", "/api/v2/todos?status=done"},
+		{"invalid sort", "/api/v2/todos?page=1&sort=text"},
+		{"sort with cursor pagination", "/api/v2/todos?sort=due_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var resp map[string]any
+			if code := getJSON(t, router, tt.path, &resp); code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400 (%v)", code, resp)
+			}
+		})
+	}
+}
+
+func TestTodoCursor_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	want := &database.TodoCursor{
+		CreatedAt: time.Date(2026, 3, 15, 12, 0, 0, 123456000, time.FixedZone("EST", -5*3600)),
+		ID:        uuid.New(),
+	}
+	got, err := decodeTodoCursor(encodeTodoCursor(want))
+	if err != nil {
+		t.Fatalf("decodeTodoCursor() error = %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("decodeTodoCursor() = %+v, want %+v", got, want)
+	}
+	if encodeTodoCursor(nil) != "" {
+		t.Error("encodeTodoCursor(nil) should be empty")
+	}
+}
@@ -0,0 +1,30 @@
+package metrics
+
+// Default is the registry the server and worker serve on /metrics when ENABLE_METRICS is set. The
+// instruments below are always updated; serving them is what the flag turns on.
+var Default = NewRegistry()
+
+var (
+	// HTTPRequestDuration times API requests by method, route template and status code
+	HTTPRequestDuration = Default.NewHistogramVec("http_request_duration_seconds",
+		"Duration of HTTP requests by method, route template and status code", DefaultDurationBuckets,
+		"method", "route", "status")
+	// JobsEnqueued counts jobs published to the queue by job type
+	JobsEnqueued = Default.NewCounterVec("jobs_enqueued_total", "Jobs published to the queue by job type", "job_type")
+	// JobsProcessed counts jobs whose processor finished without error by job type
+	JobsProcessed = Default.NewCounterVec("jobs_processed_total", "Jobs processed without error by job type", "job_type")
+	// JobsFailed counts jobs whose processor returned an error by job type
+	JobsFailed = Default.NewCounterVec("jobs_failed_total", "Jobs whose processor returned an error by job type", "job_type")
+	// QueueDepth is the number of jobs ready in the main queue, as last read by the worker's queue stats loop
+	QueueDepth = Default.NewGaugeVec("queue_depth", "Jobs ready in the main queue, as last read by the worker")
+	// QueueConsumers is the number of consumers on the main queue, as last read by the worker's queue stats loop
+	QueueConsumers = Default.NewGaugeVec("queue_consumers", "Consumers on the main queue, as last read by the worker")
+	// AICallDuration times AI provider calls, including in-call retries, by provider, operation and outcome
+	// ("ok" or the ai.AIErrorKind of the failure)
+	AICallDuration = Default.NewHistogramVec("ai_call_duration_seconds",
+		"Duration of AI provider calls by provider, operation and outcome", DefaultDurationBuckets,
+		"provider", "operation", "outcome")
+	// DBSlowQueries counts repository queries that took at least DB_SLOW_QUERY_THRESHOLD by query name
+	DBSlowQueries = Default.NewCounterVec("db_slow_queries_total",
+		"Repository queries that exceeded the slow-query threshold by query name", "query")
+)
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// UnmatchedRoute is the route label for requests no route matched, so unknown paths cannot create
+// unbounded series
+const UnmatchedRoute = "unmatched"
+
+// Middleware records the duration of each request in durations, labelled by method, route template (not
+// the raw path, which contains IDs) and status code
+func Middleware(durations *HistogramVec) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			// Wrap ResponseWriter to capture status code
+			wrapped := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+
+			next.ServeHTTP(wrapped, r)
+
+			durations.ObserveDuration(start, r.Method, routeTemplate(r), strconv.Itoa(wrapped.statusCode))
+		})
+	}
+}
+
+// routeTemplate returns the path template of the matched route, or UnmatchedRoute
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return UnmatchedRoute
+	}
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return UnmatchedRoute
+	}
+	return tmpl
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (rw *statusRecorder) WriteHeader(code int) {
+	rw.statusCode = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer (e.g. to flush a stream)
+func (rw *statusRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
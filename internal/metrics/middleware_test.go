@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	durations := NewRegistry().NewHistogramVec("http_seconds", "HTTP", DefaultDurationBuckets, "method", "route", "status")
+	r := mux.NewRouter()
+	r.Use(Middleware(durations))
+	r.HandleFunc("/todos/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodGet)
+	r.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodGet)
+	for _, path := range []string{"/todos/1", "/todos/2", "/healthz"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	// Outside a router there is no route template
+	Middleware(durations)(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	tests := []struct {
+		route, status string
+		want          uint64
+	}{
+		{"/todos/{id}", "404", 2},
+		{"/healthz", "200", 1},
+		{UnmatchedRoute, "404", 1},
+	}
+	for _, tt := range tests {
+		if got := durations.Count(http.MethodGet, tt.route, tt.status); got != tt.want {
+			t.Errorf("Count(GET, %s, %s) = %d, want %d", tt.route, tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+// Package metrics keeps counters, histograms and gauges in memory and serves them in the Prometheus text
+// exposition format
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Namespace prefixes every metric name
+const Namespace = "smart_todo"
+
+// gaugeTimeout bounds each gauge function during a scrape
+const gaugeTimeout = 2 * time.Second
+
+// DefaultDurationBuckets are histogram upper bounds in seconds, from fast API requests to slow AI calls
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// collector writes one metric family in the text format
+type collector interface {
+	name() string
+	write(ctx context.Context, w *bufio.Writer)
+}
+
+// Registry holds the metrics served by Handler
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// register adds c; registering a name twice panics, as metrics are registered once at startup
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.collectors {
+		if existing.name() == c.name() {
+			panic(fmt.Sprintf("metric %q registered twice", c.name()))
+		}
+	}
+	r.collectors = append(r.collectors, c)
+}
+
+// NewCounterVec registers a counter named Namespace_name with the given label names
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{family: newFamily(name, help, labels), values: make(map[string]*counterValue)}
+	r.register(c)
+	return c
+}
+
+// NewGaugeVec registers a gauge named Namespace_name with the given label names, set by its owner
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{family: newFamily(name, help, labels), values: make(map[string]*counterValue)}
+	r.register(g)
+	return g
+}
+
+// NewHistogramVec registers a histogram named Namespace_name with the given bucket upper bounds (sorted
+// ascending; +Inf is implied) and label names
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{family: newFamily(name, help, labels), buckets: buckets, values: make(map[string]*histogramValue)}
+	r.register(h)
+	return h
+}
+
+// NewGaugeFunc registers a gauge named Namespace_name whose value is read from fn at scrape time. When fn
+// fails the gauge is left out of that scrape.
+func (r *Registry) NewGaugeFunc(name, help string, fn func(ctx context.Context) (float64, error)) {
+	r.register(&gaugeFunc{family: newFamily(name, help, nil), fn: fn})
+}
+
+// Handler serves every registered metric in the Prometheus text format, sorted by name
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		collectors := slices.Clone(r.collectors)
+		r.mu.Unlock()
+		slices.SortFunc(collectors, func(a, b collector) int { return strings.Compare(a.name(), b.name()) })
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		for _, c := range collectors {
+			c.write(req.Context(), bw)
+		}
+		_ = bw.Flush()
+	})
+}
+
+// family is the name, help text and label names shared by every series of a metric
+type family struct {
+	fullName string
+	help     string
+	labels   []string
+}
+
+func newFamily(name, help string, labels []string) family {
+	return family{fullName: Namespace + "_" + name, help: help, labels: labels}
+}
+
+func (f family) name() string { return f.fullName }
+
+func (f family) writeHeader(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.fullName, escapeHelp(f.help), f.fullName, kind)
+}
+
+// key joins label values into a map key; it panics on a label count mismatch, which is a programming error
+func (f family) key(values []string) string {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metric %q takes %d label values, got %d", f.fullName, len(f.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labelPairs renders the labels for values plus any extra pairs, e.g. `{route="/x",le="0.5"}`
+func (f family) labelPairs(values []string, extra ...string) string {
+	if len(values) == 0 && len(extra) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(values)+len(extra)/2)
+	for i, v := range values {
+		pairs = append(pairs, f.labels[i]+`="`+escapeLabel(v)+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabel(extra[i+1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// CounterVec is a counter partitioned by label values
+type CounterVec struct {
+	family
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+// counterValue is one series of a counter or gauge
+type counterValue struct {
+	labels []string
+	value  float64
+}
+
+// Inc adds one to the series for labelValues
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta (which must not be negative) to the series for labelValues
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	key := c.key(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.values[key]
+	if !ok {
+		v = &counterValue{labels: slices.Clone(labelValues)}
+		c.values[key] = v
+	}
+	v.value += delta
+}
+
+// Value returns the current value of the series for labelValues
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	key := c.key(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := c.values[key]; ok {
+		return v.value
+	}
+	return 0
+}
+
+// Values returns the current value of every series, keyed by its label values joined with commas
+func (c *CounterVec) Values() map[string]float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	values := make(map[string]float64, len(c.values))
+	for _, v := range c.values {
+		values[strings.Join(v.labels, ",")] = v.value
+	}
+	return values
+}
+
+func (c *CounterVec) write(_ context.Context, w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	writeValues(w, c.family, "counter", c.values)
+}
+
+// GaugeVec is a gauge partitioned by label values whose owner sets its current value
+type GaugeVec struct {
+	family
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+// Set sets the series for labelValues to v
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	key := g.key(labelValues)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if existing, ok := g.values[key]; ok {
+		existing.value = v
+		return
+	}
+	g.values[key] = &counterValue{labels: slices.Clone(labelValues), value: v}
+}
+
+// Value returns the current value of the series for labelValues
+func (g *GaugeVec) Value(labelValues ...string) float64 {
+	key := g.key(labelValues)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if v, ok := g.values[key]; ok {
+		return v.value
+	}
+	return 0
+}
+
+func (g *GaugeVec) write(_ context.Context, w *bufio.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	writeValues(w, g.family, "gauge", g.values)
+}
+
+// writeValues writes a family of single-value series sorted by label values
+func writeValues(w *bufio.Writer, f family, kind string, values map[string]*counterValue) {
+	f.writeHeader(w, kind)
+	for _, key := range sortedKeys(values) {
+		v := values[key]
+		fmt.Fprintf(w, "%s%s %s\n", f.fullName, f.labelPairs(v.labels), formatFloat(v.value))
+	}
+}
+
+// HistogramVec is a histogram partitioned by label values
+type HistogramVec struct {
+	family
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labels []string
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// Observe records v in the series for labelValues
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hv, ok := h.values[key]
+	if !ok {
+		hv = &histogramValue{labels: slices.Clone(labelValues), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hv
+	}
+	if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+		hv.counts[i]++
+	}
+	hv.count++
+	hv.sum += v
+}
+
+// ObserveDuration records the time since start, in seconds
+func (h *HistogramVec) ObserveDuration(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// Count returns how many observations the series for labelValues has
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	key := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if hv, ok := h.values[key]; ok {
+		return hv.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(_ context.Context, w *bufio.Writer) {
+	h.writeHeader(w, "histogram")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.values) {
+		hv := h.values[key]
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += hv.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.fullName, h.labelPairs(hv.labels, "le", formatFloat(upper)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.fullName, h.labelPairs(hv.labels, "le", "+Inf"), hv.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.fullName, h.labelPairs(hv.labels), formatFloat(hv.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.fullName, h.labelPairs(hv.labels), hv.count)
+	}
+}
+
+// gaugeFunc is a gauge read at scrape time
+type gaugeFunc struct {
+	family
+	fn func(ctx context.Context) (float64, error)
+}
+
+func (g *gaugeFunc) write(ctx context.Context, w *bufio.Writer) {
+	ctx, cancel := context.WithTimeout(ctx, gaugeTimeout)
+	defer cancel()
+	v, err := g.fn(ctx)
+	if err != nil {
+		return
+	}
+	g.writeHeader(w, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.fullName, formatFloat(v))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func scrape(t *testing.T, registry *Registry) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the Prometheus text format", ct)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	jobs := registry.NewCounterVec("jobs_total", "Jobs by type", "job_type")
+	durations := registry.NewHistogramVec("call_seconds", "Call duration", []float64{0.1, 1}, "provider")
+	registry.NewGaugeFunc("dlq_depth", "Dead-lettered jobs", func(ctx context.Context) (float64, error) { return 7, nil })
+	registry.NewGaugeFunc("broken", "Unavailable gauge", func(ctx context.Context) (float64, error) { return 0, errors.New("down") })
+
+	jobs.Inc("task_analysis")
+	jobs.Add(2, "task_analysis")
+	jobs.Inc(`we"ird`)
+	durations.Observe(0.05, "openai")
+	durations.Observe(0.1, "openai")
+	durations.Observe(3, "openai")
+
+	got := scrape(t, registry)
+	for _, want := range []string{
+		"# HELP smart_todo_jobs_total Jobs by type\n# TYPE smart_todo_jobs_total counter\n",
+		`smart_todo_jobs_total{job_type="task_analysis"} 3` + "\n",
+		`smart_todo_jobs_total{job_type="we\"ird"} 1` + "\n",
+		"# TYPE smart_todo_call_seconds histogram\n",
+		`smart_todo_call_seconds_bucket{provider="openai",le="0.1"} 2` + "\n",
+		`smart_todo_call_seconds_bucket{provider="openai",le="1"} 2` + "\n",
+		`smart_todo_call_seconds_bucket{provider="openai",le="+Inf"} 3` + "\n",
+		`smart_todo_call_seconds_sum{provider="openai"} 3.15` + "\n",
+		`smart_todo_call_seconds_count{provider="openai"} 3` + "\n",
+		"# TYPE smart_todo_dlq_depth gauge\nsmart_todo_dlq_depth 7\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("scrape missing %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "smart_todo_broken") {
+		t.Errorf("scrape includes a failed gauge:\n%s", got)
+	}
+	if strings.Index(got, "smart_todo_call_seconds") > strings.Index(got, "smart_todo_jobs_total") {
+		t.Errorf("metrics not sorted by name:\n%s", got)
+	}
+}
+
+func TestRegistry_DuplicateNamePanics(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.NewCounterVec("jobs_total", "Jobs")
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice did not panic")
+		}
+	}()
+	registry.NewCounterVec("jobs_total", "Jobs again")
+}
+
+func TestCounterVec_Value(t *testing.T) {
+	t.Parallel()
+
+	counter := NewRegistry().NewCounterVec("c", "c", "a", "b")
+	counter.Inc("x", "y")
+	counter.Add(-1, "x", "y") // counters never go down
+	if got := counter.Value("x", "y"); got != 1 {
+		t.Errorf("Value(x, y) = %v, want 1", got)
+	}
+	if got := counter.Value("y", "x"); got != 0 {
+		t.Errorf("Value(y, x) = %v, want 0", got)
+	}
+}
+
+func TestGaugeVec_Set(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	depth := registry.NewGaugeVec("queue_depth", "Ready jobs")
+	depth.Set(12)
+	depth.Set(5) // gauges replace rather than add
+	if got := depth.Value(); got != 5 {
+		t.Errorf("Value() = %v, want 5", got)
+	}
+	if got := scrape(t, registry); !strings.Contains(got, "# TYPE smart_todo_queue_depth gauge\nsmart_todo_queue_depth 5\n") {
+		t.Errorf("scrape missing the gauge, got:\n%s", got)
+	}
+}
+
+func TestCounterVec_Values(t *testing.T) {
+	t.Parallel()
+
+	counter := NewRegistry().NewCounterVec("jobs", "Jobs", "job_type")
+	counter.Inc("task_analysis")
+	counter.Add(3, "tag_analysis")
+	got := counter.Values()
+	if len(got) != 2 || got["task_analysis"] != 1 || got["tag_analysis"] != 3 {
+		t.Errorf("Values() = %v, want task_analysis 1 and tag_analysis 3", got)
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"go.uber.org/zap"
+)
+
+// RequireAdminToken rejects requests whose X-Admin-Token header does not match token.
+// An empty token rejects every request, so admin routes stay closed unless explicitly configured.
+func RequireAdminToken(token string, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !request.HasAdminToken(r, token) {
+				respondError(w, http.StatusForbidden, "Admin access required", logger)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole rejects requests whose authenticated user lacks role. It must run after Auth; requests
+// without a user are rejected as unauthenticated. Requests made with an API key are rejected for any role
+// above user, since keys act for non-interactive clients and carry no admin scope.
+func RequireRole(role models.UserRole, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := request.UserFromContext(r)
+			if user == nil {
+				respondError(w, http.StatusUnauthorized, "Authentication required", logger)
+				return
+			}
+			_, viaAPIKey := request.APIKeyIDFromContext(r)
+			if !user.HasRole(role) || (viaAPIKey && role != models.RoleUser) {
+				logger.Warn("role_required",
+					zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+					zap.String("role", string(role)),
+					zap.Bool("api_key", viaAPIKey),
+					zap.String("path", logpkg.SanitizePath(r.URL.Path)),
+					zap.String("method", r.Method),
+				)
+				respondError(w, http.StatusForbidden, "Admin access required", logger)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAdminTokenOr lets requests with the admin token (see RequireAdminToken) through and sends the
+// others through fallback, e.g. Auth followed by RequireRole(models.RoleAdmin), so operator routes serve
+// both scripts holding the token and users with the admin role.
+func RequireAdminTokenOr(token string, fallback ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		guarded := next
+		for i := len(fallback) - 1; i >= 0; i-- {
+			guarded = fallback[i](guarded)
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if request.HasAdminToken(r, token) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			guarded.ServeHTTP(w, r)
+		})
+	}
+}
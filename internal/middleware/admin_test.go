@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/request"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestRequireAdminToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		configured string
+		header     string
+		wantStatus int
+	}{
+		{"matching token", "secret", "secret", http.StatusOK},
+		{"wrong token", "secret", "guess", http.StatusForbidden},
+		{"missing header", "secret", "", http.StatusForbidden},
+		{"not configured", "", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := RequireAdminToken(tt.configured, zap.NewNop())(next)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/anything", nil)
+			if tt.header != "" {
+				req.Header.Set(request.AdminTokenHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		user       *models.User
+		role       models.UserRole
+		viaAPIKey  bool
+		wantStatus int
+	}{
+		{"admin allowed", &models.User{ID: uuid.New(), Role: models.RoleAdmin}, models.RoleAdmin, false, http.StatusOK},
+		{"user denied", &models.User{ID: uuid.New(), Role: models.RoleUser}, models.RoleAdmin, false, http.StatusForbidden},
+		{"user without a stored role denied", &models.User{ID: uuid.New()}, models.RoleAdmin, false, http.StatusForbidden},
+		{"user allowed on user routes", &models.User{ID: uuid.New(), Role: models.RoleUser}, models.RoleUser, false, http.StatusOK},
+		{"unauthenticated", nil, models.RoleAdmin, false, http.StatusUnauthorized},
+		{"admin's API key denied", &models.User{ID: uuid.New(), Role: models.RoleAdmin}, models.RoleAdmin, true, http.StatusForbidden},
+		{"API key allowed on user routes", &models.User{ID: uuid.New(), Role: models.RoleUser}, models.RoleUser, true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := RequireRole(tt.role, zap.NewNop())(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/audit", nil)
+			if tt.user != nil {
+				req = req.WithContext(request.WithUser(req.Context(), tt.user))
+			}
+			if tt.viaAPIKey {
+				req = req.WithContext(request.WithAPIKeyID(req.Context(), uuid.New()))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRequireAdminTokenOr(t *testing.T) {
+	t.Parallel()
+
+	// authenticateAs stands in for Auth, attaching the user named by the X-Test-Role header
+	authenticateAs := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := r.Header.Get("X-Test-Role")
+			if role == "" {
+				respondError(w, http.StatusUnauthorized, "Missing Authorization header", zap.NewNop())
+				return
+			}
+			user := &models.User{ID: uuid.New(), Role: models.UserRole(role)}
+			next.ServeHTTP(w, r.WithContext(request.WithUser(r.Context(), user)))
+		})
+	}
+
+	tests := []struct {
+		name       string
+		configured string
+		token      string
+		role       string
+		wantStatus int
+	}{
+		{name: "admin token", configured: "secret", token: "secret", wantStatus: http.StatusOK},
+		{name: "admin user", configured: "secret", role: "admin", wantStatus: http.StatusOK},
+		{name: "admin user without a configured token", role: "admin", wantStatus: http.StatusOK},
+		{name: "regular user", configured: "secret", role: "user", wantStatus: http.StatusForbidden},
+		{name: "regular user with a wrong token", configured: "secret", token: "guess", role: "user", wantStatus: http.StatusForbidden},
+		{name: "no credentials", configured: "secret", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := RequireAdminTokenOr(tt.configured, authenticateAs, RequireRole(models.RoleAdmin, zap.NewNop()))(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/ai/cost", nil)
+			if tt.token != "" {
+				req.Header.Set(request.AdminTokenHeader, tt.token)
+			}
+			if tt.role != "" {
+				req.Header.Set("X-Test-Role", tt.role)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
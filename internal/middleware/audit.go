@@ -1,19 +1,68 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"time"
 
 	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
 	"github.com/benvon/smart-todo/internal/request"
 	"go.uber.org/zap"
 )
 
+// AuditRecorder persists audit events
+type AuditRecorder interface {
+	Record(ctx context.Context, event *models.AuditEvent) error
+}
+
+const (
+	// DefaultAuditBufferSize is how many audit events an AuditWriter holds while they wait to be written
+	DefaultAuditBufferSize = 1024
+
+	// auditRecordTimeout bounds how long recording one audit event may take
+	auditRecordTimeout = 2 * time.Second
+)
+
+// ErrAuditBufferFull is returned by AuditWriter.Record when the event had to be dropped
+var ErrAuditBufferFull = errors.New("audit event buffer is full")
+
+// auditActions maps the response status codes that are security events to their audit action
+var auditActions = map[int]models.AuditAction{
+	http.StatusUnauthorized:    models.AuditActionUnauthorized,
+	http.StatusForbidden:       models.AuditActionForbidden,
+	http.StatusTooManyRequests: models.AuditActionRateLimited,
+}
+
+// AuditOption configures the Audit middleware.
+type AuditOption func(*auditOptions)
+
+type auditOptions struct {
+	recorder AuditRecorder
+}
+
+// WithAuditRecorder also persists each security event with recorder. Events that fail to persist are still
+// logged, along with the error. Pass an AuditWriter so a flood of rejected requests does not turn into one
+// synchronous database write per response.
+func WithAuditRecorder(recorder AuditRecorder) AuditOption {
+	return func(o *auditOptions) {
+		o.recorder = recorder
+	}
+}
+
 // Audit logs security-related events for monitoring and compliance
-func Audit(logger *zap.Logger) func(http.Handler) http.Handler {
+func Audit(logger *zap.Logger, opts ...AuditOption) func(http.Handler) http.Handler {
+	var options auditOptions
+	for _, o := range opts {
+		o(&options)
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Wrap ResponseWriter to capture status code for audit logging
 			wrapped := &auditResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			// Authentication happens further down the chain; track who the request was authenticated as
+			r, authenticated := request.TrackAuthenticatedUser(r)
 
 			next.ServeHTTP(wrapped, r)
 
@@ -36,10 +85,108 @@ func Audit(logger *zap.Logger) func(http.Handler) http.Handler {
 					zap.String("ip", logpkg.SanitizeString(ip, logpkg.MaxGeneralStringLength)),
 				)
 			}
+			if action, ok := auditActions[statusCode]; ok && options.recorder != nil {
+				recordAuditEvent(r, options.recorder, newAuditEvent(r, action, statusCode, authenticated()), logger)
+			}
 		})
 	}
 }
 
+// newAuditEvent describes the security event of a request answered with statusCode
+func newAuditEvent(r *http.Request, action models.AuditAction, statusCode int, user *models.User) *models.AuditEvent {
+	event := &models.AuditEvent{
+		Action:     action,
+		Method:     r.Method,
+		Resource:   logpkg.SanitizePath(r.URL.Path),
+		IP:         logpkg.SanitizeString(request.ClientIP(r), logpkg.MaxGeneralStringLength),
+		StatusCode: statusCode,
+	}
+	if user != nil {
+		event.UserID = &user.ID
+	}
+	return event
+}
+
+// recordAuditEvent persists event, logging it with the error when that fails so the event is not lost. The
+// response has been written, so recording outlives a request cancelled by the client.
+func recordAuditEvent(r *http.Request, recorder AuditRecorder, event *models.AuditEvent, logger *zap.Logger) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), auditRecordTimeout)
+	defer cancel()
+	if err := recorder.Record(ctx, event); err != nil {
+		logAuditEventFailure(logger, event, err)
+	}
+}
+
+// logAuditEventFailure logs an audit event that could not be recorded
+func logAuditEventFailure(logger *zap.Logger, event *models.AuditEvent, err error) {
+	fields := []zap.Field{
+		zap.String("action", string(event.Action)),
+		zap.Int("status_code", event.StatusCode),
+		zap.String("method", event.Method),
+		zap.String("path", event.Resource),
+		zap.String("ip", event.IP),
+		zap.String("error", logpkg.SanitizeError(err)),
+	}
+	if event.UserID != nil {
+		fields = append(fields, zap.String("user_id", logpkg.SanitizeUserID(event.UserID.String())))
+	}
+	logger.Error("failed_to_record_audit_event", fields...)
+}
+
+// AuditWriter is an AuditRecorder that writes events to another recorder in the background, so recording
+// never holds up a response. Its buffer is bounded: events arriving while it is full are dropped, and logged
+// by the Audit middleware.
+type AuditWriter struct {
+	recorder AuditRecorder
+	events   chan *models.AuditEvent
+	logger   *zap.Logger
+}
+
+// NewAuditWriter creates a writer to recorder that buffers up to bufferSize events; Run writes them
+func NewAuditWriter(recorder AuditRecorder, bufferSize int, logger *zap.Logger) *AuditWriter {
+	if bufferSize < 1 {
+		bufferSize = DefaultAuditBufferSize
+	}
+	return &AuditWriter{recorder: recorder, events: make(chan *models.AuditEvent, bufferSize), logger: logger}
+}
+
+// Record queues event for Run without waiting, returning ErrAuditBufferFull when the buffer is full
+func (w *AuditWriter) Record(ctx context.Context, event *models.AuditEvent) error {
+	select {
+	case w.events <- event:
+		return nil
+	default:
+		return ErrAuditBufferFull
+	}
+}
+
+// Run writes queued events until ctx is done, then writes the events still buffered and returns
+func (w *AuditWriter) Run(ctx context.Context) {
+	for {
+		select {
+		case event := <-w.events:
+			w.write(event)
+		case <-ctx.Done():
+			for {
+				select {
+				case event := <-w.events:
+					w.write(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *AuditWriter) write(event *models.AuditEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), auditRecordTimeout)
+	defer cancel()
+	if err := w.recorder.Record(ctx, event); err != nil {
+		logAuditEventFailure(w.logger, event, err)
+	}
+}
+
 // auditResponseWriter wraps http.ResponseWriter to capture status code
 type auditResponseWriter struct {
 	http.ResponseWriter
@@ -50,3 +197,8 @@ func (aw *auditResponseWriter) WriteHeader(code int) {
 	aw.statusCode = code
 	aw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap lets http.ResponseController reach the underlying writer (e.g. to flush a stream)
+func (aw *auditResponseWriter) Unwrap() http.ResponseWriter {
+	return aw.ResponseWriter
+}
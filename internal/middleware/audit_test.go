@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type recordingAuditRecorder struct {
+	mu     sync.Mutex
+	events []*models.AuditEvent
+	err    error
+}
+
+func (r *recordingAuditRecorder) Record(ctx context.Context, event *models.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return r.err
+}
+
+func TestAudit_RecordsSecurityEvents(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: uuid.New()}
+	tests := []struct {
+		name          string
+		status        int
+		authenticated bool
+		wantAction    models.AuditAction
+	}{
+		{name: "unauthenticated request", status: http.StatusUnauthorized, wantAction: models.AuditActionUnauthorized},
+		{name: "forbidden request", status: http.StatusForbidden, authenticated: true, wantAction: models.AuditActionForbidden},
+		{name: "rate limited request", status: http.StatusTooManyRequests, authenticated: true, wantAction: models.AuditActionRateLimited},
+		{name: "successful request", status: http.StatusOK, authenticated: true},
+		{name: "not found", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := &recordingAuditRecorder{}
+			inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			})
+			// Authentication sits inside the audit middleware, as in the server's routing
+			var handler http.Handler = inner
+			if tt.authenticated {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					inner.ServeHTTP(w, r.WithContext(SetUserInContext(r.Context(), user)))
+				})
+			}
+			handler = Audit(zap.NewNop(), WithAuditRecorder(recorder))(handler)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/todos/123", nil)
+			req.RemoteAddr = "203.0.113.7:4321"
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if tt.wantAction == "" {
+				if len(recorder.events) != 0 {
+					t.Errorf("recorded %d events, want none", len(recorder.events))
+				}
+				return
+			}
+			if len(recorder.events) != 1 {
+				t.Fatalf("recorded %d events, want 1", len(recorder.events))
+			}
+			event := recorder.events[0]
+			if event.Action != tt.wantAction || event.StatusCode != tt.status || event.Method != http.MethodDelete ||
+				event.Resource != "/api/v1/todos/123" || event.IP != "203.0.113.7" {
+				t.Errorf("event = %+v, want %s for DELETE /api/v1/todos/123 from 203.0.113.7", event, tt.wantAction)
+			}
+			if tt.authenticated != (event.UserID != nil) || (event.UserID != nil && *event.UserID != user.ID) {
+				t.Errorf("event user = %v, want authenticated %v as %s", event.UserID, tt.authenticated, user.ID)
+			}
+		})
+	}
+}
+
+func TestAudit_LogsEventsThatFailToRecord(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zap.WarnLevel)
+	recorder := &recordingAuditRecorder{err: errors.New("db down")}
+	handler := Audit(zap.New(core), WithAuditRecorder(recorder))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/todos", nil))
+
+	if logs.FilterMessage("security_event").Len() != 1 {
+		t.Error("security event not logged")
+	}
+	failed := logs.FilterMessage("failed_to_record_audit_event").All()
+	if len(failed) != 1 || failed[0].ContextMap()["action"] != string(models.AuditActionUnauthorized) {
+		t.Errorf("failed record logs = %v, want the unrecorded unauthorized event", failed)
+	}
+}
+
+// blockingAuditRecorder records events once release is closed
+type blockingAuditRecorder struct {
+	recordingAuditRecorder
+	release chan struct{}
+}
+
+func (r *blockingAuditRecorder) Record(ctx context.Context, event *models.AuditEvent) error {
+	<-r.release
+	return r.recordingAuditRecorder.Record(ctx, event)
+}
+
+func TestAuditWriter(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zap.WarnLevel)
+	recorder := &blockingAuditRecorder{release: make(chan struct{})}
+	writer := NewAuditWriter(recorder, 2, zap.NewNop())
+	handler := Audit(zap.New(core), WithAuditRecorder(writer))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	// Responses are released while the recorder is stuck; the third event finds the buffer full
+	responded := make(chan struct{})
+	go func() {
+		defer close(responded)
+		for range 3 {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/todos", nil))
+		}
+	}()
+	select {
+	case <-responded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("responses waited for the audit recorder")
+	}
+	if dropped := logs.FilterMessage("failed_to_record_audit_event").All(); len(dropped) != 1 ||
+		dropped[0].ContextMap()["error"] != ErrAuditBufferFull.Error() {
+		t.Errorf("failed record logs = %v, want one dropped event", dropped)
+	}
+
+	// Stopping the writer still writes the buffered events
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	close(recorder.release)
+	writer.Run(ctx)
+	if len(recorder.events) != 2 {
+		t.Errorf("recorded %d events, want the 2 buffered", len(recorder.events))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/benvon/smart-todo/internal/database"
@@ -21,6 +22,8 @@ import (
 const (
 	// MaxTokenSize is the maximum size for JWT tokens (8KB)
 	MaxTokenSize = 8 * 1024 // 8KB
+	// APIKeyHeader carries an API key, as an alternative to sending it as the Authorization bearer token
+	APIKeyHeader = "X-API-Key"
 )
 
 var (
@@ -28,7 +31,46 @@ var (
 	errAuthCreateUser    = errors.New("auth: create user error")
 )
 
-func getOrCreateUser(ctx context.Context, userRepo *database.UserRepository, claims *models.JWTClaims, logger *zap.Logger) (*models.User, error) {
+// AuthOption configures the Auth middleware
+type AuthOption func(*authOptions)
+
+type authOptions struct {
+	adminEmails map[string]bool
+	rolesClaim  string
+}
+
+// WithAdminEmails makes users logging in with one of emails (compared case-insensitively) admins, unless
+// their identity provider states the email is unverified
+func WithAdminEmails(emails []string) AuthOption {
+	return func(o *authOptions) {
+		for _, email := range emails {
+			o.adminEmails[strings.ToLower(email)] = true
+		}
+	}
+}
+
+// WithRolesClaim makes users logging in with "admin" among the roles in claim admins (see
+// oidc.Verifier.SetRolesClaim for the claim format)
+func WithRolesClaim(claim string) AuthOption {
+	return func(o *authOptions) {
+		o.rolesClaim = claim
+	}
+}
+
+// roleFromClaims returns the role a user logging in with claims has
+func (o *authOptions) roleFromClaims(claims *models.JWTClaims) models.UserRole {
+	if slices.Contains(claims.Roles, string(models.RoleAdmin)) {
+		return models.RoleAdmin
+	}
+	// Only an email the provider vouches for grants admin; a missing email_verified claim does not
+	emailVerified := claims.EmailVerified != nil && *claims.EmailVerified
+	if claims.Email != "" && emailVerified && o.adminEmails[strings.ToLower(claims.Email)] {
+		return models.RoleAdmin
+	}
+	return models.RoleUser
+}
+
+func getOrCreateUser(ctx context.Context, userRepo *database.UserRepository, claims *models.JWTClaims, role models.UserRole, logger *zap.Logger) (*models.User, error) {
 	user, err := userRepo.GetByProviderID(ctx, claims.Sub)
 	if err == nil {
 		return user, nil
@@ -47,6 +89,7 @@ func getOrCreateUser(ctx context.Context, userRepo *database.UserRepository, cla
 		ProviderID:    &claims.Sub,
 		Name:          &claims.Name,
 		EmailVerified: true,
+		Role:          role,
 	}
 	if err := userRepo.Create(ctx, user); err != nil {
 		logger.Error("failed_to_create_user",
@@ -60,8 +103,17 @@ func getOrCreateUser(ctx context.Context, userRepo *database.UserRepository, cla
 	return user, nil
 }
 
-func maybeUpdateUser(ctx context.Context, userRepo *database.UserRepository, user *models.User, claims *models.JWTClaims, logger *zap.Logger) {
+func maybeUpdateUser(ctx context.Context, userRepo *database.UserRepository, user *models.User, claims *models.JWTClaims, role models.UserRole, logger *zap.Logger) {
 	updateNeeded := false
+	if user.Role != role {
+		logger.Info("user_role_changed",
+			zap.String("user_id", logpkg.SanitizeUserID(user.ID.String())),
+			zap.String("old_role", string(user.Role)),
+			zap.String("new_role", string(role)),
+		)
+		user.Role = role
+		updateNeeded = true
+	}
 	if user.Email != claims.Email {
 		user.Email = claims.Email
 		updateNeeded = true
@@ -82,10 +134,96 @@ func maybeUpdateUser(ctx context.Context, userRepo *database.UserRepository, use
 	}
 }
 
-// Auth creates authentication middleware that validates JWT tokens
-func Auth(db *database.DB, oidcProvider *oidc.Provider, jwksManager *oidc.JWKSManager, providerName string, logger *zap.Logger) func(http.Handler) http.Handler {
+// apiKeyFromRequest returns the API key the request authenticates with, from the X-API-Key header or an
+// Authorization bearer token with the API key prefix
+func apiKeyFromRequest(r *http.Request) (string, bool) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key, true
+	}
+	scheme, credential, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && scheme == "Bearer" && models.IsAPIKey(credential) {
+		return credential, true
+	}
+	return "", false
+}
+
+// authenticateAPIKey serves the request as the owner of the API key key, or rejects it when the key is
+// unknown or revoked, or its scope does not allow the request's method
+func authenticateAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, db *database.DB, key string, logger *zap.Logger) {
+	if len(key) > models.MaxAPIKeyLength || !models.IsAPIKey(key) {
+		respondError(w, http.StatusUnauthorized, "Invalid API key", logger)
+		return
+	}
+	ctx := r.Context()
+	apiKey, err := database.NewAPIKeyRepository(db).Authenticate(ctx, models.HashAPIKey(key))
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Warn("api_key_rejected",
+			zap.String("ip", logpkg.SanitizeString(request.ClientIP(r), logpkg.MaxGeneralStringLength)),
+			zap.String("path", logpkg.SanitizePath(r.URL.Path)),
+			zap.String("method", r.Method),
+		)
+		respondError(w, http.StatusUnauthorized, "Invalid API key", logger)
+		return
+	}
+	if err != nil {
+		logger.Error("database_error_authenticating_api_key",
+			zap.String("operation", "auth_api_key"),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		respondError(w, http.StatusInternalServerError, "Database error", logger)
+		return
+	}
+	if !apiKey.Allows(r.Method) {
+		logger.Warn("api_key_scope_denied",
+			zap.String("user_id", logpkg.SanitizeUserID(apiKey.UserID.String())),
+			zap.String("api_key_id", apiKey.ID.String()),
+			zap.String("scope", string(apiKey.Scope)),
+			zap.String("path", logpkg.SanitizePath(r.URL.Path)),
+			zap.String("method", r.Method),
+		)
+		respondError(w, http.StatusForbidden, "API key is read-only", logger)
+		return
+	}
+	user, err := database.NewUserRepository(db).GetByID(ctx, apiKey.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The user was deleted after the key was looked up, and the key was deleted with them
+		logger.Warn("api_key_rejected",
+			zap.String("ip", logpkg.SanitizeString(request.ClientIP(r), logpkg.MaxGeneralStringLength)),
+			zap.String("path", logpkg.SanitizePath(r.URL.Path)),
+			zap.String("method", r.Method),
+			zap.String("api_key_id", apiKey.ID.String()),
+		)
+		respondError(w, http.StatusUnauthorized, "Invalid API key", logger)
+		return
+	}
+	if err != nil {
+		logger.Error("database_error_fetching_user",
+			zap.String("operation", "auth_api_key"),
+			zap.String("error", logpkg.SanitizeError(err)),
+			zap.String("user_id", logpkg.SanitizeUserID(apiKey.UserID.String())),
+		)
+		respondError(w, http.StatusInternalServerError, "Database error", logger)
+		return
+	}
+	ctx = request.WithAPIKeyID(request.WithUser(ctx, user), apiKey.ID)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Auth creates authentication middleware that validates JWT tokens. Requests carrying an API key (see
+// apiKeyFromRequest) are authenticated by the key instead, for clients that cannot log in interactively.
+// Each JWT login sets the user's role from the token (see WithRolesClaim and WithAdminEmails); requests
+// with an API key keep the role of the user's last login.
+func Auth(db *database.DB, oidcProvider *oidc.Provider, jwksManager *oidc.JWKSManager, providerName string, logger *zap.Logger, opts ...AuthOption) func(http.Handler) http.Handler {
+	options := authOptions{adminEmails: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(&options)
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key, ok := apiKeyFromRequest(r); ok {
+				authenticateAPIKey(w, r, next, db, key, logger)
+				return
+			}
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
 				respondError(w, http.StatusUnauthorized, "Missing Authorization header", logger)
@@ -120,7 +258,8 @@ func Auth(db *database.DB, oidcProvider *oidc.Provider, jwksManager *oidc.JWKSMa
 				respondError(w, http.StatusInternalServerError, "JWKS URL not configured", logger)
 				return
 			}
-			verifier := oidc.NewVerifier(jwksManager, oidcConfig.Issuer)
+			verifier := oidc.NewVerifier(jwksManager, oidcConfig.Issuer, oidcConfig.AllowedAudiences()...)
+			verifier.SetRolesClaim(options.rolesClaim)
 			claims, err := verifier.Verify(ctx, tokenString, *oidcConfig.JWKSUrl)
 			if err != nil {
 				logger.Warn("token_verification_failed",
@@ -134,7 +273,8 @@ func Auth(db *database.DB, oidcProvider *oidc.Provider, jwksManager *oidc.JWKSMa
 				return
 			}
 			userRepo := database.NewUserRepository(db)
-			user, err := getOrCreateUser(ctx, userRepo, claims, logger)
+			role := options.roleFromClaims(claims)
+			user, err := getOrCreateUser(ctx, userRepo, claims, role, logger)
 			if err != nil {
 				if errors.Is(err, errAuthDatabaseFetch) {
 					respondError(w, http.StatusInternalServerError, "Database error", logger)
@@ -143,7 +283,7 @@ func Auth(db *database.DB, oidcProvider *oidc.Provider, jwksManager *oidc.JWKSMa
 				}
 				return
 			}
-			maybeUpdateUser(ctx, userRepo, user, claims, logger)
+			maybeUpdateUser(ctx, userRepo, user, claims, role, logger)
 			ctx = request.WithUser(ctx, user)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/request"
+	"go.uber.org/zap"
+)
+
+const (
+	// DebugCaptureHeader is the request header that asks for body capture on a single request
+	DebugCaptureHeader = "X-Debug-Capture"
+	// DefaultMaxCaptureBytes is how much of each request and response body is kept for logging
+	DefaultMaxCaptureBytes = 16 << 10 // 16KB
+
+	redactedBody = "[REDACTED]"
+)
+
+// sensitiveBodyFields are JSON keys (matched case-insensitively) whose values are never logged
+var sensitiveBodyFields = map[string]struct{}{
+	"password":      {},
+	"token":         {},
+	"access_token":  {},
+	"refresh_token": {},
+	"id_token":      {},
+	"api_key":       {},
+	"apikey":        {},
+	"secret":        {},
+	"client_secret": {},
+	"authorization": {},
+	"code":          {},
+}
+
+// BodyCapture logs request and response bodies at debug level for requests that carry the
+// X-Debug-Capture header together with a valid admin token. It is a no-op unless enabled is true,
+// which the server ties to debug mode. The request body is teed as the handler reads it, so the
+// handler still sees the full body; streaming (SSE) responses are not captured. Bodies are capped at
+// maxBytes and JSON fields named like credentials are redacted; non-JSON or truncated bodies are
+// logged by size only since they cannot be redacted reliably.
+func BodyCapture(enabled bool, adminToken string, maxBytes int, logger *zap.Logger) func(http.Handler) http.Handler {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxCaptureBytes
+	}
+	return func(next http.Handler) http.Handler {
+		if !enabled || logger == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(DebugCaptureHeader) == "" || !request.HasAdminToken(r, adminToken) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			reqBuf := &cappedBuffer{max: maxBytes}
+			if r.Body != nil {
+				r.Body = &teeReadCloser{Reader: io.TeeReader(r.Body, reqBuf), Closer: r.Body}
+			}
+			cw := &captureResponseWriter{ResponseWriter: w, statusCode: http.StatusOK, body: &cappedBuffer{max: maxBytes}}
+
+			next.ServeHTTP(cw, r)
+
+			logger.Debug("http_body_capture",
+				zap.String("method", r.Method),
+				zap.String("path", logpkg.SanitizePath(r.URL.Path)),
+				zap.Int("status_code", cw.statusCode),
+				zap.String("request_body", redactBody(reqBuf)),
+				zap.Int64("request_bytes", reqBuf.total),
+				zap.String("response_body", cw.loggedBody()),
+				zap.Int64("response_bytes", cw.body.total),
+			)
+		})
+	}
+}
+
+// cappedBuffer keeps the first max bytes written and counts the rest
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	max   int
+	total int64
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += int64(len(p))
+	if room := b.max - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) truncated() bool {
+	return b.total > int64(b.buf.Len())
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// captureResponseWriter copies the response body into a capped buffer until the handler starts streaming
+type captureResponseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	body        *cappedBuffer
+	streaming   bool
+	wroteHeader bool
+}
+
+func (cw *captureResponseWriter) WriteHeader(code int) {
+	cw.statusCode = code
+	cw.wroteHeader = true
+	if strings.HasPrefix(cw.Header().Get("Content-Type"), "text/event-stream") {
+		cw.streaming = true
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *captureResponseWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.streaming {
+		_, _ = cw.body.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush marks the response as streaming and forwards to the underlying writer
+func (cw *captureResponseWriter) Flush() {
+	cw.streaming = true
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *captureResponseWriter) loggedBody() string {
+	if cw.streaming {
+		return "[streaming response not captured]"
+	}
+	return redactBody(cw.body)
+}
+
+// redactBody returns the captured body with sensitive JSON fields replaced
+func redactBody(b *cappedBuffer) string {
+	if b.total == 0 {
+		return ""
+	}
+	if b.truncated() {
+		return "[body exceeds capture limit]"
+	}
+	var v any
+	if err := json.Unmarshal(b.buf.Bytes(), &v); err != nil {
+		return "[non-JSON body not captured]"
+	}
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return "[body could not be encoded]"
+	}
+	return string(out)
+}
+
+func redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if _, ok := sensitiveBodyFields[strings.ToLower(k)]; ok {
+				t[k] = redactedBody
+				continue
+			}
+			t[k] = redactValue(val)
+		}
+	case []any:
+		for i, val := range t {
+			t[i] = redactValue(val)
+		}
+	}
+	return v
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestBodyCapture(t *testing.T) {
+	t.Parallel()
+
+	const adminToken = "admin-secret"
+	const reqBody = `{"text":"buy milk","password":"hunter2"}`
+
+	tests := []struct {
+		name         string
+		enabled      bool
+		captureHdr   bool
+		token        string
+		wantCaptured bool
+	}{
+		{name: "enabled with header and admin token", enabled: true, captureHdr: true, token: adminToken, wantCaptured: true},
+		{name: "disabled outside debug mode", enabled: false, captureHdr: true, token: adminToken},
+		{name: "missing capture header", enabled: true, token: adminToken},
+		{name: "wrong admin token", enabled: true, captureHdr: true, token: "nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			core, logs := observer.New(zapcore.DebugLevel)
+			var handlerSaw string
+			h := BodyCapture(tt.enabled, adminToken, 0, zap.New(core))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("handler read body: %v", err)
+				}
+				handlerSaw = string(b)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"id":"1","access_token":"abc"}`))
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/todos", strings.NewReader(reqBody))
+			if tt.captureHdr {
+				req.Header.Set(DebugCaptureHeader, "1")
+			}
+			req.Header.Set("X-Admin-Token", tt.token)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if handlerSaw != reqBody {
+				t.Errorf("handler body = %q, want %q", handlerSaw, reqBody)
+			}
+			if rr.Body.String() != `{"id":"1","access_token":"abc"}` {
+				t.Errorf("client response altered: %q", rr.Body.String())
+			}
+
+			entries := logs.FilterMessage("http_body_capture").All()
+			if !tt.wantCaptured {
+				if len(entries) != 0 {
+					t.Errorf("expected no capture, got %d entries", len(entries))
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 capture entry, got %d", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			gotReq, _ := fields["request_body"].(string)
+			if strings.Contains(gotReq, "hunter2") || !strings.Contains(gotReq, "buy milk") {
+				t.Errorf("request_body not redacted as expected: %q", gotReq)
+			}
+			gotResp, _ := fields["response_body"].(string)
+			if strings.Contains(gotResp, "abc") || !strings.Contains(gotResp, redactedBody) {
+				t.Errorf("response_body not redacted as expected: %q", gotResp)
+			}
+			if fields["status_code"] != int64(http.StatusCreated) {
+				t.Errorf("status_code = %v, want %d", fields["status_code"], http.StatusCreated)
+			}
+		})
+	}
+}
+
+func TestBodyCapture_LimitsAndStreaming(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		maxBytes     int
+		body         string
+		stream       bool
+		wantRequest  string
+		wantResponse string
+	}{
+		{name: "body over the cap is not logged", maxBytes: 8, body: `{"text":"a long todo"}`, wantRequest: "[body exceeds capture limit]"},
+		{name: "non-JSON body is not logged", maxBytes: 1024, body: "password=hunter2", wantRequest: "[non-JSON body not captured]"},
+		{name: "streaming response is skipped", maxBytes: 1024, body: `{}`, stream: true, wantRequest: `{}`, wantResponse: "[streaming response not captured]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			core, logs := observer.New(zapcore.DebugLevel)
+			var handlerSaw string
+			h := BodyCapture(true, "tok", tt.maxBytes, zap.New(core))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				handlerSaw = string(b)
+				if tt.stream {
+					w.Header().Set("Content-Type", "text/event-stream")
+					_, _ = w.Write([]byte("data: hello\n\n"))
+					w.(http.Flusher).Flush()
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/ai/chat", strings.NewReader(tt.body))
+			req.Header.Set(DebugCaptureHeader, "1")
+			req.Header.Set("X-Admin-Token", "tok")
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if handlerSaw != tt.body {
+				t.Errorf("handler body = %q, want %q", handlerSaw, tt.body)
+			}
+			if tt.stream && !rr.Flushed {
+				t.Error("expected flush to reach the underlying writer")
+			}
+			entries := logs.FilterMessage("http_body_capture").All()
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 capture entry, got %d", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			if fields["request_body"] != tt.wantRequest {
+				t.Errorf("request_body = %v, want %q", fields["request_body"], tt.wantRequest)
+			}
+			if fields["response_body"] != tt.wantResponse {
+				t.Errorf("response_body = %v, want %q", fields["response_body"], tt.wantResponse)
+			}
+		})
+	}
+}
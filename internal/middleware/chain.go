@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Names of the middlewares the server registers for the global chain
+const (
+	ChainOTEL            = "otel"
+	ChainMetrics         = "metrics"
+	ChainSecurityHeaders = "security_headers"
+	ChainCORS            = "cors"
+	ChainConcurrency     = "concurrency"
+	ChainRequestSize     = "request_size"
+	ChainContentType     = "content_type"
+	ChainTimeout         = "timeout"
+	ChainErrorHandler    = "error_handler"
+	ChainAudit           = "audit"
+	ChainLogging         = "logging"
+	ChainBodyCapture     = "body_capture"
+	ChainActivity        = "activity"
+)
+
+// DefaultChainOrder is the global middleware chain used when none is configured, outermost first
+var DefaultChainOrder = []string{
+	ChainOTEL,
+	ChainMetrics,
+	ChainSecurityHeaders,
+	ChainCORS,
+	ChainConcurrency,
+	ChainRequestSize,
+	ChainContentType,
+	ChainTimeout,
+	ChainErrorHandler,
+	ChainAudit,
+	ChainLogging,
+	ChainBodyCapture,
+	ChainActivity,
+}
+
+type chainEntry struct {
+	middleware func(http.Handler) http.Handler
+	required   bool
+}
+
+// ChainRegistry holds the middlewares available to the global chain by name, so the chain can be assembled
+// from a configured order
+type ChainRegistry struct {
+	entries map[string]chainEntry
+}
+
+// NewChainRegistry creates an empty registry
+func NewChainRegistry() *ChainRegistry {
+	return &ChainRegistry{entries: make(map[string]chainEntry)}
+}
+
+// Register makes mw available under name. A nil mw registers a name that is known but unavailable in this
+// deployment (such as tracing with OTEL disabled); Build skips it rather than rejecting it. Registering a
+// name twice panics.
+func (c *ChainRegistry) Register(name string, mw func(http.Handler) http.Handler) {
+	c.register(name, chainEntry{middleware: mw})
+}
+
+// RegisterRequired registers mw like Register and makes Build reject any order that leaves it out
+func (c *ChainRegistry) RegisterRequired(name string, mw func(http.Handler) http.Handler) {
+	c.register(name, chainEntry{middleware: mw, required: true})
+}
+
+func (c *ChainRegistry) register(name string, entry chainEntry) {
+	if _, ok := c.entries[name]; ok {
+		panic(fmt.Sprintf("middleware %q registered twice", name))
+	}
+	c.entries[name] = entry
+}
+
+// Build assembles the chain in the given order, outermost first. It returns an error naming every unknown
+// or repeated entry and every required middleware the order leaves out.
+func (c *ChainRegistry) Build(order []string) (*Chain, error) {
+	var problems []string
+	seen := make(map[string]bool, len(order))
+	chain := &Chain{}
+	for _, name := range order {
+		entry, ok := c.entries[name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("unknown middleware %q", name))
+			continue
+		case seen[name]:
+			problems = append(problems, fmt.Sprintf("middleware %q listed more than once", name))
+			continue
+		}
+		seen[name] = true
+		if entry.middleware == nil {
+			continue
+		}
+		chain.names = append(chain.names, name)
+		chain.middlewares = append(chain.middlewares, entry.middleware)
+	}
+	var missing []string
+	for name, entry := range c.entries {
+		if entry.required && !seen[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		problems = append(problems, fmt.Sprintf("required middleware %q is missing", name))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid middleware chain: %s", strings.Join(problems, "; "))
+	}
+	return chain, nil
+}
+
+// Chain is an assembled, validated middleware chain
+type Chain struct {
+	names       []string
+	middlewares []func(http.Handler) http.Handler
+}
+
+// Names returns the names of the middlewares in the chain, outermost first
+func (c *Chain) Names() []string {
+	return append([]string(nil), c.names...)
+}
+
+// Middlewares returns the middlewares in the chain, outermost first, for registering on a router in order
+func (c *Chain) Middlewares() []func(http.Handler) http.Handler {
+	return append([]func(http.Handler) http.Handler(nil), c.middlewares...)
+}
+
+// Then wraps h in the chain, the first middleware outermost
+func (c *Chain) Then(h http.Handler) http.Handler {
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	return h
+}
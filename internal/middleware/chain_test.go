@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// recordingMiddleware appends name to calls when a request passes through it
+func recordingMiddleware(name string, calls *[]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*calls = append(*calls, name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestChainRegistry_Build(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		order     []string
+		wantNames []string
+		wantErrs  []string
+	}{
+		{
+			name:      "configured order",
+			order:     []string{"security_headers", "logging", "timeout"},
+			wantNames: []string{"security_headers", "logging", "timeout"},
+		},
+		{
+			name:      "reordered",
+			order:     []string{"timeout", "logging", "security_headers"},
+			wantNames: []string{"timeout", "logging", "security_headers"},
+		},
+		{
+			name:      "optional middleware left out",
+			order:     []string{"security_headers", "timeout"},
+			wantNames: []string{"security_headers", "timeout"},
+		},
+		{
+			name:      "unavailable middleware skipped",
+			order:     []string{"otel", "security_headers", "timeout"},
+			wantNames: []string{"security_headers", "timeout"},
+		},
+		{
+			name:     "unknown name",
+			order:    []string{"security_headers", "compression", "timeout"},
+			wantErrs: []string{`unknown middleware "compression"`},
+		},
+		{
+			name:     "repeated name",
+			order:    []string{"security_headers", "logging", "timeout", "logging"},
+			wantErrs: []string{`middleware "logging" listed more than once`},
+		},
+		{
+			name:  "required middlewares missing",
+			order: []string{"logging"},
+			wantErrs: []string{
+				`required middleware "security_headers" is missing`,
+				`required middleware "timeout" is missing`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls []string
+			registry := NewChainRegistry()
+			registry.Register("otel", nil)
+			registry.RegisterRequired("security_headers", recordingMiddleware("security_headers", &calls))
+			registry.RegisterRequired("timeout", recordingMiddleware("timeout", &calls))
+			registry.Register("logging", recordingMiddleware("logging", &calls))
+
+			chain, err := registry.Build(tt.order)
+			if len(tt.wantErrs) > 0 {
+				if err == nil {
+					t.Fatal("Build() error = nil, want an error")
+				}
+				for _, want := range tt.wantErrs {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("Build() error = %q, want it to contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if !slices.Equal(chain.Names(), tt.wantNames) {
+				t.Errorf("Names() = %v, want %v", chain.Names(), tt.wantNames)
+			}
+
+			handler := chain.Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, "handler")
+			}))
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			if want := append(slices.Clone(tt.wantNames), "handler"); !slices.Equal(calls, want) {
+				t.Errorf("request passed through %v, want %v", calls, want)
+			}
+		})
+	}
+}
+
+func TestChainRegistry_DefaultChainOrderIsValid(t *testing.T) {
+	t.Parallel()
+
+	registry := NewChainRegistry()
+	for _, name := range DefaultChainOrder {
+		registry.RegisterRequired(name, func(next http.Handler) http.Handler { return next })
+	}
+	chain, err := registry.Build(DefaultChainOrder)
+	if err != nil {
+		t.Fatalf("Build(DefaultChainOrder) error = %v", err)
+	}
+	if !slices.Equal(chain.Names(), DefaultChainOrder) {
+		t.Errorf("Names() = %v, want %v", chain.Names(), DefaultChainOrder)
+	}
+}
+
+func TestChainRegistry_RegisterTwicePanics(t *testing.T) {
+	t.Parallel()
+
+	registry := NewChainRegistry()
+	registry.Register("logging", nil)
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a name twice to panic")
+		}
+	}()
+	registry.Register("logging", nil)
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/request"
+	"go.uber.org/zap"
+)
+
+// DefaultConcurrencyExemptPaths are never counted against the per-client concurrency limit
+var DefaultConcurrencyExemptPaths = []string{"/healthz", "/readyz", "/health", "/version", "/metrics"}
+
+// ConcurrencyLimiter caps how many requests a single client IP may have in flight at once. Unlike rate
+// limiting it bounds long-lived requests (streams, blocking analysis) rather than request frequency.
+// Counts are kept per process.
+type ConcurrencyLimiter struct {
+	maxPerClient int
+	exemptPaths  []string
+	logger       *zap.Logger
+
+	mu     sync.Mutex
+	active map[string]int
+}
+
+// NewConcurrencyLimiter creates a limiter allowing maxPerClient concurrent requests per client IP
+// (0 or less disables the limit). Requests whose path equals or is below an exempt path are not counted.
+func NewConcurrencyLimiter(maxPerClient int, logger *zap.Logger, exemptPaths ...string) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		maxPerClient: maxPerClient,
+		exemptPaths:  exemptPaths,
+		logger:       logger,
+		active:       make(map[string]int),
+	}
+}
+
+// Middleware returns middleware that rejects requests over the limit with 429 Too Many Requests
+func (l *ConcurrencyLimiter) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if l.maxPerClient <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if l.exempt(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := concurrencyKey(r)
+			if !l.acquire(key) {
+				if l.logger != nil {
+					l.logger.Warn("concurrency_limit_exceeded",
+						zap.String("client_ip", key),
+						zap.String("path", logpkg.SanitizePath(r.URL.Path)),
+						zap.Int("limit", l.maxPerClient),
+					)
+				}
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+				return
+			}
+			defer l.release(key)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Active returns the number of requests currently in flight for a client key
+func (l *ConcurrencyLimiter) Active(key string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.active[key]
+}
+
+func (l *ConcurrencyLimiter) acquire(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[key] >= l.maxPerClient {
+		return false
+	}
+	l.active[key]++
+	return true
+}
+
+func (l *ConcurrencyLimiter) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[key] <= 1 {
+		delete(l.active, key)
+		return
+	}
+	l.active[key]--
+}
+
+func (l *ConcurrencyLimiter) exempt(path string) bool {
+	for _, p := range l.exemptPaths {
+		if path == p || strings.HasPrefix(path, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// concurrencyKey returns the client IP without the port, so separate connections from one client share a count
+func concurrencyKey(r *http.Request) string {
+	ip := request.ClientIP(r)
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		return host
+	}
+	return ip
+}
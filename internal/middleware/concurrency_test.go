@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// blockingHandler holds each request open until release is closed, signalling entered once per request.
+func blockingHandler(entered chan<- struct{}, release <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func serveFrom(h http.Handler, remoteAddr, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		max        int
+		remoteAddr string
+		path       string
+		wantStatus int
+	}{
+		{name: "same client over cap is rejected", max: 2, remoteAddr: "10.0.0.1:4000", path: "/api/v1/todos", wantStatus: http.StatusTooManyRequests},
+		{name: "same client on another port is rejected", max: 2, remoteAddr: "10.0.0.1:5000", path: "/api/v1/todos", wantStatus: http.StatusTooManyRequests},
+		{name: "different client is allowed", max: 2, remoteAddr: "10.0.0.2:4000", path: "/api/v1/todos", wantStatus: http.StatusOK},
+		{name: "exempt path is allowed", max: 2, remoteAddr: "10.0.0.1:4000", path: "/healthz", wantStatus: http.StatusOK},
+		{name: "zero disables the limit", max: 0, remoteAddr: "10.0.0.1:4000", path: "/api/v1/todos", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter := NewConcurrencyLimiter(tt.max, nil, DefaultConcurrencyExemptPaths...)
+			entered := make(chan struct{}, 8)
+			release := make(chan struct{})
+			h := limiter.Middleware()(blockingHandler(entered, release))
+
+			// Fill the first client's slots (at least one request even when the limit is disabled).
+			holders := max(tt.max, 1)
+			var wg sync.WaitGroup
+			for i := 0; i < holders; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					serveFrom(h, "10.0.0.1:4000", "/api/v1/todos")
+				}()
+				<-entered
+			}
+
+			done := make(chan *httptest.ResponseRecorder, 1)
+			go func() { done <- serveFrom(h, tt.remoteAddr, tt.path) }()
+
+			var rr *httptest.ResponseRecorder
+			if tt.wantStatus == http.StatusOK {
+				<-entered
+				close(release)
+				rr = <-done
+			} else {
+				rr = <-done
+				close(release)
+			}
+			wg.Wait()
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusTooManyRequests && rr.Header().Get("Retry-After") == "" {
+				t.Error("expected Retry-After header on rejection")
+			}
+			if got := limiter.Active("10.0.0.1"); got != 0 {
+				t.Errorf("Active after completion = %d, want 0", got)
+			}
+		})
+	}
+}
+
+func TestConcurrencyLimiter_ReleaseFreesSlot(t *testing.T) {
+	t.Parallel()
+
+	limiter := NewConcurrencyLimiter(1, nil)
+	h := limiter.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i := 0; i < 3; i++ {
+		if rr := serveFrom(h, "10.0.0.1:4000", "/api/v1/todos"); rr.Code != http.StatusOK {
+			t.Fatalf("sequential request %d: status = %d, want 200", i, rr.Code)
+		}
+	}
+}
@@ -7,6 +7,18 @@ import (
 
 // ContentType validates Content-Type headers for requests with bodies
 func ContentType(next http.Handler) http.Handler {
+	return ContentTypeAllowing(nil)(next)
+}
+
+// ContentTypeAllowing is ContentType that also accepts the listed media types on specific paths (matched
+// exactly), e.g. a text/markdown upload. Other paths still require application/json.
+func ContentTypeAllowing(extra map[string][]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return contentType(next, extra)
+	}
+}
+
+func contentType(next http.Handler, extra map[string][]string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Only validate Content-Type for methods that typically have bodies
 		if r.Method == "POST" || r.Method == "PATCH" || r.Method == "PUT" {
@@ -23,7 +35,7 @@ func ContentType(next http.Handler) http.Handler {
 			contentTypeLower := strings.ToLower(contentType)
 			isJSON := strings.HasPrefix(contentTypeLower, "application/json")
 			
-			if !isJSON {
+			if !isJSON && !hasMediaType(contentTypeLower, extra[r.URL.Path]) {
 				http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
 				return
 			}
@@ -31,4 +43,16 @@ func ContentType(next http.Handler) http.Handler {
 
 		next.ServeHTTP(w, r)
 	})
-}
\ No newline at end of file
+}
+
+// hasMediaType reports whether contentType (lowercased, possibly with parameters) is one of mediaTypes
+func hasMediaType(contentType string, mediaTypes []string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	for _, allowed := range mediaTypes {
+		if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContentTypeAllowing(t *testing.T) {
+	t.Parallel()
+
+	const importPath = "/api/v1/todos/import/markdown"
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		wantStatus  int
+	}{
+		{name: "json accepted everywhere", method: "POST", path: "/api/v1/todos", contentType: "application/json; charset=utf-8", wantStatus: http.StatusOK},
+		{name: "missing content type", method: "POST", path: importPath, wantStatus: http.StatusBadRequest},
+		{name: "extra type on its path", method: "POST", path: importPath, contentType: "text/markdown; charset=UTF-8", wantStatus: http.StatusOK},
+		{name: "extra type is case insensitive", method: "POST", path: importPath, contentType: "Text/Plain", wantStatus: http.StatusOK},
+		{name: "extra type on another path", method: "POST", path: "/api/v1/todos", contentType: "text/markdown", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "unlisted type on the extra path", method: "POST", path: importPath, contentType: "text/html", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "GET is not checked", method: "GET", path: "/api/v1/todos", wantStatus: http.StatusOK},
+	}
+
+	handler := ContentTypeAllowing(map[string][]string{importPath: {"text/markdown", "text/plain"}})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }),
+	)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
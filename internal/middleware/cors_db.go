@@ -8,14 +8,20 @@ import (
 	"time"
 
 	"github.com/benvon/smart-todo/internal/database"
+	"github.com/benvon/smart-todo/internal/models"
 	"github.com/rs/cors"
 	"go.uber.org/zap"
 )
 
+// CORSConfigSource provides the stored CORS config (implemented by database.CorsConfigRepository).
+type CORSConfigSource interface {
+	Get(ctx context.Context) (*models.CorsConfig, error)
+}
+
 // CORSReloader wraps rs/cors and periodically reloads CORS config from the database.
 type CORSReloader struct {
 	next     http.Handler
-	repo     *database.CorsConfigRepository
+	repo     CORSConfigSource
 	fallback string // e.g. FRONTEND_URL
 	log      *zap.Logger
 	interval time.Duration
@@ -24,7 +30,7 @@ type CORSReloader struct {
 }
 
 // NewCORSReloader creates a CORS middleware that loads config from the DB and hot-reloads it.
-func NewCORSReloader(repo *database.CorsConfigRepository, frontendURLFallback string, log *zap.Logger, reloadInterval time.Duration) *CORSReloader {
+func NewCORSReloader(repo CORSConfigSource, frontendURLFallback string, log *zap.Logger, reloadInterval time.Duration) *CORSReloader {
 	return &CORSReloader{
 		repo:     repo,
 		fallback: strings.TrimSpace(frontendURLFallback),
@@ -37,7 +43,7 @@ func NewCORSReloader(repo *database.CorsConfigRepository, frontendURLFallback st
 func (r *CORSReloader) Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		r.next = next
-		r.load(context.Background())
+		_ = r.load(context.Background())
 		return r
 	}
 }
@@ -54,14 +60,20 @@ func (r *CORSReloader) Start(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			r.load(ctx)
+			_ = r.load(ctx)
 		}
 	}
 }
 
-func (r *CORSReloader) load(ctx context.Context) {
+// Reload reloads CORS config immediately instead of waiting for the next tick.
+// If the database read fails the fallback origin is applied and the error is returned.
+func (r *CORSReloader) Reload(ctx context.Context) error {
+	return r.load(ctx)
+}
+
+func (r *CORSReloader) load(ctx context.Context) error {
 	if r.next == nil {
-		return
+		return nil
 	}
 	cfg, err := r.repo.Get(ctx)
 	var origins []string
@@ -90,13 +102,15 @@ func (r *CORSReloader) load(ctx context.Context) {
 		AllowCredentials: allowCreds,
 		MaxAge:           maxAge,
 		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "Idempotency-Key", "If-Match"},
+		ExposedHeaders:   []string{"ETag"},
 	}
 	c := cors.New(opts)
 	h := c.Handler(r.next)
 	r.mu.Lock()
 	r.current = h
 	r.mu.Unlock()
+	return err
 }
 
 // ServeHTTP implements http.Handler.
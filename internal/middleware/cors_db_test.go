@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"go.uber.org/zap"
+)
+
+type fakeCORSConfigSource struct {
+	cfg *models.CorsConfig
+	err error
+}
+
+func (f *fakeCORSConfigSource) Get(ctx context.Context) (*models.CorsConfig, error) {
+	return f.cfg, f.err
+}
+
+func corsAllowedOrigin(t *testing.T, h http.Handler, origin string) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", origin)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Header().Get("Access-Control-Allow-Origin")
+}
+
+func TestCORSReloader_Reload(t *testing.T) {
+	t.Parallel()
+
+	source := &fakeCORSConfigSource{cfg: &models.CorsConfig{AllowedOrigins: "https://old.example.com"}}
+	reloader := NewCORSReloader(source, "https://fallback.example.com", zap.NewNop(), time.Hour)
+	h := reloader.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	if got := corsAllowedOrigin(t, h, "https://new.example.com"); got != "" {
+		t.Fatalf("new origin allowed before reload: %q", got)
+	}
+
+	source.cfg = &models.CorsConfig{AllowedOrigins: "https://new.example.com"}
+	if err := reloader.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := corsAllowedOrigin(t, h, "https://new.example.com"); got != "https://new.example.com" {
+		t.Errorf("after reload Access-Control-Allow-Origin = %q, want new origin", got)
+	}
+
+	source.err = errors.New("db down")
+	if err := reloader.Reload(context.Background()); err == nil {
+		t.Fatal("expected Reload() to report the config source error")
+	}
+	if got := corsAllowedOrigin(t, h, "https://fallback.example.com"); got != "https://fallback.example.com" {
+		t.Errorf("after failed reload Access-Control-Allow-Origin = %q, want fallback origin", got)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// InFlight counts the HTTP requests currently being handled so graceful shutdown can report how many
+// requests it drained and how many were still running when its deadline hit
+type InFlight struct {
+	active atomic.Int64
+}
+
+// NewInFlight creates an in-flight request counter
+func NewInFlight() *InFlight {
+	return &InFlight{}
+}
+
+// Middleware counts each request from the time it enters next until next returns
+func (f *InFlight) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.active.Add(1)
+		defer f.active.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Count returns the number of requests currently in flight
+func (f *InFlight) Count() int64 {
+	return f.active.Load()
+}
+
+// Shutdown gracefully shuts srv down, logging the in-flight count when shutdown starts and warning with
+// the requests still active if ctx expires before they finish. It returns srv.Shutdown's error.
+func (f *InFlight) Shutdown(ctx context.Context, srv *http.Server, logger *zap.Logger) error {
+	start := time.Now()
+	inFlightAtStart := f.Count()
+	logger.Info("server_shutdown_draining",
+		zap.Int64("in_flight_requests", inFlightAtStart),
+	)
+
+	err := srv.Shutdown(ctx)
+	elapsed := time.Since(start)
+	if errors.Is(err, context.DeadlineExceeded) {
+		logger.Warn("server_shutdown_deadline_exceeded",
+			zap.Int64("in_flight_requests", inFlightAtStart),
+			zap.Int64("still_active_requests", f.Count()),
+			zap.Duration("elapsed", elapsed),
+		)
+		return err
+	}
+	logger.Info("server_shutdown_drained",
+		zap.Int64("in_flight_requests", inFlightAtStart),
+		zap.Duration("elapsed", elapsed),
+	)
+	return err
+}
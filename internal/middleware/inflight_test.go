@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestInFlight_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		handlerDelay  time.Duration
+		deadline      time.Duration
+		wantErr       error
+		wantEvent     string
+		wantLevel     zapcore.Level
+		wantStillBusy int64
+	}{
+		{
+			name:         "slow request drains before deadline",
+			handlerDelay: 50 * time.Millisecond,
+			deadline:     5 * time.Second,
+			wantEvent:    "server_shutdown_drained",
+			wantLevel:    zapcore.InfoLevel,
+		},
+		{
+			name:          "deadline hit with request still active",
+			handlerDelay:  time.Hour,
+			deadline:      50 * time.Millisecond,
+			wantErr:       context.DeadlineExceeded,
+			wantEvent:     "server_shutdown_deadline_exceeded",
+			wantLevel:     zapcore.WarnLevel,
+			wantStillBusy: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inFlight := NewInFlight()
+			entered := make(chan struct{})
+			release := make(chan struct{})
+			slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(entered)
+				select {
+				case <-time.After(tt.handlerDelay):
+				case <-release:
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+			ts := httptest.NewServer(inFlight.Middleware(slow))
+			defer ts.Close()
+			defer close(release)
+
+			go func() {
+				if resp, err := http.Get(ts.URL); err == nil {
+					_ = resp.Body.Close()
+				}
+			}()
+			<-entered
+			if got := inFlight.Count(); got != 1 {
+				t.Fatalf("Count() during slow request = %d, want 1", got)
+			}
+
+			core, logs := observer.New(zapcore.InfoLevel)
+			ctx, cancel := context.WithTimeout(context.Background(), tt.deadline)
+			defer cancel()
+			err := inFlight.Shutdown(ctx, ts.Config, zap.New(core))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Shutdown() error = %v, want %v", err, tt.wantErr)
+			}
+
+			started := logs.FilterMessage("server_shutdown_draining").All()
+			if len(started) != 1 || started[0].ContextMap()["in_flight_requests"] != int64(1) {
+				t.Errorf("draining log = %+v, want in_flight_requests=1", started)
+			}
+			finished := logs.FilterMessage(tt.wantEvent).All()
+			if len(finished) != 1 {
+				t.Fatalf("expected one %s log, got %+v", tt.wantEvent, logs.All())
+			}
+			if finished[0].Level != tt.wantLevel {
+				t.Errorf("%s level = %v, want %v", tt.wantEvent, finished[0].Level, tt.wantLevel)
+			}
+			if tt.wantStillBusy > 0 && finished[0].ContextMap()["still_active_requests"] != tt.wantStillBusy {
+				t.Errorf("still_active_requests = %v, want %d", finished[0].ContextMap()["still_active_requests"], tt.wantStillBusy)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/benvon/smart-todo/internal/request"
@@ -52,6 +53,33 @@ func (r *RedisRateLimiter) Ping(ctx context.Context) error {
 	return r.client.Ping(ctx).Err()
 }
 
+// DependencyInfo reports the Redis server version and mode from INFO server
+func (r *RedisRateLimiter) DependencyInfo(ctx context.Context) (map[string]string, error) {
+	raw, err := r.client.Info(ctx, "server").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Redis server info: %w", err)
+	}
+	return parseRedisServerInfo(raw), nil
+}
+
+// parseRedisServerInfo extracts version fields from an INFO server response
+func parseRedisServerInfo(raw string) map[string]string {
+	info := make(map[string]string)
+	for _, line := range strings.Split(raw, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "redis_version":
+			info["version"] = value
+		case "redis_mode":
+			info["mode"] = value
+		}
+	}
+	return info
+}
+
 // Client returns the underlying Redis client for use with other stores (e.g. ulule limiter).
 func (r *RedisRateLimiter) Client() *redis.Client {
 	return r.client
@@ -2,11 +2,11 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"sync"
 	"time"
 
-	"github.com/benvon/smart-todo/internal/database"
 	"github.com/benvon/smart-todo/internal/models"
 	"github.com/benvon/smart-todo/internal/request"
 	"github.com/redis/go-redis/v9"
@@ -16,12 +16,18 @@ import (
 	"go.uber.org/zap"
 )
 
+// RatelimitConfigStore reads and seeds the stored rate limit config (implemented by database.RatelimitConfigRepository).
+type RatelimitConfigStore interface {
+	Get(ctx context.Context) (*models.RatelimitConfig, error)
+	Set(ctx context.Context, c *models.RatelimitConfig) error
+}
+
 // RateLimitReloader wraps ulule/limiter and periodically reloads rate limit config from the database.
 type RateLimitReloader struct {
 	next        http.Handler
 	redisClient *redis.Client
 	store       limiter.Store
-	repo        *database.RatelimitConfigRepository
+	repo        RatelimitConfigStore
 	defaultRate string
 	log         *zap.Logger
 	interval    time.Duration
@@ -30,7 +36,7 @@ type RateLimitReloader struct {
 }
 
 // NewRateLimitReloader creates a rate limit middleware that loads config from the DB and hot-reloads it.
-func NewRateLimitReloader(redisClient *redis.Client, repo *database.RatelimitConfigRepository, defaultRate string, log *zap.Logger, reloadInterval time.Duration) *RateLimitReloader {
+func NewRateLimitReloader(redisClient *redis.Client, repo RatelimitConfigStore, defaultRate string, log *zap.Logger, reloadInterval time.Duration) *RateLimitReloader {
 	if defaultRate == "" {
 		defaultRate = defaultRatelimitRate
 	}
@@ -56,7 +62,7 @@ func NewRateLimitReloader(redisClient *redis.Client, repo *database.RatelimitCon
 func (r *RateLimitReloader) Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		r.next = next
-		r.load(context.Background())
+		_ = r.load(context.Background())
 		return r
 	}
 }
@@ -73,27 +79,33 @@ func (r *RateLimitReloader) Start(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			r.load(ctx)
+			_ = r.load(ctx)
 		}
 	}
 }
 
-func (r *RateLimitReloader) load(ctx context.Context) {
+// Reload reloads rate limit config immediately instead of waiting for the next tick.
+// If the stored config cannot be read or parsed the default rate is applied and the error is returned.
+func (r *RateLimitReloader) Reload(ctx context.Context) error {
+	return r.load(ctx)
+}
+
+func (r *RateLimitReloader) load(ctx context.Context) error {
 	if r.next == nil {
-		return
+		return nil
 	}
-	cfg, err := r.repo.Get(ctx)
+	cfg, loadErr := r.repo.Get(ctx)
 	rateStr := r.defaultRate
-	if err != nil {
+	if loadErr != nil {
 		r.log.Warn("failed_to_load_ratelimit_config_from_db_using_default",
-			zap.Error(err),
+			zap.Error(loadErr),
 			zap.String("default_rate", r.defaultRate),
 		)
 	} else if cfg != nil && cfg.Rate != "" {
 		rateStr = cfg.Rate
 	} else {
 		// Save default config if none exists
-		if err = r.repo.Set(ctx, &models.RatelimitConfig{Rate: r.defaultRate}); err != nil {
+		if err := r.repo.Set(ctx, &models.RatelimitConfig{Rate: r.defaultRate}); err != nil {
 			r.log.Error("failed_to_save_default_ratelimit_config",
 				zap.Error(err),
 				zap.String("default_rate", r.defaultRate),
@@ -108,6 +120,7 @@ func (r *RateLimitReloader) load(ctx context.Context) {
 			zap.String("rate_str", rateStr),
 			zap.String("default_rate", r.defaultRate),
 		)
+		loadErr = fmt.Errorf("invalid rate limit %q: %w", rateStr, err)
 		// Try to use default rate as fallback
 		rate, err = limiter.NewRateFromFormatted(r.defaultRate)
 		if err != nil {
@@ -115,7 +128,7 @@ func (r *RateLimitReloader) load(ctx context.Context) {
 				zap.Error(err),
 				zap.String("default_rate", r.defaultRate),
 			)
-			return
+			return fmt.Errorf("invalid default rate limit %q: %w", r.defaultRate, err)
 		}
 	}
 
@@ -130,6 +143,7 @@ func (r *RateLimitReloader) load(ctx context.Context) {
 	r.mu.Lock()
 	r.current = h
 	r.mu.Unlock()
+	return loadErr
 }
 
 // ServeHTTP implements http.Handler.
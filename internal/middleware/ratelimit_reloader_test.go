@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	memorystore "github.com/ulule/limiter/v3/drivers/store/memory"
+	"go.uber.org/zap"
+)
+
+type fakeRatelimitConfigStore struct {
+	cfg      *models.RatelimitConfig
+	err      error
+	setCalls []*models.RatelimitConfig
+}
+
+func (f *fakeRatelimitConfigStore) Get(ctx context.Context) (*models.RatelimitConfig, error) {
+	return f.cfg, f.err
+}
+
+func (f *fakeRatelimitConfigStore) Set(ctx context.Context, c *models.RatelimitConfig) error {
+	f.setCalls = append(f.setCalls, c)
+	return nil
+}
+
+func TestRateLimitReloader_Reload(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		store       *fakeRatelimitConfigStore
+		wantErr     bool
+		wantSeeding bool
+	}{
+		{"stored rate", &fakeRatelimitConfigStore{cfg: &models.RatelimitConfig{Rate: "10-M"}}, false, false},
+		{"no stored config seeds default", &fakeRatelimitConfigStore{}, false, true},
+		{"store error falls back to default", &fakeRatelimitConfigStore{err: errors.New("db down")}, true, false},
+		{"invalid stored rate falls back to default", &fakeRatelimitConfigStore{cfg: &models.RatelimitConfig{Rate: "lots"}}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// Use an in-memory limiter store so the reload path runs without Redis
+			reloader := &RateLimitReloader{
+				next:        http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+				store:       memorystore.NewStore(),
+				repo:        tt.store,
+				defaultRate: "5-S",
+				log:         zap.NewNop(),
+				interval:    time.Hour,
+			}
+
+			err := reloader.Reload(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (len(tt.store.setCalls) > 0) != tt.wantSeeding {
+				t.Errorf("Set calls = %d, wantSeeding %v", len(tt.store.setCalls), tt.wantSeeding)
+			}
+			reloader.mu.RLock()
+			defer reloader.mu.RUnlock()
+			if reloader.current == nil {
+				t.Error("expected a rate limit handler to be installed after reload")
+			}
+		})
+	}
+}
@@ -1,33 +1,191 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"go.uber.org/zap"
 )
 
 const (
 	// DefaultRequestTimeout is the default request timeout (30 seconds)
 	DefaultRequestTimeout = 30 * time.Second
+	// timeoutWriteGrace is how long past its deadline a request may still spend writing its response
+	timeoutWriteGrace = 5 * time.Second
 )
 
-// Timeout creates a middleware that enforces a timeout on request handlers
-func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+// RouteTimeout overrides the request timeout for the requests whose path equals or is below Path
+type RouteTimeout struct {
+	Path string
+	// Method limits the override to requests with this method (any method when empty)
+	Method string
+	// Timeout is the deadline of the route's requests (the default timeout when zero)
+	Timeout time.Duration
+	// Streaming requests get the deadline on their context, but their response is not buffered, so they can
+	// flush as they go; their handlers must stop writing once the context is done
+	Streaming bool
+}
+
+// matches reports whether the override applies to a request
+func (rt RouteTimeout) matches(method, path string) bool {
+	if rt.Method != "" && rt.Method != method {
+		return false
+	}
+	return path == rt.Path || strings.HasPrefix(path, strings.TrimSuffix(rt.Path, "/")+"/")
+}
+
+// Timeout creates a middleware that enforces a deadline on request handlers: timeout, or that of the most
+// specific route override matching the request. Responses are buffered until the handler returns, so a
+// handler overrunning its deadline is answered with a complete 504 JSON error rather than a truncated
+// response; what it writes afterwards is discarded. The connection's write deadline is moved to match where
+// the response writer allows it; the server's WriteTimeout should be at least WriteTimeoutFor the same
+// timeouts for when it does not.
+func Timeout(timeout time.Duration, logger *zap.Logger, routes ...RouteTimeout) func(http.Handler) http.Handler {
 	if timeout <= 0 {
 		timeout = DefaultRequestTimeout
 	}
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			route := routeTimeout(r, timeout, routes)
+			ctx, cancel := context.WithTimeout(r.Context(), route.Timeout)
 			defer cancel()
+			_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(route.Timeout + timeoutWriteGrace))
 
 			// Replace the request context with timeout context
 			r = r.WithContext(ctx)
 
-			// Use TimeoutHandler for automatic timeout handling
-			handler := http.TimeoutHandler(next, timeout, "Request Timeout")
-			handler.ServeHTTP(w, r)
+			if route.Streaming {
+				next.ServeHTTP(w, r)
+				return
+			}
+			serveWithTimeout(w, r, next, route.Timeout, logger)
 		})
 	}
-}
\ No newline at end of file
+}
+
+// WriteTimeoutFor returns the server WriteTimeout that leaves every request under Timeout(timeout, routes...)
+// time to write its response, timeout errors included
+func WriteTimeoutFor(timeout time.Duration, routes ...RouteTimeout) time.Duration {
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	longest := timeout
+	for _, route := range routes {
+		if route.Timeout > longest {
+			longest = route.Timeout
+		}
+	}
+	return longest + timeoutWriteGrace
+}
+
+// routeTimeout returns the override for r with the longest matching path, preferring one for r's method,
+// or the default timeout when none matches
+func routeTimeout(r *http.Request, timeout time.Duration, routes []RouteTimeout) RouteTimeout {
+	match := RouteTimeout{Timeout: timeout}
+	matched := false
+	for _, route := range routes {
+		if !route.matches(r.Method, r.URL.Path) {
+			continue
+		}
+		longer := len(route.Path) > len(match.Path)
+		sameWithMethod := len(route.Path) == len(match.Path) && route.Method != "" && match.Method == ""
+		if !matched || longer || sameWithMethod {
+			match, matched = route, true
+		}
+	}
+	if match.Timeout <= 0 {
+		match.Timeout = timeout
+	}
+	return match
+}
+
+// serveWithTimeout serves r with next into a buffer, copying the response to w when next returns in time
+// and answering 504 when r's deadline passes first
+func serveWithTimeout(w http.ResponseWriter, r *http.Request, next http.Handler, timeout time.Duration, logger *zap.Logger) {
+	tw := &timeoutWriter{header: make(http.Header)}
+	done := make(chan struct{})
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+			}
+		}()
+		next.ServeHTTP(tw, r)
+		close(done)
+	}()
+
+	select {
+	case p := <-panicked:
+		panic(p)
+	case <-done:
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		dst := w.Header()
+		for k, vv := range tw.header {
+			dst[k] = vv
+		}
+		if !tw.wroteHeader {
+			tw.code = http.StatusOK
+		}
+		w.WriteHeader(tw.code)
+		_, _ = w.Write(tw.buf.Bytes())
+	case <-r.Context().Done():
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		if !errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+			// The client went away; there is no one to answer
+			tw.err = r.Context().Err()
+			return
+		}
+		tw.err = http.ErrHandlerTimeout
+		logger.Warn("request_timeout",
+			zap.String("path", logpkg.SanitizePath(r.URL.Path)),
+			zap.String("method", r.Method),
+			zap.Duration("timeout", timeout),
+		)
+		respondErrorJSON(w, r, http.StatusGatewayTimeout, "Gateway Timeout", "The request took too long to process", logger)
+	}
+}
+
+// timeoutWriter buffers a response until the handler returns; writes after the deadline fail with
+// http.ErrHandlerTimeout
+type timeoutWriter struct {
+	header http.Header
+
+	mu          sync.Mutex
+	buf         bytes.Buffer
+	code        int
+	wroteHeader bool
+	err         error
+}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.header }
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.err != nil {
+		return 0, tw.err
+	}
+	if !tw.wroteHeader {
+		tw.code, tw.wroteHeader = http.StatusOK, true
+	}
+	return tw.buf.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.err != nil || tw.wroteHeader {
+		return
+	}
+	tw.code, tw.wroteHeader = code, true
+}
@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestTimeout_StreamingPaths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		path        string
+		wantFlushed bool
+	}{
+		{name: "buffered path", path: "/api/v1/todos", wantFlushed: false},
+		{name: "streaming path", path: "/api/v1/todos/export", wantFlushed: true},
+		{name: "below streaming path", path: "/api/v1/todos/export/part", wantFlushed: true},
+		{name: "streaming path prefix only", path: "/api/v1/todos/exports", wantFlushed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var hasDeadline bool
+			handler := Timeout(time.Second, zap.NewNop(), RouteTimeout{Path: "/api/v1/todos/export", Streaming: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, hasDeadline = r.Context().Deadline()
+				_, _ = w.Write([]byte("partial"))
+				_ = http.NewResponseController(w).Flush()
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			if !hasDeadline {
+				t.Error("request context has no deadline")
+			}
+			if w.Flushed != tt.wantFlushed {
+				t.Errorf("Flushed = %v, want %v", w.Flushed, tt.wantFlushed)
+			}
+			if w.Body.String() != "partial" {
+				t.Errorf("body = %q, want %q", w.Body.String(), "partial")
+			}
+		})
+	}
+}
+
+func TestTimeout_RouteOverrides(t *testing.T) {
+	t.Parallel()
+
+	routes := []RouteTimeout{
+		{Path: "/healthz", Timeout: 2 * time.Second},
+		{Path: "/api/v1/ai/chat", Timeout: 3 * time.Minute},
+		{Path: "/api/v1/ai/chat", Method: http.MethodGet, Timeout: 10 * time.Minute, Streaming: true},
+		{Path: "/api/v1/ai/chat/message", Timeout: time.Minute},
+		{Path: "/api/v1/todos/export", Streaming: true},
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		wantTimeout time.Duration
+		wantFlushed bool
+	}{
+		{name: "default", method: http.MethodGet, path: "/api/v1/todos", wantTimeout: 30 * time.Second},
+		{name: "shorter override", method: http.MethodGet, path: "/healthz", wantTimeout: 2 * time.Second},
+		{name: "method-specific override", method: http.MethodGet, path: "/api/v1/ai/chat", wantTimeout: 10 * time.Minute, wantFlushed: true},
+		{name: "override for other methods", method: http.MethodDelete, path: "/api/v1/ai/chat", wantTimeout: 3 * time.Minute},
+		{name: "longest path wins", method: http.MethodPost, path: "/api/v1/ai/chat/message", wantTimeout: time.Minute},
+		{name: "override without a timeout keeps the default", method: http.MethodGet, path: "/api/v1/todos/export", wantTimeout: 30 * time.Second, wantFlushed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var remaining time.Duration
+			handler := Timeout(30*time.Second, zap.NewNop(), routes...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				deadline, _ := r.Context().Deadline()
+				remaining = time.Until(deadline)
+				_ = http.NewResponseController(w).Flush()
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if remaining > tt.wantTimeout || remaining < tt.wantTimeout-time.Second {
+				t.Errorf("deadline in %v, want %v", remaining, tt.wantTimeout)
+			}
+			if w.Flushed != tt.wantFlushed {
+				t.Errorf("Flushed = %v, want %v", w.Flushed, tt.wantFlushed)
+			}
+		})
+	}
+}
+
+func TestTimeout_Responses(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantHeader string
+		wantBody   string
+	}{
+		{
+			name: "in time",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Test", "kept")
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"ok":true}`))
+			},
+			wantStatus: http.StatusCreated,
+			wantHeader: "kept",
+			wantBody:   `{"ok":true}`,
+		},
+		{
+			name: "implicit 200",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("body"))
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "body",
+		},
+		{
+			name: "overrunning the deadline",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Test", "dropped")
+				_, _ = w.Write([]byte("partial"))
+				<-r.Context().Done()
+				time.Sleep(10 * time.Millisecond)
+				if _, err := w.Write([]byte("late")); err != http.ErrHandlerTimeout {
+					t.Errorf("late Write() error = %v, want http.ErrHandlerTimeout", err)
+				}
+			},
+			wantStatus: http.StatusGatewayTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handlerDone := make(chan struct{})
+			handler := Timeout(50*time.Millisecond, zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer close(handlerDone)
+				tt.handler(w, r)
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/todos", nil))
+			<-handlerDone
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("X-Test"); got != tt.wantHeader {
+				t.Errorf("X-Test = %q, want %q", got, tt.wantHeader)
+			}
+			if tt.wantStatus != http.StatusGatewayTimeout {
+				if w.Body.String() != tt.wantBody {
+					t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+				}
+				return
+			}
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("504 body %q is not a JSON error: %v", w.Body.String(), err)
+			}
+			if resp.Success || resp.Error != "Gateway Timeout" || resp.Path != "/api/v1/todos" {
+				t.Errorf("504 body = %+v", resp)
+			}
+		})
+	}
+}
+
+func TestWriteTimeoutFor(t *testing.T) {
+	t.Parallel()
+
+	routes := []RouteTimeout{{Path: "/healthz", Timeout: time.Second}, {Path: "/api/v1/todos/export", Timeout: 5 * time.Minute}}
+	if got, want := WriteTimeoutFor(30*time.Second, routes...), 5*time.Minute+timeoutWriteGrace; got != want {
+		t.Errorf("WriteTimeoutFor() = %v, want %v", got, want)
+	}
+	if got, want := WriteTimeoutFor(0), DefaultRequestTimeout+timeoutWriteGrace; got != want {
+		t.Errorf("WriteTimeoutFor(0) = %v, want %v", got, want)
+	}
+}
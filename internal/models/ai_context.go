@@ -1,7 +1,10 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -12,6 +15,10 @@ type AIContext struct {
 	UserID        uuid.UUID              `json:"user_id"`
 	ContextSummary string                `json:"context_summary,omitempty"`
 	Preferences   map[string]any         `json:"preferences,omitempty"`
+	TagAliases    map[string]string      `json:"tag_aliases,omitempty"` // alias -> canonical tag
+	TagWeights    map[string]float64     `json:"tag_weights,omitempty"` // tag -> prompt priority multiplier
+	SummaryUpdatedAt *time.Time          `json:"summary_updated_at,omitempty"` // when ContextSummary was last rewritten
+	SummaryChatTurns int                 `json:"summary_chat_turns"`           // user chat messages the summary was derived from
 	CreatedAt     time.Time              `json:"created_at"`
 	UpdatedAt     time.Time              `json:"updated_at"`
 }
@@ -24,3 +31,26 @@ type UserActivity struct {
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 }
+
+// SetContextSummary replaces the summary, recording when it changed and how many chat turns it was
+// derived from (0 when the user wrote it directly)
+func (c *AIContext) SetContextSummary(summary string, chatTurns int, now time.Time) {
+	c.ContextSummary = summary
+	c.SummaryChatTurns = chatTurns
+	c.SummaryUpdatedAt = &now
+}
+
+// DefaultMaxContextSummaryLength is the default maximum length of a context summary in characters
+const DefaultMaxContextSummaryLength = 2000
+
+// ErrContextSummaryTooLong is returned when a context summary is longer than the maximum allowed
+var ErrContextSummaryTooLong = errors.New("context summary is too long")
+
+// ValidateContextSummaryLength returns ErrContextSummaryTooLong when summary has more than maxLength
+// characters. A long summary would crowd out the rest of every analysis prompt.
+func ValidateContextSummaryLength(summary string, maxLength int) error {
+	if n := utf8.RuneCountInString(summary); n > maxLength {
+		return fmt.Errorf("%w: %d characters, at most %d allowed", ErrContextSummaryTooLong, n, maxLength)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// MaxAIPauseReasonLength caps the note an operator may attach when pausing AI analysis
+const MaxAIPauseReasonLength = 500
+
+// AIAnalysisPause is the global AI analysis kill switch. While paused, workers defer analysis jobs
+// instead of calling the AI provider; todos are still created and stay pending.
+type AIAnalysisPause struct {
+	Paused bool       `json:"paused"`
+	Reason string     `json:"reason,omitempty"`
+	Since  *time.Time `json:"since,omitempty"`
+}
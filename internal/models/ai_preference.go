@@ -0,0 +1,55 @@
+package models
+
+import "strings"
+
+const (
+	// PreferenceAIProvider is the AI context preference key for the user's preferred AI provider
+	PreferenceAIProvider = "ai_provider"
+	// PreferenceAIModel is the AI context preference key for the user's preferred AI model
+	PreferenceAIModel = "ai_model"
+	// PreferenceOutputLanguage is the AI context preference key for the language AI-suggested tags are written in
+	PreferenceOutputLanguage = "output_language"
+	// PreferenceAutoArchive is the AI context preference key that opts the user out of the completed-todo
+	// retention sweep when set to false
+	PreferenceAutoArchive = "auto_archive"
+	// PreferenceWeeklySummary is the AI context preference key that opts the user out of the weekly summary
+	// when set to false
+	PreferenceWeeklySummary = "weekly_summary"
+)
+
+// AIModelPreference is a user's preferred AI provider and model. Empty fields mean the deployment default.
+type AIModelPreference struct {
+	Provider string
+	Model    string
+}
+
+// IsZero reports whether the preference selects nothing beyond the defaults
+func (p AIModelPreference) IsZero() bool {
+	return p.Provider == "" && p.Model == ""
+}
+
+// AIModelPreference returns the provider/model preference stored in the context's preferences.
+// It is safe to call on a nil context.
+func (c *AIContext) AIModelPreference() AIModelPreference {
+	if c == nil {
+		return AIModelPreference{}
+	}
+	pref := AIModelPreference{}
+	if v, ok := c.Preferences[PreferenceAIProvider].(string); ok {
+		pref.Provider = strings.TrimSpace(v)
+	}
+	if v, ok := c.Preferences[PreferenceAIModel].(string); ok {
+		pref.Model = strings.TrimSpace(v)
+	}
+	return pref
+}
+
+// OutputLanguage returns the user's preferred tag language, or "" if none is set.
+// It is safe to call on a nil context.
+func (c *AIContext) OutputLanguage() string {
+	if c == nil {
+		return ""
+	}
+	language, _ := c.Preferences[PreferenceOutputLanguage].(string)
+	return strings.TrimSpace(language)
+}
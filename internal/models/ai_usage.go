@@ -0,0 +1,187 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AICostWindow is the span the admin AI cost summary covers, ending now
+const AICostWindow = 30 * 24 * time.Hour
+
+// AIUsage is the token usage of one successful AI provider call
+type AIUsage struct {
+	// UserID is the user the call was made for (nil when it is not attributed to a user)
+	UserID           *uuid.UUID
+	Operation        string
+	Model            string
+	PromptTokens     int64
+	CompletionTokens int64
+	CreatedAt        time.Time
+}
+
+// AIUsageTotal sums the usage of the calls one operation or one user made with one model
+type AIUsageTotal struct {
+	// Operation is set for totals by operation, UserID for totals by user
+	Operation        string
+	UserID           uuid.UUID
+	Model            string
+	Calls            int64
+	PromptTokens     int64
+	CompletionTokens int64
+}
+
+// AIModelPrice is a model's price in USD per million prompt (input) and completion (output) tokens
+type AIModelPrice struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// AIPriceTable maps model names to their token prices
+type AIPriceTable map[string]AIModelPrice
+
+// DefaultAIPriceTable prices the default models of the supported providers. AI_PRICE_TABLE adds models and
+// overrides these as list prices change.
+var DefaultAIPriceTable = AIPriceTable{
+	"gpt-4o-mini":             {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+	"gpt-5-mini":              {InputPerMillion: 0.25, OutputPerMillion: 2.00},
+	"claude-3-5-haiku-latest": {InputPerMillion: 0.80, OutputPerMillion: 4.00},
+}
+
+// ParseAIPriceTable returns DefaultAIPriceTable with each "model=input/output" entry added or overriding the
+// default, where input and output are USD per million tokens (e.g. "gpt-4o=2.50/10.00")
+func ParseAIPriceTable(entries []string) (AIPriceTable, error) {
+	table := maps.Clone(DefaultAIPriceTable)
+	for _, entry := range entries {
+		model, prices, ok := strings.Cut(entry, "=")
+		input, output, ok2 := strings.Cut(prices, "/")
+		model = strings.TrimSpace(model)
+		if !ok || !ok2 || model == "" {
+			return nil, fmt.Errorf("invalid AI price %q (expected model=input/output)", entry)
+		}
+		var price AIModelPrice
+		var err error
+		if price.InputPerMillion, err = parseTokenPrice(input); err != nil {
+			return nil, fmt.Errorf("invalid AI price %q: %w", entry, err)
+		}
+		if price.OutputPerMillion, err = parseTokenPrice(output); err != nil {
+			return nil, fmt.Errorf("invalid AI price %q: %w", entry, err)
+		}
+		table[model] = price
+	}
+	return table, nil
+}
+
+// parseTokenPrice parses a non-negative price per million tokens
+func parseTokenPrice(s string) (float64, error) {
+	price, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || price < 0 || math.IsInf(price, 0) {
+		return 0, fmt.Errorf("price %q must be a non-negative number", strings.TrimSpace(s))
+	}
+	return price, nil
+}
+
+// Cost returns the estimated USD cost of the tokens with model. priced is false for models missing from the
+// table, whose cost is reported as 0.
+func (t AIPriceTable) Cost(model string, promptTokens, completionTokens int64) (cost float64, priced bool) {
+	price, ok := t[model]
+	if !ok {
+		return 0, false
+	}
+	return (float64(promptTokens)*price.InputPerMillion + float64(completionTokens)*price.OutputPerMillion) / 1e6, true
+}
+
+// AICostEntry is the usage and estimated cost of one operation or one user across models
+type AICostEntry struct {
+	Operation        string     `json:"operation,omitempty"`
+	UserID           *uuid.UUID `json:"user_id,omitempty"`
+	Calls            int64      `json:"calls"`
+	PromptTokens     int64      `json:"prompt_tokens"`
+	CompletionTokens int64      `json:"completion_tokens"`
+	EstimatedCostUSD float64    `json:"estimated_cost_usd"`
+	// UnpricedModels lists models used that have no price, so their tokens are not in EstimatedCostUSD
+	UnpricedModels []string `json:"unpriced_models,omitempty"`
+}
+
+// AICostSummary is the estimated AI cost over a period by operation and for the costliest users
+type AICostSummary struct {
+	Since                 time.Time     `json:"since"`
+	Until                 time.Time     `json:"until"`
+	TotalEstimatedCostUSD float64       `json:"total_estimated_cost_usd"`
+	ByOperation           []AICostEntry `json:"by_operation"`
+	TopUsers              []AICostEntry `json:"top_users"`
+}
+
+// NewAICostSummary prices the usage totals by operation and by user with prices. Entries are ordered by
+// estimated cost, highest first; only the topUsers costliest users are kept. The total covers all usage,
+// including calls not attributed to a user.
+func NewAICostSummary(since, until time.Time, byOperation, byUser []AIUsageTotal, prices AIPriceTable, topUsers int) *AICostSummary {
+	summary := &AICostSummary{
+		Since:       since,
+		Until:       until,
+		ByOperation: aiCostEntries(byOperation, prices, func(t AIUsageTotal) AICostEntry { return AICostEntry{Operation: t.Operation} }),
+		TopUsers: aiCostEntries(byUser, prices, func(t AIUsageTotal) AICostEntry {
+			userID := t.UserID
+			return AICostEntry{UserID: &userID}
+		}),
+	}
+	for _, entry := range summary.ByOperation {
+		summary.TotalEstimatedCostUSD += entry.EstimatedCostUSD
+	}
+	if len(summary.TopUsers) > topUsers {
+		summary.TopUsers = summary.TopUsers[:max(topUsers, 0)]
+	}
+	return summary
+}
+
+// aiCostEntries merges the per-model totals of each key (as returned by newEntry) into one priced entry and
+// sorts the entries by cost, then by key
+func aiCostEntries(totals []AIUsageTotal, prices AIPriceTable, newEntry func(AIUsageTotal) AICostEntry) []AICostEntry {
+	byKey := make(map[string]*AICostEntry)
+	entries := make([]*AICostEntry, 0, len(totals))
+	for _, t := range totals {
+		e := newEntry(t)
+		entry, ok := byKey[aiCostEntryKey(e)]
+		if !ok {
+			entry = &e
+			byKey[aiCostEntryKey(e)] = entry
+			entries = append(entries, entry)
+		}
+		entry.Calls += t.Calls
+		entry.PromptTokens += t.PromptTokens
+		entry.CompletionTokens += t.CompletionTokens
+		cost, priced := prices.Cost(t.Model, t.PromptTokens, t.CompletionTokens)
+		entry.EstimatedCostUSD += cost
+		if !priced && !slices.Contains(entry.UnpricedModels, t.Model) {
+			entry.UnpricedModels = append(entry.UnpricedModels, t.Model)
+		}
+	}
+
+	out := make([]AICostEntry, 0, len(entries))
+	for _, entry := range entries {
+		slices.Sort(entry.UnpricedModels)
+		out = append(out, *entry)
+	}
+	slices.SortFunc(out, func(a, b AICostEntry) int {
+		if c := cmp.Compare(b.EstimatedCostUSD, a.EstimatedCostUSD); c != 0 {
+			return c
+		}
+		return cmp.Compare(aiCostEntryKey(a), aiCostEntryKey(b))
+	})
+	return out
+}
+
+// aiCostEntryKey is the operation or user ID an entry is reported for
+func aiCostEntryKey(e AICostEntry) string {
+	if e.UserID != nil {
+		return e.UserID.String()
+	}
+	return e.Operation
+}
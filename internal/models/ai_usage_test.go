@@ -0,0 +1,152 @@
+package models
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseAIPriceTable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		entries []string
+		model   string
+		want    AIModelPrice
+		wantErr bool
+	}{
+		{name: "defaults", model: "gpt-4o-mini", want: DefaultAIPriceTable["gpt-4o-mini"]},
+		{name: "added model", entries: []string{"gpt-4o=2.50/10"}, model: "gpt-4o", want: AIModelPrice{2.50, 10}},
+		{name: "overridden default", entries: []string{" gpt-4o-mini = 0.2 / 0.8 "}, model: "gpt-4o-mini", want: AIModelPrice{0.2, 0.8}},
+		{name: "free model", entries: []string{"local=0/0"}, model: "local", want: AIModelPrice{}},
+		{name: "missing prices", entries: []string{"gpt-4o"}, wantErr: true},
+		{name: "missing output price", entries: []string{"gpt-4o=2.50"}, wantErr: true},
+		{name: "missing model", entries: []string{"=1/2"}, wantErr: true},
+		{name: "negative price", entries: []string{"gpt-4o=-1/2"}, wantErr: true},
+		{name: "not a number", entries: []string{"gpt-4o=cheap/2"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			table, err := ParseAIPriceTable(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAIPriceTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got, ok := table[tt.model]; !ok || got != tt.want {
+				t.Errorf("table[%q] = %+v (present %v), want %+v", tt.model, got, ok, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseAIPriceTable([]string{"gpt-4o-mini=9/9"}); err != nil {
+		t.Fatalf("ParseAIPriceTable() error = %v", err)
+	}
+	if DefaultAIPriceTable["gpt-4o-mini"].InputPerMillion == 9 {
+		t.Error("ParseAIPriceTable() modified DefaultAIPriceTable")
+	}
+}
+
+func TestAIPriceTable_Cost(t *testing.T) {
+	t.Parallel()
+
+	prices := AIPriceTable{"model-a": {InputPerMillion: 2, OutputPerMillion: 8}}
+	cost, priced := prices.Cost("model-a", 500_000, 250_000)
+	if !priced || !approxEqual(cost, 3) {
+		t.Errorf("Cost() = %v, %v, want 3, true", cost, priced)
+	}
+	if cost, priced := prices.Cost("model-b", 1_000_000, 0); priced || cost != 0 {
+		t.Errorf("Cost() of an unpriced model = %v, %v, want 0, false", cost, priced)
+	}
+}
+
+func TestNewAICostSummary(t *testing.T) {
+	t.Parallel()
+
+	prices := AIPriceTable{
+		"cheap":  {InputPerMillion: 1, OutputPerMillion: 2},
+		"pricey": {InputPerMillion: 10, OutputPerMillion: 20},
+	}
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	byOperation := []AIUsageTotal{
+		{Operation: "analyze_task", Model: "cheap", Calls: 100, PromptTokens: 1_000_000, CompletionTokens: 500_000},
+		{Operation: "analyze_task", Model: "pricey", Calls: 5, PromptTokens: 100_000, CompletionTokens: 50_000},
+		{Operation: "chat", Model: "pricey", Calls: 20, PromptTokens: 200_000, CompletionTokens: 100_000},
+		{Operation: "summarize_week", Model: "local-llm", Calls: 3, PromptTokens: 9_000, CompletionTokens: 900},
+	}
+	byUser := []AIUsageTotal{
+		{UserID: alice, Model: "cheap", Calls: 60, PromptTokens: 600_000, CompletionTokens: 300_000},
+		{UserID: bob, Model: "cheap", Calls: 40, PromptTokens: 400_000, CompletionTokens: 200_000},
+		{UserID: bob, Model: "pricey", Calls: 25, PromptTokens: 300_000, CompletionTokens: 150_000},
+		{UserID: carol, Model: "local-llm", Calls: 3, PromptTokens: 9_000, CompletionTokens: 900},
+	}
+	until := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	since := until.Add(-AICostWindow)
+
+	summary := NewAICostSummary(since, until, byOperation, byUser, prices, 2)
+
+	// analyze_task: cheap 1 + 1, pricey 1 + 1 = 4; chat: pricey 2 + 2 = 4; summarize_week: unpriced
+	wantOps := []AICostEntry{
+		{Operation: "analyze_task", Calls: 105, PromptTokens: 1_100_000, CompletionTokens: 550_000, EstimatedCostUSD: 4},
+		{Operation: "chat", Calls: 20, PromptTokens: 200_000, CompletionTokens: 100_000, EstimatedCostUSD: 4},
+		{Operation: "summarize_week", Calls: 3, PromptTokens: 9_000, CompletionTokens: 900, UnpricedModels: []string{"local-llm"}},
+	}
+	if len(summary.ByOperation) != len(wantOps) {
+		t.Fatalf("ByOperation = %+v, want %d entries", summary.ByOperation, len(wantOps))
+	}
+	for i, want := range wantOps {
+		if !aiCostEntryEqual(summary.ByOperation[i], want) {
+			t.Errorf("ByOperation[%d] = %+v, want %+v", i, summary.ByOperation[i], want)
+		}
+	}
+	if !approxEqual(summary.TotalEstimatedCostUSD, 8) {
+		t.Errorf("TotalEstimatedCostUSD = %v, want 8", summary.TotalEstimatedCostUSD)
+	}
+
+	// bob: cheap 0.4 + 0.4, pricey 3 + 3 = 6.8; alice: 0.6 + 0.6 = 1.2; carol is cut by the top 2 limit
+	wantUsers := []AICostEntry{
+		{UserID: &bob, Calls: 65, PromptTokens: 700_000, CompletionTokens: 350_000, EstimatedCostUSD: 6.8},
+		{UserID: &alice, Calls: 60, PromptTokens: 600_000, CompletionTokens: 300_000, EstimatedCostUSD: 1.2},
+	}
+	if len(summary.TopUsers) != len(wantUsers) {
+		t.Fatalf("TopUsers = %+v, want %d entries", summary.TopUsers, len(wantUsers))
+	}
+	for i, want := range wantUsers {
+		if !aiCostEntryEqual(summary.TopUsers[i], want) {
+			t.Errorf("TopUsers[%d] = %+v, want %+v", i, summary.TopUsers[i], want)
+		}
+	}
+	if !summary.Since.Equal(since) || !summary.Until.Equal(until) {
+		t.Errorf("period = %v to %v, want %v to %v", summary.Since, summary.Until, since, until)
+	}
+}
+
+func TestNewAICostSummary_NoUsage(t *testing.T) {
+	t.Parallel()
+
+	summary := NewAICostSummary(time.Time{}, time.Time{}, nil, nil, DefaultAIPriceTable, 10)
+	if summary.ByOperation == nil || summary.TopUsers == nil || len(summary.ByOperation)+len(summary.TopUsers) != 0 {
+		t.Errorf("summary = %+v, want empty (non-nil) entry lists", summary)
+	}
+	if summary.TotalEstimatedCostUSD != 0 {
+		t.Errorf("TotalEstimatedCostUSD = %v, want 0", summary.TotalEstimatedCostUSD)
+	}
+}
+
+func aiCostEntryEqual(a, b AICostEntry) bool {
+	sameUser := (a.UserID == nil) == (b.UserID == nil) && (a.UserID == nil || *a.UserID == *b.UserID)
+	return sameUser && a.Operation == b.Operation && a.Calls == b.Calls &&
+		a.PromptTokens == b.PromptTokens && a.CompletionTokens == b.CompletionTokens &&
+		approxEqual(a.EstimatedCostUSD, b.EstimatedCostUSD) && slices.Equal(a.UnpricedModels, b.UnpricedModels)
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// AnalysisQueueStatus summarizes how many of a user's todos are still waiting on AI analysis
+type AnalysisQueueStatus struct {
+	Pending    int `json:"pending"`
+	Processing int `json:"processing"`
+	Total      int `json:"total"`
+}
+
+// NewAnalysisQueueStatus builds the queue status from per-status todo counts.
+// Statuses other than pending and processing are ignored.
+func NewAnalysisQueueStatus(counts map[TodoStatus]int) AnalysisQueueStatus {
+	status := AnalysisQueueStatus{
+		Pending:    counts[TodoStatusPending],
+		Processing: counts[TodoStatusProcessing],
+	}
+	status.Total = status.Pending + status.Processing
+	return status
+}
+
+// AnalysisStallTimeout is how long a todo may stay in processing before the batch status counts its
+// analysis as failed (e.g. the worker died without resetting it)
+const AnalysisStallTimeout = 15 * time.Minute
+
+// AnalysisThroughputWindow is the recent period whose analyses estimate when outstanding work will finish
+const AnalysisThroughputWindow = 15 * time.Minute
+
+// AnalysisBatchCounts are aggregate analysis counts over all of a user's todos. RecentlyAnalyzed counts
+// processed todos updated within the throughput window.
+type AnalysisBatchCounts struct {
+	Analyzed         int
+	Pending          int
+	Processing       int
+	Failed           int
+	RecentlyAnalyzed int
+}
+
+// AnalysisBatchStatus summarizes progress of AI analysis across a user's todos, e.g. after a large import
+type AnalysisBatchStatus struct {
+	Analyzed   int `json:"analyzed"`
+	Pending    int `json:"pending"`
+	Processing int `json:"processing"`
+	Failed     int `json:"failed"`
+	Total      int `json:"total"`
+	// QueueDepth is the number of jobs waiting in the shared job queue, when the queue reports it
+	QueueDepth *int `json:"queue_depth,omitempty"`
+	// EstimatedCompletionAt is when pending and processing todos should be analyzed at the recent rate.
+	// It is omitted when nothing is outstanding or nothing was analyzed recently.
+	EstimatedCompletionAt *time.Time `json:"estimated_completion_at,omitempty"`
+}
+
+// NewAnalysisBatchStatus builds the batch status from aggregate counts, estimating completion from the number
+// of todos analyzed during window before now
+func NewAnalysisBatchStatus(counts AnalysisBatchCounts, window time.Duration, now time.Time) AnalysisBatchStatus {
+	status := AnalysisBatchStatus{
+		Analyzed:   counts.Analyzed,
+		Pending:    counts.Pending,
+		Processing: counts.Processing,
+		Failed:     counts.Failed,
+	}
+	status.Total = status.Analyzed + status.Pending + status.Processing + status.Failed
+	remaining := status.Pending + status.Processing
+	if remaining > 0 && counts.RecentlyAnalyzed > 0 && window > 0 {
+		eta := now.Add(time.Duration(float64(window) * float64(remaining) / float64(counts.RecentlyAnalyzed)))
+		status.EstimatedCompletionAt = &eta
+	}
+	return status
+}
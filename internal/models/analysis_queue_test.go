@@ -0,0 +1,96 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewAnalysisQueueStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		counts map[TodoStatus]int
+		want   AnalysisQueueStatus
+	}{
+		{"nil counts", nil, AnalysisQueueStatus{}},
+		{
+			name: "mixed statuses",
+			counts: map[TodoStatus]int{
+				TodoStatusPending:    3,
+				TodoStatusProcessing: 2,
+				TodoStatusProcessed:  7,
+				TodoStatusCompleted:  4,
+			},
+			want: AnalysisQueueStatus{Pending: 3, Processing: 2, Total: 5},
+		},
+		{
+			name:   "only settled todos",
+			counts: map[TodoStatus]int{TodoStatusProcessed: 5, TodoStatusCompleted: 1},
+			want:   AnalysisQueueStatus{},
+		},
+		{
+			name:   "only processing",
+			counts: map[TodoStatus]int{TodoStatusProcessing: 1},
+			want:   AnalysisQueueStatus{Processing: 1, Total: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := NewAnalysisQueueStatus(tt.counts); got != tt.want {
+				t.Errorf("NewAnalysisQueueStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAnalysisBatchStatus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	window := 15 * time.Minute
+	tests := []struct {
+		name    string
+		counts  AnalysisBatchCounts
+		want    AnalysisBatchStatus
+		wantETA time.Duration
+	}{
+		{name: "no todos", want: AnalysisBatchStatus{}},
+		{
+			name:    "mixed statuses mid import",
+			counts:  AnalysisBatchCounts{Analyzed: 40, Pending: 55, Processing: 5, Failed: 2, RecentlyAnalyzed: 30},
+			want:    AnalysisBatchStatus{Analyzed: 40, Pending: 55, Processing: 5, Failed: 2, Total: 102},
+			wantETA: 30 * time.Minute,
+		},
+		{
+			name:   "nothing analyzed recently",
+			counts: AnalysisBatchCounts{Analyzed: 3, Pending: 10},
+			want:   AnalysisBatchStatus{Analyzed: 3, Pending: 10, Total: 13},
+		},
+		{
+			name:   "all analyzed",
+			counts: AnalysisBatchCounts{Analyzed: 12, Failed: 1, RecentlyAnalyzed: 12},
+			want:   AnalysisBatchStatus{Analyzed: 12, Failed: 1, Total: 13},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := NewAnalysisBatchStatus(tt.counts, window, now)
+			eta := got.EstimatedCompletionAt
+			got.EstimatedCompletionAt = nil
+			if got != tt.want {
+				t.Errorf("NewAnalysisBatchStatus() = %+v, want %+v", got, tt.want)
+			}
+			switch {
+			case tt.wantETA == 0 && eta != nil:
+				t.Errorf("EstimatedCompletionAt = %v, want none", *eta)
+			case tt.wantETA != 0 && (eta == nil || !eta.Equal(now.Add(tt.wantETA))):
+				t.Errorf("EstimatedCompletionAt = %v, want %v", eta, now.Add(tt.wantETA))
+			}
+		})
+	}
+}
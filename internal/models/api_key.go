@@ -0,0 +1,112 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// APIKeyPrefix starts every API key, telling them apart from JWTs in the Authorization header
+	APIKeyPrefix = "stk_"
+	// MaxAPIKeyLength bounds the keys accepted for authentication; generated keys are shorter
+	MaxAPIKeyLength = 128
+	// MaxAPIKeyNameLength is the longest name an API key may be given
+	MaxAPIKeyNameLength = 100
+	// apiKeyRandomBytes is the entropy of a generated key
+	apiKeyRandomBytes = 32
+	// apiKeyHintLength is how much of a key, prefix included, is kept to recognise it by
+	apiKeyHintLength = len(APIKeyPrefix) + 6
+)
+
+// APIKeyScope is what requests an API key may make
+type APIKeyScope string
+
+const (
+	// APIKeyScopeFull keys may make any request their user can
+	APIKeyScopeFull APIKeyScope = "full"
+	// APIKeyScopeRead keys may only make read requests, for sharing non-destructive access with dashboards
+	// and reports
+	APIKeyScopeRead APIKeyScope = "read"
+)
+
+// APIKey is a user's credential for non-interactive clients such as CI scripts. Requests authenticated
+// with it act as the user. The key itself is only shown when it is created; just its hash is stored.
+type APIKey struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	// Hint is the start of the key, to recognise it by without revealing it
+	Hint       string      `json:"hint"`
+	Scope      APIKeyScope `json:"scope"`
+	CreatedAt  time.Time   `json:"created_at"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty"`
+}
+
+// Allows reports whether a request with method may be made with the key. Read keys are limited to the
+// methods that do not change anything.
+func (k *APIKey) Allows(method string) bool {
+	if k.Scope != APIKeyScopeRead {
+		return true
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// GenerateAPIKey returns a new random API key and its hint
+func GenerateAPIKey() (key, hint string, err error) {
+	b := make([]byte, apiKeyRandomBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:apiKeyHintLength], nil
+}
+
+// HashAPIKey returns the hex SHA-256 hash API keys are stored and looked up by. Generated keys are random
+// enough that a fast unsalted hash cannot be reversed by guessing.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether a bearer credential is an API key rather than a JWT
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// NormalizeAPIKeyName trims an API key name and checks it is present and not too long
+func NormalizeAPIKeyName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	if len(name) > MaxAPIKeyNameLength {
+		return "", fmt.Errorf("name exceeds maximum length of %d characters", MaxAPIKeyNameLength)
+	}
+	return name, nil
+}
+
+// ParseAPIKeyScope parses the scope requested for a new API key; keys have the full scope unless asked
+// otherwise
+func ParseAPIKeyScope(scope string) (APIKeyScope, error) {
+	switch APIKeyScope(strings.TrimSpace(scope)) {
+	case "", APIKeyScopeFull:
+		return APIKeyScopeFull, nil
+	case APIKeyScopeRead:
+		return APIKeyScopeRead, nil
+	default:
+		return "", fmt.Errorf("scope must be %q or %q", APIKeyScopeRead, APIKeyScopeFull)
+	}
+}
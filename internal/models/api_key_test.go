@@ -0,0 +1,113 @@
+package models
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	key, hint, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey() error = %v", err)
+	}
+	if !IsAPIKey(key) || len(key) > MaxAPIKeyLength {
+		t.Errorf("key %q is not a valid API key", key)
+	}
+	if !strings.HasPrefix(key, hint) || len(hint) >= len(key) {
+		t.Errorf("hint %q is not a strict prefix of the key", hint)
+	}
+	other, _, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey() error = %v", err)
+	}
+	if other == key {
+		t.Error("two generated keys are equal")
+	}
+	if HashAPIKey(key) == HashAPIKey(other) || HashAPIKey(key) != HashAPIKey(key) || len(HashAPIKey(key)) != 64 {
+		t.Error("HashAPIKey() does not hash keys to distinct, stable 64-character digests")
+	}
+}
+
+func TestNormalizeAPIKeyName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "trimmed", input: "  CI deploy ", want: "CI deploy"},
+		{name: "empty", input: "   ", wantErr: true},
+		{name: "too long", input: strings.Repeat("x", MaxAPIKeyNameLength+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := NormalizeAPIKeyName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeAPIKeyName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeAPIKeyName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIKey_Allows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		scope  APIKeyScope
+		method string
+		want   bool
+	}{
+		{name: "read key lists and gets", scope: APIKeyScopeRead, method: http.MethodGet, want: true},
+		{name: "read key HEAD", scope: APIKeyScopeRead, method: http.MethodHead, want: true},
+		{name: "read key creates", scope: APIKeyScopeRead, method: http.MethodPost, want: false},
+		{name: "read key updates", scope: APIKeyScopeRead, method: http.MethodPatch, want: false},
+		{name: "read key replaces", scope: APIKeyScopeRead, method: http.MethodPut, want: false},
+		{name: "read key deletes", scope: APIKeyScopeRead, method: http.MethodDelete, want: false},
+		{name: "full key deletes", scope: APIKeyScopeFull, method: http.MethodDelete, want: true},
+		{name: "key without a stored scope creates", method: http.MethodPost, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			key := &APIKey{Scope: tt.scope}
+			if got := key.Allows(tt.method); got != tt.want {
+				t.Errorf("Allows(%s) = %v, want %v", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAPIKeyScope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		want    APIKeyScope
+		wantErr bool
+	}{
+		{input: "", want: APIKeyScopeFull},
+		{input: "full", want: APIKeyScopeFull},
+		{input: " read ", want: APIKeyScopeRead},
+		{input: "read-only", wantErr: true},
+		{input: "admin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAPIKeyScope(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseAPIKeyScope(%q) = %q, %v; want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction is the kind of security event recorded in the audit log
+type AuditAction string
+
+const (
+	// AuditActionUnauthorized is a request rejected for missing or invalid credentials (401)
+	AuditActionUnauthorized AuditAction = "unauthorized"
+	// AuditActionForbidden is a request rejected for lacking access (403)
+	AuditActionForbidden AuditAction = "forbidden"
+	// AuditActionRateLimited is a request rejected by rate limiting (429)
+	AuditActionRateLimited AuditAction = "rate_limited"
+)
+
+// ParseAuditAction validates an audit action name
+func ParseAuditAction(s string) (AuditAction, error) {
+	switch action := AuditAction(s); action {
+	case AuditActionUnauthorized, AuditActionForbidden, AuditActionRateLimited:
+		return action, nil
+	}
+	return "", fmt.Errorf("invalid audit action %q (expected unauthorized, forbidden or rate_limited)", s)
+}
+
+// AuditEvent is one security event recorded by the audit middleware
+type AuditEvent struct {
+	ID uuid.UUID `json:"id"`
+	// UserID is the authenticated user the request was made as (nil when it was not authenticated)
+	UserID *uuid.UUID  `json:"user_id,omitempty"`
+	Action AuditAction `json:"action"`
+	Method string      `json:"method"`
+	// Resource is the request path
+	Resource   string    `json:"resource"`
+	IP         string    `json:"ip"`
+	StatusCode int       `json:"status_code"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AuditEventFilter selects audit events; zero fields match every event
+type AuditEventFilter struct {
+	Action AuditAction
+	// Since and Until bound CreatedAt (inclusive and exclusive)
+	Since time.Time
+	Until time.Time
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ChecklistItem is one task parsed from a markdown checklist
+type ChecklistItem struct {
+	// Text is the task text with markdown formatting removed
+	Text string
+	// Checked is true for "- [x]" items
+	Checked bool
+	// Depth is the nesting level, 0 for top-level items
+	Depth int
+	// Parent is the index of the enclosing item, or -1 for top-level items
+	Parent int
+	// Line is the 1-based line number of the item in the source
+	Line int
+}
+
+// checklistItemPattern matches a task list item: indentation, a bullet (-, *, +) or ordered marker (1. or 1)),
+// a checkbox, and the task text
+var checklistItemPattern = regexp.MustCompile(`^([ \t]*)(?:[-*+]|\d+[.)])[ \t]+\[([ xX])\][ \t]+(.*)$`)
+
+// checklistTabWidth is the number of columns a tab counts for when comparing indentation
+const checklistTabWidth = 4
+
+// ParseMarkdownChecklist extracts the task items ("- [ ] task", "- [x] done") from a markdown document, as
+// exported by Obsidian or Notion. Other lines are ignored. An item indented deeper than a previous item is
+// nested under it. Items whose text is empty once formatting is stripped are skipped.
+func ParseMarkdownChecklist(markdown string) []ChecklistItem {
+	var items []ChecklistItem
+	// open holds the indices of the items that can still take children, outermost first
+	var open []int
+	indents := make(map[int]int)
+	for i, line := range strings.Split(markdown, "\n") {
+		m := checklistItemPattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		text := StripMarkdown(m[3])
+		if text == "" {
+			continue
+		}
+		indent := checklistIndent(m[1])
+		for len(open) > 0 && indents[open[len(open)-1]] >= indent {
+			open = open[:len(open)-1]
+		}
+		item := ChecklistItem{Text: text, Checked: m[2] != " ", Parent: -1, Line: i + 1}
+		if len(open) > 0 {
+			item.Parent = open[len(open)-1]
+			item.Depth = items[item.Parent].Depth + 1
+		}
+		indents[len(items)] = indent
+		open = append(open, len(items))
+		items = append(items, item)
+	}
+	return items
+}
+
+func checklistIndent(ws string) int {
+	width := 0
+	for _, r := range ws {
+		if r == '\t' {
+			width += checklistTabWidth
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+var (
+	markdownImagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLinkPattern     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownWikiLinkPattern = regexp.MustCompile(`\[\[(?:[^\]|]*\|)?([^\]]*)\]\]`)
+	markdownCodePattern     = regexp.MustCompile("`([^`]*)`")
+	markdownSpacePattern    = regexp.MustCompile(`\s+`)
+	// markdownEmphasis matches paired emphasis markers, strongest first. Underscores only count when not
+	// inside a word so snake_case words are left alone.
+	markdownEmphasis = []struct {
+		pattern *regexp.Regexp
+		repl    string
+	}{
+		{regexp.MustCompile(`\*\*(.+?)\*\*`), "$1"},
+		{regexp.MustCompile(`~~(.+?)~~`), "$1"},
+		{regexp.MustCompile(`==(.+?)==`), "$1"},
+		{regexp.MustCompile(`\*([^*]+?)\*`), "$1"},
+		{regexp.MustCompile(`(^|[^\pL\pN_])__(.+?)__($|[^\pL\pN_])`), "$1$2$3"},
+		{regexp.MustCompile(`(^|[^\pL\pN_])_([^_]+?)_($|[^\pL\pN_])`), "$1$2$3"},
+	}
+)
+
+// StripMarkdown removes inline markdown formatting from text: links and images keep their label, wiki links
+// ([[page]] or [[page|alias]]) keep the displayed name, and code, emphasis, strikethrough and highlight
+// markers are dropped. Runs of whitespace collapse to one space.
+func StripMarkdown(text string) string {
+	text = markdownImagePattern.ReplaceAllString(text, "$1")
+	text = markdownWikiLinkPattern.ReplaceAllString(text, "$1")
+	text = markdownLinkPattern.ReplaceAllString(text, "$1")
+	text = markdownCodePattern.ReplaceAllString(text, "$1")
+	for _, e := range markdownEmphasis {
+		text = e.pattern.ReplaceAllString(text, e.repl)
+	}
+	return strings.TrimSpace(markdownSpacePattern.ReplaceAllString(text, " "))
+}
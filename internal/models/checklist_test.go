@@ -0,0 +1,109 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMarkdownChecklist(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		markdown string
+		want     []ChecklistItem
+	}{
+		{
+			name: "mixed checked and unchecked",
+			markdown: "# Groceries\n" +
+				"- [ ] Buy milk\n" +
+				"- [x] Pay rent\n" +
+				"* [X] Call mom\n" +
+				"Some notes in between\n" +
+				"+ [ ] Water plants\n",
+			want: []ChecklistItem{
+				{Text: "Buy milk", Parent: -1, Line: 2},
+				{Text: "Pay rent", Checked: true, Parent: -1, Line: 3},
+				{Text: "Call mom", Checked: true, Parent: -1, Line: 4},
+				{Text: "Water plants", Parent: -1, Line: 6},
+			},
+		},
+		{
+			name: "nested checklist",
+			markdown: "- [ ] Plan trip\n" +
+				"  - [x] Book flights\n" +
+				"  - [ ] Hotel\n" +
+				"    - [ ] Compare prices\n" +
+				"- [ ] Taxes\n" +
+				"\t- [ ] Gather receipts\n",
+			want: []ChecklistItem{
+				{Text: "Plan trip", Parent: -1, Line: 1},
+				{Text: "Book flights", Checked: true, Depth: 1, Parent: 0, Line: 2},
+				{Text: "Hotel", Depth: 1, Parent: 0, Line: 3},
+				{Text: "Compare prices", Depth: 2, Parent: 2, Line: 4},
+				{Text: "Taxes", Parent: -1, Line: 5},
+				{Text: "Gather receipts", Depth: 1, Parent: 4, Line: 6},
+			},
+		},
+		{
+			name: "dedent to an intermediate level nests under the nearest shallower item",
+			markdown: "- [ ] A\n" +
+				"      - [ ] B\n" +
+				"   - [ ] C\n",
+			want: []ChecklistItem{
+				{Text: "A", Parent: -1, Line: 1},
+				{Text: "B", Depth: 1, Parent: 0, Line: 2},
+				{Text: "C", Depth: 1, Parent: 0, Line: 3},
+			},
+		},
+		{
+			name:     "ordered lists, CRLF and plain bullets",
+			markdown: "1. [ ] First\r\n2) [x] Second\r\n- plain bullet\r\n- [ ]\r\n- [ ] **   **\r\n",
+			want: []ChecklistItem{
+				{Text: "First", Parent: -1, Line: 1},
+				{Text: "Second", Checked: true, Parent: -1, Line: 2},
+			},
+		},
+		{
+			name:     "no checklist",
+			markdown: "Just a paragraph\n- a bullet\n",
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ParseMarkdownChecklist(tt.markdown); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMarkdownChecklist() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripMarkdown(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"**Pay** the _electric_ bill", "Pay the electric bill"},
+		{"Read [the docs](https://example.com) today", "Read the docs today"},
+		{"See [[Project Plan]] and [[notes/2024|last year]]", "See Project Plan and last year"},
+		{"Run `make test`", "Run make test"},
+		{"~~Cancel~~ gym, ==important==", "Cancel gym, important"},
+		{"***urgent*** fix ![diagram](img.png)", "urgent fix diagram"},
+		{"snake_case_name stays", "snake_case_name stays"},
+		{"  spaced    out  ", "spaced out"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			if got := StripMarkdown(tt.in); got != tt.want {
+				t.Errorf("StripMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
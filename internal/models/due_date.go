@@ -0,0 +1,33 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DueDateOnlyLayout is the accepted format for due dates without a time component
+const DueDateOnlyLayout = time.DateOnly
+
+// ParseDueDate parses a due date given either as a calendar date ("2024-03-15") or an RFC3339
+// timestamp. The result is always in UTC; dateOnly reports whether the input had no time component,
+// in which case the due date is midnight UTC of that calendar date.
+func ParseDueDate(s string) (due time.Time, dateOnly bool, err error) {
+	if d, err := time.Parse(DueDateOnlyLayout, s); err == nil {
+		return d, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("expected RFC3339 (e.g. 2024-03-15T14:30:00Z) or a date (e.g. 2024-03-15): %w", err)
+	}
+	return t.UTC(), false, nil
+}
+
+// SetDueDate sets the due date and records whether it is a calendar date without a specific time.
+// A nil due clears both. Changing the due date clears the sent due-soon reminder, so the new date is reminded.
+func (t *Todo) SetDueDate(due *time.Time, dateOnly bool) {
+	if (due == nil) != (t.DueDate == nil) || (due != nil && !due.Equal(*t.DueDate)) {
+		t.Metadata.ReminderSentAt = nil
+	}
+	t.DueDate = due
+	t.Metadata.DueDateOnly = due != nil && dateOnly
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDueDate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		input        string
+		want         time.Time
+		wantDateOnly bool
+		wantErr      bool
+	}{
+		{"date only", "2024-03-15", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), true, false},
+		{"UTC timestamp", "2024-03-15T14:30:00Z", time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC), false, false},
+		{"positive offset normalized to UTC", "2024-03-15T09:00:00+02:00", time.Date(2024, 3, 15, 7, 0, 0, 0, time.UTC), false, false},
+		{"negative offset midnight is a specific time", "2024-03-15T00:00:00-05:00", time.Date(2024, 3, 15, 5, 0, 0, 0, time.UTC), false, false},
+		{"UTC midnight timestamp is a specific time", "2024-03-15T00:00:00Z", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), false, false},
+		{"invalid", "next friday", time.Time{}, false, true},
+		{"timestamp without zone", "2024-03-15T14:30:00", time.Time{}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, dateOnly, err := ParseDueDate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDueDate(%q) err = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("ParseDueDate(%q) = %v, want %v in UTC", tt.input, got, tt.want)
+			}
+			if dateOnly != tt.wantDateOnly {
+				t.Errorf("ParseDueDate(%q) dateOnly = %v, want %v", tt.input, dateOnly, tt.wantDateOnly)
+			}
+		})
+	}
+}
+
+func TestTodo_SetDueDate(t *testing.T) {
+	t.Parallel()
+
+	todo := &Todo{}
+	due := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	todo.SetDueDate(&due, true)
+	if todo.DueDate == nil || !todo.Metadata.DueDateOnly {
+		t.Fatalf("SetDueDate(date only) = %v, %v", todo.DueDate, todo.Metadata.DueDateOnly)
+	}
+	todo.SetDueDate(nil, true)
+	if todo.DueDate != nil || todo.Metadata.DueDateOnly {
+		t.Errorf("SetDueDate(nil) = %v, %v; want cleared", todo.DueDate, todo.Metadata.DueDateOnly)
+	}
+}
+
+func TestTodo_SetDueDate_ClearsReminderSent(t *testing.T) {
+	t.Parallel()
+
+	due := time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC)
+	sent := due.Add(-time.Hour)
+	tests := []struct {
+		name      string
+		due       *time.Time
+		wantClear bool
+	}{
+		{name: "same due date", due: &due, wantClear: false},
+		{name: "rescheduled", due: func() *time.Time { d := due.Add(24 * time.Hour); return &d }(), wantClear: true},
+		{name: "cleared", due: nil, wantClear: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			start := due
+			todo := &Todo{DueDate: &start, Metadata: Metadata{ReminderSentAt: &sent}}
+			todo.SetDueDate(tt.due, false)
+			if cleared := todo.Metadata.ReminderSentAt == nil; cleared != tt.wantClear {
+				t.Errorf("reminder sent cleared = %v, want %v", cleared, tt.wantClear)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// MaxExternalRefs caps how many external references one todo may carry
+	MaxExternalRefs = 20
+	// MaxExternalRefSystemLength is the maximum length of an external reference system name
+	MaxExternalRefSystemLength = 50
+	// MaxExternalRefIDLength is the maximum length of an external reference ID
+	MaxExternalRefIDLength = 255
+	// MaxExternalRefURLLength is the maximum length of an external reference URL
+	MaxExternalRefURLLength = 2048
+)
+
+// ExternalRef links a todo to an item in an external system, such as a GitHub issue or a Jira ticket
+type ExternalRef struct {
+	System string `json:"system"` // Lowercase system name, e.g. "github" or "jira"
+	ID     string `json:"id"`     // The item's ID in that system, e.g. "owner/repo#42" or "PROJ-123"
+	URL    string `json:"url,omitempty"`
+}
+
+// NormalizeExternalRefSystem trims and lowercases a system name so lookups match however it was written
+func NormalizeExternalRefSystem(system string) string {
+	return strings.ToLower(strings.TrimSpace(system))
+}
+
+// NormalizeExternalRefs validates refs and returns them with system names normalized and IDs and URLs
+// trimmed. Each ref needs a system and ID, URLs must be absolute http(s) URLs, and a system/ID pair may
+// appear only once.
+func NormalizeExternalRefs(refs []ExternalRef) ([]ExternalRef, error) {
+	if len(refs) > MaxExternalRefs {
+		return nil, fmt.Errorf("at most %d external_refs are allowed", MaxExternalRefs)
+	}
+	out := make([]ExternalRef, 0, len(refs))
+	seen := make(map[[2]string]bool, len(refs))
+	for i, ref := range refs {
+		ref = ExternalRef{
+			System: NormalizeExternalRefSystem(ref.System),
+			ID:     strings.TrimSpace(ref.ID),
+			URL:    strings.TrimSpace(ref.URL),
+		}
+		if err := ref.validate(); err != nil {
+			return nil, fmt.Errorf("external_refs[%d]: %w", i, err)
+		}
+		key := [2]string{ref.System, ref.ID}
+		if seen[key] {
+			return nil, fmt.Errorf("external_refs[%d]: duplicate reference %s/%s", i, ref.System, ref.ID)
+		}
+		seen[key] = true
+		out = append(out, ref)
+	}
+	return out, nil
+}
+
+func (r ExternalRef) validate() error {
+	if r.System == "" || len(r.System) > MaxExternalRefSystemLength {
+		return fmt.Errorf("system is required and must be at most %d characters", MaxExternalRefSystemLength)
+	}
+	if r.ID == "" || len(r.ID) > MaxExternalRefIDLength {
+		return fmt.Errorf("id is required and must be at most %d characters", MaxExternalRefIDLength)
+	}
+	if r.URL == "" {
+		return nil
+	}
+	if len(r.URL) > MaxExternalRefURLLength {
+		return fmt.Errorf("url must be at most %d characters", MaxExternalRefURLLength)
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	return nil
+}
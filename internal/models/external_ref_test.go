@@ -0,0 +1,55 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeExternalRefs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		refs    []ExternalRef
+		want    []ExternalRef
+		wantErr string
+	}{
+		{
+			name: "normalizes system and trims",
+			refs: []ExternalRef{{System: " Jira ", ID: " PROJ-1 ", URL: " https://example.atlassian.net/browse/PROJ-1 "}},
+			want: []ExternalRef{{System: "jira", ID: "PROJ-1", URL: "https://example.atlassian.net/browse/PROJ-1"}},
+		},
+		{name: "url is optional", refs: []ExternalRef{{System: "github", ID: "42"}}, want: []ExternalRef{{System: "github", ID: "42"}}},
+		{name: "empty", refs: nil, want: []ExternalRef{}},
+		{name: "missing system", refs: []ExternalRef{{ID: "42"}}, wantErr: "system is required"},
+		{name: "missing id", refs: []ExternalRef{{System: "github"}}, wantErr: "id is required"},
+		{name: "relative url", refs: []ExternalRef{{System: "github", ID: "42", URL: "/issues/42"}}, wantErr: "absolute http or https"},
+		{name: "non-http url", refs: []ExternalRef{{System: "github", ID: "42", URL: "ftp://example.com/42"}}, wantErr: "absolute http or https"},
+		{name: "duplicate", refs: []ExternalRef{{System: "github", ID: "42"}, {System: "GitHub", ID: "42"}}, wantErr: "duplicate reference github/42"},
+		{name: "too many", refs: make([]ExternalRef, MaxExternalRefs+1), wantErr: "at most"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := NormalizeExternalRefs(tt.refs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NormalizeExternalRefs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeExternalRefs() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("NormalizeExternalRefs() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ref %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
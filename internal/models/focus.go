@@ -0,0 +1,119 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// FocusWeights are the score contributions of the signals that put a todo on the focus list
+type FocusWeights struct {
+	Overdue      float64
+	DueToday     float64
+	NextHorizon  float64
+	HighPriority float64
+}
+
+// DefaultFocusWeights rank overdue above due today above the next horizon above high priority: each weight
+// exceeds the sum of the weights below it, so a weaker signal never outranks a stronger one
+var DefaultFocusWeights = FocusWeights{Overdue: 8, DueToday: 4, NextHorizon: 2, HighPriority: 1}
+
+// FocusScore returns how strongly an open todo belongs on the focus list for the calendar day of now, in now's
+// location. Completed todos score 0. A date-only due date is overdue from the day after that date, so it is
+// due today for the whole of its day; a timed due date is overdue once it has passed.
+func FocusScore(todo *Todo, now time.Time, w FocusWeights) float64 {
+	if todo.Status == TodoStatusCompleted {
+		return 0
+	}
+	var score float64
+	switch dueDay(todo, now) {
+	case dueOverdue:
+		score += w.Overdue
+	case dueToday:
+		score += w.DueToday
+	}
+	if todo.TimeHorizon == TimeHorizonNext {
+		score += w.NextHorizon
+	}
+	if todo.HighPriority() {
+		score += w.HighPriority
+	}
+	return score
+}
+
+type dueState int
+
+const (
+	dueLater dueState = iota
+	dueToday
+	dueOverdue
+)
+
+func dueDay(todo *Todo, now time.Time) dueState {
+	if todo.DueDate == nil {
+		return dueLater
+	}
+	due := *todo.DueDate
+	today := now.Format(time.DateOnly)
+	if todo.Metadata.DueDateOnly {
+		// The due date is a calendar date stored as midnight UTC; compare it with today's date as a date
+		switch day := due.UTC().Format(time.DateOnly); {
+		case day < today:
+			return dueOverdue
+		case day == today:
+			return dueToday
+		}
+		return dueLater
+	}
+	if due.Before(now) {
+		return dueOverdue
+	}
+	if due.In(now.Location()).Format(time.DateOnly) == today {
+		return dueToday
+	}
+	return dueLater
+}
+
+// RankFocus returns up to limit of todos with a positive focus score, highest score first. Ties go to the
+// earlier due date (todos without one last), then to the todo created first.
+func RankFocus(todos []*Todo, now time.Time, w FocusWeights, limit int) []*Todo {
+	type scored struct {
+		todo  *Todo
+		score float64
+	}
+	candidates := make([]scored, 0, len(todos))
+	for _, todo := range todos {
+		if s := FocusScore(todo, now, w); s > 0 {
+			candidates = append(candidates, scored{todo: todo, score: s})
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b scored) int {
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		}
+		if c := compareDueDates(a.todo.DueDate, b.todo.DueDate); c != 0 {
+			return c
+		}
+		return a.todo.CreatedAt.Compare(b.todo.CreatedAt)
+	})
+	n := min(max(limit, 0), len(candidates))
+	ranked := make([]*Todo, 0, n)
+	for _, c := range candidates[:n] {
+		ranked = append(ranked, c.todo)
+	}
+	return ranked
+}
+
+func compareDueDates(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return a.Compare(*b)
+}
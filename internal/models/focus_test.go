@@ -0,0 +1,134 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRankFocus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	at := func(s string) *time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &ts
+	}
+	todo := func(text string, due *time.Time, dateOnly bool, horizon TimeHorizon, priority Priority) *Todo {
+		td := &Todo{Text: text, TimeHorizon: horizon, Status: TodoStatusProcessed, DueDate: due, CreatedAt: now.Add(-time.Hour)}
+		td.Metadata.DueDateOnly = dateOnly
+		td.Priority = priority
+		return td
+	}
+
+	tests := []struct {
+		name    string
+		todos   []*Todo
+		weights FocusWeights
+		limit   int
+		want    []string
+	}{
+		{
+			name: "overdue beats due today beats next horizon beats high priority",
+			todos: []*Todo{
+				todo("high priority", nil, false, TimeHorizonLater, PriorityHigh),
+				todo("next", nil, false, TimeHorizonNext, ""),
+				todo("due tonight", at("2024-03-15T20:00:00Z"), false, TimeHorizonSoon, ""),
+				todo("overdue", at("2024-03-14T09:00:00Z"), false, TimeHorizonLater, ""),
+				todo("nothing", nil, false, TimeHorizonSoon, PriorityLow),
+			},
+			weights: DefaultFocusWeights,
+			limit:   5,
+			want:    []string{"overdue", "due tonight", "next", "high priority"},
+		},
+		{
+			name: "a stronger signal outranks all weaker signals combined",
+			todos: []*Todo{
+				todo("due today, next and high", at("2024-03-15T18:00:00Z"), false, TimeHorizonNext, PriorityHigh),
+				todo("overdue only", at("2024-03-15T09:00:00Z"), false, TimeHorizonLater, ""),
+			},
+			weights: DefaultFocusWeights,
+			limit:   5,
+			want:    []string{"overdue only", "due today, next and high"},
+		},
+		{
+			name: "date-only due dates are due all day and overdue from the next day",
+			todos: []*Todo{
+				todo("date today", at("2024-03-15T00:00:00Z"), true, TimeHorizonSoon, ""),
+				todo("date yesterday", at("2024-03-14T00:00:00Z"), true, TimeHorizonSoon, ""),
+				todo("date tomorrow", at("2024-03-16T00:00:00Z"), true, TimeHorizonSoon, ""),
+			},
+			weights: DefaultFocusWeights,
+			limit:   5,
+			want:    []string{"date yesterday", "date today"},
+		},
+		{
+			name: "ties go to the earlier due date, then todos without one",
+			todos: []*Todo{
+				todo("next without due", nil, false, TimeHorizonNext, ""),
+				todo("next due friday", at("2024-03-22T12:00:00Z"), false, TimeHorizonNext, ""),
+				todo("next due monday", at("2024-03-18T12:00:00Z"), false, TimeHorizonNext, ""),
+			},
+			weights: DefaultFocusWeights,
+			limit:   5,
+			want:    []string{"next due monday", "next due friday", "next without due"},
+		},
+		{
+			name: "weights are configurable",
+			todos: []*Todo{
+				todo("overdue", at("2024-03-14T09:00:00Z"), false, TimeHorizonLater, ""),
+				todo("high priority", nil, false, TimeHorizonLater, PriorityHigh),
+			},
+			weights: FocusWeights{Overdue: 1, HighPriority: 10},
+			limit:   5,
+			want:    []string{"high priority", "overdue"},
+		},
+		{
+			name: "limit and completed todos",
+			todos: []*Todo{
+				{Text: "completed overdue", Status: TodoStatusCompleted, DueDate: at("2024-03-01T00:00:00Z")},
+				todo("next a", nil, false, TimeHorizonNext, ""),
+				todo("next b", nil, false, TimeHorizonNext, ""),
+			},
+			weights: DefaultFocusWeights,
+			limit:   1,
+			want:    []string{"next a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got []string
+			for _, td := range RankFocus(tt.todos, now, tt.weights, tt.limit) {
+				got = append(got, td.Text)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("RankFocus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFocusScore_Timezone(t *testing.T) {
+	t.Parallel()
+
+	// 23:30 in New York on March 14 is already March 15 in UTC
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	now := time.Date(2024, 3, 14, 23, 30, 0, 0, ny)
+	due := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	todo := &Todo{Status: TodoStatusPending, DueDate: &due}
+
+	if got := FocusScore(todo, now, DefaultFocusWeights); got != 0 {
+		t.Errorf("score in New York = %v, want 0 (due tomorrow there)", got)
+	}
+	if got := FocusScore(todo, now.UTC(), DefaultFocusWeights); got != DefaultFocusWeights.DueToday {
+		t.Errorf("score in UTC = %v, want %v (due today)", got, DefaultFocusWeights.DueToday)
+	}
+}
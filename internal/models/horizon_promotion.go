@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+const (
+	// HorizonNextMaxDaysUntilDue is the most whole days until the due date for which a todo belongs in next.
+	// It matches the analysis prompt, which calls such todos due very soon.
+	HorizonNextMaxDaysUntilDue = 1
+	// HorizonSoonMaxDaysUntilDue is the most whole days until the due date for which a todo belongs in soon.
+	// It matches the analysis prompt, which calls such todos due within a week.
+	HorizonSoonMaxDaysUntilDue = 7
+)
+
+// DaysUntilDue returns the whole days from now until due, truncated toward zero; overdue todos are negative
+func DaysUntilDue(due, now time.Time) int {
+	return int(due.Sub(now).Hours() / 24)
+}
+
+// HorizonForDueDate returns the time horizon a due date alone implies: next when it is at most
+// HorizonNextMaxDaysUntilDue whole days away (or overdue), soon when at most HorizonSoonMaxDaysUntilDue,
+// otherwise later
+func HorizonForDueDate(due, now time.Time) TimeHorizon {
+	switch days := DaysUntilDue(due, now); {
+	case days <= HorizonNextMaxDaysUntilDue:
+		return TimeHorizonNext
+	case days <= HorizonSoonMaxDaysUntilDue:
+		return TimeHorizonSoon
+	default:
+		return TimeHorizonLater
+	}
+}
+
+// horizonUrgency orders time horizons from least (later) to most (next) urgent
+var horizonUrgency = map[TimeHorizon]int{TimeHorizonLater: 0, TimeHorizonSoon: 1, TimeHorizonNext: 2}
+
+// PromotedTimeHorizon returns the more urgent horizon the todo's approaching due date calls for, and whether
+// that is a promotion. Horizons are never demoted, and completed todos, todos without a due date and todos
+// whose horizon the user set are left alone.
+func (t *Todo) PromotedTimeHorizon(now time.Time) (TimeHorizon, bool) {
+	if t.Status == TodoStatusCompleted || t.DueDate == nil {
+		return t.TimeHorizon, false
+	}
+	if t.Metadata.TimeHorizonUserOverride != nil && *t.Metadata.TimeHorizonUserOverride {
+		return t.TimeHorizon, false
+	}
+	horizon := HorizonForDueDate(*t.DueDate, now)
+	if horizonUrgency[horizon] <= horizonUrgency[t.TimeHorizon] {
+		return t.TimeHorizon, false
+	}
+	return horizon, true
+}
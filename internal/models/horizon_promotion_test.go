@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHorizonForDueDate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		until time.Duration
+		want  TimeHorizon
+	}{
+		{"overdue", -3 * 24 * time.Hour, TimeHorizonNext},
+		{"due today", 6 * time.Hour, TimeHorizonNext},
+		{"one day away", 47 * time.Hour, TimeHorizonNext},
+		{"two days away", 48 * time.Hour, TimeHorizonSoon},
+		{"a week away", 7*24*time.Hour + 23*time.Hour, TimeHorizonSoon},
+		{"eight days away", 8 * 24 * time.Hour, TimeHorizonLater},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := HorizonForDueDate(now.Add(tt.until), now); got != tt.want {
+				t.Errorf("HorizonForDueDate(now+%v) = %s, want %s", tt.until, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodo_PromotedTimeHorizon(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	tomorrow := now.Add(24 * time.Hour)
+	nextWeek := now.Add(5 * 24 * time.Hour)
+	override := true
+	tests := []struct {
+		name string
+		todo Todo
+		want TimeHorizon
+		ok   bool
+	}{
+		{"later due tomorrow", Todo{TimeHorizon: TimeHorizonLater, DueDate: &tomorrow}, TimeHorizonNext, true},
+		{"later due this week", Todo{TimeHorizon: TimeHorizonLater, DueDate: &nextWeek}, TimeHorizonSoon, true},
+		{"soon due tomorrow", Todo{TimeHorizon: TimeHorizonSoon, DueDate: &tomorrow}, TimeHorizonNext, true},
+		{"next is never demoted", Todo{TimeHorizon: TimeHorizonNext, DueDate: &nextWeek}, TimeHorizonNext, false},
+		{"no due date", Todo{TimeHorizon: TimeHorizonLater}, TimeHorizonLater, false},
+		{"completed", Todo{TimeHorizon: TimeHorizonLater, DueDate: &tomorrow, Status: TodoStatusCompleted}, TimeHorizonLater, false},
+		{"user override", Todo{TimeHorizon: TimeHorizonLater, DueDate: &tomorrow, Metadata: Metadata{TimeHorizonUserOverride: &override}}, TimeHorizonLater, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := tt.todo.PromotedTimeHorizon(now)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("PromotedTimeHorizon() = %s, %v, want %s, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -9,4 +9,6 @@ type JWTClaims struct {
 	Iat   int64  `json:"iat"`   // Issued at
 	Iss   string `json:"iss"`   // Issuer
 	Aud   string `json:"aud"`   // Audience
+	EmailVerified *bool `json:"email_verified,omitempty"` // Whether the provider verified the email (nil when not stated)
+	Roles []string `json:"roles,omitempty"` // Roles or groups from the verifier's roles claim
 }
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // TagSource represents the source of a tag (user or AI)
 type TagSource string
 
@@ -12,9 +14,13 @@ const (
 type Metadata struct {
 	CategoryTags          []string             `json:"category_tags,omitempty"`
 	TagSources            map[string]TagSource `json:"tag_sources,omitempty"` // Maps tag name to its source
-	Priority              *string              `json:"priority,omitempty"`
 	Context               []string             `json:"context,omitempty"`
 	Duration              *string              `json:"duration,omitempty"`
 	TimeEntered           *string              `json:"time_entered,omitempty"` // ISO8601 timestamp when todo was entered (for AI context)
 	TimeHorizonUserOverride *bool              `json:"time_horizon_user_override"` // True if user manually set time_horizon
+	ReminderPolicy        *ReminderPolicy      `json:"reminder_policy,omitempty"` // Optional due-date reminder escalation
+	AnalysisDisabled      bool                 `json:"analysis_disabled,omitempty"` // True if the user opted this todo out of AI analysis
+	DueDateOnly           bool                 `json:"due_date_only,omitempty"` // True if the due date was given as a calendar date without a time
+	ExternalRefs          []ExternalRef        `json:"external_refs,omitempty"` // Links to items in external systems (GitHub issues, Jira tickets)
+	ReminderSentAt        *time.Time           `json:"reminder_sent_at,omitempty"` // When the due-soon reminder was scheduled for the current due date
 }
@@ -1,8 +1,12 @@
 package models
 
 // MergeTags merges AI tags with user tags, with user tags taking precedence
-// Returns the merged tags and updated tag sources map
-func (m *Metadata) MergeTags(aiTags []string, userTags []string) {
+// Returns true if the tags or their sources changed; merging tags that are already present is a no-op
+func (m *Metadata) MergeTags(aiTags []string, userTags []string) bool {
+	if m.hasMergedTags(aiTags, userTags) {
+		return false
+	}
+
 	// Initialize tag sources if nil
 	if m.TagSources == nil {
 		m.TagSources = make(map[string]TagSource)
@@ -22,10 +26,33 @@ func (m *Metadata) MergeTags(aiTags []string, userTags []string) {
 		m.CategoryTags = appendIfNotExists(m.CategoryTags, tag)
 		m.TagSources[tag] = TagSourceUser
 	}
+	return true
+}
+
+// hasMergedTags reports whether merging aiTags and userTags would leave the metadata unchanged
+func (m *Metadata) hasMergedTags(aiTags []string, userTags []string) bool {
+	for _, tag := range userTags {
+		if !contains(m.CategoryTags, tag) || m.TagSources[tag] != TagSourceUser {
+			return false
+		}
+	}
+	for _, tag := range aiTags {
+		if contains(userTags, tag) {
+			continue
+		}
+		if !contains(m.CategoryTags, tag) || m.TagSources[tag] != TagSourceAI {
+			return false
+		}
+	}
+	return true
 }
 
-// SetUserTags sets tags as user-defined
+// SetUserTags sets tags as user-defined; reassigning identical user tags leaves the metadata untouched
 func (m *Metadata) SetUserTags(tags []string) {
+	if m.hasUserTags(tags) {
+		return
+	}
+
 	if m.TagSources == nil {
 		m.TagSources = make(map[string]TagSource)
 	}
@@ -36,6 +63,45 @@ func (m *Metadata) SetUserTags(tags []string) {
 	}
 }
 
+// hasUserTags reports whether tags exactly match the current tags and are all user-defined
+func (m *Metadata) hasUserTags(tags []string) bool {
+	if len(tags) != len(m.CategoryTags) {
+		return false
+	}
+	for i, tag := range tags {
+		if m.CategoryTags[i] != tag || m.TagSources[tag] != TagSourceUser {
+			return false
+		}
+	}
+	return true
+}
+
+// AddUserTags adds tags as user-defined, keeping the existing tags. A tag already present from the AI becomes
+// user-defined. Returns true if any tag or source changed.
+func (m *Metadata) AddUserTags(tags []string) bool {
+	changed := false
+	for _, tag := range tags {
+		if contains(m.CategoryTags, tag) && m.TagSources[tag] == TagSourceUser {
+			continue
+		}
+		m.AddTag(tag, TagSourceUser)
+		changed = true
+	}
+	return changed
+}
+
+// RemoveTags removes tags whatever their source, keeping the rest. Returns true if any tag was removed.
+func (m *Metadata) RemoveTags(tags []string) bool {
+	changed := false
+	for _, tag := range tags {
+		if contains(m.CategoryTags, tag) {
+			m.RemoveTag(tag)
+			changed = true
+		}
+	}
+	return changed
+}
+
 // RemoveTag removes a tag from the metadata
 func (m *Metadata) RemoveTag(tag string) {
 	// Remove from category tags
@@ -96,6 +162,53 @@ func (m *Metadata) GetAITags() []string {
 	return aiTags
 }
 
+// RemoveAITags removes all AI-generated tags, keeping user tags and tags without a recorded source
+// Returns true if any tag was removed
+func (m *Metadata) RemoveAITags() bool {
+	aiTags := m.GetAITags()
+	for _, tag := range aiTags {
+		m.RemoveTag(tag)
+	}
+	return len(aiTags) > 0
+}
+
+// RenameTags replaces each tag in from with to, merging them into one tag where the first of them (or to,
+// if already present) was. to becomes user-defined if any merged tag was, otherwise AI-generated if any was;
+// without a recorded source on any of them it gets none. Returns true if any tag in from was present.
+func (m *Metadata) RenameTags(from []string, to string) bool {
+	renamed := false
+	var source TagSource
+	newTags := make([]string, 0, len(m.CategoryTags))
+	placed := false
+	for _, tag := range m.CategoryTags {
+		isFrom := tag != to && contains(from, tag)
+		if !isFrom && tag != to {
+			newTags = append(newTags, tag)
+			continue
+		}
+		renamed = renamed || isFrom
+		if s := m.TagSources[tag]; s == TagSourceUser || (s == TagSourceAI && source == "") {
+			source = s
+		}
+		if !placed {
+			newTags = append(newTags, to)
+			placed = true
+		}
+	}
+	if !renamed {
+		return false
+	}
+
+	m.CategoryTags = newTags
+	for _, tag := range from {
+		delete(m.TagSources, tag)
+	}
+	if source != "" {
+		m.AddTag(to, source)
+	}
+	return true
+}
+
 // Helper functions
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
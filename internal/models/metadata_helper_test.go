@@ -0,0 +1,203 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMetadata_SetUserTags_NoOpOnIdenticalTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		metadata    Metadata
+		tags        []string
+		wantChanged bool
+	}{
+		{
+			name: "identical user tags",
+			metadata: Metadata{
+				CategoryTags: []string{"work", "urgent"},
+				TagSources:   map[string]TagSource{"work": TagSourceUser, "urgent": TagSourceUser},
+			},
+			tags:        []string{"work", "urgent"},
+			wantChanged: false,
+		},
+		{
+			name: "same tags but AI sourced",
+			metadata: Metadata{
+				CategoryTags: []string{"work"},
+				TagSources:   map[string]TagSource{"work": TagSourceAI},
+			},
+			tags:        []string{"work"},
+			wantChanged: true,
+		},
+		{
+			name: "tag added",
+			metadata: Metadata{
+				CategoryTags: []string{"work"},
+				TagSources:   map[string]TagSource{"work": TagSourceUser},
+			},
+			tags:        []string{"work", "home"},
+			wantChanged: true,
+		},
+		{
+			name:        "empty to empty",
+			metadata:    Metadata{},
+			tags:        []string{},
+			wantChanged: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := tt.metadata
+			before := m.CategoryTags
+			m.SetUserTags(tt.tags)
+			if !slices.Equal(m.CategoryTags, tt.tags) {
+				t.Errorf("CategoryTags = %v, want %v", m.CategoryTags, tt.tags)
+			}
+			// A no-op keeps the metadata's own slice and map rather than replacing or creating them
+			if kept := len(before) == len(m.CategoryTags) && (len(before) == 0 || &before[0] == &m.CategoryTags[0]) &&
+				(tt.metadata.TagSources != nil || m.TagSources == nil); kept == tt.wantChanged {
+				t.Errorf("metadata replaced = %v, want %v", !kept, tt.wantChanged)
+			}
+			for _, tag := range tt.tags {
+				if m.TagSources[tag] != TagSourceUser {
+					t.Errorf("TagSources[%q] = %q, want user", tag, m.TagSources[tag])
+				}
+			}
+		})
+	}
+}
+
+func TestMetadata_MergeTags_NoOpOnIdenticalTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		aiTags      []string
+		userTags    []string
+		wantChanged bool
+		wantTags    []string
+	}{
+		{"identical merge", []string{"errands"}, []string{"work"}, false, []string{"work", "errands"}},
+		{"AI suggests existing user tag", []string{"work"}, []string{"work"}, false, []string{"work", "errands"}},
+		{"new AI tag", []string{"errands", "shopping"}, []string{"work"}, true, []string{"work", "errands", "shopping"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := Metadata{
+				CategoryTags: []string{"work", "errands"},
+				TagSources:   map[string]TagSource{"work": TagSourceUser, "errands": TagSourceAI},
+			}
+			if got := m.MergeTags(tt.aiTags, tt.userTags); got != tt.wantChanged {
+				t.Errorf("MergeTags() = %v, want %v", got, tt.wantChanged)
+			}
+			if len(m.CategoryTags) != len(tt.wantTags) {
+				t.Fatalf("CategoryTags = %v, want %v", m.CategoryTags, tt.wantTags)
+			}
+			for i, tag := range tt.wantTags {
+				if m.CategoryTags[i] != tag {
+					t.Errorf("CategoryTags[%d] = %q, want %q", i, m.CategoryTags[i], tag)
+				}
+			}
+		})
+	}
+}
+
+func TestMetadata_RemoveAITags(t *testing.T) {
+	t.Parallel()
+
+	m := &Metadata{
+		CategoryTags: []string{"work", "urgent", "legacy", "meeting"},
+		TagSources:   map[string]TagSource{"work": TagSourceUser, "urgent": TagSourceAI, "meeting": TagSourceAI},
+	}
+	if !m.RemoveAITags() {
+		t.Fatal("expected RemoveAITags to report a change")
+	}
+	if want := []string{"work", "legacy"}; !slices.Equal(m.CategoryTags, want) {
+		t.Errorf("CategoryTags = %v, want %v", m.CategoryTags, want)
+	}
+	if _, ok := m.TagSources["urgent"]; ok {
+		t.Error("expected AI tag source to be removed")
+	}
+	if m.TagSources["work"] != TagSourceUser {
+		t.Error("expected user tag source to be kept")
+	}
+	if m.RemoveAITags() {
+		t.Error("expected second RemoveAITags to be a no-op")
+	}
+}
+
+func TestMetadata_AddUserTagsAndRemoveTags(t *testing.T) {
+	t.Parallel()
+
+	m := Metadata{
+		CategoryTags: []string{"work", "errands"},
+		TagSources:   map[string]TagSource{"work": TagSourceUser, "errands": TagSourceAI},
+	}
+	if m.AddUserTags([]string{"work"}) {
+		t.Error("AddUserTags() of an existing user tag should report no change")
+	}
+	if !m.AddUserTags([]string{"errands", "urgent"}) {
+		t.Error("AddUserTags() should report a change")
+	}
+	if !slices.Equal(m.CategoryTags, []string{"work", "errands", "urgent"}) || m.TagSources["errands"] != TagSourceUser {
+		t.Errorf("after add: tags = %v, sources = %v", m.CategoryTags, m.TagSources)
+	}
+	if m.RemoveTags([]string{"missing"}) {
+		t.Error("RemoveTags() of an absent tag should report no change")
+	}
+	if !m.RemoveTags([]string{"work", "urgent"}) {
+		t.Error("RemoveTags() should report a change")
+	}
+	if !slices.Equal(m.CategoryTags, []string{"errands"}) || len(m.TagSources) != 1 {
+		t.Errorf("after remove: tags = %v, sources = %v", m.CategoryTags, m.TagSources)
+	}
+}
+
+func TestMetadata_RenameTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		tags        []string
+		sources     map[string]TagSource
+		from        []string
+		to          string
+		wantChanged bool
+		wantTags    []string
+		wantSource  TagSource
+	}{
+		{name: "rename keeps position and source", tags: []string{"work", "grocery", "urgent"}, sources: map[string]TagSource{"grocery": TagSourceAI}, from: []string{"grocery"}, to: "groceries", wantChanged: true, wantTags: []string{"work", "groceries", "urgent"}, wantSource: TagSourceAI},
+		{name: "merge into an existing tag", tags: []string{"groceries", "grocery"}, sources: map[string]TagSource{"groceries": TagSourceAI, "grocery": TagSourceAI}, from: []string{"grocery"}, to: "groceries", wantChanged: true, wantTags: []string{"groceries"}, wantSource: TagSourceAI},
+		{name: "user source wins", tags: []string{"grocery", "shopping", "groceries"}, sources: map[string]TagSource{"grocery": TagSourceAI, "shopping": TagSourceUser, "groceries": TagSourceAI}, from: []string{"grocery", "shopping"}, to: "groceries", wantChanged: true, wantTags: []string{"groceries"}, wantSource: TagSourceUser},
+		{name: "no recorded source", tags: []string{"grocery"}, from: []string{"grocery"}, to: "groceries", wantChanged: true, wantTags: []string{"groceries"}},
+		{name: "from absent", tags: []string{"groceries", "work"}, sources: map[string]TagSource{"groceries": TagSourceUser}, from: []string{"grocery"}, to: "groceries", wantTags: []string{"groceries", "work"}, wantSource: TagSourceUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := Metadata{CategoryTags: tt.tags, TagSources: tt.sources}
+			if changed := m.RenameTags(tt.from, tt.to); changed != tt.wantChanged {
+				t.Errorf("RenameTags() = %v, want %v", changed, tt.wantChanged)
+			}
+			if !slices.Equal(m.CategoryTags, tt.wantTags) {
+				t.Errorf("CategoryTags = %v, want %v", m.CategoryTags, tt.wantTags)
+			}
+			if m.TagSources[tt.to] != tt.wantSource {
+				t.Errorf("source of %q = %q, want %q", tt.to, m.TagSources[tt.to], tt.wantSource)
+			}
+			for _, tag := range tt.from {
+				if _, ok := m.TagSources[tag]; ok {
+					t.Errorf("source of renamed tag %q kept", tag)
+				}
+			}
+		})
+	}
+}
@@ -25,13 +25,6 @@ func TestMetadata_JSONSerialization(t *testing.T) {
 			},
 			jsonStr: `{"category_tags":["work","urgent"],"time_horizon_user_override":null}`,
 		},
-		{
-			name: "with priority",
-			metadata: Metadata{
-				Priority: stringPtr("high"),
-			},
-			jsonStr: `{"priority":"high","time_horizon_user_override":null}`,
-		},
 		{
 			name: "with context",
 			metadata: Metadata{
@@ -50,11 +43,10 @@ func TestMetadata_JSONSerialization(t *testing.T) {
 			name: "all fields",
 			metadata: Metadata{
 				CategoryTags: []string{"work"},
-				Priority:     stringPtr("medium"),
 				Context:      []string{"office"},
 				Duration:     stringPtr("1h"),
 			},
-			jsonStr: `{"category_tags":["work"],"priority":"medium","context":["office"],"duration":"1h","time_horizon_user_override":null}`,
+			jsonStr: `{"category_tags":["work"],"context":["office"],"duration":"1h","time_horizon_user_override":null}`,
 		},
 		{
 			name: "with time_entered",
@@ -67,12 +59,11 @@ func TestMetadata_JSONSerialization(t *testing.T) {
 			name: "all fields including time_entered",
 			metadata: Metadata{
 				CategoryTags: []string{"work"},
-				Priority:     stringPtr("high"),
 				Context:      []string{"home"},
 				Duration:     stringPtr("30m"),
 				TimeEntered:  stringPtr("2024-03-15T14:30:00Z"),
 			},
-			jsonStr: `{"category_tags":["work"],"priority":"high","context":["home"],"duration":"30m","time_entered":"2024-03-15T14:30:00Z","time_horizon_user_override":null}`,
+			jsonStr: `{"category_tags":["work"],"context":["home"],"duration":"30m","time_entered":"2024-03-15T14:30:00Z","time_horizon_user_override":null}`,
 		},
 		{
 			name: "with time_horizon_user_override true",
@@ -92,11 +83,10 @@ func TestMetadata_JSONSerialization(t *testing.T) {
 			name: "all fields including time_horizon_user_override",
 			metadata: Metadata{
 				CategoryTags:          []string{"work"},
-				Priority:              stringPtr("high"),
 				TimeEntered:           stringPtr("2024-03-15T14:30:00Z"),
 				TimeHorizonUserOverride: boolPtr(true),
 			},
-			jsonStr: `{"category_tags":["work"],"priority":"high","time_entered":"2024-03-15T14:30:00Z","time_horizon_user_override":true}`,
+			jsonStr: `{"category_tags":["work"],"time_entered":"2024-03-15T14:30:00Z","time_horizon_user_override":true}`,
 		},
 	}
 
@@ -196,13 +186,6 @@ func metadataEqual(a, b Metadata) bool {
 		}
 	}
 
-	if (a.Priority == nil) != (b.Priority == nil) {
-		return false
-	}
-	if a.Priority != nil && b.Priority != nil && *a.Priority != *b.Priority {
-		return false
-	}
-
 	if len(a.Context) != len(b.Context) {
 		return false
 	}
@@ -16,6 +16,14 @@ type OIDCConfig struct {
 	ClientSecret *string  `json:"client_secret,omitempty"` // Optional for public OIDC clients
 	RedirectURI string    `json:"redirect_uri"`
 	JWKSUrl     *string   `json:"jwks_url,omitempty"`
+	AuthorizationEndpoint *string `json:"authorization_endpoint,omitempty"` // Optional: from the issuer's discovery document
+	TokenEndpoint *string `json:"token_endpoint,omitempty"` // Optional: from the issuer's discovery document
+	Audiences   []string  `json:"audiences,omitempty"` // Token audiences accepted besides the client ID
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+// AllowedAudiences returns the token audiences accepted for this provider: the client ID and any extra audiences
+func (c *OIDCConfig) AllowedAudiences() []string {
+	return append([]string{c.ClientID}, c.Audiences...)
+}
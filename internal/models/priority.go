@@ -0,0 +1,33 @@
+package models
+
+import "slices"
+
+// Priority is the urgency the user sets on a todo, independent of the AI-managed time horizon. The zero
+// value means the user has not set one.
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// Priorities lists the valid priorities from least to most urgent
+var Priorities = []Priority{PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+// Valid reports whether p is one of Priorities
+func (p Priority) Valid() bool {
+	return slices.Contains(Priorities, p)
+}
+
+// Rank returns p's position in Priorities, so a more urgent priority ranks higher, or -1 when p is unset
+// or invalid
+func (p Priority) Rank() int {
+	return slices.Index(Priorities, p)
+}
+
+// HighPriority reports whether the user marked the todo high or urgent
+func (t *Todo) HighPriority() bool {
+	return t.Priority.Rank() >= PriorityHigh.Rank()
+}
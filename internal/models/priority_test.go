@@ -0,0 +1,59 @@
+package models
+
+import "testing"
+
+func TestPriority_Valid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		priority Priority
+		valid    bool
+		rank     int
+	}{
+		{PriorityLow, true, 0},
+		{PriorityMedium, true, 1},
+		{PriorityHigh, true, 2},
+		{PriorityUrgent, true, 3},
+		{"", false, -1},
+		{"critical", false, -1},
+		{"High", false, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.priority), func(t *testing.T) {
+			t.Parallel()
+			if got := tt.priority.Valid(); got != tt.valid {
+				t.Errorf("Valid() = %v, want %v", got, tt.valid)
+			}
+			if got := tt.priority.Rank(); got != tt.rank {
+				t.Errorf("Rank() = %d, want %d", got, tt.rank)
+			}
+		})
+	}
+}
+
+func TestTodo_HighPriority(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		priority Priority
+		want     bool
+	}{
+		{name: "urgent", priority: PriorityUrgent, want: true},
+		{name: "high", priority: PriorityHigh, want: true},
+		{name: "medium", priority: PriorityMedium},
+		{name: "unset"},
+		{name: "low", priority: PriorityLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todo := &Todo{Priority: tt.priority}
+			if got := todo.HighPriority(); got != tt.want {
+				t.Errorf("HighPriority() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxOverdueReminders caps how many overdue reminders a policy may request
+const MaxOverdueReminders = 20
+
+// ReminderKind identifies which stage of an escalation policy a reminder belongs to
+type ReminderKind string
+
+const (
+	ReminderKindLead    ReminderKind = "lead"
+	ReminderKindDue     ReminderKind = "due"
+	ReminderKindOverdue ReminderKind = "overdue"
+	ReminderKindDueSoon ReminderKind = "due_soon"
+)
+
+// ReminderPolicy describes when a todo with a due date should trigger notifications.
+// A policy may notify ahead of the due date, at the due date, and repeatedly once overdue.
+type ReminderPolicy struct {
+	LeadTimeMinutes        int  `json:"lead_time_minutes,omitempty"`        // Notify this many minutes before due (0 = disabled)
+	AtDue                  bool `json:"at_due,omitempty"`                   // Notify at the due time
+	OverdueIntervalMinutes int  `json:"overdue_interval_minutes,omitempty"` // Interval between overdue reminders
+	MaxOverdueReminders    int  `json:"max_overdue_reminders,omitempty"`    // Number of overdue reminders to send
+}
+
+// ReminderStep is a single scheduled notification in an escalation chain
+type ReminderStep struct {
+	Index int
+	Kind  ReminderKind
+	At    time.Time
+}
+
+// Validate checks that the policy values are within allowed bounds
+func (p *ReminderPolicy) Validate() error {
+	if p.LeadTimeMinutes < 0 {
+		return fmt.Errorf("lead_time_minutes must not be negative")
+	}
+	if p.OverdueIntervalMinutes < 0 {
+		return fmt.Errorf("overdue_interval_minutes must not be negative")
+	}
+	if p.MaxOverdueReminders < 0 || p.MaxOverdueReminders > MaxOverdueReminders {
+		return fmt.Errorf("max_overdue_reminders must be between 0 and %d", MaxOverdueReminders)
+	}
+	if p.MaxOverdueReminders > 0 && p.OverdueIntervalMinutes == 0 {
+		return fmt.Errorf("overdue_interval_minutes is required when max_overdue_reminders is set")
+	}
+	return nil
+}
+
+// Steps returns the full escalation chain for the given due date in chronological order
+func (p *ReminderPolicy) Steps(dueDate time.Time) []ReminderStep {
+	var steps []ReminderStep
+	add := func(kind ReminderKind, at time.Time) {
+		steps = append(steps, ReminderStep{Index: len(steps), Kind: kind, At: at})
+	}
+	if p.LeadTimeMinutes > 0 {
+		add(ReminderKindLead, dueDate.Add(-time.Duration(p.LeadTimeMinutes)*time.Minute))
+	}
+	if p.AtDue {
+		add(ReminderKindDue, dueDate)
+	}
+	if p.OverdueIntervalMinutes > 0 {
+		interval := time.Duration(p.OverdueIntervalMinutes) * time.Minute
+		for i := 1; i <= p.MaxOverdueReminders; i++ {
+			add(ReminderKindOverdue, dueDate.Add(time.Duration(i)*interval))
+		}
+	}
+	return steps
+}
+
+// ReminderKey identifies the current escalation chain for a todo. Pending reminder jobs carry the key
+// they were scheduled with; when the todo is completed or rescheduled the key changes (or becomes empty)
+// and the stale chain is dropped.
+func (t *Todo) ReminderKey() string {
+	p := t.Metadata.ReminderPolicy
+	if p == nil || t.DueDate == nil || t.Status == TodoStatusCompleted {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d:%t:%d:%d", t.DueDate.Unix(), p.LeadTimeMinutes, p.AtDue, p.OverdueIntervalMinutes, p.MaxOverdueReminders)
+}
+
+// NextReminderStep returns the first step of the todo's escalation chain that is due after the given time.
+// Returns false if the todo has no active policy or the chain has ended.
+func (t *Todo) NextReminderStep(after time.Time) (ReminderStep, bool) {
+	if t.ReminderKey() == "" {
+		return ReminderStep{}, false
+	}
+	for _, step := range t.Metadata.ReminderPolicy.Steps(*t.DueDate) {
+		if step.At.After(after) {
+			return step, true
+		}
+	}
+	return ReminderStep{}, false
+}
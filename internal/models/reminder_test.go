@@ -0,0 +1,135 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReminderPolicy_Steps(t *testing.T) {
+	t.Parallel()
+
+	due := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		policy    ReminderPolicy
+		wantKinds []ReminderKind
+		wantAt    []time.Time
+	}{
+		{
+			name:      "full escalation",
+			policy:    ReminderPolicy{LeadTimeMinutes: 60, AtDue: true, OverdueIntervalMinutes: 30, MaxOverdueReminders: 2},
+			wantKinds: []ReminderKind{ReminderKindLead, ReminderKindDue, ReminderKindOverdue, ReminderKindOverdue},
+			wantAt:    []time.Time{due.Add(-time.Hour), due, due.Add(30 * time.Minute), due.Add(time.Hour)},
+		},
+		{
+			name:      "due only",
+			policy:    ReminderPolicy{AtDue: true},
+			wantKinds: []ReminderKind{ReminderKindDue},
+			wantAt:    []time.Time{due},
+		},
+		{
+			name:      "overdue interval without max sends nothing overdue",
+			policy:    ReminderPolicy{LeadTimeMinutes: 15, OverdueIntervalMinutes: 30},
+			wantKinds: []ReminderKind{ReminderKindLead},
+			wantAt:    []time.Time{due.Add(-15 * time.Minute)},
+		},
+		{
+			name:   "empty policy",
+			policy: ReminderPolicy{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			steps := tt.policy.Steps(due)
+			if len(steps) != len(tt.wantKinds) {
+				t.Fatalf("len(steps) = %d, want %d", len(steps), len(tt.wantKinds))
+			}
+			for i, step := range steps {
+				if step.Index != i {
+					t.Errorf("steps[%d].Index = %d", i, step.Index)
+				}
+				if step.Kind != tt.wantKinds[i] {
+					t.Errorf("steps[%d].Kind = %s, want %s", i, step.Kind, tt.wantKinds[i])
+				}
+				if !step.At.Equal(tt.wantAt[i]) {
+					t.Errorf("steps[%d].At = %v, want %v", i, step.At, tt.wantAt[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReminderPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		policy  ReminderPolicy
+		wantErr bool
+	}{
+		{"valid", ReminderPolicy{LeadTimeMinutes: 10, AtDue: true, OverdueIntervalMinutes: 60, MaxOverdueReminders: 3}, false},
+		{"negative lead", ReminderPolicy{LeadTimeMinutes: -1}, true},
+		{"negative interval", ReminderPolicy{OverdueIntervalMinutes: -1}, true},
+		{"too many overdue", ReminderPolicy{OverdueIntervalMinutes: 10, MaxOverdueReminders: MaxOverdueReminders + 1}, true},
+		{"max without interval", ReminderPolicy{MaxOverdueReminders: 2}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTodo_ReminderKey(t *testing.T) {
+	t.Parallel()
+
+	due := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	policy := &ReminderPolicy{AtDue: true, OverdueIntervalMinutes: 60, MaxOverdueReminders: 2}
+	todo := &Todo{Status: TodoStatusPending, DueDate: &due, Metadata: Metadata{ReminderPolicy: policy}}
+	key := todo.ReminderKey()
+	if key == "" {
+		t.Fatal("expected non-empty reminder key")
+	}
+
+	rescheduled := due.Add(24 * time.Hour)
+	todo.DueDate = &rescheduled
+	if todo.ReminderKey() == key {
+		t.Error("expected reminder key to change when rescheduled")
+	}
+
+	todo.DueDate = &due
+	todo.Status = TodoStatusCompleted
+	if todo.ReminderKey() != "" {
+		t.Error("expected empty reminder key for completed todo")
+	}
+}
+
+func TestTodo_NextReminderStep(t *testing.T) {
+	t.Parallel()
+
+	due := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	todo := &Todo{
+		Status:   TodoStatusPending,
+		DueDate:  &due,
+		Metadata: Metadata{ReminderPolicy: &ReminderPolicy{LeadTimeMinutes: 60, AtDue: true, OverdueIntervalMinutes: 60, MaxOverdueReminders: 1}},
+	}
+
+	step, ok := todo.NextReminderStep(due.Add(-2 * time.Hour))
+	if !ok || step.Kind != ReminderKindLead {
+		t.Errorf("NextReminderStep before lead = %+v, %v; want lead", step, ok)
+	}
+	step, ok = todo.NextReminderStep(due.Add(-30 * time.Minute))
+	if !ok || step.Kind != ReminderKindDue || step.Index != 1 {
+		t.Errorf("NextReminderStep after lead = %+v, %v; want due", step, ok)
+	}
+	if _, ok = todo.NextReminderStep(due.Add(2 * time.Hour)); ok {
+		t.Error("expected no step after chain ends")
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+// UserRole is a user's authorization role
+type UserRole string
+
+const (
+	// RoleUser may manage their own todos; every user has at least this role
+	RoleUser UserRole = "user"
+	// RoleAdmin may additionally use the operator endpoints (audit log, admin routes)
+	RoleAdmin UserRole = "admin"
+)
+
+// HasRole reports whether the user has role. Admins have every role; a user without a stored role is a
+// regular user.
+func (u *User) HasRole(role UserRole) bool {
+	return role == RoleUser || u.Role == role || u.Role == RoleAdmin
+}
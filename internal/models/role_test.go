@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+func TestUser_HasRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		role UserRole
+		want UserRole
+		ok   bool
+	}{
+		{name: "admin is admin", role: RoleAdmin, want: RoleAdmin, ok: true},
+		{name: "admin is user", role: RoleAdmin, want: RoleUser, ok: true},
+		{name: "user is not admin", role: RoleUser, want: RoleAdmin, ok: false},
+		{name: "no role is user", role: "", want: RoleUser, ok: true},
+		{name: "no role is not admin", role: "", want: RoleAdmin, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			user := &User{Role: tt.role}
+			if got := user.HasRole(tt.want); got != tt.ok {
+				t.Errorf("HasRole(%q) = %v, want %v", tt.want, got, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxTagLength is the maximum length of a tag in characters (not bytes)
+const MaxTagLength = 50
+
+// NormalizeTags normalizes each tag with NormalizeTagName and drops empty tags, tags longer than
+// MaxTagLength characters, tags containing non-printable characters, and duplicates, preserving order.
+func NormalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTagName(tag)
+		if tag == "" || seen[tag] || utf8.RuneCountInString(tag) > MaxTagLength || !isPrintableTag(tag) {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	return out
+}
+
+func isPrintableTag(tag string) bool {
+	for _, r := range tag {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
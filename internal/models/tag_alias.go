@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTagAliases is the maximum number of tag aliases a user can define
+const MaxTagAliases = 100
+
+// MaxTagAliasLength is the maximum length of an alias or canonical tag
+const MaxTagAliasLength = 50
+
+// NormalizeTagName lowercases a tag and collapses any Unicode whitespace to single spaces, so tags and
+// aliases match regardless of case or spacing. Non-ASCII letters are kept as they are.
+func NormalizeTagName(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// CanonicalTag returns the canonical form of tag, or tag unchanged when it is not an alias.
+// It is safe to call on a nil context.
+func (c *AIContext) CanonicalTag(tag string) string {
+	if c == nil || len(c.TagAliases) == 0 {
+		return tag
+	}
+	if canonical, ok := c.TagAliases[NormalizeTagName(tag)]; ok {
+		return canonical
+	}
+	return tag
+}
+
+// CanonicalizeTags rewrites aliases in tags to their canonical form, dropping duplicates
+// that result from the rewrite while preserving order.
+func (c *AIContext) CanonicalizeTags(tags []string) []string {
+	if c == nil || len(c.TagAliases) == 0 {
+		return tags
+	}
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		canonical := c.CanonicalTag(tag)
+		if seen[canonical] {
+			continue
+		}
+		seen[canonical] = true
+		out = append(out, canonical)
+	}
+	return out
+}
+
+// SetTagAlias validates and stores an alias for canonical. Both are normalized first. Chains are
+// rejected: the canonical tag cannot itself be an alias, and an alias cannot be another alias's target.
+func (c *AIContext) SetTagAlias(alias, canonical string) error {
+	alias = NormalizeTagName(alias)
+	canonical = NormalizeTagName(canonical)
+	if alias == "" || canonical == "" {
+		return errors.New("alias and canonical tag are required")
+	}
+	if utf8.RuneCountInString(alias) > MaxTagAliasLength || utf8.RuneCountInString(canonical) > MaxTagAliasLength {
+		return fmt.Errorf("alias and canonical tag must be at most %d characters", MaxTagAliasLength)
+	}
+	if alias == canonical {
+		return errors.New("alias must differ from canonical tag")
+	}
+	if _, ok := c.TagAliases[canonical]; ok {
+		return fmt.Errorf("canonical tag %q is itself an alias", canonical)
+	}
+	for existingAlias, target := range c.TagAliases {
+		if target == alias && existingAlias != alias {
+			return fmt.Errorf("%q is already the canonical tag for %q", alias, existingAlias)
+		}
+	}
+	if _, exists := c.TagAliases[alias]; !exists && len(c.TagAliases) >= MaxTagAliases {
+		return fmt.Errorf("at most %d tag aliases are allowed", MaxTagAliases)
+	}
+	if c.TagAliases == nil {
+		c.TagAliases = make(map[string]string)
+	}
+	c.TagAliases[alias] = canonical
+	return nil
+}
+
+// RemoveTagAlias deletes an alias and reports whether it existed
+func (c *AIContext) RemoveTagAlias(alias string) bool {
+	alias = NormalizeTagName(alias)
+	if _, ok := c.TagAliases[alias]; !ok {
+		return false
+	}
+	delete(c.TagAliases, alias)
+	return true
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAIContext_CanonicalizeTags(t *testing.T) {
+	t.Parallel()
+
+	aliases := &AIContext{TagAliases: map[string]string{"chores": "errands", "job": "work"}}
+	tests := []struct {
+		name    string
+		context *AIContext
+		tags    []string
+		want    []string
+	}{
+		{"nil context", nil, []string{"chores"}, []string{"chores"}},
+		{"rewrites aliases case-insensitively", aliases, []string{"Chores", "home"}, []string{"errands", "home"}},
+		{"drops duplicates from rewrite", aliases, []string{"errands", "chores", "job", "work"}, []string{"errands", "work"}},
+		{"no aliases matched", aliases, []string{"home"}, []string{"home"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.context.CanonicalizeTags(tt.tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CanonicalizeTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAIContext_SetTagAlias(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		existing  map[string]string
+		alias     string
+		canonical string
+		wantErr   bool
+	}{
+		{"new alias is normalized", nil, " Chores ", "Errands", false},
+		{"replace existing alias", map[string]string{"chores": "errands"}, "chores", "home", false},
+		{"empty canonical", nil, "chores", " ", true},
+		{"alias equals canonical", nil, "chores", "CHORES", true},
+		{"canonical is an alias", map[string]string{"chores": "errands"}, "tasks", "chores", true},
+		{"alias is a canonical target", map[string]string{"chores": "errands"}, "errands", "home", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := &AIContext{TagAliases: tt.existing}
+			err := c.SetTagAlias(tt.alias, tt.canonical)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetTagAlias() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && c.TagAliases[NormalizeTagName(tt.alias)] != NormalizeTagName(tt.canonical) {
+				t.Errorf("TagAliases = %v", c.TagAliases)
+			}
+		})
+	}
+}
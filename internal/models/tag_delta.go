@@ -0,0 +1,144 @@
+package models
+
+import "sort"
+
+// TagSnapshot is one todo's tags and their sources, as tag statistics count them
+type TagSnapshot struct {
+	Tags    []string
+	Sources map[string]TagSource
+}
+
+// TagSnapshot copies the metadata's tags and tag sources
+func (m *Metadata) TagSnapshot() TagSnapshot {
+	sources := make(map[string]TagSource, len(m.TagSources))
+	for tag, source := range m.TagSources {
+		sources[tag] = source
+	}
+	return TagSnapshot{Tags: append([]string(nil), m.CategoryTags...), Sources: sources}
+}
+
+// source returns where tag came from; tags without a recorded source count as AI tags
+func (s TagSnapshot) source(tag string) TagSource {
+	if source, ok := s.Sources[tag]; ok {
+		return source
+	}
+	return TagSourceAI
+}
+
+// TagDelta is one todo's tags before and after a change
+type TagDelta struct {
+	Before TagSnapshot
+	After  TagSnapshot
+}
+
+// NewTagDelta returns the change from before's tags to after's
+func NewTagDelta(before, after *Metadata) TagDelta {
+	return TagDelta{Before: before.TagSnapshot(), After: after.TagSnapshot()}
+}
+
+// CanonicalizeTagSnapshot rewrites aliases in the snapshot's tags and sources to their canonical tags.
+// A user-sourced tag wins when an alias and its canonical tag were both present.
+func (c *AIContext) CanonicalizeTagSnapshot(s TagSnapshot) TagSnapshot {
+	if c == nil || len(c.TagAliases) == 0 {
+		return s
+	}
+	sources := make(map[string]TagSource, len(s.Sources))
+	for tag, source := range s.Sources {
+		key := c.CanonicalTag(tag)
+		if existing, ok := sources[key]; ok && existing == TagSourceUser {
+			continue
+		}
+		sources[key] = source
+	}
+	return TagSnapshot{Tags: c.CanonicalizeTags(s.Tags), Sources: sources}
+}
+
+// ApplyTagDelta updates the counts for one todo's tag change without rescanning the user's todos: the
+// before tags are removed and the after tags added, to both the per-tag counts and the co-occurrence
+// pairs. Tags and pairs whose count drops to zero are removed. Because only the strongest MaxTagPairs
+// pairs are stored, a pair that was cut off restarts from its delta; a periodic full analysis corrects it.
+func (s *TagStatistics) ApplyTagDelta(d TagDelta) {
+	if s.TagStats == nil {
+		s.TagStats = make(map[string]TagStats)
+	}
+	s.addTagCounts(d.Before, -1)
+	s.addTagCounts(d.After, 1)
+
+	pairs := make(map[[2]string]int, len(s.CoOccurrence))
+	for _, pair := range s.CoOccurrence {
+		pairs[[2]string{pair.A, pair.B}] = pair.Count
+	}
+	for _, key := range tagPairKeys(d.Before.Tags) {
+		if _, ok := pairs[key]; ok {
+			pairs[key]--
+		}
+	}
+	for _, key := range tagPairKeys(d.After.Tags) {
+		pairs[key]++
+	}
+	coOccurrence := make([]TagPair, 0, len(pairs))
+	for key, count := range pairs {
+		if count > 0 {
+			coOccurrence = append(coOccurrence, TagPair{A: key[0], B: key[1], Count: count})
+		}
+	}
+	SortTagPairs(coOccurrence)
+	if len(coOccurrence) > MaxTagPairs {
+		coOccurrence = coOccurrence[:MaxTagPairs]
+	}
+	s.CoOccurrence = coOccurrence
+}
+
+// addTagCounts adds sign to the counts of each tag in the snapshot
+func (s *TagStatistics) addTagCounts(snapshot TagSnapshot, sign int) {
+	for _, tag := range snapshot.Tags {
+		st := s.TagStats[tag]
+		st.Total += sign
+		switch snapshot.source(tag) {
+		case TagSourceAI:
+			st.AI += sign
+		case TagSourceUser:
+			st.User += sign
+		}
+		if st.Total <= 0 {
+			delete(s.TagStats, tag)
+			continue
+		}
+		st.AI, st.User = max(st.AI, 0), max(st.User, 0)
+		s.TagStats[tag] = st
+	}
+}
+
+// SortTagPairs orders pairs strongest first, breaking ties alphabetically so stored pairs are stable
+func SortTagPairs(pairs []TagPair) {
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Count != pairs[j].Count {
+			return pairs[i].Count > pairs[j].Count
+		}
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+}
+
+// tagPairKeys returns every pair of distinct non-empty tags, each pair sorted
+func tagPairKeys(tags []string) [][2]string {
+	seen := make(map[string]bool, len(tags))
+	unique := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		unique = append(unique, tag)
+	}
+	sort.Strings(unique)
+	var keys [][2]string
+	for i := 0; i < len(unique); i++ {
+		for j := i + 1; j < len(unique); j++ {
+			keys = append(keys, [2]string{unique[i], unique[j]})
+		}
+	}
+	return keys
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTagStatistics_ApplyTagDelta(t *testing.T) {
+	t.Parallel()
+
+	stats := &TagStatistics{
+		TagStats:     map[string]TagStats{"work": {Total: 2, AI: 1, User: 1}, "home": {Total: 1, AI: 1}},
+		CoOccurrence: []TagPair{{A: "home", B: "work", Count: 1}},
+	}
+	stats.ApplyTagDelta(TagDelta{
+		Before: TagSnapshot{Tags: []string{"work", "home"}, Sources: map[string]TagSource{"work": TagSourceUser}},
+		After:  TagSnapshot{Tags: []string{"work", "urgent"}, Sources: map[string]TagSource{"work": TagSourceUser, "urgent": TagSourceUser}},
+	})
+
+	wantStats := map[string]TagStats{"work": {Total: 2, AI: 1, User: 1}, "urgent": {Total: 1, User: 1}}
+	if !reflect.DeepEqual(stats.TagStats, wantStats) {
+		t.Errorf("TagStats = %+v, want %+v", stats.TagStats, wantStats)
+	}
+	wantPairs := []TagPair{{A: "urgent", B: "work", Count: 1}}
+	if !reflect.DeepEqual(stats.CoOccurrence, wantPairs) {
+		t.Errorf("CoOccurrence = %+v, want %+v", stats.CoOccurrence, wantPairs)
+	}
+
+	// Removing tags the statistics never counted must not leave negative counts behind
+	stats.ApplyTagDelta(TagDelta{Before: TagSnapshot{Tags: []string{"errands", "home"}}})
+	if _, ok := stats.TagStats["errands"]; ok {
+		t.Errorf("uncounted tag left in TagStats: %+v", stats.TagStats)
+	}
+	if !reflect.DeepEqual(stats.CoOccurrence, wantPairs) {
+		t.Errorf("CoOccurrence = %+v, want %+v", stats.CoOccurrence, wantPairs)
+	}
+}
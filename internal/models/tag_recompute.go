@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// TagRecomputeResult reports one run that enqueued tag analysis for every user
+type TagRecomputeResult struct {
+	// Locked is false when another run was already in progress and nothing was enqueued
+	Locked bool `json:"-"`
+	// Enqueued is how many tag analysis jobs were enqueued
+	Enqueued int `json:"enqueued"`
+	// LastRunAt is when the last enqueued job becomes due; nil when nothing was enqueued
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+}
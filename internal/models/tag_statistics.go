@@ -1,6 +1,7 @@
 package models
 
 import (
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,9 +18,62 @@ type TagStats struct {
 type TagStatistics struct {
 	UserID         uuid.UUID            `json:"user_id"`
 	TagStats       map[string]TagStats  `json:"tag_stats"` // Maps tag name to statistics
+	CoOccurrence   []TagPair            `json:"co_occurrence,omitempty"` // Strongest tag pairs, sorted by count descending
 	Tainted        bool                 `json:"tainted"`
 	LastAnalyzedAt *time.Time           `json:"last_analyzed_at,omitempty"`
 	AnalysisVersion int                 `json:"analysis_version"`
+	ContentHash    string               `json:"-"` // Hash of the tag inputs last aggregated; empty when unknown
 	CreatedAt      time.Time            `json:"created_at"`
 	UpdatedAt      time.Time            `json:"updated_at"`
 }
+
+// MaxTagPairs bounds how many co-occurring tag pairs are stored per user
+const MaxTagPairs = 200
+
+// TagPair counts how many todos carry both tags. A sorts before B.
+type TagPair struct {
+	A     string `json:"a"`
+	B     string `json:"b"`
+	Count int    `json:"count"`
+}
+
+// RelatedTag is a tag that commonly appears together with another tag
+type RelatedTag struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// RelatedTags returns up to limit tags that most often appear together with tag, strongest first.
+// A limit of zero or less returns all related tags.
+func (s *TagStatistics) RelatedTags(tag string, limit int) []RelatedTag {
+	related := make([]RelatedTag, 0)
+	for _, pair := range s.CoOccurrence {
+		switch tag {
+		case pair.A:
+			related = append(related, RelatedTag{Tag: pair.B, Count: pair.Count})
+		case pair.B:
+			related = append(related, RelatedTag{Tag: pair.A, Count: pair.Count})
+		}
+	}
+	sort.SliceStable(related, func(i, j int) bool {
+		if related[i].Count != related[j].Count {
+			return related[i].Count > related[j].Count
+		}
+		return related[i].Tag < related[j].Tag
+	})
+	if limit > 0 && len(related) > limit {
+		related = related[:limit]
+	}
+	return related
+}
+
+// Reset empties the statistics and marks them tainted so the next tag analysis rebuilds them from scratch.
+// Returns how many tags were cleared.
+func (s *TagStatistics) Reset() int {
+	cleared := len(s.TagStats)
+	s.TagStats = make(map[string]TagStats)
+	s.CoOccurrence = nil
+	s.Tainted = true
+	s.LastAnalyzedAt = nil
+	return cleared
+}
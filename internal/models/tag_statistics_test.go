@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTagStatistics_Reset(t *testing.T) {
+	t.Parallel()
+
+	analyzed := time.Now()
+	userID := uuid.New()
+	stats := &TagStatistics{
+		UserID:          userID,
+		TagStats:        map[string]TagStats{"work": {Total: 3, AI: 2, User: 1}, "home": {Total: 1, AI: 1}},
+		CoOccurrence:    []TagPair{{A: "home", B: "work", Count: 1}},
+		LastAnalyzedAt:  &analyzed,
+		AnalysisVersion: 4,
+	}
+
+	if cleared := stats.Reset(); cleared != 2 {
+		t.Errorf("Reset() cleared %d tags, want 2", cleared)
+	}
+	if len(stats.TagStats) != 0 || stats.TagStats == nil {
+		t.Errorf("TagStats = %v, want empty non-nil map", stats.TagStats)
+	}
+	if stats.CoOccurrence != nil || stats.LastAnalyzedAt != nil {
+		t.Errorf("CoOccurrence = %v, LastAnalyzedAt = %v, want both cleared", stats.CoOccurrence, stats.LastAnalyzedAt)
+	}
+	if !stats.Tainted {
+		t.Error("Reset() should mark the statistics tainted")
+	}
+	if stats.UserID != userID || stats.AnalysisVersion != 4 {
+		t.Error("Reset() should keep the user and analysis version")
+	}
+	if cleared := (&TagStatistics{}).Reset(); cleared != 0 {
+		t.Errorf("Reset() of empty statistics cleared %d tags, want 0", cleared)
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{"lowercases and trims", []string{" Work ", "HOME"}, []string{"work", "home"}},
+		{"non-ASCII letters lowercased", []string{"Ärzte", "ÉCOLE", "Μουσική"}, []string{"ärzte", "école", "μουσική"}},
+		{"unicode whitespace collapsed", []string{"cocina 　casera"}, []string{"cocina casera"}},
+		{"scripts without case kept", []string{"買い物", "쇼핑"}, []string{"買い物", "쇼핑"}},
+		{"duplicates after normalization dropped", []string{"Café", "café", "CAFÉ "}, []string{"café"}},
+		{"empty and control characters dropped", []string{"", "   ", "bad\u0007tag", "ok"}, []string{"ok"}},
+		{"length counted in characters", []string{strings.Repeat("é", MaxTagLength), strings.Repeat("é", MaxTagLength+1)}, []string{strings.Repeat("é", MaxTagLength)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := NormalizeTags(tt.tags); !slices.Equal(got, tt.want) {
+				t.Errorf("NormalizeTags(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+// DefaultTagWeight is the weight of a tag the user has not weighted; it leaves the tag's priority unchanged
+const DefaultTagWeight = 1.0
+
+// MaxTagWeight is the largest weight a user can give a tag
+const MaxTagWeight = 10.0
+
+// MaxTagWeights is the maximum number of tags a user can weight
+const MaxTagWeights = 100
+
+// TagWeight returns the user's weight for tag, or DefaultTagWeight when it has none.
+// It is safe to call on a nil context.
+func (c *AIContext) TagWeight(tag string) float64 {
+	if c == nil || len(c.TagWeights) == 0 {
+		return DefaultTagWeight
+	}
+	if weight, ok := c.TagWeights[NormalizeTagName(tag)]; ok {
+		return weight
+	}
+	return DefaultTagWeight
+}
+
+// SetTagWeight validates and stores the weight for the normalized tag. Weights above 1 make the AI prefer
+// the tag, weights below 1 make it less likely to be suggested. Setting DefaultTagWeight removes the entry.
+func (c *AIContext) SetTagWeight(tag string, weight float64) error {
+	tag = NormalizeTagName(tag)
+	if tag == "" {
+		return errors.New("tag is required")
+	}
+	if utf8.RuneCountInString(tag) > MaxTagAliasLength {
+		return fmt.Errorf("tag must be at most %d characters", MaxTagAliasLength)
+	}
+	if math.IsNaN(weight) || weight <= 0 || weight > MaxTagWeight {
+		return fmt.Errorf("weight must be greater than 0 and at most %g", MaxTagWeight)
+	}
+	if weight == DefaultTagWeight {
+		delete(c.TagWeights, tag)
+		return nil
+	}
+	if _, exists := c.TagWeights[tag]; !exists && len(c.TagWeights) >= MaxTagWeights {
+		return fmt.Errorf("at most %d tag weights are allowed", MaxTagWeights)
+	}
+	if c.TagWeights == nil {
+		c.TagWeights = make(map[string]float64)
+	}
+	c.TagWeights[tag] = weight
+	return nil
+}
+
+// RemoveTagWeight resets the tag to the default weight and reports whether it had one
+func (c *AIContext) RemoveTagWeight(tag string) bool {
+	tag = NormalizeTagName(tag)
+	if _, ok := c.TagWeights[tag]; !ok {
+		return false
+	}
+	delete(c.TagWeights, tag)
+	return true
+}
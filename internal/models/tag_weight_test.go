@@ -0,0 +1,59 @@
+package models
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestAIContext_SetTagWeight(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		existing map[string]float64
+		tag      string
+		weight   float64
+		wantErr  bool
+		want     map[string]float64
+	}{
+		{"normalizes tag", nil, " Deep  Work ", 3, false, map[string]float64{"deep work": 3}},
+		{"lowers priority", nil, "misc", 0.5, false, map[string]float64{"misc": 0.5}},
+		{"default weight removes entry", map[string]float64{"work": 2}, "work", DefaultTagWeight, false, map[string]float64{}},
+		{"zero weight rejected", nil, "work", 0, true, nil},
+		{"negative weight rejected", nil, "work", -1, true, nil},
+		{"above maximum rejected", nil, "work", MaxTagWeight + 1, true, nil},
+		{"NaN rejected", nil, "work", math.NaN(), true, nil},
+		{"empty tag rejected", nil, "  ", 2, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := &AIContext{TagWeights: tt.existing}
+			err := c.SetTagWeight(tt.tag, tt.weight)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetTagWeight() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(c.TagWeights, tt.want) {
+				t.Errorf("TagWeights = %v, want %v", c.TagWeights, tt.want)
+			}
+		})
+	}
+}
+
+func TestAIContext_TagWeight(t *testing.T) {
+	t.Parallel()
+
+	var nilContext *AIContext
+	if got := nilContext.TagWeight("work"); got != DefaultTagWeight {
+		t.Errorf("nil context TagWeight() = %v, want %v", got, DefaultTagWeight)
+	}
+	c := &AIContext{TagWeights: map[string]float64{"work": 4}}
+	if got := c.TagWeight("Work"); got != 4 {
+		t.Errorf("TagWeight(Work) = %v, want 4", got)
+	}
+	if got := c.TagWeight("home"); got != DefaultTagWeight {
+		t.Errorf("TagWeight(home) = %v, want %v", got, DefaultTagWeight)
+	}
+}
@@ -25,6 +25,19 @@ const (
 	TodoStatusCompleted  TodoStatus = "completed"
 )
 
+// AnalyzedTodoStatuses are the statuses of todos the AI has already categorized
+var AnalyzedTodoStatuses = []TodoStatus{TodoStatusProcessed, TodoStatusCompleted}
+
+// RetentionAction is what the retention sweep does with completed todos older than the retention age
+type RetentionAction string
+
+const (
+	// RetentionActionArchive hides retired todos from listings but keeps them for stats and export
+	RetentionActionArchive RetentionAction = "archive"
+	// RetentionActionTrash moves retired todos to the trash
+	RetentionActionTrash RetentionAction = "trash"
+)
+
 // Todo represents a todo item
 type Todo struct {
 	ID          uuid.UUID   `json:"id"`
@@ -34,7 +47,22 @@ type Todo struct {
 	Status      TodoStatus  `json:"status"`
 	Metadata    Metadata    `json:"metadata"`
 	DueDate     *time.Time  `json:"due_date,omitempty"`
+	Priority    Priority    `json:"priority,omitempty"`
 	CreatedAt   time.Time   `json:"created_at"`
 	UpdatedAt   time.Time   `json:"updated_at"`
 	CompletedAt *time.Time  `json:"completed_at,omitempty"`
+	// ArchivedAt and TrashedAt are set when the retention sweep retires an old completed todo
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	TrashedAt  *time.Time `json:"trashed_at,omitempty"`
+}
+
+// EnteredAt returns when the user entered the todo: TimeEntered from metadata if present and
+// parseable, otherwise CreatedAt. Analysis uses it to interpret relative time expressions.
+func (t *Todo) EnteredAt() time.Time {
+	if t.Metadata.TimeEntered != nil && *t.Metadata.TimeEntered != "" {
+		if entered, err := time.Parse(time.RFC3339, *t.Metadata.TimeEntered); err == nil {
+			return entered
+		}
+	}
+	return t.CreatedAt
 }
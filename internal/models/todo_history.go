@@ -0,0 +1,97 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChangeActor identifies who made a change to a todo
+type ChangeActor string
+
+const (
+	// ChangeActorUser is the todo's owner editing through the API
+	ChangeActorUser ChangeActor = "user"
+	// ChangeActorAI is the analysis worker applying AI suggestions
+	ChangeActorAI ChangeActor = "ai"
+	// ChangeActorSystem is any other automated change
+	ChangeActorSystem ChangeActor = "system"
+)
+
+// Fields tracked in todo history
+const (
+	HistoryFieldText        = "text"
+	HistoryFieldTags        = "tags"
+	HistoryFieldTimeHorizon = "time_horizon"
+	HistoryFieldStatus      = "status"
+	HistoryFieldDueDate     = "due_date"
+	HistoryFieldPriority    = "priority"
+)
+
+// FieldChange is a single field's old and new value
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// TodoHistoryEntry records the fields changed by one update of a todo
+type TodoHistoryEntry struct {
+	ID        uuid.UUID     `json:"id"`
+	TodoID    uuid.UUID     `json:"todo_id"`
+	Actor     ChangeActor   `json:"actor"`
+	Changes   []FieldChange `json:"changes"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// DiffTodos returns the field-level changes from prev to next in a stable field order.
+// Tags are compared as sets; due dates are rendered as RFC3339 strings and priorities as strings (nil when unset).
+func DiffTodos(prev, next *Todo) []FieldChange {
+	var changes []FieldChange
+	if prev.Text != next.Text {
+		changes = append(changes, FieldChange{Field: HistoryFieldText, Old: prev.Text, New: next.Text})
+	}
+	if oldTags, newTags := sortedTags(prev.Metadata.CategoryTags), sortedTags(next.Metadata.CategoryTags); !slices.Equal(oldTags, newTags) {
+		changes = append(changes, FieldChange{Field: HistoryFieldTags, Old: oldTags, New: newTags})
+	}
+	if prev.TimeHorizon != next.TimeHorizon {
+		changes = append(changes, FieldChange{Field: HistoryFieldTimeHorizon, Old: string(prev.TimeHorizon), New: string(next.TimeHorizon)})
+	}
+	if prev.Status != next.Status {
+		changes = append(changes, FieldChange{Field: HistoryFieldStatus, Old: string(prev.Status), New: string(next.Status)})
+	}
+	if oldDue, newDue := historyTime(prev.DueDate), historyTime(next.DueDate); oldDue != newDue {
+		changes = append(changes, FieldChange{Field: HistoryFieldDueDate, Old: oldDue, New: newDue})
+	}
+	if prev.Priority != next.Priority {
+		changes = append(changes, FieldChange{Field: HistoryFieldPriority, Old: historyPriority(prev.Priority), New: historyPriority(next.Priority)})
+	}
+	return changes
+}
+
+// sortedTags returns a sorted, de-duplicated copy of tags (never nil, so it renders as [])
+func sortedTags(tags []string) []string {
+	out := slices.Clone(tags)
+	if out == nil {
+		out = []string{}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// historyPriority renders p as a string, or nil when unset
+func historyPriority(p Priority) any {
+	if p == "" {
+		return nil
+	}
+	return string(p)
+}
+
+// historyTime renders t as an RFC3339 UTC string, or nil when unset
+func historyTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffTodos_SequenceOfEdits(t *testing.T) {
+	t.Parallel()
+
+	due := time.Date(2026, 3, 20, 9, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	state := &Todo{
+		Text:        "Buy milk",
+		TimeHorizon: TimeHorizonSoon,
+		Status:      TodoStatusPending,
+	}
+
+	steps := []struct {
+		name string
+		edit func(t *Todo)
+		want []FieldChange
+	}{
+		{
+			name: "ai tags and horizon",
+			edit: func(t *Todo) {
+				t.Metadata.CategoryTags = []string{"shopping", "errands"}
+				t.TimeHorizon = TimeHorizonNext
+				t.Status = TodoStatusProcessed
+			},
+			want: []FieldChange{
+				{Field: HistoryFieldTags, Old: []string{}, New: []string{"errands", "shopping"}},
+				{Field: HistoryFieldTimeHorizon, Old: "soon", New: "next"},
+				{Field: HistoryFieldStatus, Old: "pending", New: "processed"},
+			},
+		},
+		{
+			name: "user edits text and sets due date",
+			edit: func(t *Todo) {
+				t.Text = "Buy oat milk"
+				t.DueDate = &due
+			},
+			want: []FieldChange{
+				{Field: HistoryFieldText, Old: "Buy milk", New: "Buy oat milk"},
+				{Field: HistoryFieldDueDate, Old: nil, New: "2026-03-20T14:00:00Z"},
+			},
+		},
+		{
+			name: "reordered tags are not a change",
+			edit: func(t *Todo) {
+				t.Metadata.CategoryTags = []string{"errands", "shopping"}
+			},
+			want: nil,
+		},
+		{
+			name: "same instant in another zone is not a change",
+			edit: func(t *Todo) {
+				utc := due.UTC()
+				t.DueDate = &utc
+			},
+			want: nil,
+		},
+		{
+			name: "user removes a tag, clears due date and completes",
+			edit: func(t *Todo) {
+				t.Metadata.CategoryTags = []string{"shopping"}
+				t.DueDate = nil
+				t.Status = TodoStatusCompleted
+			},
+			want: []FieldChange{
+				{Field: HistoryFieldTags, Old: []string{"errands", "shopping"}, New: []string{"shopping"}},
+				{Field: HistoryFieldStatus, Old: "processed", New: "completed"},
+				{Field: HistoryFieldDueDate, Old: "2026-03-20T14:00:00Z", New: nil},
+			},
+		},
+		{
+			name: "user sets a priority",
+			edit: func(t *Todo) {
+				t.Priority = PriorityUrgent
+			},
+			want: []FieldChange{
+				{Field: HistoryFieldPriority, Old: nil, New: "urgent"},
+			},
+		},
+		{
+			name: "user clears the priority",
+			edit: func(t *Todo) {
+				t.Priority = ""
+			},
+			want: []FieldChange{
+				{Field: HistoryFieldPriority, Old: "urgent", New: nil},
+			},
+		},
+	}
+
+	// Steps depend on the previous state, so they run in order against a copy of the last stored todo
+	for _, step := range steps {
+		next := *state
+		next.Metadata.CategoryTags = append([]string(nil), state.Metadata.CategoryTags...)
+		step.edit(&next)
+
+		got := DiffTodos(state, &next)
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: DiffTodos() = %#v, want %#v", step.name, got, step.want)
+		}
+		state = &next
+	}
+}
+
+func TestDiffTodos_DuplicateTagsIgnored(t *testing.T) {
+	t.Parallel()
+
+	prev := &Todo{Metadata: Metadata{CategoryTags: []string{"work"}}}
+	next := &Todo{Metadata: Metadata{CategoryTags: []string{"work", "work"}}}
+	if got := DiffTodos(prev, next); got != nil {
+		t.Errorf("DiffTodos() = %#v, want no changes", got)
+	}
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidMerge is returned when two todos cannot be merged
+var ErrInvalidMerge = errors.New("todos cannot be merged")
+
+// MergeTodos folds source into target and moves source to the trash at now. Target keeps its text, status,
+// time horizon and priority. Tags and context are combined (a tag is a user tag if either todo has it as
+// one), the source's external references move to target so lookups by reference find the merged todo,
+// target keeps the earlier of the two entry times, and a target without a due date takes the source's. Both
+// todos must belong to the same user.
+func MergeTodos(target, source *Todo, now time.Time) error {
+	switch {
+	case target.ID == source.ID:
+		return fmt.Errorf("%w: a todo cannot be merged into itself", ErrInvalidMerge)
+	case target.UserID != source.UserID:
+		return fmt.Errorf("%w: todos belong to different users", ErrInvalidMerge)
+	case source.TrashedAt != nil:
+		return fmt.Errorf("%w: source todo is already in the trash", ErrInvalidMerge)
+	}
+	refs, err := mergeExternalRefs(target.Metadata.ExternalRefs, source.Metadata.ExternalRefs)
+	if err != nil {
+		return err
+	}
+
+	target.Metadata.ExternalRefs = refs
+	source.Metadata.ExternalRefs = nil
+	mergeTags(&target.Metadata, &source.Metadata)
+	target.Metadata.Context = mergeStrings(target.Metadata.Context, source.Metadata.Context)
+	if entered := source.EnteredAt(); entered.Before(target.EnteredAt()) {
+		timeEntered := entered.UTC().Format(time.RFC3339)
+		target.Metadata.TimeEntered = &timeEntered
+	}
+	if target.DueDate == nil && source.DueDate != nil {
+		due := *source.DueDate
+		target.SetDueDate(&due, source.Metadata.DueDateOnly)
+	}
+	source.TrashedAt = &now
+	return nil
+}
+
+// mergeTags adds src's tags to dst, keeping dst's order. A tag either side set as a user tag stays one.
+func mergeTags(dst, src *Metadata) {
+	if dst.TagSources == nil {
+		dst.TagSources = make(map[string]TagSource)
+	}
+	dst.CategoryTags = mergeStrings(dst.CategoryTags, src.CategoryTags)
+	for _, tag := range src.CategoryTags {
+		source, ok := src.TagSources[tag]
+		if !ok {
+			continue
+		}
+		if _, exists := dst.TagSources[tag]; !exists || source == TagSourceUser {
+			dst.TagSources[tag] = source
+		}
+	}
+}
+
+// mergeStrings appends the values of b missing from a
+func mergeStrings(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	for _, v := range a {
+		seen[v] = true
+	}
+	for _, v := range b {
+		if !seen[v] {
+			seen[v] = true
+			a = append(a, v)
+		}
+	}
+	return a
+}
+
+// mergeExternalRefs appends the refs of b whose system and ID are not already in a
+func mergeExternalRefs(a, b []ExternalRef) ([]ExternalRef, error) {
+	merged := append([]ExternalRef(nil), a...)
+	for _, ref := range b {
+		if !containsExternalRef(merged, ref) {
+			merged = append(merged, ref)
+		}
+	}
+	if len(merged) > MaxExternalRefs {
+		return nil, fmt.Errorf("%w: the merged todo would have more than %d external_refs", ErrInvalidMerge, MaxExternalRefs)
+	}
+	if len(merged) == 0 {
+		return nil, nil
+	}
+	return merged, nil
+}
+
+func containsExternalRef(refs []ExternalRef, ref ExternalRef) bool {
+	for _, r := range refs {
+		if r.System == ref.System && r.ID == ref.ID {
+			return true
+		}
+	}
+	return false
+}
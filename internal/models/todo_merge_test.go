@@ -0,0 +1,145 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMergeTodos(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	earlier := "2026-10-01T08:00:00Z"
+	later := "2026-10-10T08:00:00Z"
+	due := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+
+	newTarget := func() *Todo {
+		return &Todo{
+			ID:          uuid.New(),
+			UserID:      userID,
+			Text:        "Renew passport",
+			Status:      TodoStatusProcessed,
+			TimeHorizon: TimeHorizonNext,
+			Metadata: Metadata{
+				CategoryTags: []string{"travel", "errands"},
+				TagSources:   map[string]TagSource{"travel": TagSourceUser, "errands": TagSourceAI},
+				Context:      []string{"online"},
+				TimeEntered:  &later,
+				ExternalRefs: []ExternalRef{{System: "github", ID: "me/todo#1"}},
+			},
+		}
+	}
+	newSource := func() *Todo {
+		return &Todo{
+			ID:          uuid.New(),
+			UserID:      userID,
+			Text:        "passport renewal",
+			Status:      TodoStatusPending,
+			TimeHorizon: TimeHorizonLater,
+			DueDate:     &due,
+			Metadata: Metadata{
+				CategoryTags: []string{"errands", "government"},
+				TagSources:   map[string]TagSource{"errands": TagSourceUser, "government": TagSourceAI},
+				Context:      []string{"online", "post office"},
+				TimeEntered:  &earlier,
+				DueDateOnly:  true,
+				ExternalRefs: []ExternalRef{{System: "github", ID: "me/todo#1"}, {System: "jira", ID: "HOME-7"}},
+			},
+		}
+	}
+
+	target, source := newTarget(), newSource()
+	if err := MergeTodos(target, source, now); err != nil {
+		t.Fatalf("MergeTodos() error = %v", err)
+	}
+	if target.Text != "Renew passport" || target.Status != TodoStatusProcessed || target.TimeHorizon != TimeHorizonNext {
+		t.Errorf("target text/status/horizon changed: %q %s %s", target.Text, target.Status, target.TimeHorizon)
+	}
+	if want := []string{"travel", "errands", "government"}; !slices.Equal(target.Metadata.CategoryTags, want) {
+		t.Errorf("tags = %v, want %v", target.Metadata.CategoryTags, want)
+	}
+	wantSources := map[string]TagSource{"travel": TagSourceUser, "errands": TagSourceUser, "government": TagSourceAI}
+	for tag, want := range wantSources {
+		if got := target.Metadata.TagSources[tag]; got != want {
+			t.Errorf("tag source of %s = %q, want %q", tag, got, want)
+		}
+	}
+	if want := []string{"online", "post office"}; !slices.Equal(target.Metadata.Context, want) {
+		t.Errorf("context = %v, want %v", target.Metadata.Context, want)
+	}
+	if len(target.Metadata.ExternalRefs) != 2 || target.Metadata.ExternalRefs[1].ID != "HOME-7" {
+		t.Errorf("external refs = %+v, want the github ref once plus HOME-7", target.Metadata.ExternalRefs)
+	}
+	if target.Metadata.TimeEntered == nil || *target.Metadata.TimeEntered != earlier {
+		t.Errorf("time entered = %v, want the earlier %s", target.Metadata.TimeEntered, earlier)
+	}
+	if target.DueDate == nil || !target.DueDate.Equal(due) || !target.Metadata.DueDateOnly {
+		t.Errorf("due date = %v (date only %v), want the source's %v", target.DueDate, target.Metadata.DueDateOnly, due)
+	}
+	if source.TrashedAt == nil || !source.TrashedAt.Equal(now) {
+		t.Errorf("source trashed_at = %v, want %v", source.TrashedAt, now)
+	}
+	if len(source.Metadata.ExternalRefs) != 0 {
+		t.Errorf("source external refs = %+v, want them moved to the target", source.Metadata.ExternalRefs)
+	}
+
+	// A target with its own due date and an earlier entry time keeps both
+	target, source = newTarget(), newSource()
+	ownDue := due.Add(48 * time.Hour)
+	target.DueDate = &ownDue
+	target.Metadata.TimeEntered = &earlier
+	source.Metadata.TimeEntered = &later
+	if err := MergeTodos(target, source, now); err != nil {
+		t.Fatalf("MergeTodos() error = %v", err)
+	}
+	if !target.DueDate.Equal(ownDue) || target.Metadata.DueDateOnly || *target.Metadata.TimeEntered != earlier {
+		t.Errorf("due date = %v, time entered = %s, want the target's own", target.DueDate, *target.Metadata.TimeEntered)
+	}
+}
+
+func TestMergeTodos_Invalid(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	userID := uuid.New()
+	tooManyRefs := func(system string) []ExternalRef {
+		refs := make([]ExternalRef, MaxExternalRefs)
+		for i := range refs {
+			refs[i] = ExternalRef{System: system, ID: uuid.NewString()}
+		}
+		return refs
+	}
+
+	tests := []struct {
+		name   string
+		modify func(target, source *Todo)
+	}{
+		{"same todo", func(target, source *Todo) { source.ID = target.ID }},
+		{"different users", func(target, source *Todo) { source.UserID = uuid.New() }},
+		{"source already trashed", func(target, source *Todo) { source.TrashedAt = &now }},
+		{"too many external refs", func(target, source *Todo) {
+			target.Metadata.ExternalRefs = tooManyRefs("github")
+			source.Metadata.ExternalRefs = []ExternalRef{{System: "jira", ID: "HOME-1"}}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			target := &Todo{ID: uuid.New(), UserID: userID, Metadata: Metadata{CategoryTags: []string{"home"}}}
+			source := &Todo{ID: uuid.New(), UserID: userID, Metadata: Metadata{CategoryTags: []string{"work"}}}
+			tt.modify(target, source)
+			if err := MergeTodos(target, source, now); !errors.Is(err, ErrInvalidMerge) {
+				t.Fatalf("MergeTodos() error = %v, want ErrInvalidMerge", err)
+			}
+			if !slices.Equal(target.Metadata.CategoryTags, []string{"home"}) {
+				t.Errorf("target tags = %v, want unchanged", target.Metadata.CategoryTags)
+			}
+		})
+	}
+}
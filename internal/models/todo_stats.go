@@ -0,0 +1,30 @@
+package models
+
+// TodoCounts counts a user's todos for the stats dashboard. Archived and trashed todos are not counted.
+type TodoCounts struct {
+	// ByStatus counts todos by status and then time horizon; combinations without todos are left out
+	ByStatus map[TodoStatus]map[TimeHorizon]int `json:"by_status"`
+	Total    int                                `json:"total"`
+	// Overdue counts the todos past their due date that are not completed
+	Overdue int `json:"overdue"`
+}
+
+// NewTodoCounts returns empty counts
+func NewTodoCounts() *TodoCounts {
+	return &TodoCounts{ByStatus: make(map[TodoStatus]map[TimeHorizon]int)}
+}
+
+// Add counts count todos with the status and time horizon, overdue of which are overdue
+func (c *TodoCounts) Add(status TodoStatus, horizon TimeHorizon, count, overdue int) {
+	if count == 0 {
+		return
+	}
+	byHorizon, ok := c.ByStatus[status]
+	if !ok {
+		byHorizon = make(map[TimeHorizon]int)
+		c.ByStatus[status] = byHorizon
+	}
+	byHorizon[horizon] += count
+	c.Total += count
+	c.Overdue += overdue
+}
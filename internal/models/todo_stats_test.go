@@ -0,0 +1,27 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTodoCounts_Add(t *testing.T) {
+	t.Parallel()
+
+	counts := NewTodoCounts()
+	counts.Add(TodoStatusPending, TimeHorizonNext, 3, 1)
+	counts.Add(TodoStatusPending, TimeHorizonLater, 2, 0)
+	counts.Add(TodoStatusCompleted, TimeHorizonSoon, 4, 0)
+	counts.Add(TodoStatusProcessed, TimeHorizonSoon, 0, 0)
+
+	want := map[TodoStatus]map[TimeHorizon]int{
+		TodoStatusPending:   {TimeHorizonNext: 3, TimeHorizonLater: 2},
+		TodoStatusCompleted: {TimeHorizonSoon: 4},
+	}
+	if !reflect.DeepEqual(counts.ByStatus, want) {
+		t.Errorf("ByStatus = %v, want %v", counts.ByStatus, want)
+	}
+	if counts.Total != 9 || counts.Overdue != 1 {
+		t.Errorf("Total = %d, Overdue = %d; want 9 and 1", counts.Total, counts.Overdue)
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidStatusTransition is returned when a todo cannot move from its current status to the requested one
+var ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+// ErrSystemTodoStatus is returned when a client asks for a status only the analysis lifecycle may set
+var ErrSystemTodoStatus = errors.New("status can only be set by the system")
+
+// ClientTodoStatuses are the statuses clients may set directly. Processing and processed belong to the
+// analysis lifecycle and are only set by system code.
+var ClientTodoStatuses = []TodoStatus{TodoStatusPending, TodoStatusCompleted}
+
+// ClientSettable reports whether clients may set status s directly
+func (s TodoStatus) ClientSettable() bool {
+	for _, allowed := range ClientTodoStatuses {
+		if allowed == s {
+			return true
+		}
+	}
+	return false
+}
+
+// todoStatusTransitions lists the statuses each status may move to. Analysis moves todos through
+// pending -> processing -> processed; any open todo can be completed, and reopening a completed todo
+// returns it to processed. Staying in the same status is always allowed.
+var todoStatusTransitions = map[TodoStatus][]TodoStatus{
+	TodoStatusPending:    {TodoStatusProcessing, TodoStatusCompleted},
+	TodoStatusProcessing: {TodoStatusPending, TodoStatusProcessed, TodoStatusCompleted},
+	TodoStatusProcessed:  {TodoStatusPending, TodoStatusCompleted},
+	TodoStatusCompleted:  {TodoStatusProcessed},
+}
+
+// CanTransitionTo reports whether a todo in status s may move to next
+func (s TodoStatus) CanTransitionTo(next TodoStatus) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range todoStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionTo moves the todo to next, setting CompletedAt to now when it becomes completed and clearing
+// it (and any retention archive or trash marker) when it is reopened. It returns an error wrapping ErrInvalidStatusTransition if the move is not allowed.
+func (t *Todo) TransitionTo(next TodoStatus, now time.Time) error {
+	if !t.Status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, t.Status, next)
+	}
+	if t.Status == next {
+		return nil
+	}
+	t.Status = next
+	if next == TodoStatusCompleted {
+		t.CompletedAt = &now
+	} else {
+		t.CompletedAt = nil
+		t.ArchivedAt = nil
+		t.TrashedAt = nil
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTodoStatus_CanTransitionTo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		from TodoStatus
+		to   TodoStatus
+		want bool
+	}{
+		{TodoStatusPending, TodoStatusPending, true},
+		{TodoStatusPending, TodoStatusProcessing, true},
+		{TodoStatusPending, TodoStatusProcessed, false},
+		{TodoStatusPending, TodoStatusCompleted, true},
+		{TodoStatusProcessing, TodoStatusPending, true},
+		{TodoStatusProcessing, TodoStatusProcessing, true},
+		{TodoStatusProcessing, TodoStatusProcessed, true},
+		{TodoStatusProcessing, TodoStatusCompleted, true},
+		{TodoStatusProcessed, TodoStatusPending, true},
+		{TodoStatusProcessed, TodoStatusProcessing, false},
+		{TodoStatusProcessed, TodoStatusProcessed, true},
+		{TodoStatusProcessed, TodoStatusCompleted, true},
+		{TodoStatusCompleted, TodoStatusPending, false},
+		{TodoStatusCompleted, TodoStatusProcessing, false},
+		{TodoStatusCompleted, TodoStatusProcessed, true},
+		{TodoStatusCompleted, TodoStatusCompleted, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			t.Parallel()
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodo_TransitionTo(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+	tests := []struct {
+		name            string
+		from            TodoStatus
+		completedAt     *time.Time
+		to              TodoStatus
+		wantErr         bool
+		wantCompletedAt *time.Time
+	}{
+		{"completing sets completed_at", TodoStatusProcessed, nil, TodoStatusCompleted, false, &now},
+		{"reopening clears completed_at", TodoStatusCompleted, &earlier, TodoStatusProcessed, false, nil},
+		{"completing again keeps completed_at", TodoStatusCompleted, &earlier, TodoStatusCompleted, false, &earlier},
+		{"forbidden transition leaves todo unchanged", TodoStatusCompleted, &earlier, TodoStatusPending, true, &earlier},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todo := &Todo{Status: tt.from, CompletedAt: tt.completedAt}
+			err := todo.TransitionTo(tt.to, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TransitionTo() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidStatusTransition) {
+					t.Errorf("TransitionTo() err = %v, want ErrInvalidStatusTransition", err)
+				}
+				if todo.Status != tt.from {
+					t.Errorf("Status = %s, want unchanged %s", todo.Status, tt.from)
+				}
+			} else if todo.Status != tt.to {
+				t.Errorf("Status = %s, want %s", todo.Status, tt.to)
+			}
+			switch {
+			case tt.wantCompletedAt == nil && todo.CompletedAt != nil:
+				t.Errorf("CompletedAt = %v, want nil", todo.CompletedAt)
+			case tt.wantCompletedAt != nil && (todo.CompletedAt == nil || !todo.CompletedAt.Equal(*tt.wantCompletedAt)):
+				t.Errorf("CompletedAt = %v, want %v", todo.CompletedAt, *tt.wantCompletedAt)
+			}
+		})
+	}
+}
+
+func TestTodo_TransitionTo_ReopeningClearsRetention(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	completed, retired := now.Add(-60*24*time.Hour), now.Add(-24*time.Hour)
+	todo := &Todo{Status: TodoStatusCompleted, CompletedAt: &completed, ArchivedAt: &retired, TrashedAt: &retired}
+
+	if err := todo.TransitionTo(TodoStatusCompleted, now); err != nil || todo.ArchivedAt == nil {
+		t.Fatalf("staying completed: err = %v, ArchivedAt = %v, want the todo to stay archived", err, todo.ArchivedAt)
+	}
+	if err := todo.TransitionTo(TodoStatusProcessed, now); err != nil {
+		t.Fatalf("TransitionTo() err = %v", err)
+	}
+	if todo.ArchivedAt != nil || todo.TrashedAt != nil {
+		t.Errorf("ArchivedAt = %v, TrashedAt = %v, want both cleared on reopen", todo.ArchivedAt, todo.TrashedAt)
+	}
+}
+
+func TestTodoStatus_ClientSettable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		status TodoStatus
+		want   bool
+	}{
+		{TodoStatusPending, true},
+		{TodoStatusCompleted, true},
+		{TodoStatusProcessing, false},
+		{TodoStatusProcessed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			t.Parallel()
+			if got := tt.status.ClientSettable(); got != tt.want {
+				t.Errorf("%s.ClientSettable() = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+
+	// System code still moves todos through the analysis lifecycle
+	todo := &Todo{Status: TodoStatusPending}
+	for _, next := range []TodoStatus{TodoStatusProcessing, TodoStatusProcessed} {
+		if err := todo.TransitionTo(next, time.Now()); err != nil {
+			t.Fatalf("TransitionTo(%s) error = %v", next, err)
+		}
+	}
+}
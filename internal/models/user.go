@@ -13,6 +13,8 @@ type User struct {
 	ProviderID    *string    `json:"provider_id,omitempty"`
 	Name          *string   `json:"name,omitempty"`
 	EmailVerified bool      `json:"email_verified"`
+	// Role is what the user is authorized to do (RoleUser or RoleAdmin)
+	Role          UserRole  `json:"role"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
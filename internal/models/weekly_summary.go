@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WeeklySummaryPeriod is the span a weekly summary covers, ending at the start of the week it is sent in
+const WeeklySummaryPeriod = 7 * 24 * time.Hour
+
+// WeeklySummary is the AI summary of what a user completed during the week before WeekStart and what is
+// still open
+type WeeklySummary struct {
+	UserID         uuid.UUID `json:"user_id"`
+	WeekStart      time.Time `json:"week_start"`
+	Summary        string    `json:"summary"`
+	CompletedCount int       `json:"completed_count"`
+	PendingCount   int       `json:"pending_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// WeekStart returns midnight UTC on the Monday of t's week
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// WeeklySummaryEnabled reports whether the user receives weekly summaries, which is the default.
+// Setting the weekly_summary preference to false opts out. It is safe to call on a nil context.
+func (c *AIContext) WeeklySummaryEnabled() bool {
+	if c == nil {
+		return true
+	}
+	enabled, ok := c.Preferences[PreferenceWeeklySummary].(bool)
+	return !ok || enabled
+}
+
+// FormatWeeklySummary is the plain summary used when no AI provider can write one
+func FormatWeeklySummary(completed, pending int) string {
+	return fmt.Sprintf("You completed %d %s last week and have %d still open.", completed, pluralTodos(completed), pending)
+}
+
+func pluralTodos(n int) string {
+	if n == 1 {
+		return "todo"
+	}
+	return "todos"
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeekStart(t *testing.T) {
+	t.Parallel()
+
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		in   time.Time
+	}{
+		{"monday midnight", monday},
+		{"wednesday", time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)},
+		{"sunday night", time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC)},
+		{"converted to UTC", time.Date(2026, 10, 19, 8, 0, 0, 0, time.FixedZone("UTC+9", 9*3600))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := WeekStart(tt.in); !got.Equal(monday) {
+				t.Errorf("WeekStart(%v) = %v, want %v", tt.in, got, monday)
+			}
+		})
+	}
+}
+
+func TestAIContext_WeeklySummaryEnabled(t *testing.T) {
+	t.Parallel()
+
+	var nilContext *AIContext
+	if !nilContext.WeeklySummaryEnabled() {
+		t.Error("a nil context should default to enabled")
+	}
+	if !(&AIContext{Preferences: map[string]any{PreferenceWeeklySummary: true}}).WeeklySummaryEnabled() {
+		t.Error("weekly_summary=true should be enabled")
+	}
+	if (&AIContext{Preferences: map[string]any{PreferenceWeeklySummary: false}}).WeeklySummaryEnabled() {
+		t.Error("weekly_summary=false should opt out")
+	}
+}
+
+func TestFormatWeeklySummary(t *testing.T) {
+	t.Parallel()
+
+	if got, want := FormatWeeklySummary(1, 0), "You completed 1 todo last week and have 0 still open."; got != want {
+		t.Errorf("FormatWeeklySummary(1, 0) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Notification is a user-facing message about a todo
+type Notification struct {
+	UserID  uuid.UUID  `json:"user_id"`
+	TodoID  uuid.UUID  `json:"todo_id"`
+	Kind    string     `json:"kind"`
+	Text    string     `json:"text"`
+	DueDate *time.Time `json:"due_date,omitempty"`
+	SentAt  time.Time  `json:"sent_at"`
+}
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier is a Notifier that only logs notifications. It is the default when no delivery channel is configured.
+type LogNotifier struct {
+	logger *zap.Logger
+}
+
+// NewLogNotifier creates a new log-only notifier
+func NewLogNotifier(logger *zap.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+// Notify logs the notification
+func (n *LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	fields := []zap.Field{
+		zap.String("user_id", logpkg.SanitizeUserID(notification.UserID.String())),
+		zap.String("todo_id", logpkg.SanitizeUserID(notification.TodoID.String())),
+		zap.String("kind", notification.Kind),
+	}
+	if notification.DueDate != nil {
+		fields = append(fields, zap.Time("due_date", *notification.DueDate))
+	}
+	n.logger.Info("notification_sent", fields...)
+	return nil
+}
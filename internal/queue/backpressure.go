@@ -0,0 +1,128 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// backpressureDepthTTL is how long a queue depth reading is reused, so enqueues do not each ask the broker
+const backpressureDepthTTL = time.Second
+
+// QueueStats is a point-in-time snapshot of the main job queue
+type QueueStats struct {
+	// Depth is the number of messages ready for delivery (delayed jobs still held by the exchange are not counted)
+	Depth int
+	// Consumers is the number of consumers attached to the queue
+	Consumers int
+}
+
+// StatsReporter is implemented by queues that can report their depth
+type StatsReporter interface {
+	QueueStats(ctx context.Context) (QueueStats, error)
+}
+
+// BackpressureMode selects what happens to an analysis job while the queue is above its high-watermark
+type BackpressureMode string
+
+const (
+	// BackpressureModeSkip drops the analysis job; the todo is kept and can be analyzed later
+	BackpressureModeSkip BackpressureMode = "skip"
+	// BackpressureModeDelay enqueues the job with a NotBefore delay to spread the load
+	BackpressureModeDelay BackpressureMode = "delay"
+)
+
+// BackpressureAction is the decision taken for one job
+type BackpressureAction int
+
+const (
+	// BackpressureNone enqueues the job unchanged
+	BackpressureNone BackpressureAction = iota
+	// BackpressureSkip does not enqueue the job
+	BackpressureSkip
+	// BackpressureDelay enqueues the job with a NotBefore delay
+	BackpressureDelay
+)
+
+// DecideBackpressure returns the action for a job given the current queue depth. The queue is over its
+// high-watermark once depth reaches it; a watermark of 0 disables backpressure.
+func DecideBackpressure(depth, highWatermark int, mode BackpressureMode) BackpressureAction {
+	if highWatermark <= 0 || depth < highWatermark {
+		return BackpressureNone
+	}
+	if mode == BackpressureModeDelay {
+		return BackpressureDelay
+	}
+	return BackpressureSkip
+}
+
+// Backpressure applies a high-watermark on queue depth to jobs before they are enqueued
+type Backpressure struct {
+	stats         StatsReporter
+	highWatermark int
+	mode          BackpressureMode
+	delay         time.Duration
+
+	mu       sync.Mutex
+	depth    QueueStats
+	depthAt  time.Time
+	depthTTL time.Duration
+}
+
+// NewBackpressure creates a backpressure policy. Jobs are delayed by delay in BackpressureModeDelay.
+func NewBackpressure(stats StatsReporter, highWatermark int, mode BackpressureMode, delay time.Duration) *Backpressure {
+	return &Backpressure{stats: stats, highWatermark: highWatermark, mode: mode, delay: delay, depthTTL: backpressureDepthTTL}
+}
+
+// queueStats returns the queue stats, reusing a reading younger than depthTTL. Failed readings are not kept.
+func (b *Backpressure) queueStats(ctx context.Context) (QueueStats, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.depthAt.IsZero() && time.Since(b.depthAt) < b.depthTTL {
+		return b.depth, nil
+	}
+	stats, err := b.stats.QueueStats(ctx)
+	if err != nil {
+		return QueueStats{}, err
+	}
+	b.depth, b.depthAt = stats, time.Now()
+	return stats, nil
+}
+
+// BackpressureStatus reports the current queue depth and whether backpressure is being applied
+type BackpressureStatus struct {
+	Depth         int
+	HighWatermark int
+	Active        bool
+}
+
+// Status reads the queue depth and reports whether it is over the high-watermark
+func (b *Backpressure) Status(ctx context.Context) (BackpressureStatus, error) {
+	stats, err := b.queueStats(ctx)
+	if err != nil {
+		return BackpressureStatus{}, err
+	}
+	return BackpressureStatus{
+		Depth:         stats.Depth,
+		HighWatermark: b.highWatermark,
+		Active:        DecideBackpressure(stats.Depth, b.highWatermark, b.mode) != BackpressureNone,
+	}, nil
+}
+
+// Apply decides what to do with job at the current queue depth. For BackpressureDelay the job's NotBefore is
+// moved to at least now plus the configured delay. When the depth cannot be read the job is let through and
+// the error returned for logging.
+func (b *Backpressure) Apply(ctx context.Context, job *Job) (BackpressureAction, int, error) {
+	stats, err := b.queueStats(ctx)
+	if err != nil {
+		return BackpressureNone, 0, err
+	}
+	action := DecideBackpressure(stats.Depth, b.highWatermark, b.mode)
+	if action == BackpressureDelay {
+		notBefore := time.Now().Add(b.delay)
+		if job.NotBefore == nil || job.NotBefore.Before(notBefore) {
+			job.NotBefore = &notBefore
+		}
+	}
+	return action, stats.Depth, nil
+}
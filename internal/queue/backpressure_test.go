@@ -0,0 +1,147 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type mockStatsReporter struct {
+	stats QueueStats
+	err   error
+	calls int
+}
+
+func (m *mockStatsReporter) QueueStats(ctx context.Context) (QueueStats, error) {
+	m.calls++
+	return m.stats, m.err
+}
+
+func TestDecideBackpressure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		depth         int
+		highWatermark int
+		mode          BackpressureMode
+		want          BackpressureAction
+	}{
+		{name: "disabled", depth: 1000, highWatermark: 0, mode: BackpressureModeSkip, want: BackpressureNone},
+		{name: "below watermark", depth: 99, highWatermark: 100, mode: BackpressureModeSkip, want: BackpressureNone},
+		{name: "at watermark skips", depth: 100, highWatermark: 100, mode: BackpressureModeSkip, want: BackpressureSkip},
+		{name: "above watermark skips", depth: 500, highWatermark: 100, mode: BackpressureModeSkip, want: BackpressureSkip},
+		{name: "above watermark delays", depth: 500, highWatermark: 100, mode: BackpressureModeDelay, want: BackpressureDelay},
+		{name: "below watermark in delay mode", depth: 10, highWatermark: 100, mode: BackpressureModeDelay, want: BackpressureNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := DecideBackpressure(tt.depth, tt.highWatermark, tt.mode); got != tt.want {
+				t.Errorf("DecideBackpressure(%d, %d, %q) = %v, want %v", tt.depth, tt.highWatermark, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackpressure_Apply(t *testing.T) {
+	t.Parallel()
+
+	later := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name          string
+		stats         *mockStatsReporter
+		mode          BackpressureMode
+		notBefore     *time.Time
+		wantAction    BackpressureAction
+		wantErr       bool
+		wantDelayed   bool
+		wantNotBefore *time.Time
+	}{
+		{name: "under watermark leaves job alone", stats: &mockStatsReporter{stats: QueueStats{Depth: 5}}, mode: BackpressureModeDelay, wantAction: BackpressureNone},
+		{name: "over watermark skips", stats: &mockStatsReporter{stats: QueueStats{Depth: 10}}, mode: BackpressureModeSkip, wantAction: BackpressureSkip},
+		{name: "over watermark delays", stats: &mockStatsReporter{stats: QueueStats{Depth: 10}}, mode: BackpressureModeDelay, wantAction: BackpressureDelay, wantDelayed: true},
+		{name: "later not_before is kept", stats: &mockStatsReporter{stats: QueueStats{Depth: 10}}, mode: BackpressureModeDelay, notBefore: &later, wantAction: BackpressureDelay, wantNotBefore: &later},
+		{name: "stats error fails open", stats: &mockStatsReporter{err: errors.New("broker down")}, mode: BackpressureModeSkip, wantAction: BackpressureNone, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bp := NewBackpressure(tt.stats, 10, tt.mode, time.Minute)
+			todoID := uuid.New()
+			job := NewJob(JobTypeTaskAnalysis, uuid.New(), &todoID)
+			job.NotBefore = tt.notBefore
+
+			start := time.Now()
+			action, _, err := bp.Apply(context.Background(), job)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if action != tt.wantAction {
+				t.Errorf("Apply() action = %v, want %v", action, tt.wantAction)
+			}
+			switch {
+			case tt.wantNotBefore != nil:
+				if job.NotBefore == nil || !job.NotBefore.Equal(*tt.wantNotBefore) {
+					t.Errorf("NotBefore = %v, want %v", job.NotBefore, tt.wantNotBefore)
+				}
+			case tt.wantDelayed:
+				if job.NotBefore == nil || job.NotBefore.Before(start.Add(time.Minute)) {
+					t.Errorf("NotBefore = %v, want at least %v", job.NotBefore, start.Add(time.Minute))
+				}
+			default:
+				if job.NotBefore != nil {
+					t.Errorf("NotBefore = %v, want nil", job.NotBefore)
+				}
+			}
+		})
+	}
+}
+
+func TestBackpressure_Status(t *testing.T) {
+	t.Parallel()
+
+	bp := NewBackpressure(&mockStatsReporter{stats: QueueStats{Depth: 12}}, 10, BackpressureModeSkip, 0)
+	status, err := bp.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Depth != 12 || status.HighWatermark != 10 || !status.Active {
+		t.Errorf("Status() = %+v, want depth 12, watermark 10, active", status)
+	}
+}
+
+func TestBackpressure_ReusesDepth(t *testing.T) {
+	t.Parallel()
+
+	stats := &mockStatsReporter{err: errors.New("broker down")}
+	bp := NewBackpressure(stats, 10, BackpressureModeSkip, 0)
+	todoID := uuid.New()
+	job := NewJob(JobTypeTaskAnalysis, uuid.New(), &todoID)
+
+	if _, _, err := bp.Apply(context.Background(), job); err == nil {
+		t.Fatal("Apply() error = nil, want the stats error")
+	}
+	stats.stats, stats.err = QueueStats{Depth: 12}, nil
+	for range 3 {
+		if action, depth, err := bp.Apply(context.Background(), job); err != nil || action != BackpressureSkip || depth != 12 {
+			t.Fatalf("Apply() = %v, %d, %v; want skip at depth 12", action, depth, err)
+		}
+	}
+	if stats.calls != 2 {
+		t.Errorf("QueueStats called %d times, want 2 (failed readings are retried, good ones reused)", stats.calls)
+	}
+
+	bp.depthTTL = 0
+	stats.stats = QueueStats{Depth: 1}
+	if action, _, _ := bp.Apply(context.Background(), job); action != BackpressureNone {
+		t.Errorf("Apply() = %v after the reading expired, want none at depth 1", action)
+	}
+}
@@ -2,25 +2,53 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 )
 
-// GarbageCollector runs periodic DLQ purges, removing messages older than retention.
+// GarbageCollector runs periodic DLQ purges, removing messages older than retention, and deletes expired
+// records such as idempotency keys.
 type GarbageCollector struct {
-	dlqPurger DLQPurger
-	interval  time.Duration
-	retention time.Duration
+	dlqPurger     DLQPurger
+	interval      time.Duration
+	retention     time.Duration
+	recordPurgers []recordPurge
+}
+
+// RecordPurger deletes stored records older than a given age, returning how many were deleted.
+type RecordPurger interface {
+	DeleteOlderThan(ctx context.Context, age time.Duration) (int64, error)
+}
+
+type recordPurge struct {
+	name   string
+	purger RecordPurger
+	age    time.Duration
+}
+
+// GarbageCollectorOption configures a GarbageCollector.
+type GarbageCollectorOption func(*GarbageCollector)
+
+// WithRecordPurger also deletes the records of purger older than age on each run; name labels them in output.
+func WithRecordPurger(name string, purger RecordPurger, age time.Duration) GarbageCollectorOption {
+	return func(gc *GarbageCollector) {
+		gc.recordPurgers = append(gc.recordPurgers, recordPurge{name: name, purger: purger, age: age})
+	}
 }
 
 // NewGarbageCollector creates a new garbage collector. purger is used to purge DLQ messages
 // older than retention; pass a RabbitMQ queue (implements DLQPurger) or another implementation.
-func NewGarbageCollector(purger DLQPurger, interval time.Duration, retention time.Duration) *GarbageCollector {
-	return &GarbageCollector{
+func NewGarbageCollector(purger DLQPurger, interval time.Duration, retention time.Duration, opts ...GarbageCollectorOption) *GarbageCollector {
+	gc := &GarbageCollector{
 		dlqPurger: purger,
 		interval:  interval,
 		retention: retention,
 	}
+	for _, o := range opts {
+		o(gc)
+	}
+	return gc
 }
 
 // Start runs the GC loop until ctx is cancelled.
@@ -39,19 +67,27 @@ func (gc *GarbageCollector) Start(ctx context.Context) error {
 	}
 }
 
-// collect purges DLQ messages older than retention.
+// collect purges DLQ messages older than retention, then expired records. A failure does not stop the
+// purges after it.
 func (gc *GarbageCollector) collect(ctx context.Context) error {
-	if gc.dlqPurger == nil {
-		return nil
-	}
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
 	defer cancel()
-	n, err := gc.dlqPurger.PurgeOlderThan(ctx, gc.retention)
-	if err != nil {
-		return fmt.Errorf("DLQ purge: %w", err)
+	var errs []error
+	if gc.dlqPurger != nil {
+		n, err := gc.dlqPurger.PurgeOlderThan(ctx, gc.retention)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("DLQ purge: %w", err))
+		} else if n > 0 {
+			fmt.Printf("DLQ GC purged %d message(s) older than %v\n", n, gc.retention)
+		}
 	}
-	if n > 0 {
-		fmt.Printf("DLQ GC purged %d message(s) older than %v\n", n, gc.retention)
+	for _, p := range gc.recordPurgers {
+		n, err := p.purger.DeleteOlderThan(ctx, p.age)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s purge: %w", p.name, err))
+		} else if n > 0 {
+			fmt.Printf("GC deleted %d %s older than %v\n", n, p.name, p.age)
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
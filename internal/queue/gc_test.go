@@ -75,3 +75,46 @@ func TestGarbageCollector_Start_StopsOnContextCancel(t *testing.T) {
 		t.Error("expected context cancelled error")
 	}
 }
+
+type mockRecordPurger struct {
+	deleted int64
+	err     error
+	ages    []time.Duration
+}
+
+func (m *mockRecordPurger) DeleteOlderThan(ctx context.Context, age time.Duration) (int64, error) {
+	m.ages = append(m.ages, age)
+	return m.deleted, m.err
+}
+
+func TestGarbageCollector_Collect_RecordPurgers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		dlqErr   error
+		purgeErr error
+		wantErr  bool
+	}{
+		{name: "records purged after the DLQ"},
+		{name: "DLQ failure still purges records", dlqErr: errors.New("purge failed"), wantErr: true},
+		{name: "record purge failure", purgeErr: errors.New("db down"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dlq := &mockDLQPurger{purgeFunc: func(context.Context, time.Duration) (int, error) { return 0, tt.dlqErr }}
+			records := &mockRecordPurger{deleted: 2, err: tt.purgeErr}
+			gc := NewGarbageCollector(dlq, time.Minute, time.Hour, WithRecordPurger("idempotency keys", records, 24*time.Hour))
+
+			err := gc.collect(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("collect() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(records.ages) != 1 || records.ages[0] != 24*time.Hour {
+				t.Errorf("DeleteOlderThan calls = %v, want one with 24h", records.ages)
+			}
+		})
+	}
+}
@@ -13,6 +13,12 @@ type MessageInterface interface {
 	GetJob() *Job
 }
 
+// RedeliveryReporter is implemented by messages that know whether the broker delivered them before, e.g. after
+// a consumer reconnect left an earlier delivery unacknowledged
+type RedeliveryReporter interface {
+	IsRedelivered() bool
+}
+
 // JobQueue is the interface for job queues
 type JobQueue interface {
 	Enqueue(ctx context.Context, job *Job) error
@@ -22,6 +28,22 @@ type JobQueue interface {
 	HealthCheck(ctx context.Context) error
 }
 
+// DLQStatsReporter is implemented by queues that can report how many jobs are dead-lettered
+type DLQStatsReporter interface {
+	DLQDepth(ctx context.Context) (int, error)
+}
+
+// Stats is a snapshot of the main queue and its dead letter queue
+type Stats struct {
+	Queue QueueStats
+	DLQ   QueueStats
+}
+
+// Inspector is implemented by queues that can report the main queue and DLQ together
+type Inspector interface {
+	Stats(ctx context.Context) (Stats, error)
+}
+
 // DLQPurger removes dead-lettered messages older than a retention period.
 // Implementations consume from the DLQ, ack (discard) old messages, and nack without requeue for recent ones so the purge completes.
 type DLQPurger interface {
@@ -16,6 +16,41 @@ const (
 	JobTypeReprocessUser JobType = "reprocess_user"
 	// JobTypeTagAnalysis is a job for analyzing and aggregating tag statistics for a user
 	JobTypeTagAnalysis JobType = "tag_analysis"
+	// JobTypeDueReminder is a job for sending a due-date reminder for a todo
+	JobTypeDueReminder JobType = "due_reminder"
+	// JobTypeWeeklySummary is a job for generating and delivering a user's weekly summary
+	JobTypeWeeklySummary JobType = "weekly_summary"
+)
+
+// Known reports whether t is one of the job types above
+func (t JobType) Known() bool {
+	switch t {
+	case JobTypeTaskAnalysis, JobTypeReprocessUser, JobTypeTagAnalysis, JobTypeDueReminder, JobTypeWeeklySummary:
+		return true
+	}
+	return false
+}
+
+const (
+	// MetadataReminderKey is the job metadata key holding the escalation chain key a reminder was scheduled for
+	MetadataReminderKey = "reminder_key"
+	// MetadataReminderStep is the job metadata key holding the index of the reminder step in its chain
+	MetadataReminderStep = "reminder_step"
+	// MetadataReminderDueDate is the job metadata key holding the due date (RFC3339) a due-soon reminder was scheduled for
+	MetadataReminderDueDate = "reminder_due_date"
+	// MetadataManualAnalysis marks a task analysis job requested by the user, which is throttled at request time
+	MetadataManualAnalysis = "manual_analysis"
+	// MetadataAnalysisDeferred marks a task analysis job that was deferred by the per-user analysis interval
+	MetadataAnalysisDeferred = "analysis_deferred"
+	// MetadataAnalysisBudgeted marks a task analysis job that already holds a slot in the user's analysis budget
+	MetadataAnalysisBudgeted = "analysis_budgeted"
+	// MetadataSkipTagStats marks a task analysis job that should run without the user's tag statistics guidance
+	MetadataSkipTagStats = "skip_tag_stats"
+	// MetadataWeekStart is the job metadata key holding the week (YYYY-MM-DD, a Monday) a weekly summary is for
+	MetadataWeekStart = "week_start"
+	// MetadataForceTagAnalysis marks a tag analysis job that must re-aggregate the statistics even when their
+	// inputs are unchanged, e.g. one enqueued by a recompute after an aggregation fix
+	MetadataForceTagAnalysis = "force_tag_analysis"
 )
 
 // Job represents a job in the queue
@@ -32,7 +67,8 @@ type Job struct {
 	MaxRetries int                    `json:"max_retries"`
 }
 
-// NewJob creates a new job
+// NewJob creates a new job, allowing the retries of DefaultRetryPolicy. The worker applies the retry policy
+// configured for the job's type when the job fails.
 func NewJob(jobType JobType, userID uuid.UUID, todoID *uuid.UUID) *Job {
 	return &Job{
 		ID:         uuid.New(),
@@ -42,10 +78,38 @@ func NewJob(jobType JobType, userID uuid.UUID, todoID *uuid.UUID) *Job {
 		Metadata:   make(map[string]any),
 		CreatedAt:  time.Now(),
 		RetryCount: 0,
-		MaxRetries: 3,
+		MaxRetries: DefaultRetryPolicy.MaxRetries,
 	}
 }
 
+// NewDueReminderJob creates a reminder job for a single step of a todo's escalation chain, delayed until at
+func NewDueReminderJob(userID, todoID uuid.UUID, reminderKey string, step int, at time.Time) *Job {
+	job := NewJob(JobTypeDueReminder, userID, &todoID)
+	job.NotBefore = &at
+	job.Metadata[MetadataReminderKey] = reminderKey
+	job.Metadata[MetadataReminderStep] = step
+	return job
+}
+
+// NewDueSoonReminderJob creates a job for the single reminder of a todo without a reminder policy that is due
+// at dueDate; it is dropped if not run by then
+func NewDueSoonReminderJob(userID, todoID uuid.UUID, dueDate time.Time) *Job {
+	job := NewJob(JobTypeDueReminder, userID, &todoID)
+	job.NotAfter = &dueDate
+	job.Metadata[MetadataReminderDueDate] = dueDate.UTC().Format(time.RFC3339)
+	return job
+}
+
+// NewWeeklySummaryJob creates a weekly summary job for the week starting weekStart, delayed until at and
+// dropped if it has not run by expires
+func NewWeeklySummaryJob(userID uuid.UUID, weekStart, at, expires time.Time) *Job {
+	job := NewJob(JobTypeWeeklySummary, userID, nil)
+	job.NotBefore = &at
+	job.NotAfter = &expires
+	job.Metadata[MetadataWeekStart] = weekStart.Format(time.DateOnly)
+	return job
+}
+
 // ShouldProcess checks if the job should be processed now
 func (j *Job) ShouldProcess() bool {
 	now := time.Now()
@@ -0,0 +1,393 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// ErrQueueClosed is returned when enqueueing to or consuming from a closed queue
+var ErrQueueClosed = errors.New("queue is closed")
+
+// InMemoryQueue implements JobQueue inside a single process, for local development and tests that should not
+// need a broker. Jobs are stored as the same JSON RabbitMQ carries, so consumers see identical jobs. Like the
+// RabbitMQ queue, jobs are delayed until NotBefore, dropped once past NotAfter, requeued when their consumer
+// stops before settling them, and dead-lettered when nacked without requeue. Nothing survives a restart and
+// other processes cannot see the jobs.
+type InMemoryQueue struct {
+	mu        sync.Mutex
+	ready     []memoryEntry
+	dlq       []memoryEntry
+	timers    map[*time.Timer]struct{}
+	consumers int
+	closed    bool
+	// wake signals consumers that ready jobs are available; done is closed by Close
+	wake chan struct{}
+	done chan struct{}
+	// dequeued settles the messages handed out by Dequeue
+	dequeued *memoryChannel
+	logger   *zap.Logger
+}
+
+// memoryEntry is a job's JSON as enqueued, with whether it was delivered before
+type memoryEntry struct {
+	body        []byte
+	createdAt   time.Time
+	redelivered bool
+}
+
+// NewInMemoryQueue creates an empty in-memory queue
+func NewInMemoryQueue() *InMemoryQueue {
+	q := &InMemoryQueue{
+		timers: make(map[*time.Timer]struct{}),
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		logger: zap.NewNop(),
+	}
+	q.dequeued = newMemoryChannel(q, 0)
+	return q
+}
+
+// SetLogger sets the logger for warnings about messages acked or nacked after their consumer stopped
+func (q *InMemoryQueue) SetLogger(logger *zap.Logger) {
+	if logger != nil {
+		q.logger = logger
+	}
+}
+
+// Enqueue adds a job to the queue; a job with NotBefore still ahead is held until then
+func (q *InMemoryQueue) Enqueue(ctx context.Context, job *Job) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+	entry := memoryEntry{body: body, createdAt: job.CreatedAt}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	if job.NotBefore != nil && time.Until(*job.NotBefore) > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(time.Until(*job.NotBefore), func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			delete(q.timers, timer)
+			if !q.closed {
+				q.pushLocked(entry)
+			}
+		})
+		q.timers[timer] = struct{}{}
+	} else {
+		q.pushLocked(entry)
+	}
+	metrics.JobsEnqueued.Inc(string(job.Type))
+	return nil
+}
+
+// pushLocked appends entry to the ready jobs and wakes a consumer; q.mu must be held
+func (q *InMemoryQueue) pushLocked(entry memoryEntry) {
+	q.ready = append(q.ready, entry)
+	q.signalLocked()
+}
+
+func (q *InMemoryQueue) signalLocked() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// popLocked removes the oldest ready job, dropping jobs past their NotAfter; q.mu must be held
+func (q *InMemoryQueue) popLocked() (*Job, memoryEntry, bool) {
+	for len(q.ready) > 0 {
+		entry := q.ready[0]
+		q.ready[0] = memoryEntry{}
+		q.ready = q.ready[1:]
+		var job Job
+		if err := json.Unmarshal(entry.body, &job); err != nil {
+			q.dlq = append(q.dlq, entry)
+			continue
+		}
+		if job.IsExpired() {
+			continue
+		}
+		if len(q.ready) > 0 {
+			q.signalLocked()
+		}
+		return &job, entry, true
+	}
+	return nil, memoryEntry{}, false
+}
+
+// Dequeue removes and returns the oldest ready job, or nil when there is none
+// DEPRECATED: Use Consume() for better performance and scalability
+func (q *InMemoryQueue) Dequeue(ctx context.Context) (*Message, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+	job, entry, ok := q.popLocked()
+	if !ok {
+		return nil, nil
+	}
+	return q.dequeued.deliver(job, entry), nil
+}
+
+// Consume delivers ready jobs until ctx is done, with at most prefetchCount delivered but not yet acked or
+// nacked. When ctx is done the jobs still unsettled are requeued, as a broker does for a closed channel.
+func (q *InMemoryQueue) Consume(ctx context.Context, prefetchCount int) (<-chan *Message, <-chan error, error) {
+	if prefetchCount < 1 {
+		prefetchCount = 1
+	}
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil, nil, ErrQueueClosed
+	}
+	q.consumers++
+	q.mu.Unlock()
+
+	ch := newMemoryChannel(q, prefetchCount)
+	msgChan := make(chan *Message, prefetchCount)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(msgChan)
+		defer close(errChan)
+		defer func() {
+			q.mu.Lock()
+			q.consumers--
+			q.mu.Unlock()
+			ch.close()
+		}()
+		for {
+			msg, ok := ch.next(ctx)
+			if !ok {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case msgChan <- msg:
+			}
+		}
+	}()
+	return msgChan, errChan, nil
+}
+
+// Close stops holding delayed jobs and rejects further enqueues; jobs not yet consumed are discarded
+func (q *InMemoryQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	close(q.done)
+	for timer := range q.timers {
+		timer.Stop()
+	}
+	q.timers = nil
+	return nil
+}
+
+// HealthCheck reports whether the queue is still open
+func (q *InMemoryQueue) HealthCheck(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	return nil
+}
+
+// QueueStats implements StatsReporter for the ready jobs (delayed jobs are not counted)
+func (q *InMemoryQueue) QueueStats(ctx context.Context) (QueueStats, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return QueueStats{Depth: len(q.ready), Consumers: q.consumers}, nil
+}
+
+// DLQDepth implements DLQStatsReporter
+func (q *InMemoryQueue) DLQDepth(ctx context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.dlq), nil
+}
+
+// Stats implements Inspector
+func (q *InMemoryQueue) Stats(ctx context.Context) (Stats, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return Stats{
+		Queue: QueueStats{Depth: len(q.ready), Consumers: q.consumers},
+		DLQ:   QueueStats{Depth: len(q.dlq)},
+	}, nil
+}
+
+// PurgeOlderThan implements DLQPurger, removing dead-lettered jobs created more than retention ago
+func (q *InMemoryQueue) PurgeOlderThan(ctx context.Context, retention time.Duration) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	cutoff := time.Now().Add(-retention)
+	kept := q.dlq[:0]
+	for _, entry := range q.dlq {
+		if !entry.createdAt.Before(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	purged := len(q.dlq) - len(kept)
+	clear(q.dlq[len(kept):])
+	q.dlq = kept
+	return purged, nil
+}
+
+// memoryChannel is the DeliveryChannel of one consumer of an InMemoryQueue. It tracks the jobs delivered to
+// the consumer until they are settled and limits how many may be outstanding.
+type memoryChannel struct {
+	q *InMemoryQueue
+	// slots has a token for each delivery the prefetch limit still allows; nil means unlimited
+	slots chan struct{}
+
+	mu      sync.Mutex
+	nextTag uint64
+	unacked map[uint64]memoryEntry
+	closed  bool
+}
+
+func newMemoryChannel(q *InMemoryQueue, prefetch int) *memoryChannel {
+	ch := &memoryChannel{q: q, unacked: make(map[uint64]memoryEntry)}
+	if prefetch > 0 {
+		ch.slots = make(chan struct{}, prefetch)
+		for range prefetch {
+			ch.slots <- struct{}{}
+		}
+	}
+	return ch
+}
+
+// next waits for a prefetch slot and a ready job and delivers it, or reports false once ctx is done or the
+// queue is closed
+func (c *memoryChannel) next(ctx context.Context) (*Message, bool) {
+	select {
+	case <-ctx.Done():
+		return nil, false
+	case <-c.slots:
+	}
+	for {
+		c.q.mu.Lock()
+		if c.q.closed {
+			c.q.mu.Unlock()
+			return nil, false
+		}
+		job, entry, ok := c.q.popLocked()
+		if ok {
+			msg := c.deliver(job, entry)
+			c.q.mu.Unlock()
+			return msg, true
+		}
+		c.q.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			c.slots <- struct{}{}
+			return nil, false
+		case <-c.q.done:
+			return nil, false
+		case <-c.q.wake:
+		}
+	}
+}
+
+// deliver records the job as unacked on this channel and wraps it in a Message
+func (c *memoryChannel) deliver(job *Job, entry memoryEntry) *Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextTag++
+	c.unacked[c.nextTag] = entry
+	return &Message{
+		Job:         job,
+		DeliveryTag: c.nextTag,
+		Channel:     c,
+		Redelivered: entry.redelivered,
+		logger:      c.q.logger,
+	}
+}
+
+// settle removes the delivery from the unacked jobs and frees its prefetch slot
+func (c *memoryChannel) settle(tag uint64) (memoryEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.unacked[tag]
+	if !ok {
+		return memoryEntry{}, fmt.Errorf("unknown delivery tag %d", tag)
+	}
+	delete(c.unacked, tag)
+	if c.slots != nil {
+		c.slots <- struct{}{}
+	}
+	return entry, nil
+}
+
+// Ack implements DeliveryChannel
+func (c *memoryChannel) Ack(tag uint64, multiple bool) error {
+	_, err := c.settle(tag)
+	return err
+}
+
+// Nack implements DeliveryChannel: the job is requeued, or dead-lettered when requeue is false
+func (c *memoryChannel) Nack(tag uint64, multiple, requeue bool) error {
+	entry, err := c.settle(tag)
+	if err != nil {
+		return err
+	}
+	c.q.mu.Lock()
+	defer c.q.mu.Unlock()
+	if requeue {
+		entry.redelivered = true
+		c.q.pushLocked(entry)
+	} else {
+		c.q.dlq = append(c.q.dlq, entry)
+	}
+	return nil
+}
+
+// IsClosed implements DeliveryChannel
+func (c *memoryChannel) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// close requeues the jobs still unsettled on the channel; later acks and nacks of them are stale
+func (c *memoryChannel) close() {
+	c.mu.Lock()
+	c.closed = true
+	unacked := c.unacked
+	c.unacked = make(map[uint64]memoryEntry)
+	c.mu.Unlock()
+
+	c.q.mu.Lock()
+	defer c.q.mu.Unlock()
+	for _, entry := range unacked {
+		entry.redelivered = true
+		c.q.pushLocked(entry)
+	}
+}
+
+// Ensure InMemoryQueue implements the queue contracts and memoryChannel settles its deliveries
+var (
+	_ JobQueue         = (*InMemoryQueue)(nil)
+	_ DLQPurger        = (*InMemoryQueue)(nil)
+	_ Inspector        = (*InMemoryQueue)(nil)
+	_ StatsReporter    = (*InMemoryQueue)(nil)
+	_ DLQStatsReporter = (*InMemoryQueue)(nil)
+	_ DeliveryChannel  = (*memoryChannel)(nil)
+)
@@ -0,0 +1,277 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// receive waits briefly for a message on msgChan, failing the test if none arrives
+func receive(t *testing.T, msgChan <-chan *Message) *Message {
+	t.Helper()
+	select {
+	case msg, ok := <-msgChan:
+		if !ok {
+			t.Fatal("message channel closed")
+		}
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message delivered")
+	}
+	return nil
+}
+
+// expectNone fails the test if a message arrives on msgChan within wait
+func expectNone(t *testing.T, msgChan <-chan *Message, wait time.Duration) {
+	t.Helper()
+	select {
+	case msg := <-msgChan:
+		t.Fatalf("unexpected message %v", msg.GetJob().ID)
+	case <-time.After(wait):
+	}
+}
+
+func TestInMemoryQueue_EnqueueConsume(t *testing.T) {
+	t.Parallel()
+
+	q := NewInMemoryQueue()
+	defer func() { _ = q.Close() }()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	todoID := uuid.New()
+	job := NewDueReminderJob(uuid.New(), todoID, "chain", 2, time.Now().Add(-time.Second))
+	if err := q.Enqueue(ctx, job); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	msgChan, _, err := q.Consume(ctx, 1)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	msg := receive(t, msgChan)
+	got := msg.GetJob()
+	if got.ID != job.ID || got.Type != JobTypeDueReminder || got.TodoID == nil || *got.TodoID != todoID {
+		t.Errorf("delivered job = %+v, want %+v", got, job)
+	}
+	// Jobs round-trip through JSON like on the broker, so numbers in metadata decode as float64
+	if step, ok := got.Metadata[MetadataReminderStep].(float64); !ok || step != 2 {
+		t.Errorf("reminder step = %#v, want float64 2", got.Metadata[MetadataReminderStep])
+	}
+	if msg.IsRedelivered() {
+		t.Error("first delivery reported as redelivered")
+	}
+	if err := msg.Ack(); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if err := msg.Ack(); err == nil {
+		t.Error("second Ack() error = nil, want unknown delivery tag")
+	}
+}
+
+func TestInMemoryQueue_NotBeforeAndNotAfter(t *testing.T) {
+	t.Parallel()
+
+	q := NewInMemoryQueue()
+	defer func() { _ = q.Close() }()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	delayed := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+	notBefore := time.Now().Add(150 * time.Millisecond)
+	delayed.NotBefore = &notBefore
+	expired := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+	notAfter := time.Now().Add(-time.Second)
+	expired.NotAfter = &notAfter
+	for _, job := range []*Job{delayed, expired} {
+		if err := q.Enqueue(ctx, job); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+	if stats, _ := q.QueueStats(ctx); stats.Depth != 1 {
+		t.Errorf("depth = %d, want 1 (delayed jobs are not counted)", stats.Depth)
+	}
+
+	msgChan, _, err := q.Consume(ctx, 1)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	expectNone(t, msgChan, 50*time.Millisecond)
+	msg := receive(t, msgChan)
+	if msg.GetJob().ID != delayed.ID {
+		t.Errorf("delivered job %s, want the delayed job %s", msg.GetJob().ID, delayed.ID)
+	}
+	if time.Now().Before(notBefore) {
+		t.Errorf("delayed job delivered before its NotBefore")
+	}
+}
+
+func TestInMemoryQueue_Nack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		requeue     bool
+		wantDLQ     int
+		wantRequeue bool
+	}{
+		{name: "requeue redelivers", requeue: true, wantRequeue: true},
+		{name: "reject dead-letters", requeue: false, wantDLQ: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			q := NewInMemoryQueue()
+			defer func() { _ = q.Close() }()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			job := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+			if err := q.Enqueue(ctx, job); err != nil {
+				t.Fatalf("Enqueue() error = %v", err)
+			}
+			msgChan, _, err := q.Consume(ctx, 1)
+			if err != nil {
+				t.Fatalf("Consume() error = %v", err)
+			}
+			if err := receive(t, msgChan).Nack(tt.requeue); err != nil {
+				t.Fatalf("Nack() error = %v", err)
+			}
+
+			if depth, _ := q.DLQDepth(ctx); depth != tt.wantDLQ {
+				t.Errorf("DLQ depth = %d, want %d", depth, tt.wantDLQ)
+			}
+			if !tt.wantRequeue {
+				expectNone(t, msgChan, 50*time.Millisecond)
+				return
+			}
+			msg := receive(t, msgChan)
+			if msg.GetJob().ID != job.ID || !msg.IsRedelivered() {
+				t.Errorf("redelivered job %s (redelivered %v), want %s redelivered", msg.GetJob().ID, msg.IsRedelivered(), job.ID)
+			}
+		})
+	}
+}
+
+func TestInMemoryQueue_Prefetch(t *testing.T) {
+	t.Parallel()
+
+	q := NewInMemoryQueue()
+	defer func() { _ = q.Close() }()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for range 3 {
+		if err := q.Enqueue(ctx, NewJob(JobTypeTaskAnalysis, uuid.New(), nil)); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+	msgChan, _, err := q.Consume(ctx, 2)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	first := receive(t, msgChan)
+	receive(t, msgChan)
+	expectNone(t, msgChan, 50*time.Millisecond)
+
+	if err := first.Ack(); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	receive(t, msgChan)
+}
+
+func TestInMemoryQueue_StoppedConsumerRequeues(t *testing.T) {
+	t.Parallel()
+
+	q := NewInMemoryQueue()
+	defer func() { _ = q.Close() }()
+	job := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+	if err := q.Enqueue(context.Background(), job); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	msgChan, _, err := q.Consume(ctx, 1)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	stale := receive(t, msgChan)
+	cancel()
+	for range msgChan {
+	}
+	// Settling a delivery of a stopped consumer is skipped, as for a closed broker channel
+	if err := stale.Ack(); err != nil {
+		t.Fatalf("stale Ack() error = %v", err)
+	}
+
+	msg, err := q.Dequeue(context.Background())
+	if err != nil || msg == nil {
+		t.Fatalf("Dequeue() = %v, %v; want the requeued job", msg, err)
+	}
+	if msg.GetJob().ID != job.ID || !msg.IsRedelivered() {
+		t.Errorf("dequeued job %s (redelivered %v), want %s redelivered", msg.GetJob().ID, msg.IsRedelivered(), job.ID)
+	}
+}
+
+func TestInMemoryQueue_PurgeOlderThan(t *testing.T) {
+	t.Parallel()
+
+	q := NewInMemoryQueue()
+	defer func() { _ = q.Close() }()
+	ctx := context.Background()
+
+	old := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+	old.CreatedAt = time.Now().Add(-48 * time.Hour)
+	recent := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+	for _, job := range []*Job{old, recent} {
+		if err := q.Enqueue(ctx, job); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+		msg, err := q.Dequeue(ctx)
+		if err != nil || msg == nil {
+			t.Fatalf("Dequeue() = %v, %v", msg, err)
+		}
+		if err := msg.Nack(false); err != nil {
+			t.Fatalf("Nack() error = %v", err)
+		}
+	}
+
+	purged, err := q.PurgeOlderThan(ctx, 24*time.Hour)
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeOlderThan() = %d, %v; want 1", purged, err)
+	}
+	if stats, _ := q.Stats(ctx); stats.DLQ.Depth != 1 {
+		t.Errorf("DLQ depth after purge = %d, want 1", stats.DLQ.Depth)
+	}
+}
+
+func TestInMemoryQueue_Close(t *testing.T) {
+	t.Parallel()
+
+	q := NewInMemoryQueue()
+	msgChan, _, err := q.Consume(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	select {
+	case _, ok := <-msgChan:
+		if ok {
+			t.Error("message delivered after Close")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("consumer did not stop after Close")
+	}
+	if err := q.Enqueue(context.Background(), NewJob(JobTypeTaskAnalysis, uuid.New(), nil)); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Enqueue() after Close error = %v, want ErrQueueClosed", err)
+	}
+	if err := q.HealthCheck(context.Background()); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("HealthCheck() after Close error = %v, want ErrQueueClosed", err)
+	}
+}
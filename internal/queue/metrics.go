@@ -0,0 +1,20 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/benvon/smart-todo/internal/metrics"
+)
+
+// RegisterDLQDepthGauge registers a dlq_depth gauge on registry that reads q's dead letter queue depth at
+// scrape time. Queues that cannot report their DLQ depth register nothing.
+func RegisterDLQDepthGauge(registry *metrics.Registry, q JobQueue) {
+	reporter, ok := q.(DLQStatsReporter)
+	if !ok {
+		return
+	}
+	registry.NewGaugeFunc("dlq_depth", "Jobs in the dead letter queue", func(ctx context.Context) (float64, error) {
+		depth, err := reporter.DLQDepth(ctx)
+		return float64(depth), err
+	})
+}
@@ -0,0 +1,53 @@
+package queue
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/metrics"
+)
+
+// dlqDepthQueue is a JobQueue that only reports its DLQ depth
+type dlqDepthQueue struct {
+	JobQueue
+	depth int
+}
+
+func (q *dlqDepthQueue) DLQDepth(ctx context.Context) (int, error) {
+	return q.depth, nil
+}
+
+func TestRegisterDLQDepthGauge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		queue JobQueue
+		want  string
+	}{
+		{name: "reports the DLQ depth", queue: &dlqDepthQueue{depth: 4}, want: "smart_todo_dlq_depth 4\n"},
+		{name: "queue without DLQ stats", queue: struct{ JobQueue }{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			registry := metrics.NewRegistry()
+			RegisterDLQDepthGauge(registry, tt.queue)
+
+			rec := httptest.NewRecorder()
+			registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			body, _ := io.ReadAll(rec.Body)
+			if tt.want == "" && len(body) != 0 {
+				t.Errorf("scrape = %q, want no metrics", body)
+			}
+			if !strings.Contains(string(body), tt.want) {
+				t.Errorf("scrape = %q, want %q", body, tt.want)
+			}
+		})
+	}
+}
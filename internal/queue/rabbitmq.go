@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/benvon/smart-todo/internal/metrics"
+	"github.com/benvon/smart-todo/internal/tlsconfig"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
 )
 
 const (
@@ -28,11 +31,33 @@ type RabbitMQQueue struct {
 	dlqName             string
 	exchangeName        string
 	delayedExchangeName string
+	// delayedExchangeAvailable is false when the delayed-message plugin is not installed
+	delayedExchangeAvailable bool
+	// logger receives warnings from messages settled after their delivery channel closed
+	logger *zap.Logger
 }
 
+// delayedExchangeType is the exchange type provided by the rabbitmq_delayed_message_exchange plugin
+const delayedExchangeType = "x-delayed-message"
+
 // NewRabbitMQQueue creates a new RabbitMQ queue
 func NewRabbitMQQueue(amqpURL string) (*RabbitMQQueue, error) {
-	conn, err := amqp.Dial(amqpURL)
+	return NewRabbitMQQueueWithTLS(amqpURL, tlsconfig.Options{})
+}
+
+// NewRabbitMQQueueWithTLS creates a new RabbitMQ queue, dialing with the given client TLS options when set
+func NewRabbitMQQueueWithTLS(amqpURL string, tlsOpts tlsconfig.Options) (*RabbitMQQueue, error) {
+	tlsCfg, err := tlsOpts.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build RabbitMQ TLS config: %w", err)
+	}
+
+	var conn *amqp.Connection
+	if tlsCfg != nil {
+		conn, err = amqp.DialTLS(amqpURL, tlsCfg)
+	} else {
+		conn, err = amqp.Dial(amqpURL)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
@@ -53,6 +78,7 @@ func NewRabbitMQQueue(amqpURL string) (*RabbitMQQueue, error) {
 		dlqName:             DefaultDLQName,
 		exchangeName:        DefaultExchangeName,
 		delayedExchangeName: DefaultDelayedExchangeName,
+		logger:              zap.NewNop(),
 	}
 
 	// Setup exchanges and queues
@@ -75,7 +101,7 @@ func (q *RabbitMQQueue) setup() error {
 	}
 	err := q.channel.ExchangeDeclare(
 		q.delayedExchangeName,
-		"x-delayed-message",
+		delayedExchangeType,
 		true,  // durable
 		false, // auto-deleted
 		false, // internal
@@ -94,6 +120,8 @@ func (q *RabbitMQQueue) setup() error {
 		}
 		// Log warning but continue without delayed exchange
 		fmt.Printf("Warning: delayed message exchange not available (plugin may not be installed): %v\n", err)
+	} else {
+		q.delayedExchangeAvailable = true
 	}
 
 	// Declare regular exchange
@@ -177,6 +205,24 @@ func (q *RabbitMQQueue) setup() error {
 	return nil
 }
 
+// DependencyInfo reports the broker product and version and whether the delayed-message plugin is usable.
+// Without the plugin, jobs with NotBefore are delivered immediately instead of being delayed by the broker.
+func (q *RabbitMQQueue) DependencyInfo(ctx context.Context) (map[string]string, error) {
+	info := map[string]string{
+		"product": fmt.Sprint(q.conn.Properties["product"]),
+		"version": fmt.Sprint(q.conn.Properties["version"]),
+	}
+	ch, err := q.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer func() { _ = ch.Close() }()
+	// A passive declare fails (and closes the channel) if the exchange type is unknown to the broker
+	err = ch.ExchangeDeclarePassive(q.delayedExchangeName, delayedExchangeType, true, false, false, false, nil)
+	info["delayed_message_plugin"] = fmt.Sprint(err == nil)
+	return info, nil
+}
+
 // Enqueue adds a job to the queue
 func (q *RabbitMQQueue) Enqueue(ctx context.Context, job *Job) error {
 	jobJSON, err := json.Marshal(job)
@@ -184,48 +230,17 @@ func (q *RabbitMQQueue) Enqueue(ctx context.Context, job *Job) error {
 		return fmt.Errorf("failed to marshal job: %w", err)
 	}
 
-	publishing := amqp.Publishing{
-		ContentType:  "application/json",
-		Body:         jobJSON,
-		DeliveryMode: amqp.Persistent, // Make message persistent
-		MessageId:    job.ID.String(),
-		Timestamp:    job.CreatedAt,
-	}
-
-	// Calculate TTL from NotAfter if set
-	if job.NotAfter != nil {
-		ttl := time.Until(*job.NotAfter)
-		if ttl > 0 {
-			publishing.Expiration = fmt.Sprintf("%d", int(ttl.Milliseconds()))
-		}
-	}
-
-	var exchangeName string
-	var routingKey string
-	var headers amqp.Table
-
 	// Use delayed exchange if NotBefore is set
-	if job.NotBefore != nil {
-		delay := time.Until(*job.NotBefore)
-		if delay > 0 {
-			exchangeName = q.delayedExchangeName
-			headers = amqp.Table{
-				"x-delay": int(delay.Milliseconds()),
-			}
-			publishing.Headers = headers
-		} else {
-			exchangeName = q.exchangeName
-		}
-	} else {
-		exchangeName = q.exchangeName
+	exchangeName := q.exchangeName
+	publishing, delayed := newJobPublishing(job, jobJSON, time.Now())
+	if delayed {
+		exchangeName = q.delayedExchangeName
 	}
 
-	routingKey = "jobs"
-
 	err = q.channel.PublishWithContext(
 		ctx,
 		exchangeName,
-		routingKey,
+		jobRoutingKey,
 		false, // mandatory
 		false, // immediate
 		publishing,
@@ -233,6 +248,7 @@ func (q *RabbitMQQueue) Enqueue(ctx context.Context, job *Job) error {
 	if err != nil {
 		return fmt.Errorf("failed to publish job: %w", err)
 	}
+	metrics.JobsEnqueued.Inc(string(job.Type))
 
 	return nil
 }
@@ -281,22 +297,22 @@ func (q *RabbitMQQueue) Consume(ctx context.Context, prefetchCount int) (<-chan
 	msgChan := make(chan *Message, prefetchCount)
 	errChan := make(chan error, 1)
 
-	go runConsumeLoop(ctx, deliveries, consumeCh, msgChan, errChan)
+	go runConsumeLoop(ctx, deliveries, consumeCh, q.notReadyHolder(consumeCh), q.logger, msgChan, errChan)
 
 	return msgChan, errChan, nil
 }
 
 // runConsumeLoop receives deliveries, converts them to Messages, and sends on msgChan until ctx is done or deliveries close.
-func runConsumeLoop(ctx context.Context, deliveries <-chan amqp.Delivery, consumeCh *amqp.Channel, msgChan chan *Message, errChan chan error) {
+func runConsumeLoop(ctx context.Context, deliveries <-chan amqp.Delivery, consumeCh *amqp.Channel, holder *notReadyHolder, logger *zap.Logger, msgChan chan *Message, errChan chan error) {
 	defer close(msgChan)
 	defer close(errChan)
 	defer func() { _ = consumeCh.Close() }()
-	for processOneDelivery(ctx, deliveries, consumeCh, msgChan, errChan) {
+	for processOneDelivery(ctx, deliveries, consumeCh, holder, logger, msgChan, errChan) {
 	}
 }
 
 // processOneDelivery receives one delivery (or handles ctx.Done), processes it, and optionally sends on msgChan. Returns false to stop the loop.
-func processOneDelivery(ctx context.Context, deliveries <-chan amqp.Delivery, ch *amqp.Channel, msgChan chan *Message, errChan chan error) bool {
+func processOneDelivery(ctx context.Context, deliveries <-chan amqp.Delivery, ch *amqp.Channel, holder *notReadyHolder, logger *zap.Logger, msgChan chan *Message, errChan chan error) bool {
 	select {
 	case <-ctx.Done():
 		return false
@@ -305,7 +321,7 @@ func processOneDelivery(ctx context.Context, deliveries <-chan amqp.Delivery, ch
 			errChan <- fmt.Errorf("delivery channel closed")
 			return false
 		}
-		msg, err := processConsumeDelivery(delivery, ch)
+		msg, err := processConsumeDelivery(ctx, delivery, ch, holder, logger)
 		if err != nil {
 			_ = delivery.Nack(false, false)
 			errChan <- err
@@ -326,7 +342,8 @@ func processOneDelivery(ctx context.Context, deliveries <-chan amqp.Delivery, ch
 
 // processConsumeDelivery converts a delivery into a Message or returns an error.
 // Returns (nil, nil) when the message was expired or not ready (caller should not send to errChan).
-func processConsumeDelivery(delivery amqp.Delivery, ch *amqp.Channel) (*Message, error) {
+// Expired jobs are dropped; jobs not ready yet are handed to holder.
+func processConsumeDelivery(ctx context.Context, delivery amqp.Delivery, ch *amqp.Channel, holder *notReadyHolder, logger *zap.Logger) (*Message, error) {
 	if delivery.Expiration != "" {
 		_ = delivery.Nack(false, false)
 		return nil, nil
@@ -335,17 +352,44 @@ func processConsumeDelivery(delivery amqp.Delivery, ch *amqp.Channel) (*Message,
 	if err := json.Unmarshal(delivery.Body, &job); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal job: %w", err)
 	}
+	if job.IsExpired() {
+		_ = delivery.Nack(false, false)
+		return nil, nil
+	}
 	if !job.ShouldProcess() {
-		_ = delivery.Nack(false, true)
+		holder.hold(ctx, delivery, delivery.Body, &job)
 		return nil, nil
 	}
 	return &Message{
 		Job:         &job,
 		DeliveryTag: delivery.DeliveryTag,
 		Channel:     ch,
+		Redelivered: delivery.Redelivered,
+		logger:      logger,
 	}, nil
 }
 
+// notReadyHolder returns the holder for jobs delivered on ch ahead of their NotBefore, re-publishing them
+// on ch when the delayed exchange is available
+func (q *RabbitMQQueue) notReadyHolder(ch *amqp.Channel) *notReadyHolder {
+	holder := &notReadyHolder{
+		delayedExchange: q.delayedExchangeName,
+		maxRequeueDelay: MaxNotReadyRequeueDelay,
+		now:             time.Now,
+	}
+	if q.delayedExchangeAvailable {
+		holder.publisher = ch
+	}
+	return holder
+}
+
+// SetLogger sets the logger for warnings about messages acked or nacked after their delivery channel closed
+func (q *RabbitMQQueue) SetLogger(logger *zap.Logger) {
+	if logger != nil {
+		q.logger = logger
+	}
+}
+
 // Dequeue removes and returns a message from the queue
 // DEPRECATED: Use Consume() for better performance and scalability
 func (q *RabbitMQQueue) Dequeue(ctx context.Context) (*Message, error) {
@@ -376,10 +420,13 @@ func (q *RabbitMQQueue) Dequeue(ctx context.Context) (*Message, error) {
 		return nil, fmt.Errorf("failed to unmarshal job: %w", err)
 	}
 
-	// Check if job should be processed now (respect NotBefore)
+	// Drop expired jobs; hold jobs that are not due yet (respect NotBefore)
+	if job.IsExpired() {
+		_ = msg.Nack(false, false)
+		return nil, nil
+	}
 	if !job.ShouldProcess() {
-		// Requeue the job if it's not time yet
-		_ = msg.Nack(false, true)
+		q.notReadyHolder(q.channel).hold(ctx, msg, msg.Body, &job)
 		return nil, nil
 	}
 
@@ -387,6 +434,8 @@ func (q *RabbitMQQueue) Dequeue(ctx context.Context) (*Message, error) {
 		Job:         &job,
 		DeliveryTag: msg.DeliveryTag,
 		Channel:     q.channel,
+		Redelivered: msg.Redelivered,
+		logger:      q.logger,
 	}, nil
 }
 
@@ -424,6 +473,52 @@ func (q *RabbitMQQueue) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// QueueStats implements StatsReporter for the main queue
+func (q *RabbitMQQueue) QueueStats(ctx context.Context) (QueueStats, error) {
+	stats, err := q.inspect(q.queueName)
+	if err != nil {
+		return QueueStats{}, err
+	}
+	return stats[0], nil
+}
+
+// DLQDepth implements DLQStatsReporter
+func (q *RabbitMQQueue) DLQDepth(ctx context.Context) (int, error) {
+	stats, err := q.inspect(q.dlqName)
+	if err != nil {
+		return 0, err
+	}
+	return stats[0].Depth, nil
+}
+
+// Stats implements Inspector, reading the main queue and the DLQ on one channel
+func (q *RabbitMQQueue) Stats(ctx context.Context) (Stats, error) {
+	stats, err := q.inspect(q.queueName, q.dlqName)
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{Queue: stats[0], DLQ: stats[1]}, nil
+}
+
+// inspect reads the message and consumer counts of the named queues with passive declares (the non-deprecated
+// form of QueueInspect). Uses a dedicated channel so a failed declare does not close the channel used for publishing.
+func (q *RabbitMQQueue) inspect(names ...string) ([]QueueStats, error) {
+	ch, err := q.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer func() { _ = ch.Close() }()
+	stats := make([]QueueStats, 0, len(names))
+	for _, name := range names {
+		info, err := ch.QueueDeclarePassive(name, true, false, false, false, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect queue %s: %w", name, err)
+		}
+		stats = append(stats, QueueStats{Depth: info.Messages, Consumers: info.Consumers})
+	}
+	return stats, nil
+}
+
 // PurgeOlderThan implements DLQPurger. It Get()s from the DLQ, acks (discards) messages older than
 // retention, and nacks without requeue for newer ones so the loop can complete. Uses a dedicated
 // channel to avoid interfering with main queue operations.
@@ -0,0 +1,95 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// MaxNotReadyRequeueDelay bounds how long a consumer waits before requeueing a job delivered ahead of its
+// NotBefore when the job cannot be handed to the delayed exchange
+const MaxNotReadyRequeueDelay = 5 * time.Second
+
+// jobRoutingKey routes jobs from both exchanges to the job queue
+const jobRoutingKey = "jobs"
+
+// jobPublisher publishes to an exchange; *amqp.Channel implements it
+type jobPublisher interface {
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
+// deliveryAcker acknowledges one delivery; amqp.Delivery implements it
+type deliveryAcker interface {
+	Ack(multiple bool) error
+	Nack(multiple, requeue bool) error
+}
+
+// newJobPublishing builds the persistent publishing for a job's JSON body, with a TTL from NotAfter. When
+// NotBefore is still ahead of now, delayed is true and the publishing carries the remaining wait as x-delay
+// for the delayed exchange.
+func newJobPublishing(job *Job, body []byte, now time.Time) (publishing amqp.Publishing, delayed bool) {
+	publishing = amqp.Publishing{
+		ContentType:  "application/json",
+		Body:         body,
+		DeliveryMode: amqp.Persistent, // Make message persistent
+		MessageId:    job.ID.String(),
+		Timestamp:    job.CreatedAt,
+	}
+	if job.NotAfter != nil {
+		if ttl := job.NotAfter.Sub(now); ttl > 0 {
+			publishing.Expiration = fmt.Sprintf("%d", int(ttl.Milliseconds()))
+		}
+	}
+	if job.NotBefore != nil {
+		if delay := job.NotBefore.Sub(now); delay > 0 {
+			publishing.Headers = amqp.Table{"x-delay": int(delay.Milliseconds())}
+			delayed = true
+		}
+	}
+	return publishing, delayed
+}
+
+// notReadyHolder keeps jobs delivered ahead of their NotBefore out of the consumer until they are due.
+// Requeueing them directly would redeliver them at once, spinning the consumer and the broker until
+// NotBefore passes.
+type notReadyHolder struct {
+	// publisher is nil when the delayed exchange is unavailable
+	publisher       jobPublisher
+	delayedExchange string
+	maxRequeueDelay time.Duration
+	now             func() time.Time
+}
+
+// hold re-publishes a not-ready job to the delayed exchange, which holds it for the remaining wait, and acks
+// the original delivery. Without the delayed exchange, or if the publish fails, it waits out the remaining
+// time (at most maxRequeueDelay, or until ctx is done) and requeues the delivery instead.
+func (h *notReadyHolder) hold(ctx context.Context, delivery deliveryAcker, body []byte, job *Job) {
+	now := h.now()
+	wait := time.Duration(0)
+	if job.NotBefore != nil {
+		wait = job.NotBefore.Sub(now)
+	}
+	if h.publisher != nil {
+		if publishing, delayed := newJobPublishing(job, body, now); delayed {
+			if err := h.publisher.PublishWithContext(ctx, h.delayedExchange, jobRoutingKey, false, false, publishing); err == nil {
+				_ = delivery.Ack(false)
+				return
+			}
+		}
+	}
+
+	if wait > h.maxRequeueDelay {
+		wait = h.maxRequeueDelay
+	}
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+	_ = delivery.Nack(false, true)
+}
@@ -0,0 +1,212 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type publishCall struct {
+	exchange string
+	key      string
+	msg      amqp.Publishing
+}
+
+type mockJobPublisher struct {
+	calls []publishCall
+	err   error
+}
+
+func (m *mockJobPublisher) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	m.calls = append(m.calls, publishCall{exchange: exchange, key: key, msg: msg})
+	return m.err
+}
+
+type mockDeliveryAcker struct {
+	acks     int
+	nacks    int
+	requeued bool
+}
+
+func (m *mockDeliveryAcker) Ack(multiple bool) error {
+	m.acks++
+	return nil
+}
+
+func (m *mockDeliveryAcker) Nack(multiple, requeue bool) error {
+	m.nacks++
+	m.requeued = requeue
+	return nil
+}
+
+func TestNotReadyHolder_Hold(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		publisher     *mockJobPublisher
+		notBefore     time.Duration
+		wantPublished bool
+		wantDelayMs   int
+		wantAck       bool
+		wantWait      time.Duration
+	}{
+		{
+			name:          "re-published with the remaining wait",
+			publisher:     &mockJobPublisher{},
+			notBefore:     90 * time.Second,
+			wantPublished: true,
+			wantDelayMs:   90000,
+			wantAck:       true,
+		},
+		{
+			name:      "delayed exchange unavailable",
+			notBefore: 20 * time.Millisecond,
+			wantWait:  20 * time.Millisecond,
+		},
+		{
+			name:          "publish fails",
+			publisher:     &mockJobPublisher{err: errors.New("channel closed")},
+			notBefore:     20 * time.Millisecond,
+			wantPublished: true,
+			wantDelayMs:   20,
+			wantWait:      20 * time.Millisecond,
+		},
+		{
+			name:      "fallback wait is bounded",
+			notBefore: time.Hour,
+			wantWait:  50 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			job := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+			notBefore := now.Add(tt.notBefore)
+			job.NotBefore = &notBefore
+			body, err := json.Marshal(job)
+			if err != nil {
+				t.Fatalf("marshal job: %v", err)
+			}
+
+			holder := &notReadyHolder{
+				delayedExchange: DefaultDelayedExchangeName,
+				maxRequeueDelay: 50 * time.Millisecond,
+				now:             func() time.Time { return now },
+			}
+			if tt.publisher != nil {
+				holder.publisher = tt.publisher
+			}
+			delivery := &mockDeliveryAcker{}
+
+			start := time.Now()
+			holder.hold(context.Background(), delivery, body, job)
+			elapsed := time.Since(start)
+
+			if tt.wantPublished {
+				if len(tt.publisher.calls) != 1 {
+					t.Fatalf("published %d times, want once", len(tt.publisher.calls))
+				}
+				call := tt.publisher.calls[0]
+				if call.exchange != DefaultDelayedExchangeName || call.key != jobRoutingKey {
+					t.Errorf("published to %q/%q, want %q/%q", call.exchange, call.key, DefaultDelayedExchangeName, jobRoutingKey)
+				}
+				if got := call.msg.Headers["x-delay"]; got != tt.wantDelayMs {
+					t.Errorf("x-delay = %v, want %d", got, tt.wantDelayMs)
+				}
+				if string(call.msg.Body) != string(body) || call.msg.MessageId != job.ID.String() || call.msg.DeliveryMode != amqp.Persistent {
+					t.Errorf("re-published message does not match the original job: %+v", call.msg)
+				}
+			} else if tt.publisher != nil && len(tt.publisher.calls) != 0 {
+				t.Errorf("published %d times, want none", len(tt.publisher.calls))
+			}
+
+			if tt.wantAck {
+				if delivery.acks != 1 || delivery.nacks != 0 {
+					t.Errorf("acks = %d, nacks = %d, want the original acked only", delivery.acks, delivery.nacks)
+				}
+				return
+			}
+			if delivery.acks != 0 || delivery.nacks != 1 || !delivery.requeued {
+				t.Errorf("acks = %d, nacks = %d (requeue %v), want one requeue", delivery.acks, delivery.nacks, delivery.requeued)
+			}
+			if elapsed < tt.wantWait {
+				t.Errorf("requeued after %v, want at least %v", elapsed, tt.wantWait)
+			}
+			if elapsed > tt.wantWait+time.Second {
+				t.Errorf("requeued after %v, want about %v", elapsed, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestNotReadyHolder_HoldStopsWaitingOnCancel(t *testing.T) {
+	t.Parallel()
+
+	job := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+	notBefore := time.Now().Add(time.Hour)
+	job.NotBefore = &notBefore
+	holder := &notReadyHolder{maxRequeueDelay: time.Hour, now: time.Now}
+	delivery := &mockDeliveryAcker{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	holder.hold(ctx, delivery, nil, job)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("hold waited %v after cancellation", elapsed)
+	}
+	if delivery.nacks != 1 || !delivery.requeued {
+		t.Errorf("nacks = %d (requeue %v), want the delivery requeued", delivery.nacks, delivery.requeued)
+	}
+}
+
+func TestNewJobPublishing(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	past, soon, later := now.Add(-time.Minute), now.Add(2*time.Second), now.Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		notBefore      *time.Time
+		notAfter       *time.Time
+		wantDelayed    bool
+		wantDelay      any
+		wantExpiration string
+	}{
+		{name: "immediate"},
+		{name: "not before passed", notBefore: &past},
+		{name: "delayed", notBefore: &soon, wantDelayed: true, wantDelay: 2000},
+		{name: "with TTL", notAfter: &later, wantExpiration: "3600000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			job := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+			job.NotBefore, job.NotAfter = tt.notBefore, tt.notAfter
+			publishing, delayed := newJobPublishing(job, []byte("{}"), now)
+			if delayed != tt.wantDelayed {
+				t.Errorf("delayed = %v, want %v", delayed, tt.wantDelayed)
+			}
+			if got := publishing.Headers["x-delay"]; got != tt.wantDelay {
+				t.Errorf("x-delay = %v, want %v", got, tt.wantDelay)
+			}
+			if publishing.Expiration != tt.wantExpiration {
+				t.Errorf("expiration = %q, want %q", publishing.Expiration, tt.wantExpiration)
+			}
+		})
+	}
+}
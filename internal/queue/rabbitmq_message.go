@@ -1,24 +1,64 @@
 package queue
 
 import (
+	"errors"
+
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
 )
 
+// DeliveryChannel settles deliveries on the channel they arrived on; *amqp.Channel implements it
+type DeliveryChannel interface {
+	Ack(tag uint64, multiple bool) error
+	Nack(tag uint64, multiple, requeue bool) error
+	IsClosed() bool
+}
+
 // Message wraps a Job with its RabbitMQ delivery information
 type Message struct {
 	Job         *Job
 	DeliveryTag uint64
-	Channel     *amqp.Channel
+	// Channel is the channel the message was delivered on; its delivery tag is only valid there
+	Channel DeliveryChannel
+	// Redelivered is set when the broker delivered the message before without it being acknowledged
+	Redelivered bool
+
+	logger *zap.Logger
 }
 
-// Ack acknowledges the message
+// Ack acknowledges the message. If the delivery channel has closed (e.g. during a reconnect) the ack is
+// skipped with a warning: the broker has already requeued the message and will redeliver it on a new
+// channel, and acking the stale delivery tag could only fail.
 func (m *Message) Ack() error {
-	return m.Channel.Ack(m.DeliveryTag, false)
+	if m.Channel.IsClosed() {
+		m.warnStaleDelivery("ack")
+		return nil
+	}
+	if err := m.Channel.Ack(m.DeliveryTag, false); err != nil {
+		if errors.Is(err, amqp.ErrClosed) {
+			m.warnStaleDelivery("ack")
+			return nil
+		}
+		return err
+	}
+	return nil
 }
 
-// Nack negatively acknowledges the message
+// Nack negatively acknowledges the message. Like Ack, it is skipped with a warning when the delivery
+// channel has closed; the broker then requeues the message regardless of requeue.
 func (m *Message) Nack(requeue bool) error {
-	return m.Channel.Nack(m.DeliveryTag, false, requeue)
+	if m.Channel.IsClosed() {
+		m.warnStaleDelivery("nack")
+		return nil
+	}
+	if err := m.Channel.Nack(m.DeliveryTag, false, requeue); err != nil {
+		if errors.Is(err, amqp.ErrClosed) {
+			m.warnStaleDelivery("nack")
+			return nil
+		}
+		return err
+	}
+	return nil
 }
 
 // GetJob returns the job associated with this message
@@ -26,5 +66,27 @@ func (m *Message) GetJob() *Job {
 	return m.Job
 }
 
-// Ensure Message implements MessageInterface
-var _ MessageInterface = (*Message)(nil)
+// IsRedelivered reports whether the broker delivered the message before
+func (m *Message) IsRedelivered() bool {
+	return m.Redelivered
+}
+
+func (m *Message) warnStaleDelivery(op string) {
+	if m.logger == nil {
+		return
+	}
+	fields := []zap.Field{
+		zap.String("operation", op),
+		zap.Uint64("delivery_tag", m.DeliveryTag),
+	}
+	if m.Job != nil {
+		fields = append(fields, zap.String("job_id", m.Job.ID.String()), zap.String("job_type", string(m.Job.Type)))
+	}
+	m.logger.Warn("queue_delivery_channel_closed", fields...)
+}
+
+// Ensure Message implements MessageInterface and RedeliveryReporter
+var (
+	_ MessageInterface   = (*Message)(nil)
+	_ RedeliveryReporter = (*Message)(nil)
+)
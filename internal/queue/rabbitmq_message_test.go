@@ -0,0 +1,93 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// fakeDeliveryChannel records acks and nacks; closed simulates a channel lost to a reconnect
+type fakeDeliveryChannel struct {
+	closed  bool
+	err     error
+	acked   []uint64
+	nacked  []uint64
+	requeue []bool
+}
+
+func (c *fakeDeliveryChannel) Ack(tag uint64, multiple bool) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.acked = append(c.acked, tag)
+	return nil
+}
+
+func (c *fakeDeliveryChannel) Nack(tag uint64, multiple, requeue bool) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.nacked = append(c.nacked, tag)
+	c.requeue = append(c.requeue, requeue)
+	return nil
+}
+
+func (c *fakeDeliveryChannel) IsClosed() bool { return c.closed }
+
+func TestMessage_SettleOnClosedChannel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		channel  *fakeDeliveryChannel
+		wantErr  bool
+		wantWarn bool
+	}{
+		{name: "open channel", channel: &fakeDeliveryChannel{}},
+		{name: "closed channel", channel: &fakeDeliveryChannel{closed: true}, wantWarn: true},
+		{name: "channel closes during settle", channel: &fakeDeliveryChannel{err: amqp.ErrClosed}, wantWarn: true},
+		{name: "other error", channel: &fakeDeliveryChannel{err: errors.New("frame error")}, wantErr: true},
+	}
+
+	settle := map[string]func(m *Message) error{
+		"ack":  (*Message).Ack,
+		"nack": func(m *Message) error { return m.Nack(true) },
+	}
+
+	for _, tt := range tests {
+		for op, fn := range settle {
+			t.Run(tt.name+"/"+op, func(t *testing.T) {
+				t.Parallel()
+				ch := *tt.channel
+				core, logs := observer.New(zapcore.WarnLevel)
+				msg := &Message{Job: NewJob(JobTypeTaskAnalysis, uuid.New(), nil), DeliveryTag: 7, Channel: &ch, logger: zap.New(core)}
+
+				err := fn(msg)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("%s() error = %v, wantErr %v", op, err, tt.wantErr)
+				}
+				if got := logs.FilterMessage("queue_delivery_channel_closed").Len() == 1; got != tt.wantWarn {
+					t.Errorf("warned = %v, want %v", got, tt.wantWarn)
+				}
+				settled := len(ch.acked) + len(ch.nacked)
+				if wantSettled := !tt.channel.closed && tt.channel.err == nil; (settled == 1) != wantSettled {
+					t.Errorf("settled %d deliveries on the channel, want settled %v", settled, wantSettled)
+				}
+			})
+		}
+	}
+}
+
+func TestMessage_WithoutLogger(t *testing.T) {
+	t.Parallel()
+
+	msg := &Message{Job: NewJob(JobTypeTaskAnalysis, uuid.New(), nil), Channel: &fakeDeliveryChannel{closed: true}}
+	if err := msg.Ack(); err != nil {
+		t.Errorf("Ack() on a closed channel without a logger error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,643 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/metrics"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// RedisQueueGroup is the consumer group all workers read the job stream with
+	RedisQueueGroup = "workers"
+	// RedisQueuePollInterval is how long a consumer blocks waiting for jobs before moving due delayed jobs
+	// to the stream again
+	RedisQueuePollInterval = time.Second
+	// RedisQueueClaimIdle is how long a delivered job may go without a heartbeat from its consumer before
+	// another consumer takes it over, e.g. after the worker holding it crashed. Consumers heartbeat the jobs
+	// they hold every third of it, so jobs running longer are not taken over.
+	RedisQueueClaimIdle = 10 * time.Minute
+
+	// redisQueueOpTimeout bounds the Redis calls made to settle a delivery, which get no context
+	redisQueueOpTimeout = 5 * time.Second
+	// redisQueuePromoteBatch is how many due delayed jobs one promotion moves to the stream
+	redisQueuePromoteBatch = 100
+	// redisDLQPurgePage is how many dead-lettered jobs PurgeOlderThan reads at a time
+	redisDLQPurgePage = 100
+	// redisJobField and redisRedeliveredField are the fields of a job stream entry
+	redisJobField         = "job"
+	redisRedeliveredField = "redelivered"
+)
+
+// redisPromoteScript moves delayed jobs whose NotBefore has passed from the delayed sorted set to the stream.
+// KEYS[1] = delayed set, KEYS[2] = stream, ARGV[1] = now (ms), ARGV[2] = batch size. Returns how many moved.
+var redisPromoteScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, body in ipairs(due) do
+	redis.call('XADD', KEYS[2], '*', 'job', body)
+	redis.call('ZREM', KEYS[1], body)
+end
+return #due
+`)
+
+// redisTrimScript removes the first ARGV[1] entries of a list if the last of them is still ARGV[2], so entries
+// another purge removed meanwhile are not counted twice and newer ones are not removed.
+// KEYS[1] = list. Returns how many were removed.
+var redisTrimScript = redis.NewScript(`
+if redis.call('LINDEX', KEYS[1], ARGV[1] - 1) ~= ARGV[2] then
+	return 0
+end
+redis.call('LTRIM', KEYS[1], ARGV[1], -1)
+return tonumber(ARGV[1])
+`)
+
+// RedisQueue implements JobQueue with Redis, for deployments that run Redis but not RabbitMQ. Ready jobs are
+// entries of a stream read through one consumer group, so each job goes to one consumer and stays pending
+// until it is acked or nacked. Consumers heartbeat the jobs they hold, and jobs left pending by a crashed
+// worker are claimed by another after RedisQueueClaimIdle. Jobs with NotBefore ahead wait in a sorted set scored by NotBefore until a consumer
+// moves them to the stream, and dead-lettered jobs are kept in a list. Jobs are stored as the same JSON
+// RabbitMQ carries. The Redis client is owned by the caller.
+type RedisQueue struct {
+	client     redis.Cmdable
+	stream     string
+	delayedKey string
+	dlqKey     string
+	// id distinguishes this process's consumers in the group
+	id string
+	// claimIdle and pollInterval default to RedisQueueClaimIdle and RedisQueuePollInterval
+	claimIdle    time.Duration
+	pollInterval time.Duration
+
+	mu       sync.Mutex
+	closed   bool
+	done     chan struct{}
+	dequeued *redisChannel
+	// heartbeatDequeued starts the heartbeat of the Dequeue consumer on first use
+	heartbeatDequeued sync.Once
+	logger            *zap.Logger
+}
+
+// NewRedisQueue creates a Redis queue on client, creating the consumer group if it does not exist
+func NewRedisQueue(ctx context.Context, client redis.Cmdable) (*RedisQueue, error) {
+	q := &RedisQueue{
+		client:       client,
+		stream:       DefaultQueueName,
+		delayedKey:   DefaultQueueName + ":delayed",
+		dlqKey:       DefaultDLQName,
+		id:           uuid.NewString(),
+		claimIdle:    RedisQueueClaimIdle,
+		pollInterval: RedisQueuePollInterval,
+		done:         make(chan struct{}),
+		logger:       zap.NewNop(),
+	}
+	if err := client.XGroupCreateMkStream(ctx, q.stream, RedisQueueGroup, "0").Err(); err != nil &&
+		!strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	q.dequeued = newRedisChannel(q, q.id+":dequeue", 0)
+	return q, nil
+}
+
+// SetLogger sets the logger for warnings about messages acked or nacked after their consumer stopped
+func (q *RedisQueue) SetLogger(logger *zap.Logger) {
+	if logger != nil {
+		q.logger = logger
+	}
+}
+
+// Enqueue adds a job to the stream, or to the delayed set when NotBefore is still ahead
+func (q *RedisQueue) Enqueue(ctx context.Context, job *Job) error {
+	if q.isClosed() {
+		return ErrQueueClosed
+	}
+	body, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+	if job.NotBefore != nil && job.NotBefore.After(time.Now()) {
+		err = q.client.ZAdd(ctx, q.delayedKey, redis.Z{Score: float64(job.NotBefore.UnixMilli()), Member: body}).Err()
+	} else {
+		err = q.client.XAdd(ctx, &redis.XAddArgs{Stream: q.stream, Values: []any{redisJobField, body}}).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	metrics.JobsEnqueued.Inc(string(job.Type))
+	return nil
+}
+
+// promote moves due delayed jobs to the stream
+func (q *RedisQueue) promote(ctx context.Context) error {
+	err := redisPromoteScript.Run(ctx, q.client, []string{q.delayedKey, q.stream},
+		time.Now().UnixMilli(), redisQueuePromoteBatch).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to promote delayed jobs: %w", err)
+	}
+	return nil
+}
+
+// Dequeue returns the next ready job without waiting, or nil when there is none
+// DEPRECATED: Use Consume() for better performance and scalability
+func (q *RedisQueue) Dequeue(ctx context.Context) (*Message, error) {
+	if q.isClosed() {
+		return nil, ErrQueueClosed
+	}
+	if err := q.promote(ctx); err != nil {
+		return nil, err
+	}
+	q.heartbeatDequeued.Do(func() { go q.dequeued.heartbeat(context.Background()) })
+	for {
+		entry, err := q.dequeued.read(ctx, -1)
+		if err != nil || entry == nil {
+			return nil, err
+		}
+		if msg := q.dequeued.deliver(ctx, *entry, false); msg != nil {
+			return msg, nil
+		}
+	}
+}
+
+// Consume delivers ready jobs until ctx is done, with at most prefetchCount delivered but not yet acked or
+// nacked. When ctx is done the jobs still unsettled are requeued, as a broker does for a closed channel.
+func (q *RedisQueue) Consume(ctx context.Context, prefetchCount int) (<-chan *Message, <-chan error, error) {
+	if q.isClosed() {
+		return nil, nil, ErrQueueClosed
+	}
+	if prefetchCount < 1 {
+		prefetchCount = 1
+	}
+	ch := newRedisChannel(q, q.id+":"+uuid.NewString(), prefetchCount)
+	msgChan := make(chan *Message, prefetchCount)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(msgChan)
+		defer close(errChan)
+		defer ch.close()
+		go ch.heartbeat(ctx)
+		for {
+			msg, ok := ch.next(ctx, errChan)
+			if !ok {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case msgChan <- msg:
+			}
+		}
+	}()
+	return msgChan, errChan, nil
+}
+
+// Close stops new enqueues and consumers; the caller closes the Redis client
+func (q *RedisQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.done)
+	}
+	return nil
+}
+
+func (q *RedisQueue) isClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+// HealthCheck verifies Redis answers and the queue is open
+func (q *RedisQueue) HealthCheck(ctx context.Context) error {
+	if q.isClosed() {
+		return ErrQueueClosed
+	}
+	if err := q.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis queue health check failed: %w", err)
+	}
+	return nil
+}
+
+// QueueStats implements StatsReporter for the ready jobs (delayed jobs are not counted)
+func (q *RedisQueue) QueueStats(ctx context.Context) (QueueStats, error) {
+	length, err := q.client.XLen(ctx, q.stream).Result()
+	if err != nil {
+		return QueueStats{}, fmt.Errorf("failed to read job stream length: %w", err)
+	}
+	groups, err := q.client.XInfoGroups(ctx, q.stream).Result()
+	if err != nil {
+		return QueueStats{}, fmt.Errorf("failed to read consumer group: %w", err)
+	}
+	stats := QueueStats{Depth: int(length)}
+	for _, g := range groups {
+		if g.Name == RedisQueueGroup {
+			// Pending jobs are delivered and still in the stream until settled
+			stats.Depth = int(length - g.Pending)
+			stats.Consumers = int(g.Consumers)
+		}
+	}
+	return stats, nil
+}
+
+// DLQDepth implements DLQStatsReporter
+func (q *RedisQueue) DLQDepth(ctx context.Context) (int, error) {
+	n, err := q.client.LLen(ctx, q.dlqKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read DLQ length: %w", err)
+	}
+	return int(n), nil
+}
+
+// Stats implements Inspector
+func (q *RedisQueue) Stats(ctx context.Context) (Stats, error) {
+	main, err := q.QueueStats(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	dlq, err := q.DLQDepth(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{Queue: main, DLQ: QueueStats{Depth: dlq}}, nil
+}
+
+// PurgeOlderThan implements DLQPurger, removing dead-lettered jobs created more than retention ago. Jobs are
+// dead-lettered at the tail of the list, so the old ones are trimmed from its head a page at a time, stopping
+// at the first job still within retention; an old job behind it is purged once that one expires too.
+func (q *RedisQueue) PurgeOlderThan(ctx context.Context, retention time.Duration) (int, error) {
+	cutoff := time.Now().Add(-retention)
+	purged := 0
+	for {
+		page, err := q.client.LRange(ctx, q.dlqKey, 0, redisDLQPurgePage-1).Result()
+		if err != nil {
+			return purged, fmt.Errorf("DLQ purge read: %w", err)
+		}
+		old := 0
+		for old < len(page) && deadLetterCreatedBefore([]byte(page[old]), cutoff) {
+			old++
+		}
+		if old == 0 {
+			return purged, nil
+		}
+		n, err := redisTrimScript.Run(ctx, q.client, []string{q.dlqKey}, old, page[old-1]).Int()
+		if err != nil {
+			return purged, fmt.Errorf("DLQ purge trim: %w", err)
+		}
+		purged += n
+		// A page with a job still within retention is the last; a trim that removed nothing lost a race with
+		// another purge and is retried on the list as it is now
+		if n > 0 && old < len(page) {
+			return purged, nil
+		}
+	}
+}
+
+// deadLetterCreatedBefore reports whether the dead-lettered job body was created before cutoff. Bodies that
+// are not valid jobs are treated as old so they do not stay in the DLQ forever.
+func deadLetterCreatedBefore(body []byte, cutoff time.Time) bool {
+	var job Job
+	if err := json.Unmarshal(body, &job); err != nil {
+		return true
+	}
+	return job.CreatedAt.Before(cutoff)
+}
+
+// redisEntry is a job stream entry
+type redisEntry struct {
+	id          string
+	body        []byte
+	redelivered bool
+}
+
+// parseRedisEntry reads a job stream entry; claimed entries were delivered before
+func parseRedisEntry(msg redis.XMessage, claimed bool) (redisEntry, error) {
+	body, ok := msg.Values[redisJobField].(string)
+	if !ok {
+		return redisEntry{id: msg.ID}, fmt.Errorf("stream entry %s has no job", msg.ID)
+	}
+	_, redelivered := msg.Values[redisRedeliveredField]
+	return redisEntry{id: msg.ID, body: []byte(body), redelivered: redelivered || claimed}, nil
+}
+
+// redisChannel is the DeliveryChannel of one consumer of a RedisQueue. It tracks the jobs delivered to the
+// consumer until they are settled and limits how many may be outstanding.
+type redisChannel struct {
+	q        *RedisQueue
+	consumer string
+	// slots has a token for each delivery the prefetch limit still allows; nil means unlimited
+	slots     chan struct{}
+	lastClaim time.Time
+
+	mu      sync.Mutex
+	nextTag uint64
+	unacked map[uint64]redisEntry
+	closed  bool
+}
+
+func newRedisChannel(q *RedisQueue, consumer string, prefetch int) *redisChannel {
+	ch := &redisChannel{q: q, consumer: consumer, unacked: make(map[uint64]redisEntry)}
+	if prefetch > 0 {
+		ch.slots = make(chan struct{}, prefetch)
+		for range prefetch {
+			ch.slots <- struct{}{}
+		}
+	}
+	return ch
+}
+
+// next waits for a prefetch slot and a ready job and delivers it, or reports false once ctx is done or the
+// queue is closed. Redis errors are reported on errChan and retried after the poll interval.
+func (c *redisChannel) next(ctx context.Context, errChan chan<- error) (*Message, bool) {
+	select {
+	case <-ctx.Done():
+		return nil, false
+	case <-c.slots:
+	}
+	for ctx.Err() == nil && !c.q.isClosed() {
+		entry, claimed, err := c.fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			select {
+			case errChan <- err:
+			default:
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(c.q.pollInterval):
+			}
+			continue
+		}
+		if entry == nil {
+			continue
+		}
+		if msg := c.deliver(ctx, *entry, claimed); msg != nil {
+			return msg, true
+		}
+	}
+	c.slots <- struct{}{}
+	return nil, false
+}
+
+// fetch moves due delayed jobs to the stream, takes over a job another consumer left unsettled for too long,
+// and otherwise waits up to the poll interval for a new job. Once no job is left to take over, the next
+// check waits half the claim idle time.
+func (c *redisChannel) fetch(ctx context.Context) (*redisEntry, bool, error) {
+	if err := c.q.promote(ctx); err != nil {
+		return nil, false, err
+	}
+	if time.Since(c.lastClaim) >= c.q.claimIdle/2 {
+		msgs, _, err := c.q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   c.q.stream,
+			Group:    RedisQueueGroup,
+			Consumer: c.consumer,
+			MinIdle:  c.q.claimIdle,
+			Start:    "0-0",
+			Count:    1,
+		}).Result()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to claim idle jobs: %w", err)
+		}
+		if len(msgs) > 0 {
+			entry, err := c.parse(ctx, msgs[0], true)
+			return entry, true, err
+		}
+		c.lastClaim = time.Now()
+	}
+	entry, err := c.read(ctx, c.q.pollInterval)
+	return entry, false, err
+}
+
+// heartbeat resets the idle time of the jobs delivered to the consumer every third of the claim idle time,
+// so other consumers do not take over a job that is still running, until ctx is done or the queue is closed
+func (c *redisChannel) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(c.q.claimIdle / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.q.done:
+			return
+		case <-ticker.C:
+		}
+		c.mu.Lock()
+		ids := make([]string, 0, len(c.unacked))
+		for _, entry := range c.unacked {
+			ids = append(ids, entry.id)
+		}
+		c.mu.Unlock()
+		if len(ids) == 0 {
+			continue
+		}
+		// JUSTID claims do not count as deliveries
+		err := c.q.client.XClaimJustID(ctx, &redis.XClaimArgs{
+			Stream:   c.q.stream,
+			Group:    RedisQueueGroup,
+			Consumer: c.consumer,
+			Messages: ids,
+		}).Err()
+		if err != nil && ctx.Err() == nil {
+			c.q.logger.Warn("failed_to_heartbeat_jobs", zap.Int("jobs", len(ids)), zap.Error(err))
+		}
+	}
+}
+
+// read reads the next new stream entry for the consumer, waiting up to block (negative does not wait)
+func (c *redisChannel) read(ctx context.Context, block time.Duration) (*redisEntry, error) {
+	streams, err := c.q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    RedisQueueGroup,
+		Consumer: c.consumer,
+		Streams:  []string{c.q.stream, ">"},
+		Count:    1,
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job stream: %w", err)
+	}
+	if len(streams) == 0 || len(streams[0].Messages) == 0 {
+		return nil, nil
+	}
+	return c.parse(ctx, streams[0].Messages[0], false)
+}
+
+// parse reads a delivered stream entry, dropping entries without a job
+func (c *redisChannel) parse(ctx context.Context, msg redis.XMessage, claimed bool) (*redisEntry, error) {
+	entry, err := parseRedisEntry(msg, claimed)
+	if err != nil {
+		if settleErr := c.q.settle(ctx, entry, c.q.dlqKey); settleErr != nil {
+			return nil, settleErr
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// deliver records the entry as unacked and wraps it in a Message. Jobs past their NotAfter are dropped and
+// jobs not yet due go back to the delayed set; both return nil.
+func (c *redisChannel) deliver(ctx context.Context, entry redisEntry, claimed bool) *Message {
+	var job Job
+	if err := json.Unmarshal(entry.body, &job); err != nil {
+		if settleErr := c.q.settle(ctx, entry, c.q.dlqKey); settleErr != nil {
+			c.q.logger.Warn("failed_to_dead_letter_invalid_job", zap.String("entry_id", entry.id), zap.Error(settleErr))
+		}
+		return nil
+	}
+	if job.IsExpired() {
+		if err := c.q.settle(ctx, entry, ""); err != nil {
+			c.q.logger.Warn("failed_to_drop_expired_job", zap.String("job_id", job.ID.String()), zap.Error(err))
+		}
+		return nil
+	}
+	if !job.ShouldProcess() {
+		if err := c.q.delay(ctx, entry, *job.NotBefore); err != nil {
+			c.q.logger.Warn("failed_to_delay_job", zap.String("job_id", job.ID.String()), zap.Error(err))
+		}
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextTag++
+	c.unacked[c.nextTag] = entry
+	return &Message{
+		Job:         &job,
+		DeliveryTag: c.nextTag,
+		Channel:     c,
+		Redelivered: entry.redelivered,
+		logger:      c.q.logger,
+	}
+}
+
+// take removes the delivery from the unacked jobs and frees its prefetch slot
+func (c *redisChannel) take(tag uint64) (redisEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.unacked[tag]
+	if !ok {
+		return redisEntry{}, fmt.Errorf("unknown delivery tag %d", tag)
+	}
+	delete(c.unacked, tag)
+	if c.slots != nil {
+		c.slots <- struct{}{}
+	}
+	return entry, nil
+}
+
+// Ack implements DeliveryChannel
+func (c *redisChannel) Ack(tag uint64, multiple bool) error {
+	entry, err := c.take(tag)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisQueueOpTimeout)
+	defer cancel()
+	return c.q.settle(ctx, entry, "")
+}
+
+// Nack implements DeliveryChannel: the job is requeued, or dead-lettered when requeue is false
+func (c *redisChannel) Nack(tag uint64, multiple, requeue bool) error {
+	entry, err := c.take(tag)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisQueueOpTimeout)
+	defer cancel()
+	if requeue {
+		return c.q.requeue(ctx, entry)
+	}
+	return c.q.settle(ctx, entry, c.q.dlqKey)
+}
+
+// IsClosed implements DeliveryChannel
+func (c *redisChannel) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// close requeues the jobs still unsettled on the channel and removes its consumer from the group; later
+// acks and nacks of the requeued jobs are stale
+func (c *redisChannel) close() {
+	c.mu.Lock()
+	c.closed = true
+	unacked := c.unacked
+	c.unacked = make(map[uint64]redisEntry)
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisQueueOpTimeout)
+	defer cancel()
+	for _, entry := range unacked {
+		if err := c.q.requeue(ctx, entry); err != nil {
+			// Left pending without a heartbeat, the job is claimed by another consumer after the claim idle time
+			c.q.logger.Warn("failed_to_requeue_unsettled_job", zap.String("entry_id", entry.id), zap.Error(err))
+			return
+		}
+	}
+	_ = c.q.client.XGroupDelConsumer(ctx, c.q.stream, RedisQueueGroup, c.consumer).Err()
+}
+
+// settle acks and deletes the stream entry, pushing its job to the list at dlqKey unless dlqKey is empty
+func (q *RedisQueue) settle(ctx context.Context, entry redisEntry, dlqKey string) error {
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, q.stream, RedisQueueGroup, entry.id)
+		pipe.XDel(ctx, q.stream, entry.id)
+		if dlqKey != "" && entry.body != nil {
+			pipe.RPush(ctx, dlqKey, entry.body)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to settle job: %w", err)
+	}
+	return nil
+}
+
+// requeue replaces the stream entry with a new one at the end of the stream, marked as redelivered
+func (q *RedisQueue) requeue(ctx context.Context, entry redisEntry) error {
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, q.stream, RedisQueueGroup, entry.id)
+		pipe.XDel(ctx, q.stream, entry.id)
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: q.stream, Values: []any{redisJobField, entry.body, redisRedeliveredField, "1"}})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to requeue job: %w", err)
+	}
+	return nil
+}
+
+// delay moves the stream entry back to the delayed set until notBefore
+func (q *RedisQueue) delay(ctx context.Context, entry redisEntry, notBefore time.Time) error {
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, q.stream, RedisQueueGroup, entry.id)
+		pipe.XDel(ctx, q.stream, entry.id)
+		pipe.ZAdd(ctx, q.delayedKey, redis.Z{Score: float64(notBefore.UnixMilli()), Member: entry.body})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delay job: %w", err)
+	}
+	return nil
+}
+
+// Ensure RedisQueue implements the queue contracts and redisChannel settles its deliveries
+var (
+	_ JobQueue         = (*RedisQueue)(nil)
+	_ DLQPurger        = (*RedisQueue)(nil)
+	_ Inspector        = (*RedisQueue)(nil)
+	_ StatsReporter    = (*RedisQueue)(nil)
+	_ DLQStatsReporter = (*RedisQueue)(nil)
+	_ DeliveryChannel  = (*redisChannel)(nil)
+)
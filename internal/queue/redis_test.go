@@ -0,0 +1,815 @@
+package queue
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestParseRedisEntry(t *testing.T) {
+	t.Parallel()
+
+	body := `{"id":"` + uuid.NewString() + `"}`
+	tests := []struct {
+		name            string
+		msg             redis.XMessage
+		claimed         bool
+		wantErr         bool
+		wantRedelivered bool
+	}{
+		{name: "new entry", msg: redis.XMessage{ID: "1-0", Values: map[string]any{redisJobField: body}}},
+		{name: "requeued entry", msg: redis.XMessage{ID: "1-0", Values: map[string]any{redisJobField: body, redisRedeliveredField: "1"}}, wantRedelivered: true},
+		{name: "claimed from another consumer", msg: redis.XMessage{ID: "1-0", Values: map[string]any{redisJobField: body}}, claimed: true, wantRedelivered: true},
+		{name: "entry without a job", msg: redis.XMessage{ID: "1-0", Values: map[string]any{"other": "x"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			entry, err := parseRedisEntry(tt.msg, tt.claimed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRedisEntry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if entry.id != tt.msg.ID {
+				t.Errorf("id = %q, want %q", entry.id, tt.msg.ID)
+			}
+			if tt.wantErr {
+				return
+			}
+			if string(entry.body) != body || entry.redelivered != tt.wantRedelivered {
+				t.Errorf("entry = %q redelivered %v, want %q redelivered %v", entry.body, entry.redelivered, body, tt.wantRedelivered)
+			}
+		})
+	}
+}
+
+func TestDeadLetterCreatedBefore(t *testing.T) {
+	t.Parallel()
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	jobBody := func(createdAt time.Time) []byte {
+		job := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+		job.CreatedAt = createdAt
+		body, err := json.Marshal(job)
+		if err != nil {
+			t.Fatalf("marshal job: %v", err)
+		}
+		return body
+	}
+
+	tests := []struct {
+		name string
+		body []byte
+		want bool
+	}{
+		{name: "older job", body: jobBody(cutoff.Add(-time.Hour)), want: true},
+		{name: "newer job", body: jobBody(cutoff.Add(time.Hour)), want: false},
+		{name: "invalid body", body: []byte("not json"), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := deadLetterCreatedBefore(tt.body, cutoff); got != tt.want {
+				t.Errorf("deadLetterCreatedBefore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeRedis is an in-memory stand-in for the Redis commands RedisQueue uses: streams with consumer groups,
+// sorted sets, lists, and the queue's scripts run natively. Commands the queue does not use panic through
+// the nil embedded Cmdable.
+type fakeRedis struct {
+	redis.Cmdable
+
+	mu      sync.Mutex
+	seq     int64
+	streams map[string]*fakeStream
+	zsets   map[string]map[string]float64
+	lists   map[string][]string
+	// added is closed and replaced when an entry is added, waking blocked readers
+	added chan struct{}
+}
+
+type fakeStream struct {
+	entries []fakeStreamEntry
+	groups  map[string]*fakeGroup
+}
+
+type fakeStreamEntry struct {
+	seq    int64
+	values map[string]any
+}
+
+type fakeGroup struct {
+	lastDelivered int64
+	pending       map[int64]*fakePending
+	consumers     map[string]bool
+}
+
+type fakePending struct {
+	consumer    string
+	deliveredAt time.Time
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		streams: make(map[string]*fakeStream),
+		zsets:   make(map[string]map[string]float64),
+		lists:   make(map[string][]string),
+		added:   make(chan struct{}),
+	}
+}
+
+func fakeEntryID(seq int64) string { return strconv.FormatInt(seq, 10) + "-0" }
+
+func fakeEntrySeq(id string) int64 {
+	seq, _ := strconv.ParseInt(strings.TrimSuffix(id, "-0"), 10, 64)
+	return seq
+}
+
+// fakeString converts a command argument to the string Redis would store
+func fakeString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (f *fakeRedis) stream(key string) *fakeStream {
+	s, ok := f.streams[key]
+	if !ok {
+		s = &fakeStream{groups: make(map[string]*fakeGroup)}
+		f.streams[key] = s
+	}
+	return s
+}
+
+func (s *fakeStream) entry(seq int64) (fakeStreamEntry, bool) {
+	for _, e := range s.entries {
+		if e.seq == seq {
+			return e, true
+		}
+	}
+	return fakeStreamEntry{}, false
+}
+
+func (f *fakeRedis) xadd(key string, values []any) string {
+	f.seq++
+	entry := fakeStreamEntry{seq: f.seq, values: make(map[string]any)}
+	for i := 0; i+1 < len(values); i += 2 {
+		entry.values[fakeString(values[i])] = fakeString(values[i+1])
+	}
+	s := f.stream(key)
+	s.entries = append(s.entries, entry)
+	close(f.added)
+	f.added = make(chan struct{})
+	return fakeEntryID(entry.seq)
+}
+
+func (f *fakeRedis) Ping(ctx context.Context) *redis.StatusCmd {
+	return redis.NewStatusResult("PONG", nil)
+}
+
+func (f *fakeRedis) XGroupCreateMkStream(ctx context.Context, stream, group, start string) *redis.StatusCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.stream(stream)
+	if _, ok := s.groups[group]; ok {
+		return redis.NewStatusResult("", errors.New("BUSYGROUP Consumer Group name already exists"))
+	}
+	s.groups[group] = &fakeGroup{pending: make(map[int64]*fakePending), consumers: make(map[string]bool)}
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (f *fakeRedis) XGroupDelConsumer(ctx context.Context, stream, group, consumer string) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	g := f.stream(stream).groups[group]
+	delete(g.consumers, consumer)
+	var dropped int64
+	for seq, p := range g.pending {
+		if p.consumer == consumer {
+			delete(g.pending, seq)
+			dropped++
+		}
+	}
+	return redis.NewIntResult(dropped, nil)
+}
+
+func (f *fakeRedis) XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return redis.NewStringResult(f.xadd(a.Stream, a.Values.([]any)), nil)
+}
+
+func (f *fakeRedis) XReadGroup(ctx context.Context, a *redis.XReadGroupArgs) *redis.XStreamSliceCmd {
+	deadline := time.Now().Add(a.Block)
+	for {
+		f.mu.Lock()
+		s := f.stream(a.Streams[0])
+		g := s.groups[a.Group]
+		g.consumers[a.Consumer] = true
+		var msgs []redis.XMessage
+		for _, e := range s.entries {
+			if e.seq <= g.lastDelivered || int64(len(msgs)) == a.Count {
+				continue
+			}
+			g.lastDelivered = e.seq
+			g.pending[e.seq] = &fakePending{consumer: a.Consumer, deliveredAt: time.Now()}
+			msgs = append(msgs, redis.XMessage{ID: fakeEntryID(e.seq), Values: e.values})
+		}
+		added := f.added
+		f.mu.Unlock()
+		if len(msgs) > 0 {
+			return redis.NewXStreamSliceCmdResult([]redis.XStream{{Stream: a.Streams[0], Messages: msgs}}, nil)
+		}
+		wait := time.Until(deadline)
+		if a.Block < 0 || wait <= 0 {
+			return redis.NewXStreamSliceCmdResult(nil, redis.Nil)
+		}
+		select {
+		case <-ctx.Done():
+			return redis.NewXStreamSliceCmdResult(nil, ctx.Err())
+		case <-added:
+		case <-time.After(wait):
+		}
+	}
+}
+
+// claim moves the pending entries idle for at least minIdle to consumer
+func (f *fakeRedis) claim(stream, group, consumer string, minIdle time.Duration, seqs []int64) []redis.XMessage {
+	s := f.stream(stream)
+	g := s.groups[group]
+	var claimed []redis.XMessage
+	for _, seq := range seqs {
+		p, ok := g.pending[seq]
+		if !ok || time.Since(p.deliveredAt) < minIdle {
+			continue
+		}
+		e, ok := s.entry(seq)
+		if !ok {
+			delete(g.pending, seq)
+			continue
+		}
+		p.consumer, p.deliveredAt = consumer, time.Now()
+		claimed = append(claimed, redis.XMessage{ID: fakeEntryID(seq), Values: e.values})
+	}
+	return claimed
+}
+
+func (f *fakeRedis) XAutoClaim(ctx context.Context, a *redis.XAutoClaimArgs) *redis.XAutoClaimCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var seqs []int64
+	for seq := range f.stream(a.Stream).groups[a.Group].pending {
+		seqs = append(seqs, seq)
+	}
+	slices.Sort(seqs)
+	claimed := f.claim(a.Stream, a.Group, a.Consumer, a.MinIdle, seqs)
+	if int64(len(claimed)) > a.Count {
+		claimed = claimed[:a.Count]
+	}
+	cmd := redis.NewXAutoClaimCmd(ctx)
+	cmd.SetVal(claimed, "0-0")
+	return cmd
+}
+
+func (f *fakeRedis) XClaimJustID(ctx context.Context, a *redis.XClaimArgs) *redis.StringSliceCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	seqs := make([]int64, 0, len(a.Messages))
+	for _, id := range a.Messages {
+		seqs = append(seqs, fakeEntrySeq(id))
+	}
+	var ids []string
+	for _, msg := range f.claim(a.Stream, a.Group, a.Consumer, a.MinIdle, seqs) {
+		ids = append(ids, msg.ID)
+	}
+	return redis.NewStringSliceResult(ids, nil)
+}
+
+func (f *fakeRedis) XAck(ctx context.Context, stream, group string, ids ...string) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	g := f.stream(stream).groups[group]
+	var acked int64
+	for _, id := range ids {
+		if _, ok := g.pending[fakeEntrySeq(id)]; ok {
+			delete(g.pending, fakeEntrySeq(id))
+			acked++
+		}
+	}
+	return redis.NewIntResult(acked, nil)
+}
+
+func (f *fakeRedis) XDel(ctx context.Context, stream string, ids ...string) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.stream(stream)
+	var deleted int64
+	for _, id := range ids {
+		seq := fakeEntrySeq(id)
+		s.entries = slices.DeleteFunc(s.entries, func(e fakeStreamEntry) bool {
+			if e.seq == seq {
+				deleted++
+				return true
+			}
+			return false
+		})
+	}
+	return redis.NewIntResult(deleted, nil)
+}
+
+func (f *fakeRedis) XLen(ctx context.Context, stream string) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return redis.NewIntResult(int64(len(f.stream(stream).entries)), nil)
+}
+
+func (f *fakeRedis) XInfoGroups(ctx context.Context, stream string) *redis.XInfoGroupsCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var groups []redis.XInfoGroup
+	for name, g := range f.stream(stream).groups {
+		groups = append(groups, redis.XInfoGroup{Name: name, Consumers: int64(len(g.consumers)), Pending: int64(len(g.pending))})
+	}
+	cmd := redis.NewXInfoGroupsCmd(ctx, stream)
+	cmd.SetVal(groups)
+	return cmd
+}
+
+func (f *fakeRedis) ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	set, ok := f.zsets[key]
+	if !ok {
+		set = make(map[string]float64)
+		f.zsets[key] = set
+	}
+	for _, m := range members {
+		set[fakeString(m.Member)] = m.Score
+	}
+	return redis.NewIntResult(int64(len(members)), nil)
+}
+
+func (f *fakeRedis) RPush(ctx context.Context, key string, values ...any) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, v := range values {
+		f.lists[key] = append(f.lists[key], fakeString(v))
+	}
+	return redis.NewIntResult(int64(len(f.lists[key])), nil)
+}
+
+func (f *fakeRedis) LLen(ctx context.Context, key string) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return redis.NewIntResult(int64(len(f.lists[key])), nil)
+}
+
+func (f *fakeRedis) LRange(ctx context.Context, key string, start, stop int64) *redis.StringSliceCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	list := f.lists[key]
+	if stop < 0 || stop >= int64(len(list)) {
+		stop = int64(len(list)) - 1
+	}
+	if start > stop {
+		return redis.NewStringSliceResult([]string{}, nil)
+	}
+	return redis.NewStringSliceResult(slices.Clone(list[start:stop+1]), nil)
+}
+
+// EvalSha runs the queue's scripts natively
+func (f *fakeRedis) EvalSha(ctx context.Context, sha1 string, keys []string, args ...any) *redis.Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch sha1 {
+	case redisPromoteScript.Hash():
+		now, _ := strconv.ParseFloat(fakeString(args[0]), 64)
+		limit, _ := strconv.Atoi(fakeString(args[1]))
+		var due []string
+		for body, score := range f.zsets[keys[0]] {
+			if score <= now {
+				due = append(due, body)
+			}
+		}
+		slices.SortFunc(due, func(a, b string) int { return cmp.Compare(f.zsets[keys[0]][a], f.zsets[keys[0]][b]) })
+		if len(due) > limit {
+			due = due[:limit]
+		}
+		for _, body := range due {
+			f.xadd(keys[1], []any{redisJobField, body})
+			delete(f.zsets[keys[0]], body)
+		}
+		return redis.NewCmdResult(int64(len(due)), nil)
+	case redisTrimScript.Hash():
+		n, _ := strconv.Atoi(fakeString(args[0]))
+		list := f.lists[keys[0]]
+		if n > len(list) || list[n-1] != fakeString(args[1]) {
+			return redis.NewCmdResult(int64(0), nil)
+		}
+		f.lists[keys[0]] = list[n:]
+		return redis.NewCmdResult(int64(n), nil)
+	}
+	return redis.NewCmdResult(nil, errors.New("NOSCRIPT No matching script"))
+}
+
+// TxPipelined applies the queued commands in order once fn returns
+func (f *fakeRedis) TxPipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	pipe := &fakePipeline{}
+	if err := fn(pipe); err != nil {
+		return nil, err
+	}
+	for _, cmd := range pipe.cmds {
+		cmd(f)
+	}
+	return nil, nil
+}
+
+// fakePipeline queues the commands RedisQueue sends in transactions
+type fakePipeline struct {
+	redis.Pipeliner
+	cmds []func(*fakeRedis)
+}
+
+func (p *fakePipeline) XAck(ctx context.Context, stream, group string, ids ...string) *redis.IntCmd {
+	p.cmds = append(p.cmds, func(f *fakeRedis) { f.XAck(ctx, stream, group, ids...) })
+	return redis.NewIntCmd(ctx)
+}
+
+func (p *fakePipeline) XDel(ctx context.Context, stream string, ids ...string) *redis.IntCmd {
+	p.cmds = append(p.cmds, func(f *fakeRedis) { f.XDel(ctx, stream, ids...) })
+	return redis.NewIntCmd(ctx)
+}
+
+func (p *fakePipeline) XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd {
+	p.cmds = append(p.cmds, func(f *fakeRedis) { f.XAdd(ctx, a) })
+	return redis.NewStringCmd(ctx)
+}
+
+func (p *fakePipeline) ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd {
+	p.cmds = append(p.cmds, func(f *fakeRedis) { f.ZAdd(ctx, key, members...) })
+	return redis.NewIntCmd(ctx)
+}
+
+func (p *fakePipeline) RPush(ctx context.Context, key string, values ...any) *redis.IntCmd {
+	p.cmds = append(p.cmds, func(f *fakeRedis) { f.RPush(ctx, key, values...) })
+	return redis.NewIntCmd(ctx)
+}
+
+// newTestRedisQueue returns a queue on a fresh fakeRedis that polls and claims idle jobs quickly
+func newTestRedisQueue(t *testing.T, client *fakeRedis, claimIdle time.Duration) *RedisQueue {
+	t.Helper()
+	q, err := NewRedisQueue(context.Background(), client)
+	if err != nil {
+		t.Fatalf("NewRedisQueue() error = %v", err)
+	}
+	q.pollInterval = 20 * time.Millisecond
+	q.claimIdle = claimIdle
+	t.Cleanup(func() { _ = q.Close() })
+	return q
+}
+
+func TestRedisQueue_EnqueueConsume(t *testing.T) {
+	t.Parallel()
+
+	q := newTestRedisQueue(t, newFakeRedis(), RedisQueueClaimIdle)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	todoID := uuid.New()
+	job := NewDueReminderJob(uuid.New(), todoID, "chain", 2, time.Now().Add(-time.Second))
+	if err := q.Enqueue(ctx, job); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if stats, _ := q.QueueStats(ctx); stats.Depth != 1 {
+		t.Errorf("depth = %d, want 1", stats.Depth)
+	}
+	msgChan, _, err := q.Consume(ctx, 1)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	msg := receive(t, msgChan)
+	got := msg.GetJob()
+	if got.ID != job.ID || got.Type != JobTypeDueReminder || got.TodoID == nil || *got.TodoID != todoID {
+		t.Errorf("delivered job = %+v, want %+v", got, job)
+	}
+	if step, ok := got.Metadata[MetadataReminderStep].(float64); !ok || step != 2 {
+		t.Errorf("reminder step = %#v, want float64 2", got.Metadata[MetadataReminderStep])
+	}
+	if msg.IsRedelivered() {
+		t.Error("first delivery reported as redelivered")
+	}
+	if stats, _ := q.QueueStats(ctx); stats.Depth != 0 || stats.Consumers != 1 {
+		t.Errorf("stats while delivered = %+v, want depth 0 and 1 consumer", stats)
+	}
+	if err := msg.Ack(); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if err := msg.Ack(); err == nil {
+		t.Error("second Ack() error = nil, want unknown delivery tag")
+	}
+	if length, _ := q.client.XLen(ctx, q.stream).Result(); length != 0 {
+		t.Errorf("stream length after ack = %d, want 0", length)
+	}
+}
+
+func TestRedisQueue_NotBeforeAndNotAfter(t *testing.T) {
+	t.Parallel()
+
+	q := newTestRedisQueue(t, newFakeRedis(), RedisQueueClaimIdle)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	delayed := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+	notBefore := time.Now().Add(150 * time.Millisecond)
+	delayed.NotBefore = &notBefore
+	expired := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+	notAfter := time.Now().Add(-time.Second)
+	expired.NotAfter = &notAfter
+	for _, job := range []*Job{delayed, expired} {
+		if err := q.Enqueue(ctx, job); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+	if stats, _ := q.QueueStats(ctx); stats.Depth != 1 {
+		t.Errorf("depth = %d, want 1 (delayed jobs are not counted)", stats.Depth)
+	}
+
+	msgChan, _, err := q.Consume(ctx, 1)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	expectNone(t, msgChan, 50*time.Millisecond)
+	msg := receive(t, msgChan)
+	if msg.GetJob().ID != delayed.ID {
+		t.Errorf("delivered job %s, want the delayed job %s", msg.GetJob().ID, delayed.ID)
+	}
+	if time.Now().Before(notBefore) {
+		t.Errorf("delayed job delivered before its NotBefore")
+	}
+	if depth, _ := q.DLQDepth(ctx); depth != 0 {
+		t.Errorf("DLQ depth = %d, want the expired job dropped", depth)
+	}
+}
+
+func TestRedisQueue_Nack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		requeue     bool
+		wantDLQ     int
+		wantRequeue bool
+	}{
+		{name: "requeue redelivers", requeue: true, wantRequeue: true},
+		{name: "reject dead-letters", requeue: false, wantDLQ: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			q := newTestRedisQueue(t, newFakeRedis(), RedisQueueClaimIdle)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			job := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+			if err := q.Enqueue(ctx, job); err != nil {
+				t.Fatalf("Enqueue() error = %v", err)
+			}
+			msgChan, _, err := q.Consume(ctx, 1)
+			if err != nil {
+				t.Fatalf("Consume() error = %v", err)
+			}
+			if err := receive(t, msgChan).Nack(tt.requeue); err != nil {
+				t.Fatalf("Nack() error = %v", err)
+			}
+
+			if depth, _ := q.DLQDepth(ctx); depth != tt.wantDLQ {
+				t.Errorf("DLQ depth = %d, want %d", depth, tt.wantDLQ)
+			}
+			if !tt.wantRequeue {
+				expectNone(t, msgChan, 50*time.Millisecond)
+				return
+			}
+			msg := receive(t, msgChan)
+			if msg.GetJob().ID != job.ID || !msg.IsRedelivered() {
+				t.Errorf("redelivered job %s (redelivered %v), want %s redelivered", msg.GetJob().ID, msg.IsRedelivered(), job.ID)
+			}
+		})
+	}
+}
+
+func TestRedisQueue_Prefetch(t *testing.T) {
+	t.Parallel()
+
+	q := newTestRedisQueue(t, newFakeRedis(), RedisQueueClaimIdle)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for range 3 {
+		if err := q.Enqueue(ctx, NewJob(JobTypeTaskAnalysis, uuid.New(), nil)); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+	msgChan, _, err := q.Consume(ctx, 2)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	first := receive(t, msgChan)
+	receive(t, msgChan)
+	expectNone(t, msgChan, 50*time.Millisecond)
+
+	if err := first.Ack(); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	receive(t, msgChan)
+}
+
+func TestRedisQueue_StoppedConsumerRequeues(t *testing.T) {
+	t.Parallel()
+
+	q := newTestRedisQueue(t, newFakeRedis(), RedisQueueClaimIdle)
+	job := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+	if err := q.Enqueue(context.Background(), job); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	msgChan, _, err := q.Consume(ctx, 1)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	stale := receive(t, msgChan)
+	cancel()
+	for range msgChan {
+	}
+	// Settling a delivery of a stopped consumer is skipped, as for a closed broker channel
+	if err := stale.Ack(); err != nil {
+		t.Fatalf("stale Ack() error = %v", err)
+	}
+
+	msg, err := q.Dequeue(context.Background())
+	if err != nil || msg == nil {
+		t.Fatalf("Dequeue() = %v, %v; want the requeued job", msg, err)
+	}
+	if msg.GetJob().ID != job.ID || !msg.IsRedelivered() {
+		t.Errorf("dequeued job %s (redelivered %v), want %s redelivered", msg.GetJob().ID, msg.IsRedelivered(), job.ID)
+	}
+}
+
+func TestRedisQueue_ClaimIdle(t *testing.T) {
+	t.Parallel()
+
+	const claimIdle = 100 * time.Millisecond
+	client := newFakeRedis()
+	q := newTestRedisQueue(t, client, claimIdle)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	running := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+	if err := q.Enqueue(ctx, running); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	holder, _, err := q.Consume(ctx, 1)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	held := receive(t, holder)
+
+	// A crashed worker read this job and never settled it or heartbeat it
+	abandoned := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+	if err := q.Enqueue(ctx, abandoned); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if _, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{Group: RedisQueueGroup, Consumer: "crashed", Streams: []string{q.stream, ">"}, Count: 1, Block: -1}).Result(); err != nil {
+		t.Fatalf("XReadGroup() error = %v", err)
+	}
+
+	other, _, err := q.Consume(ctx, 2)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	msg := receive(t, other)
+	if msg.GetJob().ID != abandoned.ID || !msg.IsRedelivered() {
+		t.Errorf("claimed job %s (redelivered %v), want the abandoned job %s redelivered", msg.GetJob().ID, msg.IsRedelivered(), abandoned.ID)
+	}
+	// The job still running past the claim idle time is heartbeat, so it is not taken over
+	expectNone(t, other, 4*claimIdle)
+	if err := held.Ack(); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+}
+
+func TestRedisQueue_InvalidEntryDeadLettered(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeRedis()
+	q := newTestRedisQueue(t, client, RedisQueueClaimIdle)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client.XAdd(ctx, &redis.XAddArgs{Stream: q.stream, Values: []any{redisJobField, "not json"}})
+	client.XAdd(ctx, &redis.XAddArgs{Stream: q.stream, Values: []any{"other", "x"}})
+	job := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+	if err := q.Enqueue(ctx, job); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	msgChan, _, err := q.Consume(ctx, 1)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if msg := receive(t, msgChan); msg.GetJob().ID != job.ID {
+		t.Errorf("delivered job %s, want %s", msg.GetJob().ID, job.ID)
+	}
+	// The invalid job is dead-lettered; the entry without one has nothing to keep
+	if depth, _ := q.DLQDepth(ctx); depth != 1 {
+		t.Errorf("DLQ depth = %d, want the invalid job", depth)
+	}
+	if length, _ := client.XLen(ctx, q.stream).Result(); length != 1 {
+		t.Errorf("stream length = %d, want only the delivered job", length)
+	}
+}
+
+func TestRedisQueue_PurgeOlderThan(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeRedis()
+	q := newTestRedisQueue(t, client, RedisQueueClaimIdle)
+	ctx := context.Background()
+
+	// More old jobs than a page, then a recent one and an old one behind it
+	var bodies []any
+	deadLetter := func(createdAt time.Time) {
+		job := NewJob(JobTypeTaskAnalysis, uuid.New(), nil)
+		job.CreatedAt = createdAt
+		body, err := json.Marshal(job)
+		if err != nil {
+			t.Fatalf("marshal job: %v", err)
+		}
+		bodies = append(bodies, body)
+	}
+	for range redisDLQPurgePage + 5 {
+		deadLetter(time.Now().Add(-48 * time.Hour))
+	}
+	deadLetter(time.Now())
+	deadLetter(time.Now().Add(-48 * time.Hour))
+	client.RPush(ctx, q.dlqKey, bodies...)
+
+	purged, err := q.PurgeOlderThan(ctx, 24*time.Hour)
+	if err != nil || purged != redisDLQPurgePage+5 {
+		t.Fatalf("PurgeOlderThan() = %d, %v; want %d", purged, err, redisDLQPurgePage+5)
+	}
+	if stats, _ := q.Stats(ctx); stats.DLQ.Depth != 2 {
+		t.Errorf("DLQ depth after purge = %d, want 2", stats.DLQ.Depth)
+	}
+	if purged, err := q.PurgeOlderThan(ctx, 24*time.Hour); err != nil || purged != 0 {
+		t.Errorf("second PurgeOlderThan() = %d, %v; want nothing left to purge ahead of the recent job", purged, err)
+	}
+}
+
+func TestRedisQueue_Close(t *testing.T) {
+	t.Parallel()
+
+	q := newTestRedisQueue(t, newFakeRedis(), RedisQueueClaimIdle)
+	msgChan, _, err := q.Consume(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	select {
+	case _, ok := <-msgChan:
+		if ok {
+			t.Error("message delivered after Close")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("consumer did not stop after Close")
+	}
+	if err := q.Enqueue(context.Background(), NewJob(JobTypeTaskAnalysis, uuid.New(), nil)); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Enqueue() after Close error = %v, want ErrQueueClosed", err)
+	}
+	if err := q.HealthCheck(context.Background()); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("HealthCheck() after Close error = %v, want ErrQueueClosed", err)
+	}
+}
@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy is how often and how quickly a failed job is retried
+type RetryPolicy struct {
+	// MaxRetries is how many times the job is retried before it is dead-lettered
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry; it doubles with each further attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy applies to job types without a policy of their own
+var DefaultRetryPolicy = RetryPolicy{MaxRetries: 3, BaseDelay: 5 * time.Second, MaxDelay: 5 * time.Minute}
+
+// Delay returns the backoff before retrying a job that has already been retried attempt times
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// RetryPolicies maps job types to their retry policy
+type RetryPolicies map[JobType]RetryPolicy
+
+// For returns the policy of jobType, or DefaultRetryPolicy when it has none
+func (p RetryPolicies) For(jobType JobType) RetryPolicy {
+	if policy, ok := p[jobType]; ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
+
+// ParseRetryPolicies parses "job_type=max_retries/base_delay/max_delay" entries (e.g. "tag_analysis=5/1s/30s")
+// into retry policies; job types without an entry use DefaultRetryPolicy. Unknown job types are an error, so a
+// misspelled type does not silently leave the default in place.
+func ParseRetryPolicies(entries []string) (RetryPolicies, error) {
+	policies := make(RetryPolicies, len(entries))
+	for _, entry := range entries {
+		jobType, spec, ok := strings.Cut(entry, "=")
+		parts := strings.Split(spec, "/")
+		jobType = strings.TrimSpace(jobType)
+		if !ok || jobType == "" || len(parts) != 3 {
+			return nil, fmt.Errorf("invalid retry policy %q (expected job_type=max_retries/base_delay/max_delay)", entry)
+		}
+		if !JobType(jobType).Known() {
+			return nil, fmt.Errorf("invalid retry policy %q: unknown job type %q", entry, jobType)
+		}
+		maxRetries, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || maxRetries < 0 {
+			return nil, fmt.Errorf("invalid retry policy %q: max retries must be a non-negative integer", entry)
+		}
+		baseDelay, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || baseDelay <= 0 {
+			return nil, fmt.Errorf("invalid retry policy %q: base delay must be a positive duration", entry)
+		}
+		maxDelay, err := time.ParseDuration(strings.TrimSpace(parts[2]))
+		if err != nil || maxDelay < baseDelay {
+			return nil, fmt.Errorf("invalid retry policy %q: max delay must be a duration of at least the base delay", entry)
+		}
+		policies[JobType(jobType)] = RetryPolicy{MaxRetries: maxRetries, BaseDelay: baseDelay, MaxDelay: maxDelay}
+	}
+	return policies, nil
+}
@@ -0,0 +1,94 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Second},
+		{attempt: 1, want: 2 * time.Second},
+		{attempt: 3, want: 8 * time.Second},
+		{attempt: 4, want: 10 * time.Second},
+		{attempt: 100, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Delay(tt.attempt); got != tt.want {
+			t.Errorf("Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicies_For(t *testing.T) {
+	t.Parallel()
+
+	tagPolicy := RetryPolicy{MaxRetries: 8, BaseDelay: time.Second, MaxDelay: time.Minute}
+	policies := RetryPolicies{JobTypeTagAnalysis: tagPolicy}
+
+	if got := policies.For(JobTypeTagAnalysis); got != tagPolicy {
+		t.Errorf("For(tag_analysis) = %+v, want %+v", got, tagPolicy)
+	}
+	if got := policies.For(JobTypeTaskAnalysis); got != DefaultRetryPolicy {
+		t.Errorf("For(task_analysis) = %+v, want the default %+v", got, DefaultRetryPolicy)
+	}
+	if got := RetryPolicies(nil).For(JobTypeTaskAnalysis); got != DefaultRetryPolicy {
+		t.Errorf("nil policies For() = %+v, want the default %+v", got, DefaultRetryPolicy)
+	}
+}
+
+func TestParseRetryPolicies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		entries []string
+		want    RetryPolicies
+		wantErr bool
+	}{
+		{name: "empty", want: RetryPolicies{}},
+		{
+			name:    "per job type",
+			entries: []string{"task_analysis=5/30s/30m", " tag_analysis = 2/1s/10s "},
+			want: RetryPolicies{
+				JobTypeTaskAnalysis: {MaxRetries: 5, BaseDelay: 30 * time.Second, MaxDelay: 30 * time.Minute},
+				JobTypeTagAnalysis:  {MaxRetries: 2, BaseDelay: time.Second, MaxDelay: 10 * time.Second},
+			},
+		},
+		{name: "no retries", entries: []string{"due_reminder=0/1s/1s"}, want: RetryPolicies{JobTypeDueReminder: {BaseDelay: time.Second, MaxDelay: time.Second}}},
+		{name: "missing job type", entries: []string{"=3/1s/1m"}, wantErr: true},
+		{name: "unknown job type", entries: []string{"tag_analyses=3/1s/1m"}, wantErr: true},
+		{name: "missing max delay", entries: []string{"task_analysis=3/1s"}, wantErr: true},
+		{name: "negative retries", entries: []string{"task_analysis=-1/1s/1m"}, wantErr: true},
+		{name: "zero base delay", entries: []string{"task_analysis=3/0s/1m"}, wantErr: true},
+		{name: "max delay below base delay", entries: []string{"task_analysis=3/1m/1s"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseRetryPolicies(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRetryPolicies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseRetryPolicies() = %+v, want %+v", got, tt.want)
+			}
+			for jobType, policy := range tt.want {
+				if got[jobType] != policy {
+					t.Errorf("policy for %s = %+v, want %+v", jobType, got[jobType], policy)
+				}
+			}
+		})
+	}
+}
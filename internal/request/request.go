@@ -2,40 +2,156 @@ package request
 
 import (
 	"context"
+	"crypto/subtle"
+	"net"
 	"net/http"
+	"net/netip"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
 )
 
 type contextKey string
 
-const userContextKey contextKey = "user"
+const (
+	userContextKey           contextKey = "user"
+	authenticatedUserSlotKey contextKey = "authenticated_user_slot"
+	apiKeyIDContextKey       contextKey = "api_key_id"
+)
+
+// AdminTokenHeader is the request header carrying the operator admin token.
+const AdminTokenHeader = "X-Admin-Token"
 
 // UserContextKey returns the context key used for the user. Exposed for tests that inject non-user values.
 func UserContextKey() contextKey { return userContextKey }
 
-// ClientIP extracts the client IP from the request, respecting X-Forwarded-For and X-Real-IP.
+// trustedProxies holds the proxy address ranges whose forwarded headers ClientIP honors
+var trustedProxies atomic.Pointer[[]netip.Prefix]
+
+// SetTrustedProxies sets the address ranges of the reverse proxies in front of the API. ClientIP honors
+// X-Forwarded-For and X-Real-IP only on connections from these ranges; by default no proxy is trusted.
+// It should be called once at startup before serving requests.
+func SetTrustedProxies(prefixes []netip.Prefix) {
+	trustedProxies.Store(&prefixes)
+}
+
+// ClientIP returns the client IP of the request. Forwarded headers are only honored when the connection comes
+// from a trusted proxy (see SetTrustedProxies), so clients cannot spoof their address to evade rate limits;
+// otherwise the host of RemoteAddr is used.
 func ClientIP(r *http.Request) string {
+	var trusted []netip.Prefix
+	if p := trustedProxies.Load(); p != nil {
+		trusted = *p
+	}
+	return clientIP(r, trusted)
+}
+
+// clientIP resolves the client IP given the trusted proxy ranges. X-Forwarded-For is walked from the nearest
+// hop back, skipping trusted proxies, so addresses a client prepends itself are never used.
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	remoteAddr, err := netip.ParseAddr(remote)
+	if err != nil || !isTrustedProxy(remoteAddr, trusted) {
+		return remote
+	}
+
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		parts := strings.Split(xff, ",")
-		if len(parts) > 0 {
-			return strings.TrimSpace(parts[0])
+		client := remote
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			client = hop.String()
+			if !isTrustedProxy(hop, trusted) {
+				break
+			}
 		}
+		return client
+	}
+	if xri, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return xri.String()
 	}
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return strings.TrimSpace(xri)
+	return remote
+}
+
+// isTrustedProxy reports whether addr falls in one of the trusted ranges
+func isTrustedProxy(addr netip.Addr, trusted []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
 	}
-	return r.RemoteAddr
+	return false
 }
 
-// WithUser returns a context with the user attached.
+// WithUser returns a context with the user attached. The user is also reported to middleware that tracks the
+// authenticated user (see TrackAuthenticatedUser).
 func WithUser(ctx context.Context, user *models.User) context.Context {
+	if slot, ok := ctx.Value(authenticatedUserSlotKey).(*authenticatedUserSlot); ok {
+		slot.set(user)
+	}
 	return context.WithValue(ctx, userContextKey, user)
 }
 
+// authenticatedUserSlot receives the user authentication attaches further down the handler chain
+type authenticatedUserSlot struct {
+	mu   sync.Mutex
+	user *models.User
+}
+
+func (s *authenticatedUserSlot) set(user *models.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = user
+}
+
+func (s *authenticatedUserSlot) get() *models.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.user
+}
+
+// TrackAuthenticatedUser returns r with a context that records the user authentication attaches while
+// serving it, and a function returning that user (nil if the request was not authenticated). Middleware
+// wrapping the authenticated routes uses it, as the user is only in the context of inner handlers.
+func TrackAuthenticatedUser(r *http.Request) (*http.Request, func() *models.User) {
+	slot := &authenticatedUserSlot{}
+	return r.WithContext(context.WithValue(r.Context(), authenticatedUserSlotKey, slot)), slot.get
+}
+
 // UserFromContext returns the user from the request context, or nil if missing or wrong type.
 func UserFromContext(r *http.Request) *models.User {
 	u, _ := r.Context().Value(userContextKey).(*models.User)
 	return u
 }
+
+// WithAPIKeyID returns a context recording that the request was authenticated with the API key id
+func WithAPIKeyID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, apiKeyIDContextKey, id)
+}
+
+// APIKeyIDFromContext returns the API key the request was authenticated with, or false when it was
+// authenticated otherwise
+func APIKeyIDFromContext(r *http.Request) (uuid.UUID, bool) {
+	id, ok := r.Context().Value(apiKeyIDContextKey).(uuid.UUID)
+	return id, ok
+}
+
+// HasAdminToken reports whether the request carries the configured admin token.
+// Always false when no admin token is configured.
+func HasAdminToken(r *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	provided := r.Header.Get(AdminTokenHeader)
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
@@ -3,6 +3,7 @@ package request
 import (
 	"context"
 	"net/http/httptest"
+	"net/netip"
 	"testing"
 
 	"github.com/benvon/smart-todo/internal/models"
@@ -17,11 +18,11 @@ func TestClientIP(t *testing.T) {
 		remote  string
 		wantIP  string
 	}{
-		{"x-forwarded-for", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "", "1.2.3.4"},
-		{"x-forwarded-for first", map[string]string{"X-Forwarded-For": " 1.2.3.4 , 5.6.7.8 "}, "", "1.2.3.4"},
-		{"x-real-ip", map[string]string{"X-Real-IP": "9.9.9.9"}, "", "9.9.9.9"},
-		{"remote addr", nil, "10.0.0.1:12345", "10.0.0.1:12345"},
-		{"xff over xri", map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Real-IP": "9.9.9.9"}, "", "1.2.3.4"},
+		{"remote addr without port", nil, "10.0.0.1:12345", "10.0.0.1"},
+		{"ipv6 remote addr", nil, "[2001:db8::1]:443", "2001:db8::1"},
+		{"remote addr without a port", nil, "10.0.0.1", "10.0.0.1"},
+		{"x-forwarded-for ignored by default", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "10.0.0.1:12345", "10.0.0.1"},
+		{"x-real-ip ignored by default", map[string]string{"X-Real-IP": "9.9.9.9"}, "10.0.0.1:12345", "10.0.0.1"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -30,9 +31,7 @@ func TestClientIP(t *testing.T) {
 			for k, v := range tt.headers {
 				r.Header.Set(k, v)
 			}
-			if tt.remote != "" {
-				r.RemoteAddr = tt.remote
-			}
+			r.RemoteAddr = tt.remote
 			got := ClientIP(r)
 			if got != tt.wantIP {
 				t.Errorf("ClientIP() = %q, want %q", got, tt.wantIP)
@@ -41,6 +40,43 @@ func TestClientIP(t *testing.T) {
 	}
 }
 
+func TestClientIP_TrustedProxies(t *testing.T) {
+	t.Parallel()
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")}
+	tests := []struct {
+		name    string
+		headers map[string]string
+		remote  string
+		wantIP  string
+	}{
+		{"x-forwarded-for from trusted proxy", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "10.0.0.1:12345", "1.2.3.4"},
+		{"spoofed hops before the client are skipped", map[string]string{"X-Forwarded-For": " 6.6.6.6 , 1.2.3.4 "}, "10.0.0.1:12345", "1.2.3.4"},
+		{"chained trusted proxies", map[string]string{"X-Forwarded-For": "1.2.3.4, 10.1.1.1, 10.2.2.2"}, "10.0.0.1:12345", "1.2.3.4"},
+		{"all hops trusted", map[string]string{"X-Forwarded-For": "10.1.1.1, 10.2.2.2"}, "10.0.0.1:12345", "10.1.1.1"},
+		{"invalid hop stops the walk", map[string]string{"X-Forwarded-For": "garbage, 10.1.1.1"}, "10.0.0.1:12345", "10.1.1.1"},
+		{"x-real-ip from trusted proxy", map[string]string{"X-Real-IP": "9.9.9.9"}, "10.0.0.1:12345", "9.9.9.9"},
+		{"xff over xri", map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Real-IP": "9.9.9.9"}, "10.0.0.1:12345", "1.2.3.4"},
+		{"invalid x-real-ip", map[string]string{"X-Real-IP": "not-an-ip"}, "10.0.0.1:12345", "10.0.0.1"},
+		{"ipv6 trusted proxy", map[string]string{"X-Forwarded-For": "2001:db8::7"}, "[fd00::1]:443", "2001:db8::7"},
+		{"untrusted remote ignores x-forwarded-for", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "203.0.113.5:12345", "203.0.113.5"},
+		{"untrusted remote ignores x-real-ip", map[string]string{"X-Real-IP": "9.9.9.9"}, "203.0.113.5:12345", "203.0.113.5"},
+		{"no headers from trusted proxy", nil, "10.0.0.1:12345", "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			r.RemoteAddr = tt.remote
+			if got := clientIP(r, trusted); got != tt.wantIP {
+				t.Errorf("clientIP() = %q, want %q", got, tt.wantIP)
+			}
+		})
+	}
+}
+
 func TestUserFromContext(t *testing.T) {
 	t.Parallel()
 	u := &models.User{ID: uuid.New(), Email: "a@b.c"}
@@ -73,3 +109,49 @@ func TestUserFromContext_WrongType(t *testing.T) {
 		t.Errorf("UserFromContext() = %+v, want nil when wrong type", got)
 	}
 }
+
+func TestHasAdminToken(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		configured string
+		header     string
+		want       bool
+	}{
+		{"matching token", "s3cret", "s3cret", true},
+		{"wrong token", "s3cret", "guess", false},
+		{"missing header", "s3cret", "", false},
+		{"admin disabled", "", "", false},
+		{"admin disabled ignores header", "", "anything", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				r.Header.Set(AdminTokenHeader, tt.header)
+			}
+			if got := HasAdminToken(r, tt.configured); got != tt.want {
+				t.Errorf("HasAdminToken() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackAuthenticatedUser(t *testing.T) {
+	t.Parallel()
+	u := &models.User{ID: uuid.New()}
+	r, authenticated := TrackAuthenticatedUser(httptest.NewRequest("GET", "/", nil))
+	if got := authenticated(); got != nil {
+		t.Errorf("authenticated() before authentication = %+v, want nil", got)
+	}
+
+	// Authentication attaches the user to a derived request, as the auth middleware does
+	inner := r.WithContext(WithUser(r.Context(), u))
+	if got := UserFromContext(inner); got != u {
+		t.Errorf("UserFromContext() = %p, want %p", got, u)
+	}
+	if got := authenticated(); got != u {
+		t.Errorf("authenticated() = %p, want %p", got, u)
+	}
+}
@@ -0,0 +1,247 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultAnthropicModel is the default Anthropic model to use
+	DefaultAnthropicModel = "claude-3-5-haiku-latest"
+	// DefaultAnthropicBaseURL is the default Anthropic API base URL
+	DefaultAnthropicBaseURL = "https://api.anthropic.com"
+	// AnthropicAPIVersion is the Messages API version sent in the anthropic-version header
+	AnthropicAPIVersion = "2023-06-01"
+
+	// ErrNoContentInResponse is returned when a Messages API response has no text content
+	ErrNoContentInResponse = "no content in response"
+
+	// anthropicAnalysisMaxTokens, anthropicChatMaxTokens, anthropicSummaryMaxTokens and
+	// anthropicWeeklySummaryMaxTokens cap the response length of each call; the Messages API requires a cap
+	anthropicAnalysisMaxTokens      = 1024
+	anthropicChatMaxTokens          = 1024
+	anthropicSummaryMaxTokens       = 500
+	anthropicWeeklySummaryMaxTokens = 300
+
+	// anthropicJSONPrefill starts the assistant turn of analysis requests so the model continues with a JSON
+	// object; the Messages API has no JSON response format
+	anthropicJSONPrefill = "{"
+)
+
+// AnthropicProvider implements the AIProvider interface using Anthropic's Messages API. Analysis uses the
+// same prompt as the OpenAI provider.
+type AnthropicProvider struct {
+	providerBase
+	apiKey  string
+	baseURL string
+}
+
+// anthropicMessage is one turn of a Messages API conversation
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// anthropicRequest is a Messages API request body
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+}
+
+// anthropicResponse is the part of a Messages API response the provider reads
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int64 `json:"input_tokens"`
+		OutputTokens int64 `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// content returns the concatenated text blocks of the response
+func (r *anthropicResponse) content() string {
+	var b strings.Builder
+	for _, block := range r.Content {
+		if block.Type == "text" {
+			b.WriteString(block.Text)
+		}
+	}
+	return b.String()
+}
+
+func (r *anthropicResponse) usage() (promptTokens, completionTokens int64) {
+	return r.Usage.InputTokens, r.Usage.OutputTokens
+}
+
+// NewAnthropicProvider creates a new Anthropic provider
+func NewAnthropicProvider(apiKey string, model string) *AnthropicProvider {
+	return NewAnthropicProviderWithLogger(apiKey, DefaultAnthropicBaseURL, model, nil, false, 0)
+}
+
+// NewAnthropicProviderWithLogger creates a new Anthropic provider with logger support. timeout bounds each
+// API call; 0 uses DefaultTimeout.
+func NewAnthropicProviderWithLogger(apiKey string, baseURL string, model string, logger *zap.Logger, debugMode bool, timeout time.Duration) *AnthropicProvider {
+	if model == "" {
+		model = DefaultAnthropicModel
+	}
+	if baseURL == "" {
+		baseURL = DefaultAnthropicBaseURL
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &AnthropicProvider{
+		providerBase: newProviderBase(anthropicProviderName, model, timeout, logger, debugMode),
+		apiKey:       apiKey,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// AnalyzeTask analyzes a task and returns suggested tags and time horizon
+func (p *AnthropicProvider) AnalyzeTask(ctx context.Context, text string, userContext *models.AIContext) ([]string, models.TimeHorizon, error) {
+	return p.AnalyzeTaskWithDueDate(ctx, text, nil, time.Now(), userContext, nil)
+}
+
+// AnalyzeTaskWithDueDate analyzes a task with an optional due date and creation time, returns suggested tags and time horizon.
+// tagStats is optional tag statistics to guide tag selection (prefer existing tags).
+func (p *AnthropicProvider) AnalyzeTaskWithDueDate(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
+	prompt := p.buildAnalysisPrompt(text, dueDate, dueDateOnlyFromContext(ctx), priorityFromContext(ctx), createdAt, userContext, tagStats)
+	req := anthropicRequest{
+		Model:     p.model,
+		MaxTokens: anthropicAnalysisMaxTokens,
+		System:    analysisSystemPrompt,
+		Messages: []anthropicMessage{
+			{Role: "user", Content: prompt},
+			{Role: "assistant", Content: anthropicJSONPrefill},
+		},
+	}
+	content, err := p.send(ctx, "analyze_task", req, prompt)
+	if err != nil {
+		return nil, models.TimeHorizonSoon, fmt.Errorf("failed to analyze task: %w", err)
+	}
+	// The response continues the prefilled "{" unless the model repeated it
+	if content = strings.TrimSpace(content); !strings.HasPrefix(content, anthropicJSONPrefill) {
+		content = anthropicJSONPrefill + content
+	}
+	tags, th, missing, err := parseAndValidateAnalysisResponse(content)
+	if err != nil {
+		return nil, models.TimeHorizonSoon, err
+	}
+	p.warnIncompleteAnalysis(ctx, missing)
+	return tags, th, nil
+}
+
+// Chat handles a chat message and returns the AI response
+func (p *AnthropicProvider) Chat(ctx context.Context, messages []ChatMessage, userContext *models.AIContext) (*ChatResponse, error) {
+	req := anthropicRequest{
+		Model:     p.model,
+		MaxTokens: anthropicChatMaxTokens,
+		System:    buildChatSystemContent(userContext),
+		Messages:  chatMessagesToAnthropic(messages),
+	}
+	content, err := p.send(ctx, "chat", req, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to chat: %w", err)
+	}
+	return &ChatResponse{Message: content, NeedsUpdate: true}, nil
+}
+
+// chatMessagesToAnthropic converts chat history to Messages API turns. Unknown roles are sent as user turns
+// and consecutive turns of the same role are merged, since the API requires alternating roles.
+func chatMessagesToAnthropic(messages []ChatMessage) []anthropicMessage {
+	out := make([]anthropicMessage, 0, len(messages))
+	for _, msg := range messages {
+		role := "user"
+		if msg.Role == "assistant" {
+			role = "assistant"
+		}
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Content += "\n\n" + msg.Content
+			continue
+		}
+		out = append(out, anthropicMessage{Role: role, Content: msg.Content})
+	}
+	return out
+}
+
+// SummarizeContext summarizes a conversation history into a context summary
+func (p *AnthropicProvider) SummarizeContext(ctx context.Context, conversationHistory []ChatMessage) (string, error) {
+	prompt := buildSummaryPrompt(conversationHistory)
+	req := anthropicRequest{
+		Model:     p.model,
+		MaxTokens: anthropicSummaryMaxTokens,
+		System:    "You are a helpful assistant that creates concise summaries of conversations. Focus on extracting user preferences and patterns.",
+		Messages:  []anthropicMessage{{Role: "user", Content: prompt}},
+	}
+	content, err := p.send(ctx, "summarize_context", req, prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize context: %w", err)
+	}
+	return content, nil
+}
+
+// SummarizeWeek writes a short summary of what the user completed last week and what is still open
+func (p *AnthropicProvider) SummarizeWeek(ctx context.Context, completed, pending []string) (string, error) {
+	prompt := buildWeeklySummaryPrompt(completed, pending)
+	req := anthropicRequest{
+		Model:     p.model,
+		MaxTokens: anthropicWeeklySummaryMaxTokens,
+		System:    "You are a helpful assistant that writes brief, encouraging weekly summaries of a user's todo list. Use plain text, no more than a short paragraph.",
+		Messages:  []anthropicMessage{{Role: "user", Content: prompt}},
+	}
+	content, err := p.send(ctx, "summarize_week", req, prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize week: %w", err)
+	}
+	return content, nil
+}
+
+// send posts req to the Messages API and returns the response text. API errors are mapped into a
+// ProviderError.
+func (p *AnthropicProvider) send(ctx context.Context, operation string, req anthropicRequest, prompt string) (string, error) {
+	return sendJSON(ctx, &p.providerBase, jsonCall{
+		operation:    operation,
+		prompt:       prompt,
+		messageCount: len(req.Messages),
+		url:          p.baseURL + "/v1/messages",
+		header: http.Header{
+			"X-Api-Key":         {p.apiKey},
+			"Anthropic-Version": {AnthropicAPIVersion},
+		},
+		body:           req,
+		transportError: mapAnthropicTransportError,
+		responseError:  func(resp *http.Response, body []byte) error { return anthropicResponseError(resp, body) },
+	}, &anthropicResponse{})
+}
+
+// RegisterAnthropic registers the Anthropic provider with the registry
+func RegisterAnthropic(registry *ProviderRegistry) {
+	registry.Register(anthropicProviderName, func(config map[string]string) (AIProvider, error) {
+		apiKey, ok := config["api_key"]
+		if !ok || apiKey == "" {
+			return nil, fmt.Errorf("anthropic api_key is required")
+		}
+
+		provider := NewAnthropicProviderWithLogger(apiKey, config["base_url"], config["model"], nil, false, 0)
+		if err := provider.configure(config); err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
+
+// Ensure AnthropicProvider implements the optional provider interfaces
+var (
+	_ AIProviderWithDueDate = (*AnthropicProvider)(nil)
+	_ WeeklySummarizer      = (*AnthropicProvider)(nil)
+)
@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// anthropicProviderName labels errors mapped from the Anthropic API
+const anthropicProviderName = "anthropic"
+
+// Default retry hints for Anthropic errors whose response carries no Retry-After header
+const (
+	anthropicRateLimitRetryAfter = 60 * time.Second
+	anthropicQuotaRetryAfter     = time.Hour
+)
+
+// anthropicResponseError maps an error response from the Messages API into a ProviderError. The wrapped
+// APIError keeps ExtractAPIError, IsRateLimitError and IsQuotaError working on Anthropic errors, as they do
+// on OpenAI ones.
+func anthropicResponseError(resp *http.Response, body []byte) *ProviderError {
+	var payload struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	apiErr := &APIError{
+		Message:    payload.Error.Message,
+		Type:       payload.Error.Type,
+		StatusCode: resp.StatusCode,
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	provErr := &ProviderError{Kind: AIErrorUnknown, Provider: anthropicProviderName, StatusCode: resp.StatusCode, Err: apiErr}
+	switch status := resp.StatusCode; {
+	case isAnthropicQuotaError(apiErr):
+		// Exhausted credit is reported as a 400 invalid request; it clears only once the account is topped up
+		apiErr.IsPermanent = true
+		apiErr.Code = "insufficient_quota"
+		provErr.Kind = AIErrorQuota
+		provErr.RetryAfter = anthropicRetryAfter(resp, anthropicQuotaRetryAfter)
+	case status == http.StatusTooManyRequests:
+		provErr.Kind = AIErrorRateLimit
+		provErr.RetryAfter = anthropicRetryAfter(resp, anthropicRateLimitRetryAfter)
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		provErr.Kind = AIErrorTimeout
+	case status >= 500:
+		// Including the non-standard 529 the API returns while overloaded
+		provErr.Kind = AIErrorServer
+	case status >= 400:
+		provErr.Kind = AIErrorInvalidRequest
+	}
+	apiErr.RetryAfter = provErr.RetryAfter
+	return provErr
+}
+
+// isAnthropicQuotaError reports whether the error response means the account is out of credit
+func isAnthropicQuotaError(apiErr *APIError) bool {
+	if apiErr.StatusCode != http.StatusBadRequest && apiErr.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	message := strings.ToLower(apiErr.Message)
+	return strings.Contains(message, "credit balance") || strings.Contains(message, "billing")
+}
+
+// anthropicRetryAfter reads the Retry-After header (in seconds) of the error response, or returns fallback
+func anthropicRetryAfter(resp *http.Response, fallback time.Duration) *time.Duration {
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+		d := time.Duration(secs) * time.Second
+		return &d
+	}
+	return &fallback
+}
+
+// mapAnthropicTransportError maps a request that got no API response: timeouts become ProviderErrors,
+// other transport errors are returned unchanged so IsTransientNetworkError still recognizes them
+func mapAnthropicTransportError(err error) error {
+	if isTimeoutError(err) {
+		return &ProviderError{Kind: AIErrorTimeout, Provider: anthropicProviderName, Err: err}
+	}
+	return err
+}
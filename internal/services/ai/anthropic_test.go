@@ -0,0 +1,195 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+)
+
+func TestAnthropicProvider_AnalyzeTask(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		text     string
+		wantTags []string
+		wantTH   models.TimeHorizon
+	}{
+		{
+			name:     "continues the prefill",
+			text:     `"tags": ["work"], "time_horizon": "next"}`,
+			wantTags: []string{"work"},
+			wantTH:   models.TimeHorizonNext,
+		},
+		{
+			name:     "repeats the prefill",
+			text:     `{"tags": ["home"], "time_horizon": "later"}`,
+			wantTags: []string{"home"},
+			wantTH:   models.TimeHorizonLater,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got anthropicRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/messages" {
+					t.Errorf("path = %q, want /v1/messages", r.URL.Path)
+				}
+				if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") != AnthropicAPIVersion {
+					t.Errorf("headers = %v, want the API key and version", r.Header)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("decode request: %v", err)
+				}
+				resp := map[string]any{"content": []map[string]string{{"type": "text", "text": tt.text}}}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(resp)
+			}))
+			defer server.Close()
+
+			provider := NewAnthropicProviderWithLogger("test-key", server.URL, "", nil, false, 0)
+			tags, th, err := provider.AnalyzeTask(context.Background(), "Write report", nil)
+			if err != nil {
+				t.Fatalf("AnalyzeTask() error = %v", err)
+			}
+			if !slices.Equal(tags, tt.wantTags) || th != tt.wantTH {
+				t.Errorf("AnalyzeTask() = %v, %q, want %v, %q", tags, th, tt.wantTags, tt.wantTH)
+			}
+			if got.Model != DefaultAnthropicModel || got.MaxTokens == 0 || got.System == "" {
+				t.Errorf("request = %+v, want the default model, a token cap and a system prompt", got)
+			}
+			if n := len(got.Messages); n != 2 || got.Messages[n-1] != (anthropicMessage{Role: "assistant", Content: anthropicJSONPrefill}) {
+				t.Errorf("messages = %+v, want the prompt followed by the JSON prefill", got.Messages)
+			}
+		})
+	}
+}
+
+func TestAnthropicProvider_ErrorMapping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		status         int
+		body           string
+		retryAfter     string
+		wantKind       AIErrorKind
+		wantRateLimit  bool
+		wantQuota      bool
+		wantRetryAfter time.Duration
+	}{
+		{
+			name:           "rate limited",
+			status:         http.StatusTooManyRequests,
+			body:           `{"type":"error","error":{"type":"rate_limit_error","message":"Number of requests has exceeded your rate limit"}}`,
+			retryAfter:     "17",
+			wantKind:       AIErrorRateLimit,
+			wantRateLimit:  true,
+			wantRetryAfter: 17 * time.Second,
+		},
+		{
+			name:           "out of credit",
+			status:         http.StatusBadRequest,
+			body:           `{"type":"error","error":{"type":"invalid_request_error","message":"Your credit balance is too low to access the Anthropic API."}}`,
+			wantKind:       AIErrorQuota,
+			wantQuota:      true,
+			wantRetryAfter: anthropicQuotaRetryAfter,
+		},
+		{
+			name:     "overloaded",
+			status:   529,
+			body:     `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			wantKind: AIErrorServer,
+		},
+		{
+			name:     "invalid API key",
+			status:   http.StatusUnauthorized,
+			body:     `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`,
+			wantKind: AIErrorInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := NewAnthropicProviderWithLogger("test-key", server.URL, "", nil, false, 0)
+			provider.SetHTTPRetries(0)
+			_, _, err := provider.AnalyzeTask(context.Background(), "Write report", nil)
+			if err == nil {
+				t.Fatal("AnalyzeTask() error = nil, want an API error")
+			}
+			if got := ClassifyError(err); got != tt.wantKind {
+				t.Errorf("ClassifyError() = %v, want %v", got, tt.wantKind)
+			}
+			if got := IsRateLimitError(err); got != tt.wantRateLimit {
+				t.Errorf("IsRateLimitError() = %v, want %v", got, tt.wantRateLimit)
+			}
+			if got := IsQuotaError(err); got != tt.wantQuota {
+				t.Errorf("IsQuotaError() = %v, want %v", got, tt.wantQuota)
+			}
+			apiErr := ExtractAPIError(err)
+			if apiErr == nil || apiErr.StatusCode != tt.status {
+				t.Fatalf("ExtractAPIError() = %+v, want status %d", apiErr, tt.status)
+			}
+			if tt.wantRetryAfter > 0 && (apiErr.RetryAfter == nil || *apiErr.RetryAfter != tt.wantRetryAfter) {
+				t.Errorf("RetryAfter = %v, want %v", apiErr.RetryAfter, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestChatMessagesToAnthropic(t *testing.T) {
+	t.Parallel()
+
+	got := chatMessagesToAnthropic([]ChatMessage{
+		{Role: "system", Content: "context"},
+		{Role: "user", Content: "hello"},
+		{Role: "assistant", Content: "hi"},
+		{Role: "assistant", Content: "how can I help?"},
+		{Role: "user", Content: "plan my week"},
+	})
+	want := []anthropicMessage{
+		{Role: "user", Content: "context\n\nhello"},
+		{Role: "assistant", Content: "hi\n\nhow can I help?"},
+		{Role: "user", Content: "plan my week"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("chatMessagesToAnthropic() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRegisterAnthropic(t *testing.T) {
+	t.Parallel()
+
+	registry := NewProviderRegistry()
+	RegisterAnthropic(registry)
+
+	if _, err := registry.GetProvider("anthropic", map[string]string{}); err == nil {
+		t.Error("GetProvider() without api_key error = nil, want an error")
+	}
+	provider, err := registry.GetProvider("anthropic", map[string]string{"api_key": "test-key", "tokenizer": TokenizerHeuristic})
+	if err != nil {
+		t.Fatalf("GetProvider() error = %v", err)
+	}
+	if _, ok := provider.(*AnthropicProvider); !ok {
+		t.Errorf("GetProvider() = %T, want *AnthropicProvider", provider)
+	}
+}
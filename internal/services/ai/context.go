@@ -3,6 +3,7 @@ package ai
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/benvon/smart-todo/internal/database"
 	"github.com/benvon/smart-todo/internal/models"
@@ -11,18 +12,26 @@ import (
 
 // ContextService manages AI context for users
 type ContextService struct {
-	provider    AIProvider
-	contextRepo *database.AIContextRepository
+	provider         AIProvider
+	contextRepo      *database.AIContextRepository
+	maxSummaryLength int
 }
 
 // NewContextService creates a new context service
 func NewContextService(provider AIProvider, contextRepo *database.AIContextRepository) *ContextService {
 	return &ContextService{
-		provider:    provider,
-		contextRepo: contextRepo,
+		provider:         provider,
+		contextRepo:      contextRepo,
+		maxSummaryLength: models.DefaultMaxContextSummaryLength,
 	}
 }
 
+// SetMaxSummaryLength sets the maximum length in characters of a stored context summary; a longer one is
+// rejected with models.ErrContextSummaryTooLong
+func (s *ContextService) SetMaxSummaryLength(maxLength int) {
+	s.maxSummaryLength = maxLength
+}
+
 // GetOrCreateContext gets or creates AI context for a user
 func (s *ContextService) GetOrCreateContext(ctx context.Context, userID uuid.UUID) (*models.AIContext, error) {
 	aiContext, err := s.contextRepo.GetByUserID(ctx, userID)
@@ -45,27 +54,37 @@ func (s *ContextService) GetOrCreateContext(ctx context.Context, userID uuid.UUI
 
 // UpdateContextSummary updates the context summary from a conversation
 func (s *ContextService) UpdateContextSummary(ctx context.Context, userID uuid.UUID, conversationHistory []ChatMessage) error {
+	_, err := s.SummarizeConversation(ctx, userID, conversationHistory)
+	return err
+}
+
+// SummarizeConversation replaces the user's context summary with a summary of the conversation and returns
+// the saved context
+func (s *ContextService) SummarizeConversation(ctx context.Context, userID uuid.UUID, conversationHistory []ChatMessage) (*models.AIContext, error) {
 	// Summarize conversation
 	summary, err := s.provider.SummarizeContext(ctx, conversationHistory)
 	if err != nil {
-		return fmt.Errorf("failed to summarize context: %w", err)
+		return nil, fmt.Errorf("failed to summarize context: %w", err)
+	}
+	if err := models.ValidateContextSummaryLength(summary, s.maxSummaryLength); err != nil {
+		return nil, err
 	}
 
 	// Get or create context
 	aiContext, err := s.GetOrCreateContext(ctx, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Update summary
-	aiContext.ContextSummary = summary
+	// Update summary, recording how many user turns it was derived from
+	aiContext.SetContextSummary(summary, countUserTurns(conversationHistory), time.Now())
 
 	// Update in database
 	if err := s.contextRepo.Update(ctx, aiContext); err != nil {
-		return fmt.Errorf("failed to update context: %w", err)
+		return nil, fmt.Errorf("failed to update context: %w", err)
 	}
 
-	return nil
+	return aiContext, nil
 }
 
 // MergeContextSummary merges a new summary with existing context
@@ -76,11 +95,14 @@ func (s *ContextService) MergeContextSummary(ctx context.Context, userID uuid.UU
 	}
 
 	// Simple merge: append new summary to existing
+	merged := newSummary
 	if aiContext.ContextSummary != "" {
-		aiContext.ContextSummary = aiContext.ContextSummary + "\n\n" + newSummary
-	} else {
-		aiContext.ContextSummary = newSummary
+		merged = aiContext.ContextSummary + "\n\n" + newSummary
+	}
+	if err := models.ValidateContextSummaryLength(merged, s.maxSummaryLength); err != nil {
+		return err
 	}
+	aiContext.SetContextSummary(merged, aiContext.SummaryChatTurns, time.Now())
 
 	// Update in database
 	if err := s.contextRepo.Update(ctx, aiContext); err != nil {
@@ -90,6 +112,17 @@ func (s *ContextService) MergeContextSummary(ctx context.Context, userID uuid.UU
 	return nil
 }
 
+// countUserTurns counts the user messages in a conversation
+func countUserTurns(messages []ChatMessage) int {
+	turns := 0
+	for _, msg := range messages {
+		if msg.Role == "user" {
+			turns++
+		}
+	}
+	return turns
+}
+
 // LoadContextForAnalysis loads user context for task analysis
 func (s *ContextService) LoadContextForAnalysis(ctx context.Context, userID uuid.UUID) (*models.AIContext, error) {
 	return s.GetOrCreateContext(ctx, userID)
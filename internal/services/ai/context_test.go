@@ -0,0 +1,33 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+)
+
+type summaryProvider struct {
+	namedProvider
+	summary string
+}
+
+func (p *summaryProvider) SummarizeContext(ctx context.Context, conversation []ChatMessage) (string, error) {
+	return p.summary, nil
+}
+
+func TestContextService_UpdateContextSummary_RejectsLongSummary(t *testing.T) {
+	t.Parallel()
+
+	// The length is checked before the repository is used, so none is needed
+	service := NewContextService(&summaryProvider{summary: strings.Repeat("a", 11)}, nil)
+	service.SetMaxSummaryLength(10)
+
+	err := service.UpdateContextSummary(context.Background(), uuid.New(), []ChatMessage{{Role: "user", Content: "hi"}})
+	if !errors.Is(err, models.ErrContextSummaryTooLong) {
+		t.Errorf("UpdateContextSummary() error = %v, want %v", err, models.ErrContextSummaryTooLong)
+	}
+}
@@ -1,11 +1,18 @@
 package ai
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"strings"
+	"syscall"
 	"time"
+
+	"github.com/openai/openai-go/v3"
 )
 
 var (
@@ -29,6 +36,94 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("API error (status %d, type %s): %s", e.StatusCode, e.Type, e.Message)
 }
 
+// AIErrorKind is a provider-agnostic classification of AI provider failures. Each provider maps its native
+// errors into a kind so retry logic does not depend on the provider.
+type AIErrorKind string
+
+const (
+	// AIErrorUnknown is any failure no provider mapping recognized
+	AIErrorUnknown AIErrorKind = "unknown"
+	// AIErrorRateLimit is a temporary request or token rate limit; retry after a short delay
+	AIErrorRateLimit AIErrorKind = "rate_limit"
+	// AIErrorQuota is an exhausted quota or billing limit; retry after a long delay
+	AIErrorQuota AIErrorKind = "quota"
+	// AIErrorInvalidRequest is a request the provider rejected (bad input, auth, unknown model); retrying
+	// the same request cannot succeed
+	AIErrorInvalidRequest AIErrorKind = "invalid_request"
+	// AIErrorServer is a provider-side failure (5xx)
+	AIErrorServer AIErrorKind = "server"
+	// AIErrorTimeout is a request that did not complete in time
+	AIErrorTimeout AIErrorKind = "timeout"
+)
+
+// ProviderError is a provider failure mapped into an AIErrorKind
+type ProviderError struct {
+	Kind     AIErrorKind
+	Provider string
+	// StatusCode is the HTTP status of the provider response, 0 if there was none
+	StatusCode int
+	// RetryAfter is the provider's hint for when to retry, if any
+	RetryAfter *time.Duration
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s %s error: %v", e.Provider, e.Kind, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error { return e.Err }
+
+// ClassifyError returns the kind of an AI provider error. Errors a provider mapped into a ProviderError keep
+// its kind; anything else falls back to generic quota, rate limit and timeout heuristics.
+func ClassifyError(err error) AIErrorKind {
+	if err == nil {
+		return AIErrorUnknown
+	}
+	var provErr *ProviderError
+	if errors.As(err, &provErr) {
+		return provErr.Kind
+	}
+	switch {
+	case IsQuotaError(err):
+		return AIErrorQuota
+	case IsRateLimitError(err):
+		return AIErrorRateLimit
+	case isTimeoutError(err):
+		return AIErrorTimeout
+	}
+	return AIErrorUnknown
+}
+
+func isTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// RetryAfterHint returns the provider's retry hint carried by err, if any
+func RetryAfterHint(err error) *time.Duration {
+	var provErr *ProviderError
+	if errors.As(err, &provErr) && provErr.RetryAfter != nil {
+		return provErr.RetryAfter
+	}
+	if apiErr := ExtractAPIError(err); apiErr != nil {
+		return apiErr.RetryAfter
+	}
+	return nil
+}
+
+// IsTransient reports whether err is an AI outage expected to clear on its own: a rate limit, a provider-side
+// failure, a timeout or a dropped connection. Rejected requests and exhausted quotas are not transient.
+func IsTransient(err error) bool {
+	switch ClassifyError(err) {
+	case AIErrorRateLimit, AIErrorServer, AIErrorTimeout:
+		return true
+	}
+	return IsTransientNetworkError(err)
+}
+
 // IsRateLimitError checks if an error is a rate limit error
 func IsRateLimitError(err error) bool {
 	if err == nil {
@@ -65,12 +160,65 @@ func IsQuotaError(err error) bool {
 		strings.Contains(errStr, "billing")
 }
 
+// IsTransientNetworkError reports whether err is a network-level failure worth retrying within the same call
+// (connection reset, dropped connection, dial/read or DNS timeout). Refused connections and failed name
+// lookups point at a wrong URL or a stopped server, which an immediate retry does not fix. Errors carrying an API
+// response (4xx/5xx, rate limits, quota) and caller cancellation are never transient here; rate limits and
+// quota go through the queue-level backoff instead.
+func IsTransientNetworkError(err error) bool {
+	if err == nil || isAPIResponseError(err) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return isTransientConnError(err)
+}
+
+// transientConnErrors are connection-level failures that usually succeed on an immediate retry
+var transientConnErrors = []error{syscall.ECONNRESET, syscall.EPIPE, io.ErrUnexpectedEOF, io.EOF}
+
+// isAPIResponseError reports whether err came with a response from the API (including rate limit and quota).
+// Transport failures are excluded before the message heuristics run, since the request URL in their
+// message (e.g. a port such as :42979) can otherwise look like a status code.
+func isAPIResponseError(err error) bool {
+	var respErr *openai.Error
+	if errors.As(err, &respErr) {
+		return true
+	}
+	var provErr *ProviderError
+	if errors.As(err, &provErr) && provErr.StatusCode != 0 {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return false
+	}
+	return ExtractAPIError(err) != nil || IsQuotaError(err)
+}
+
+func isTransientConnError(err error) bool {
+	for _, target := range transientConnErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
 // ExtractAPIError extracts API error details from an error
 func ExtractAPIError(err error) *APIError {
 	if err == nil {
 		return nil
 	}
 
+	// Providers that map their own error responses (e.g. Anthropic) wrap the APIError directly
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
 	// Try to unwrap the error to find the underlying error
 	errStr := err.Error()
 
@@ -126,13 +274,13 @@ func ExtractAPIError(err error) *APIError {
 // GetRetryDelay calculates the delay before retrying based on error type
 func GetRetryDelay(err error, attempt int) time.Duration {
 	shift := retryShiftAmount(attempt)
-	if IsQuotaError(err) {
+	switch ClassifyError(err) {
+	case AIErrorQuota:
 		return capDuration(time.Hour*time.Duration(1<<shift), 24*time.Hour)
-	}
-	if IsRateLimitError(err) {
+	case AIErrorRateLimit:
 		delay := capDuration(60*time.Second*time.Duration(1<<shift), 15*time.Minute)
-		if apiErr := ExtractAPIError(err); apiErr != nil && apiErr.RetryAfter != nil && *apiErr.RetryAfter > delay {
-			delay = *apiErr.RetryAfter
+		if hint := RetryAfterHint(err); hint != nil && *hint > delay {
+			delay = *hint
 		}
 		return delay
 	}
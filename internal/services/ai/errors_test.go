@@ -0,0 +1,129 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsTransientNetworkError(t *testing.T) {
+	t.Parallel()
+
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"connection reset", &url.Error{Op: "Post", URL: "https://api.example.com", Err: &net.OpError{Op: "read", Net: "tcp", Err: &os.SyscallError{Syscall: "read", Err: syscall.ECONNRESET}}}, true},
+		{"connection refused", fmt.Errorf("failed: %w", dialErr), false},
+		{"unreachable host", &net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: syscall.EHOSTUNREACH}}, false},
+		{"dial timeout", &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}, true},
+		{"dns timeout", &net.DNSError{Err: "i/o timeout", Name: "api.example.com", IsTimeout: true}, true},
+		{"dns not found", &net.DNSError{Err: "no such host", Name: "api.example.com", IsNotFound: true}, false},
+		{"dns server failure", &net.DNSError{Err: "server misbehaving", Name: "api.example.com", IsTemporary: true}, false},
+		{"unexpected eof", &url.Error{Op: "Post", URL: "https://api.example.com", Err: io.ErrUnexpectedEOF}, true},
+		{"dropped connection with status-like port", &url.Error{Op: "Post", URL: "http://127.0.0.1:42979/chat/completions", Err: io.EOF}, true},
+		{"rate limited", errors.New(`POST "https://api.openai.com/v1/chat/completions": 429 Too Many Requests`), false},
+		{"quota exhausted", errors.New(`429 {"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}`), false},
+		{"extracted api error", &APIError{StatusCode: 429, Type: "rate_limit_error"}, false},
+		{"caller canceled", fmt.Errorf("request: %w", context.Canceled), false},
+		{"deadline exceeded", &url.Error{Op: "Post", URL: "https://api.example.com", Err: context.DeadlineExceeded}, false},
+		{"plain error", errors.New("invalid request"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := IsTransientNetworkError(tt.err); got != tt.want {
+				t.Errorf("IsTransientNetworkError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"rate limited", &ProviderError{Kind: AIErrorRateLimit, Err: errors.New("slow down")}, true},
+		{"provider server error", &ProviderError{Kind: AIErrorServer, StatusCode: 502, Err: errors.New("bad gateway")}, true},
+		{"timeout", fmt.Errorf("chat: %w", context.DeadlineExceeded), true},
+		{"connection reset", &url.Error{Op: "Post", URL: "https://api.example.com", Err: io.ErrUnexpectedEOF}, true},
+		{"quota exhausted", &ProviderError{Kind: AIErrorQuota, Err: errors.New("insufficient_quota")}, false},
+		{"invalid request", &ProviderError{Kind: AIErrorInvalidRequest, StatusCode: 401, Err: errors.New("bad key")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want AIErrorKind
+	}{
+		{"nil", nil, AIErrorUnknown},
+		{"provider error keeps its kind", fmt.Errorf("wrapped: %w", &ProviderError{Kind: AIErrorInvalidRequest, Provider: "other", Err: errors.New("quota field missing")}), AIErrorInvalidRequest},
+		{"unmapped quota message", errors.New("insufficient_quota: check your billing"), AIErrorQuota},
+		{"unmapped rate limit message", errors.New("too many requests"), AIErrorRateLimit},
+		{"unmapped deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), AIErrorTimeout},
+		{"unrelated error", errors.New("todo not found"), AIErrorUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRetryDelay_ByKind(t *testing.T) {
+	t.Parallel()
+
+	hint := 10 * time.Minute
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+	}{
+		{"quota", &ProviderError{Kind: AIErrorQuota}, time.Hour},
+		{"rate limit", &ProviderError{Kind: AIErrorRateLimit}, time.Minute},
+		{"rate limit with a longer provider hint", &ProviderError{Kind: AIErrorRateLimit, RetryAfter: &hint}, hint},
+		{"server error", &ProviderError{Kind: AIErrorServer}, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := GetRetryDelay(tt.err, 0); got != tt.want {
+				t.Errorf("GetRetryDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
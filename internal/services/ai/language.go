@@ -0,0 +1,120 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// OutputLanguageAuto asks the model to tag each todo in the todo's own language
+const OutputLanguageAuto = "auto"
+
+// maxOutputLanguageLength bounds the language name inserted into prompts
+const maxOutputLanguageLength = 32
+
+// normalizeOutputLanguage returns the trimmed language name, or "" if it is not a plain language name.
+// Only letters, spaces and hyphens are accepted because the value is inserted into the prompt.
+func normalizeOutputLanguage(language string) string {
+	language = strings.Join(strings.Fields(language), " ")
+	if language == "" || utf8.RuneCountInString(language) > maxOutputLanguageLength {
+		return ""
+	}
+	for _, r := range language {
+		if !unicode.IsLetter(r) && r != ' ' && r != '-' {
+			return ""
+		}
+	}
+	if strings.EqualFold(language, OutputLanguageAuto) {
+		return OutputLanguageAuto
+	}
+	return language
+}
+
+// scriptLanguages maps scripts used by a single common language to that language
+var scriptLanguages = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "Korean"},
+	{unicode.Hiragana, "Japanese"},
+	{unicode.Katakana, "Japanese"},
+	{unicode.Han, "Chinese"},
+	{unicode.Cyrillic, "Russian"},
+	{unicode.Greek, "Greek"},
+	{unicode.Arabic, "Arabic"},
+	{unicode.Hebrew, "Hebrew"},
+	{unicode.Devanagari, "Hindi"},
+	{unicode.Thai, "Thai"},
+}
+
+// latinStopwords lists frequent short words that identify Latin-script languages
+var latinStopwords = map[string][]string{
+	"English":    {"the", "and", "to", "for", "with", "of", "my", "on", "at", "buy", "call"},
+	"Spanish":    {"el", "la", "los", "las", "de", "del", "y", "para", "con", "que", "mi", "comprar", "llamar"},
+	"French":     {"le", "la", "les", "de", "des", "du", "et", "pour", "avec", "mon", "ma", "acheter", "appeler"},
+	"German":     {"der", "die", "das", "und", "mit", "für", "zum", "zur", "mein", "meine", "kaufen", "anrufen"},
+	"Portuguese": {"o", "a", "os", "as", "de", "do", "da", "e", "para", "com", "meu", "minha", "comprar", "ligar"},
+	"Italian":    {"il", "lo", "la", "gli", "di", "del", "della", "e", "per", "con", "mio", "mia", "comprare", "chiamare"},
+}
+
+// detectLanguage guesses the language of text from its script, or from common words for Latin-script
+// text. It returns "" when the text is too short or ambiguous to tell.
+func detectLanguage(text string) string {
+	for _, r := range text {
+		for _, s := range scriptLanguages {
+			if unicode.Is(s.table, r) {
+				if s.language == "Chinese" && strings.IndexFunc(text, isKana) >= 0 {
+					return "Japanese"
+				}
+				return s.language
+			}
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	best, bestScore, tied := "", 0, false
+	for language, stopwords := range latinStopwords {
+		score := 0
+		for _, w := range words {
+			for _, sw := range stopwords {
+				if w == sw {
+					score++
+					break
+				}
+			}
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = language, score, false
+		case score == bestScore && score > 0:
+			tied = true
+		}
+	}
+	if bestScore == 0 || tied {
+		return ""
+	}
+	return best
+}
+
+func isKana(r rune) bool {
+	return unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r)
+}
+
+// promptLanguageSection tells the model which language to write tags in. With OutputLanguageAuto the
+// detected language is named when detection succeeds; otherwise tags follow the todo's language.
+func promptLanguageSection(language, text string) string {
+	switch language {
+	case "":
+		return ""
+	case OutputLanguageAuto:
+		if detected := detectLanguage(text); detected != "" {
+			return fmt.Sprintf("\n\nThe todo item appears to be written in %s. Respond with tags in %s.", detected, detected)
+		}
+		return "\n\nRespond with tags in the same language as the todo item."
+	default:
+		return fmt.Sprintf("\n\nRespond with tags in %s, regardless of the language of the todo item.", language)
+	}
+}
@@ -0,0 +1,20 @@
+package ai
+
+import (
+	"time"
+
+	"github.com/benvon/smart-todo/internal/metrics"
+)
+
+// aiCallOutcomeOK is the outcome label of a successful call; failures use their AIErrorKind
+const aiCallOutcomeOK = "ok"
+
+// observeAICall records the duration of a provider call since start in metrics.AICallDuration. It is
+// deferred with a pointer to the call's named error so the outcome is read once the call returns.
+func observeAICall(provider, operation string, start time.Time, err *error) {
+	outcome := aiCallOutcomeOK
+	if *err != nil {
+		outcome = string(ClassifyError(*err))
+	}
+	metrics.AICallDuration.ObserveDuration(start, provider, operation, outcome)
+}
@@ -0,0 +1,25 @@
+package ai
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/metrics"
+)
+
+func TestObserveAICall(t *testing.T) {
+	t.Parallel()
+
+	const operation = "observe_ai_call_test"
+	success, failure := error(nil), errors.New(ErrNoContentInResponse)
+	observeAICall(openAIProviderName, operation, time.Now(), &success)
+	observeAICall(openAIProviderName, operation, time.Now(), &failure)
+
+	if got := metrics.AICallDuration.Count(openAIProviderName, operation, aiCallOutcomeOK); got != 1 {
+		t.Errorf("successful calls = %d, want 1", got)
+	}
+	if got := metrics.AICallDuration.Count(openAIProviderName, operation, string(ClassifyError(failure))); got != 1 {
+		t.Errorf("failed calls = %d, want 1", got)
+	}
+}
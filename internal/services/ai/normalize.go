@@ -0,0 +1,149 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Text normalization steps, as named in AI_TEXT_NORMALIZATION
+const (
+	NormalizeLowercase          = "lowercase"
+	NormalizeStripPunctuation   = "strip_punctuation"
+	NormalizeCollapseWhitespace = "collapse_whitespace"
+	NormalizeStem               = "stem"
+	// NormalizeNone disables every step
+	NormalizeNone = "none"
+)
+
+// minStemLength keeps the suffix stripper from reducing short words to fragments (e.g. "is", "bus")
+const minStemLength = 3
+
+// TextNormalization selects the steps applied to text before it is compared for similarity, so that
+// "Call mom!" and "call mom" match
+type TextNormalization struct {
+	Lowercase bool
+	// StripPunctuation treats punctuation and symbols as word separators; apostrophes are dropped so
+	// "mom's" becomes "moms"
+	StripPunctuation   bool
+	CollapseWhitespace bool
+	// Stem strips common English inflections (plurals, -ing, -ed) from each word
+	Stem bool
+}
+
+// DefaultTextNormalization lowercases, strips punctuation and collapses whitespace, without stemming
+var DefaultTextNormalization = TextNormalization{Lowercase: true, StripPunctuation: true, CollapseWhitespace: true}
+
+// ParseTextNormalization builds a normalization from step names; no steps means the default, and "none"
+// disables them all
+func ParseTextNormalization(steps []string) (TextNormalization, error) {
+	if len(steps) == 0 {
+		return DefaultTextNormalization, nil
+	}
+	var n TextNormalization
+	for _, step := range steps {
+		switch strings.ToLower(strings.TrimSpace(step)) {
+		case NormalizeLowercase:
+			n.Lowercase = true
+		case NormalizeStripPunctuation:
+			n.StripPunctuation = true
+		case NormalizeCollapseWhitespace:
+			n.CollapseWhitespace = true
+		case NormalizeStem:
+			n.Stem = true
+		case NormalizeNone:
+			if len(steps) > 1 {
+				return TextNormalization{}, fmt.Errorf("text normalization %q cannot be combined with other steps", NormalizeNone)
+			}
+		default:
+			return TextNormalization{}, fmt.Errorf("unknown text normalization step %q (want %s, %s, %s, %s or %s)",
+				step, NormalizeLowercase, NormalizeStripPunctuation, NormalizeCollapseWhitespace, NormalizeStem, NormalizeNone)
+		}
+	}
+	return n, nil
+}
+
+// Normalize applies the enabled steps to text
+func (n TextNormalization) Normalize(text string) string {
+	if n.Lowercase {
+		text = strings.ToLower(text)
+	}
+	if n.StripPunctuation {
+		text = stripPunctuation(text)
+	}
+	if n.Stem {
+		words := strings.Fields(text)
+		for i, word := range words {
+			words[i] = stemWord(word)
+		}
+		// Stemming splits on whitespace, so the result is already collapsed
+		return strings.Join(words, " ")
+	}
+	if n.CollapseWhitespace {
+		text = strings.Join(strings.Fields(text), " ")
+	}
+	return text
+}
+
+// Similarity scores how alike two texts are after normalization as the Jaccard similarity of their words:
+// 0 for nothing in common (or an empty text) up to 1 for the same words
+func (n TextNormalization) Similarity(s1, s2 string) float64 {
+	words1 := strings.Fields(n.Normalize(s1))
+	words2 := strings.Fields(n.Normalize(s2))
+
+	if len(words1) == 0 || len(words2) == 0 {
+		return 0.0
+	}
+
+	// Create a set of words from s2 for faster lookup
+	word2Set := make(map[string]bool)
+	for _, word := range words2 {
+		word2Set[word] = true
+	}
+
+	// Count common words
+	commonCount := 0
+	for _, word := range words1 {
+		if word2Set[word] {
+			commonCount++
+		}
+	}
+
+	// Use Jaccard similarity: intersection / union
+	union := len(words1) + len(words2) - commonCount
+	if union == 0 {
+		return 0.0
+	}
+
+	return float64(commonCount) / float64(union)
+}
+
+// stripPunctuation replaces punctuation and symbols with spaces, dropping apostrophes within words
+func stripPunctuation(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\'' || r == '’':
+			return -1
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			return ' '
+		}
+		return r
+	}, text)
+}
+
+// stemWord strips a plural ending and then an -ing or -ed ending, keeping at least minStemLength characters.
+// It is deliberately simple: inflections of the same word only need to meet, not to produce dictionary forms.
+func stemWord(word string) string {
+	if stem, ok := strings.CutSuffix(word, "ies"); ok && len([]rune(stem)) >= minStemLength {
+		word = stem + "y"
+	} else if stem, ok := strings.CutSuffix(word, "s"); ok && len([]rune(stem)) >= minStemLength && !strings.ContainsAny(stem[len(stem)-1:], "siu") {
+		// -ss, -is and -us endings ("glass", "this", "status") are not plurals
+		word = stem
+	}
+	for _, suffix := range []string{"ing", "ed"} {
+		if stem, ok := strings.CutSuffix(word, suffix); ok && len([]rune(stem)) >= minStemLength {
+			return stem
+		}
+	}
+	return word
+}
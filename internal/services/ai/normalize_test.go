@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+)
+
+func TestTextNormalization_Normalize(t *testing.T) {
+	t.Parallel()
+
+	all := TextNormalization{Lowercase: true, StripPunctuation: true, CollapseWhitespace: true, Stem: true}
+	tests := []struct {
+		name  string
+		norm  TextNormalization
+		input string
+		want  string
+	}{
+		{name: "none", input: "Call Mom!", want: "Call Mom!"},
+		{name: "lowercase only", norm: TextNormalization{Lowercase: true}, input: "Call Mom!", want: "call mom!"},
+		{name: "default", norm: DefaultTextNormalization, input: "  Call   Mom!!  ", want: "call mom"},
+		{name: "punctuation separates words", norm: DefaultTextNormalization, input: "e-mail/phone: Bob", want: "e mail phone bob"},
+		{name: "apostrophes dropped", norm: DefaultTextNormalization, input: "Mom’s birthday", want: "moms birthday"},
+		{name: "whitespace kept without collapse", norm: TextNormalization{StripPunctuation: true}, input: "a, b", want: "a  b"},
+		{name: "stemming", norm: all, input: "Buying groceries; called the plumbers", want: "buy grocery call the plumber"},
+		{name: "stemming keeps short words and double s", norm: all, input: "this bus needs glass", want: "this bus need glass"},
+		{name: "plural then suffix", norm: all, input: "meetings meeting meet", want: "meet meet meet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.norm.Normalize(tt.input); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextNormalization_SimilarityVariants(t *testing.T) {
+	t.Parallel()
+
+	lowercaseOnly := TextNormalization{Lowercase: true}
+	withStem := DefaultTextNormalization
+	withStem.Stem = true
+	tests := []struct {
+		name   string
+		s1, s2 string
+		richer TextNormalization
+	}{
+		{name: "punctuation", s1: "Call mom!", s2: "call mom", richer: DefaultTextNormalization},
+		{name: "case and punctuation", s1: "PAY RENT.", s2: "pay rent", richer: DefaultTextNormalization},
+		{name: "hyphenated tag", s1: "follow-up", s2: "Send follow up email", richer: DefaultTextNormalization},
+		{name: "inflections", s1: "groceries", s2: "buy grocery", richer: withStem},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			before := lowercaseOnly.Similarity(tt.s1, tt.s2)
+			after := tt.richer.Similarity(tt.s1, tt.s2)
+			if after <= before {
+				t.Errorf("Similarity(%q, %q) = %f with richer normalization, want more than %f", tt.s1, tt.s2, after, before)
+			}
+		})
+	}
+
+	if got := DefaultTextNormalization.Similarity("Call mom!", "call mom"); got != 1 {
+		t.Errorf("Similarity of punctuation and case variants = %f, want 1", got)
+	}
+}
+
+func TestParseTextNormalization(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		steps   []string
+		want    TextNormalization
+		wantErr bool
+	}{
+		{name: "default", want: DefaultTextNormalization},
+		{name: "selected steps", steps: []string{"lowercase", " STEM "}, want: TextNormalization{Lowercase: true, Stem: true}},
+		{name: "none", steps: []string{"none"}, want: TextNormalization{}},
+		{name: "none with other steps", steps: []string{"none", "stem"}, wantErr: true},
+		{name: "unknown step", steps: []string{"lemmatize"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseTextNormalization(tt.steps)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTextNormalization() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTextNormalization() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectTagsForPrompt_TextNormalization(t *testing.T) {
+	t.Parallel()
+
+	// Equal usage, so only similarity to the todo decides which tag makes the one-tag budget
+	tagStats := map[string]models.TagStats{"errand": {Total: 5}, "finance": {Total: 5}}
+	provider := &OpenAIProvider{providerBase: providerBase{analysisPrompter: analysisPrompter{maxTagsInPrompt: 1, maxTagTokens: 100}}}
+
+	provider.SetTextNormalization(TextNormalization{Lowercase: true, StripPunctuation: true, Stem: true})
+	if got := provider.selectTagsForPrompt(tagStats, "Run errands!", nil); len(got) != 1 || got[0] != "errand" {
+		t.Errorf("selectTagsForPrompt() with stemming = %v, want [errand]", got)
+	}
+}
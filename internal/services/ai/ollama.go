@@ -0,0 +1,232 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultOllamaModel is the default Ollama model to use
+	DefaultOllamaModel = "llama3.2"
+	// DefaultOllamaBaseURL is the default Ollama server URL
+	DefaultOllamaBaseURL = "http://localhost:11434"
+	// DefaultOllamaTimeout is the default timeout for Ollama calls; local models are often much slower
+	// than hosted APIs
+	DefaultOllamaTimeout = 2 * time.Minute
+
+	// ollamaProviderName labels errors mapped from the Ollama API
+	ollamaProviderName = "ollama"
+)
+
+// OllamaProvider implements the AIProvider interface using a local Ollama server's chat API, so todo text
+// never leaves the network. Analysis uses the same prompt as the OpenAI provider.
+type OllamaProvider struct {
+	providerBase
+	baseURL string
+}
+
+// ollamaMessage is one message of an Ollama chat
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ollamaRequest is an Ollama /api/chat request body
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	// Format "json" constrains the model to emit a JSON object
+	Format string `json:"format,omitempty"`
+}
+
+// ollamaResponse is the part of a non-streamed /api/chat response the provider reads
+type ollamaResponse struct {
+	Message         ollamaMessage `json:"message"`
+	PromptEvalCount int64         `json:"prompt_eval_count"`
+	EvalCount       int64         `json:"eval_count"`
+}
+
+func (r *ollamaResponse) content() string { return r.Message.Content }
+
+func (r *ollamaResponse) usage() (promptTokens, completionTokens int64) {
+	return r.PromptEvalCount, r.EvalCount
+}
+
+// NewOllamaProvider creates a new Ollama provider for the server at baseURL
+func NewOllamaProvider(baseURL string, model string) *OllamaProvider {
+	return NewOllamaProviderWithLogger(baseURL, model, nil, false, 0)
+}
+
+// NewOllamaProviderWithLogger creates a new Ollama provider with logger support. baseURL is the server root
+// (e.g. http://host:11434); a trailing /api is accepted. timeout bounds each API call; 0 uses
+// DefaultOllamaTimeout.
+func NewOllamaProviderWithLogger(baseURL string, model string, logger *zap.Logger, debugMode bool, timeout time.Duration) *OllamaProvider {
+	if model == "" {
+		model = DefaultOllamaModel
+	}
+	if baseURL == "" {
+		baseURL = DefaultOllamaBaseURL
+	}
+	if timeout <= 0 {
+		timeout = DefaultOllamaTimeout
+	}
+	return &OllamaProvider{
+		providerBase: newProviderBase(ollamaProviderName, model, timeout, logger, debugMode),
+		baseURL:      strings.TrimSuffix(strings.TrimRight(baseURL, "/"), "/api"),
+	}
+}
+
+// AnalyzeTask analyzes a task and returns suggested tags and time horizon
+func (p *OllamaProvider) AnalyzeTask(ctx context.Context, text string, userContext *models.AIContext) ([]string, models.TimeHorizon, error) {
+	return p.AnalyzeTaskWithDueDate(ctx, text, nil, time.Now(), userContext, nil)
+}
+
+// AnalyzeTaskWithDueDate analyzes a task with an optional due date and creation time, returns suggested tags and time horizon.
+// tagStats is optional tag statistics to guide tag selection (prefer existing tags).
+func (p *OllamaProvider) AnalyzeTaskWithDueDate(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
+	prompt := p.buildAnalysisPrompt(text, dueDate, dueDateOnlyFromContext(ctx), priorityFromContext(ctx), createdAt, userContext, tagStats)
+	req := ollamaRequest{
+		Model: p.model,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: analysisSystemPrompt},
+			{Role: "user", Content: prompt},
+		},
+		Format: "json",
+	}
+	content, err := p.send(ctx, "analyze_task", req, prompt)
+	if err != nil {
+		return nil, models.TimeHorizonSoon, fmt.Errorf("failed to analyze task: %w", err)
+	}
+	// Small local models often wrap the JSON in prose; parseAndValidateAnalysisResponse extracts it
+	tags, th, missing, err := parseAndValidateAnalysisResponse(content)
+	if err != nil {
+		return nil, models.TimeHorizonSoon, err
+	}
+	p.warnIncompleteAnalysis(ctx, missing)
+	return tags, th, nil
+}
+
+// Chat handles a chat message and returns the AI response
+func (p *OllamaProvider) Chat(ctx context.Context, messages []ChatMessage, userContext *models.AIContext) (*ChatResponse, error) {
+	req := ollamaRequest{
+		Model:    p.model,
+		Messages: []ollamaMessage{{Role: "system", Content: buildChatSystemContent(userContext)}},
+	}
+	for _, msg := range messages {
+		req.Messages = append(req.Messages, ollamaMessage{Role: msg.Role, Content: msg.Content})
+	}
+	content, err := p.send(ctx, "chat", req, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to chat: %w", err)
+	}
+	return &ChatResponse{Message: content, NeedsUpdate: true}, nil
+}
+
+// SummarizeContext summarizes a conversation history into a context summary
+func (p *OllamaProvider) SummarizeContext(ctx context.Context, conversationHistory []ChatMessage) (string, error) {
+	prompt := buildSummaryPrompt(conversationHistory)
+	req := ollamaRequest{
+		Model: p.model,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: "You are a helpful assistant that creates concise summaries of conversations. Focus on extracting user preferences and patterns."},
+			{Role: "user", Content: prompt},
+		},
+	}
+	content, err := p.send(ctx, "summarize_context", req, prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize context: %w", err)
+	}
+	return content, nil
+}
+
+// SummarizeWeek writes a short summary of what the user completed last week and what is still open
+func (p *OllamaProvider) SummarizeWeek(ctx context.Context, completed, pending []string) (string, error) {
+	prompt := buildWeeklySummaryPrompt(completed, pending)
+	req := ollamaRequest{
+		Model: p.model,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: "You are a helpful assistant that writes brief, encouraging weekly summaries of a user's todo list. Use plain text, no more than a short paragraph."},
+			{Role: "user", Content: prompt},
+		},
+	}
+	content, err := p.send(ctx, "summarize_week", req, prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize week: %w", err)
+	}
+	return content, nil
+}
+
+// send posts req to the chat API and returns the response text. API errors are mapped into a
+// ProviderError.
+func (p *OllamaProvider) send(ctx context.Context, operation string, req ollamaRequest, prompt string) (string, error) {
+	return sendJSON(ctx, &p.providerBase, jsonCall{
+		operation:      operation,
+		prompt:         prompt,
+		messageCount:   len(req.Messages),
+		url:            p.baseURL + "/api/chat",
+		body:           req,
+		transportError: mapOllamaTransportError,
+		responseError:  func(resp *http.Response, body []byte) error { return ollamaResponseError(resp.StatusCode, body) },
+	}, &ollamaResponse{})
+}
+
+// ollamaResponseError maps an error response ({"error": "..."}) into a ProviderError. An unknown model is
+// an invalid request; an overloaded server (503 when its queue is full) is a server error worth retrying.
+func ollamaResponseError(status int, body []byte) *ProviderError {
+	var payload struct {
+		Error string `json:"error"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	apiErr := &APIError{Message: payload.Error, StatusCode: status}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(status)
+	}
+
+	provErr := &ProviderError{Kind: AIErrorUnknown, Provider: ollamaProviderName, StatusCode: status, Err: apiErr}
+	switch {
+	case status == http.StatusTooManyRequests:
+		provErr.Kind = AIErrorRateLimit
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		provErr.Kind = AIErrorTimeout
+	case status >= 500:
+		provErr.Kind = AIErrorServer
+	case status >= 400:
+		provErr.Kind = AIErrorInvalidRequest
+	}
+	return provErr
+}
+
+// mapOllamaTransportError maps a request that got no API response: timeouts become ProviderErrors, other
+// transport errors are returned unchanged so IsTransientNetworkError still recognizes them
+func mapOllamaTransportError(err error) error {
+	if isTimeoutError(err) {
+		return &ProviderError{Kind: AIErrorTimeout, Provider: ollamaProviderName, Err: err}
+	}
+	return err
+}
+
+// RegisterOllama registers the Ollama provider with the registry. It needs no API key; base_url is the
+// Ollama server (default http://localhost:11434).
+func RegisterOllama(registry *ProviderRegistry) {
+	registry.Register(ollamaProviderName, func(config map[string]string) (AIProvider, error) {
+		provider := NewOllamaProviderWithLogger(config["base_url"], config["model"], nil, false, 0)
+		if err := provider.configure(config); err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
+
+// Ensure OllamaProvider implements the optional provider interfaces
+var (
+	_ AIProviderWithDueDate = (*OllamaProvider)(nil)
+	_ WeeklySummarizer      = (*OllamaProvider)(nil)
+)
@@ -0,0 +1,162 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+)
+
+func TestOllamaProvider_AnalyzeTask(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		basePath string
+		content  string
+		wantTags []string
+		wantTH   models.TimeHorizon
+	}{
+		{
+			name:     "plain JSON",
+			content:  `{"tags": ["work"], "time_horizon": "next"}`,
+			wantTags: []string{"work"},
+			wantTH:   models.TimeHorizonNext,
+		},
+		{
+			name:     "JSON wrapped in prose",
+			content:  "Sure! Here is the analysis:\n{\"tags\": [\"home\"], \"time_horizon\": \"later\"}\nLet me know if you need more.",
+			wantTags: []string{"home"},
+			wantTH:   models.TimeHorizonLater,
+		},
+		{
+			name:     "base URL with /api",
+			basePath: "/api/",
+			content:  `{"tags": ["work"], "time_horizon": "soon"}`,
+			wantTags: []string{"work"},
+			wantTH:   models.TimeHorizonSoon,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got ollamaRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/chat" {
+					t.Errorf("path = %q, want /api/chat", r.URL.Path)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("decode request: %v", err)
+				}
+				resp := ollamaResponse{Message: ollamaMessage{Role: "assistant", Content: tt.content}}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(resp)
+			}))
+			defer server.Close()
+
+			provider := NewOllamaProvider(server.URL+tt.basePath, "")
+			tags, th, err := provider.AnalyzeTask(context.Background(), "Write report", nil)
+			if err != nil {
+				t.Fatalf("AnalyzeTask() error = %v", err)
+			}
+			if !slices.Equal(tags, tt.wantTags) || th != tt.wantTH {
+				t.Errorf("AnalyzeTask() = %v, %q, want %v, %q", tags, th, tt.wantTags, tt.wantTH)
+			}
+			if got.Model != DefaultOllamaModel || got.Stream || got.Format != "json" {
+				t.Errorf("request = %+v, want the default model, no streaming and JSON format", got)
+			}
+			if len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[1].Role != "user" {
+				t.Errorf("messages = %+v, want the system prompt and the analysis prompt", got.Messages)
+			}
+		})
+	}
+}
+
+func TestOllamaProvider_ErrorMapping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantKind AIErrorKind
+	}{
+		{name: "unknown model", status: http.StatusNotFound, body: `{"error":"model \"llama9\" not found, try pulling it first"}`, wantKind: AIErrorInvalidRequest},
+		{name: "server busy", status: http.StatusServiceUnavailable, body: `{"error":"server busy, please try again"}`, wantKind: AIErrorServer},
+		{name: "no error body", status: http.StatusInternalServerError, wantKind: AIErrorServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := NewOllamaProvider(server.URL, "llama9")
+			_, _, err := provider.AnalyzeTask(context.Background(), "Write report", nil)
+			if err == nil {
+				t.Fatal("AnalyzeTask() error = nil, want an API error")
+			}
+			if got := ClassifyError(err); got != tt.wantKind {
+				t.Errorf("ClassifyError() = %v, want %v", got, tt.wantKind)
+			}
+			if IsRateLimitError(err) || IsQuotaError(err) {
+				t.Errorf("error %v reported as rate limit or quota", err)
+			}
+			if apiErr := ExtractAPIError(err); apiErr == nil || apiErr.StatusCode != tt.status {
+				t.Errorf("ExtractAPIError() = %+v, want status %d", apiErr, tt.status)
+			}
+		})
+	}
+}
+
+func TestNewOllamaProviderWithLogger_Timeout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{name: "configured timeout", timeout: 5 * time.Minute, want: 5 * time.Minute},
+		{name: "unset uses the Ollama default", want: DefaultOllamaTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			provider := NewOllamaProviderWithLogger("", "", nil, false, tt.timeout)
+			if provider.httpClient.Timeout != tt.want {
+				t.Errorf("timeout = %v, want %v", provider.httpClient.Timeout, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterOllama(t *testing.T) {
+	t.Parallel()
+
+	registry := NewProviderRegistry()
+	RegisterOllama(registry)
+
+	provider, err := registry.GetProvider("ollama", map[string]string{"model": "qwen2.5", "tokenizer": TokenizerHeuristic})
+	if err != nil {
+		t.Fatalf("GetProvider() without api_key error = %v", err)
+	}
+	ollama, ok := provider.(*OllamaProvider)
+	if !ok {
+		t.Fatalf("GetProvider() = %T, want *OllamaProvider", provider)
+	}
+	if ollama.model != "qwen2.5" || ollama.baseURL != DefaultOllamaBaseURL {
+		t.Errorf("provider model %q at %q, want qwen2.5 at %q", ollama.model, ollama.baseURL, DefaultOllamaBaseURL)
+	}
+}
@@ -6,8 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,13 +24,15 @@ const (
 	DefaultOpenAIModel = "gpt-4o-mini"
 	// DefaultOpenAIBaseURL is the default OpenAI API base URL
 	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
-	// DefaultTimeout is the default timeout for API calls
+	// DefaultTimeout is the default timeout for OpenAI and Anthropic API calls (AI_REQUEST_TIMEOUT)
 	DefaultTimeout = 30 * time.Second
 
 	// DefaultMaxTagsInPrompt is the default maximum number of tags to include in the prompt
 	DefaultMaxTagsInPrompt = 50
 	// DefaultMaxTagTokens is the default maximum number of tokens for the tag list (roughly 30% of typical context)
 	DefaultMaxTagTokens = 500
+	// MaxCoOccurrenceHints is the maximum number of co-occurring tag pairs to include in the prompt
+	MaxCoOccurrenceHints = 5
 
 	// TagScoreFrequencyWeight is the weight given to tag frequency in the scoring algorithm
 	TagScoreFrequencyWeight = 0.7
@@ -41,55 +43,78 @@ const (
 
 	// ErrNoChoicesInResponse is returned when the API response has no choices
 	ErrNoChoicesInResponse = "no choices in response"
+
+	analysisSystemPrompt = "You are a helpful assistant that analyzes todo items and suggests tags and time horizons. Respond with valid JSON only."
 )
 
-// OpenAIProvider implements the AIProvider interface using OpenAI's API
-type OpenAIProvider struct {
-	client          openai.Client
-	model           string
+// analysisPrompter builds task analysis prompts. Providers embed it so every provider sends the same prompt.
+type analysisPrompter struct {
 	maxTagsInPrompt int
 	maxTagTokens    int
-	logger          *zap.Logger
-	debugMode       bool
+	tokenizer       Tokenizer
+	outputLanguage  string
+	// normalization prepares text for tag similarity; nil uses DefaultTextNormalization
+	normalization *TextNormalization
+}
+
+// newAnalysisPrompter returns a prompter with the default tag budget and the heuristic tokenizer
+func newAnalysisPrompter() analysisPrompter {
+	return analysisPrompter{
+		maxTagsInPrompt: DefaultMaxTagsInPrompt,
+		maxTagTokens:    DefaultMaxTagTokens,
+		tokenizer:       HeuristicTokenizer{},
+	}
+}
+
+// OpenAIProvider implements the AIProvider interface using OpenAI's API
+type OpenAIProvider struct {
+	// providerBase's httpClient is shared with client; SetHTTPRetries swaps its transport
+	providerBase
+	client openai.Client
 }
 
 // NewOpenAIProvider creates a new OpenAI provider
 func NewOpenAIProvider(apiKey string, model string) *OpenAIProvider {
-	return NewOpenAIProviderWithLogger(apiKey, DefaultOpenAIBaseURL, model, nil, false)
+	return NewOpenAIProviderWithLogger(apiKey, DefaultOpenAIBaseURL, model, nil, false, 0)
 }
 
-// NewOpenAIProviderWithLogger creates a new OpenAI provider with logger support
-func NewOpenAIProviderWithLogger(apiKey string, baseURL string, model string, logger *zap.Logger, debugMode bool) *OpenAIProvider {
+// NewOpenAIProviderWithLogger creates a new OpenAI provider with logger support. timeout bounds each API
+// call; 0 uses DefaultTimeout.
+func NewOpenAIProviderWithLogger(apiKey string, baseURL string, model string, logger *zap.Logger, debugMode bool, timeout time.Duration) *OpenAIProvider {
 	if model == "" {
 		model = DefaultOpenAIModel
 	}
 	if baseURL == "" {
 		baseURL = DefaultOpenAIBaseURL
 	}
-
-	httpClient := &http.Client{
-		Timeout: DefaultTimeout,
+	if timeout <= 0 {
+		timeout = DefaultTimeout
 	}
 
+	base := newProviderBase(openAIProviderName, model, timeout, logger, debugMode)
 	client := openai.NewClient(
 		option.WithAPIKey(apiKey),
 		option.WithBaseURL(baseURL),
-		option.WithHTTPClient(httpClient),
+		option.WithHTTPClient(base.httpClient),
+		// The SDK's own retries would also retry rate limits; 5xx responses and transient network errors are
+		// retried by the client's retryTransport instead, and rate limits are left to the queue-level backoff
+		option.WithMaxRetries(0),
 	)
 
-	return &OpenAIProvider{
-		client:          client,
-		model:           model,
-		maxTagsInPrompt: DefaultMaxTagsInPrompt,
-		maxTagTokens:    DefaultMaxTagTokens,
-		logger:          logger,
-		debugMode:       debugMode,
-	}
+	return &OpenAIProvider{providerBase: base, client: client}
 }
 
 // NewOpenAIProviderWithConfig creates a new OpenAI provider with custom configuration
 func NewOpenAIProviderWithConfig(apiKey string, baseURL string, model string) *OpenAIProvider {
-	return NewOpenAIProviderWithLogger(apiKey, baseURL, model, nil, false)
+	return NewOpenAIProviderWithLogger(apiKey, baseURL, model, nil, false, 0)
+}
+
+// outputLanguageFor returns the user's valid language preference, falling back to the provider default
+func (p *analysisPrompter) outputLanguageFor(userContext *models.AIContext) string {
+	if language := normalizeOutputLanguage(userContext.OutputLanguage()); language != "" {
+		return language
+	}
+	return p.outputLanguage
 }
 
 // AnalyzeTask analyzes a task and returns suggested tags and time horizon
@@ -97,10 +122,14 @@ func (p *OpenAIProvider) AnalyzeTask(ctx context.Context, text string, userConte
 	return p.AnalyzeTaskWithDueDate(ctx, text, nil, time.Now(), userContext, nil)
 }
 
-func parseAndValidateAnalysisResponse(content string) ([]string, models.TimeHorizon, error) {
+// parseAndValidateAnalysisResponse parses the model's analysis JSON. An invalid or missing time_horizon
+// defaults to soon. A missing or null tags field returns nil tags, which callers treat as "keep the existing
+// tags", while an empty array returns an empty non-nil slice. missing lists the fields the model left out; a
+// response missing both fields is an error.
+func parseAndValidateAnalysisResponse(content string) (tags []string, th models.TimeHorizon, missing []string, err error) {
 	var analysis struct {
-		Tags        []string `json:"tags"`
-		TimeHorizon string   `json:"time_horizon"`
+		Tags        *[]string `json:"tags"`
+		TimeHorizon *string   `json:"time_horizon"`
 	}
 	raw := content
 	if err := json.Unmarshal([]byte(raw), &analysis); err != nil {
@@ -112,23 +141,51 @@ func parseAndValidateAnalysisResponse(content string) ([]string, models.TimeHori
 			}
 		}
 		if err := json.Unmarshal([]byte(raw), &analysis); err != nil {
-			return nil, models.TimeHorizonSoon, fmt.Errorf("failed to parse analysis response: %w", err)
+			return nil, models.TimeHorizonSoon, nil, fmt.Errorf("failed to parse analysis response: %w", err)
 		}
 	}
-	th := models.TimeHorizon(analysis.TimeHorizon)
-	switch th {
-	case models.TimeHorizonNext, models.TimeHorizonSoon, models.TimeHorizonLater:
-	default:
-		th = models.TimeHorizonSoon
+	if analysis.Tags == nil {
+		missing = append(missing, "tags")
+	} else {
+		tags = models.NormalizeTags(*analysis.Tags)
+	}
+	th = models.TimeHorizonSoon
+	if analysis.TimeHorizon == nil {
+		missing = append(missing, "time_horizon")
+	} else {
+		switch h := models.TimeHorizon(*analysis.TimeHorizon); h {
+		case models.TimeHorizonNext, models.TimeHorizonSoon, models.TimeHorizonLater:
+			th = h
+		}
+	}
+	if len(missing) == 2 {
+		return nil, models.TimeHorizonSoon, missing, errors.New("analysis response has neither tags nor time_horizon")
 	}
-	return analysis.Tags, th, nil
+	return tags, th, missing, nil
 }
 
 // buildAndSendAnalysisRequest builds the prompt, sends the request, and returns the response content or an error.
 func (p *OpenAIProvider) buildAndSendAnalysisRequest(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) (string, error) {
-	prompt := p.buildAnalysisPrompt(text, dueDate, createdAt, userContext, tagStats)
+	prompt := p.buildAnalysisPrompt(text, dueDate, dueDateOnlyFromContext(ctx), priorityFromContext(ctx), createdAt, userContext, tagStats)
+	return p.sendAnalysisPrompt(ctx, prompt)
+}
+
+// dueDateOnlyFromContext reports whether the caller marked the due date as a calendar date without a time
+func dueDateOnlyFromContext(ctx context.Context) bool {
+	dateOnly, _ := ctx.Value(DueDateOnlyContextKey()).(bool)
+	return dateOnly
+}
+
+// priorityFromContext returns the priority the caller set for the todo, or "" when the user has not set one
+func priorityFromContext(ctx context.Context) models.Priority {
+	priority, _ := ctx.Value(PriorityContextKey()).(models.Priority)
+	return priority
+}
+
+// sendAnalysisPrompt sends an already-built analysis prompt and returns the response content or an error.
+func (p *OpenAIProvider) sendAnalysisPrompt(ctx context.Context, prompt string) (string, error) {
 	messages := []openai.ChatCompletionMessageParamUnion{
-		openai.SystemMessage("You are a helpful assistant that analyzes todo items and suggests tags and time horizons. Respond with valid JSON only."),
+		openai.SystemMessage(analysisSystemPrompt),
 		openai.UserMessage(prompt),
 	}
 	req := openai.ChatCompletionNewParams{
@@ -160,23 +217,32 @@ func (p *OpenAIProvider) doAnalysisAPIRequest(ctx context.Context, req openai.Ch
 	requestID := ExtractRequestID(ctx)
 	p.logAnalysisRequest("analyze_task", prompt, messages, userIDStr, todoIDStr, requestID)
 	start := time.Now()
-	resp, err := p.client.Chat.Completions.New(ctx, req)
+	resp, err := p.createChatCompletion(ctx, req, "analyze_task")
 	latency := time.Since(start)
 	if err != nil {
-		p.logAnalysisError("analyze_task", err, userIDStr, todoIDStr, requestID, latency)
-		if apiErr := ExtractAPIError(err); apiErr != nil {
-			return "", fmt.Errorf("failed to analyze task: %w", apiErr)
-		}
-		return "", fmt.Errorf("failed to analyze task: %w", err)
+		p.logError("analyze_task", err, userIDStr, todoIDStr, requestID, latency)
+		return "", fmt.Errorf("failed to analyze task: %w", mapOpenAIError(err))
 	}
 	if len(resp.Choices) == 0 {
 		return "", errors.New(ErrNoChoicesInResponse)
 	}
 	content := resp.Choices[0].Message.Content
-	p.logAnalysisResponse("analyze_task", content, userIDStr, todoIDStr, requestID, latency)
+	p.logResponse("analyze_task", content, userIDStr, todoIDStr, requestID, latency)
 	return content, nil
 }
 
+// createChatCompletion sends req and records its token usage. 5xx responses and transient network errors
+// have already been retried by the HTTP client's retryTransport; any error left is returned as is.
+func (p *OpenAIProvider) createChatCompletion(ctx context.Context, req openai.ChatCompletionNewParams, operation string) (resp *openai.ChatCompletion, err error) {
+	defer observeAICall(openAIProviderName, operation, time.Now(), &err)
+	resp, err = p.client.Chat.Completions.New(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	recordUsage(ctx, p.usageRecorder, operation, p.model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	return resp, nil
+}
+
 func (p *OpenAIProvider) logAnalysisRequest(operation, prompt string, messages []openai.ChatCompletionMessageParamUnion, userIDStr, todoIDStr, requestID string) {
 	if p.logger == nil || !p.debugMode {
 		return
@@ -193,37 +259,6 @@ func (p *OpenAIProvider) logAnalysisRequest(operation, prompt string, messages [
 	)
 }
 
-func (p *OpenAIProvider) logAnalysisError(operation string, err error, userIDStr, todoIDStr, requestID string, latency time.Duration) {
-	if p.logger == nil || !p.debugMode {
-		return
-	}
-	p.logger.Debug("llm_api_error",
-		zap.String("operation", operation),
-		zap.String("model", p.model),
-		zap.Error(err),
-		zap.String("user_id", userIDStr),
-		zap.String("todo_id", todoIDStr),
-		zap.String("request_id", requestID),
-		zap.Duration("latency_ms", latency),
-	)
-}
-
-func (p *OpenAIProvider) logAnalysisResponse(operation, content, userIDStr, todoIDStr, requestID string, latency time.Duration) {
-	if p.logger == nil || !p.debugMode {
-		return
-	}
-	p.logger.Debug("llm_api_response",
-		zap.String("operation", operation),
-		zap.String("model", p.model),
-		zap.Int("response_length", len(content)),
-		zap.String("response_preview", SanitizeResponse(content, true)),
-		zap.String("user_id", userIDStr),
-		zap.String("todo_id", todoIDStr),
-		zap.String("request_id", requestID),
-		zap.Int64("latency_ms", latency.Milliseconds()),
-	)
-}
-
 // AnalyzeTaskWithDueDate analyzes a task with an optional due date and creation time, returns suggested tags and time horizon.
 // tagStats is optional tag statistics to guide tag selection (prefer existing tags).
 func (p *OpenAIProvider) AnalyzeTaskWithDueDate(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
@@ -231,10 +266,11 @@ func (p *OpenAIProvider) AnalyzeTaskWithDueDate(ctx context.Context, text string
 	if err != nil {
 		return nil, models.TimeHorizonSoon, err
 	}
-	tags, th, err := parseAndValidateAnalysisResponse(content)
+	tags, th, missing, err := parseAndValidateAnalysisResponse(content)
 	if err != nil {
 		return nil, models.TimeHorizonSoon, err
 	}
+	p.warnIncompleteAnalysis(ctx, missing)
 	return tags, th, nil
 }
 
@@ -247,20 +283,17 @@ func (p *OpenAIProvider) Chat(ctx context.Context, messages []ChatMessage, userC
 	p.logChatRequest(openAIMessages, userIDStr, requestID, messages)
 	req := openai.ChatCompletionNewParams{Model: shared.ChatModel(p.model), Messages: openAIMessages}
 	startTime := time.Now()
-	resp, err := p.client.Chat.Completions.New(ctx, req)
+	resp, err := p.createChatCompletion(ctx, req, "chat")
 	latency := time.Since(startTime)
 	if err != nil {
-		p.logAnalysisError("chat", err, userIDStr, "", requestID, latency)
-		if apiErr := ExtractAPIError(err); apiErr != nil {
-			return nil, fmt.Errorf("failed to chat: %w", apiErr)
-		}
-		return nil, fmt.Errorf("failed to chat: %w", err)
+		p.logError("chat", err, userIDStr, "", requestID, latency)
+		return nil, fmt.Errorf("failed to chat: %w", mapOpenAIError(err))
 	}
 	if len(resp.Choices) == 0 {
 		return nil, errors.New(ErrNoChoicesInResponse)
 	}
 	content := resp.Choices[0].Message.Content
-	p.logAnalysisResponse("chat", content, userIDStr, "", requestID, latency)
+	p.logResponse("chat", content, userIDStr, "", requestID, latency)
 	return &ChatResponse{Message: content, NeedsUpdate: true}, nil
 }
 
@@ -336,20 +369,17 @@ func (p *OpenAIProvider) SummarizeContext(ctx context.Context, conversationHisto
 		MaxTokens: openai.Int(500),
 	}
 	startTime := time.Now()
-	resp, err := p.client.Chat.Completions.New(ctx, req)
+	resp, err := p.createChatCompletion(ctx, req, "summarize_context")
 	latency := time.Since(startTime)
 	if err != nil {
-		p.logAnalysisError("summarize_context", err, userIDStr, "", requestID, latency)
-		if apiErr := ExtractAPIError(err); apiErr != nil {
-			return "", fmt.Errorf("failed to summarize context: %w", apiErr)
-		}
-		return "", fmt.Errorf("failed to summarize context: %w", err)
+		p.logError("summarize_context", err, userIDStr, "", requestID, latency)
+		return "", fmt.Errorf("failed to summarize context: %w", mapOpenAIError(err))
 	}
 	if len(resp.Choices) == 0 {
 		return "", errors.New(ErrNoChoicesInResponse)
 	}
 	content := resp.Choices[0].Message.Content
-	p.logAnalysisResponse("summarize_context", content, userIDStr, "", requestID, latency)
+	p.logResponse("summarize_context", content, userIDStr, "", requestID, latency)
 	return content, nil
 }
 
@@ -361,6 +391,54 @@ func buildSummaryPrompt(conversationHistory []ChatMessage) string {
 	return prompt
 }
 
+// SummarizeWeek writes a short summary of what the user completed last week and what is still open
+func (p *OpenAIProvider) SummarizeWeek(ctx context.Context, completed, pending []string) (string, error) {
+	requestID := ExtractRequestID(ctx)
+	userIDStr := contextUserIDString(ctx)
+	prompt := buildWeeklySummaryPrompt(completed, pending)
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("You are a helpful assistant that writes brief, encouraging weekly summaries of a user's todo list. Use plain text, no more than a short paragraph."),
+		openai.UserMessage(prompt),
+	}
+	p.logSummarizeRequest(prompt, len(completed)+len(pending), userIDStr, requestID)
+	req := openai.ChatCompletionNewParams{
+		Model:     shared.ChatModel(p.model),
+		Messages:  messages,
+		MaxTokens: openai.Int(300),
+	}
+	startTime := time.Now()
+	resp, err := p.createChatCompletion(ctx, req, "summarize_week")
+	latency := time.Since(startTime)
+	if err != nil {
+		p.logError("summarize_week", err, userIDStr, "", requestID, latency)
+		return "", fmt.Errorf("failed to summarize week: %w", mapOpenAIError(err))
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New(ErrNoChoicesInResponse)
+	}
+	content := resp.Choices[0].Message.Content
+	p.logResponse("summarize_week", content, userIDStr, "", requestID, latency)
+	return content, nil
+}
+
+func buildWeeklySummaryPrompt(completed, pending []string) string {
+	prompt := "Summarize this user's week: what they got done and what is still waiting for them.\n\nCompleted last week:\n"
+	for _, text := range completed {
+		prompt += fmt.Sprintf("- %s\n", text)
+	}
+	if len(completed) == 0 {
+		prompt += "(nothing)\n"
+	}
+	prompt += "\nStill open:\n"
+	for _, text := range pending {
+		prompt += fmt.Sprintf("- %s\n", text)
+	}
+	if len(pending) == 0 {
+		prompt += "(nothing)\n"
+	}
+	return prompt
+}
+
 func (p *OpenAIProvider) logSummarizeRequest(prompt string, conversationLength int, userIDStr, requestID string) {
 	if p.logger == nil || !p.debugMode {
 		return
@@ -378,7 +456,7 @@ func (p *OpenAIProvider) logSummarizeRequest(prompt string, conversationLength i
 
 // estimateTokenCount provides a rough estimate of token count for a string
 // This uses a simple heuristic: ~4 characters per token (common for English text)
-// For more accurate counting, consider using a tokenizer library
+// It is the fallback for HeuristicTokenizer when no BPE encoding is available
 func estimateTokenCount(text string) int {
 	// Handle empty text explicitly
 	if len(text) == 0 {
@@ -387,44 +465,10 @@ func estimateTokenCount(text string) int {
 	return len(text) / 4
 }
 
-// calculateStringSimilarity calculates a simple similarity score between two strings
-// Returns a score between 0 and 1, where 1 means identical
-// This uses a basic approach: counts common words (case-insensitive)
-func calculateStringSimilarity(s1, s2 string) float64 {
-	// Convert to lowercase and split into words
-	words1 := strings.Fields(strings.ToLower(s1))
-	words2 := strings.Fields(strings.ToLower(s2))
-
-	if len(words1) == 0 || len(words2) == 0 {
-		return 0.0
-	}
-
-	// Create a set of words from s2 for faster lookup
-	word2Set := make(map[string]bool)
-	for _, word := range words2 {
-		word2Set[word] = true
-	}
-
-	// Count common words
-	commonCount := 0
-	for _, word := range words1 {
-		if word2Set[word] {
-			commonCount++
-		}
-	}
-
-	// Use Jaccard similarity: intersection / union
-	union := len(words1) + len(words2) - commonCount
-	if union == 0 {
-		return 0.0
-	}
-
-	return float64(commonCount) / float64(union)
-}
-
 // selectTagsForPrompt selects tags to include in the prompt using a smart algorithm
-// It combines frequently used tags with tags semantically similar to the todo text
-func (p *OpenAIProvider) selectTagsForPrompt(tagStats map[string]models.TagStats, todoText string) []string {
+// It combines frequently used tags with tags semantically similar to the todo text, scaled by the
+// user's tag weights so tags marked important rank above more frequent ones
+func (p *analysisPrompter) selectTagsForPrompt(tagStats map[string]models.TagStats, todoText string, userContext *models.AIContext) []string {
 	if len(tagStats) == 0 {
 		return nil
 	}
@@ -450,11 +494,12 @@ func (p *OpenAIProvider) selectTagsForPrompt(tagStats map[string]models.TagStats
 	tagList := make([]tagScore, 0, len(tagStats))
 	for tag, stats := range tagStats {
 		// Calculate similarity between tag and todo text
-		similarity := calculateStringSimilarity(tag, todoText)
+		similarity := p.similarity(tag, todoText)
 
-		// Combined score: frequency weight + similarity weight
+		// Combined score: frequency weight + similarity weight, times the user's weight for the tag
 		// Similarity is multiplied to make it comparable with frequency scores
 		score := float64(stats.Total)*TagScoreFrequencyWeight + similarity*TagScoreSimilarityMultiplier*TagScoreSimilarityWeight
+		score *= userContext.TagWeight(tag)
 
 		tagList = append(tagList, tagScore{
 			tag:        tag,
@@ -476,7 +521,7 @@ func (p *OpenAIProvider) selectTagsForPrompt(tagStats map[string]models.TagStats
 	for _, entry := range tagList {
 		// Build tag entry text to estimate its token count
 		tagText := fmt.Sprintf("- %s (used %d times)\n", entry.tag, entry.total)
-		entryTokens := estimateTokenCount(tagText)
+		entryTokens := p.countTokens(tagText)
 
 		// Check if adding this tag would exceed token limit
 		if estimatedTokens+entryTokens > maxTokens {
@@ -495,8 +540,31 @@ func (p *OpenAIProvider) selectTagsForPrompt(tagStats map[string]models.TagStats
 	return selectedTags
 }
 
-// buildAnalysisPrompt builds the prompt for task analysis with time context and tag statistics
-func (p *OpenAIProvider) buildAnalysisPrompt(text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) string {
+// SetTextNormalization sets how the todo text and tags are normalized before their similarity is scored
+// when choosing which tags to put in the prompt (default: DefaultTextNormalization)
+func (p *analysisPrompter) SetTextNormalization(normalization TextNormalization) {
+	p.normalization = &normalization
+}
+
+// similarity scores a tag against text with the configured normalization
+func (p *analysisPrompter) similarity(tag, text string) float64 {
+	if p.normalization == nil {
+		return DefaultTextNormalization.Similarity(tag, text)
+	}
+	return p.normalization.Similarity(tag, text)
+}
+
+// countTokens counts tokens with the configured tokenizer, or the heuristic if none is set
+func (p *analysisPrompter) countTokens(text string) int {
+	if p.tokenizer == nil {
+		return estimateTokenCount(text)
+	}
+	return p.tokenizer.CountTokens(text)
+}
+
+// buildAnalysisPrompt builds the prompt for task analysis with time context, the user's priority and tag
+// statistics. priority is "" when the user has not set one.
+func (p *analysisPrompter) buildAnalysisPrompt(text string, dueDate *time.Time, dueDateOnly bool, priority models.Priority, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) string {
 	now := time.Now()
 	prompt := fmt.Sprintf(`Analyze the following todo item and suggest:
 1. Relevant tags (as a JSON array of strings)
@@ -504,9 +572,12 @@ func (p *OpenAIProvider) buildAnalysisPrompt(text string, dueDate *time.Time, cr
 
 Todo item: "%s"`, text)
 	prompt += promptTimeContext(now, createdAt)
-	prompt += promptDueDateSection(dueDate, now)
+	prompt += promptDueDateSection(dueDate, dueDateOnly, now)
+	prompt += promptPrioritySection(priority)
 	prompt += analysisPromptJSONGuidelines()
-	prompt += p.promptTagStatsSection(tagStats, text)
+	prompt += p.promptTagStatsSection(tagStats, text, userContext)
+	prompt += promptTagAliasSection(userContext)
+	prompt += promptLanguageSection(p.outputLanguageFor(userContext), text)
 	if userContext != nil && userContext.ContextSummary != "" {
 		prompt += "\n\nUser preferences: " + userContext.ContextSummary
 	}
@@ -529,15 +600,16 @@ func promptTimeContext(now, createdAt time.Time) string {
 	return s
 }
 
-func promptDueDateSection(dueDate *time.Time, now time.Time) string {
+// promptDueDateSection describes the due date. dateOnly comes from how the user entered the date rather
+// than the time of day, so a midnight deadline in any timezone is still presented as a specific time.
+func promptDueDateSection(dueDate *time.Time, dateOnly bool, now time.Time) string {
 	if dueDate == nil {
 		return ""
 	}
-	daysUntil := int(dueDate.Sub(now).Hours() / 24)
-	isDateOnly := dueDate.Hour() == 0 && dueDate.Minute() == 0 && dueDate.Second() == 0 && dueDate.Nanosecond() == 0
+	daysUntil := models.DaysUntilDue(*dueDate, now)
 	s := "\n\nDue date:"
-	if isDateOnly {
-		s += fmt.Sprintf(" %s (date only, no specific time)", dueDate.Format("2006-01-02"))
+	if dateOnly {
+		s += fmt.Sprintf(" %s (date only, no specific time)", dueDate.UTC().Format(models.DueDateOnlyLayout))
 	} else {
 		s += fmt.Sprintf(" %s (specific time)", dueDate.Format(time.RFC3339))
 	}
@@ -553,15 +625,29 @@ func promptDueDateNote(daysUntil int) string {
 	if daysUntil == 0 {
 		return "\nNote: This item is due today."
 	}
-	if daysUntil <= 1 {
+	if daysUntil <= models.HorizonNextMaxDaysUntilDue {
 		return "\nNote: This item is due very soon (today or tomorrow)."
 	}
-	if daysUntil <= 7 {
+	if daysUntil <= models.HorizonSoonMaxDaysUntilDue {
 		return fmt.Sprintf("\nNote: This item is due in %d days (within a week).", daysUntil)
 	}
 	return ""
 }
 
+// promptPrioritySection describes the priority the user set, a signal for the time horizon that a due date
+// still outweighs
+func promptPrioritySection(priority models.Priority) string {
+	switch priority {
+	case models.PriorityUrgent, models.PriorityHigh:
+		return fmt.Sprintf("\n\nUser-set priority: %s\nNote: The user marked this item %s priority; lean toward a more immediate time horizon unless the due date clearly allows it to wait.", priority, priority)
+	case models.PriorityLow:
+		return fmt.Sprintf("\n\nUser-set priority: %s\nNote: The user marked this item low priority; lean toward a later time horizon unless the due date makes it pressing.", priority)
+	case models.PriorityMedium:
+		return fmt.Sprintf("\n\nUser-set priority: %s", priority)
+	}
+	return ""
+}
+
 func analysisPromptJSONGuidelines() string {
 	return `
 
@@ -583,17 +669,20 @@ When interpreting relative time expressions in the todo text (like "this weekend
 Return only valid JSON.`
 }
 
-func (p *OpenAIProvider) promptTagStatsSection(tagStats *models.TagStatistics, text string) string {
+func (p *analysisPrompter) promptTagStatsSection(tagStats *models.TagStatistics, text string, userContext *models.AIContext) string {
 	if tagStats == nil || len(tagStats.TagStats) == 0 {
 		return ""
 	}
 	s := "\n\nExisting tags (prefer reusing these when semantically similar):"
-	for _, tag := range p.selectTagsForPrompt(tagStats.TagStats, text) {
+	for _, tag := range p.selectTagsForPrompt(tagStats.TagStats, text, userContext) {
 		stats := tagStats.TagStats[tag]
 		s += fmt.Sprintf("\n- %s (used %d times", tag, stats.Total)
 		if stats.AI > 0 || stats.User > 0 {
 			s += fmt.Sprintf(", %d AI-generated, %d user-defined", stats.AI, stats.User)
 		}
+		if userContext.TagWeight(tag) > models.DefaultTagWeight {
+			s += ", marked important by the user"
+		}
 		s += ")"
 	}
 	s += "\n\nTag selection guidance:"
@@ -601,6 +690,44 @@ func (p *OpenAIProvider) promptTagStatsSection(tagStats *models.TagStatistics, t
 	s += "\n- Only create new tags if no existing tag is a good match (consider synonyms, related concepts, and variations)"
 	s += "\n- When an existing tag is close enough, use it rather than creating a new one"
 	s += "\n- This helps maintain consistency and reduces tag proliferation"
+	s += p.promptCoOccurrenceSection(tagStats, text)
+	return s
+}
+
+// promptCoOccurrenceSection lists the strongest tag pairs where one of the tags matches the todo text,
+// so the model can suggest the companion tag the user usually adds alongside it.
+func (p *analysisPrompter) promptCoOccurrenceSection(tagStats *models.TagStatistics, text string) string {
+	var lines []string
+	for _, pair := range tagStats.CoOccurrence {
+		if p.similarity(pair.A, text) == 0 && p.similarity(pair.B, text) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("\n- %s + %s (used together %d times)", pair.A, pair.B, pair.Count))
+		if len(lines) >= MaxCoOccurrenceHints {
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n\nTags often used together (if one applies, consider its companion):" + strings.Join(lines, "")
+}
+
+// promptTagAliasSection lists the user's tag aliases so the model suggests canonical tags directly.
+// Aliases returned anyway are rewritten by the analyzer before the todo is stored.
+func promptTagAliasSection(userContext *models.AIContext) string {
+	if userContext == nil || len(userContext.TagAliases) == 0 {
+		return ""
+	}
+	aliases := make([]string, 0, len(userContext.TagAliases))
+	for alias := range userContext.TagAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	s := "\n\nTag aliases (always use the canonical tag on the right instead of the alias):"
+	for _, alias := range aliases {
+		s += fmt.Sprintf("\n- %s -> %s", alias, userContext.TagAliases[alias])
+	}
 	return s
 }
 
@@ -615,6 +742,23 @@ func RegisterOpenAI(registry *ProviderRegistry) {
 		model := config["model"]
 		baseURL := config["base_url"]
 
-		return NewOpenAIProviderWithConfig(apiKey, baseURL, model), nil
+		provider := NewOpenAIProviderWithConfig(apiKey, baseURL, model)
+		if err := provider.configure(config); err != nil {
+			return nil, err
+		}
+		return provider, nil
 	})
 }
+
+// parseHTTPRetryConfig reads the optional http_retries registry setting, DefaultHTTPRetries when it is not given
+func parseHTTPRetryConfig(providerName string, config map[string]string) (int, error) {
+	v := config["http_retries"]
+	if v == "" {
+		return DefaultHTTPRetries, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s http_retries must be a non-negative integer (got %q)", providerName, v)
+	}
+	return n, nil
+}
@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// openAIProviderName labels errors mapped from the OpenAI API
+const openAIProviderName = "openai"
+
+// Default retry hints for OpenAI errors whose response carries no Retry-After header
+const (
+	openAIRateLimitRetryAfter = 60 * time.Second
+	openAIQuotaRetryAfter     = time.Hour
+)
+
+// mapOpenAIError maps an error from the OpenAI API (or an OpenAI-compatible endpoint) into a ProviderError.
+// Errors without an API response are mapped only when they are timeouts; other transport errors are
+// returned unchanged.
+func mapOpenAIError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return openAIResponseError(apiErr, err)
+	}
+	if legacy := ExtractAPIError(err); legacy != nil {
+		// Compatible endpoints may surface a 429 only in the error message
+		kind := AIErrorRateLimit
+		if legacy.IsPermanent {
+			kind = AIErrorQuota
+		}
+		return &ProviderError{Kind: kind, Provider: openAIProviderName, StatusCode: legacy.StatusCode, RetryAfter: legacy.RetryAfter, Err: legacy}
+	}
+	if isTimeoutError(err) {
+		return &ProviderError{Kind: AIErrorTimeout, Provider: openAIProviderName, Err: err}
+	}
+	return err
+}
+
+func openAIResponseError(apiErr *openai.Error, err error) *ProviderError {
+	provErr := &ProviderError{Kind: AIErrorUnknown, Provider: openAIProviderName, StatusCode: apiErr.StatusCode, Err: err}
+	switch status := apiErr.StatusCode; {
+	case status == http.StatusTooManyRequests && (apiErr.Code == "insufficient_quota" || apiErr.Type == "insufficient_quota"):
+		provErr.Kind = AIErrorQuota
+		provErr.RetryAfter = openAIRetryAfter(apiErr, openAIQuotaRetryAfter)
+	case status == http.StatusTooManyRequests:
+		provErr.Kind = AIErrorRateLimit
+		provErr.RetryAfter = openAIRetryAfter(apiErr, openAIRateLimitRetryAfter)
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		provErr.Kind = AIErrorTimeout
+	case status >= 500:
+		provErr.Kind = AIErrorServer
+	case status >= 400:
+		provErr.Kind = AIErrorInvalidRequest
+	}
+	return provErr
+}
+
+// openAIRetryAfter reads the Retry-After header (in seconds) of the error response, or returns fallback
+func openAIRetryAfter(apiErr *openai.Error, fallback time.Duration) *time.Duration {
+	if apiErr.Response != nil {
+		if secs, err := strconv.Atoi(apiErr.Response.Header.Get("Retry-After")); err == nil && secs > 0 {
+			d := time.Duration(secs) * time.Second
+			return &d
+		}
+	}
+	return &fallback
+}
@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// newOpenAIAPIError builds an SDK error as returned for an HTTP error response
+func newOpenAIAPIError(status int, code string, header http.Header) error {
+	req, _ := http.NewRequest(http.MethodPost, "https://api.openai.com/v1/chat/completions", nil)
+	if header == nil {
+		header = http.Header{}
+	}
+	return &openai.Error{
+		Code:       code,
+		StatusCode: status,
+		Request:    req,
+		Response:   &http.Response{StatusCode: status, Header: header},
+	}
+}
+
+func TestMapOpenAIError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		err            error
+		wantKind       AIErrorKind
+		wantRetryAfter time.Duration
+	}{
+		{"rate limit", newOpenAIAPIError(429, "rate_limit_exceeded", nil), AIErrorRateLimit, openAIRateLimitRetryAfter},
+		{"rate limit with Retry-After", newOpenAIAPIError(429, "", http.Header{"Retry-After": []string{"120"}}), AIErrorRateLimit, 2 * time.Minute},
+		{"insufficient quota", newOpenAIAPIError(429, "insufficient_quota", nil), AIErrorQuota, openAIQuotaRetryAfter},
+		{"bad request", newOpenAIAPIError(400, "context_length_exceeded", nil), AIErrorInvalidRequest, 0},
+		{"invalid api key", newOpenAIAPIError(401, "invalid_api_key", nil), AIErrorInvalidRequest, 0},
+		{"unknown model", newOpenAIAPIError(404, "model_not_found", nil), AIErrorInvalidRequest, 0},
+		{"server error", newOpenAIAPIError(500, "", nil), AIErrorServer, 0},
+		{"overloaded", newOpenAIAPIError(503, "", nil), AIErrorServer, 0},
+		{"gateway timeout", newOpenAIAPIError(504, "", nil), AIErrorTimeout, 0},
+		{"wrapped sdk error", fmt.Errorf("request: %w", newOpenAIAPIError(502, "", nil)), AIErrorServer, 0},
+		{"deadline exceeded", &url.Error{Op: "Post", URL: "https://api.openai.com", Err: context.DeadlineExceeded}, AIErrorTimeout, 0},
+		{"429 in a compatible endpoint's message", errors.New(`POST "http://llm.local/v1/chat/completions": 429 Too Many Requests`), AIErrorRateLimit, 60 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mapped := mapOpenAIError(tt.err)
+			var provErr *ProviderError
+			if !errors.As(mapped, &provErr) {
+				t.Fatalf("mapOpenAIError(%v) = %v, want a *ProviderError", tt.err, mapped)
+			}
+			if provErr.Kind != tt.wantKind || provErr.Provider != openAIProviderName {
+				t.Errorf("kind = %s (provider %q), want %s (provider %q)", provErr.Kind, provErr.Provider, tt.wantKind, openAIProviderName)
+			}
+			if got := ClassifyError(fmt.Errorf("failed to analyze task: %w", mapped)); got != tt.wantKind {
+				t.Errorf("ClassifyError() of the wrapped error = %s, want %s", got, tt.wantKind)
+			}
+			switch {
+			case tt.wantRetryAfter == 0 && provErr.RetryAfter != nil:
+				t.Errorf("RetryAfter = %v, want none", *provErr.RetryAfter)
+			case tt.wantRetryAfter != 0 && (provErr.RetryAfter == nil || *provErr.RetryAfter != tt.wantRetryAfter):
+				t.Errorf("RetryAfter = %v, want %v", provErr.RetryAfter, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestMapOpenAIError_Unmapped(t *testing.T) {
+	t.Parallel()
+
+	if mapOpenAIError(nil) != nil {
+		t.Error("mapOpenAIError(nil) should be nil")
+	}
+	plain := errors.New("connection closed")
+	if got := mapOpenAIError(plain); got != plain {
+		t.Errorf("mapOpenAIError(%v) = %v, want the error unchanged", plain, got)
+	}
+}
+
+func TestOpenAIProvider_RequestTimeout(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	provider := NewOpenAIProviderWithLogger("test-key", server.URL, "", nil, false, 50*time.Millisecond)
+	provider.SetHTTPRetries(0)
+	_, err := provider.Chat(context.Background(), []ChatMessage{{Role: "user", Content: "hi"}}, nil)
+	if err == nil {
+		t.Fatal("Chat() error = nil, want a timeout")
+	}
+	if got := ClassifyError(err); got != AIErrorTimeout {
+		t.Errorf("ClassifyError() = %s, want %s", got, AIErrorTimeout)
+	}
+	if IsRateLimitError(err) {
+		t.Errorf("timeout %v reported as a rate limit", err)
+	}
+}
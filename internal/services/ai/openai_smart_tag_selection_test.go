@@ -38,7 +38,7 @@ func TestSelectTagsForPrompt_PrioritizesSimilarTags(t *testing.T) {
 	}
 
 	// Test with "buy groceries" - should prioritize "shopping" and "groceries" due to similarity
-	selectedTags := provider.selectTagsForPrompt(tagStats, "buy groceries")
+	selectedTags := provider.selectTagsForPrompt(tagStats, "buy groceries", nil)
 
 	if len(selectedTags) == 0 {
 		t.Error("Expected to select at least some tags")
@@ -71,7 +71,7 @@ func TestSelectTagsForPrompt_RespectsMaxTagsLimit(t *testing.T) {
 		"urgent":   {Total: 3},
 	}
 
-	selectedTags := provider.selectTagsForPrompt(tagStats, "finish report")
+	selectedTags := provider.selectTagsForPrompt(tagStats, "finish report", nil)
 
 	if len(selectedTags) > 2 {
 		t.Errorf("Expected at most 2 tags, got %d: %v", len(selectedTags), selectedTags)
@@ -91,7 +91,7 @@ func TestSelectTagsForPrompt_RespectsTokenLimit(t *testing.T) {
 		"urgent":   {Total: 3},
 	}
 
-	selectedTags := provider.selectTagsForPrompt(tagStats, "finish report")
+	selectedTags := provider.selectTagsForPrompt(tagStats, "finish report", nil)
 
 	// Should select fewer tags due to token limit
 	if len(selectedTags) >= len(tagStats) {
@@ -129,6 +129,8 @@ func TestBuildAnalysisPrompt_UsesSmartTagSelection(t *testing.T) {
 	prompt := provider.buildAnalysisPrompt(
 		"schedule team meeting",
 		nil,
+		false,
+		"",
 		time.Now(),
 		nil,
 		tagStats,
@@ -175,7 +177,7 @@ func TestEstimateTokenCount(t *testing.T) {
 	}
 }
 
-func TestCalculateStringSimilarity(t *testing.T) {
+func TestTextNormalization_Similarity(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
@@ -215,17 +217,74 @@ func TestCalculateStringSimilarity(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := calculateStringSimilarity(tt.s1, tt.s2)
+			result := DefaultTextNormalization.Similarity(tt.s1, tt.s2)
 			if result < 0 || result > 1 {
-				t.Errorf("calculateStringSimilarity(%q, %q) = %f, expected value between 0 and 1", tt.s1, tt.s2, result)
+				t.Errorf("Similarity(%q, %q) = %f, expected value between 0 and 1", tt.s1, tt.s2, result)
 			}
 			if tt.checkExact {
 				if result != tt.exactScore {
-					t.Errorf("calculateStringSimilarity(%q, %q) = %f, expected exactly %f", tt.s1, tt.s2, result, tt.exactScore)
+					t.Errorf("Similarity(%q, %q) = %f, expected exactly %f", tt.s1, tt.s2, result, tt.exactScore)
 				}
 			} else if result < tt.minScore {
-				t.Errorf("calculateStringSimilarity(%q, %q) = %f, expected at least %f", tt.s1, tt.s2, result, tt.minScore)
+				t.Errorf("Similarity(%q, %q) = %f, expected at least %f", tt.s1, tt.s2, result, tt.minScore)
 			}
 		})
 	}
 }
+
+func TestSelectTagsForPrompt_TagWeights(t *testing.T) {
+	t.Parallel()
+
+	tagStats := map[string]models.TagStats{
+		"work":    {Total: 20},
+		"home":    {Total: 12},
+		"finance": {Total: 5},
+	}
+
+	tests := []struct {
+		name      string
+		weights   map[string]float64
+		maxTags   int
+		wantFirst string
+		wantLast  string
+	}{
+		{"default weights rank by frequency", nil, 3, "work", "finance"},
+		{"weighted tag outranks more frequent tag", map[string]float64{"finance": 5}, 3, "finance", "home"},
+		{"down-weighted tag drops below less frequent tag", map[string]float64{"work": 0.1}, 3, "home", "work"},
+		{"weighted tag survives a tight tag limit", map[string]float64{"finance": 5}, 1, "finance", "finance"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			provider := NewOpenAIProvider("test-key", "")
+			provider.maxTagsInPrompt = tt.maxTags
+
+			selected := provider.selectTagsForPrompt(tagStats, "unrelated", &models.AIContext{TagWeights: tt.weights})
+			if len(selected) != tt.maxTags {
+				t.Fatalf("selected %v, want %d tags", selected, tt.maxTags)
+			}
+			if selected[0] != tt.wantFirst || selected[len(selected)-1] != tt.wantLast {
+				t.Errorf("selected %v, want %s first and %s last", selected, tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestBuildAnalysisPrompt_MarksWeightedTags(t *testing.T) {
+	t.Parallel()
+
+	provider := NewOpenAIProvider("test-key", "")
+	tagStats := &models.TagStatistics{TagStats: map[string]models.TagStats{"work": {Total: 20}, "finance": {Total: 5}}}
+	userContext := &models.AIContext{TagWeights: map[string]float64{"finance": 5}}
+
+	prompt := provider.buildAnalysisPrompt("pay invoices", nil, false, "", time.Now(), userContext, tagStats)
+	finance := strings.Index(prompt, "- finance (used 5 times, marked important by the user)")
+	work := strings.Index(prompt, "- work (used 20 times)")
+	if finance < 0 || work < 0 {
+		t.Fatalf("prompt missing weighted tag entries:\n%s", prompt)
+	}
+	if finance > work {
+		t.Error("expected the weighted tag to be listed before the more frequent tag")
+	}
+}
@@ -39,6 +39,8 @@ func TestBuildAnalysisPrompt_IncludesTagStatistics(t *testing.T) {
 	prompt := provider.buildAnalysisPrompt(
 		"Buy groceries",
 		nil,
+		false,
+		"",
 		time.Now(),
 		nil,
 		tagStats,
@@ -116,6 +118,8 @@ func TestBuildAnalysisPrompt_HandlesNilTagStatistics(t *testing.T) {
 	prompt := provider.buildAnalysisPrompt(
 		"Buy groceries",
 		nil,
+		false,
+		"",
 		time.Now(),
 		nil,
 		nil, // No tag statistics
@@ -141,6 +145,8 @@ func TestBuildAnalysisPrompt_HandlesEmptyTagStatistics(t *testing.T) {
 	prompt := provider.buildAnalysisPrompt(
 		"Buy groceries",
 		nil,
+		false,
+		"",
 		time.Now(),
 		nil,
 		tagStats,
@@ -151,3 +157,38 @@ func TestBuildAnalysisPrompt_HandlesEmptyTagStatistics(t *testing.T) {
 		t.Error("Expected prompt to NOT include 'Existing tags' section when tagStats is empty")
 	}
 }
+
+func TestBuildAnalysisPrompt_IncludesRelevantCoOccurrence(t *testing.T) {
+	t.Parallel()
+
+	provider := &OpenAIProvider{}
+	tagStats := &models.TagStatistics{
+		TagStats: map[string]models.TagStats{
+			"work":    {Total: 10},
+			"urgent":  {Total: 6},
+			"garden":  {Total: 4},
+			"weekend": {Total: 4},
+		},
+		CoOccurrence: []models.TagPair{
+			{A: "urgent", B: "work", Count: 5},
+			{A: "garden", B: "weekend", Count: 3},
+		},
+	}
+
+	prompt := provider.buildAnalysisPrompt("Finish the work report", nil, false, "", time.Now(), nil, tagStats)
+
+	if !strings.Contains(prompt, "Tags often used together") {
+		t.Fatal("Expected prompt to include co-occurrence section")
+	}
+	if !strings.Contains(prompt, "urgent + work (used together 5 times)") {
+		t.Error("Expected pair matching the todo text to be included")
+	}
+	if strings.Contains(prompt, "garden + weekend") {
+		t.Error("Expected unrelated pair to be omitted")
+	}
+
+	prompt = provider.buildAnalysisPrompt("Call the dentist", nil, false, "", time.Now(), nil, tagStats)
+	if strings.Contains(prompt, "Tags often used together") {
+		t.Error("Expected no co-occurrence section when no pair matches the todo text")
+	}
+}
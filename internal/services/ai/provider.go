@@ -9,7 +9,8 @@ import (
 
 // AIProvider is the interface for AI providers
 type AIProvider interface {
-	// AnalyzeTask analyzes a task and returns suggested tags and time horizon
+	// AnalyzeTask analyzes a task and returns suggested tags and time horizon. Nil tags mean the model gave
+	// no tags at all and the todo's existing tags should be kept; an empty non-nil slice means no tags apply.
 	AnalyzeTask(ctx context.Context, text string, userContext *models.AIContext) ([]string, models.TimeHorizon, error)
 
 	// Chat handles a chat message and returns the AI response
@@ -28,6 +29,12 @@ type AIProviderWithDueDate interface {
 	AnalyzeTaskWithDueDate(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error)
 }
 
+// WeeklySummarizer is an optional interface for providers that can write a user's weekly summary
+type WeeklySummarizer interface {
+	// SummarizeWeek summarizes the todos completed last week and the ones still open
+	SummarizeWeek(ctx context.Context, completed, pending []string) (string, error)
+}
+
 // ChatMessage represents a message in a chat conversation
 type ChatMessage struct {
 	Role    string `json:"role"` // "user" or "assistant"
@@ -0,0 +1,232 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// providerBase is what the providers share: the analysis prompt, the HTTP client with its retryTransport,
+// usage reporting and debug logging
+type providerBase struct {
+	analysisPrompter
+	// name labels the provider's metrics, errors and logs
+	name          string
+	httpClient    *http.Client
+	model         string
+	usageRecorder UsageRecorder
+	logger        *zap.Logger
+	debugMode     bool
+}
+
+// newProviderBase returns a base whose HTTP client times calls out after timeout and retries them
+// DefaultHTTPRetries times
+func newProviderBase(name, model string, timeout time.Duration, logger *zap.Logger, debugMode bool) providerBase {
+	return providerBase{
+		analysisPrompter: newAnalysisPrompter(),
+		name:             name,
+		httpClient:       &http.Client{Timeout: timeout, Transport: newRetryTransport(nil, DefaultHTTPRetries, DefaultHTTPRetryBackoff, logger)},
+		model:            model,
+		logger:           logger,
+		debugMode:        debugMode,
+	}
+}
+
+// SetTokenizer sets the tokenizer used to budget the tag list in analysis prompts (default: heuristic)
+func (p *providerBase) SetTokenizer(tokenizer Tokenizer) {
+	if tokenizer != nil {
+		p.tokenizer = tokenizer
+	}
+}
+
+// SetUsageRecorder sets where the token usage of each successful call is reported (default: nowhere)
+func (p *providerBase) SetUsageRecorder(recorder UsageRecorder) {
+	p.usageRecorder = recorder
+}
+
+// SetHTTPRetries sets how many times an HTTP request to the API is retried, with jittered exponential
+// backoff, after a 5xx response or a transient network error. 4xx responses, including rate limits, are
+// never retried here.
+func (p *providerBase) SetHTTPRetries(retries int) {
+	p.httpClient.Transport = newRetryTransport(nil, retries, DefaultHTTPRetryBackoff, p.logger)
+}
+
+// SetOutputLanguage sets the default language for suggested tags: a language name such as "Spanish",
+// OutputLanguageAuto to follow each todo's language, or "" for no instruction. Users can override it
+// with the output_language preference.
+func (p *providerBase) SetOutputLanguage(language string) {
+	p.outputLanguage = normalizeOutputLanguage(language)
+	if p.outputLanguage == "" && strings.TrimSpace(language) != "" && p.logger != nil {
+		p.logger.Warn("ignoring_invalid_output_language", zap.String("language", language))
+	}
+}
+
+// configure applies the tokenizer, output_language and http_retries registry settings
+func (p *providerBase) configure(config map[string]string) error {
+	tokenizer, err := NewTokenizer(config["tokenizer"], p.model, nil)
+	if err != nil {
+		return err
+	}
+	p.SetTokenizer(tokenizer)
+	p.SetOutputLanguage(config["output_language"])
+	retries, err := parseHTTPRetryConfig(p.name, config)
+	if err != nil {
+		return err
+	}
+	p.SetHTTPRetries(retries)
+	return nil
+}
+
+// warnIncompleteAnalysis logs an analysis response that lacked some fields
+func (p *providerBase) warnIncompleteAnalysis(ctx context.Context, missing []string) {
+	if len(missing) == 0 || p.logger == nil {
+		return
+	}
+	userIDStr, todoIDStr := contextIDStrings(ctx)
+	p.logger.Warn("incomplete_analysis_response",
+		zap.String("model", p.model),
+		zap.Strings("missing_fields", missing),
+		zap.String("user_id", userIDStr),
+		zap.String("todo_id", todoIDStr),
+		zap.String("request_id", ExtractRequestID(ctx)),
+	)
+}
+
+// jsonCall is one call of a provider's JSON API
+type jsonCall struct {
+	operation string
+	// prompt and messageCount are only logged
+	prompt       string
+	messageCount int
+	url          string
+	header       http.Header
+	body         any
+	// transportError maps a request that got no API response
+	transportError func(error) error
+	// responseError maps a non-2xx API response
+	responseError func(resp *http.Response, body []byte) error
+}
+
+// jsonResponse is a decoded API response that reports its text and token usage
+type jsonResponse interface {
+	content() string
+	usage() (promptTokens, completionTokens int64)
+}
+
+// sendJSON posts call and decodes the response into out, returning its text. It reports the call's
+// metrics and usage and logs it in debug mode. 5xx responses and transient network errors have already
+// been retried by the HTTP client's retryTransport.
+func sendJSON[R jsonResponse](ctx context.Context, p *providerBase, call jsonCall, out R) (string, error) {
+	userIDStr, todoIDStr := contextIDStrings(ctx)
+	requestID := ExtractRequestID(ctx)
+	p.logRequest(call, userIDStr, todoIDStr, requestID)
+
+	body, err := json.Marshal(call.body)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	start := time.Now()
+	err = p.postJSON(ctx, call, body, out)
+	latency := time.Since(start)
+	if err != nil {
+		p.logError(call.operation, err, userIDStr, todoIDStr, requestID, latency)
+		return "", err
+	}
+	promptTokens, completionTokens := out.usage()
+	recordUsage(ctx, p.usageRecorder, call.operation, p.model, promptTokens, completionTokens)
+	content := out.content()
+	if content == "" {
+		return "", errors.New(ErrNoContentInResponse)
+	}
+	p.logResponse(call.operation, content, userIDStr, todoIDStr, requestID, latency)
+	return content, nil
+}
+
+// postJSON sends one request, decoding a 2xx response into out
+func (p *providerBase) postJSON(ctx context.Context, call jsonCall, body []byte, out any) (err error) {
+	defer observeAICall(p.name, call.operation, time.Now(), &err)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, call.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, vv := range call.header {
+		httpReq.Header[k] = vv
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return call.transportError(err)
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return call.transportError(err)
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return call.responseError(httpResp, respBody)
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func (p *providerBase) logRequest(call jsonCall, userIDStr, todoIDStr, requestID string) {
+	if p.logger == nil || !p.debugMode {
+		return
+	}
+	p.logger.Debug("llm_api_request",
+		zap.String("operation", call.operation),
+		zap.String("provider", p.name),
+		zap.String("model", p.model),
+		zap.Int("prompt_length", len(call.prompt)),
+		zap.Int("message_count", call.messageCount),
+		zap.String("prompt_preview", SanitizePrompt(call.prompt, call.operation == "analyze_task")),
+		zap.String("user_id", userIDStr),
+		zap.String("todo_id", todoIDStr),
+		zap.String("request_id", requestID),
+	)
+}
+
+func (p *providerBase) logError(operation string, err error, userIDStr, todoIDStr, requestID string, latency time.Duration) {
+	if p.logger == nil || !p.debugMode {
+		return
+	}
+	p.logger.Debug("llm_api_error",
+		zap.String("operation", operation),
+		zap.String("provider", p.name),
+		zap.String("model", p.model),
+		zap.Error(err),
+		zap.String("user_id", userIDStr),
+		zap.String("todo_id", todoIDStr),
+		zap.String("request_id", requestID),
+		zap.Duration("latency_ms", latency),
+	)
+}
+
+func (p *providerBase) logResponse(operation, content, userIDStr, todoIDStr, requestID string, latency time.Duration) {
+	if p.logger == nil || !p.debugMode {
+		return
+	}
+	p.logger.Debug("llm_api_response",
+		zap.String("operation", operation),
+		zap.String("provider", p.name),
+		zap.String("model", p.model),
+		zap.Int("response_length", len(content)),
+		zap.String("response_preview", SanitizeResponse(content, true)),
+		zap.String("user_id", userIDStr),
+		zap.String("todo_id", todoIDStr),
+		zap.String("request_id", requestID),
+		zap.Int64("latency_ms", latency.Milliseconds()),
+	)
+}
@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultHTTPRetries is how many times a provider HTTP request is retried after a 5xx response or a
+	// transient network error (AI_MAX_HTTP_RETRIES)
+	DefaultHTTPRetries = 2
+	// DefaultHTTPRetryBackoff is the delay before the first HTTP retry; it doubles for each further retry
+	// and a random jitter of up to half the delay is taken off
+	DefaultHTTPRetryBackoff = 200 * time.Millisecond
+	// maxRetryDrainBytes bounds how much of a failed response body is read so its connection can be reused
+	maxRetryDrainBytes = 64 << 10
+)
+
+// retryTransport retries provider requests that failed with a 5xx response or a transient network error,
+// with exponential backoff and jitter. 4xx responses, including 429 rate limits, are returned at once so
+// the provider maps them and the worker applies its queue-level backoff. Requests whose body cannot be
+// replayed are never retried, and no retry is started that would outlast the request's deadline.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	backoff    time.Duration
+	logger     *zap.Logger
+	// jitter returns a random duration in [0, n); replaced in tests
+	jitter func(n time.Duration) time.Duration
+}
+
+// newRetryTransport wraps base (http.DefaultTransport if nil) to retry up to maxRetries times
+func newRetryTransport(base http.RoundTripper, maxRetries int, backoff time.Duration, logger *zap.Logger) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{
+		base:       base,
+		maxRetries: max(maxRetries, 0),
+		backoff:    max(backoff, 0),
+		logger:     logger,
+		jitter:     func(n time.Duration) time.Duration { return rand.N(n) },
+	}
+}
+
+// RoundTrip sends req, retrying retryable failures
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(attemptReq)
+		if attempt >= t.maxRetries || !retryableHTTPResult(resp, err) || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			return resp, err
+		}
+		delay := t.delay(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+		next := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			next.Body = body
+		}
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+			_, _ = io.CopyN(io.Discard, resp.Body, maxRetryDrainBytes)
+			_ = resp.Body.Close()
+		}
+		if t.logger != nil {
+			t.logger.Warn("llm_api_http_retrying",
+				zap.String("host", req.URL.Host),
+				zap.Int("attempt", attempt+1),
+				zap.Int("status", status),
+				zap.Duration("backoff", delay),
+				zap.Error(err),
+			)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		attemptReq = next
+	}
+}
+
+// delay is the backoff before retry attempt+1: backoff doubled per attempt, less up to half of it as jitter
+func (t *retryTransport) delay(attempt int) time.Duration {
+	d := t.backoff << retryShiftAmount(attempt)
+	if half := d / 2; half > 0 {
+		d -= t.jitter(half)
+	}
+	return d
+}
+
+// retryableHTTPResult reports whether a round trip failed in a way worth retrying: a 5xx response or a
+// transient network error. Caller cancellation and deadlines are not retried.
+func retryableHTTPResult(resp *http.Response, err error) bool {
+	if err != nil {
+		return IsTransientNetworkError(err)
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
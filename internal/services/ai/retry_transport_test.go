@@ -0,0 +1,156 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		statuses   []int // responses in order; the last one repeats
+		retries    int
+		wantCalls  int32
+		wantStatus int
+	}{
+		{name: "success is not retried", statuses: []int{http.StatusOK}, retries: 2, wantCalls: 1, wantStatus: http.StatusOK},
+		{name: "server error then success", statuses: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK}, retries: 2, wantCalls: 3, wantStatus: http.StatusOK},
+		{name: "gives up after retry budget", statuses: []int{http.StatusServiceUnavailable}, retries: 2, wantCalls: 3, wantStatus: http.StatusServiceUnavailable},
+		{name: "retries disabled", statuses: []int{http.StatusInternalServerError}, retries: 0, wantCalls: 1, wantStatus: http.StatusInternalServerError},
+		{name: "rate limit is not retried", statuses: []int{http.StatusTooManyRequests, http.StatusOK}, retries: 2, wantCalls: 1, wantStatus: http.StatusTooManyRequests},
+		{name: "bad request is not retried", statuses: []int{http.StatusBadRequest, http.StatusOK}, retries: 2, wantCalls: 1, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1))
+				if body, _ := io.ReadAll(r.Body); string(body) != `{"prompt":"hi"}` {
+					t.Errorf("attempt %d body = %q, want the original body", n, body)
+				}
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses))-1])
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: newRetryTransport(nil, tt.retries, time.Millisecond, nil)}
+			resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"prompt":"hi"}`))
+			if err != nil {
+				t.Fatalf("Post() error = %v", err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("server calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_RespectsDeadline(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	// The first backoff (at least 500ms) does not fit in the deadline, so the 500 is returned at once
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	client := &http.Client{Transport: newRetryTransport(nil, 3, time.Second, nil)}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError || calls.Load() != 1 {
+		t.Errorf("status %d after %d calls, want 500 after 1", resp.StatusCode, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Do() took %v, want no wait for a retry past the deadline", elapsed)
+	}
+}
+
+func TestRetryTransport_Delay(t *testing.T) {
+	t.Parallel()
+
+	transport := newRetryTransport(nil, 3, 100*time.Millisecond, nil)
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond} {
+		transport.jitter = func(n time.Duration) time.Duration { return 0 }
+		if got := transport.delay(attempt); got != want {
+			t.Errorf("delay(%d) without jitter = %v, want %v", attempt, got, want)
+		}
+		transport.jitter = func(n time.Duration) time.Duration { return n - 1 }
+		if got := transport.delay(attempt); got <= want/2 || got > want {
+			t.Errorf("delay(%d) with full jitter = %v, want in (%v, %v]", attempt, got, want/2, want)
+		}
+	}
+}
+
+func TestProviders_RateLimitNotRetriedByTransport(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`))
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProviderWithLogger("test-key", server.URL, "", nil, false, 0)
+	provider.SetHTTPRetries(3)
+	_, _, err := provider.AnalyzeTask(context.Background(), "Write report", nil)
+	if !IsRateLimitError(err) {
+		t.Errorf("AnalyzeTask() error = %v, want a rate limit error", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server calls = %d, want 1", got)
+	}
+}
+
+func TestOpenAIProvider_ServerErrorRetried(t *testing.T) {
+	t.Parallel()
+
+	const completion = `{"id":"c1","object":"chat.completion","created":1,"model":"gpt-4o-mini","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"tags\":[\"work\"],\"time_horizon\":\"soon\"}"}}]}`
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`{"error":{"message":"upstream","type":"server_error"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(completion))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProviderWithConfig("test-key", server.URL, "gpt-4o-mini")
+	tags, _, err := provider.AnalyzeTask(context.Background(), "Write report", nil)
+	if err != nil || len(tags) != 1 || tags[0] != "work" {
+		t.Fatalf("AnalyzeTask() = %v, %v; want [work] after the 502 is retried", tags, err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server calls = %d, want 2", got)
+	}
+}
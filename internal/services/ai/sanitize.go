@@ -16,6 +16,8 @@ const (
 	userIDContextKey    contextKey = "user_id"
 	todoIDContextKey    contextKey = "todo_id"
 	requestIDContextKey contextKey = "request_id"
+	dueDateOnlyContext  contextKey = "due_date_only"
+	priorityContext     contextKey = "priority"
 )
 
 // UserIDContextKey returns the context key for user ID
@@ -33,6 +35,18 @@ func RequestIDContextKey() contextKey {
 	return requestIDContextKey
 }
 
+// DueDateOnlyContextKey returns the context key for whether the due date passed to analysis is a
+// calendar date without a specific time (bool)
+func DueDateOnlyContextKey() contextKey {
+	return dueDateOnlyContext
+}
+
+// PriorityContextKey returns the context key for the priority the user set on the todo passed to analysis
+// (models.Priority)
+func PriorityContextKey() contextKey {
+	return priorityContext
+}
+
 const (
 	// MaxPreviewLength is the maximum length for preview strings in logs
 	MaxPreviewLength = 200
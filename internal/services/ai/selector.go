@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"go.uber.org/zap"
+)
+
+// ProviderBuilder creates a provider instance for a provider name and model
+type ProviderBuilder func(provider, model string) (AIProvider, error)
+
+// ProviderSelector resolves the AI provider for a user from their model preference. Only provider/model
+// pairs on the allow-list are honored; everything else uses the default provider. Instances are cached
+// per provider/model pair so each is built at most once.
+type ProviderSelector struct {
+	defaultProvider AIProvider
+	defaultName     string
+	defaultModel    string
+	build           ProviderBuilder
+	allowed         map[string]bool
+	logger          *zap.Logger
+
+	mu    sync.Mutex
+	cache map[string]AIProvider
+}
+
+// NewProviderSelector creates a selector. allowed entries are "provider:model", or just "model" for the
+// default provider; with no entries every user gets the default provider.
+func NewProviderSelector(defaultProvider AIProvider, defaultName, defaultModel string, build ProviderBuilder, allowed []string, logger *zap.Logger) *ProviderSelector {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	s := &ProviderSelector{
+		defaultProvider: defaultProvider,
+		defaultName:     defaultName,
+		defaultModel:    defaultModel,
+		build:           build,
+		allowed:         make(map[string]bool, len(allowed)),
+		logger:          logger,
+		cache:           make(map[string]AIProvider),
+	}
+	for _, entry := range allowed {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, model, ok := strings.Cut(entry, ":")
+		if !ok {
+			name, model = defaultName, entry
+		}
+		s.allowed[providerKey(name, model)] = true
+	}
+	return s
+}
+
+// ProviderFor returns the provider for the user's preference, or the default provider when the user has
+// no preference, the preference is not allowed, or the provider cannot be built.
+func (s *ProviderSelector) ProviderFor(userContext *models.AIContext) AIProvider {
+	pref := userContext.AIModelPreference()
+	if pref.IsZero() {
+		return s.defaultProvider
+	}
+	name, model := pref.Provider, pref.Model
+	if name == "" {
+		name = s.defaultName
+	}
+	if model == "" {
+		model = s.defaultModel
+	}
+	if name == s.defaultName && model == s.defaultModel {
+		return s.defaultProvider
+	}
+	key := providerKey(name, model)
+	if !s.allowed[key] || s.build == nil {
+		return s.defaultProvider
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if provider, ok := s.cache[key]; ok {
+		return provider
+	}
+	provider, err := s.build(name, model)
+	if err != nil {
+		s.logger.Warn("failed_to_build_user_ai_provider",
+			zap.String("provider", name),
+			zap.String("model", model),
+			zap.Error(err),
+		)
+		provider = s.defaultProvider
+	}
+	s.cache[key] = provider
+	return provider
+}
+
+func providerKey(provider, model string) string {
+	return strings.ToLower(provider) + ":" + model
+}
@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+)
+
+type namedProvider struct {
+	name string
+}
+
+func (p *namedProvider) AnalyzeTask(ctx context.Context, text string, userContext *models.AIContext) ([]string, models.TimeHorizon, error) {
+	return nil, "", nil
+}
+
+func (p *namedProvider) Chat(ctx context.Context, messages []ChatMessage, userContext *models.AIContext) (*ChatResponse, error) {
+	return nil, nil
+}
+
+func (p *namedProvider) SummarizeContext(ctx context.Context, conversation []ChatMessage) (string, error) {
+	return "", nil
+}
+
+func contextWithPreference(provider, model string) *models.AIContext {
+	prefs := map[string]any{}
+	if provider != "" {
+		prefs[models.PreferenceAIProvider] = provider
+	}
+	if model != "" {
+		prefs[models.PreferenceAIModel] = model
+	}
+	return &models.AIContext{Preferences: prefs}
+}
+
+func TestProviderSelector_ProviderFor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		allowed     []string
+		userContext *models.AIContext
+		want        string
+	}{
+		{"nil context uses default", []string{"gpt-4o"}, nil, "openai/default"},
+		{"no preference uses default", []string{"gpt-4o"}, contextWithPreference("", ""), "openai/default"},
+		{"allowed model for default provider", []string{"gpt-4o"}, contextWithPreference("", "gpt-4o"), "openai/gpt-4o"},
+		{"allowed provider and model", []string{"openai:gpt-4o"}, contextWithPreference("openai", "gpt-4o"), "openai/gpt-4o"},
+		{"disallowed model uses default", []string{"gpt-4o"}, contextWithPreference("", "gpt-5"), "openai/default"},
+		{"empty allow-list uses default", nil, contextWithPreference("", "gpt-4o"), "openai/default"},
+		{"build failure uses default", []string{"other:gpt-4o"}, contextWithPreference("other", "gpt-4o"), "openai/default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			build := func(provider, model string) (AIProvider, error) {
+				if provider != "openai" {
+					return nil, errors.New("unsupported provider")
+				}
+				return &namedProvider{name: provider + "/" + model}, nil
+			}
+			s := NewProviderSelector(&namedProvider{name: "openai/default"}, "openai", "default", build, tt.allowed, nil)
+			got := s.ProviderFor(tt.userContext).(*namedProvider)
+			if got.name != tt.want {
+				t.Errorf("ProviderFor() = %s, want %s", got.name, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderSelector_CachesProviders(t *testing.T) {
+	t.Parallel()
+
+	builds := 0
+	build := func(provider, model string) (AIProvider, error) {
+		builds++
+		return &namedProvider{name: provider + "/" + model}, nil
+	}
+	s := NewProviderSelector(&namedProvider{name: "default"}, "openai", "default", build, []string{"gpt-4o"}, nil)
+
+	first := s.ProviderFor(contextWithPreference("", "gpt-4o"))
+	second := s.ProviderFor(contextWithPreference("openai", "gpt-4o"))
+	if first != second {
+		t.Error("expected the same provider instance for the same provider/model")
+	}
+	if builds != 1 {
+		t.Errorf("built %d providers, want 1", builds)
+	}
+}
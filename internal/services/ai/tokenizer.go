@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktokenloader "github.com/pkoukk/tiktoken-go-loader"
+	"go.uber.org/zap"
+)
+
+const (
+	// TokenizerTiktoken counts tokens with the model's BPE encoding, falling back to the heuristic
+	TokenizerTiktoken = "tiktoken"
+	// TokenizerHeuristic estimates tokens as ~4 characters per token
+	TokenizerHeuristic = "heuristic"
+)
+
+// Tokenizer counts the tokens a piece of prompt text will consume
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// HeuristicTokenizer estimates token counts without a vocabulary (~4 characters per token)
+type HeuristicTokenizer struct{}
+
+// CountTokens returns the heuristic token estimate for text
+func (HeuristicTokenizer) CountTokens(text string) int {
+	return estimateTokenCount(text)
+}
+
+// BPETokenizer counts tokens exactly using a tiktoken BPE encoding
+type BPETokenizer struct {
+	encoding *tiktoken.Tiktoken
+}
+
+// CountTokens returns the number of BPE tokens in text
+func (t *BPETokenizer) CountTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return len(t.encoding.EncodeOrdinary(text))
+}
+
+var setBPELoaderOnce sync.Once
+
+// NewBPETokenizer returns a tokenizer using the encoding for model. The BPE ranks are embedded in the
+// binary, so no network access is needed. It returns an error for models without a known encoding.
+func NewBPETokenizer(model string) (*BPETokenizer, error) {
+	setBPELoaderOnce.Do(func() {
+		tiktoken.SetBpeLoader(tiktokenloader.NewOfflineLoader())
+	})
+	encoding, err := tiktoken.EncodingForModel(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tokenizer for model %s: %w", model, err)
+	}
+	return &BPETokenizer{encoding: encoding}, nil
+}
+
+// NewTokenizer returns the tokenizer selected by kind (tiktoken or heuristic) for model. When the BPE
+// tokenizer is selected but unavailable for the model, it logs a warning and falls back to the heuristic.
+func NewTokenizer(kind, model string, logger *zap.Logger) (Tokenizer, error) {
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case TokenizerHeuristic:
+		return HeuristicTokenizer{}, nil
+	case "", TokenizerTiktoken:
+		tokenizer, err := NewBPETokenizer(model)
+		if err != nil {
+			if logger != nil {
+				logger.Warn("tokenizer_fallback_to_heuristic",
+					zap.String("model", model),
+					zap.Error(err),
+				)
+			}
+			return HeuristicTokenizer{}, nil
+		}
+		return tokenizer, nil
+	default:
+		return nil, fmt.Errorf("unsupported tokenizer %q (expected %s or %s)", kind, TokenizerTiktoken, TokenizerHeuristic)
+	}
+}
@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestNewTokenizer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		kind          string
+		model         string
+		wantHeuristic bool
+		wantErr       bool
+	}{
+		{"default uses BPE", "", "", false, false},
+		{"tiktoken for known model", "tiktoken", "gpt-4o-mini", false, false},
+		{"heuristic", "heuristic", "gpt-4o-mini", true, false},
+		{"unknown model falls back", "tiktoken", "llama-3-70b", true, false},
+		{"unsupported kind", "wordpiece", "gpt-4o-mini", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tokenizer, err := NewTokenizer(tt.kind, tt.model, zap.NewNop())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTokenizer() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if _, isHeuristic := tokenizer.(HeuristicTokenizer); isHeuristic != tt.wantHeuristic {
+				t.Errorf("tokenizer = %T, want heuristic %v", tokenizer, tt.wantHeuristic)
+			}
+		})
+	}
+}
+
+// TestHeuristicTokenizer_ErrorBound compares the len/4 heuristic with BPE counts for tag list entries as
+// they appear in the prompt, so changes to the prompt format that break the fallback's accuracy are caught.
+func TestHeuristicTokenizer_ErrorBound(t *testing.T) {
+	t.Parallel()
+
+	bpe, err := NewBPETokenizer(DefaultOpenAIModel)
+	if err != nil {
+		t.Fatalf("NewBPETokenizer() error = %v", err)
+	}
+	if got := bpe.CountTokens("hello world"); got != 2 {
+		t.Errorf("BPE CountTokens(hello world) = %d, want 2", got)
+	}
+
+	tagLists := map[string][]string{
+		"short everyday tags": {"work", "home", "shopping", "errands", "health", "finance"},
+		"hyphenated tags":     {"project-alpha", "q3-planning", "customer-support", "follow-up", "urgent"},
+		"family tags":         {"kids", "school", "doctor", "car", "garden", "groceries", "birthday", "travel"},
+	}
+	const maxRelativeError = 0.5
+
+	for name, tags := range tagLists {
+		heuristic, actual := 0, 0
+		for i, tag := range tags {
+			entry := fmt.Sprintf("- %s (used %d times)\n", tag, i*7+3)
+			heuristic += HeuristicTokenizer{}.CountTokens(entry)
+			actual += bpe.CountTokens(entry)
+		}
+		relErr := math.Abs(float64(heuristic-actual)) / float64(actual)
+		if relErr > maxRelativeError {
+			t.Errorf("%s: heuristic %d vs actual %d tokens (error %.0f%%, bound %.0f%%)", name, heuristic, actual, relErr*100, maxRelativeError*100)
+		}
+	}
+}
+
+type fixedTokenizer int
+
+func (f fixedTokenizer) CountTokens(string) int { return int(f) }
+
+func TestSelectTagsForPrompt_UsesTokenizer(t *testing.T) {
+	t.Parallel()
+
+	provider := &OpenAIProvider{providerBase: providerBase{analysisPrompter: analysisPrompter{maxTagsInPrompt: 10, maxTagTokens: 25}}}
+	provider.SetTokenizer(fixedTokenizer(10))
+	tagStats := map[string]models.TagStats{
+		"work": {Total: 5}, "home": {Total: 4}, "errands": {Total: 3}, "health": {Total: 2},
+	}
+
+	selected := provider.selectTagsForPrompt(tagStats, "unrelated", nil)
+	if len(selected) != 2 {
+		t.Errorf("selected %v, want 2 tags within a 25-token budget at 10 tokens each", selected)
+	}
+}
@@ -0,0 +1,87 @@
+package ai
+
+import (
+	"context"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"go.uber.org/zap"
+)
+
+// AnalysisTrace captures every stage of a single task analysis for support diagnostics
+type AnalysisTrace struct {
+	Model        string             `json:"model"`
+	SystemPrompt string             `json:"system_prompt"`
+	Prompt       string             `json:"prompt"`
+	RawResponse  string             `json:"raw_response,omitempty"`
+	Tags         []string           `json:"tags,omitempty"`
+	TimeHorizon  models.TimeHorizon `json:"time_horizon,omitempty"`
+	Error        string             `json:"error,omitempty"`
+	ParseError   string             `json:"parse_error,omitempty"`
+	Timings      AnalysisTimings    `json:"timings"`
+}
+
+// AnalysisTimings records how long each stage of a traced analysis took, in milliseconds
+type AnalysisTimings struct {
+	PromptBuildMs int64 `json:"prompt_build_ms"`
+	APICallMs     int64 `json:"api_call_ms"`
+	ParseMs       int64 `json:"parse_ms"`
+	TotalMs       int64 `json:"total_ms"`
+}
+
+// AIProviderWithTrace is an optional interface for providers that can run an analysis and
+// return the full prompt, raw response and parse result instead of only the parsed result
+type AIProviderWithTrace interface {
+	// TraceAnalysis runs the same analysis as AnalyzeTaskWithDueDate with debug logging forced on.
+	// API and parse failures are recorded in the trace; the returned error is reserved for failures
+	// that prevent a trace from being produced at all.
+	TraceAnalysis(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) (*AnalysisTrace, error)
+}
+
+var _ AIProviderWithTrace = (*OpenAIProvider)(nil)
+
+// TraceAnalysis runs a task analysis with debug logging enabled for this call only and returns the trace
+func (p *OpenAIProvider) TraceAnalysis(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) (*AnalysisTrace, error) {
+	debug := *p
+	debug.debugMode = true
+
+	trace := &AnalysisTrace{Model: p.model, SystemPrompt: analysisSystemPrompt}
+	start := time.Now()
+
+	stage := time.Now()
+	trace.Prompt = debug.buildAnalysisPrompt(text, dueDate, dueDateOnlyFromContext(ctx), priorityFromContext(ctx), createdAt, userContext, tagStats)
+	trace.Timings.PromptBuildMs = time.Since(stage).Milliseconds()
+
+	stage = time.Now()
+	content, err := debug.sendAnalysisPrompt(ctx, trace.Prompt)
+	trace.Timings.APICallMs = time.Since(stage).Milliseconds()
+	if err != nil {
+		trace.Error = err.Error()
+	} else {
+		trace.RawResponse = content
+		stage = time.Now()
+		tags, th, _, parseErr := parseAndValidateAnalysisResponse(content)
+		trace.Timings.ParseMs = time.Since(stage).Milliseconds()
+		if parseErr != nil {
+			trace.ParseError = parseErr.Error()
+		} else {
+			trace.Tags = tags
+			trace.TimeHorizon = th
+		}
+	}
+	trace.Timings.TotalMs = time.Since(start).Milliseconds()
+
+	if p.logger != nil {
+		userIDStr, todoIDStr := contextIDStrings(ctx)
+		p.logger.Info("llm_debug_analysis",
+			zap.String("model", p.model),
+			zap.String("user_id", userIDStr),
+			zap.String("todo_id", todoIDStr),
+			zap.String("request_id", ExtractRequestID(ctx)),
+			zap.Bool("api_error", trace.Error != ""),
+			zap.Bool("parse_error", trace.ParseError != ""),
+			zap.Int64("total_ms", trace.Timings.TotalMs),
+		)
+	}
+	return trace, nil
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"go.uber.org/zap"
+)
+
+func newChatCompletionServer(t *testing.T, content string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id":"cmpl-1","object":"chat.completion","created":1,"model":"gpt-4o-mini","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":%q}}]}`, content)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenAIProvider_TraceAnalysis(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		content       string
+		wantTags      []string
+		wantHorizon   models.TimeHorizon
+		wantParseFail bool
+	}{
+		{
+			name:        "parsed response",
+			content:     `{"tags":["finance","home"],"time_horizon":"next"}`,
+			wantTags:    []string{"finance", "home"},
+			wantHorizon: models.TimeHorizonNext,
+		},
+		{
+			name:          "unparseable response keeps raw content",
+			content:       "I think this is about money",
+			wantParseFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := newChatCompletionServer(t, tt.content)
+			p := NewOpenAIProviderWithLogger("test-key", srv.URL, "", zap.NewNop(), false, 0)
+
+			trace, err := p.TraceAnalysis(context.Background(), "Pay rent", nil, time.Now(), nil, nil)
+			if err != nil {
+				t.Fatalf("TraceAnalysis() error = %v", err)
+			}
+			if !strings.Contains(trace.Prompt, `Todo item: "Pay rent"`) {
+				t.Errorf("prompt missing todo text: %q", trace.Prompt)
+			}
+			if trace.SystemPrompt == "" || trace.Model != DefaultOpenAIModel {
+				t.Errorf("trace model/system prompt not captured: %+v", trace)
+			}
+			if trace.RawResponse != tt.content {
+				t.Errorf("RawResponse = %q, want %q", trace.RawResponse, tt.content)
+			}
+			if (trace.ParseError != "") != tt.wantParseFail {
+				t.Errorf("ParseError = %q, wantParseFail %v", trace.ParseError, tt.wantParseFail)
+			}
+			if strings.Join(trace.Tags, ",") != strings.Join(tt.wantTags, ",") || trace.TimeHorizon != tt.wantHorizon {
+				t.Errorf("result = %v/%s, want %v/%s", trace.Tags, trace.TimeHorizon, tt.wantTags, tt.wantHorizon)
+			}
+		})
+	}
+}
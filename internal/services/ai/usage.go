@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// UsageRecorder receives the token usage of each successful provider call
+type UsageRecorder interface {
+	RecordUsage(ctx context.Context, usage models.AIUsage)
+}
+
+// UsageStore persists AI usage; *database.AIUsageRepository implements it
+type UsageStore interface {
+	Record(ctx context.Context, usage *models.AIUsage) error
+}
+
+// tokenCounter and costCounter count usage by operation and model; they are no-ops until a meter provider
+// is registered
+var (
+	usageMeter      = otel.Meter("github.com/benvon/smart-todo/internal/services/ai")
+	tokenCounter, _ = usageMeter.Int64Counter(
+		"ai.tokens",
+		metric.WithDescription("Tokens used by AI provider calls, by operation, model and token type"),
+		metric.WithUnit("{token}"),
+	)
+	costCounter, _ = usageMeter.Float64Counter(
+		"ai.estimated_cost",
+		metric.WithDescription("Estimated cost of AI provider calls from the AI_PRICE_TABLE prices, by operation and model"),
+		metric.WithUnit("USD"),
+	)
+)
+
+// UsageTracker counts the tokens and estimated cost of AI calls as metrics and stores each call's usage for
+// the admin cost summary
+type UsageTracker struct {
+	store  UsageStore
+	prices models.AIPriceTable
+	logger *zap.Logger
+}
+
+// NewUsageTracker creates a usage tracker pricing usage with prices. store may be nil to only emit metrics.
+func NewUsageTracker(store UsageStore, prices models.AIPriceTable, logger *zap.Logger) *UsageTracker {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &UsageTracker{store: store, prices: prices, logger: logger}
+}
+
+// RecordUsage counts the usage and stores it, attributed to the user in ctx (UserIDContextKey) unless
+// usage already names one. A failed store is logged; it never fails the AI call.
+func (t *UsageTracker) RecordUsage(ctx context.Context, usage models.AIUsage) {
+	op, model := attribute.String("operation", usage.Operation), attribute.String("model", usage.Model)
+	tokenCounter.Add(ctx, usage.PromptTokens, metric.WithAttributes(op, model, attribute.String("token_type", "prompt")))
+	tokenCounter.Add(ctx, usage.CompletionTokens, metric.WithAttributes(op, model, attribute.String("token_type", "completion")))
+	if cost, priced := t.prices.Cost(usage.Model, usage.PromptTokens, usage.CompletionTokens); priced {
+		costCounter.Add(ctx, cost, metric.WithAttributes(op, model))
+	}
+
+	if t.store == nil {
+		return
+	}
+	if usage.UserID == nil {
+		if userID, ok := ctx.Value(UserIDContextKey()).(uuid.UUID); ok {
+			usage.UserID = &userID
+		}
+	}
+	if err := t.store.Record(context.WithoutCancel(ctx), &usage); err != nil {
+		t.logger.Warn("ai_usage_record_failed",
+			zap.String("operation", usage.Operation),
+			zap.String("model", usage.Model),
+			zap.Error(err),
+		)
+	}
+}
+
+// recordUsage reports the usage of one call to recorder, if any
+func recordUsage(ctx context.Context, recorder UsageRecorder, operation, model string, promptTokens, completionTokens int64) {
+	if recorder == nil {
+		return
+	}
+	recorder.RecordUsage(ctx, models.AIUsage{
+		Operation:        operation,
+		Model:            model,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+	})
+}
@@ -0,0 +1,130 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type fakeUsageStore struct {
+	recorded []models.AIUsage
+	err      error
+}
+
+func (s *fakeUsageStore) Record(ctx context.Context, usage *models.AIUsage) error {
+	s.recorded = append(s.recorded, *usage)
+	return s.err
+}
+
+func TestUsageTracker_RecordUsage(t *testing.T) {
+	t.Parallel()
+
+	userID, otherID := uuid.New(), uuid.New()
+	tests := []struct {
+		name     string
+		ctxUser  *uuid.UUID
+		usage    models.AIUsage
+		storeErr error
+		wantUser *uuid.UUID
+	}{
+		{name: "attributed to the user in the context", ctxUser: &userID, usage: models.AIUsage{Operation: "chat", Model: "gpt-4o-mini"}, wantUser: &userID},
+		{name: "not attributed", usage: models.AIUsage{Operation: "analyze_task", Model: "gpt-4o-mini"}},
+		{name: "user already set", ctxUser: &userID, usage: models.AIUsage{UserID: &otherID, Operation: "chat"}, wantUser: &otherID},
+		{name: "store fails", usage: models.AIUsage{Operation: "chat"}, storeErr: errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := &fakeUsageStore{err: tt.storeErr}
+			tracker := NewUsageTracker(store, models.DefaultAIPriceTable, zap.NewNop())
+
+			ctx := context.Background()
+			if tt.ctxUser != nil {
+				ctx = context.WithValue(ctx, UserIDContextKey(), *tt.ctxUser)
+			}
+			tt.usage.PromptTokens, tt.usage.CompletionTokens = 120, 30
+			tracker.RecordUsage(ctx, tt.usage)
+
+			if len(store.recorded) != 1 {
+				t.Fatalf("recorded %d usages, want 1", len(store.recorded))
+			}
+			got := store.recorded[0]
+			if (got.UserID == nil) != (tt.wantUser == nil) || (got.UserID != nil && *got.UserID != *tt.wantUser) {
+				t.Errorf("UserID = %v, want %v", got.UserID, tt.wantUser)
+			}
+			if got.Operation != tt.usage.Operation || got.PromptTokens != 120 || got.CompletionTokens != 30 {
+				t.Errorf("recorded %+v, want %+v", got, tt.usage)
+			}
+		})
+	}
+}
+
+type usageRecorderFunc func(ctx context.Context, usage models.AIUsage)
+
+func (f usageRecorderFunc) RecordUsage(ctx context.Context, usage models.AIUsage) { f(ctx, usage) }
+
+// usageRecordingProvider is a provider that reports its token usage
+type usageRecordingProvider interface {
+	AIProvider
+	SetUsageRecorder(UsageRecorder)
+}
+
+func TestProviders_RecordUsage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		response  string
+		provider  func(baseURL string) usageRecordingProvider
+		wantModel string
+	}{
+		{
+			name:     "openai",
+			response: `{"id":"c1","object":"chat.completion","created":1,"model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}],"usage":{"prompt_tokens":42,"completion_tokens":7,"total_tokens":49}}`,
+			provider: func(baseURL string) usageRecordingProvider {
+				return NewOpenAIProviderWithConfig("test-key", baseURL, "gpt-4o-mini")
+			},
+			wantModel: "gpt-4o-mini",
+		},
+		{
+			name:     "anthropic",
+			response: `{"content":[{"type":"text","text":"hello"}],"usage":{"input_tokens":42,"output_tokens":7}}`,
+			provider: func(baseURL string) usageRecordingProvider {
+				return NewAnthropicProviderWithLogger("test-key", baseURL, "", nil, false, 0)
+			},
+			wantModel: DefaultAnthropicModel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			var recorded []models.AIUsage
+			provider := tt.provider(server.URL)
+			provider.SetUsageRecorder(usageRecorderFunc(func(ctx context.Context, usage models.AIUsage) {
+				recorded = append(recorded, usage)
+			}))
+			if _, err := provider.Chat(context.Background(), []ChatMessage{{Role: "user", Content: "hi"}}, nil); err != nil {
+				t.Fatalf("Chat() error = %v", err)
+			}
+
+			want := models.AIUsage{Operation: "chat", Model: tt.wantModel, PromptTokens: 42, CompletionTokens: 7}
+			if len(recorded) != 1 || recorded[0] != want {
+				t.Errorf("recorded %+v, want [%+v]", recorded, want)
+			}
+		})
+	}
+}
@@ -19,14 +19,23 @@ func NewClient(oidcConfig *models.OIDCConfig) *Client {
 		clientSecret = *oidcConfig.ClientSecret
 	}
 	
+	authURL := oidcConfig.Issuer + "/oauth2/authorize"
+	if oidcConfig.AuthorizationEndpoint != nil && *oidcConfig.AuthorizationEndpoint != "" {
+		authURL = *oidcConfig.AuthorizationEndpoint
+	}
+	tokenURL := oidcConfig.Issuer + "/oauth2/token"
+	if oidcConfig.TokenEndpoint != nil && *oidcConfig.TokenEndpoint != "" {
+		tokenURL = *oidcConfig.TokenEndpoint
+	}
+
 	config := &oauth2.Config{
 		ClientID:     oidcConfig.ClientID,
 		ClientSecret: clientSecret,
 		RedirectURL:  oidcConfig.RedirectURI,
 		Scopes:       []string{"openid", "email", "profile"},
 		Endpoint: oauth2.Endpoint{
-			AuthURL:  oidcConfig.Issuer + "/oauth2/authorize",
-			TokenURL: oidcConfig.Issuer + "/oauth2/token",
+			AuthURL:  authURL,
+			TokenURL: tokenURL,
 		},
 	}
 
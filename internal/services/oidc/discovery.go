@@ -0,0 +1,78 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/benvon/smart-todo/internal/models"
+)
+
+const (
+	// MaxDiscoverySize is the maximum size for OpenID discovery documents (64KB)
+	MaxDiscoverySize = 64 * 1024 // 64KB
+)
+
+// Discovery holds the fields of an issuer's OpenID discovery document that the server uses
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// DiscoveryURL returns the URL of the issuer's discovery document
+func DiscoveryURL(issuer string) string {
+	return strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+}
+
+// FetchDiscovery fetches the issuer's discovery document over HTTPS. The document must name the same issuer,
+// ignoring a trailing slash, and a JWKS URI.
+func FetchDiscovery(ctx context.Context, client *http.Client, issuer string) (*Discovery, error) {
+	if !strings.HasPrefix(issuer, "https://") {
+		return nil, fmt.Errorf("issuer must use HTTPS")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, DiscoveryURL(issuer), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch discovery document: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery endpoint returned status %d", resp.StatusCode)
+	}
+	var discovery Discovery
+	if err := json.NewDecoder(io.LimitReader(resp.Body, MaxDiscoverySize)).Decode(&discovery); err != nil {
+		return nil, fmt.Errorf("failed to decode discovery document: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, fmt.Errorf("discovery document issuer %q does not match %q", discovery.Issuer, issuer)
+	}
+	if discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document has no jwks_uri")
+	}
+	return &discovery, nil
+}
+
+// Apply sets the issuer, JWKS URL and endpoints of config from the discovery document. The issuer is taken as
+// discovered because tokens must name it exactly (Auth0 issuers end with a slash, for example).
+func (d *Discovery) Apply(config *models.OIDCConfig) {
+	config.Issuer = d.Issuer
+	jwksURL := d.JWKSURI
+	config.JWKSUrl = &jwksURL
+	config.AuthorizationEndpoint = optionalString(d.AuthorizationEndpoint)
+	config.TokenEndpoint = optionalString(d.TokenEndpoint)
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,121 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+)
+
+func TestFetchDiscovery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// issuer builds the document's issuer from the server URL
+		issuer  func(serverURL string) string
+		jwksURI string
+		status  int
+		wantErr bool
+	}{
+		{name: "matching issuer", issuer: func(u string) string { return u }, jwksURI: "https://idp.example.com/jwks.json", status: http.StatusOK},
+		{name: "issuer with trailing slash", issuer: func(u string) string { return u + "/" }, jwksURI: "https://idp.example.com/jwks.json", status: http.StatusOK},
+		{name: "different issuer", issuer: func(u string) string { return "https://other.example.com" }, jwksURI: "https://idp.example.com/jwks.json", status: http.StatusOK, wantErr: true},
+		{name: "missing jwks_uri", issuer: func(u string) string { return u }, status: http.StatusOK, wantErr: true},
+		{name: "error status", issuer: func(u string) string { return u }, status: http.StatusNotFound, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var server *httptest.Server
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/.well-known/openid-configuration" {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tt.status)
+				_ = json.NewEncoder(w).Encode(Discovery{
+					Issuer:                tt.issuer(server.URL),
+					AuthorizationEndpoint: server.URL + "/authorize",
+					TokenEndpoint:         server.URL + "/oauth/token",
+					JWKSURI:               tt.jwksURI,
+				})
+			}))
+			defer server.Close()
+
+			discovery, err := FetchDiscovery(context.Background(), server.Client(), server.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchDiscovery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if discovery.JWKSURI != tt.jwksURI || discovery.TokenEndpoint != server.URL+"/oauth/token" {
+				t.Errorf("discovery = %+v", discovery)
+			}
+		})
+	}
+}
+
+func TestFetchDiscovery_RequiresHTTPS(t *testing.T) {
+	t.Parallel()
+
+	if _, err := FetchDiscovery(context.Background(), http.DefaultClient, "http://idp.example.com"); err == nil {
+		t.Error("FetchDiscovery() error = nil, want an error for a plain HTTP issuer")
+	}
+}
+
+func TestDiscovery_Apply(t *testing.T) {
+	t.Parallel()
+
+	config := &models.OIDCConfig{Issuer: "https://tenant.auth0.com", ClientID: "client"}
+	discovery := &Discovery{
+		Issuer:                "https://tenant.auth0.com/",
+		AuthorizationEndpoint: "https://tenant.auth0.com/authorize",
+		TokenEndpoint:         "https://tenant.auth0.com/oauth/token",
+		JWKSURI:               "https://tenant.auth0.com/.well-known/jwks.json",
+	}
+	discovery.Apply(config)
+
+	if config.Issuer != discovery.Issuer {
+		t.Errorf("Issuer = %q, want %q", config.Issuer, discovery.Issuer)
+	}
+	if config.JWKSUrl == nil || *config.JWKSUrl != discovery.JWKSURI {
+		t.Errorf("JWKSUrl = %v, want %q", config.JWKSUrl, discovery.JWKSURI)
+	}
+	if got := getAuthEndpoint(config); got != discovery.AuthorizationEndpoint {
+		t.Errorf("auth endpoint = %q, want %q", got, discovery.AuthorizationEndpoint)
+	}
+	if got := getTokenEndpoint(config); got != discovery.TokenEndpoint {
+		t.Errorf("token endpoint = %q, want %q", got, discovery.TokenEndpoint)
+	}
+}
+
+func TestAudienceAllowed(t *testing.T) {
+	t.Parallel()
+
+	allowed := []string{"client-id", "https://api.example.com"}
+	tests := []struct {
+		name      string
+		audiences []string
+		want      bool
+	}{
+		{name: "client id", audiences: []string{"client-id"}, want: true},
+		{name: "one of several", audiences: []string{"https://tenant.auth0.com/userinfo", "https://api.example.com"}, want: true},
+		{name: "other audience", audiences: []string{"someone-else"}, want: false},
+		{name: "no audience", audiences: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := audienceAllowed(tt.audiences, allowed); got != tt.want {
+				t.Errorf("audienceAllowed(%v) = %v, want %v", tt.audiences, got, tt.want)
+			}
+		})
+	}
+}
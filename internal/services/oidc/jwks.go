@@ -2,9 +2,12 @@ package oidc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -15,61 +18,267 @@ import (
 const (
 	// MaxJWKSSize is the maximum size for JWKS responses (10KB)
 	MaxJWKSSize = 10 * 1024 // 10KB
+	// DefaultJWKSCacheTTL is how long fetched keys are used before they are fetched again
+	DefaultJWKSCacheTTL = time.Hour
+	// DefaultJWKSNegativeCacheTTL is how long a key ID missing from a freshly fetched key set is remembered as
+	// unknown; key sets are also fetched for unknown key IDs at most this often
+	DefaultJWKSNegativeCacheTTL = time.Minute
+	// DefaultJWKSMaxStale is how long past their expiry cached keys are still used while they cannot be fetched
+	DefaultJWKSMaxStale = 24 * time.Hour
+	// jwksRetryBaseDelay is how long fetches of a key set are held back after it failed to fetch; the delay
+	// doubles with each further failure up to jwksRetryMaxDelay
+	jwksRetryBaseDelay = 5 * time.Second
+	jwksRetryMaxDelay  = 5 * time.Minute
+	// maxUnknownKeyIDs caps the unknown key IDs remembered per key set
+	maxUnknownKeyIDs = 1024
 )
 
+// ErrUnknownKeyID is returned for a token whose key ID is not in its issuer's key set
+var ErrUnknownKeyID = errors.New("unknown key ID")
+
+// errJWKSBackoff is returned instead of fetching a key set whose last fetch failed too recently
+var errJWKSBackoff = errors.New("JWKS fetch failed recently, not retried yet")
+
 // JWKSCache caches JWKS keys
 type JWKSCache struct {
 	keys    jwk.Set
+	fetched time.Time
 	expires time.Time
-	mu      sync.RWMutex
+	// unknownKIDs maps key IDs missing from the keys to when they may be looked up again
+	unknownKIDs map[string]time.Time
 }
 
-// JWKSManager manages JWKS fetching and caching
+// jwksFailure tracks the failed fetches of a key set since it was last fetched
+type jwksFailure struct {
+	count   int
+	retryAt time.Time
+}
+
+// jwksFetch is a fetch of a key set in progress, shared by everyone who needs the keys meanwhile
+type jwksFetch struct {
+	done chan struct{}
+	keys jwk.Set
+	err  error
+}
+
+// JWKSManager manages JWKS fetching and caching. Key sets are cached for a TTL and can be refreshed in the
+// background by Start; concurrent lookups of the same key set share one fetch.
 type JWKSManager struct {
-	cache map[string]*JWKSCache
-	mu    sync.RWMutex
-	ttl   time.Duration
+	mu          sync.Mutex
+	cache       map[string]*JWKSCache
+	fetches     map[string]*jwksFetch
+	failures    map[string]*jwksFailure
+	ttl         time.Duration
+	negativeTTL time.Duration
+	maxStale    time.Duration
+	fetch       func(ctx context.Context, jwksURL string) (jwk.Set, error)
+	now         func() time.Time
+}
+
+// JWKSOption configures a JWKSManager
+type JWKSOption func(*JWKSManager)
+
+// WithJWKSCacheTTL sets how long fetched keys are cached (default DefaultJWKSCacheTTL)
+func WithJWKSCacheTTL(ttl time.Duration) JWKSOption {
+	return func(m *JWKSManager) {
+		if ttl > 0 {
+			m.ttl = ttl
+		}
+	}
+}
+
+// WithJWKSNegativeCacheTTL sets how long unknown key IDs are remembered (default DefaultJWKSNegativeCacheTTL)
+func WithJWKSNegativeCacheTTL(ttl time.Duration) JWKSOption {
+	return func(m *JWKSManager) {
+		if ttl > 0 {
+			m.negativeTTL = ttl
+		}
+	}
+}
+
+// WithJWKSMaxStale sets how long past their expiry cached keys are still used while fetching them fails
+// (default DefaultJWKSMaxStale); zero never uses expired keys
+func WithJWKSMaxStale(maxStale time.Duration) JWKSOption {
+	return func(m *JWKSManager) {
+		if maxStale >= 0 {
+			m.maxStale = maxStale
+		}
+	}
 }
 
 // NewJWKSManager creates a new JWKS manager
-func NewJWKSManager() *JWKSManager {
-	return &JWKSManager{
-		cache: make(map[string]*JWKSCache),
-		ttl:   1 * time.Hour, // Cache for 1 hour
+func NewJWKSManager(opts ...JWKSOption) *JWKSManager {
+	m := &JWKSManager{
+		cache:       make(map[string]*JWKSCache),
+		fetches:     make(map[string]*jwksFetch),
+		failures:    make(map[string]*jwksFailure),
+		ttl:         DefaultJWKSCacheTTL,
+		negativeTTL: DefaultJWKSNegativeCacheTTL,
+		maxStale:    DefaultJWKSMaxStale,
+		now:         time.Now,
+	}
+	m.fetch = m.fetchJWKS
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
-// GetJWKS retrieves JWKS for a given JWKS URL, with caching
+// GetJWKS retrieves JWKS for a given JWKS URL, with caching. When the cached keys have expired and fetching
+// them again fails, the expired keys are returned for up to the max-stale window rather than failing every
+// verification during an outage of the identity provider. After a failed fetch the key set is not fetched
+// again until a backoff has passed.
 func (m *JWKSManager) GetJWKS(ctx context.Context, jwksURL string) (jwk.Set, error) {
-	m.mu.RLock()
-	cache, exists := m.cache[jwksURL]
-	m.mu.RUnlock()
-
-	if exists {
-		cache.mu.RLock()
-		if time.Now().Before(cache.expires) && cache.keys != nil {
-			keys := cache.keys
-			cache.mu.RUnlock()
-			return keys, nil
-		}
-		cache.mu.RUnlock()
+	m.mu.Lock()
+	cache := m.cache[jwksURL]
+	m.mu.Unlock()
+	now := m.now()
+	if cache != nil && now.Before(cache.expires) {
+		return cache.keys, nil
 	}
 
-	// Fetch fresh JWKS
-	keys, err := m.fetchJWKS(ctx, jwksURL)
+	keys, err := m.refresh(ctx, jwksURL)
 	if err != nil {
+		if cache != nil && now.Before(cache.expires.Add(m.maxStale)) {
+			return cache.keys, nil
+		}
 		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
 	}
+	return keys, nil
+}
+
+// GetJWKSForKeyID retrieves the JWKS at jwksURL, which must contain the key kid (any key set when kid is
+// empty). A key ID missing from the cached keys is looked up in a fresh fetch, since the issuer may have
+// rotated its keys; one still missing returns ErrUnknownKeyID and is remembered for the negative cache TTL,
+// so tokens naming made-up key IDs do not reach the JWKS URL.
+func (m *JWKSManager) GetJWKSForKeyID(ctx context.Context, jwksURL, kid string) (jwk.Set, error) {
+	keys, err := m.GetJWKS(ctx, jwksURL)
+	if err != nil || kid == "" {
+		return keys, err
+	}
+	if _, ok := keys.LookupKeyID(kid); ok {
+		return keys, nil
+	}
 
-	// Update cache
 	m.mu.Lock()
-	m.cache[jwksURL] = &JWKSCache{
-		keys:    keys,
-		expires: time.Now().Add(m.ttl),
+	now := m.now()
+	cache := m.cache[jwksURL]
+	until, unknown := cache.unknownKIDs[kid]
+	fetchedRecently := now.Sub(cache.fetched) < m.negativeTTL
+	m.mu.Unlock()
+	if unknown && now.Before(until) {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKeyID, kid)
 	}
+	if !fetchedRecently {
+		if keys, err = m.refresh(ctx, jwksURL); err != nil {
+			return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+		}
+		if _, ok := keys.LookupKeyID(kid); ok {
+			return keys, nil
+		}
+	}
+	m.rememberUnknownKeyID(jwksURL, kid)
+	return nil, fmt.Errorf("%w %q", ErrUnknownKeyID, kid)
+}
+
+func (m *JWKSManager) rememberUnknownKeyID(jwksURL, kid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cache := m.cache[jwksURL]
+	if cache == nil {
+		return
+	}
+	now := m.now()
+	if cache.unknownKIDs == nil {
+		cache.unknownKIDs = make(map[string]time.Time)
+	}
+	if len(cache.unknownKIDs) >= maxUnknownKeyIDs {
+		maps.DeleteFunc(cache.unknownKIDs, func(_ string, until time.Time) bool { return !now.Before(until) })
+		if len(cache.unknownKIDs) >= maxUnknownKeyIDs {
+			return
+		}
+	}
+	cache.unknownKIDs[kid] = now.Add(m.negativeTTL)
+}
+
+// refresh fetches the key set at jwksURL and caches it. Callers arriving while a fetch is in progress wait
+// for it instead of fetching again; the fetch is not cancelled when the caller that started it goes away.
+// While the backoff after a failed fetch lasts it returns errJWKSBackoff without fetching.
+func (m *JWKSManager) refresh(ctx context.Context, jwksURL string) (jwk.Set, error) {
+	m.mu.Lock()
+	if failure, ok := m.failures[jwksURL]; ok && m.now().Before(failure.retryAt) {
+		m.mu.Unlock()
+		return nil, errJWKSBackoff
+	}
+	if f, ok := m.fetches[jwksURL]; ok {
+		m.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.keys, f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	f := &jwksFetch{done: make(chan struct{})}
+	m.fetches[jwksURL] = f
 	m.mu.Unlock()
 
-	return keys, nil
+	f.keys, f.err = m.fetch(context.WithoutCancel(ctx), jwksURL)
+
+	m.mu.Lock()
+	delete(m.fetches, jwksURL)
+	now := m.now()
+	if f.err == nil {
+		m.cache[jwksURL] = &JWKSCache{keys: f.keys, fetched: now, expires: now.Add(m.ttl)}
+		delete(m.failures, jwksURL)
+	} else {
+		failure := m.failures[jwksURL]
+		if failure == nil {
+			failure = &jwksFailure{}
+			m.failures[jwksURL] = failure
+		}
+		failure.count++
+		failure.retryAt = now.Add(jwksRetryDelay(failure.count))
+	}
+	m.mu.Unlock()
+	close(f.done)
+	return f.keys, f.err
+}
+
+// Start refreshes every cached key set each half TTL until ctx is done, so verifications find keys cached
+// ahead of their expiry instead of fetching them. A failed refresh keeps the cached keys.
+func (m *JWKSManager) Start(ctx context.Context) {
+	ticker := time.NewTicker(m.ttl / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.refreshAll(ctx)
+		}
+	}
+}
+
+// jwksRetryDelay returns the backoff after the given number of consecutive failed fetches
+func jwksRetryDelay(failures int) time.Duration {
+	delay := jwksRetryBaseDelay
+	for i := 1; i < failures && delay < jwksRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, jwksRetryMaxDelay)
+}
+
+func (m *JWKSManager) refreshAll(ctx context.Context) {
+	m.mu.Lock()
+	urls := slices.Collect(maps.Keys(m.cache))
+	m.mu.Unlock()
+	for _, jwksURL := range urls {
+		if ctx.Err() != nil {
+			return
+		}
+		_, _ = m.refresh(ctx, jwksURL)
+	}
 }
 
 func (m *JWKSManager) fetchJWKS(ctx context.Context, jwksURL string) (jwk.Set, error) {
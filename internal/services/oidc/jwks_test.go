@@ -0,0 +1,323 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+const testJWKSURL = "https://idp.example.com/.well-known/jwks.json"
+
+// testSigningKey is an RSA private key with its key ID, for signing test tokens
+type testSigningKey struct {
+	private jwk.Key
+	public  jwk.Key
+}
+
+func newTestSigningKey(t *testing.T, kid string) testSigningKey {
+	t.Helper()
+	raw, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	private, err := jwk.FromRaw(raw)
+	if err != nil {
+		t.Fatalf("jwk from key: %v", err)
+	}
+	for _, key := range []jwk.Key{private} {
+		_ = key.Set(jwk.KeyIDKey, kid)
+		_ = key.Set(jwk.AlgorithmKey, jwa.RS256)
+	}
+	public, err := private.PublicKey()
+	if err != nil {
+		t.Fatalf("public key: %v", err)
+	}
+	return testSigningKey{private: private, public: public}
+}
+
+func (k testSigningKey) sign(t *testing.T, issuer, audience string) string {
+	t.Helper()
+	token, err := jwt.NewBuilder().Issuer(issuer).Audience([]string{audience}).Subject("user-1").
+		Expiration(time.Now().Add(time.Hour)).Build()
+	if err != nil {
+		t.Fatalf("build token: %v", err)
+	}
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256, k.private))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return string(signed)
+}
+
+// fakeJWKSEndpoint serves the public keys it currently holds and counts fetches
+type fakeJWKSEndpoint struct {
+	mu      sync.Mutex
+	keys    []testSigningKey
+	err     error
+	fetches atomic.Int32
+	// release, when set, holds every fetch until it is closed
+	release chan struct{}
+}
+
+func (f *fakeJWKSEndpoint) serve(keys ...testSigningKey) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = keys
+	f.err = nil
+}
+
+func (f *fakeJWKSEndpoint) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func (f *fakeJWKSEndpoint) fetch(ctx context.Context, jwksURL string) (jwk.Set, error) {
+	f.fetches.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	set := jwk.NewSet()
+	for _, key := range f.keys {
+		if err := set.AddKey(key.public); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// newTestJWKSManager returns a manager fetching from endpoint on a clock the test advances
+func newTestJWKSManager(endpoint *fakeJWKSEndpoint, clock *time.Time) *JWKSManager {
+	m := NewJWKSManager(WithJWKSCacheTTL(time.Hour), WithJWKSNegativeCacheTTL(time.Minute))
+	m.fetch = endpoint.fetch
+	m.now = func() time.Time { return *clock }
+	return m
+}
+
+func TestVerifier_KeyRotation(t *testing.T) {
+	t.Parallel()
+
+	const issuer = "https://idp.example.com/"
+	oldKey := newTestSigningKey(t, "key-1")
+	newKey := newTestSigningKey(t, "key-2")
+	endpoint := &fakeJWKSEndpoint{}
+	endpoint.serve(oldKey)
+	clock := time.Now()
+	m := newTestJWKSManager(endpoint, &clock)
+	verifier := NewVerifier(m, issuer, "client-id")
+	ctx := context.Background()
+
+	if _, err := verifier.Verify(ctx, oldKey.sign(t, issuer, "client-id"), testJWKSURL); err != nil {
+		t.Fatalf("Verify(old key) error = %v", err)
+	}
+
+	// The issuer rotates to a new key before the cached keys expire
+	clock = clock.Add(10 * time.Minute)
+	endpoint.serve(oldKey, newKey)
+	claims, err := verifier.Verify(ctx, newKey.sign(t, issuer, "client-id"), testJWKSURL)
+	if err != nil {
+		t.Fatalf("Verify(new key) error = %v", err)
+	}
+	if claims.Sub != "user-1" {
+		t.Errorf("sub = %q, want user-1", claims.Sub)
+	}
+	if got := endpoint.fetches.Load(); got != 2 {
+		t.Errorf("fetches = %d, want 2 (initial and after rotation)", got)
+	}
+
+	// Both keys are now cached
+	if _, err := verifier.Verify(ctx, oldKey.sign(t, issuer, "client-id"), testJWKSURL); err != nil {
+		t.Errorf("Verify(old key) after rotation error = %v", err)
+	}
+	if got := endpoint.fetches.Load(); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+
+	if _, err := verifier.Verify(ctx, newKey.sign(t, issuer, "someone-else"), testJWKSURL); err == nil {
+		t.Error("Verify() accepted a token for another audience")
+	}
+}
+
+func TestJWKSManager_NegativeCaching(t *testing.T) {
+	t.Parallel()
+
+	key := newTestSigningKey(t, "key-1")
+	endpoint := &fakeJWKSEndpoint{}
+	endpoint.serve(key)
+	clock := time.Now()
+	m := newTestJWKSManager(endpoint, &clock)
+	ctx := context.Background()
+
+	if _, err := m.GetJWKS(ctx, testJWKSURL); err != nil {
+		t.Fatalf("GetJWKS() error = %v", err)
+	}
+	// Just fetched: an unknown key ID does not fetch again
+	for range 5 {
+		if _, err := m.GetJWKSForKeyID(ctx, testJWKSURL, "forged"); !errors.Is(err, ErrUnknownKeyID) {
+			t.Fatalf("GetJWKSForKeyID(forged) error = %v, want ErrUnknownKeyID", err)
+		}
+	}
+	if got := endpoint.fetches.Load(); got != 1 {
+		t.Errorf("fetches = %d, want 1", got)
+	}
+
+	// Once the keys are older than the negative TTL, a new unknown key ID fetches once and is remembered
+	clock = clock.Add(2 * time.Minute)
+	for _, kid := range []string{"other", "other", "other"} {
+		if _, err := m.GetJWKSForKeyID(ctx, testJWKSURL, kid); !errors.Is(err, ErrUnknownKeyID) {
+			t.Fatalf("GetJWKSForKeyID(%s) error = %v, want ErrUnknownKeyID", kid, err)
+		}
+	}
+	if got := endpoint.fetches.Load(); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+	if _, err := m.GetJWKSForKeyID(ctx, testJWKSURL, "key-1"); err != nil {
+		t.Errorf("GetJWKSForKeyID(key-1) error = %v", err)
+	}
+}
+
+func TestJWKSManager_SingleFlight(t *testing.T) {
+	t.Parallel()
+
+	endpoint := &fakeJWKSEndpoint{release: make(chan struct{})}
+	endpoint.serve(newTestSigningKey(t, "key-1"))
+	clock := time.Now()
+	m := newTestJWKSManager(endpoint, &clock)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := m.GetJWKSForKeyID(context.Background(), testJWKSURL, "key-1")
+			errs <- err
+		}()
+	}
+	// Let the callers pile up on the first fetch before it completes
+	time.Sleep(50 * time.Millisecond)
+	close(endpoint.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("GetJWKSForKeyID() error = %v", err)
+		}
+	}
+	if got := endpoint.fetches.Load(); got != 1 {
+		t.Errorf("fetches = %d, want 1 shared fetch", got)
+	}
+}
+
+func TestJWKSManager_ExpiryAndRefresh(t *testing.T) {
+	t.Parallel()
+
+	oldKey := newTestSigningKey(t, "key-1")
+	newKey := newTestSigningKey(t, "key-2")
+	endpoint := &fakeJWKSEndpoint{}
+	endpoint.serve(oldKey)
+	clock := time.Now()
+	m := newTestJWKSManager(endpoint, &clock)
+	ctx := context.Background()
+
+	if _, err := m.GetJWKS(ctx, testJWKSURL); err != nil {
+		t.Fatalf("GetJWKS() error = %v", err)
+	}
+
+	// A background refresh picks up rotated keys without a request fetching them
+	endpoint.serve(newKey)
+	m.refreshAll(ctx)
+	keys, err := m.GetJWKS(ctx, testJWKSURL)
+	if err != nil {
+		t.Fatalf("GetJWKS() error = %v", err)
+	}
+	if _, ok := keys.LookupKeyID("key-2"); !ok {
+		t.Error("refreshed keys lack key-2")
+	}
+
+	// Expired keys are fetched again; when that fails the expired keys are still served
+	clock = clock.Add(2 * time.Hour)
+	endpoint.fail(errors.New("idp down"))
+	keys, err = m.GetJWKS(ctx, testJWKSURL)
+	if err != nil {
+		t.Fatalf("GetJWKS() during outage error = %v, want the expired keys", err)
+	}
+	if _, ok := keys.LookupKeyID("key-2"); !ok {
+		t.Error("served keys lack key-2")
+	}
+	if got := endpoint.fetches.Load(); got != 3 {
+		t.Errorf("fetches = %d, want 3", got)
+	}
+
+	// Without cached keys a failed fetch is an error
+	if _, err := m.GetJWKS(ctx, "https://other.example.com/jwks.json"); err == nil {
+		t.Error("GetJWKS() error = nil, want the fetch error")
+	}
+}
+
+func TestJWKSManager_MaxStaleAndBackoff(t *testing.T) {
+	t.Parallel()
+
+	key := newTestSigningKey(t, "key-1")
+	endpoint := &fakeJWKSEndpoint{}
+	endpoint.serve(key)
+	clock := time.Now()
+	m := newTestJWKSManager(endpoint, &clock)
+	m.maxStale = 3 * time.Hour
+	ctx := context.Background()
+
+	if _, err := m.GetJWKS(ctx, testJWKSURL); err != nil {
+		t.Fatalf("GetJWKS() error = %v", err)
+	}
+
+	// Once expired, a failed fetch serves the stale keys and holds back further fetches for a while
+	clock = clock.Add(2 * time.Hour)
+	endpoint.fail(errors.New("idp down"))
+	for range 3 {
+		if _, err := m.GetJWKS(ctx, testJWKSURL); err != nil {
+			t.Fatalf("GetJWKS() during outage error = %v, want the stale keys", err)
+		}
+	}
+	if got := endpoint.fetches.Load(); got != 2 {
+		t.Errorf("fetches = %d, want 2 (no refetch during the backoff)", got)
+	}
+
+	// Each further failure backs off longer
+	clock = clock.Add(jwksRetryBaseDelay)
+	_, _ = m.GetJWKS(ctx, testJWKSURL)
+	clock = clock.Add(jwksRetryBaseDelay)
+	_, _ = m.GetJWKS(ctx, testJWKSURL)
+	if got := endpoint.fetches.Load(); got != 3 {
+		t.Errorf("fetches = %d, want 3 (the second backoff is twice as long)", got)
+	}
+
+	// Past the max-stale window the stale keys are no longer served
+	clock = clock.Add(2 * time.Hour)
+	if _, err := m.GetJWKS(ctx, testJWKSURL); err == nil {
+		t.Error("GetJWKS() error = nil past the max-stale window, want the fetch error")
+	}
+
+	// A successful fetch clears the backoff
+	endpoint.serve(key)
+	clock = clock.Add(jwksRetryMaxDelay)
+	if _, err := m.GetJWKS(ctx, testJWKSURL); err != nil {
+		t.Fatalf("GetJWKS() after recovery error = %v", err)
+	}
+	if _, ok := m.failures[testJWKSURL]; ok {
+		t.Error("failure backoff kept after a successful fetch")
+	}
+}
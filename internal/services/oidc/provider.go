@@ -2,7 +2,6 @@ package oidc
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -49,6 +48,9 @@ func (p *Provider) GetLoginConfig(ctx context.Context, providerName string) (*Lo
 }
 
 func getAuthEndpoint(config *models.OIDCConfig) string {
+	if config.AuthorizationEndpoint != nil && *config.AuthorizationEndpoint != "" {
+		return *config.AuthorizationEndpoint
+	}
 	if config.Domain != nil && *config.Domain != "" && strings.Contains(config.Issuer, "cognito-idp.") {
 		return fmt.Sprintf("%s/oauth2/authorize", cognitoDomainBaseURL(*config.Domain))
 	}
@@ -59,16 +61,10 @@ func getAuthEndpoint(config *models.OIDCConfig) string {
 }
 
 func fetchAuthEndpointFromDiscovery(issuer string) string {
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(issuer + "/.well-known/openid-configuration")
-	if err != nil || resp.StatusCode != http.StatusOK {
-		return ""
-	}
-	defer func() { _ = resp.Body.Close() }()
-	var discovery struct {
-		AuthorizationEndpoint string `json:"authorization_endpoint"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil || discovery.AuthorizationEndpoint == "" {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	discovery, err := FetchDiscovery(ctx, http.DefaultClient, issuer)
+	if err != nil {
 		return ""
 	}
 	return discovery.AuthorizationEndpoint
@@ -82,6 +78,9 @@ func authEndpointFromIssuer(issuer string) string {
 }
 
 func getTokenEndpoint(config *models.OIDCConfig) string {
+	if config.TokenEndpoint != nil && *config.TokenEndpoint != "" {
+		return *config.TokenEndpoint
+	}
 	if config.Domain != nil && *config.Domain != "" && strings.Contains(config.Issuer, "cognito-idp.") {
 		baseURL := cognitoDomainBaseURL(*config.Domain)
 		return fmt.Sprintf("%s/oauth2/token", baseURL)
@@ -0,0 +1,73 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"golang.org/x/oauth2"
+)
+
+// ErrInvalidRefreshToken is returned when the provider rejects a refresh token as invalid, expired or revoked
+var ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+
+// refreshTimeout bounds a refresh request to the provider's token endpoint
+const refreshTimeout = 10 * time.Second
+
+// TokenSet holds the tokens issued by a refresh
+type TokenSet struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token,omitempty"`
+	// RefreshToken is the rotated refresh token, or the one refreshed when the provider does not rotate them
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is the lifetime of the access token in seconds, when the provider gives one
+	ExpiresIn int `json:"expires_in,omitempty"`
+}
+
+// RefreshTokens exchanges a refresh token for new tokens at the token endpoint of the provider's stored
+// configuration. A refresh token the provider rejects (invalid_grant) yields ErrInvalidRefreshToken.
+func (p *Provider) RefreshTokens(ctx context.Context, providerName, refreshToken string) (*TokenSet, error) {
+	config, err := p.GetConfig(ctx, providerName)
+	if err != nil {
+		return nil, err
+	}
+	return refreshTokens(ctx, config, getTokenEndpoint(config), refreshToken)
+}
+
+func refreshTokens(ctx context.Context, config *models.OIDCConfig, tokenURL, refreshToken string) (*TokenSet, error) {
+	clientSecret := ""
+	if config.ClientSecret != nil {
+		clientSecret = *config.ClientSecret
+	}
+	oauthConfig := &oauth2.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: clientSecret,
+		Endpoint:     oauth2.Endpoint{TokenURL: tokenURL},
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Timeout: refreshTimeout})
+	token, err := oauthConfig.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to refresh tokens: %w", err)
+	}
+	tokens := &TokenSet{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenType:    token.Type(),
+	}
+	if idToken, ok := token.Extra("id_token").(string); ok {
+		tokens.IDToken = idToken
+	}
+	if !token.Expiry.IsZero() {
+		tokens.ExpiresIn = max(int(time.Until(token.Expiry).Round(time.Second).Seconds()), 0)
+	}
+	return tokens, nil
+}
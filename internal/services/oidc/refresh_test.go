@@ -0,0 +1,80 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/models"
+)
+
+func TestRefreshTokens(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "refresh_token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PostForm.Get("refresh_token") {
+		case "valid":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "new-access",
+				"id_token":     "new-id",
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+			})
+		case "revoked":
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	config := &models.OIDCConfig{ClientID: "client-id", Issuer: server.URL}
+	tests := []struct {
+		name         string
+		refreshToken string
+		wantErr      error
+		wantAnyErr   bool
+	}{
+		{name: "valid", refreshToken: "valid"},
+		{name: "invalid grant", refreshToken: "revoked", wantErr: ErrInvalidRefreshToken},
+		{name: "provider error", refreshToken: "broken", wantAnyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tokens, err := refreshTokens(context.Background(), config, server.URL+"/oauth2/token", tt.refreshToken)
+			if tt.wantErr != nil || tt.wantAnyErr {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("refreshTokens() error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.wantAnyErr && errors.Is(err, ErrInvalidRefreshToken) {
+					t.Errorf("provider error reported as an invalid refresh token")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("refreshTokens() error = %v", err)
+			}
+			if tokens.AccessToken != "new-access" || tokens.IDToken != "new-id" || tokens.TokenType != "Bearer" {
+				t.Errorf("tokens = %+v", tokens)
+			}
+			// The provider did not rotate the refresh token, so the refreshed one is returned
+			if tokens.RefreshToken != "valid" {
+				t.Errorf("refresh token = %q, want the refreshed one", tokens.RefreshToken)
+			}
+			if tokens.ExpiresIn < 3590 || tokens.ExpiresIn > 3600 {
+				t.Errorf("expires_in = %d, want about 3600", tokens.ExpiresIn)
+			}
+		})
+	}
+}
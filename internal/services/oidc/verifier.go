@@ -3,8 +3,11 @@ package oidc
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/benvon/smart-todo/internal/models"
+	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 )
 
@@ -12,16 +15,27 @@ import (
 type Verifier struct {
 	jwksManager *JWKSManager
 	issuer      string
+	audiences   []string
+	rolesClaim  string
 }
 
-// NewVerifier creates a new JWT verifier
-func NewVerifier(jwksManager *JWKSManager, issuer string) *Verifier {
+// NewVerifier creates a new JWT verifier. When audiences are given, a token must name at least one of them
+// in its aud claim, which may hold several audiences.
+func NewVerifier(jwksManager *JWKSManager, issuer string, audiences ...string) *Verifier {
 	return &Verifier{
 		jwksManager: jwksManager,
 		issuer:      issuer,
+		audiences:   audiences,
 	}
 }
 
+// SetRolesClaim names the claim the user's roles or groups are read from into JWTClaims.Roles, e.g.
+// "cognito:groups", or a dotted path into nested claims such as "realm_access.roles". The claim may hold a
+// list or a space-separated string.
+func (v *Verifier) SetRolesClaim(claim string) {
+	v.rolesClaim = claim
+}
+
 const (
 	// MaxTokenSize is the maximum size for JWT tokens (8KB)
 	MaxTokenSize = 8 * 1024 // 8KB
@@ -32,7 +46,14 @@ func (v *Verifier) Verify(ctx context.Context, tokenString string, jwksURL strin
 	if len(tokenString) > MaxTokenSize {
 		return nil, fmt.Errorf("token exceeds maximum size of %d bytes", MaxTokenSize)
 	}
-	keys, err := v.jwksManager.GetJWKS(ctx, jwksURL)
+	msg, err := jws.Parse([]byte(tokenString))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+	if len(msg.Signatures()) != 1 {
+		return nil, fmt.Errorf("token must have exactly one signature")
+	}
+	keys, err := v.jwksManager.GetJWKSForKeyID(ctx, jwksURL, msg.Signatures()[0].ProtectedHeaders().KeyID())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get JWKS: %w", err)
 	}
@@ -43,7 +64,24 @@ func (v *Verifier) Verify(ctx context.Context, tokenString string, jwksURL strin
 	if err := jwt.Validate(token, jwt.WithIssuer(v.issuer)); err != nil {
 		return nil, fmt.Errorf("token issuer validation failed: %w", err)
 	}
-	return extractJWTClaims(token), nil
+	if len(v.audiences) > 0 && !audienceAllowed(token.Audience(), v.audiences) {
+		return nil, fmt.Errorf("token audience validation failed: none of %v is accepted", token.Audience())
+	}
+	claims := extractJWTClaims(token)
+	if v.rolesClaim != "" {
+		claims.Roles = stringsClaim(token, v.rolesClaim)
+	}
+	return claims, nil
+}
+
+// audienceAllowed reports whether any of the token's audiences is one of the allowed audiences
+func audienceAllowed(tokenAudiences, allowed []string) bool {
+	for _, aud := range tokenAudiences {
+		if slices.Contains(allowed, aud) {
+			return true
+		}
+	}
+	return false
 }
 
 // extractJWTClaims copies standard claims from the token into a JWTClaims struct.
@@ -56,9 +94,51 @@ func extractJWTClaims(token jwt.Token) *models.JWTClaims {
 	setInt64Claim(token, "exp", &claims.Exp)
 	setInt64Claim(token, "iat", &claims.Iat)
 	setAudClaim(token, &claims.Aud)
+	if v, ok := token.Get("email_verified"); ok {
+		if b, ok := v.(bool); ok {
+			claims.EmailVerified = &b
+		}
+	}
 	return claims
 }
 
+// stringsClaim returns the strings in the claim named name, which may be a dotted path into nested claims
+func stringsClaim(token jwt.Token, name string) []string {
+	v, ok := token.Get(name)
+	if !ok {
+		first, rest, nested := strings.Cut(name, ".")
+		if !nested {
+			return nil
+		}
+		v, ok = token.Get(first)
+		for ok && rest != "" {
+			claims, isObject := v.(map[string]any)
+			if !isObject {
+				return nil
+			}
+			var key string
+			key, rest, _ = strings.Cut(rest, ".")
+			v, ok = claims[key]
+		}
+		if !ok {
+			return nil
+		}
+	}
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
 func setStringClaim(token jwt.Token, key string, out *string) {
 	if v, ok := token.Get(key); ok {
 		if s, ok := v.(string); ok {
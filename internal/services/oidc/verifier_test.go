@@ -0,0 +1,70 @@
+package oidc
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+func TestVerifier_RolesAndEmailVerified(t *testing.T) {
+	t.Parallel()
+
+	const issuer = "https://idp.example.com/"
+	key := newTestSigningKey(t, "key-1")
+	endpoint := &fakeJWKSEndpoint{}
+	endpoint.serve(key)
+	clock := time.Now()
+	m := newTestJWKSManager(endpoint, &clock)
+
+	tests := []struct {
+		name              string
+		rolesClaim        string
+		claims            map[string]any
+		wantRoles         []string
+		wantEmailVerified *bool
+	}{
+		{name: "list claim", rolesClaim: "cognito:groups", claims: map[string]any{"cognito:groups": []string{"admin", "staff"}}, wantRoles: []string{"admin", "staff"}},
+		{name: "nested claim", rolesClaim: "realm_access.roles", claims: map[string]any{"realm_access": map[string]any{"roles": []string{"admin"}}}, wantRoles: []string{"admin"}},
+		{name: "space-separated claim", rolesClaim: "roles", claims: map[string]any{"roles": "admin  user"}, wantRoles: []string{"admin", "user"}},
+		{name: "missing claim", rolesClaim: "realm_access.roles", claims: map[string]any{"realm_access": "admin"}},
+		{name: "no roles claim configured", claims: map[string]any{"roles": []string{"admin"}}},
+		{name: "unverified email", claims: map[string]any{"email_verified": false}, wantEmailVerified: new(bool)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			builder := jwt.NewBuilder().Issuer(issuer).Audience([]string{"client-id"}).Subject("user-1").
+				Expiration(time.Now().Add(time.Hour))
+			for name, value := range tt.claims {
+				builder = builder.Claim(name, value)
+			}
+			token, err := builder.Build()
+			if err != nil {
+				t.Fatalf("build token: %v", err)
+			}
+			signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256, key.private))
+			if err != nil {
+				t.Fatalf("sign token: %v", err)
+			}
+
+			verifier := NewVerifier(m, issuer, "client-id")
+			verifier.SetRolesClaim(tt.rolesClaim)
+			claims, err := verifier.Verify(context.Background(), string(signed), testJWKSURL)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !slices.Equal(claims.Roles, tt.wantRoles) {
+				t.Errorf("roles = %v, want %v", claims.Roles, tt.wantRoles)
+			}
+			if (claims.EmailVerified == nil) != (tt.wantEmailVerified == nil) ||
+				(claims.EmailVerified != nil && *claims.EmailVerified != *tt.wantEmailVerified) {
+				t.Errorf("email_verified = %v, want %v", claims.EmailVerified, tt.wantEmailVerified)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Package supervisor starts a process's long-running components in order and stops them in
+// reverse order, waiting for each to finish within a per-component timeout.
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultStopTimeout is how long Shutdown waits for a single component to stop
+const DefaultStopTimeout = 10 * time.Second
+
+// Component is a long-running part of a process. Start launches its work and returns without
+// blocking; Stop asks it to finish and blocks until it has.
+type Component interface {
+	Name() string
+	Start(ctx context.Context) error
+	Stop()
+}
+
+// Supervisor owns a set of components and their lifecycle
+type Supervisor struct {
+	components  []Component
+	started     []Component
+	stopTimeout time.Duration
+	logger      *zap.Logger
+}
+
+// Option configures a Supervisor
+type Option func(*Supervisor)
+
+// WithStopTimeout sets the per-component stop timeout (default DefaultStopTimeout)
+func WithStopTimeout(d time.Duration) Option {
+	return func(s *Supervisor) {
+		if d > 0 {
+			s.stopTimeout = d
+		}
+	}
+}
+
+// New creates a supervisor for components, which are started in the given order
+func New(logger *zap.Logger, components []Component, opts ...Option) *Supervisor {
+	s := &Supervisor{
+		components:  components,
+		stopTimeout: DefaultStopTimeout,
+		logger:      logger,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Start starts every component in order. If one fails to start, the components already
+// started are stopped in reverse order and the error is returned.
+func (s *Supervisor) Start(ctx context.Context) error {
+	for _, c := range s.components {
+		if err := c.Start(ctx); err != nil {
+			s.Shutdown()
+			return fmt.Errorf("failed to start %s: %w", c.Name(), err)
+		}
+		s.started = append(s.started, c)
+		s.logger.Info("component_started", zap.String("component", c.Name()))
+	}
+	return nil
+}
+
+// Shutdown stops started components in reverse start order. Each Stop gets the per-component
+// timeout; a component that overruns is logged and left behind so the rest can still stop.
+// It returns the names of components that did not stop in time.
+func (s *Supervisor) Shutdown() []string {
+	var timedOut []string
+	for i := len(s.started) - 1; i >= 0; i-- {
+		c := s.started[i]
+		if !s.stopWithTimeout(c) {
+			timedOut = append(timedOut, c.Name())
+		}
+	}
+	s.started = nil
+	return timedOut
+}
+
+// Run starts the components, waits for a signal on sig or for ctx to end, then shuts down
+func (s *Supervisor) Run(ctx context.Context, sig <-chan os.Signal) ([]string, error) {
+	if err := s.Start(ctx); err != nil {
+		return nil, err
+	}
+	select {
+	case received := <-sig:
+		s.logger.Info("shutdown_signal_received", zap.String("signal", received.String()))
+	case <-ctx.Done():
+		s.logger.Info("shutdown_context_done")
+	}
+	return s.Shutdown(), nil
+}
+
+func (s *Supervisor) stopWithTimeout(c Component) bool {
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Stop()
+	}()
+	timer := time.NewTimer(s.stopTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		s.logger.Info("component_stopped",
+			zap.String("component", c.Name()),
+			zap.Int64("duration_ms", time.Since(start).Milliseconds()),
+		)
+		return true
+	case <-timer.C:
+		s.logger.Warn("component_stop_timeout",
+			zap.String("component", c.Name()),
+			zap.Duration("timeout", s.stopTimeout),
+		)
+		return false
+	}
+}
+
+// Func adapts a blocking run function into a Component. Start runs it in a goroutine with a
+// context that Stop cancels; Stop then waits for run to return.
+func Func(name string, run func(ctx context.Context)) Component {
+	return &funcComponent{name: name, run: run}
+}
+
+type funcComponent struct {
+	name   string
+	run    func(ctx context.Context)
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (f *funcComponent) Name() string { return f.name }
+
+func (f *funcComponent) Start(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	f.cancel = cancel
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.run(runCtx)
+	}()
+	return nil
+}
+
+func (f *funcComponent) Stop() {
+	if f.cancel != nil {
+		f.cancel()
+	}
+	f.wg.Wait()
+}
@@ -0,0 +1,167 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// recorder collects start and stop events across components in the order they happen
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) all() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+type fakeComponent struct {
+	name     string
+	rec      *recorder
+	startErr error
+	block    chan struct{} // when set, Stop blocks until it is closed
+}
+
+func (f *fakeComponent) Name() string { return f.name }
+
+func (f *fakeComponent) Start(ctx context.Context) error {
+	if f.startErr != nil {
+		return f.startErr
+	}
+	f.rec.add("start:" + f.name)
+	return nil
+}
+
+func (f *fakeComponent) Stop() {
+	if f.block != nil {
+		<-f.block
+	}
+	f.rec.add("stop:" + f.name)
+}
+
+func equalEvents(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSupervisor_RunStopsAllComponentsOnSIGTERM(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{}
+	loopExited := make(chan struct{})
+	components := []Component{
+		&fakeComponent{name: "gc", rec: rec},
+		Func("scheduler", func(ctx context.Context) {
+			defer close(loopExited)
+			<-ctx.Done()
+			rec.add("stop:scheduler")
+		}),
+		&fakeComponent{name: "consumer", rec: rec},
+	}
+	sup := New(zap.NewNop(), components, WithStopTimeout(time.Second))
+
+	sig := make(chan os.Signal, 1)
+	sig <- syscall.SIGTERM
+
+	start := time.Now()
+	timedOut, err := sup.Run(context.Background(), sig)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(timedOut) != 0 {
+		t.Errorf("timed out components = %v, want none", timedOut)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("shutdown took %v, want within the 1s deadline", elapsed)
+	}
+	select {
+	case <-loopExited:
+	default:
+		t.Error("Func component goroutine still running after shutdown")
+	}
+	want := []string{"start:gc", "start:consumer", "stop:consumer", "stop:scheduler", "stop:gc"}
+	if got := rec.all(); !equalEvents(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestSupervisor_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		stuck        bool
+		startErr     error
+		wantErr      bool
+		wantTimedOut []string
+		wantEvents   []string
+	}{
+		{
+			name:       "stops in reverse start order",
+			wantEvents: []string{"start:a", "start:b", "stop:b", "stop:a"},
+		},
+		{
+			name:         "stuck component times out and the rest still stop",
+			stuck:        true,
+			wantTimedOut: []string{"b"},
+			wantEvents:   []string{"start:a", "start:b", "stop:a"},
+		},
+		{
+			name:       "start failure stops already started components",
+			startErr:   errors.New("boom"),
+			wantErr:    true,
+			wantEvents: []string{"start:a", "stop:a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := &recorder{}
+			b := &fakeComponent{name: "b", rec: rec, startErr: tt.startErr}
+			if tt.stuck {
+				b.block = make(chan struct{})
+				defer close(b.block)
+			}
+			sup := New(zap.NewNop(), []Component{&fakeComponent{name: "a", rec: rec}, b}, WithStopTimeout(50*time.Millisecond))
+
+			err := sup.Start(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Start() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var timedOut []string
+			if err == nil {
+				timedOut = sup.Shutdown()
+			}
+			if !equalEvents(timedOut, tt.wantTimedOut) {
+				t.Errorf("timed out = %v, want %v", timedOut, tt.wantTimedOut)
+			}
+			if got := rec.all(); !equalEvents(got, tt.wantEvents) {
+				t.Errorf("events = %v, want %v", got, tt.wantEvents)
+			}
+		})
+	}
+}
@@ -12,8 +12,25 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
 )
 
+// TracerOption configures InitTracer
+type TracerOption func(*tracerOptions)
+
+type tracerOptions struct {
+	sampleRatio float64
+}
+
+// WithSampleRatio samples the given fraction (0-1) of new traces; the default samples all of them
+func WithSampleRatio(ratio float64) TracerOption {
+	return func(o *tracerOptions) { o.sampleRatio = ratio }
+}
+
 // InitTracer initializes the OpenTelemetry tracer provider
-func InitTracer(ctx context.Context, serviceName, endpoint string) (*sdktrace.TracerProvider, error) {
+func InitTracer(ctx context.Context, serviceName, endpoint string, opts ...TracerOption) (*sdktrace.TracerProvider, error) {
+	options := tracerOptions{sampleRatio: 1}
+	for _, o := range opts {
+		o(&options)
+	}
+
 	// Create OTLP HTTP exporter
 	exporter, err := otlptracehttp.New(ctx,
 		otlptracehttp.WithEndpoint(endpoint),
@@ -36,9 +53,9 @@ func InitTracer(ctx context.Context, serviceName, endpoint string) (*sdktrace.Tr
 
 	// Create tracer provider
 	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSpanProcessor(errorSamplingProcessor{next: sdktrace.NewBatchSpanProcessor(exporter)}),
 		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSampler(NewSampler(options.sampleRatio)),
 	)
 
 	// Set global tracer provider
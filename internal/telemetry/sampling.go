@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewSampler returns the sampler used for the given ratio. New traces are sampled with probability
+// ratio (by trace ID, so every service makes the same choice), while a sampling decision carried in an
+// inbound traceparent is honored as-is and the caller's trace ID is kept. Spans that lose the draw are
+// still recorded, rather than dropped, so errorSamplingProcessor can export them if they end in error.
+func NewSampler(ratio float64) sdktrace.Sampler {
+	return recordUnsampled{base: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))}
+}
+
+// recordUnsampled downgrades Drop decisions to RecordOnly
+type recordUnsampled struct {
+	base sdktrace.Sampler
+}
+
+func (s recordUnsampled) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	res := s.base.ShouldSample(p)
+	if res.Decision == sdktrace.Drop {
+		res.Decision = sdktrace.RecordOnly
+	}
+	return res
+}
+
+func (s recordUnsampled) Description() string {
+	return fmt.Sprintf("RecordUnsampled{%s}", s.base.Description())
+}
+
+// errorSamplingProcessor forwards sampled spans to next, plus unsampled spans that ended with an error
+// status, marked as sampled so the exporter keeps them. Other unsampled spans are discarded.
+type errorSamplingProcessor struct {
+	next sdktrace.SpanProcessor
+}
+
+func (p errorSamplingProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p errorSamplingProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.next.OnEnd(s)
+		return
+	}
+	if s.Status().Code == codes.Error {
+		p.next.OnEnd(forcedSampleSpan{ReadOnlySpan: s})
+	}
+}
+
+func (p errorSamplingProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p errorSamplingProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// forcedSampleSpan reports its span context with the sampled flag set
+type forcedSampleSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s forcedSampleSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
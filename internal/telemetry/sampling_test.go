@@ -0,0 +1,94 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewSampler_UsesConfiguredRatio(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ratio    float64
+		wantDesc string
+	}{
+		{ratio: 0.1, wantDesc: "TraceIDRatioBased{0.1}"},
+		{ratio: 0.5, wantDesc: "TraceIDRatioBased{0.5}"},
+		{ratio: 1, wantDesc: "TraceIDRatioBased{1}"},
+		{ratio: 0, wantDesc: "TraceIDRatioBased{0}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantDesc, func(t *testing.T) {
+			t.Parallel()
+			desc := NewSampler(tt.ratio).Description()
+			if !strings.Contains(desc, "ParentBased{root:"+tt.wantDesc) {
+				t.Errorf("Description() = %q, want parent-based root %s", desc, tt.wantDesc)
+			}
+		})
+	}
+}
+
+func TestSampling_ExportDecisions(t *testing.T) {
+	t.Parallel()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	tests := []struct {
+		name        string
+		ratio       float64
+		traceParent string
+		fail        bool
+		wantExport  bool
+	}{
+		{name: "ratio 0 drops ok span", ratio: 0, wantExport: false},
+		{name: "ratio 0 still exports error span", ratio: 0, fail: true, wantExport: true},
+		{name: "ratio 1 exports ok span", ratio: 1, wantExport: true},
+		{name: "inbound sampled parent is honored", ratio: 0, traceParent: "00-" + traceID + "-00f067aa0ba902b7-01", wantExport: true},
+		{name: "inbound unsampled parent is honored", ratio: 1, traceParent: "00-" + traceID + "-00f067aa0ba902b7-00", wantExport: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			exporter := tracetest.NewInMemoryExporter()
+			tp := sdktrace.NewTracerProvider(
+				sdktrace.WithSpanProcessor(errorSamplingProcessor{next: sdktrace.NewSimpleSpanProcessor(exporter)}),
+				sdktrace.WithSampler(NewSampler(tt.ratio)),
+			)
+			defer func() { _ = tp.Shutdown(context.Background()) }()
+
+			ctx := context.Background()
+			if tt.traceParent != "" {
+				carrier := propagation.MapCarrier{"traceparent": tt.traceParent}
+				ctx = propagation.TraceContext{}.Extract(ctx, carrier)
+			}
+			_, span := tp.Tracer("test").Start(ctx, "request")
+			if tt.fail {
+				span.SetStatus(codes.Error, "boom")
+			}
+			span.End()
+
+			spans := exporter.GetSpans()
+			if got := len(spans) == 1; got != tt.wantExport {
+				t.Fatalf("exported %d spans, want export = %v", len(spans), tt.wantExport)
+			}
+			if !tt.wantExport {
+				return
+			}
+			if !spans[0].SpanContext.IsSampled() {
+				t.Error("exported span should be marked sampled")
+			}
+			if tt.traceParent != "" && spans[0].SpanContext.TraceID().String() != traceID {
+				t.Errorf("trace ID = %s, want inbound %s", spans[0].SpanContext.TraceID(), traceID)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate and its key to dir and returns their paths
+func writeTestCert(t *testing.T, dir string) (certPath, keyPath string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "smart-todo-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() error = %v", err)
+	}
+
+	certPath = filepath.Join(dir, "cert.pem")
+	keyPath = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("WriteFile(cert) error = %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("WriteFile(key) error = %v", err)
+	}
+	return certPath, keyPath
+}
+
+func TestOptions_Build(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certPath, keyPath := writeTestCert(t, dir)
+	garbagePath := filepath.Join(dir, "garbage.pem")
+	if err := os.WriteFile(garbagePath, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("WriteFile(garbage) error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		wantNil  bool
+		wantErr  bool
+		validate func(t *testing.T, cfg *tls.Config)
+	}{
+		{
+			name:    "zero options build no config",
+			opts:    Options{},
+			wantNil: true,
+		},
+		{
+			name: "CA file populates root pool",
+			opts: Options{CAFile: certPath},
+			validate: func(t *testing.T, cfg *tls.Config) {
+				if cfg.RootCAs == nil {
+					t.Error("RootCAs is nil, want pool from CA file")
+				}
+				if len(cfg.Certificates) != 0 {
+					t.Errorf("Certificates = %d, want 0", len(cfg.Certificates))
+				}
+				if cfg.MinVersion != tls.VersionTLS12 {
+					t.Errorf("MinVersion = %x, want TLS 1.2", cfg.MinVersion)
+				}
+			},
+		},
+		{
+			name: "client certificate and key are loaded",
+			opts: Options{CertFile: certPath, KeyFile: keyPath},
+			validate: func(t *testing.T, cfg *tls.Config) {
+				if len(cfg.Certificates) != 1 {
+					t.Errorf("Certificates = %d, want 1", len(cfg.Certificates))
+				}
+				if cfg.RootCAs != nil {
+					t.Error("RootCAs set without CA file, want system pool")
+				}
+			},
+		},
+		{
+			name: "insecure skip verify only",
+			opts: Options{InsecureSkipVerify: true},
+			validate: func(t *testing.T, cfg *tls.Config) {
+				if !cfg.InsecureSkipVerify {
+					t.Error("InsecureSkipVerify = false, want true")
+				}
+			},
+		},
+		{
+			name:    "missing CA file",
+			opts:    Options{CAFile: filepath.Join(dir, "missing.pem")},
+			wantErr: true,
+		},
+		{
+			name:    "CA file without certificates",
+			opts:    Options{CAFile: garbagePath},
+			wantErr: true,
+		},
+		{
+			name:    "certificate without key",
+			opts:    Options{CertFile: certPath},
+			wantErr: true,
+		},
+		{
+			name:    "mismatched key file",
+			opts:    Options{CertFile: certPath, KeyFile: garbagePath},
+			wantErr: true,
+		},
+		{
+			name:    "directory instead of file",
+			opts:    Options{CAFile: dir},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := tt.opts.Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantNil {
+				if cfg != nil {
+					t.Errorf("Build() = %+v, want nil", cfg)
+				}
+				return
+			}
+			if cfg == nil {
+				t.Fatal("Build() = nil, want config")
+			}
+			if tt.validate != nil {
+				tt.validate(t, cfg)
+			}
+		})
+	}
+}
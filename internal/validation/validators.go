@@ -87,3 +87,11 @@ func ValidateTodoStatus(value string) error {
 		return fmt.Errorf("invalid status: %s (must be 'pending', 'processing', 'processed', or 'completed')", value)
 	}
 }
+
+// ValidateTodoPriority validates a Priority string value
+func ValidateTodoPriority(value string) error {
+	if !models.Priority(value).Valid() {
+		return fmt.Errorf("invalid priority: %s (must be 'low', 'medium', 'high', or 'urgent')", value)
+	}
+	return nil
+}
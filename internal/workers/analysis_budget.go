@@ -0,0 +1,72 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// AnalysisBudgetPrefix namespaces the Redis keys for the per-user background analysis budget
+const AnalysisBudgetPrefix = "ai_analysis_budget"
+
+// AnalysisBudget caps how many background AI analyses a user may run per minute across all workers
+type AnalysisBudget interface {
+	// Take books one analysis for the user and returns when it may run; a time not after now means at once
+	Take(ctx context.Context, userID uuid.UUID) (runAt time.Time, err error)
+}
+
+// analysisBudgetScript is a generic cell rate algorithm token bucket. The key holds the user's theoretical
+// arrival time (TAT) in Unix milliseconds: every booking moves it forward by one emission interval, and a
+// booking may run once the TAT is within the burst tolerance of the clock. Bookings past the budget still
+// move the TAT forward, so each deferred job is given its own later slot instead of all coming due together.
+//
+// KEYS[1] = budget key, ARGV[1] = now (ms), ARGV[2] = emission interval (ms), ARGV[3] = burst tolerance (ms).
+// Returns the time (ms) the booked analysis may run.
+var analysisBudgetScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local emission = tonumber(ARGV[2])
+local tolerance = tonumber(ARGV[3])
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+local next_tat = tat + emission
+redis.call('SET', KEYS[1], next_tat, 'PX', next_tat - now)
+return tat - tolerance
+`)
+
+// RedisAnalysisBudget is a token bucket in Redis shared by all workers. It holds jobsPerMinute tokens and
+// refills one every minute/jobsPerMinute, so a user may run a full minute's budget at once and is then held
+// to the steady rate.
+type RedisAnalysisBudget struct {
+	client    redis.Cmdable
+	prefix    string
+	emission  time.Duration
+	tolerance time.Duration
+}
+
+// NewRedisAnalysisBudget creates a Redis-backed budget of jobsPerMinute analyses per user; jobsPerMinute
+// must be positive
+func NewRedisAnalysisBudget(client redis.Cmdable, prefix string, jobsPerMinute int) *RedisAnalysisBudget {
+	emission := time.Minute / time.Duration(jobsPerMinute)
+	return &RedisAnalysisBudget{
+		client:    client,
+		prefix:    prefix,
+		emission:  emission,
+		tolerance: emission * time.Duration(jobsPerMinute-1),
+	}
+}
+
+// Take books one analysis against the user's budget
+func (b *RedisAnalysisBudget) Take(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	key := fmt.Sprintf("%s:tat:%s", b.prefix, userID)
+	runAt, err := analysisBudgetScript.Run(ctx, b.client, []string{key},
+		time.Now().UnixMilli(), b.emission.Milliseconds(), b.tolerance.Milliseconds()).Int64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to book analysis budget: %w", err)
+	}
+	return time.UnixMilli(runAt), nil
+}
@@ -0,0 +1,197 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryAnalysisBudget mirrors RedisAnalysisBudget semantics for a single process
+type memoryAnalysisBudget struct {
+	mu        sync.Mutex
+	emission  time.Duration
+	tolerance time.Duration
+	err       error
+	tat       map[uuid.UUID]time.Time
+	taken     int
+}
+
+func newMemoryAnalysisBudget(jobsPerMinute int) *memoryAnalysisBudget {
+	emission := time.Minute / time.Duration(jobsPerMinute)
+	return &memoryAnalysisBudget{
+		emission:  emission,
+		tolerance: emission * time.Duration(jobsPerMinute-1),
+		tat:       make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (m *memoryAnalysisBudget) Take(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.taken++
+	if m.err != nil {
+		return time.Time{}, m.err
+	}
+	now := time.Now()
+	tat := m.tat[userID]
+	if tat.Before(now) {
+		tat = now
+	}
+	m.tat[userID] = tat.Add(m.emission)
+	return tat.Add(-m.tolerance), nil
+}
+
+func newBudgetTestAnalyzer(t *testing.T, userID uuid.UUID, analyzed *int, jobQueue *mockJobQueue, opts ...TaskAnalyzerOption) *TaskAnalyzer {
+	t.Helper()
+	aiProvider := &mockAIProvider{
+		analyzeTaskWithDueDateFunc: func(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
+			*analyzed++
+			return []string{"work"}, models.TimeHorizonSoon, nil
+		},
+	}
+	todoRepo := &mockTodoRepo{
+		getByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Todo, error) {
+			return &models.Todo{ID: id, UserID: userID, Text: "todo", Status: models.TodoStatusPending}, nil
+		},
+		updateFunc: func(ctx context.Context, todo *models.Todo, oldTags []string) error {
+			return nil
+		},
+	}
+	return NewTaskAnalyzer(aiProvider, todoRepo, &mockAIContextRepo{}, &mockUserActivityRepo{}, nil, jobQueue, zap.NewNop(), opts...)
+}
+
+func TestTaskAnalyzer_AnalysisBudget_Burst(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	analyzed := 0
+	jobQueue := &mockJobQueue{}
+	budget := newMemoryAnalysisBudget(2)
+	analyzer := newBudgetTestAnalyzer(t, userID, &analyzed, jobQueue, WithAnalysisBudget(budget))
+
+	// Five todos created in a loop: the first two fit the budget, the rest get successive slots
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		todoID := uuid.New()
+		if err := analyzer.ProcessTaskAnalysisJob(context.Background(), queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoID)); err != nil {
+			t.Fatalf("job %d: ProcessTaskAnalysisJob() error = %v", i, err)
+		}
+	}
+
+	if analyzed != 2 {
+		t.Errorf("analyzed %d todos within the budget, want 2", analyzed)
+	}
+	if got := len(jobQueue.enqueueCalls); got != 3 {
+		t.Fatalf("enqueued %d deferred jobs, want 3", got)
+	}
+	var prev time.Time
+	for i, deferred := range jobQueue.enqueueCalls {
+		if deferred.NotBefore == nil || !deferred.NotBefore.After(start) || !deferred.NotBefore.After(prev) {
+			t.Errorf("deferred job %d not before %v, want a slot after %v", i, deferred.NotBefore, prev)
+			continue
+		}
+		if !jobMetadataBool(deferred, queue.MetadataAnalysisBudgeted) {
+			t.Errorf("deferred job %d missing %s metadata", i, queue.MetadataAnalysisBudgeted)
+		}
+		prev = *deferred.NotBefore
+	}
+	if want := start.Add(90 * time.Second); prev.Before(want.Add(-time.Second)) || prev.After(want.Add(time.Second)) {
+		t.Errorf("last deferred job not before %v, want about %v", prev, want)
+	}
+
+	// A deferred job holds its slot and runs without booking another
+	if err := analyzer.ProcessTaskAnalysisJob(context.Background(), jobQueue.enqueueCalls[0]); err != nil {
+		t.Fatalf("deferred ProcessTaskAnalysisJob() error = %v", err)
+	}
+	if analyzed != 3 || budget.taken != 5 {
+		t.Errorf("after the deferred job analyzed %d and booked %d, want 3 and 5", analyzed, budget.taken)
+	}
+}
+
+func TestTaskAnalyzer_AnalysisBudget_Bypass(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		manual     bool
+		budgetErr  error
+		wantBooked int
+	}{
+		{name: "manual analysis bypasses the budget", manual: true},
+		{name: "budget errors fail open", budgetErr: errors.New("redis down"), wantBooked: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			userID := uuid.New()
+			analyzed := 0
+			jobQueue := &mockJobQueue{}
+			budget := newMemoryAnalysisBudget(1)
+			budget.err = tt.budgetErr
+			analyzer := newBudgetTestAnalyzer(t, userID, &analyzed, jobQueue, WithAnalysisBudget(budget))
+
+			for i := 0; i < 3; i++ {
+				todoID := uuid.New()
+				job := queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoID)
+				job.Metadata[queue.MetadataManualAnalysis] = tt.manual
+				if err := analyzer.ProcessTaskAnalysisJob(context.Background(), job); err != nil {
+					t.Fatalf("ProcessTaskAnalysisJob() error = %v", err)
+				}
+			}
+			if analyzed != 3 {
+				t.Errorf("analyzed %d todos, want 3", analyzed)
+			}
+			if budget.taken != tt.wantBooked {
+				t.Errorf("booked %d analyses, want %d", budget.taken, tt.wantBooked)
+			}
+			if got := len(jobQueue.enqueueCalls); got != 0 {
+				t.Errorf("enqueued %d deferred jobs, want 0", got)
+			}
+		})
+	}
+}
+
+func TestTaskAnalyzer_AnalysisBudget_IntervalKeepsSlot(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	analyzed := 0
+	jobQueue := &mockJobQueue{}
+	budget := newMemoryAnalysisBudget(10)
+	now := time.Now()
+	analyzer := newBudgetTestAnalyzer(t, userID, &analyzed, jobQueue,
+		WithAnalysisBudget(budget),
+		WithAnalysisThrottle(newMemoryAnalysisThrottle(time.Minute, func() time.Time { return now })),
+	)
+
+	for i := 0; i < 2; i++ {
+		todoID := uuid.New()
+		if err := analyzer.ProcessTaskAnalysisJob(context.Background(), queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoID)); err != nil {
+			t.Fatalf("job %d: ProcessTaskAnalysisJob() error = %v", i, err)
+		}
+	}
+	if analyzed != 1 || len(jobQueue.enqueueCalls) != 1 {
+		t.Fatalf("analyzed %d and deferred %d, want 1 and 1", analyzed, len(jobQueue.enqueueCalls))
+	}
+	deferred := jobQueue.enqueueCalls[0]
+	if !jobMetadataBool(deferred, queue.MetadataAnalysisDeferred) || !jobMetadataBool(deferred, queue.MetadataAnalysisBudgeted) {
+		t.Fatalf("interval-deferred job metadata = %v, want it to keep its budget slot", deferred.Metadata)
+	}
+
+	now = now.Add(time.Minute)
+	if err := analyzer.ProcessTaskAnalysisJob(context.Background(), deferred); err != nil {
+		t.Fatalf("deferred ProcessTaskAnalysisJob() error = %v", err)
+	}
+	if analyzed != 2 || budget.taken != 2 {
+		t.Errorf("analyzed %d and booked %d, want 2 and 2", analyzed, budget.taken)
+	}
+}
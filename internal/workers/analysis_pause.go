@@ -0,0 +1,73 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// AnalysisPauseKey is the Redis key holding the global AI analysis pause
+	AnalysisPauseKey = "ai_analysis:paused"
+	// AnalysisPauseRecheckDelay is how long analysis jobs are held back before the pause is checked again
+	AnalysisPauseRecheckDelay = time.Minute
+)
+
+// AnalysisPause reports whether AI analysis is globally paused
+type AnalysisPause interface {
+	Status(ctx context.Context) (models.AIAnalysisPause, error)
+}
+
+// RedisAnalysisPause stores the global AI analysis pause in Redis so that every worker and API process
+// sees the same state. The key has no TTL; analysis stays paused until it is explicitly resumed.
+type RedisAnalysisPause struct {
+	client redis.Cmdable
+	now    func() time.Time
+}
+
+// NewRedisAnalysisPause creates a Redis-backed analysis pause
+func NewRedisAnalysisPause(client redis.Cmdable) *RedisAnalysisPause {
+	return &RedisAnalysisPause{client: client, now: time.Now}
+}
+
+// Status returns the current pause state; a missing key means analysis is running
+func (p *RedisAnalysisPause) Status(ctx context.Context) (models.AIAnalysisPause, error) {
+	raw, err := p.client.Get(ctx, AnalysisPauseKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return models.AIAnalysisPause{}, nil
+	}
+	if err != nil {
+		return models.AIAnalysisPause{}, fmt.Errorf("failed to read analysis pause key: %w", err)
+	}
+	var state models.AIAnalysisPause
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return models.AIAnalysisPause{}, fmt.Errorf("failed to decode analysis pause: %w", err)
+	}
+	state.Paused = true
+	return state, nil
+}
+
+// SetPaused pauses or resumes AI analysis and returns the new state
+func (p *RedisAnalysisPause) SetPaused(ctx context.Context, paused bool, reason string) (models.AIAnalysisPause, error) {
+	if !paused {
+		if err := p.client.Del(ctx, AnalysisPauseKey).Err(); err != nil {
+			return models.AIAnalysisPause{}, fmt.Errorf("failed to clear analysis pause key: %w", err)
+		}
+		return models.AIAnalysisPause{}, nil
+	}
+	since := p.now().UTC()
+	state := models.AIAnalysisPause{Paused: true, Reason: reason, Since: &since}
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return models.AIAnalysisPause{}, fmt.Errorf("failed to encode analysis pause: %w", err)
+	}
+	if err := p.client.Set(ctx, AnalysisPauseKey, raw, 0).Err(); err != nil {
+		return models.AIAnalysisPause{}, fmt.Errorf("failed to set analysis pause key: %w", err)
+	}
+	return state, nil
+}
@@ -0,0 +1,142 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryAnalysisPause is an in-memory kill switch for tests
+type memoryAnalysisPause struct {
+	mu     sync.Mutex
+	paused bool
+	err    error
+}
+
+func (m *memoryAnalysisPause) Status(ctx context.Context) (models.AIAnalysisPause, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return models.AIAnalysisPause{Paused: m.paused}, m.err
+}
+
+func (m *memoryAnalysisPause) set(paused bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = paused
+}
+
+func TestTaskAnalyzer_AnalysisPause(t *testing.T) {
+	t.Parallel()
+
+	userID, todoID := uuid.New(), uuid.New()
+	analyzed := 0
+	aiProvider := &mockAIProvider{
+		analyzeTaskWithDueDateFunc: func(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
+			analyzed++
+			return []string{"work"}, models.TimeHorizonSoon, nil
+		},
+	}
+	todoRepo := &mockTodoRepo{
+		getByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Todo, error) {
+			return &models.Todo{ID: id, UserID: userID, Text: "todo", Status: models.TodoStatusPending}, nil
+		},
+		updateFunc: func(ctx context.Context, todo *models.Todo, oldTags []string) error {
+			return nil
+		},
+	}
+	pause := &memoryAnalysisPause{paused: true}
+	jobQueue := &mockJobQueue{}
+	analyzer := NewTaskAnalyzer(aiProvider, todoRepo, &mockAIContextRepo{}, &mockUserActivityRepo{}, nil, jobQueue, zap.NewNop(),
+		WithAnalysisPause(pause),
+	)
+
+	job := queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoID)
+	acked := 0
+	msg := &mockMessage{job: job, ackFunc: func() error { acked++; return nil }}
+	before := time.Now()
+	if err := analyzer.ProcessJob(context.Background(), msg); err != nil {
+		t.Fatalf("ProcessJob() while paused error = %v", err)
+	}
+	if analyzed != 0 {
+		t.Errorf("analyzed %d todos while paused, want 0", analyzed)
+	}
+	if acked != 1 || len(jobQueue.enqueueCalls) != 1 {
+		t.Fatalf("acked %d, enqueued %d while paused, want the job acked and re-enqueued once", acked, len(jobQueue.enqueueCalls))
+	}
+	held := jobQueue.enqueueCalls[0]
+	if held.ID == job.ID || held.Type != job.Type || *held.TodoID != todoID || held.RetryCount != job.RetryCount {
+		t.Errorf("held job = %+v, want a new copy of job %s with retry count %d", held, job.ID, job.RetryCount)
+	}
+	if held.NotBefore == nil || held.NotBefore.Before(before.Add(AnalysisPauseRecheckDelay)) {
+		t.Errorf("held job not before %v, want at least %v from now", held.NotBefore, AnalysisPauseRecheckDelay)
+	}
+
+	// Once the pause is cleared the held job is analyzed as soon as it comes due
+	pause.set(false)
+	held.NotBefore = nil
+	if err := analyzer.ProcessJob(context.Background(), &mockMessage{job: held}); err != nil {
+		t.Fatalf("ProcessJob() after resume error = %v", err)
+	}
+	if analyzed != 1 {
+		t.Errorf("analyzed %d todos after resume, want 1", analyzed)
+	}
+	if len(jobQueue.enqueueCalls) != 1 {
+		t.Errorf("enqueued %d jobs after resume, want no more", len(jobQueue.enqueueCalls))
+	}
+}
+
+func TestTaskAnalyzer_AnalysisPause_JobTypes(t *testing.T) {
+	t.Parallel()
+
+	userID, todoID := uuid.New(), uuid.New()
+	tests := []struct {
+		name       string
+		job        *queue.Job
+		pauseErr   error
+		enqueueErr error
+		wantHeld   bool
+		wantErr    bool
+		wantNack   bool
+	}{
+		{name: "reprocess user held", job: queue.NewJob(queue.JobTypeReprocessUser, userID, nil), wantHeld: true},
+		{name: "other job types run", job: queue.NewJob(queue.JobTypeTagAnalysis, userID, nil)},
+		{name: "pause state unavailable", job: queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoID), pauseErr: errors.New("redis down")},
+		{name: "re-enqueue fails", job: queue.NewJob(queue.JobTypeReprocessUser, userID, nil), enqueueErr: errors.New("broker down"), wantHeld: true, wantErr: true, wantNack: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			processed := 0
+			jobQueue := &mockJobQueue{enqueueFunc: func(ctx context.Context, job *queue.Job) error { return tt.enqueueErr }}
+			analyzer := NewTaskAnalyzer(&mockAIProvider{}, &mockTodoRepo{}, &mockAIContextRepo{}, &mockUserActivityRepo{}, nil, jobQueue, zap.NewNop(),
+				WithAnalysisPause(&memoryAnalysisPause{paused: true, err: tt.pauseErr}),
+			)
+			proc := func(ctx context.Context, job *queue.Job) error { processed++; return nil }
+			analyzer.RegisterProcessor(tt.job.Type, proc, false)
+
+			var nacked *bool
+			msg := &mockMessage{job: tt.job, nackFunc: func(requeue bool) error { nacked = &requeue; return nil }}
+			err := analyzer.ProcessJob(context.Background(), msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessJob() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if held := len(jobQueue.enqueueCalls) == 1; held != tt.wantHeld {
+				t.Errorf("held = %v, want %v", held, tt.wantHeld)
+			}
+			if (processed == 1) == tt.wantHeld {
+				t.Errorf("processed %d times, want held %v", processed, tt.wantHeld)
+			}
+			if tt.wantNack && (nacked == nil || !*nacked) {
+				t.Errorf("nack requeue = %v, want true", nacked)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// AnalysisThrottlePrefix namespaces the Redis keys for background task analysis
+	AnalysisThrottlePrefix = "ai_analysis"
+	// ManualAnalysisThrottlePrefix namespaces the Redis keys for manually requested analysis
+	ManualAnalysisThrottlePrefix = "ai_manual_analysis"
+)
+
+// AnalysisThrottle enforces a minimum interval between a user's AI analyses across all workers
+type AnalysisThrottle interface {
+	// Reserve claims the user's analysis slot; when the slot is taken it returns the time the next one opens
+	Reserve(ctx context.Context, userID uuid.UUID) (ok bool, next time.Time, err error)
+	// MarkDeferred records that a deferred analysis is scheduled for the todo, returning false if one already is
+	MarkDeferred(ctx context.Context, userID, todoID uuid.UUID, ttl time.Duration) (bool, error)
+	// ClearDeferred removes the deferred marker once the todo's analysis runs
+	ClearDeferred(ctx context.Context, userID, todoID uuid.UUID) error
+}
+
+// RedisAnalysisThrottle throttles analyses with Redis keys shared by all processes. A last-run key
+// holding the timestamp of the user's latest analysis expires after the interval; while it exists
+// further analyses are deferred until it does.
+type RedisAnalysisThrottle struct {
+	client   redis.Cmdable
+	prefix   string
+	interval time.Duration
+}
+
+// NewRedisAnalysisThrottle creates a Redis-backed throttle; prefix separates independent limits
+func NewRedisAnalysisThrottle(client redis.Cmdable, prefix string, interval time.Duration) *RedisAnalysisThrottle {
+	return &RedisAnalysisThrottle{client: client, prefix: prefix, interval: interval}
+}
+
+// Reserve claims the user's analysis slot if the interval has elapsed since the last analysis
+func (t *RedisAnalysisThrottle) Reserve(ctx context.Context, userID uuid.UUID) (bool, time.Time, error) {
+	runKey := fmt.Sprintf("%s:last_run:%s", t.prefix, userID)
+	now := time.Now()
+	acquired, err := t.client.SetNX(ctx, runKey, now.Unix(), t.interval).Result()
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("failed to set analysis run key: %w", err)
+	}
+	if acquired {
+		return true, time.Time{}, nil
+	}
+	remaining, err := t.client.PTTL(ctx, runKey).Result()
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("failed to read analysis run key ttl: %w", err)
+	}
+	if remaining <= 0 {
+		remaining = t.interval
+	}
+	return false, now.Add(remaining), nil
+}
+
+// MarkDeferred records a scheduled deferred analysis for the todo
+func (t *RedisAnalysisThrottle) MarkDeferred(ctx context.Context, userID, todoID uuid.UUID, ttl time.Duration) (bool, error) {
+	marked, err := t.client.SetNX(ctx, t.deferredKey(userID, todoID), 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to set deferred analysis key: %w", err)
+	}
+	return marked, nil
+}
+
+// ClearDeferred removes the todo's deferred analysis marker
+func (t *RedisAnalysisThrottle) ClearDeferred(ctx context.Context, userID, todoID uuid.UUID) error {
+	if err := t.client.Del(ctx, t.deferredKey(userID, todoID)).Err(); err != nil {
+		return fmt.Errorf("failed to clear deferred analysis key: %w", err)
+	}
+	return nil
+}
+
+func (t *RedisAnalysisThrottle) deferredKey(userID, todoID uuid.UUID) string {
+	return fmt.Sprintf("%s:deferred:%s:%s", t.prefix, userID, todoID)
+}
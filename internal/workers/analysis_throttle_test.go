@@ -0,0 +1,206 @@
+package workers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryAnalysisThrottle mirrors RedisAnalysisThrottle semantics with an injectable clock
+type memoryAnalysisThrottle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	now      func() time.Time
+	lastRun  map[uuid.UUID]time.Time
+	deferred map[uuid.UUID]bool
+}
+
+func newMemoryAnalysisThrottle(interval time.Duration, now func() time.Time) *memoryAnalysisThrottle {
+	return &memoryAnalysisThrottle{
+		interval: interval,
+		now:      now,
+		lastRun:  make(map[uuid.UUID]time.Time),
+		deferred: make(map[uuid.UUID]bool),
+	}
+}
+
+func (m *memoryAnalysisThrottle) Reserve(ctx context.Context, userID uuid.UUID) (bool, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	last, ok := m.lastRun[userID]
+	if !ok || now.Sub(last) >= m.interval {
+		m.lastRun[userID] = now
+		return true, time.Time{}, nil
+	}
+	return false, last.Add(m.interval), nil
+}
+
+func (m *memoryAnalysisThrottle) MarkDeferred(ctx context.Context, userID, todoID uuid.UUID, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.deferred[todoID] {
+		return false, nil
+	}
+	m.deferred[todoID] = true
+	return true, nil
+}
+
+func (m *memoryAnalysisThrottle) ClearDeferred(ctx context.Context, userID, todoID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.deferred, todoID)
+	return nil
+}
+
+func TestTaskAnalyzer_AnalysisThrottle_RapidCreates(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	todoIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	var analyzeMu sync.Mutex
+	analyzed := 0
+	aiProvider := &mockAIProvider{
+		analyzeTaskWithDueDateFunc: func(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
+			analyzeMu.Lock()
+			defer analyzeMu.Unlock()
+			analyzed++
+			return []string{"work"}, models.TimeHorizonSoon, nil
+		},
+	}
+	todoRepo := &mockTodoRepo{
+		getByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Todo, error) {
+			return &models.Todo{ID: id, UserID: userID, Text: "todo", Status: models.TodoStatusPending}, nil
+		},
+		updateFunc: func(ctx context.Context, todo *models.Todo, oldTags []string) error {
+			return nil
+		},
+	}
+
+	now := time.Now()
+	clock := func() time.Time { return now }
+	jobQueue := &mockJobQueue{}
+	analyzer := NewTaskAnalyzer(aiProvider, todoRepo, &mockAIContextRepo{}, &mockUserActivityRepo{}, nil, jobQueue, zap.NewNop(),
+		WithAnalysisThrottle(newMemoryAnalysisThrottle(time.Minute, clock)),
+	)
+
+	// Three todos created back to back, plus a repeat analysis request for the second one
+	jobs := []*queue.Job{
+		queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoIDs[0]),
+		queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoIDs[1]),
+		queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoIDs[2]),
+		queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoIDs[1]),
+	}
+	for i, job := range jobs {
+		if err := analyzer.ProcessTaskAnalysisJob(context.Background(), job); err != nil {
+			t.Fatalf("job %d: ProcessTaskAnalysisJob() error = %v", i, err)
+		}
+	}
+
+	if analyzed != 1 {
+		t.Errorf("analyzed %d todos within the interval, want 1", analyzed)
+	}
+	if got := len(jobQueue.enqueueCalls); got != 2 {
+		t.Fatalf("enqueued %d deferred jobs, want 2 (repeat request coalesced)", got)
+	}
+	wantNext := now.Add(time.Minute)
+	for i, deferred := range jobQueue.enqueueCalls {
+		if deferred.Type != queue.JobTypeTaskAnalysis || deferred.NotBefore == nil || !deferred.NotBefore.Equal(wantNext) {
+			t.Errorf("deferred job %d = %+v, want task analysis not before %v", i, deferred, wantNext)
+		}
+		if !jobMetadataBool(deferred, queue.MetadataAnalysisDeferred) {
+			t.Errorf("deferred job %d missing %s metadata", i, queue.MetadataAnalysisDeferred)
+		}
+	}
+
+	// Both deferred jobs come due together: one runs, the other is pushed back one more interval
+	now = wantNext
+	deferred := append([]*queue.Job(nil), jobQueue.enqueueCalls...)
+	for _, job := range deferred {
+		if err := analyzer.ProcessTaskAnalysisJob(context.Background(), job); err != nil {
+			t.Fatalf("deferred ProcessTaskAnalysisJob() error = %v", err)
+		}
+	}
+	if analyzed != 2 {
+		t.Errorf("analyzed %d todos after first deferral, want 2", analyzed)
+	}
+	if got := len(jobQueue.enqueueCalls); got != 3 {
+		t.Fatalf("enqueued %d jobs after first deferral, want 3", got)
+	}
+	redeferred := jobQueue.enqueueCalls[2]
+	if redeferred.NotBefore == nil || !redeferred.NotBefore.Equal(wantNext.Add(time.Minute)) {
+		t.Errorf("re-deferred job not before %v, want %v", redeferred.NotBefore, wantNext.Add(time.Minute))
+	}
+
+	now = wantNext.Add(time.Minute)
+	if err := analyzer.ProcessTaskAnalysisJob(context.Background(), redeferred); err != nil {
+		t.Fatalf("re-deferred ProcessTaskAnalysisJob() error = %v", err)
+	}
+	if analyzed != 3 {
+		t.Errorf("analyzed %d todos in total, want 3", analyzed)
+	}
+}
+
+func TestTaskAnalyzer_AnalysisThrottle_Bypass(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		manual   bool
+		throttle bool
+	}{
+		{name: "manual analysis bypasses background interval", manual: true, throttle: true},
+		{name: "no throttle configured", throttle: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			userID := uuid.New()
+			analyzed := 0
+			aiProvider := &mockAIProvider{
+				analyzeTaskWithDueDateFunc: func(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
+					analyzed++
+					return []string{"work"}, models.TimeHorizonSoon, nil
+				},
+			}
+			todoRepo := &mockTodoRepo{
+				getByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Todo, error) {
+					return &models.Todo{ID: id, UserID: userID, Text: "todo", Status: models.TodoStatusPending}, nil
+				},
+				updateFunc: func(ctx context.Context, todo *models.Todo, oldTags []string) error {
+					return nil
+				},
+			}
+			var opts []TaskAnalyzerOption
+			if tt.throttle {
+				opts = append(opts, WithAnalysisThrottle(newMemoryAnalysisThrottle(time.Hour, time.Now)))
+			}
+			jobQueue := &mockJobQueue{}
+			analyzer := NewTaskAnalyzer(aiProvider, todoRepo, &mockAIContextRepo{}, &mockUserActivityRepo{}, nil, jobQueue, zap.NewNop(), opts...)
+
+			for i := 0; i < 2; i++ {
+				todoID := uuid.New()
+				job := queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoID)
+				job.Metadata[queue.MetadataManualAnalysis] = tt.manual
+				if err := analyzer.ProcessTaskAnalysisJob(context.Background(), job); err != nil {
+					t.Fatalf("ProcessTaskAnalysisJob() error = %v", err)
+				}
+			}
+			if analyzed != 2 {
+				t.Errorf("analyzed %d todos, want 2", analyzed)
+			}
+			if got := len(jobQueue.enqueueCalls); got != 0 {
+				t.Errorf("enqueued %d deferred jobs, want 0", got)
+			}
+		})
+	}
+}
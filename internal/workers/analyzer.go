@@ -33,6 +33,7 @@ type processorEntry struct {
 // TaskAnalyzer processes task analysis jobs
 type TaskAnalyzer struct {
 	aiProvider    ai.AIProvider
+	providers     AIProviderResolver
 	todoRepo      database.TodoRepositoryInterface
 	contextRepo   database.AIContextRepositoryInterface
 	activityRepo  database.UserActivityRepositoryInterface
@@ -43,6 +44,59 @@ type TaskAnalyzer struct {
 	cacheTTL      time.Duration
 	logger        *zap.Logger
 	registry      map[queue.JobType]processorEntry
+	throttle      AnalysisThrottle
+	budget        AnalysisBudget
+	pause         AnalysisPause
+	retryPolicies queue.RetryPolicies
+}
+
+// AIProviderResolver picks the AI provider to use for a user based on their AI context.
+type AIProviderResolver interface {
+	ProviderFor(userContext *models.AIContext) ai.AIProvider
+}
+
+// TaskAnalyzerOption configures a TaskAnalyzer.
+type TaskAnalyzerOption func(*TaskAnalyzer)
+
+// WithAIProviderResolver selects the AI provider per user instead of always using the default provider.
+func WithAIProviderResolver(resolver AIProviderResolver) TaskAnalyzerOption {
+	return func(a *TaskAnalyzer) {
+		a.providers = resolver
+	}
+}
+
+// WithAnalysisThrottle limits how often a user's todos are analyzed. Task analysis jobs arriving within
+// the interval are re-enqueued for the next allowed time, and repeat jobs for an already deferred todo
+// are dropped. Manually requested jobs are throttled by the API instead and bypass this limit.
+func WithAnalysisThrottle(throttle AnalysisThrottle) TaskAnalyzerOption {
+	return func(a *TaskAnalyzer) {
+		a.throttle = throttle
+	}
+}
+
+// WithAnalysisBudget caps how many background analyses a user may run per minute. A task analysis job past
+// the budget is re-enqueued for the slot the budget books for it, so a user enqueuing many jobs at once cannot
+// use up the AI provider quota shared by all users. Manually requested jobs bypass the budget.
+func WithAnalysisBudget(budget AnalysisBudget) TaskAnalyzerOption {
+	return func(a *TaskAnalyzer) {
+		a.budget = budget
+	}
+}
+
+// WithAnalysisPause honours the global AI analysis kill switch. While paused, task analysis and reprocess
+// jobs are re-enqueued to be checked again after AnalysisPauseRecheckDelay instead of being processed.
+func WithAnalysisPause(pause AnalysisPause) TaskAnalyzerOption {
+	return func(a *TaskAnalyzer) {
+		a.pause = pause
+	}
+}
+
+// WithRetryPolicies sets the retries and backoff of failed jobs per job type; job types without a policy, and
+// all of them without this option, use queue.DefaultRetryPolicy.
+func WithRetryPolicies(policies queue.RetryPolicies) TaskAnalyzerOption {
+	return func(a *TaskAnalyzer) {
+		a.retryPolicies = policies
+	}
 }
 
 // NewTaskAnalyzer creates a new task analyzer and registers task_analysis and reprocess_user processors.
@@ -54,6 +108,7 @@ func NewTaskAnalyzer(
 	tagStatsRepo database.TagStatisticsRepositoryInterface,
 	jobQueue queue.JobQueue,
 	logger *zap.Logger,
+	opts ...TaskAnalyzerOption,
 ) *TaskAnalyzer {
 	a := &TaskAnalyzer{
 		aiProvider:    aiProvider,
@@ -67,6 +122,9 @@ func NewTaskAnalyzer(
 		logger:        logger,
 		registry:      make(map[queue.JobType]processorEntry),
 	}
+	for _, o := range opts {
+		o(a)
+	}
 	a.RegisterProcessor(queue.JobTypeTaskAnalysis, a.ProcessTaskAnalysisJob, true)
 	a.RegisterProcessor(queue.JobTypeReprocessUser, a.ProcessReprocessUserJob, false)
 	return a
@@ -120,42 +178,62 @@ func (a *TaskAnalyzer) getTagStatistics(ctx context.Context, userID uuid.UUID) (
 	return stats, nil
 }
 
-// todoCreatedAt returns CreatedAt, or TimeEntered from metadata if present and parseable.
-func todoCreatedAt(todo *models.Todo) time.Time {
-	if todo.Metadata.TimeEntered != nil && *todo.Metadata.TimeEntered != "" {
-		if t, err := time.Parse(time.RFC3339, *todo.Metadata.TimeEntered); err == nil {
-			return t
-		}
-	}
-	return todo.CreatedAt
-}
-
 // analyzeTodoWithProvider runs AI analysis for a todo. It uses AnalyzeTaskWithDueDate when
-// the provider supports it, otherwise falls back to AnalyzeTask.
+// the provider supports it, otherwise falls back to AnalyzeTask. Returned tags that are one of
+// the user's aliases are rewritten to their canonical tag. The provider follows the user's
+// AI model preference when a resolver is configured.
 func (a *TaskAnalyzer) analyzeTodoWithProvider(ctx context.Context, job *queue.Job, todo *models.Todo, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
-	createdAt := todoCreatedAt(todo)
+	createdAt := todo.EnteredAt()
 	ctxWithIDs := context.WithValue(ctx, ai.UserIDContextKey(), job.UserID)
 	ctxWithIDs = context.WithValue(ctxWithIDs, ai.TodoIDContextKey(), todo.ID)
+	ctxWithIDs = context.WithValue(ctxWithIDs, ai.DueDateOnlyContextKey(), todo.Metadata.DueDateOnly)
+	ctxWithIDs = context.WithValue(ctxWithIDs, ai.PriorityContextKey(), todo.Priority)
 
-	providerWithDueDate, ok := a.aiProvider.(ai.AIProviderWithDueDate)
-	if ok {
-		return providerWithDueDate.AnalyzeTaskWithDueDate(ctxWithIDs, todo.Text, todo.DueDate, createdAt, userContext, tagStats)
+	provider := a.aiProvider
+	if a.providers != nil {
+		provider = a.providers.ProviderFor(userContext)
+	}
+
+	var tags []string
+	var timeHorizon models.TimeHorizon
+	var err error
+	if providerWithDueDate, ok := provider.(ai.AIProviderWithDueDate); ok {
+		tags, timeHorizon, err = providerWithDueDate.AnalyzeTaskWithDueDate(ctxWithIDs, todo.Text, todo.DueDate, createdAt, userContext, tagStats)
+	} else {
+		tags, timeHorizon, err = provider.AnalyzeTask(ctxWithIDs, todo.Text, userContext)
+	}
+	if err != nil {
+		return nil, "", err
 	}
-	return a.aiProvider.AnalyzeTask(ctxWithIDs, todo.Text, userContext)
+	if tags == nil {
+		// The model gave no tags; keep nil so the todo's existing tags are preserved
+		return nil, timeHorizon, nil
+	}
+	return userContext.CanonicalizeTags(tags), timeHorizon, nil
 }
 
 // ProcessTaskAnalysisJob processes a task analysis job
 func (a *TaskAnalyzer) ProcessTaskAnalysisJob(ctx context.Context, job *queue.Job) error {
+	ctx = database.WithChangeActor(ctx, models.ChangeActorAI)
 	if job.TodoID == nil {
 		return fmt.Errorf("todo_id is required for task analysis job")
 	}
+	if a.analysisOverBudget(ctx, job) || a.analysisThrottled(ctx, job) {
+		return nil
+	}
 	todo, err := a.todoRepo.GetByUserIDAndID(ctx, job.UserID, *job.TodoID)
 	if err != nil {
 		return fmt.Errorf("failed to get todo: %w", err)
 	}
+	if todo.Metadata.AnalysisDisabled {
+		a.logger.Debug("skipping_analysis_disabled_todo",
+			zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+		)
+		return nil
+	}
 	originalTags := todo.Metadata.CategoryTags
 	userContext, _ := a.contextRepo.GetByUserID(ctx, job.UserID)
-	tagStats, _ := a.getTagStatistics(ctx, job.UserID)
+	tagStats := a.tagStatisticsForJob(ctx, job)
 	if a.shouldSkipAnalysisForPausedUser(ctx, job.UserID) {
 		return nil
 	}
@@ -173,6 +251,138 @@ func (a *TaskAnalyzer) ProcessTaskAnalysisJob(ctx context.Context, job *queue.Jo
 	return nil
 }
 
+// deferredAnalysisMarkerGrace keeps a todo's deferred marker alive past its scheduled run in case the
+// deferred job is pushed back again by other todos competing for the same user's slot
+const deferredAnalysisMarkerGrace = time.Hour
+
+// analysisThrottled reports whether the job was deferred or coalesced by the per-user analysis interval
+// and should be acked without running. Throttle errors fail open so analyses are never lost to Redis outages.
+func (a *TaskAnalyzer) analysisThrottled(ctx context.Context, job *queue.Job) bool {
+	if a.throttle == nil || a.jobQueue == nil || jobMetadataBool(job, queue.MetadataManualAnalysis) {
+		return false
+	}
+	ok, next, err := a.throttle.Reserve(ctx, job.UserID)
+	if err != nil {
+		a.logger.Warn("analysis_throttle_failed",
+			zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return false
+	}
+	if ok {
+		if err := a.throttle.ClearDeferred(ctx, job.UserID, *job.TodoID); err != nil {
+			a.logger.Warn("failed_to_clear_deferred_analysis",
+				zap.String("todo_id", logpkg.SanitizeUserID(job.TodoID.String())),
+				zap.String("error", logpkg.SanitizeError(err)),
+			)
+		}
+		return false
+	}
+	return a.deferAnalysis(ctx, job, next)
+}
+
+// deferAnalysis re-enqueues the job for next unless a deferred run for the same todo is already scheduled
+func (a *TaskAnalyzer) deferAnalysis(ctx context.Context, job *queue.Job, next time.Time) bool {
+	if !jobMetadataBool(job, queue.MetadataAnalysisDeferred) {
+		marked, err := a.throttle.MarkDeferred(ctx, job.UserID, *job.TodoID, time.Until(next)+deferredAnalysisMarkerGrace)
+		if err != nil {
+			a.logger.Warn("analysis_throttle_failed",
+				zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+				zap.String("error", logpkg.SanitizeError(err)),
+			)
+			return false
+		}
+		if !marked {
+			a.logger.Debug("task_analysis_coalesced",
+				zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+				zap.String("todo_id", logpkg.SanitizeUserID(job.TodoID.String())),
+			)
+			return true
+		}
+	}
+	deferred := queue.NewJob(queue.JobTypeTaskAnalysis, job.UserID, job.TodoID)
+	deferred.NotBefore = &next
+	deferred.Metadata[queue.MetadataAnalysisDeferred] = true
+	if jobMetadataBool(job, queue.MetadataAnalysisBudgeted) {
+		deferred.Metadata[queue.MetadataAnalysisBudgeted] = true
+	}
+	if err := a.jobQueue.Enqueue(ctx, deferred); err != nil {
+		a.logger.Warn("failed_to_enqueue_deferred_analysis",
+			zap.String("todo_id", logpkg.SanitizeUserID(job.TodoID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		_ = a.throttle.ClearDeferred(ctx, job.UserID, *job.TodoID)
+		return false
+	}
+	a.logger.Debug("task_analysis_deferred",
+		zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+		zap.String("todo_id", logpkg.SanitizeUserID(job.TodoID.String())),
+		zap.Time("run_at", next),
+	)
+	return true
+}
+
+// analysisOverBudget reports whether the job was deferred because the user is past their analysis budget and
+// should be acked without running. A job keeps the slot it booked when it is deferred again by the analysis
+// interval, so it is charged only once. Budget errors fail open so analyses are never lost to Redis outages.
+func (a *TaskAnalyzer) analysisOverBudget(ctx context.Context, job *queue.Job) bool {
+	if a.budget == nil || a.jobQueue == nil || jobMetadataBool(job, queue.MetadataManualAnalysis) ||
+		jobMetadataBool(job, queue.MetadataAnalysisBudgeted) {
+		return false
+	}
+	runAt, err := a.budget.Take(ctx, job.UserID)
+	if err != nil {
+		a.logger.Warn("analysis_budget_failed",
+			zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return false
+	}
+	if job.Metadata == nil {
+		job.Metadata = make(map[string]any)
+	}
+	job.Metadata[queue.MetadataAnalysisBudgeted] = true
+	delay := time.Until(runAt)
+	if delay <= 0 {
+		return false
+	}
+
+	deferred := queue.NewJob(queue.JobTypeTaskAnalysis, job.UserID, job.TodoID)
+	deferred.NotBefore = &runAt
+	deferred.Metadata[queue.MetadataAnalysisBudgeted] = true
+	if err := a.jobQueue.Enqueue(ctx, deferred); err != nil {
+		a.logger.Warn("failed_to_enqueue_over_budget_analysis",
+			zap.String("todo_id", logpkg.SanitizeUserID(job.TodoID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return false
+	}
+	a.logger.Info("task_analysis_over_budget",
+		zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+		zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+		zap.String("todo_id", logpkg.SanitizeUserID(job.TodoID.String())),
+		zap.Duration("delay", delay),
+		zap.Time("run_at", runAt),
+	)
+	return true
+}
+
+// tagStatisticsForJob returns the tag statistics guidance for a task analysis job, or nil when the
+// request opted out of it
+func (a *TaskAnalyzer) tagStatisticsForJob(ctx context.Context, job *queue.Job) *models.TagStatistics {
+	if jobMetadataBool(job, queue.MetadataSkipTagStats) {
+		return nil
+	}
+	tagStats, _ := a.getTagStatistics(ctx, job.UserID)
+	return tagStats
+}
+
+// jobMetadataBool reads a boolean job metadata flag
+func jobMetadataBool(job *queue.Job, key string) bool {
+	v, _ := job.Metadata[key].(bool)
+	return v
+}
+
 func (a *TaskAnalyzer) shouldSkipAnalysisForPausedUser(ctx context.Context, userID uuid.UUID) bool {
 	activity, err := a.activityRepo.GetByUserID(ctx, userID)
 	if err != nil || activity == nil || !activity.ReprocessingPaused {
@@ -216,8 +426,9 @@ func (a *TaskAnalyzer) resetTodoToPendingOnError(ctx context.Context, todo *mode
 }
 
 func (a *TaskAnalyzer) applyAnalysisResultToTodo(todo *models.Todo, tags []string, timeHorizon models.TimeHorizon) {
-	existingUserTags := todo.Metadata.GetUserTags()
-	todo.Metadata.MergeTags(tags, existingUserTags)
+	if tags != nil {
+		todo.Metadata.MergeTags(tags, todo.Metadata.GetUserTags())
+	}
 	if todo.Metadata.TimeHorizonUserOverride == nil || !*todo.Metadata.TimeHorizonUserOverride {
 		todo.TimeHorizon = timeHorizon
 	}
@@ -238,6 +449,7 @@ func (a *TaskAnalyzer) logAnalyzedTodo(todo *models.Todo, tags []string, timeHor
 
 // ProcessReprocessUserJob processes a reprocess user job
 func (a *TaskAnalyzer) ProcessReprocessUserJob(ctx context.Context, job *queue.Job) error {
+	ctx = database.WithChangeActor(ctx, models.ChangeActorAI)
 	if a.shouldSkipReprocessingForPausedUser(ctx, job.UserID) {
 		return nil
 	}
@@ -294,11 +506,16 @@ func (a *TaskAnalyzer) reprocessTodos(ctx context.Context, job *queue.Job, todos
 			)
 			continue
 		}
-		todo.Metadata.MergeTags(tags, existingUserTags)
+		changed := tags != nil && todo.Metadata.MergeTags(tags, existingUserTags)
 		if (todo.Metadata.TimeHorizonUserOverride == nil || !*todo.Metadata.TimeHorizonUserOverride) && timeHorizon != originalTimeHorizon {
 			todo.TimeHorizon = timeHorizon
+			changed = true
 			updated++
 		}
+		// A todo the analysis left as it was is not written back
+		if !changed {
+			continue
+		}
 		if err := a.todoRepo.Update(ctx, todo, originalTags); err != nil {
 			a.logger.Error("failed_to_update_todo",
 				zap.String("operation", "reprocess_user_job"),
@@ -331,6 +548,9 @@ func (a *TaskAnalyzer) ProcessJob(ctx context.Context, msg queue.MessageInterfac
 		}
 		return nil
 	}
+	if a.analysisPaused(ctx, job) {
+		return a.holdWhilePaused(ctx, msg, job)
+	}
 	ent, ok := a.registry[job.Type]
 	if !ok {
 		a.nackOrLog(msg, false, job.ID.String())
@@ -355,6 +575,47 @@ func (a *TaskAnalyzer) ProcessJob(ctx context.Context, msg queue.MessageInterfac
 	return nil
 }
 
+// analysisPaused reports whether the job is an AI analysis job and analysis is globally paused. If the
+// pause state cannot be read the job runs, so a Redis outage does not stall analysis.
+func (a *TaskAnalyzer) analysisPaused(ctx context.Context, job *queue.Job) bool {
+	if a.pause == nil || a.jobQueue == nil {
+		return false
+	}
+	if job.Type != queue.JobTypeTaskAnalysis && job.Type != queue.JobTypeReprocessUser {
+		return false
+	}
+	state, err := a.pause.Status(ctx)
+	if err != nil {
+		a.logger.Warn("analysis_pause_check_failed",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return false
+	}
+	return state.Paused
+}
+
+// holdWhilePaused re-enqueues the job to run after AnalysisPauseRecheckDelay. Holding a job is not a
+// failed attempt, so its retry count is left unchanged; the held copy gets a new ID so it is not mistaken
+// for a redelivery of this (handled) job.
+func (a *TaskAnalyzer) holdWhilePaused(ctx context.Context, msg queue.MessageInterface, job *queue.Job) error {
+	notBefore := time.Now().Add(AnalysisPauseRecheckDelay)
+	held := *job
+	held.ID = uuid.New()
+	held.NotBefore = &notBefore
+	if err := a.jobQueue.Enqueue(ctx, &held); err != nil {
+		a.nackOrLog(msg, true, job.ID.String())
+		return fmt.Errorf("failed to re-enqueue job while analysis is paused: %w", err)
+	}
+	a.logger.Debug("ai_analysis_paused_job_deferred",
+		zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+		zap.String("job_type", string(job.Type)),
+		zap.Time("not_before", notBefore),
+	)
+	a.ackOrLog(msg, job.ID.String())
+	return nil
+}
+
 func buildDelayedJob(job *queue.Job, notBefore time.Time) *queue.Job {
 	return &queue.Job{
 		ID:         job.ID,
@@ -465,17 +726,43 @@ func (a *TaskAnalyzer) handleRateLimitError(ctx context.Context, msg queue.Messa
 	return a.sendToDLQ(msg, job, err, jobType)
 }
 
-func (a *TaskAnalyzer) handleGenericRetry(msg queue.MessageInterface, job *queue.Job, err error, jobType string) error {
-	job.IncrementRetry()
-	a.logger.Warn("job_failed_will_retry",
+// handleGenericRetry re-enqueues a failed job after the backoff of its type's retry policy, or requeues it
+// at once when there is no queue to re-enqueue to
+func (a *TaskAnalyzer) handleGenericRetry(ctx context.Context, msg queue.MessageInterface, job *queue.Job, err error, jobType string) error {
+	if a.jobQueue == nil {
+		job.IncrementRetry()
+		a.logger.Warn("job_failed_will_retry",
+			zap.String("job_type", jobType),
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.Int("attempt", job.RetryCount),
+			zap.Int("max_retries", job.MaxRetries),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		a.nackOrLog(msg, true, job.ID.String())
+		return fmt.Errorf("job failed (will retry): %w", err)
+	}
+	retryDelay := a.retryPolicies.For(job.Type).Delay(job.RetryCount)
+	notBefore := time.Now().Add(retryDelay)
+	delayedJob := buildDelayedJob(job, notBefore)
+	if enqErr := a.jobQueue.Enqueue(ctx, delayedJob); enqErr != nil {
+		a.logger.Error("failed_to_re_enqueue_failed_job",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("error", logpkg.SanitizeError(enqErr)),
+		)
+		a.nackOrLog(msg, true, job.ID.String())
+		return fmt.Errorf("job failed, failed to re-enqueue: %w", enqErr)
+	}
+	a.ackOrLog(msg, job.ID.String())
+	a.logger.Warn("job_failed_re_enqueued_with_backoff",
 		zap.String("job_type", jobType),
 		zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
-		zap.Int("attempt", job.RetryCount),
+		zap.Int("attempt", delayedJob.RetryCount),
 		zap.Int("max_retries", job.MaxRetries),
+		zap.Time("retry_at", notBefore),
+		zap.Duration("retry_delay", retryDelay),
 		zap.String("error", logpkg.SanitizeError(err)),
 	)
-	a.nackOrLog(msg, true, job.ID.String())
-	return fmt.Errorf("job failed (will retry): %w", err)
+	return nil
 }
 
 func (a *TaskAnalyzer) sendToDLQ(msg queue.MessageInterface, job *queue.Job, err error, jobType string) error {
@@ -491,16 +778,72 @@ func (a *TaskAnalyzer) sendToDLQ(msg queue.MessageInterface, job *queue.Job, err
 	return fmt.Errorf("job failed (max retries): %w", err)
 }
 
-// handleJobError handles errors from job processing with intelligent retry logic.
+// rejectInvalidRequest dead-letters a job whose AI request the provider rejected; retrying the same request
+// cannot succeed
+func (a *TaskAnalyzer) rejectInvalidRequest(msg queue.MessageInterface, job *queue.Job, err error, jobType string) error {
+	a.logger.Error("job_failed_invalid_ai_request",
+		zap.String("operation", "handle_job_error"),
+		zap.String("job_type", jobType),
+		zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+		zap.Int("retry_count", job.RetryCount),
+		zap.String("error", logpkg.SanitizeError(err)),
+	)
+	a.nackOrLog(msg, false, job.ID.String())
+	return fmt.Errorf("job failed (invalid AI request, not retried): %w", err)
+}
+
+// handleTimeoutError re-enqueues a job whose AI request timed out with the backoff of its retry policy,
+// counting the attempt against the job's retry limit. A model that is consistently slower than
+// AI_REQUEST_TIMEOUT will keep timing out, so unlike rate limits the job is dead-lettered once its retries
+// are used up.
+func (a *TaskAnalyzer) handleTimeoutError(ctx context.Context, msg queue.MessageInterface, job *queue.Job, err error, jobType string) error {
+	if !job.CanRetry() {
+		return a.sendToDLQ(msg, job, err, jobType)
+	}
+	if a.jobQueue == nil {
+		return a.handleGenericRetry(ctx, msg, job, err, jobType)
+	}
+	notBefore := time.Now().Add(a.retryPolicies.For(job.Type).Delay(job.RetryCount))
+	delayedJob := buildDelayedJob(job, notBefore)
+	if enqErr := a.jobQueue.Enqueue(ctx, delayedJob); enqErr != nil {
+		a.logger.Error("failed_to_re_enqueue_timed_out_job",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("error", logpkg.SanitizeError(enqErr)),
+		)
+		a.nackOrLog(msg, true, job.ID.String())
+		return fmt.Errorf("AI request timed out, failed to re-enqueue: %w", enqErr)
+	}
+	a.ackOrLog(msg, job.ID.String())
+	a.logger.Warn("ai_request_timed_out_re_enqueued",
+		zap.String("job_type", jobType),
+		zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+		zap.Int("attempt", delayedJob.RetryCount),
+		zap.Int("max_retries", job.MaxRetries),
+		zap.Time("retry_at", notBefore),
+		zap.String("error", logpkg.SanitizeError(err)),
+	)
+	return nil
+}
+
+// handleJobError handles errors from job processing with intelligent retry logic, by provider-agnostic
+// error kind: quota and rate limits are re-enqueued with the delay the provider asks for, invalid requests
+// are not retried, and timeouts and anything else (server errors, unknown failures) are re-enqueued with
+// the backoff of the job type's retry policy. The policy's retry limit applies to every kind of error, also
+// for jobs enqueued before the policy changed.
 func (a *TaskAnalyzer) handleJobError(ctx context.Context, msg queue.MessageInterface, job *queue.Job, err error, jobType string) error {
-	if ai.IsQuotaError(err) {
+	job.MaxRetries = a.retryPolicies.For(job.Type).MaxRetries
+	switch ai.ClassifyError(err) {
+	case ai.AIErrorQuota:
 		return a.handleQuotaError(ctx, msg, job, err, jobType)
-	}
-	if ai.IsRateLimitError(err) {
+	case ai.AIErrorRateLimit:
 		return a.handleRateLimitError(ctx, msg, job, err, jobType)
+	case ai.AIErrorTimeout:
+		return a.handleTimeoutError(ctx, msg, job, err, jobType)
+	case ai.AIErrorInvalidRequest:
+		return a.rejectInvalidRequest(msg, job, err, jobType)
 	}
 	if job.CanRetry() {
-		return a.handleGenericRetry(msg, job, err, jobType)
+		return a.handleGenericRetry(ctx, msg, job, err, jobType)
 	}
 	return a.sendToDLQ(msg, job, err, jobType)
 }
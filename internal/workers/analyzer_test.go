@@ -3,6 +3,8 @@ package workers
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -130,6 +132,85 @@ func (m *mockTodoRepo) GetByUserIDAndID(ctx context.Context, userID uuid.UUID, i
 	return nil, nil
 }
 
+func (m *mockTodoRepo) ListByUserID(ctx context.Context, userID uuid.UUID, filter database.TodoListFilter, page, pageSize int) ([]*models.Todo, int, error) {
+	return m.GetByUserIDPaginated(ctx, userID, filter.TimeHorizon, filter.Status, page, pageSize)
+}
+
+func (m *mockTodoRepo) ListByUserIDAfter(ctx context.Context, userID uuid.UUID, filter database.TodoListFilter, after *database.TodoCursor, limit int) ([]*models.Todo, error) {
+	m.t.Fatal("ListByUserIDAfter should not be called")
+	return nil, nil
+}
+
+func (m *mockTodoRepo) ListOpenByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*models.Todo, error) {
+	m.t.Fatal("ListOpenByUserID should not be called")
+	return nil, nil
+}
+
+func (m *mockTodoRepo) CountByStatusAndHorizon(ctx context.Context, userID uuid.UUID) (*models.TodoCounts, error) {
+	m.t.Fatal("CountByStatusAndHorizon should not be called")
+	return nil, nil
+}
+
+func (m *mockTodoRepo) GetDueWithin(ctx context.Context, window time.Duration) ([]*models.Todo, error) {
+	m.t.Fatal("GetDueWithin should not be called")
+	return nil, nil
+}
+
+func (m *mockTodoRepo) MarkReminderSent(ctx context.Context, userID, id uuid.UUID, dueDate, sentAt time.Time) (bool, error) {
+	m.t.Fatal("MarkReminderSent should not be called")
+	return false, nil
+}
+
+func (m *mockTodoRepo) GetByExternalRef(ctx context.Context, userID uuid.UUID, system, id string) (*models.Todo, error) {
+	m.t.Fatal("GetByExternalRef should not be called")
+	return nil, nil
+}
+
+func (m *mockTodoRepo) MergeTodos(ctx context.Context, userID, targetID, sourceID uuid.UUID, merge func(target, source *models.Todo) error) (*models.Todo, error) {
+	m.t.Fatal("MergeTodos should not be called")
+	return nil, nil
+}
+
+func (m *mockTodoRepo) BulkUpdateDueDates(ctx context.Context, userID uuid.UUID, sel database.TodoBulkSelection, apply func(todo *models.Todo) bool) ([]*models.Todo, error) {
+	m.t.Fatal("BulkUpdateDueDates should not be called")
+	return nil, nil
+}
+
+func (m *mockTodoRepo) BulkUpdateStatus(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, status models.TodoStatus) ([]uuid.UUID, error) {
+	m.t.Fatal("BulkUpdateStatus should not be called")
+	return nil, nil
+}
+
+func (m *mockTodoRepo) BulkDelete(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]uuid.UUID, error) {
+	m.t.Fatal("BulkDelete should not be called")
+	return nil, nil
+}
+
+func (m *mockTodoRepo) ResetAITags(ctx context.Context, userID uuid.UUID, requeue bool) (int, error) {
+	m.t.Fatal("ResetAITags should not be called")
+	return 0, nil
+}
+
+func (m *mockTodoRepo) RenameTag(ctx context.Context, userID uuid.UUID, from []string, to string) (int, error) {
+	m.t.Fatal("RenameTag should not be called")
+	return 0, nil
+}
+
+func (m *mockTodoRepo) RemoveTagFromAll(ctx context.Context, userID uuid.UUID, tag string) (int, error) {
+	m.t.Fatal("RemoveTagFromAll should not be called")
+	return 0, nil
+}
+
+func (m *mockTodoRepo) Create(ctx context.Context, todo *models.Todo) error {
+	m.t.Fatal("Create should not be called")
+	return nil
+}
+
+func (m *mockTodoRepo) CreateBatch(ctx context.Context, todos []*models.Todo) error {
+	m.t.Fatal("CreateBatch should not be called")
+	return nil
+}
+
 func (m *mockTodoRepo) Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
 	m.mu.Lock()
 	m.deleteCalls = append(m.deleteCalls, struct{ userID, id uuid.UUID }{userID, id})
@@ -328,6 +409,9 @@ func TestTaskAnalyzer_ProcessTaskAnalysisJob(t *testing.T) {
 						}, nil
 					},
 					updateFunc: func(ctx context.Context, todo *models.Todo, oldTags []string) error {
+						if actor := database.ChangeActorFromContext(ctx); actor != models.ChangeActorAI {
+							return fmt.Errorf("update attributed to %q, want ai", actor)
+						}
 						return nil
 					},
 				}
@@ -343,6 +427,52 @@ func TestTaskAnalyzer_ProcessTaskAnalysisJob(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "normalizes AI-returned alias to canonical tag",
+			job: &queue.Job{
+				ID:     uuid.New(),
+				Type:   queue.JobTypeTaskAnalysis,
+				UserID: userID,
+				TodoID: &todoID,
+			},
+			setupMocks: func() (*mockAIProvider, *mockTodoRepo, *mockAIContextRepo, *mockUserActivityRepo, *mockJobQueue) {
+				aiProvider := &mockAIProvider{
+					analyzeTaskWithDueDateFunc: func(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
+						return []string{"Chores", "errands", "home"}, models.TimeHorizonSoon, nil
+					},
+				}
+				todoRepo := &mockTodoRepo{
+					getByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Todo, error) {
+						return &models.Todo{ID: id, UserID: userID, Text: "Pick up dry cleaning", Status: models.TodoStatusPending}, nil
+					},
+					updateFunc: func(ctx context.Context, todo *models.Todo, oldTags []string) error {
+						return nil
+					},
+				}
+				contextRepo := &mockAIContextRepo{
+					getByUserIDFunc: func(ctx context.Context, uid uuid.UUID) (*models.AIContext, error) {
+						return &models.AIContext{UserID: uid, TagAliases: map[string]string{"chores": "errands"}}, nil
+					},
+				}
+				return aiProvider, todoRepo, contextRepo, &mockUserActivityRepo{}, &mockJobQueue{}
+			},
+			expectError: false,
+			validateTodo: func(t *testing.T, todo *models.Todo) {
+				want := []string{"errands", "home"}
+				if len(todo.Metadata.CategoryTags) != len(want) {
+					t.Fatalf("CategoryTags = %v, want %v", todo.Metadata.CategoryTags, want)
+				}
+				for i, tag := range want {
+					if todo.Metadata.CategoryTags[i] != tag {
+						t.Errorf("CategoryTags = %v, want %v", todo.Metadata.CategoryTags, want)
+						break
+					}
+				}
+				if _, ok := todo.Metadata.TagSources["Chores"]; ok {
+					t.Error("alias should not be recorded as a tag source")
+				}
+			},
+		},
 		{
 			name: "preserves user-set time horizon",
 			job: &queue.Job{
@@ -505,6 +635,31 @@ func TestTaskAnalyzer_ProcessTaskAnalysisJob(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "todo opted out of analysis is skipped",
+			job: &queue.Job{
+				ID:     uuid.New(),
+				Type:   queue.JobTypeTaskAnalysis,
+				UserID: userID,
+				TodoID: &todoID,
+			},
+			setupMocks: func() (*mockAIProvider, *mockTodoRepo, *mockAIContextRepo, *mockUserActivityRepo, *mockJobQueue) {
+				// AI provider and Update are not configured: calling either fails the test
+				todoRepo := &mockTodoRepo{
+					getByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Todo, error) {
+						return &models.Todo{
+							ID:       id,
+							UserID:   userID,
+							Text:     "Private note",
+							Status:   models.TodoStatusPending,
+							Metadata: models.Metadata{AnalysisDisabled: true},
+						}, nil
+					},
+				}
+				return &mockAIProvider{}, todoRepo, &mockAIContextRepo{}, &mockUserActivityRepo{}, &mockJobQueue{}
+			},
+			expectError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -963,3 +1118,356 @@ func TestTaskAnalyzer_TimeContext(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+type stubProviderResolver struct {
+	providers map[string]ai.AIProvider
+	fallback  ai.AIProvider
+}
+
+func (r *stubProviderResolver) ProviderFor(userContext *models.AIContext) ai.AIProvider {
+	if p, ok := r.providers[userContext.AIModelPreference().Model]; ok {
+		return p
+	}
+	return r.fallback
+}
+
+func TestTaskAnalyzer_AnalyzeTodoWithProvider_PerUserProvider(t *testing.T) {
+	t.Parallel()
+
+	newProvider := func(tag string) *mockAIProvider {
+		return &mockAIProvider{
+			t: t,
+			analyzeTaskWithDueDateFunc: func(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
+				return []string{tag}, models.TimeHorizonSoon, nil
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		preferences map[string]any
+		wantTag     string
+	}{
+		{"no preference uses default", nil, "default"},
+		{"preferred model", map[string]any{models.PreferenceAIModel: "gpt-4o"}, "gpt-4o"},
+		{"unknown model uses default", map[string]any{models.PreferenceAIModel: "other"}, "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			defaultProvider := newProvider("default")
+			resolver := &stubProviderResolver{
+				providers: map[string]ai.AIProvider{"gpt-4o": newProvider("gpt-4o")},
+				fallback:  defaultProvider,
+			}
+			analyzer := NewTaskAnalyzer(defaultProvider, nil, nil, nil, nil, nil, zap.NewNop(), WithAIProviderResolver(resolver))
+
+			userID := uuid.New()
+			todo := &models.Todo{ID: uuid.New(), UserID: userID, Text: "Plan trip", CreatedAt: time.Now()}
+			userContext := &models.AIContext{UserID: userID, Preferences: tt.preferences}
+			job := queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todo.ID)
+
+			tags, _, err := analyzer.analyzeTodoWithProvider(context.Background(), job, todo, userContext, nil)
+			if err != nil {
+				t.Fatalf("analyzeTodoWithProvider() error = %v", err)
+			}
+			if len(tags) != 1 || tags[0] != tt.wantTag {
+				t.Errorf("tags = %v, want [%s]", tags, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestTaskAnalyzer_ProcessTaskAnalysisJob_SkipTagStats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		skip          bool
+		wantTagStats  bool
+		wantRepoCalls int
+	}{
+		{"tag stats used by default", false, true, 1},
+		{"tag stats omitted when disabled", true, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todoID := uuid.New()
+			stats := &models.TagStatistics{UserID: userID, TagStats: map[string]models.TagStats{"work": {Total: 3}}}
+
+			var gotTagStats *models.TagStatistics
+			aiProvider := &mockAIProvider{
+				t: t,
+				analyzeTaskWithDueDateFunc: func(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
+					gotTagStats = tagStats
+					return []string{"work"}, models.TimeHorizonSoon, nil
+				},
+			}
+			todoRepo := &mockTodoRepo{
+				t: t,
+				getByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Todo, error) {
+					return &models.Todo{ID: id, UserID: userID, Text: "Write report", Status: models.TodoStatusPending}, nil
+				},
+				updateFunc: func(ctx context.Context, todo *models.Todo, oldTags []string) error {
+					return nil
+				},
+			}
+			tagStatsRepo := &mockTagStatisticsRepoForWorker{
+				t: t,
+				getByUserIDFunc: func(ctx context.Context, id uuid.UUID) (*models.TagStatistics, error) {
+					return stats, nil
+				},
+			}
+			analyzer := NewTaskAnalyzer(aiProvider, todoRepo, &mockAIContextRepo{t: t}, &mockUserActivityRepo{}, tagStatsRepo, nil, zap.NewNop())
+
+			job := queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoID)
+			if tt.skip {
+				job.Metadata[queue.MetadataSkipTagStats] = true
+			}
+			if err := analyzer.ProcessTaskAnalysisJob(context.Background(), job); err != nil {
+				t.Fatalf("ProcessTaskAnalysisJob() error = %v", err)
+			}
+			if (gotTagStats != nil) != tt.wantTagStats {
+				t.Errorf("provider tag stats = %v, want present = %v", gotTagStats, tt.wantTagStats)
+			}
+			if got := len(tagStatsRepo.getByUserIDCalls); got != tt.wantRepoCalls {
+				t.Errorf("tag stats lookups = %d, want %d", got, tt.wantRepoCalls)
+			}
+		})
+	}
+}
+
+func TestTaskAnalyzer_ReprocessTodos_SkipsUnchangedTodos(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	unchanged := &models.Todo{ID: uuid.New(), UserID: userID, Text: "Write report", Status: models.TodoStatusPending,
+		TimeHorizon: models.TimeHorizonNext, Metadata: models.Metadata{
+			CategoryTags: []string{"work"}, TagSources: map[string]models.TagSource{"work": models.TagSourceAI},
+		}}
+	retagged := &models.Todo{ID: uuid.New(), UserID: userID, Text: "File taxes", Status: models.TodoStatusPending,
+		TimeHorizon: models.TimeHorizonNext}
+	aiProvider := &mockAIProvider{
+		t: t,
+		analyzeTaskWithDueDateFunc: func(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
+			return []string{"work"}, models.TimeHorizonNext, nil
+		},
+	}
+	todoRepo := &mockTodoRepo{
+		t: t,
+		updateFunc: func(ctx context.Context, todo *models.Todo, oldTags []string) error {
+			return nil
+		},
+	}
+	analyzer := NewTaskAnalyzer(aiProvider, todoRepo, &mockAIContextRepo{t: t}, &mockUserActivityRepo{}, nil, nil, zap.NewNop())
+
+	job := queue.NewJob(queue.JobTypeReprocessUser, userID, nil)
+	analyzer.reprocessTodos(context.Background(), job, []*models.Todo{unchanged, retagged}, nil)
+
+	if len(todoRepo.updateCalls) != 1 || todoRepo.updateCalls[0].ID != retagged.ID {
+		t.Fatalf("updated %d todos, want only the retagged one", len(todoRepo.updateCalls))
+	}
+}
+
+func TestTaskAnalyzer_ProcessTaskAnalysisJob_IncompleteResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		tags        []string
+		horizon     models.TimeHorizon
+		wantTags    []string
+		wantHorizon models.TimeHorizon
+	}{
+		{name: "null tags keep existing tags", tags: nil, horizon: models.TimeHorizonLater, wantTags: []string{"errands", "home"}, wantHorizon: models.TimeHorizonLater},
+		{name: "empty tags keep existing tags", tags: []string{}, horizon: models.TimeHorizonLater, wantTags: []string{"errands", "home"}, wantHorizon: models.TimeHorizonLater},
+		{name: "missing horizon still applies tags", tags: []string{"chores"}, horizon: models.TimeHorizonSoon, wantTags: []string{"errands", "home"}, wantHorizon: models.TimeHorizonSoon},
+		{name: "new tags are merged", tags: []string{"shopping"}, horizon: models.TimeHorizonNext, wantTags: []string{"errands", "home", "shopping"}, wantHorizon: models.TimeHorizonNext},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todoID := uuid.New()
+			aiProvider := &mockAIProvider{
+				t: t,
+				analyzeTaskWithDueDateFunc: func(ctx context.Context, text string, dueDate *time.Time, createdAt time.Time, userContext *models.AIContext, tagStats *models.TagStatistics) ([]string, models.TimeHorizon, error) {
+					return tt.tags, tt.horizon, nil
+				},
+			}
+			todoRepo := &mockTodoRepo{
+				t: t,
+				getByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Todo, error) {
+					return &models.Todo{
+						ID:          id,
+						UserID:      userID,
+						Text:        "Buy groceries",
+						Status:      models.TodoStatusPending,
+						TimeHorizon: models.TimeHorizonNext,
+						Metadata: models.Metadata{
+							CategoryTags: []string{"errands", "home"},
+							TagSources:   map[string]models.TagSource{"errands": models.TagSourceAI, "home": models.TagSourceUser},
+						},
+					}, nil
+				},
+				updateFunc: func(ctx context.Context, todo *models.Todo, oldTags []string) error {
+					return nil
+				},
+			}
+			// Aliases make canonicalization build a new slice, which must not turn nil tags into an empty list
+			contextRepo := &mockAIContextRepo{
+				t: t,
+				getByUserIDFunc: func(ctx context.Context, uid uuid.UUID) (*models.AIContext, error) {
+					return &models.AIContext{UserID: uid, TagAliases: map[string]string{"chores": "errands"}}, nil
+				},
+			}
+			analyzer := NewTaskAnalyzer(aiProvider, todoRepo, contextRepo, &mockUserActivityRepo{}, nil, nil, zap.NewNop())
+
+			if err := analyzer.ProcessTaskAnalysisJob(context.Background(), queue.NewJob(queue.JobTypeTaskAnalysis, userID, &todoID)); err != nil {
+				t.Fatalf("ProcessTaskAnalysisJob() error = %v", err)
+			}
+			if len(todoRepo.updateCalls) == 0 {
+				t.Fatal("expected the todo to be updated")
+			}
+			todo := todoRepo.updateCalls[len(todoRepo.updateCalls)-1]
+			if !slices.Equal(todo.Metadata.CategoryTags, tt.wantTags) {
+				t.Errorf("tags = %v, want %v", todo.Metadata.CategoryTags, tt.wantTags)
+			}
+			if todo.Metadata.TagSources["home"] != models.TagSourceUser {
+				t.Errorf("user tag source = %q, want user", todo.Metadata.TagSources["home"])
+			}
+			if todo.TimeHorizon != tt.wantHorizon {
+				t.Errorf("time horizon = %s, want %s", todo.TimeHorizon, tt.wantHorizon)
+			}
+		})
+	}
+}
+
+func TestTaskAnalyzer_HandleJobError_ByErrorKind(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		err         error
+		wantAcked   bool
+		wantNack    []bool
+		wantDelayed bool
+	}{
+		{"quota is re-enqueued with a delay", &ai.ProviderError{Kind: ai.AIErrorQuota, Err: errors.New("quota")}, true, nil, true},
+		{"rate limit is re-enqueued with a delay", &ai.ProviderError{Kind: ai.AIErrorRateLimit, Err: errors.New("slow down")}, true, nil, true},
+		{"invalid request is not retried", &ai.ProviderError{Kind: ai.AIErrorInvalidRequest, Err: errors.New("bad model")}, false, []bool{false}, false},
+		{"server error is re-enqueued with a backoff", &ai.ProviderError{Kind: ai.AIErrorServer, Err: errors.New("502")}, true, nil, true},
+		{"timeout is re-enqueued with a backoff", &ai.ProviderError{Kind: ai.AIErrorTimeout, Err: context.DeadlineExceeded}, true, nil, true},
+		{"unknown error is re-enqueued with a backoff", errors.New("todo not found"), true, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			jobQueue := &mockJobQueue{t: t}
+			analyzer := NewTaskAnalyzer(&mockAIProvider{t: t}, &mockTodoRepo{t: t}, &mockAIContextRepo{t: t}, &mockUserActivityRepo{t: t}, nil, jobQueue, zap.NewNop())
+			job := queue.NewJob(queue.JobTypeTaskAnalysis, uuid.New(), nil)
+			acked := false
+			var nacks []bool
+			msg := &mockMessage{
+				job:      job,
+				ackFunc:  func() error { acked = true; return nil },
+				nackFunc: func(requeue bool) error { nacks = append(nacks, requeue); return nil },
+			}
+
+			if err := analyzer.handleJobError(context.Background(), msg, job, tt.err, "task_analysis"); tt.wantDelayed != (err == nil) {
+				t.Errorf("handleJobError() error = %v", err)
+			}
+			if acked != tt.wantAcked || !slices.Equal(nacks, tt.wantNack) {
+				t.Errorf("acked = %v, nacks = %v; want acked = %v, nacks = %v", acked, nacks, tt.wantAcked, tt.wantNack)
+			}
+			if delayed := len(jobQueue.enqueueCalls) == 1 && jobQueue.enqueueCalls[0].NotBefore != nil; delayed != tt.wantDelayed {
+				t.Errorf("re-enqueued with delay = %v, want %v", delayed, tt.wantDelayed)
+			}
+		})
+	}
+}
+
+func TestTaskAnalyzer_HandleJobError_TimeoutUsesRetryLimit(t *testing.T) {
+	t.Parallel()
+
+	timeoutErr := &ai.ProviderError{Kind: ai.AIErrorTimeout, Err: context.DeadlineExceeded}
+	jobQueue := &mockJobQueue{t: t}
+	analyzer := NewTaskAnalyzer(&mockAIProvider{t: t}, &mockTodoRepo{t: t}, &mockAIContextRepo{t: t}, &mockUserActivityRepo{t: t}, nil, jobQueue, zap.NewNop())
+	job := queue.NewJob(queue.JobTypeTaskAnalysis, uuid.New(), nil)
+
+	// Each timeout re-enqueues the job with one more attempt counted, until the retries are used up
+	for attempt := 1; job.CanRetry(); attempt++ {
+		if err := analyzer.handleJobError(context.Background(), &mockMessage{job: job}, job, timeoutErr, "task_analysis"); err != nil {
+			t.Fatalf("attempt %d: handleJobError() error = %v", attempt, err)
+		}
+		if len(jobQueue.enqueueCalls) != attempt {
+			t.Fatalf("attempt %d: enqueued %d jobs, want %d", attempt, len(jobQueue.enqueueCalls), attempt)
+		}
+		job = jobQueue.enqueueCalls[attempt-1]
+		if job.RetryCount != attempt {
+			t.Fatalf("attempt %d: re-enqueued retry count = %d", attempt, job.RetryCount)
+		}
+	}
+
+	var nacks []bool
+	msg := &mockMessage{job: job, nackFunc: func(requeue bool) error { nacks = append(nacks, requeue); return nil }}
+	if err := analyzer.handleJobError(context.Background(), msg, job, timeoutErr, "task_analysis"); err == nil {
+		t.Error("handleJobError() after the last retry error = nil, want dead-lettered")
+	}
+	if !slices.Equal(nacks, []bool{false}) {
+		t.Errorf("nacks = %v, want one nack without requeue", nacks)
+	}
+	if ai.IsRateLimitError(timeoutErr) {
+		t.Error("timeout reported as a rate limit")
+	}
+}
+
+func TestTaskAnalyzer_HandleJobError_RetryPolicyBackoff(t *testing.T) {
+	t.Parallel()
+
+	serverErr := &ai.ProviderError{Kind: ai.AIErrorServer, Err: errors.New("502")}
+	policy := queue.RetryPolicy{MaxRetries: 4, BaseDelay: 2 * time.Second, MaxDelay: 5 * time.Second}
+	jobQueue := &mockJobQueue{t: t}
+	analyzer := NewTaskAnalyzer(&mockAIProvider{t: t}, &mockTodoRepo{t: t}, &mockAIContextRepo{t: t}, &mockUserActivityRepo{t: t}, nil, jobQueue, zap.NewNop(),
+		WithRetryPolicies(queue.RetryPolicies{queue.JobTypeTaskAnalysis: policy}))
+	job := queue.NewJob(queue.JobTypeTaskAnalysis, uuid.New(), nil)
+
+	// Each failure backs off longer, following the job type's policy
+	for attempt := 0; job.CanRetry(); attempt++ {
+		start := time.Now()
+		if err := analyzer.handleJobError(context.Background(), &mockMessage{job: job}, job, serverErr, "task_analysis"); err != nil {
+			t.Fatalf("attempt %d: handleJobError() error = %v", attempt, err)
+		}
+		job = jobQueue.enqueueCalls[attempt]
+		want := start.Add(policy.Delay(attempt))
+		if job.NotBefore == nil || job.NotBefore.Before(want) || job.NotBefore.After(want.Add(time.Second)) {
+			t.Fatalf("attempt %d: re-enqueued not before %v, want about %v", attempt, job.NotBefore, want)
+		}
+	}
+	if len(jobQueue.enqueueCalls) != policy.MaxRetries {
+		t.Errorf("re-enqueued %d times, want %d", len(jobQueue.enqueueCalls), policy.MaxRetries)
+	}
+}
+
+func TestTaskAnalyzer_HandleJobError_RequeuesWithoutQueue(t *testing.T) {
+	t.Parallel()
+
+	analyzer := NewTaskAnalyzer(&mockAIProvider{t: t}, &mockTodoRepo{t: t}, &mockAIContextRepo{t: t}, &mockUserActivityRepo{t: t}, nil, nil, zap.NewNop())
+	job := queue.NewJob(queue.JobTypeTaskAnalysis, uuid.New(), nil)
+	var nacks []bool
+	msg := &mockMessage{job: job, nackFunc: func(requeue bool) error { nacks = append(nacks, requeue); return nil }}
+
+	if err := analyzer.handleJobError(context.Background(), msg, job, errors.New("todo not found"), "task_analysis"); err == nil {
+		t.Error("handleJobError() error = nil, want the job requeued with an error")
+	}
+	if !slices.Equal(nacks, []bool{true}) || job.RetryCount != 1 {
+		t.Errorf("nacks = %v, retry count = %d; want one requeue and retry count 1", nacks, job.RetryCount)
+	}
+}
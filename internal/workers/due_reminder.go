@@ -0,0 +1,73 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DueTodoFinder finds the todos coming due that still need a due-soon reminder and marks them reminded.
+// MarkReminderSent reports false when the todo was already marked or its due date changed.
+type DueTodoFinder interface {
+	GetDueWithin(ctx context.Context, window time.Duration) ([]*models.Todo, error)
+	MarkReminderSent(ctx context.Context, userID, id uuid.UUID, dueDate, sentAt time.Time) (bool, error)
+}
+
+// DueReminderScheduler enqueues a due-soon reminder for each todo without a reminder policy that comes due
+// within the window. Each due date is reminded once: the todo is marked before its job is enqueued, so a job
+// that fails to enqueue is not retried by later scans.
+type DueReminderScheduler struct {
+	todos    DueTodoFinder
+	jobQueue queue.JobQueue
+	window   time.Duration
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewDueReminderScheduler creates a scheduler that reminds of todos coming due within window
+func NewDueReminderScheduler(todos DueTodoFinder, jobQueue queue.JobQueue, window time.Duration, logger *zap.Logger) *DueReminderScheduler {
+	return &DueReminderScheduler{
+		todos:    todos,
+		jobQueue: jobQueue,
+		window:   window,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Schedule enqueues the due-soon reminders not yet sent and returns how many it enqueued
+func (s *DueReminderScheduler) Schedule(ctx context.Context) (int, error) {
+	todos, err := s.todos.GetDueWithin(ctx, s.window)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find todos coming due: %w", err)
+	}
+	scheduled := 0
+	for _, todo := range todos {
+		if todo.DueDate == nil {
+			continue
+		}
+		marked, err := s.todos.MarkReminderSent(ctx, todo.UserID, todo.ID, *todo.DueDate, s.now())
+		if err != nil {
+			return scheduled, err
+		}
+		if !marked {
+			continue
+		}
+		if err := s.jobQueue.Enqueue(ctx, queue.NewDueSoonReminderJob(todo.UserID, todo.ID, *todo.DueDate)); err != nil {
+			return scheduled, fmt.Errorf("failed to enqueue due reminder: %w", err)
+		}
+		scheduled++
+	}
+	if scheduled > 0 {
+		s.logger.Info("scheduled_due_reminders",
+			zap.Int("count", scheduled),
+			zap.Duration("window", s.window),
+		)
+	}
+	return scheduled, nil
+}
@@ -0,0 +1,104 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeDueTodoFinder serves todos coming due from a fixed pool and marks them like the repository does
+type fakeDueTodoFinder struct {
+	todos   []*models.Todo
+	marked  map[uuid.UUID]bool
+	findErr error
+}
+
+func (f *fakeDueTodoFinder) GetDueWithin(ctx context.Context, window time.Duration) ([]*models.Todo, error) {
+	if f.findErr != nil {
+		return nil, f.findErr
+	}
+	var due []*models.Todo
+	for _, todo := range f.todos {
+		if !f.marked[todo.ID] {
+			due = append(due, todo)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeDueTodoFinder) MarkReminderSent(ctx context.Context, userID, id uuid.UUID, dueDate, sentAt time.Time) (bool, error) {
+	if f.marked[id] {
+		return false, nil
+	}
+	f.marked[id] = true
+	return true, nil
+}
+
+func TestDueReminderScheduler_Schedule(t *testing.T) {
+	t.Parallel()
+
+	due := time.Now().Add(time.Hour)
+	userID := uuid.New()
+	fresh := &models.Todo{ID: uuid.New(), UserID: userID, DueDate: &due}
+	reminded := &models.Todo{ID: uuid.New(), UserID: userID, DueDate: &due}
+
+	tests := []struct {
+		name       string
+		finder     *fakeDueTodoFinder
+		enqueueErr error
+		want       int
+		wantErr    bool
+	}{
+		{
+			name:   "enqueues reminders not yet sent",
+			finder: &fakeDueTodoFinder{todos: []*models.Todo{fresh, reminded}, marked: map[uuid.UUID]bool{reminded.ID: true}},
+			want:   1,
+		},
+		{
+			name:    "find error",
+			finder:  &fakeDueTodoFinder{findErr: errors.New("db down")},
+			wantErr: true,
+		},
+		{
+			name:       "enqueue error",
+			finder:     &fakeDueTodoFinder{todos: []*models.Todo{fresh}, marked: map[uuid.UUID]bool{}},
+			enqueueErr: errors.New("broker down"),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			jobQueue := &mockJobQueue{t: t, enqueueFunc: func(ctx context.Context, job *queue.Job) error { return tt.enqueueErr }}
+			scheduler := NewDueReminderScheduler(tt.finder, jobQueue, 24*time.Hour, zap.NewNop())
+
+			got, err := scheduler.Schedule(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Schedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Schedule() = %d, want %d", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			for _, job := range jobQueue.enqueueCalls {
+				if job.Type != queue.JobTypeDueReminder || job.TodoID == nil || *job.TodoID != fresh.ID {
+					t.Errorf("enqueued %+v, want a due reminder for %s", job, fresh.ID)
+				}
+			}
+
+			// A second scan finds nothing left to remind
+			if again, err := scheduler.Schedule(context.Background()); err != nil || again != 0 {
+				t.Errorf("second Schedule() = %d, %v; want 0", again, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultHorizonPromotionBatchSize is how many todos one horizon promotion batch updates
+const DefaultHorizonPromotionBatchSize = 500
+
+// TimeHorizonPromoter moves open todos to the more urgent horizon their due date calls for as of now. locked
+// is false when another worker holds the promotion lock, in which case nothing was promoted.
+type TimeHorizonPromoter interface {
+	PromoteTimeHorizonsByDueDate(ctx context.Context, now time.Time, limit int) (promoted int, locked bool, err error)
+}
+
+// HorizonPromotionSweeper promotes the time horizons of todos whose due dates are approaching (later to soon,
+// soon to next) without an AI call, so prioritization stays current between reprocessing runs
+type HorizonPromotionSweeper struct {
+	promoter  TimeHorizonPromoter
+	batchSize int
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewHorizonPromotionSweeper creates a sweeper that promotes todos through promoter
+func NewHorizonPromotionSweeper(promoter TimeHorizonPromoter, logger *zap.Logger) *HorizonPromotionSweeper {
+	return &HorizonPromotionSweeper{
+		promoter:  promoter,
+		batchSize: DefaultHorizonPromotionBatchSize,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Sweep promotes eligible todos batch by batch until a batch comes back short or another worker holds the
+// promotion lock, and returns how many todos it promoted
+func (s *HorizonPromotionSweeper) Sweep(ctx context.Context) (int, error) {
+	now := s.now()
+	total := 0
+	for {
+		promoted, locked, err := s.promoter.PromoteTimeHorizonsByDueDate(ctx, now, s.batchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to promote time horizons: %w", err)
+		}
+		if !locked {
+			s.logger.Debug("horizon_promotion_skipped_lock_held")
+			break
+		}
+		total += promoted
+		if promoted < s.batchSize {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+	if total > 0 {
+		s.logger.Info("promoted_time_horizons", zap.Int("count", total))
+	}
+	return total, nil
+}
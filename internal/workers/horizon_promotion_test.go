@@ -0,0 +1,98 @@
+package workers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeHorizonPromoter promotes todos from a fixed pool the way the repository does, up to limit per call
+type fakeHorizonPromoter struct {
+	todos  []*models.Todo
+	locked bool
+	calls  int
+}
+
+func (f *fakeHorizonPromoter) PromoteTimeHorizonsByDueDate(ctx context.Context, now time.Time, limit int) (int, bool, error) {
+	f.calls++
+	if !f.locked {
+		return 0, false, nil
+	}
+	promoted := 0
+	for _, todo := range f.todos {
+		if promoted == limit {
+			break
+		}
+		if horizon, ok := todo.PromotedTimeHorizon(now); ok {
+			todo.TimeHorizon = horizon
+			promoted++
+		}
+	}
+	return promoted, true, nil
+}
+
+func TestHorizonPromotionSweeper_Sweep(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	override := true
+	newTodos := func() []*models.Todo {
+		mk := func(th models.TimeHorizon, due time.Duration) *models.Todo {
+			d := now.Add(due)
+			return &models.Todo{ID: uuid.New(), Status: models.TodoStatusProcessed, TimeHorizon: th, DueDate: &d}
+		}
+		overridden := mk(models.TimeHorizonLater, 12*time.Hour)
+		overridden.Metadata.TimeHorizonUserOverride = &override
+		return []*models.Todo{
+			mk(models.TimeHorizonLater, 12*time.Hour),   // 0: later -> next
+			mk(models.TimeHorizonLater, 5*24*time.Hour), // 1: later -> soon
+			mk(models.TimeHorizonSoon, 30*time.Hour),    // 2: soon -> next
+			mk(models.TimeHorizonLater, 9*24*time.Hour), // 3: stays later
+			overridden, // 4: user override stays later
+		}
+	}
+
+	tests := []struct {
+		name         string
+		locked       bool
+		wantPromoted int
+		wantCalls    int
+		wantHorizons []models.TimeHorizon
+	}{
+		{
+			name: "promotes in batches", locked: true, wantPromoted: 3, wantCalls: 2,
+			wantHorizons: []models.TimeHorizon{models.TimeHorizonNext, models.TimeHorizonSoon, models.TimeHorizonNext, models.TimeHorizonLater, models.TimeHorizonLater},
+		},
+		{
+			name: "lock held by another worker", wantCalls: 1,
+			wantHorizons: []models.TimeHorizon{models.TimeHorizonLater, models.TimeHorizonLater, models.TimeHorizonSoon, models.TimeHorizonLater, models.TimeHorizonLater},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			promoter := &fakeHorizonPromoter{todos: newTodos(), locked: tt.locked}
+			sweeper := NewHorizonPromotionSweeper(promoter, zap.NewNop())
+			sweeper.batchSize = 2
+			sweeper.now = func() time.Time { return now }
+
+			promoted, err := sweeper.Sweep(context.Background())
+			if err != nil {
+				t.Fatalf("Sweep() error = %v", err)
+			}
+			if promoted != tt.wantPromoted || promoter.calls != tt.wantCalls {
+				t.Errorf("Sweep() promoted %d in %d calls, want %d in %d", promoted, promoter.calls, tt.wantPromoted, tt.wantCalls)
+			}
+			for i, todo := range promoter.todos {
+				if todo.TimeHorizon != tt.wantHorizons[i] {
+					t.Errorf("todo %d horizon = %s, want %s", i, todo.TimeHorizon, tt.wantHorizons[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benvon/smart-todo/internal/metrics"
+	"github.com/benvon/smart-todo/internal/queue"
+	"go.uber.org/zap"
+)
+
+// QueueStatsReporter periodically logs the main queue and DLQ counts together with the jobs this worker has
+// processed and failed by type, and feeds the queue gauges served on /metrics. A DLQ that grew since the last
+// report is logged as a warning so it can be alerted on.
+type QueueStatsReporter struct {
+	inspector queue.Inspector
+	logger    *zap.Logger
+	// lastDLQ is the DLQ depth at the previous report; -1 before the first one
+	lastDLQ int
+}
+
+// NewQueueStatsReporter creates a reporter reading counts from inspector
+func NewQueueStatsReporter(inspector queue.Inspector, logger *zap.Logger) *QueueStatsReporter {
+	return &QueueStatsReporter{inspector: inspector, logger: logger, lastDLQ: -1}
+}
+
+// Report reads the queue counts once, updates the gauges and logs them. It is not safe for concurrent use.
+func (r *QueueStatsReporter) Report(ctx context.Context) (queue.Stats, error) {
+	stats, err := r.inspector.Stats(ctx)
+	if err != nil {
+		return queue.Stats{}, fmt.Errorf("failed to read queue stats: %w", err)
+	}
+	metrics.QueueDepth.Set(float64(stats.Queue.Depth))
+	metrics.QueueConsumers.Set(float64(stats.Queue.Consumers))
+
+	r.logger.Info("queue_stats",
+		zap.Int("queue_depth", stats.Queue.Depth),
+		zap.Int("consumers", stats.Queue.Consumers),
+		zap.Int("dlq_depth", stats.DLQ.Depth),
+		zap.Any("processed_by_type", metrics.JobsProcessed.Values()),
+		zap.Any("failed_by_type", metrics.JobsFailed.Values()),
+	)
+	if r.lastDLQ >= 0 && stats.DLQ.Depth > r.lastDLQ {
+		r.logger.Warn("dlq_growing",
+			zap.Int("previous_dlq_depth", r.lastDLQ),
+			zap.Int("dlq_depth", stats.DLQ.Depth),
+		)
+	}
+	r.lastDLQ = stats.DLQ.Depth
+	return stats, nil
+}
@@ -0,0 +1,64 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/queue"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type fakeQueueInspector struct {
+	stats []queue.Stats
+	err   error
+}
+
+func (f *fakeQueueInspector) Stats(ctx context.Context) (queue.Stats, error) {
+	if f.err != nil {
+		return queue.Stats{}, f.err
+	}
+	next := f.stats[0]
+	f.stats = f.stats[1:]
+	return next, nil
+}
+
+func TestQueueStatsReporter_Report(t *testing.T) {
+	t.Parallel()
+
+	inspector := &fakeQueueInspector{stats: []queue.Stats{
+		{Queue: queue.QueueStats{Depth: 40, Consumers: 2}, DLQ: queue.QueueStats{Depth: 3}},
+		{Queue: queue.QueueStats{Depth: 10, Consumers: 2}, DLQ: queue.QueueStats{Depth: 3}},
+		{Queue: queue.QueueStats{Depth: 12, Consumers: 2}, DLQ: queue.QueueStats{Depth: 5}},
+		{Queue: queue.QueueStats{Depth: 12, Consumers: 2}, DLQ: queue.QueueStats{Depth: 0}},
+	}}
+	core, logs := observer.New(zapcore.InfoLevel)
+	reporter := NewQueueStatsReporter(inspector, zap.New(core))
+
+	// Only the third report, where the DLQ went from 3 to 5, warns; the first has nothing to compare to
+	wantWarn := []bool{false, false, true, false}
+	for i, want := range wantWarn {
+		before := logs.FilterMessage("dlq_growing").Len()
+		stats, err := reporter.Report(context.Background())
+		if err != nil {
+			t.Fatalf("Report() %d error = %v", i, err)
+		}
+		if got := logs.FilterMessage("dlq_growing").Len() > before; got != want {
+			t.Errorf("report %d (DLQ %d) warned = %v, want %v", i, stats.DLQ.Depth, got, want)
+		}
+	}
+	if got := logs.FilterMessage("queue_stats").Len(); got != len(wantWarn) {
+		t.Errorf("logged %d queue_stats entries, want %d", got, len(wantWarn))
+	}
+}
+
+func TestQueueStatsReporter_ReportError(t *testing.T) {
+	t.Parallel()
+
+	reporter := NewQueueStatsReporter(&fakeQueueInspector{err: errors.New("channel closed")}, zap.NewNop())
+	if _, err := reporter.Report(context.Background()); err == nil {
+		t.Error("Report() error = nil, want the inspector error")
+	}
+}
@@ -0,0 +1,114 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// ProcessedJobPrefix namespaces the Redis keys marking handled jobs
+	ProcessedJobPrefix = "job_processed"
+	// DefaultProcessedJobTTL is how long a handled job is remembered. Redeliveries after a consumer
+	// reconnect arrive within seconds, so this only needs to cover a broker or worker restart.
+	DefaultProcessedJobTTL = time.Hour
+)
+
+// ProcessedJobMarker remembers which jobs have been handled so a redelivered copy can be recognised
+type ProcessedJobMarker interface {
+	MarkProcessed(ctx context.Context, job *queue.Job) error
+	Processed(ctx context.Context, job *queue.Job) (bool, error)
+}
+
+// RedisProcessedJobMarker stores processed markers in Redis, shared by all workers. Markers are keyed by
+// job ID and retry count, since a retried job is re-published with the same ID.
+type RedisProcessedJobMarker struct {
+	client redis.Cmdable
+	ttl    time.Duration
+}
+
+// NewRedisProcessedJobMarker creates a Redis-backed marker whose keys expire after ttl
+func NewRedisProcessedJobMarker(client redis.Cmdable, ttl time.Duration) *RedisProcessedJobMarker {
+	return &RedisProcessedJobMarker{client: client, ttl: ttl}
+}
+
+// MarkProcessed records that the job has been handled
+func (m *RedisProcessedJobMarker) MarkProcessed(ctx context.Context, job *queue.Job) error {
+	if err := m.client.Set(ctx, processedJobKey(job), 1, m.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set processed job key: %w", err)
+	}
+	return nil
+}
+
+// Processed reports whether the job has already been handled
+func (m *RedisProcessedJobMarker) Processed(ctx context.Context, job *queue.Job) (bool, error) {
+	n, err := m.client.Exists(ctx, processedJobKey(job)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to read processed job key: %w", err)
+	}
+	return n > 0, nil
+}
+
+func processedJobKey(job *queue.Job) string {
+	return fmt.Sprintf("%s:%s:%d", ProcessedJobPrefix, job.ID, job.RetryCount)
+}
+
+// RedeliveryGuard drops redelivered copies of jobs that were already handled. When a consumer's channel
+// closes after a job was processed but before its ack reached the broker, the broker redelivers the job on
+// the new channel; without the guard it would be processed twice.
+type RedeliveryGuard struct {
+	marker ProcessedJobMarker
+	logger *zap.Logger
+}
+
+// NewRedeliveryGuard creates a redelivery guard backed by marker
+func NewRedeliveryGuard(marker ProcessedJobMarker, logger *zap.Logger) *RedeliveryGuard {
+	return &RedeliveryGuard{marker: marker, logger: logger}
+}
+
+// Skip acks msg and returns true when it is a redelivery of a job that was already handled. Only messages
+// the broker flags as redelivered are checked; if the marker cannot be read the job is processed again.
+func (g *RedeliveryGuard) Skip(ctx context.Context, msg queue.MessageInterface) bool {
+	redelivery, ok := msg.(queue.RedeliveryReporter)
+	if !ok || !redelivery.IsRedelivered() {
+		return false
+	}
+	job := msg.GetJob()
+	processed, err := g.marker.Processed(ctx, job)
+	if err != nil {
+		g.logger.Warn("processed_job_check_failed",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return false
+	}
+	if !processed {
+		return false
+	}
+	g.logger.Info("duplicate_job_delivery_skipped",
+		zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+		zap.String("job_type", string(job.Type)),
+	)
+	if err := msg.Ack(); err != nil {
+		g.logger.Warn("failed_to_ack_duplicate_job",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+	}
+	return true
+}
+
+// Done marks the job as handled; call it once the job's message has been settled successfully
+func (g *RedeliveryGuard) Done(ctx context.Context, job *queue.Job) {
+	if err := g.marker.MarkProcessed(ctx, job); err != nil {
+		g.logger.Warn("failed_to_mark_job_processed",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+	}
+}
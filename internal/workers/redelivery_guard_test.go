@@ -0,0 +1,138 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryProcessedJobMarker mirrors RedisProcessedJobMarker without expiry
+type memoryProcessedJobMarker struct {
+	mu        sync.Mutex
+	processed map[string]bool
+	err       error
+}
+
+func newMemoryProcessedJobMarker() *memoryProcessedJobMarker {
+	return &memoryProcessedJobMarker{processed: make(map[string]bool)}
+}
+
+func (m *memoryProcessedJobMarker) MarkProcessed(ctx context.Context, job *queue.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.processed[processedJobKey(job)] = true
+	return m.err
+}
+
+func (m *memoryProcessedJobMarker) Processed(ctx context.Context, job *queue.Job) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.processed[processedJobKey(job)], m.err
+}
+
+// fakeDeliveryChannel stands in for an AMQP channel; a closed channel rejects acks for its delivery tags
+type fakeDeliveryChannel struct {
+	closed bool
+	acked  []uint64
+}
+
+func (c *fakeDeliveryChannel) Ack(tag uint64, multiple bool) error {
+	if c.closed {
+		return fmt.Errorf("delivery tag %d unknown on closed channel", tag)
+	}
+	c.acked = append(c.acked, tag)
+	return nil
+}
+
+func (c *fakeDeliveryChannel) Nack(tag uint64, multiple, requeue bool) error {
+	return c.Ack(tag, multiple)
+}
+
+func (c *fakeDeliveryChannel) IsClosed() bool { return c.closed }
+
+func TestRedeliveryGuard_ReconnectRedelivery(t *testing.T) {
+	t.Parallel()
+
+	guard := NewRedeliveryGuard(newMemoryProcessedJobMarker(), zap.NewNop())
+	job := queue.NewJob(queue.JobTypeTagAnalysis, uuid.New(), nil)
+	processed := 0
+	handle := func(msg *queue.Message) {
+		if guard.Skip(context.Background(), msg) {
+			return
+		}
+		processed++
+		if err := msg.Ack(); err != nil {
+			t.Fatalf("Ack() error = %v", err)
+		}
+		guard.Done(context.Background(), msg.GetJob())
+	}
+
+	// The job is processed, but its channel closes before the ack is sent
+	stale := &fakeDeliveryChannel{}
+	first := &queue.Message{Job: job, DeliveryTag: 4, Channel: stale}
+	stale.closed = true
+	handle(first)
+
+	// The broker redelivers the unacknowledged job on the new channel
+	fresh := &fakeDeliveryChannel{}
+	handle(&queue.Message{Job: job, DeliveryTag: 1, Channel: fresh, Redelivered: true})
+
+	if processed != 1 {
+		t.Errorf("processed the job %d times, want 1", processed)
+	}
+	if len(fresh.acked) != 1 || fresh.acked[0] != 1 {
+		t.Errorf("acked %v on the new channel, want the redelivered tag 1", fresh.acked)
+	}
+}
+
+func TestRedeliveryGuard_Skip(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	tests := []struct {
+		name        string
+		redelivered bool
+		processed   bool
+		markerErr   error
+		retried     bool
+		wantSkip    bool
+	}{
+		{name: "first delivery", processed: true},
+		{name: "redelivery of a processed job", redelivered: true, processed: true, wantSkip: true},
+		{name: "redelivery of an unprocessed job", redelivered: true},
+		{name: "redelivery of a retry", redelivered: true, processed: true, retried: true},
+		{name: "marker unavailable", redelivered: true, processed: true, markerErr: errors.New("redis down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			marker := newMemoryProcessedJobMarker()
+			job := queue.NewJob(queue.JobTypeDueReminder, userID, nil)
+			if tt.processed {
+				_ = marker.MarkProcessed(context.Background(), job)
+			}
+			marker.err = tt.markerErr
+			if tt.retried {
+				retry := *job
+				retry.RetryCount++
+				job = &retry
+			}
+			ch := &fakeDeliveryChannel{}
+			msg := &queue.Message{Job: job, DeliveryTag: 9, Channel: ch, Redelivered: tt.redelivered}
+
+			if got := NewRedeliveryGuard(marker, zap.NewNop()).Skip(context.Background(), msg); got != tt.wantSkip {
+				t.Errorf("Skip() = %v, want %v", got, tt.wantSkip)
+			}
+			if acked := len(ch.acked) == 1; acked != tt.wantSkip {
+				t.Errorf("acked = %v, want only skipped messages acked", acked)
+			}
+		})
+	}
+}
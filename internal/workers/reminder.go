@@ -0,0 +1,196 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/database"
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/notify"
+	"github.com/benvon/smart-todo/internal/queue"
+	"go.uber.org/zap"
+)
+
+// ReminderWorker processes due-date reminder jobs. Each reminder notifies the user and schedules the next
+// step of the todo's escalation chain, so the chain continues until the policy ends or the todo changes.
+type ReminderWorker struct {
+	todoRepo database.TodoRepositoryInterface
+	jobQueue queue.JobQueue
+	notifier notify.Notifier
+	logger   *zap.Logger
+	registry map[queue.JobType]processorEntry
+}
+
+// NewReminderWorker creates a new reminder worker and registers the due_reminder processor.
+func NewReminderWorker(
+	todoRepo database.TodoRepositoryInterface,
+	jobQueue queue.JobQueue,
+	notifier notify.Notifier,
+	logger *zap.Logger,
+) *ReminderWorker {
+	w := &ReminderWorker{
+		todoRepo: todoRepo,
+		jobQueue: jobQueue,
+		notifier: notifier,
+		logger:   logger,
+		registry: make(map[queue.JobType]processorEntry),
+	}
+	w.RegisterProcessor(queue.JobTypeDueReminder, w.ProcessDueReminderJob, false)
+	return w
+}
+
+// RegisterProcessor registers a processor for a job type.
+func (w *ReminderWorker) RegisterProcessor(typ queue.JobType, proc JobProcessor, useHandleJobError bool) {
+	w.registry[typ] = processorEntry{proc: proc, useHandleJobError: useHandleJobError}
+}
+
+// ProcessDueReminderJob sends the reminder for one escalation step and schedules the next one.
+// Jobs whose chain key no longer matches the todo (completed, rescheduled, or policy changed) are dropped.
+// Due-soon reminders from DueReminderScheduler are single reminders without a chain.
+func (w *ReminderWorker) ProcessDueReminderJob(ctx context.Context, job *queue.Job) error {
+	if job.TodoID == nil {
+		return fmt.Errorf("todo_id is required for due reminder job")
+	}
+	todo, err := w.todoRepo.GetByUserIDAndID(ctx, job.UserID, *job.TodoID)
+	if err != nil {
+		return fmt.Errorf("failed to get todo: %w", err)
+	}
+	if scheduledDue, ok := job.Metadata[queue.MetadataReminderDueDate].(string); ok {
+		return w.sendDueSoonReminder(ctx, todo, scheduledDue)
+	}
+	jobKey, _ := job.Metadata[queue.MetadataReminderKey].(string)
+	if jobKey == "" || jobKey != todo.ReminderKey() {
+		w.logger.Debug("reminder_chain_cancelled",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+		)
+		return nil
+	}
+	step, ok := reminderStepFromJob(job, todo)
+	if !ok {
+		return fmt.Errorf("invalid reminder step for job %s", job.ID)
+	}
+	if err := w.notifier.Notify(ctx, notify.Notification{
+		UserID:  todo.UserID,
+		TodoID:  todo.ID,
+		Kind:    string(step.Kind),
+		Text:    todo.Text,
+		DueDate: todo.DueDate,
+		SentAt:  time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to send reminder: %w", err)
+	}
+	return w.scheduleNextStep(ctx, todo, jobKey, step)
+}
+
+// sendDueSoonReminder notifies the user that the todo is coming due, unless it was completed, retired or
+// rescheduled since the reminder was scheduled
+func (w *ReminderWorker) sendDueSoonReminder(ctx context.Context, todo *models.Todo, scheduledDue string) error {
+	due, err := time.Parse(time.RFC3339, scheduledDue)
+	if err != nil {
+		return fmt.Errorf("invalid reminder due date %q: %w", scheduledDue, err)
+	}
+	if todo.Status == models.TodoStatusCompleted || todo.ArchivedAt != nil || todo.TrashedAt != nil ||
+		todo.DueDate == nil || !todo.DueDate.Truncate(time.Second).Equal(due) {
+		w.logger.Debug("due_soon_reminder_cancelled",
+			zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+		)
+		return nil
+	}
+	if err := w.notifier.Notify(ctx, notify.Notification{
+		UserID:  todo.UserID,
+		TodoID:  todo.ID,
+		Kind:    string(models.ReminderKindDueSoon),
+		Text:    todo.Text,
+		DueDate: todo.DueDate,
+		SentAt:  time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to send reminder: %w", err)
+	}
+	return nil
+}
+
+// reminderStepFromJob resolves the job's step index against the todo's current policy.
+func reminderStepFromJob(job *queue.Job, todo *models.Todo) (models.ReminderStep, bool) {
+	var index int
+	switch v := job.Metadata[queue.MetadataReminderStep].(type) {
+	case int:
+		index = v
+	case float64:
+		index = int(v)
+	default:
+		return models.ReminderStep{}, false
+	}
+	steps := todo.Metadata.ReminderPolicy.Steps(*todo.DueDate)
+	if index < 0 || index >= len(steps) {
+		return models.ReminderStep{}, false
+	}
+	return steps[index], true
+}
+
+func (w *ReminderWorker) scheduleNextStep(ctx context.Context, todo *models.Todo, key string, current models.ReminderStep) error {
+	steps := todo.Metadata.ReminderPolicy.Steps(*todo.DueDate)
+	if current.Index+1 >= len(steps) {
+		w.logger.Debug("reminder_chain_finished",
+			zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+		)
+		return nil
+	}
+	next := steps[current.Index+1]
+	job := queue.NewDueReminderJob(todo.UserID, todo.ID, key, next.Index, next.At)
+	if err := w.jobQueue.Enqueue(ctx, job); err != nil {
+		return fmt.Errorf("failed to enqueue next reminder: %w", err)
+	}
+	w.logger.Debug("scheduled_next_reminder",
+		zap.String("todo_id", logpkg.SanitizeUserID(todo.ID.String())),
+		zap.String("kind", string(next.Kind)),
+		zap.Time("not_before", next.At),
+	)
+	return nil
+}
+
+// ProcessJob processes a job based on its type using the processor registry.
+func (w *ReminderWorker) ProcessJob(ctx context.Context, msg queue.MessageInterface) error {
+	job := msg.GetJob()
+	if job.IsExpired() {
+		w.logger.Debug("reminder_job_expired",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+		)
+		return w.ack(msg, job)
+	}
+	ent, ok := w.registry[job.Type]
+	if !ok {
+		if nackErr := msg.Nack(false); nackErr != nil {
+			w.logger.Warn("failed_to_nack_unknown_job_type",
+				zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+				zap.String("error", logpkg.SanitizeError(nackErr)),
+			)
+		}
+		return fmt.Errorf("unknown job type: %s", job.Type)
+	}
+	if err := ent.proc(ctx, job); err != nil {
+		w.logger.Error("due_reminder_job_failed",
+			zap.String("operation", "process_job"),
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		if nackErr := msg.Nack(false); nackErr != nil {
+			w.logger.Warn("failed_to_nack_due_reminder_job",
+				zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+				zap.String("error", logpkg.SanitizeError(nackErr)),
+			)
+		}
+		return fmt.Errorf("due reminder failed: %w", err)
+	}
+	return w.ack(msg, job)
+}
+
+func (w *ReminderWorker) ack(msg queue.MessageInterface, job *queue.Job) error {
+	if err := msg.Ack(); err != nil {
+		return fmt.Errorf("failed to ack due reminder job %s: %w", job.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package workers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/notify"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type recordingNotifier struct {
+	sent []notify.Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func newReminderTodo(userID uuid.UUID, due time.Time) *models.Todo {
+	return &models.Todo{
+		ID:      uuid.New(),
+		UserID:  userID,
+		Text:    "Pay rent",
+		Status:  models.TodoStatusPending,
+		DueDate: &due,
+		Metadata: models.Metadata{
+			ReminderPolicy: &models.ReminderPolicy{LeadTimeMinutes: 60, AtDue: true, OverdueIntervalMinutes: 60, MaxOverdueReminders: 2},
+		},
+	}
+}
+
+func TestReminderWorker_ProcessDueReminderJob_ChainsNextStep(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	due := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	todo := newReminderTodo(userID, due)
+	repo := &mockTodoRepo{
+		t: t,
+		getByUserIDAndIDFunc: func(ctx context.Context, uid uuid.UUID, id uuid.UUID) (*models.Todo, error) {
+			return todo, nil
+		},
+	}
+	jobQueue := &mockJobQueue{t: t}
+	notifier := &recordingNotifier{}
+	w := NewReminderWorker(repo, jobQueue, notifier, zap.NewNop())
+
+	steps := todo.Metadata.ReminderPolicy.Steps(due)
+	job := queue.NewDueReminderJob(userID, todo.ID, todo.ReminderKey(), 0, steps[0].At)
+	for i := range steps {
+		if err := w.ProcessDueReminderJob(context.Background(), job); err != nil {
+			t.Fatalf("step %d: ProcessDueReminderJob() error = %v", i, err)
+		}
+		if len(notifier.sent) != i+1 {
+			t.Fatalf("step %d: sent %d notifications, want %d", i, len(notifier.sent), i+1)
+		}
+		if notifier.sent[i].Kind != string(steps[i].Kind) {
+			t.Errorf("step %d: kind = %s, want %s", i, notifier.sent[i].Kind, steps[i].Kind)
+		}
+		if i == len(steps)-1 {
+			break
+		}
+		job = jobQueue.enqueueCalls[len(jobQueue.enqueueCalls)-1]
+		if job.Type != queue.JobTypeDueReminder || job.NotBefore == nil || !job.NotBefore.Equal(steps[i+1].At) {
+			t.Fatalf("step %d: next job = %+v, want due_reminder at %v", i, job, steps[i+1].At)
+		}
+	}
+	if len(jobQueue.enqueueCalls) != len(steps)-1 {
+		t.Errorf("enqueued %d follow-up jobs, want %d", len(jobQueue.enqueueCalls), len(steps)-1)
+	}
+}
+
+func TestReminderWorker_ProcessDueReminderJob_CancelledChains(t *testing.T) {
+	t.Parallel()
+
+	due := time.Now().Add(time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name   string
+		mutate func(todo *models.Todo)
+	}{
+		{"completed", func(todo *models.Todo) {
+			now := time.Now()
+			todo.Status = models.TodoStatusCompleted
+			todo.CompletedAt = &now
+		}},
+		{"rescheduled", func(todo *models.Todo) {
+			later := due.Add(24 * time.Hour)
+			todo.DueDate = &later
+		}},
+		{"due date cleared", func(todo *models.Todo) { todo.DueDate = nil }},
+		{"policy removed", func(todo *models.Todo) { todo.Metadata.ReminderPolicy = nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todo := newReminderTodo(userID, due)
+			job := queue.NewDueReminderJob(userID, todo.ID, todo.ReminderKey(), 1, due)
+			tt.mutate(todo)
+
+			repo := &mockTodoRepo{
+				t: t,
+				getByUserIDAndIDFunc: func(ctx context.Context, uid uuid.UUID, id uuid.UUID) (*models.Todo, error) {
+					return todo, nil
+				},
+			}
+			jobQueue := &mockJobQueue{t: t}
+			notifier := &recordingNotifier{}
+			w := NewReminderWorker(repo, jobQueue, notifier, zap.NewNop())
+
+			acked := false
+			msg := &mockMessage{job: job, ackFunc: func() error { acked = true; return nil }}
+			if err := w.ProcessJob(context.Background(), msg); err != nil {
+				t.Fatalf("ProcessJob() error = %v", err)
+			}
+			if !acked {
+				t.Error("expected cancelled reminder job to be acked")
+			}
+			if len(notifier.sent) != 0 {
+				t.Errorf("sent %d notifications, want 0", len(notifier.sent))
+			}
+			if len(jobQueue.enqueueCalls) != 0 {
+				t.Errorf("enqueued %d jobs, want 0", len(jobQueue.enqueueCalls))
+			}
+		})
+	}
+}
+
+func TestReminderWorker_ProcessDueReminderJob_DueSoon(t *testing.T) {
+	t.Parallel()
+
+	due := time.Now().Add(time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name     string
+		mutate   func(todo *models.Todo)
+		wantSent bool
+	}{
+		{name: "still due", mutate: func(todo *models.Todo) {}, wantSent: true},
+		{name: "completed", mutate: func(todo *models.Todo) { todo.Status = models.TodoStatusCompleted }},
+		{name: "rescheduled", mutate: func(todo *models.Todo) {
+			later := due.Add(24 * time.Hour)
+			todo.DueDate = &later
+		}},
+		{name: "trashed", mutate: func(todo *models.Todo) {
+			now := time.Now()
+			todo.TrashedAt = &now
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			todo := &models.Todo{ID: uuid.New(), UserID: userID, Text: "Renew passport", Status: models.TodoStatusPending, DueDate: &due}
+			job := queue.NewDueSoonReminderJob(userID, todo.ID, due)
+			tt.mutate(todo)
+
+			repo := &mockTodoRepo{
+				t: t,
+				getByUserIDAndIDFunc: func(ctx context.Context, uid uuid.UUID, id uuid.UUID) (*models.Todo, error) {
+					return todo, nil
+				},
+			}
+			notifier := &recordingNotifier{}
+			w := NewReminderWorker(repo, &mockJobQueue{t: t}, notifier, zap.NewNop())
+
+			if err := w.ProcessDueReminderJob(context.Background(), job); err != nil {
+				t.Fatalf("ProcessDueReminderJob() error = %v", err)
+			}
+			if sent := len(notifier.sent) == 1; sent != tt.wantSent {
+				t.Fatalf("sent %d notifications, want sent %v", len(notifier.sent), tt.wantSent)
+			}
+			if tt.wantSent && notifier.sent[0].Kind != string(models.ReminderKindDueSoon) {
+				t.Errorf("kind = %s, want %s", notifier.sent[0].Kind, models.ReminderKindDueSoon)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"go.uber.org/zap"
+)
+
+// DefaultRetentionBatchSize is how many completed todos one retention batch retires
+const DefaultRetentionBatchSize = 500
+
+// CompletedTodoRetirer archives or trashes completed todos past a cutoff. locked is false when another
+// worker holds the sweep lock, in which case nothing was retired.
+type CompletedTodoRetirer interface {
+	RetireCompletedBefore(ctx context.Context, action models.RetentionAction, cutoff time.Time, limit int) (retired int, locked bool, err error)
+}
+
+// RetentionSweeper retires completed todos older than the retention age, keeping active lists lean while the
+// todos stay available for stats and export. Users opt out with the auto_archive preference.
+type RetentionSweeper struct {
+	retirer   CompletedTodoRetirer
+	age       time.Duration
+	action    models.RetentionAction
+	batchSize int
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewRetentionSweeper creates a sweeper that applies action to todos completed more than age ago
+func NewRetentionSweeper(retirer CompletedTodoRetirer, age time.Duration, action models.RetentionAction, logger *zap.Logger) *RetentionSweeper {
+	return &RetentionSweeper{
+		retirer:   retirer,
+		age:       age,
+		action:    action,
+		batchSize: DefaultRetentionBatchSize,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Sweep retires eligible todos batch by batch until a batch comes back short or another worker holds the
+// sweep lock, and returns how many todos it retired
+func (s *RetentionSweeper) Sweep(ctx context.Context) (int, error) {
+	cutoff := s.now().Add(-s.age)
+	total := 0
+	for {
+		retired, locked, err := s.retirer.RetireCompletedBefore(ctx, s.action, cutoff, s.batchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to retire completed todos: %w", err)
+		}
+		if !locked {
+			s.logger.Debug("retention_sweep_skipped_lock_held", zap.String("action", string(s.action)))
+			break
+		}
+		total += retired
+		if retired < s.batchSize {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+	if total > 0 {
+		s.logger.Info("retired_completed_todos",
+			zap.String("action", string(s.action)),
+			zap.Int("count", total),
+			zap.Time("completed_before", cutoff),
+		)
+	}
+	return total, nil
+}
@@ -0,0 +1,87 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"go.uber.org/zap"
+)
+
+// fakeRetirer hands out completed todos in batches from a fixed pool and records each call
+type fakeRetirer struct {
+	remaining int
+	locked    bool
+	err       error
+	calls     []retireCall
+}
+
+type retireCall struct {
+	action models.RetentionAction
+	cutoff time.Time
+	limit  int
+}
+
+func (f *fakeRetirer) RetireCompletedBefore(ctx context.Context, action models.RetentionAction, cutoff time.Time, limit int) (int, bool, error) {
+	f.calls = append(f.calls, retireCall{action: action, cutoff: cutoff, limit: limit})
+	if f.err != nil {
+		return 0, false, f.err
+	}
+	if !f.locked {
+		return 0, false, nil
+	}
+	n := min(limit, f.remaining)
+	f.remaining -= n
+	return n, true, nil
+}
+
+func TestRetentionSweeper_Sweep(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	age := 30 * 24 * time.Hour
+
+	tests := []struct {
+		name        string
+		action      models.RetentionAction
+		retirer     *fakeRetirer
+		wantRetired int
+		wantCalls   int
+		wantErr     bool
+	}{
+		{name: "nothing to retire", action: models.RetentionActionArchive, retirer: &fakeRetirer{locked: true}, wantCalls: 1},
+		{name: "one short batch", action: models.RetentionActionArchive, retirer: &fakeRetirer{locked: true, remaining: 1}, wantRetired: 1, wantCalls: 1},
+		{name: "full batches continue", action: models.RetentionActionTrash, retirer: &fakeRetirer{locked: true, remaining: 5}, wantRetired: 5, wantCalls: 3},
+		{name: "exact batches end on an empty batch", action: models.RetentionActionArchive, retirer: &fakeRetirer{locked: true, remaining: 4}, wantRetired: 4, wantCalls: 3},
+		{name: "lock held by another worker", action: models.RetentionActionArchive, retirer: &fakeRetirer{remaining: 5}, wantCalls: 1},
+		{name: "repository error", action: models.RetentionActionArchive, retirer: &fakeRetirer{err: errors.New("db down")}, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sweeper := NewRetentionSweeper(tt.retirer, age, tt.action, zap.NewNop())
+			sweeper.batchSize = 2
+			sweeper.now = func() time.Time { return now }
+
+			retired, err := sweeper.Sweep(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Sweep() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if retired != tt.wantRetired {
+				t.Errorf("Sweep() retired %d, want %d", retired, tt.wantRetired)
+			}
+			if len(tt.retirer.calls) != tt.wantCalls {
+				t.Fatalf("RetireCompletedBefore called %d times, want %d", len(tt.retirer.calls), tt.wantCalls)
+			}
+			for _, call := range tt.retirer.calls {
+				if call.action != tt.action || !call.cutoff.Equal(now.Add(-age)) || call.limit != 2 {
+					t.Errorf("RetireCompletedBefore(%s, %v, %d), want (%s, %v, 2)", call.action, call.cutoff, call.limit, tt.action, now.Add(-age))
+				}
+			}
+		})
+	}
+}
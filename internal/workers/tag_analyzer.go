@@ -2,7 +2,13 @@ package workers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/benvon/smart-todo/internal/database"
@@ -13,12 +19,39 @@ import (
 	"go.uber.org/zap"
 )
 
+// tagAggregationVersion salts tagInputsHash; bump it whenever aggregation changes so statistics stored by
+// the previous aggregation no longer match and are rebuilt
+const tagAggregationVersion = 2
+
 // TagAnalyzer processes tag analysis jobs to aggregate tag statistics
 type TagAnalyzer struct {
 	todoRepo     database.TodoRepositoryInterface
 	tagStatsRepo database.TagStatisticsRepositoryInterface
 	logger       *zap.Logger
 	registry     map[queue.JobType]processorEntry
+	coalescer    TagAnalysisCoalescer                  // Optional: limits runs per user per window
+	jobQueue     queue.JobQueue                        // Required with coalescer: schedules trailing runs
+	contextRepo  database.AIContextRepositoryInterface // Optional: tag aliases folded into canonical tags
+}
+
+// TagAnalyzerOption configures a TagAnalyzer.
+type TagAnalyzerOption func(*TagAnalyzer)
+
+// WithTagAnalysisCoalescing enables per-user coalescing of tag analysis runs. Jobs arriving within the
+// coalescing window are acked early, and a single trailing run is scheduled on jobQueue.
+func WithTagAnalysisCoalescing(coalescer TagAnalysisCoalescer, jobQueue queue.JobQueue) TagAnalyzerOption {
+	return func(a *TagAnalyzer) {
+		a.coalescer = coalescer
+		a.jobQueue = jobQueue
+	}
+}
+
+// WithTagAnalyzerAliases folds each user's tag aliases into their canonical tags before aggregating,
+// so statistics and co-occurrence only ever count canonical tags.
+func WithTagAnalyzerAliases(contextRepo database.AIContextRepositoryInterface) TagAnalyzerOption {
+	return func(a *TagAnalyzer) {
+		a.contextRepo = contextRepo
+	}
 }
 
 // NewTagAnalyzer creates a new tag analyzer and registers the tag_analysis processor.
@@ -26,6 +59,7 @@ func NewTagAnalyzer(
 	todoRepo database.TodoRepositoryInterface,
 	tagStatsRepo database.TagStatisticsRepositoryInterface,
 	logger *zap.Logger,
+	opts ...TagAnalyzerOption,
 ) *TagAnalyzer {
 	a := &TagAnalyzer{
 		todoRepo:     todoRepo,
@@ -33,6 +67,9 @@ func NewTagAnalyzer(
 		logger:       logger,
 		registry:     make(map[queue.JobType]processorEntry),
 	}
+	for _, o := range opts {
+		o(a)
+	}
 	a.RegisterProcessor(queue.JobTypeTagAnalysis, a.ProcessTagAnalysisJob, false)
 	return a
 }
@@ -42,11 +79,23 @@ func (a *TagAnalyzer) RegisterProcessor(typ queue.JobType, proc JobProcessor, us
 	a.registry[typ] = processorEntry{proc: proc, useHandleJobError: useHandleJobError}
 }
 
-// ProcessTagAnalysisJob processes a tag analysis job
+// ProcessTagAnalysisJob processes a tag analysis job. When the statistics are not tainted and were aggregated
+// from tag inputs with the same content hash, the job is acked without rewriting them. Jobs marked with
+// queue.MetadataForceTagAnalysis are never coalesced and taint the statistics first, so they are always rebuilt.
 func (a *TagAnalyzer) ProcessTagAnalysisJob(ctx context.Context, job *queue.Job) error {
 	if job.UserID == (queue.Job{}.UserID) {
 		return fmt.Errorf("user_id is required for tag analysis job")
 	}
+	force := jobMetadataBool(job, queue.MetadataForceTagAnalysis)
+	if !force && a.coalesced(ctx, job) {
+		return nil
+	}
+	if force {
+		// Tainting lets the update through UpdateStatistics' unchanged-inputs check as well
+		if _, err := a.tagStatsRepo.MarkTainted(ctx, job.UserID); err != nil {
+			return fmt.Errorf("failed to mark tag statistics for recompute: %w", err)
+		}
+	}
 	a.logger.Info("processing_tag_analysis_job",
 		zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
 		zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
@@ -64,6 +113,15 @@ func (a *TagAnalyzer) ProcessTagAnalysisJob(ctx context.Context, job *queue.Job)
 	if err != nil {
 		return err
 	}
+	allTodos = canonicalizeTodoTags(allTodos, a.loadTagAliases(ctx, job.UserID))
+	contentHash := tagInputsHash(allTodos)
+	if !force && !stats.Tainted && stats.ContentHash == contentHash {
+		a.logger.Debug("tag_statistics_unchanged",
+			zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+			zap.Int("total_todos", len(allTodos)),
+		)
+		return nil
+	}
 	tagStatsMap, todosWithTags, completedWithTags := aggregateTagStatsFromTodos(allTodos)
 	a.logger.Info("aggregated_tag_statistics",
 		zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
@@ -72,6 +130,8 @@ func (a *TagAnalyzer) ProcessTagAnalysisJob(ctx context.Context, job *queue.Job)
 		zap.Int("unique_tags", len(tagStatsMap)),
 	)
 	stats.TagStats = tagStatsMap
+	stats.CoOccurrence = aggregateTagCoOccurrence(allTodos, models.MaxTagPairs)
+	stats.ContentHash = contentHash
 	now := time.Now()
 	stats.LastAnalyzedAt = &now
 	updated, err := a.tagStatsRepo.UpdateStatistics(ctx, stats)
@@ -79,6 +139,7 @@ func (a *TagAnalyzer) ProcessTagAnalysisJob(ctx context.Context, job *queue.Job)
 		return fmt.Errorf("failed to update tag statistics: %w", err)
 	}
 	if !updated {
+		// Another update won the version check, or a concurrent worker already stored these inputs
 		a.logger.Debug("tag_statistics_version_conflict",
 			zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
 		)
@@ -92,6 +153,48 @@ func (a *TagAnalyzer) ProcessTagAnalysisJob(ctx context.Context, job *queue.Job)
 	return nil
 }
 
+// coalesced reports whether the job was absorbed by coalescing and should be acked without running.
+// Coalescing errors fail open so statistics are never left stale because Redis is unavailable.
+func (a *TagAnalyzer) coalesced(ctx context.Context, job *queue.Job) bool {
+	if a.coalescer == nil || a.jobQueue == nil {
+		return false
+	}
+	decision, runAt, err := a.coalescer.Coalesce(ctx, job.UserID)
+	if err != nil {
+		a.logger.Warn("tag_analysis_coalescing_failed",
+			zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return false
+	}
+	switch decision {
+	case CoalesceDefer:
+		trailing := queue.NewJob(queue.JobTypeTagAnalysis, job.UserID, nil)
+		trailing.NotBefore = &runAt
+		if err := a.jobQueue.Enqueue(ctx, trailing); err != nil {
+			a.logger.Warn("failed_to_enqueue_trailing_tag_analysis",
+				zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+				zap.String("error", logpkg.SanitizeError(err)),
+			)
+			return false
+		}
+		a.logger.Debug("tag_analysis_deferred",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+			zap.Time("run_at", runAt),
+		)
+		return true
+	case CoalesceSkip:
+		a.logger.Debug("tag_analysis_coalesced",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+		)
+		return true
+	default:
+		return false
+	}
+}
+
 func (a *TagAnalyzer) loadAllTodosForUser(ctx context.Context, userID uuid.UUID) ([]*models.Todo, error) {
 	var allTodos []*models.Todo
 	page, pageSize := 1, 500
@@ -114,6 +217,40 @@ func (a *TagAnalyzer) loadAllTodosForUser(ctx context.Context, userID uuid.UUID)
 	return allTodos, nil
 }
 
+// loadTagAliases returns the user's AI context for alias lookups, or nil when aliases are not configured
+func (a *TagAnalyzer) loadTagAliases(ctx context.Context, userID uuid.UUID) *models.AIContext {
+	if a.contextRepo == nil {
+		return nil
+	}
+	userContext, err := a.contextRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil
+	}
+	return userContext
+}
+
+// canonicalizeTodoTags returns copies of todos whose tags (and tag sources) use canonical tags only.
+// Todos without aliased tags are returned as-is.
+func canonicalizeTodoTags(todos []*models.Todo, aliases *models.AIContext) []*models.Todo {
+	if aliases == nil || len(aliases.TagAliases) == 0 {
+		return todos
+	}
+	out := make([]*models.Todo, 0, len(todos))
+	for _, todo := range todos {
+		tags := aliases.CanonicalizeTags(todo.Metadata.CategoryTags)
+		if slices.Equal(tags, todo.Metadata.CategoryTags) {
+			out = append(out, todo)
+			continue
+		}
+		canonical := *todo
+		snapshot := aliases.CanonicalizeTagSnapshot(todo.Metadata.TagSnapshot())
+		canonical.Metadata.CategoryTags = snapshot.Tags
+		canonical.Metadata.TagSources = snapshot.Sources
+		out = append(out, &canonical)
+	}
+	return out
+}
+
 func aggregateTagStatsFromTodos(todos []*models.Todo) (tagStatsMap map[string]models.TagStats, todosWithTags, completedWithTags int) {
 	tagStatsMap = make(map[string]models.TagStats)
 	for _, todo := range todos {
@@ -127,13 +264,7 @@ func aggregateTagStatsFromTodos(todos []*models.Todo) (tagStatsMap map[string]mo
 		for _, tag := range todo.Metadata.CategoryTags {
 			st := tagStatsMap[tag]
 			st.Total++
-			source := models.TagSourceAI
-			if todo.Metadata.TagSources != nil {
-				if ts, ok := todo.Metadata.TagSources[tag]; ok {
-					source = ts
-				}
-			}
-			switch source {
+			switch tagSource(todo, tag) {
 			case models.TagSourceAI:
 				st.AI++
 			case models.TagSourceUser:
@@ -145,6 +276,72 @@ func aggregateTagStatsFromTodos(todos []*models.Todo) (tagStatsMap map[string]mo
 	return tagStatsMap, todosWithTags, completedWithTags
 }
 
+// tagSource returns the source of one of todo's tags; tags without a recorded source count as AI tags
+func tagSource(todo *models.Todo, tag string) models.TagSource {
+	if ts, ok := todo.Metadata.TagSources[tag]; ok {
+		return ts
+	}
+	return models.TagSourceAI
+}
+
+// tagInputsHash returns a hash of everything tag statistics are aggregated from: each todo's sorted tags with
+// their sources, and tagAggregationVersion. It does not depend on todo order, so equal hashes mean aggregation
+// yields the same statistics.
+func tagInputsHash(todos []*models.Todo) string {
+	entries := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		if len(todo.Metadata.CategoryTags) == 0 {
+			continue
+		}
+		tags := make([]string, 0, len(todo.Metadata.CategoryTags))
+		for _, tag := range todo.Metadata.CategoryTags {
+			tags = append(tags, strconv.Quote(tag)+"="+string(tagSource(todo, tag)))
+		}
+		sort.Strings(tags)
+		entries = append(entries, strings.Join(tags, ","))
+	}
+	sort.Strings(entries)
+	sum := sha256.Sum256([]byte("v" + strconv.Itoa(tagAggregationVersion) + "\n" + strings.Join(entries, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// aggregateTagCoOccurrence counts how many todos carry each pair of tags and keeps the maxPairs
+// strongest pairs (ties broken alphabetically so the stored matrix is stable between runs).
+func aggregateTagCoOccurrence(todos []*models.Todo, maxPairs int) []models.TagPair {
+	counts := make(map[[2]string]int)
+	for _, todo := range todos {
+		tags := uniqueSortedTags(todo.Metadata.CategoryTags)
+		for i := 0; i < len(tags); i++ {
+			for j := i + 1; j < len(tags); j++ {
+				counts[[2]string{tags[i], tags[j]}]++
+			}
+		}
+	}
+	pairs := make([]models.TagPair, 0, len(counts))
+	for key, count := range counts {
+		pairs = append(pairs, models.TagPair{A: key[0], B: key[1], Count: count})
+	}
+	models.SortTagPairs(pairs)
+	if maxPairs > 0 && len(pairs) > maxPairs {
+		pairs = pairs[:maxPairs]
+	}
+	return pairs
+}
+
+func uniqueSortedTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
+}
+
 func (a *TagAnalyzer) logTagBreakdownIfDebug(userID uuid.UUID, tagStatsMap map[string]models.TagStats) {
 	if len(tagStatsMap) == 0 || !a.logger.Core().Enabled(zap.DebugLevel) {
 		return
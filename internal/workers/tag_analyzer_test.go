@@ -2,6 +2,8 @@ package workers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"sync"
 	"testing"
@@ -886,3 +888,216 @@ func TestTagAnalyzer_ProcessTagAnalysisJob_DatabaseError(t *testing.T) {
 }
 
 // Use the existing mockMessage from analyzer_test.go
+
+func TestAggregateTagCoOccurrence(t *testing.T) {
+	t.Parallel()
+
+	todoWithTags := func(tags ...string) *models.Todo {
+		return &models.Todo{Metadata: models.Metadata{CategoryTags: tags}}
+	}
+
+	tests := []struct {
+		name     string
+		todos    []*models.Todo
+		maxPairs int
+		want     []models.TagPair
+	}{
+		{
+			name: "counts pairs across todos, strongest first",
+			todos: []*models.Todo{
+				todoWithTags("work", "urgent"),
+				todoWithTags("urgent", "work", "email"),
+				todoWithTags("home"),
+			},
+			maxPairs: 10,
+			want: []models.TagPair{
+				{A: "urgent", B: "work", Count: 2},
+				{A: "email", B: "urgent", Count: 1},
+				{A: "email", B: "work", Count: 1},
+			},
+		},
+		{
+			name:     "duplicate tags on one todo count once",
+			todos:    []*models.Todo{todoWithTags("a", "b", "a", "b")},
+			maxPairs: 10,
+			want:     []models.TagPair{{A: "a", B: "b", Count: 1}},
+		},
+		{
+			name: "capped to max pairs",
+			todos: []*models.Todo{
+				todoWithTags("a", "b", "c"),
+				todoWithTags("a", "b"),
+			},
+			maxPairs: 2,
+			want: []models.TagPair{
+				{A: "a", B: "b", Count: 2},
+				{A: "a", B: "c", Count: 1},
+			},
+		},
+		{
+			name:     "single-tag and untagged todos produce no pairs",
+			todos:    []*models.Todo{todoWithTags("solo"), todoWithTags()},
+			maxPairs: 10,
+			want:     []models.TagPair{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := aggregateTagCoOccurrence(tt.todos, tt.maxPairs)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d pairs %v, want %d %v", len(got), got, len(tt.want), tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("pair[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCanonicalizeTodoTags_CountsCanonicalTagsOnly(t *testing.T) {
+	t.Parallel()
+
+	aliases := &models.AIContext{TagAliases: map[string]string{"chores": "errands"}}
+	todos := []*models.Todo{
+		{Metadata: models.Metadata{
+			CategoryTags: []string{"chores", "home"},
+			TagSources:   map[string]models.TagSource{"chores": models.TagSourceAI, "home": models.TagSourceAI},
+		}},
+		{Metadata: models.Metadata{
+			CategoryTags: []string{"errands", "chores"},
+			TagSources:   map[string]models.TagSource{"errands": models.TagSourceUser, "chores": models.TagSourceAI},
+		}},
+		{Metadata: models.Metadata{CategoryTags: []string{"work"}}},
+	}
+
+	canonical := canonicalizeTodoTags(todos, aliases)
+	if todos[0].Metadata.CategoryTags[0] != "chores" {
+		t.Error("input todos should not be modified")
+	}
+	if canonical[2] != todos[2] {
+		t.Error("todos without aliases should be reused as-is")
+	}
+
+	stats, _, _ := aggregateTagStatsFromTodos(canonical)
+	if _, ok := stats["chores"]; ok {
+		t.Errorf("alias counted in statistics: %+v", stats)
+	}
+	errands := stats["errands"]
+	if errands.Total != 2 || errands.AI != 1 || errands.User != 1 {
+		t.Errorf("errands stats = %+v, want total 2 (1 AI, 1 user)", errands)
+	}
+	pairs := aggregateTagCoOccurrence(canonical, models.MaxTagPairs)
+	if len(pairs) != 1 || pairs[0] != (models.TagPair{A: "errands", B: "home", Count: 1}) {
+		t.Errorf("co-occurrence = %+v, want only errands+home", pairs)
+	}
+}
+
+func TestTagInputsHash(t *testing.T) {
+	t.Parallel()
+
+	todo := func(sources map[string]models.TagSource, tags ...string) *models.Todo {
+		return &models.Todo{ID: uuid.New(), Metadata: models.Metadata{CategoryTags: tags, TagSources: sources}}
+	}
+	ai := map[string]models.TagSource{"work": models.TagSourceAI, "urgent": models.TagSourceAI}
+	base := []*models.Todo{todo(ai, "work", "urgent"), todo(nil, "home"), todo(nil)}
+
+	tests := []struct {
+		name  string
+		todos []*models.Todo
+		equal bool
+	}{
+		{name: "same inputs on other todos", todos: []*models.Todo{todo(ai, "work", "urgent"), todo(nil, "home")}, equal: true},
+		{name: "todos and tags reordered", todos: []*models.Todo{todo(nil, "home"), todo(ai, "urgent", "work")}, equal: true},
+		{name: "missing source counts as AI", todos: []*models.Todo{todo(nil, "work", "urgent"), todo(nil, "home")}, equal: true},
+		{name: "tag source changed", todos: []*models.Todo{todo(map[string]models.TagSource{"work": models.TagSourceUser}, "work", "urgent"), todo(nil, "home")}},
+		{name: "tag moved to another todo", todos: []*models.Todo{todo(ai, "work"), todo(nil, "home", "urgent")}},
+		{name: "tag removed", todos: []*models.Todo{todo(ai, "work"), todo(nil, "home")}},
+		{name: "no tags", todos: []*models.Todo{todo(nil)}},
+	}
+
+	want := tagInputsHash(base)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tagInputsHash(tt.todos); (got == want) != tt.equal {
+				t.Errorf("tagInputsHash() = %s, base %s, want equal %v", got, want, tt.equal)
+			}
+		})
+	}
+}
+
+func TestTagAnalyzer_ProcessTagAnalysisJob_SkipsUnchangedInputs(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	todos := []*models.Todo{{
+		ID: uuid.New(), UserID: userID, Status: models.TodoStatusProcessed,
+		Metadata: models.Metadata{CategoryTags: []string{"test"}, TagSources: map[string]models.TagSource{"test": models.TagSourceAI}},
+	}}
+	current := tagInputsHash(todos)
+	// What tagInputsHash returned before it was salted with tagAggregationVersion
+	legacySum := sha256.Sum256([]byte(`"test"=ai`))
+	legacyHash := hex.EncodeToString(legacySum[:])
+
+	tests := []struct {
+		name        string
+		tainted     bool
+		force       bool
+		storedHash  string
+		wantUpdated bool
+	}{
+		{name: "unchanged inputs", storedHash: current},
+		{name: "tainted with unchanged inputs", tainted: true, storedHash: current, wantUpdated: true},
+		{name: "forced with unchanged inputs", force: true, storedHash: current, wantUpdated: true},
+		{name: "aggregated by an older aggregation version", storedHash: legacyHash, wantUpdated: true},
+		{name: "changed inputs", storedHash: tagInputsHash(nil), wantUpdated: true},
+		{name: "no stored hash", wantUpdated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todoRepo := &mockTodoRepo{
+				t: t,
+				getByUserIDPaginatedFunc: func(ctx context.Context, uid uuid.UUID, timeHorizon *models.TimeHorizon, status *models.TodoStatus, page, pageSize int) ([]*models.Todo, int, error) {
+					return todos, len(todos), nil
+				},
+			}
+			tagStatsRepo := &mockTagStatisticsRepoForWorker{
+				t: t,
+				getByUserIDOrCreateFunc: func(ctx context.Context, uid uuid.UUID) (*models.TagStatistics, error) {
+					return &models.TagStatistics{UserID: uid, TagStats: map[string]models.TagStats{}, Tainted: tt.tainted, AnalysisVersion: 3, ContentHash: tt.storedHash}, nil
+				},
+				updateStatisticsFunc: func(ctx context.Context, s *models.TagStatistics) (bool, error) {
+					return true, nil
+				},
+				markTaintedFunc: func(ctx context.Context, uid uuid.UUID) (bool, error) {
+					return true, nil
+				},
+			}
+			analyzer := NewTagAnalyzer(todoRepo, tagStatsRepo, zap.NewNop())
+
+			job := queue.NewJob(queue.JobTypeTagAnalysis, userID, nil)
+			if tt.force {
+				job.Metadata[queue.MetadataForceTagAnalysis] = true
+			}
+			if err := analyzer.ProcessTagAnalysisJob(context.Background(), job); err != nil {
+				t.Fatalf("ProcessTagAnalysisJob() error = %v", err)
+			}
+			if updated := len(tagStatsRepo.updateStatisticsCalls) == 1; updated != tt.wantUpdated {
+				t.Fatalf("UpdateStatistics called = %v, want %v", updated, tt.wantUpdated)
+			}
+			// Forced jobs taint the stored statistics so UpdateStatistics' own unchanged-inputs check lets them through
+			if tainted := len(tagStatsRepo.markTaintedCalls) == 1; tainted != tt.force {
+				t.Errorf("MarkTainted called = %v, want %v", tainted, tt.force)
+			}
+			if tt.wantUpdated && tagStatsRepo.updateStatisticsCalls[0].ContentHash != current {
+				t.Errorf("stored hash = %q, want %q", tagStatsRepo.updateStatisticsCalls[0].ContentHash, current)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultTagAnalysisDebounce is how long after a user's last tag change their tag analysis runs
+	DefaultTagAnalysisDebounce = 5 * time.Second
+	// DefaultTagAnalysisDebounceMaxWait caps how long repeated tag changes may delay a tag analysis
+	DefaultTagAnalysisDebounceMaxWait = time.Minute
+
+	// tagChangeBurstPruneSize is how many users' bursts are tracked before finished ones are dropped
+	tagChangeBurstPruneSize = 1024
+)
+
+// TagStatsTainter marks a user's tag statistics as needing a full tag analysis.
+// *database.TagStatisticsRepository implements it.
+type TagStatsTainter interface {
+	MarkTainted(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// TagChangeHandler keeps tag statistics current when a todo repository reports tag changes. It applies the
+// change incrementally when configured to, and otherwise marks the statistics tainted and schedules a
+// debounced tag analysis: each change pushes the analysis out by the debounce delay, but never past the max
+// wait after the first change of a burst, so rapid-fire edits cannot postpone it forever. Its Handle method
+// is a database.TagChangeHandler.
+type TagChangeHandler struct {
+	tagStats    TagStatsTainter
+	jobQueue    queue.JobQueue
+	incremental *IncrementalTagStats
+	debounce    time.Duration
+	maxWait     time.Duration
+	logger      *zap.Logger
+	now         func() time.Time
+
+	mu     sync.Mutex
+	bursts map[uuid.UUID]tagChangeBurst
+}
+
+// tagChangeBurst is a user's run of tag changes covered by an enqueued tag analysis due at runAt
+type tagChangeBurst struct {
+	start time.Time
+	runAt time.Time
+}
+
+// TagChangeHandlerOption configures a TagChangeHandler.
+type TagChangeHandlerOption func(*TagChangeHandler)
+
+// WithTagChangeIncremental applies tag changes through incremental before falling back to a tag analysis.
+func WithTagChangeIncremental(incremental *IncrementalTagStats) TagChangeHandlerOption {
+	return func(h *TagChangeHandler) {
+		h.incremental = incremental
+	}
+}
+
+// WithTagChangeDebounce sets how long after the last tag change the analysis runs (0 runs it at once) and
+// how long past the first change of a burst it may be pushed out. maxWait is raised to debounce if lower.
+func WithTagChangeDebounce(debounce, maxWait time.Duration) TagChangeHandlerOption {
+	return func(h *TagChangeHandler) {
+		h.debounce = max(debounce, 0)
+		h.maxWait = max(maxWait, h.debounce)
+	}
+}
+
+// NewTagChangeHandler creates a handler that taints statistics through tagStats and enqueues tag analysis
+// jobs on jobQueue (nil only logs that no job could be enqueued).
+func NewTagChangeHandler(tagStats TagStatsTainter, jobQueue queue.JobQueue, logger *zap.Logger, opts ...TagChangeHandlerOption) *TagChangeHandler {
+	h := &TagChangeHandler{
+		tagStats: tagStats,
+		jobQueue: jobQueue,
+		debounce: DefaultTagAnalysisDebounce,
+		maxWait:  DefaultTagAnalysisDebounceMaxWait,
+		logger:   logger,
+		now:      time.Now,
+		bursts:   make(map[uuid.UUID]tagChangeBurst),
+	}
+	for _, o := range opts {
+		o(h)
+	}
+	return h
+}
+
+// Handle reacts to a tag change of the user. The job is enqueued even when marking the statistics tainted
+// fails, so the analysis eventually repairs them; an error is returned only when the job could not be enqueued.
+func (h *TagChangeHandler) Handle(ctx context.Context, userID uuid.UUID, deltas []models.TagDelta) error {
+	h.logger.Debug("tag_change_handler_invoked",
+		zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+	)
+
+	// In incremental mode, apply the change to the stored statistics and skip the full reanalysis
+	// unless the statistics are not current or the last full analysis is too old
+	if h.incremental != nil && h.incremental.Apply(ctx, userID, deltas) {
+		return nil
+	}
+
+	var markTaintedErr error
+	if _, err := h.tagStats.MarkTainted(ctx, userID); err != nil {
+		h.logger.Warn("failed_to_mark_tag_statistics_tainted",
+			zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		markTaintedErr = err
+	}
+
+	if h.jobQueue == nil {
+		h.logger.Warn("job_queue_not_available",
+			zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+		)
+		return nil
+	}
+
+	now := h.now()
+	runAt, enqueue := h.schedule(userID, now)
+	if !enqueue {
+		h.logger.Debug("tag_analysis_job_already_pending",
+			zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+			zap.Time("run_at", runAt),
+		)
+		return nil
+	}
+
+	tagJob := queue.NewJob(queue.JobTypeTagAnalysis, userID, nil)
+	if runAt.After(now) {
+		tagJob.NotBefore = &runAt
+	}
+	if err := h.jobQueue.Enqueue(ctx, tagJob); err != nil {
+		h.forget(userID, runAt)
+		h.logger.Error("failed_to_enqueue_tag_analysis_job",
+			zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		err = fmt.Errorf("failed to enqueue tag analysis job: %w", err)
+		if markTaintedErr != nil {
+			return errors.Join(markTaintedErr, err)
+		}
+		return err
+	}
+	h.logger.Info("enqueued_tag_analysis_job",
+		zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+		zap.Duration("debounce_delay", runAt.Sub(now)),
+	)
+	return nil
+}
+
+// schedule returns when the user's tag analysis should run after a change at now, and whether a job has to be
+// enqueued for it. A change while a job is pending pushes the run out by the debounce delay, capped at the max
+// wait after the burst's first change; once the cap is reached the pending job already covers the change.
+func (h *TagChangeHandler) schedule(userID uuid.UUID, now time.Time) (time.Time, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	burst, ok := h.bursts[userID]
+	if !ok || !now.Before(burst.runAt) {
+		if len(h.bursts) >= tagChangeBurstPruneSize {
+			for id, b := range h.bursts {
+				if !now.Before(b.runAt) {
+					delete(h.bursts, id)
+				}
+			}
+		}
+		burst = tagChangeBurst{start: now, runAt: now.Add(h.debounce)}
+		h.bursts[userID] = burst
+		return burst.runAt, true
+	}
+
+	runAt := now.Add(h.debounce)
+	if limit := burst.start.Add(h.maxWait); runAt.After(limit) {
+		runAt = limit
+	}
+	if !runAt.After(burst.runAt) {
+		return burst.runAt, false
+	}
+	burst.runAt = runAt
+	h.bursts[userID] = burst
+	return runAt, true
+}
+
+// forget drops the user's burst after its job for runAt failed to enqueue, so the next change starts a new one
+func (h *TagChangeHandler) forget(userID uuid.UUID, runAt time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if burst, ok := h.bursts[userID]; ok && burst.runAt.Equal(runAt) {
+		delete(h.bursts, userID)
+	}
+}
@@ -0,0 +1,141 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type fakeTagStatsTainter struct {
+	err     error
+	tainted []uuid.UUID
+}
+
+func (f *fakeTagStatsTainter) MarkTainted(ctx context.Context, userID uuid.UUID) (bool, error) {
+	f.tainted = append(f.tainted, userID)
+	return f.err == nil, f.err
+}
+
+func TestTagChangeHandler_Debounce(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		debounce time.Duration
+		maxWait  time.Duration
+		// changes are the offsets from start of successive tag changes of one user
+		changes []time.Duration
+		// wantRunAt are the offsets from start of the enqueued jobs' NotBefore; -1 for no NotBefore
+		wantRunAt []time.Duration
+	}{
+		{
+			name: "single change", debounce: 5 * time.Second, maxWait: time.Minute,
+			changes: []time.Duration{0}, wantRunAt: []time.Duration{5 * time.Second},
+		},
+		{
+			name: "changes push the run out until the max wait", debounce: 5 * time.Second, maxWait: 12 * time.Second,
+			changes:   []time.Duration{0, 2 * time.Second, 6 * time.Second, 9 * time.Second, 10 * time.Second, 11 * time.Second},
+			wantRunAt: []time.Duration{5 * time.Second, 7 * time.Second, 11 * time.Second, 12 * time.Second},
+		},
+		{
+			name: "change after the run starts a new burst", debounce: 5 * time.Second, maxWait: 5 * time.Second,
+			changes:   []time.Duration{0, time.Second, 5 * time.Second},
+			wantRunAt: []time.Duration{5 * time.Second, 10 * time.Second},
+		},
+		{
+			name: "no debounce", debounce: 0, maxWait: 0,
+			changes: []time.Duration{0, 0}, wantRunAt: []time.Duration{-1, -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			jobQueue := &mockJobQueue{t: t}
+			handler := NewTagChangeHandler(&fakeTagStatsTainter{}, jobQueue, zap.NewNop(), WithTagChangeDebounce(tt.debounce, tt.maxWait))
+			userID := uuid.New()
+
+			for _, offset := range tt.changes {
+				handler.now = func() time.Time { return start.Add(offset) }
+				if err := handler.Handle(context.Background(), userID, nil); err != nil {
+					t.Fatalf("Handle() at +%v error = %v", offset, err)
+				}
+			}
+
+			if len(jobQueue.enqueueCalls) != len(tt.wantRunAt) {
+				t.Fatalf("enqueued %d jobs, want %d", len(jobQueue.enqueueCalls), len(tt.wantRunAt))
+			}
+			for i, job := range jobQueue.enqueueCalls {
+				if job.Type != queue.JobTypeTagAnalysis || job.UserID != userID {
+					t.Errorf("job %d = %s for %s, want tag analysis for %s", i, job.Type, job.UserID, userID)
+				}
+				switch want := tt.wantRunAt[i]; {
+				case want < 0 && job.NotBefore != nil:
+					t.Errorf("job %d NotBefore = %v, want none", i, job.NotBefore)
+				case want >= 0 && (job.NotBefore == nil || job.NotBefore.Sub(start) != want):
+					t.Errorf("job %d NotBefore = %v, want start+%v", i, job.NotBefore, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTagChangeHandler_Handle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		taintErr    error
+		enqueueErr  error
+		noQueue     bool
+		wantErr     bool
+		wantJobs    int
+		wantRetried bool // a second change after the failure enqueues again
+	}{
+		{name: "taints and enqueues", wantJobs: 1},
+		{name: "taint failure still enqueues", taintErr: errors.New("db down"), wantJobs: 1},
+		{name: "enqueue failure", enqueueErr: errors.New("broker down"), wantErr: true, wantJobs: 1, wantRetried: true},
+		{name: "both fail", taintErr: errors.New("db down"), enqueueErr: errors.New("broker down"), wantErr: true, wantJobs: 1, wantRetried: true},
+		{name: "no queue", noQueue: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tainter := &fakeTagStatsTainter{err: tt.taintErr}
+			jobQueue := &mockJobQueue{t: t, enqueueFunc: func(ctx context.Context, job *queue.Job) error { return tt.enqueueErr }}
+			var q queue.JobQueue = jobQueue
+			if tt.noQueue {
+				q = nil
+			}
+			handler := NewTagChangeHandler(tainter, q, zap.NewNop())
+			userID := uuid.New()
+
+			err := handler.Handle(context.Background(), userID, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Handle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.taintErr != nil && !errors.Is(err, tt.taintErr) && tt.wantErr {
+				t.Errorf("Handle() error = %v, want it to include %v", err, tt.taintErr)
+			}
+			if len(tainter.tainted) != 1 || tainter.tainted[0] != userID {
+				t.Errorf("tainted %v, want %v", tainter.tainted, userID)
+			}
+			if len(jobQueue.enqueueCalls) != tt.wantJobs {
+				t.Fatalf("enqueued %d jobs, want %d", len(jobQueue.enqueueCalls), tt.wantJobs)
+			}
+			if tt.wantRetried {
+				_ = handler.Handle(context.Background(), userID, nil)
+				if len(jobQueue.enqueueCalls) != 2 {
+					t.Errorf("enqueued %d jobs after a second change, want 2", len(jobQueue.enqueueCalls))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// CoalesceDecision tells the tag analyzer what to do with a tag analysis job
+type CoalesceDecision int
+
+const (
+	// CoalesceRun means no analysis ran for the user within the window; run now
+	CoalesceRun CoalesceDecision = iota
+	// CoalesceDefer means an analysis ran recently; schedule a single trailing run at the returned time
+	CoalesceDefer
+	// CoalesceSkip means a trailing run is already scheduled; the job is superfluous
+	CoalesceSkip
+)
+
+// TagAnalysisCoalescer limits tag analysis to at most one run per user per window across all workers
+type TagAnalysisCoalescer interface {
+	Coalesce(ctx context.Context, userID uuid.UUID) (decision CoalesceDecision, runAt time.Time, err error)
+}
+
+// RedisTagAnalysisCoalescer coalesces tag analysis runs using Redis keys shared by all workers.
+// A last-run key with a TTL of the window marks a recent run, and a pending key ensures only one
+// trailing run is scheduled so edits made during the window are still picked up.
+type RedisTagAnalysisCoalescer struct {
+	client redis.Cmdable
+	window time.Duration
+}
+
+// NewRedisTagAnalysisCoalescer creates a new Redis-backed coalescer for the given window
+func NewRedisTagAnalysisCoalescer(client redis.Cmdable, window time.Duration) *RedisTagAnalysisCoalescer {
+	return &RedisTagAnalysisCoalescer{client: client, window: window}
+}
+
+// Coalesce decides whether a tag analysis for userID should run now, be deferred, or be skipped
+func (c *RedisTagAnalysisCoalescer) Coalesce(ctx context.Context, userID uuid.UUID) (CoalesceDecision, time.Time, error) {
+	runKey := fmt.Sprintf("tag_analysis:last_run:%s", userID)
+	pendingKey := fmt.Sprintf("tag_analysis:pending:%s", userID)
+
+	acquired, err := c.client.SetNX(ctx, runKey, time.Now().Unix(), c.window).Result()
+	if err != nil {
+		return CoalesceRun, time.Time{}, fmt.Errorf("failed to set tag analysis run key: %w", err)
+	}
+	if acquired {
+		if err := c.client.Del(ctx, pendingKey).Err(); err != nil {
+			return CoalesceRun, time.Time{}, fmt.Errorf("failed to clear tag analysis pending key: %w", err)
+		}
+		return CoalesceRun, time.Time{}, nil
+	}
+
+	remaining, err := c.client.PTTL(ctx, runKey).Result()
+	if err != nil {
+		return CoalesceRun, time.Time{}, fmt.Errorf("failed to read tag analysis run key ttl: %w", err)
+	}
+	if remaining <= 0 {
+		remaining = c.window
+	}
+	scheduled, err := c.client.SetNX(ctx, pendingKey, 1, remaining+c.window).Result()
+	if err != nil {
+		return CoalesceRun, time.Time{}, fmt.Errorf("failed to set tag analysis pending key: %w", err)
+	}
+	if scheduled {
+		return CoalesceDefer, time.Now().Add(remaining), nil
+	}
+	return CoalesceSkip, time.Time{}, nil
+}
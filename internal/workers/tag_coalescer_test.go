@@ -0,0 +1,168 @@
+package workers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryTagAnalysisCoalescer mirrors RedisTagAnalysisCoalescer semantics with an injectable clock
+type memoryTagAnalysisCoalescer struct {
+	mu      sync.Mutex
+	window  time.Duration
+	now     func() time.Time
+	lastRun map[uuid.UUID]time.Time
+	pending map[uuid.UUID]bool
+}
+
+func newMemoryTagAnalysisCoalescer(window time.Duration, now func() time.Time) *memoryTagAnalysisCoalescer {
+	return &memoryTagAnalysisCoalescer{
+		window:  window,
+		now:     now,
+		lastRun: make(map[uuid.UUID]time.Time),
+		pending: make(map[uuid.UUID]bool),
+	}
+}
+
+func (c *memoryTagAnalysisCoalescer) Coalesce(ctx context.Context, userID uuid.UUID) (CoalesceDecision, time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	last, ok := c.lastRun[userID]
+	if !ok || now.Sub(last) >= c.window {
+		c.lastRun[userID] = now
+		delete(c.pending, userID)
+		return CoalesceRun, time.Time{}, nil
+	}
+	if !c.pending[userID] {
+		c.pending[userID] = true
+		return CoalesceDefer, last.Add(c.window), nil
+	}
+	return CoalesceSkip, time.Time{}, nil
+}
+
+func TestTagAnalyzer_Coalescing_RapidJobsCollapseToSingleUpdate(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	todos := []*models.Todo{
+		{
+			ID:     uuid.New(),
+			UserID: userID,
+			Status: models.TodoStatusProcessed,
+			Metadata: models.Metadata{
+				CategoryTags: []string{"work"},
+				TagSources:   map[string]models.TagSource{"work": models.TagSourceUser},
+			},
+		},
+	}
+	todoRepo := &mockTodoRepo{
+		t: t,
+		getByUserIDPaginatedFunc: func(ctx context.Context, uid uuid.UUID, timeHorizon *models.TimeHorizon, status *models.TodoStatus, page, pageSize int) ([]*models.Todo, int, error) {
+			return todos, len(todos), nil
+		},
+	}
+	tagStatsRepo := &mockTagStatisticsRepoForWorker{
+		t: t,
+		getByUserIDOrCreateFunc: func(ctx context.Context, uid uuid.UUID) (*models.TagStatistics, error) {
+			return &models.TagStatistics{UserID: uid, TagStats: map[string]models.TagStats{}}, nil
+		},
+		updateStatisticsFunc: func(ctx context.Context, s *models.TagStatistics) (bool, error) {
+			return true, nil
+		},
+	}
+
+	var clockMu sync.Mutex
+	now := time.Now()
+	clock := func() time.Time {
+		clockMu.Lock()
+		defer clockMu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		clockMu.Lock()
+		defer clockMu.Unlock()
+		now = now.Add(d)
+	}
+
+	jobQueue := &mockJobQueue{t: t}
+	analyzer := NewTagAnalyzer(todoRepo, tagStatsRepo, zap.NewNop(),
+		WithTagAnalysisCoalescing(newMemoryTagAnalysisCoalescer(30*time.Second, clock), jobQueue),
+	)
+
+	for i := 0; i < 5; i++ {
+		job := queue.NewJob(queue.JobTypeTagAnalysis, userID, nil)
+		acked := false
+		msg := &mockMessage{job: job, ackFunc: func() error { acked = true; return nil }}
+		if err := analyzer.ProcessJob(context.Background(), msg); err != nil {
+			t.Fatalf("job %d: ProcessJob() error = %v", i, err)
+		}
+		if !acked {
+			t.Errorf("job %d: expected job to be acked", i)
+		}
+		advance(time.Second)
+	}
+
+	if got := len(tagStatsRepo.updateStatisticsCalls); got != 1 {
+		t.Errorf("UpdateStatistics called %d times within window, want 1", got)
+	}
+	if got := len(jobQueue.enqueueCalls); got != 1 {
+		t.Fatalf("enqueued %d trailing jobs, want 1", got)
+	}
+	trailing := jobQueue.enqueueCalls[0]
+	if trailing.Type != queue.JobTypeTagAnalysis || trailing.NotBefore == nil {
+		t.Fatalf("trailing job = %+v, want delayed tag analysis job", trailing)
+	}
+
+	// The trailing run fires once the window has elapsed and picks up edits made meanwhile
+	advance(30 * time.Second)
+	if err := analyzer.ProcessTagAnalysisJob(context.Background(), trailing); err != nil {
+		t.Fatalf("trailing ProcessTagAnalysisJob() error = %v", err)
+	}
+	if got := len(tagStatsRepo.updateStatisticsCalls); got != 2 {
+		t.Errorf("UpdateStatistics called %d times after trailing run, want 2", got)
+	}
+}
+
+func TestTagAnalyzer_Coalescing_DifferentUsersNotCoalesced(t *testing.T) {
+	t.Parallel()
+
+	todoRepo := &mockTodoRepo{
+		t: t,
+		getByUserIDPaginatedFunc: func(ctx context.Context, uid uuid.UUID, timeHorizon *models.TimeHorizon, status *models.TodoStatus, page, pageSize int) ([]*models.Todo, int, error) {
+			return nil, 0, nil
+		},
+	}
+	tagStatsRepo := &mockTagStatisticsRepoForWorker{
+		t: t,
+		getByUserIDOrCreateFunc: func(ctx context.Context, uid uuid.UUID) (*models.TagStatistics, error) {
+			return &models.TagStatistics{UserID: uid, TagStats: map[string]models.TagStats{}}, nil
+		},
+		updateStatisticsFunc: func(ctx context.Context, s *models.TagStatistics) (bool, error) {
+			return true, nil
+		},
+	}
+	jobQueue := &mockJobQueue{t: t}
+	analyzer := NewTagAnalyzer(todoRepo, tagStatsRepo, zap.NewNop(),
+		WithTagAnalysisCoalescing(newMemoryTagAnalysisCoalescer(time.Minute, time.Now), jobQueue),
+	)
+
+	for i := 0; i < 3; i++ {
+		job := queue.NewJob(queue.JobTypeTagAnalysis, uuid.New(), nil)
+		if err := analyzer.ProcessTagAnalysisJob(context.Background(), job); err != nil {
+			t.Fatalf("ProcessTagAnalysisJob() error = %v", err)
+		}
+	}
+	if got := len(tagStatsRepo.updateStatisticsCalls); got != 3 {
+		t.Errorf("UpdateStatistics called %d times, want 3", got)
+	}
+	if got := len(jobQueue.enqueueCalls); got != 0 {
+		t.Errorf("enqueued %d jobs, want 0", got)
+	}
+}
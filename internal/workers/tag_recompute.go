@@ -0,0 +1,81 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TagRecomputeUserSource streams every user ID to fn under a lock that lets only one recompute run at a time.
+// locked is false when another recompute holds the lock, in which case fn is not called.
+// *database.UserRepository implements it.
+type TagRecomputeUserSource interface {
+	ForEachIDForTagRecompute(ctx context.Context, fn func(uuid.UUID) error) (locked bool, err error)
+}
+
+// TagRecomputer enqueues a tag analysis job for every user, e.g. to rebuild all tag statistics after an
+// aggregation fix. Jobs are paced apart so the workers and database are not hit by every user at once.
+type TagRecomputer struct {
+	users    TagRecomputeUserSource
+	jobQueue queue.JobQueue
+	pacing   time.Duration
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewTagRecomputer creates a recomputer that schedules each user's job pacing after the previous one
+// (0 makes every job due at once)
+func NewTagRecomputer(users TagRecomputeUserSource, jobQueue queue.JobQueue, pacing time.Duration, logger *zap.Logger) *TagRecomputer {
+	return &TagRecomputer{
+		users:    users,
+		jobQueue: jobQueue,
+		pacing:   max(pacing, 0),
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// RecomputeAll enqueues one forced tag analysis job per user as the user IDs are streamed, the first due now
+// and each following one pacing later. It stops at the first enqueue failure and returns the jobs enqueued
+// until then with the error.
+func (r *TagRecomputer) RecomputeAll(ctx context.Context) (models.TagRecomputeResult, error) {
+	now := r.now()
+	var result models.TagRecomputeResult
+	locked, err := r.users.ForEachIDForTagRecompute(ctx, func(userID uuid.UUID) error {
+		job := queue.NewJob(queue.JobTypeTagAnalysis, userID, nil)
+		job.Metadata[queue.MetadataForceTagAnalysis] = true
+		runAt := now.Add(r.pacing * time.Duration(result.Enqueued))
+		if runAt.After(now) {
+			job.NotBefore = &runAt
+		}
+		if err := r.jobQueue.Enqueue(ctx, job); err != nil {
+			return fmt.Errorf("failed to enqueue tag analysis job: %w", err)
+		}
+		result.Enqueued++
+		result.LastRunAt = &runAt
+		return nil
+	})
+	result.Locked = locked
+	if err != nil {
+		r.logger.Error("tag_recompute_failed",
+			zap.Int("enqueued", result.Enqueued),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return result, err
+	}
+	if !locked {
+		r.logger.Info("tag_recompute_skipped_lock_held")
+		return result, nil
+	}
+	r.logger.Info("tag_recompute_enqueued",
+		zap.Int("enqueued", result.Enqueued),
+		zap.Duration("pacing", r.pacing),
+	)
+	return result, nil
+}
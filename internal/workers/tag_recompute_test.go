@@ -0,0 +1,132 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeTagRecomputeUsers models the advisory lock with a mutex: a stream that finds it held reports locked=false
+type fakeTagRecomputeUsers struct {
+	lock   sync.Mutex
+	ids    []uuid.UUID
+	during func() // called while the lock is held, before streaming
+}
+
+func (f *fakeTagRecomputeUsers) ForEachIDForTagRecompute(ctx context.Context, fn func(uuid.UUID) error) (bool, error) {
+	if !f.lock.TryLock() {
+		return false, nil
+	}
+	defer f.lock.Unlock()
+	if f.during != nil {
+		f.during()
+	}
+	for _, id := range f.ids {
+		if err := fn(id); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+func TestTagRecomputer_RecomputeAll(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	tests := []struct {
+		name         string
+		pacing       time.Duration
+		failAt       int // 1-based enqueue call that fails; 0 never fails
+		wantEnqueued int
+		wantDelays   []time.Duration // NotBefore - now per job; -1 for no NotBefore
+		wantErr      bool
+	}{
+		{name: "paced one second apart", pacing: time.Second, wantEnqueued: 3, wantDelays: []time.Duration{-1, time.Second, 2 * time.Second}},
+		{name: "no pacing", pacing: 0, wantEnqueued: 3, wantDelays: []time.Duration{-1, -1, -1}},
+		{name: "stops at the first enqueue failure", pacing: time.Second, failAt: 2, wantEnqueued: 1, wantDelays: []time.Duration{-1, time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			jobQueue := &mockJobQueue{t: t}
+			jobQueue.enqueueFunc = func(ctx context.Context, job *queue.Job) error {
+				if len(jobQueue.enqueueCalls) == tt.failAt {
+					return errors.New("broker down")
+				}
+				return nil
+			}
+			recomputer := NewTagRecomputer(&fakeTagRecomputeUsers{ids: ids}, jobQueue, tt.pacing, zap.NewNop())
+			recomputer.now = func() time.Time { return now }
+
+			result, err := recomputer.RecomputeAll(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RecomputeAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !result.Locked || result.Enqueued != tt.wantEnqueued {
+				t.Errorf("result = %+v, want locked with %d enqueued", result, tt.wantEnqueued)
+			}
+			if len(jobQueue.enqueueCalls) != len(tt.wantDelays) {
+				t.Fatalf("enqueued %d jobs, want %d", len(jobQueue.enqueueCalls), len(tt.wantDelays))
+			}
+			for i, job := range jobQueue.enqueueCalls {
+				if job.Type != queue.JobTypeTagAnalysis || job.UserID != ids[i] {
+					t.Errorf("job %d = %s for %s, want tag analysis for %s", i, job.Type, job.UserID, ids[i])
+				}
+				if !jobMetadataBool(job, queue.MetadataForceTagAnalysis) {
+					t.Errorf("job %d is not forced, so unchanged inputs would skip the recompute", i)
+				}
+				switch want := tt.wantDelays[i]; {
+				case want < 0 && job.NotBefore != nil:
+					t.Errorf("job %d NotBefore = %v, want none", i, job.NotBefore)
+				case want >= 0 && (job.NotBefore == nil || job.NotBefore.Sub(now) != want):
+					t.Errorf("job %d NotBefore = %v, want now+%v", i, job.NotBefore, want)
+				}
+			}
+			if wantLast := now.Add(tt.pacing * time.Duration(tt.wantEnqueued-1)); !tt.wantErr && (result.LastRunAt == nil || !result.LastRunAt.Equal(wantLast)) {
+				t.Errorf("LastRunAt = %v, want %v", result.LastRunAt, wantLast)
+			}
+		})
+	}
+}
+
+func TestTagRecomputer_SingleRun(t *testing.T) {
+	t.Parallel()
+
+	users := &fakeTagRecomputeUsers{ids: []uuid.UUID{uuid.New(), uuid.New()}}
+	jobQueue := &mockJobQueue{t: t}
+	recomputer := NewTagRecomputer(users, jobQueue, time.Second, zap.NewNop())
+
+	// A second run started while the first holds the lock enqueues nothing
+	var concurrent models.TagRecomputeResult
+	users.during = func() {
+		var err error
+		if concurrent, err = recomputer.RecomputeAll(context.Background()); err != nil {
+			t.Errorf("concurrent RecomputeAll() error = %v", err)
+		}
+	}
+	first, err := recomputer.RecomputeAll(context.Background())
+	if err != nil {
+		t.Fatalf("RecomputeAll() error = %v", err)
+	}
+	if concurrent.Locked || concurrent.Enqueued != 0 {
+		t.Errorf("concurrent run = %+v, want skipped without enqueueing", concurrent)
+	}
+	if !first.Locked || first.Enqueued != 2 || len(jobQueue.enqueueCalls) != 2 {
+		t.Errorf("first run = %+v with %d enqueue calls, want 2 enqueued", first, len(jobQueue.enqueueCalls))
+	}
+
+	// Once the lock is released a new run goes ahead
+	users.during = nil
+	if again, err := recomputer.RecomputeAll(context.Background()); err != nil || !again.Locked || again.Enqueued != 2 {
+		t.Errorf("later run = %+v, %v, want 2 enqueued", again, err)
+	}
+}
@@ -0,0 +1,105 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/database"
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultTagStatsFullAnalysisInterval is how old the last full tag analysis may get before an incremental
+// update also schedules a full one to correct drift
+const DefaultTagStatsFullAnalysisInterval = 24 * time.Hour
+
+// TagStatsDeltaApplier applies per-todo tag changes to a user's stored tag statistics. It returns nil
+// statistics when there are none current to apply them to.
+type TagStatsDeltaApplier interface {
+	ApplyTagDeltas(ctx context.Context, userID uuid.UUID, deltas []models.TagDelta) (*models.TagStatistics, error)
+}
+
+// IncrementalTagStats keeps tag statistics current on tag changes by applying each change's delta directly,
+// instead of rescanning all of the user's todos. A full tag analysis is still needed when the change was not
+// tracked per todo, when the statistics are missing or tainted, and once the last full analysis is older than
+// the full analysis interval.
+type IncrementalTagStats struct {
+	applier      TagStatsDeltaApplier
+	contextRepo  database.AIContextRepositoryInterface
+	fullInterval time.Duration
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewIncrementalTagStats creates an incremental updater that applies deltas through applier. When contextRepo
+// is set, each user's tag aliases are folded into their canonical tags first, as the tag analyzer does.
+func NewIncrementalTagStats(applier TagStatsDeltaApplier, contextRepo database.AIContextRepositoryInterface, fullInterval time.Duration, logger *zap.Logger) *IncrementalTagStats {
+	if fullInterval <= 0 {
+		fullInterval = DefaultTagStatsFullAnalysisInterval
+	}
+	return &IncrementalTagStats{
+		applier:      applier,
+		contextRepo:  contextRepo,
+		fullInterval: fullInterval,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// Apply applies deltas to the user's tag statistics and reports whether they are now current. When it
+// returns false the caller should fall back to a full tag analysis.
+func (s *IncrementalTagStats) Apply(ctx context.Context, userID uuid.UUID, deltas []models.TagDelta) bool {
+	if deltas == nil {
+		return false
+	}
+	if aliases := s.loadTagAliases(ctx, userID); aliases != nil {
+		canonical := make([]models.TagDelta, len(deltas))
+		for i, delta := range deltas {
+			canonical[i] = models.TagDelta{
+				Before: aliases.CanonicalizeTagSnapshot(delta.Before),
+				After:  aliases.CanonicalizeTagSnapshot(delta.After),
+			}
+		}
+		deltas = canonical
+	}
+
+	stats, err := s.applier.ApplyTagDeltas(ctx, userID, deltas)
+	if err != nil {
+		s.logger.Warn("failed_to_apply_tag_deltas",
+			zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return false
+	}
+	if stats == nil {
+		s.logger.Debug("tag_statistics_not_current",
+			zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+		)
+		return false
+	}
+	if stats.LastAnalyzedAt == nil || s.now().Sub(*stats.LastAnalyzedAt) >= s.fullInterval {
+		s.logger.Debug("tag_statistics_full_analysis_due",
+			zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+		)
+		return false
+	}
+	s.logger.Debug("applied_tag_deltas",
+		zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+		zap.Int("deltas", len(deltas)),
+	)
+	return true
+}
+
+// loadTagAliases returns the user's AI context for alias lookups, or nil when aliases are not configured
+func (s *IncrementalTagStats) loadTagAliases(ctx context.Context, userID uuid.UUID) *models.AIContext {
+	if s.contextRepo == nil {
+		return nil
+	}
+	userContext, err := s.contextRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil
+	}
+	return userContext
+}
@@ -0,0 +1,144 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// inMemoryTagStats applies deltas to one user's statistics like TagStatisticsRepository.ApplyTagDeltas
+type inMemoryTagStats struct {
+	stats *models.TagStatistics
+	err   error
+	calls int
+}
+
+func (m *inMemoryTagStats) ApplyTagDeltas(ctx context.Context, userID uuid.UUID, deltas []models.TagDelta) (*models.TagStatistics, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.stats == nil || m.stats.Tainted {
+		return nil, nil
+	}
+	for _, delta := range deltas {
+		m.stats.ApplyTagDelta(delta)
+	}
+	m.stats.AnalysisVersion++
+	return m.stats, nil
+}
+
+// fullRescan computes statistics from scratch the way the tag analyzer does
+func fullRescan(todos []*models.Todo, aliases *models.AIContext) (map[string]models.TagStats, []models.TagPair) {
+	canonical := canonicalizeTodoTags(todos, aliases)
+	stats, _, _ := aggregateTagStatsFromTodos(canonical)
+	return stats, aggregateTagCoOccurrence(canonical, models.MaxTagPairs)
+}
+
+func TestIncrementalTagStats_MatchesFullRescan(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	aliases := &models.AIContext{TagAliases: map[string]string{"chores": "errands"}}
+	todos := []*models.Todo{
+		{ID: uuid.New(), UserID: userID, Metadata: models.Metadata{
+			CategoryTags: []string{"work", "urgent"},
+			TagSources:   map[string]models.TagSource{"work": models.TagSourceUser, "urgent": models.TagSourceAI},
+		}},
+		{ID: uuid.New(), UserID: userID, Metadata: models.Metadata{CategoryTags: []string{"home", "chores"}}},
+		{ID: uuid.New(), UserID: userID, Metadata: models.Metadata{
+			CategoryTags: []string{"work"},
+			TagSources:   map[string]models.TagSource{"work": models.TagSourceAI},
+		}},
+		{ID: uuid.New(), UserID: userID},
+	}
+
+	now := time.Now()
+	tagStats, coOccurrence := fullRescan(todos, aliases)
+	store := &inMemoryTagStats{stats: &models.TagStatistics{
+		UserID:         userID,
+		TagStats:       tagStats,
+		CoOccurrence:   coOccurrence,
+		LastAnalyzedAt: &now,
+	}}
+	incremental := NewIncrementalTagStats(store, &mockAIContextRepo{
+		getByUserIDFunc: func(ctx context.Context, userID uuid.UUID) (*models.AIContext, error) { return aliases, nil },
+	}, time.Hour, zap.NewNop())
+
+	edits := []struct {
+		name string
+		todo int
+		edit func(m *models.Metadata)
+	}{
+		{"user adds a tag", 0, func(m *models.Metadata) { m.SetUserTags([]string{"work", "urgent", "meeting"}) }},
+		{"user removes a tag", 0, func(m *models.Metadata) { m.SetUserTags([]string{"work", "meeting"}) }},
+		{"AI tags an untagged todo", 3, func(m *models.Metadata) { m.MergeTags([]string{"home", "errands"}, nil) }},
+		{"user overrides an AI tag", 2, func(m *models.Metadata) { m.SetUserTags([]string{"work"}) }},
+		{"alias rewritten to its canonical tag", 1, func(m *models.Metadata) { m.SetUserTags([]string{"errands", "home"}) }},
+		{"AI tags removed", 3, func(m *models.Metadata) { m.RemoveAITags() }},
+		{"all tags cleared", 0, func(m *models.Metadata) { m.SetUserTags(nil) }},
+	}
+
+	for _, e := range edits {
+		todo := todos[e.todo]
+		before := todo.Metadata.TagSnapshot()
+		e.edit(&todo.Metadata)
+		delta := models.TagDelta{Before: before, After: todo.Metadata.TagSnapshot()}
+
+		if !incremental.Apply(context.Background(), userID, []models.TagDelta{delta}) {
+			t.Fatalf("%s: Apply() = false, want true", e.name)
+		}
+		wantStats, wantPairs := fullRescan(todos, aliases)
+		if !reflect.DeepEqual(store.stats.TagStats, wantStats) {
+			t.Errorf("%s: tag stats = %+v, full rescan = %+v", e.name, store.stats.TagStats, wantStats)
+		}
+		if !reflect.DeepEqual(store.stats.CoOccurrence, wantPairs) {
+			t.Errorf("%s: co-occurrence = %+v, full rescan = %+v", e.name, store.stats.CoOccurrence, wantPairs)
+		}
+	}
+}
+
+func TestIncrementalTagStats_Apply_FallsBackToFullAnalysis(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	recent := now.Add(-time.Hour)
+	stale := now.Add(-25 * time.Hour)
+	delta := []models.TagDelta{{After: models.TagSnapshot{Tags: []string{"work"}}}}
+
+	tests := []struct {
+		name      string
+		store     *inMemoryTagStats
+		deltas    []models.TagDelta
+		want      bool
+		wantCalls int
+	}{
+		{"applied", &inMemoryTagStats{stats: &models.TagStatistics{LastAnalyzedAt: &recent}}, delta, true, 1},
+		{"untracked change", &inMemoryTagStats{stats: &models.TagStatistics{LastAnalyzedAt: &recent}}, nil, false, 0},
+		{"no statistics", &inMemoryTagStats{}, delta, false, 1},
+		{"tainted", &inMemoryTagStats{stats: &models.TagStatistics{Tainted: true, LastAnalyzedAt: &recent}}, delta, false, 1},
+		{"never fully analyzed", &inMemoryTagStats{stats: &models.TagStatistics{}}, delta, false, 1},
+		{"full analysis due", &inMemoryTagStats{stats: &models.TagStatistics{LastAnalyzedAt: &stale}}, delta, false, 1},
+		{"store error", &inMemoryTagStats{err: errors.New("connection refused")}, delta, false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			incremental := NewIncrementalTagStats(tt.store, nil, 24*time.Hour, zap.NewNop())
+			incremental.now = func() time.Time { return now }
+			if got := incremental.Apply(context.Background(), uuid.New(), tt.deltas); got != tt.want {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+			if tt.store.calls != tt.wantCalls {
+				t.Errorf("ApplyTagDeltas called %d times, want %d", tt.store.calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package workers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/database"
+	logpkg "github.com/benvon/smart-todo/internal/logger"
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/notify"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/benvon/smart-todo/internal/services/ai"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// WeeklySummaryTodoLimit caps how many completed and how many open todos go into one weekly summary prompt
+const WeeklySummaryTodoLimit = 50
+
+// WeeklySummaryNotificationKind is the notification kind weekly summaries are delivered with
+const WeeklySummaryNotificationKind = "weekly_summary"
+
+// WeeklySummaryClaimer marks the users due a week's summary as scheduled: users not yet scheduled for the week,
+// not paused, not opted out and, unless activeSince is zero, active since activeSince. locked is false when
+// another worker is scheduling, in which case nobody was claimed. *database.WeeklySummaryRepository implements it.
+type WeeklySummaryClaimer interface {
+	ClaimWeeklySummaryUsers(ctx context.Context, weekStart, activeSince time.Time) (claimed []uuid.UUID, locked bool, err error)
+	UnclaimWeeklySummaryUser(ctx context.Context, userID uuid.UUID, weekStart time.Time) error
+}
+
+// WeeklySummaryScheduler enqueues one weekly summary job per eligible user each week, spreading the jobs over
+// a window so the AI calls do not all land at once
+type WeeklySummaryScheduler struct {
+	claimer  WeeklySummaryClaimer
+	jobQueue queue.JobQueue
+	spread   time.Duration
+	maxIdle  time.Duration
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewWeeklySummaryScheduler creates a scheduler that paces jobs over spread and skips users idle for longer
+// than maxIdle (0 keeps every user)
+func NewWeeklySummaryScheduler(claimer WeeklySummaryClaimer, jobQueue queue.JobQueue, spread, maxIdle time.Duration, logger *zap.Logger) *WeeklySummaryScheduler {
+	return &WeeklySummaryScheduler{
+		claimer:  claimer,
+		jobQueue: jobQueue,
+		spread:   spread,
+		maxIdle:  maxIdle,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Schedule claims the users due a summary for the current week and enqueues their jobs. It returns how many
+// jobs were enqueued; users already scheduled this week are not claimed again, and users whose job could not
+// be enqueued are unclaimed so the next run retries them.
+func (s *WeeklySummaryScheduler) Schedule(ctx context.Context) (int, error) {
+	now := s.now()
+	weekStart := models.WeekStart(now)
+	var activeSince time.Time
+	if s.maxIdle > 0 {
+		activeSince = now.Add(-s.maxIdle)
+	}
+	claimed, locked, err := s.claimer.ClaimWeeklySummaryUsers(ctx, weekStart, activeSince)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim weekly summary users: %w", err)
+	}
+	if !locked {
+		s.logger.Debug("weekly_summary_schedule_skipped_lock_held")
+		return 0, nil
+	}
+
+	expires := weekStart.Add(models.WeeklySummaryPeriod)
+	enqueued := 0
+	for i, userID := range claimed {
+		job := queue.NewWeeklySummaryJob(userID, weekStart, paceAt(now, s.spread, i, len(claimed)), expires)
+		if err := s.jobQueue.Enqueue(ctx, job); err != nil {
+			s.logger.Warn("failed_to_enqueue_weekly_summary_job",
+				zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+				zap.String("error", logpkg.SanitizeError(err)),
+			)
+			if err := s.claimer.UnclaimWeeklySummaryUser(ctx, userID, weekStart); err != nil {
+				s.logger.Error("failed_to_unclaim_weekly_summary_user",
+					zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+					zap.String("error", logpkg.SanitizeError(err)),
+				)
+			}
+			continue
+		}
+		enqueued++
+	}
+	if len(claimed) > 0 {
+		s.logger.Info("scheduled_weekly_summaries",
+			zap.Time("week_start", weekStart),
+			zap.Int("user_count", len(claimed)),
+			zap.Int("enqueued", enqueued),
+			zap.Duration("spread", s.spread),
+		)
+	}
+	return enqueued, nil
+}
+
+// paceAt spreads job i of n evenly over spread starting at now
+func paceAt(now time.Time, spread time.Duration, i, n int) time.Time {
+	if spread <= 0 || n <= 1 {
+		return now
+	}
+	return now.Add(spread * time.Duration(i) / time.Duration(n))
+}
+
+// WeeklySummaryTodoLister lists the todos a weekly summary is written from
+type WeeklySummaryTodoLister interface {
+	ListCompletedBetween(ctx context.Context, userID uuid.UUID, from, to time.Time, limit int) ([]*models.Todo, error)
+	ListOpenByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*models.Todo, error)
+}
+
+// WeeklySummaryStore keeps generated weekly summaries for GET /ai/weekly-summary
+type WeeklySummaryStore interface {
+	Save(ctx context.Context, summary *models.WeeklySummary) error
+}
+
+// WeeklySummaryWorker processes weekly summary jobs: it summarizes the user's week with their AI provider,
+// stores the summary and delivers it through the notifier
+type WeeklySummaryWorker struct {
+	todos       WeeklySummaryTodoLister
+	contextRepo database.AIContextRepositoryInterface
+	store       WeeklySummaryStore
+	jobQueue    queue.JobQueue
+	providers   AIProviderResolver
+	notifier    notify.Notifier
+	logger      *zap.Logger
+	now         func() time.Time
+	registry    map[queue.JobType]processorEntry
+}
+
+// NewWeeklySummaryWorker creates a new weekly summary worker and registers the weekly_summary processor.
+// Providers that do not implement ai.WeeklySummarizer get a plain count-based summary. Jobs that fail on a
+// transient AI error are re-enqueued on jobQueue with a backoff.
+func NewWeeklySummaryWorker(
+	todos WeeklySummaryTodoLister,
+	contextRepo database.AIContextRepositoryInterface,
+	store WeeklySummaryStore,
+	jobQueue queue.JobQueue,
+	providers AIProviderResolver,
+	notifier notify.Notifier,
+	logger *zap.Logger,
+) *WeeklySummaryWorker {
+	w := &WeeklySummaryWorker{
+		todos:       todos,
+		contextRepo: contextRepo,
+		store:       store,
+		jobQueue:    jobQueue,
+		providers:   providers,
+		notifier:    notifier,
+		logger:      logger,
+		now:         time.Now,
+		registry:    make(map[queue.JobType]processorEntry),
+	}
+	w.registry[queue.JobTypeWeeklySummary] = processorEntry{proc: w.ProcessWeeklySummaryJob}
+	return w
+}
+
+// ProcessWeeklySummaryJob generates, stores and delivers the summary for the job's week. Users who opted out
+// after the job was scheduled are skipped.
+func (w *WeeklySummaryWorker) ProcessWeeklySummaryJob(ctx context.Context, job *queue.Job) error {
+	weekStartStr, _ := job.Metadata[queue.MetadataWeekStart].(string)
+	weekStart, err := time.Parse(time.DateOnly, weekStartStr)
+	if err != nil {
+		return fmt.Errorf("invalid week_start for weekly summary job %s: %w", job.ID, err)
+	}
+	// Users without an AI context keep the defaults
+	userContext, err := w.contextRepo.GetByUserID(ctx, job.UserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get AI context: %w", err)
+	}
+	if !userContext.WeeklySummaryEnabled() {
+		w.logger.Debug("weekly_summary_skipped_opted_out",
+			zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+		)
+		return nil
+	}
+
+	completed, err := w.todos.ListCompletedBetween(ctx, job.UserID, weekStart.Add(-models.WeeklySummaryPeriod), weekStart, WeeklySummaryTodoLimit)
+	if err != nil {
+		return fmt.Errorf("failed to list completed todos: %w", err)
+	}
+	pending, err := w.todos.ListOpenByUserID(ctx, job.UserID, WeeklySummaryTodoLimit)
+	if err != nil {
+		return fmt.Errorf("failed to list open todos: %w", err)
+	}
+	text, err := w.summarize(ctx, job.UserID, userContext, completed, pending)
+	if err != nil {
+		return err
+	}
+
+	summary := &models.WeeklySummary{
+		UserID:         job.UserID,
+		WeekStart:      weekStart,
+		Summary:        text,
+		CompletedCount: len(completed),
+		PendingCount:   len(pending),
+		CreatedAt:      w.now(),
+	}
+	if err := w.store.Save(ctx, summary); err != nil {
+		return fmt.Errorf("failed to save weekly summary: %w", err)
+	}
+	if err := w.notifier.Notify(ctx, notify.Notification{
+		UserID: job.UserID,
+		Kind:   WeeklySummaryNotificationKind,
+		Text:   text,
+		SentAt: summary.CreatedAt,
+	}); err != nil {
+		return fmt.Errorf("failed to deliver weekly summary: %w", err)
+	}
+	w.logger.Info("weekly_summary_sent",
+		zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+		zap.String("week_start", weekStartStr),
+		zap.Int("completed_count", summary.CompletedCount),
+		zap.Int("pending_count", summary.PendingCount),
+	)
+	return nil
+}
+
+// summarize asks the user's AI provider for the summary, falling back to models.FormatWeeklySummary when
+// the provider cannot write one, rejects the request or is out of quota, or there is nothing to summarize.
+// Other AI errors are returned so the job is retried.
+func (w *WeeklySummaryWorker) summarize(ctx context.Context, userID uuid.UUID, userContext *models.AIContext, completed, pending []*models.Todo) (string, error) {
+	fallback := models.FormatWeeklySummary(len(completed), len(pending))
+	if len(completed) == 0 && len(pending) == 0 {
+		return fallback, nil
+	}
+	summarizer, ok := w.providers.ProviderFor(userContext).(ai.WeeklySummarizer)
+	if !ok {
+		return fallback, nil
+	}
+	ctx = context.WithValue(ctx, ai.UserIDContextKey(), userID)
+	text, err := summarizer.SummarizeWeek(ctx, todoTexts(completed), todoTexts(pending))
+	if err != nil {
+		kind := ai.ClassifyError(err)
+		if kind != ai.AIErrorInvalidRequest && kind != ai.AIErrorQuota {
+			return "", fmt.Errorf("failed to summarize week: %w", err)
+		}
+		// Retrying the same request cannot succeed, and a quota is unlikely to reset before the week is over
+		w.logger.Warn("weekly_summary_ai_failed_using_fallback",
+			zap.String("user_id", logpkg.SanitizeUserID(userID.String())),
+			zap.String("error_kind", string(kind)),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		return fallback, nil
+	}
+	return text, nil
+}
+
+func todoTexts(todos []*models.Todo) []string {
+	texts := make([]string, len(todos))
+	for i, todo := range todos {
+		texts[i] = todo.Text
+	}
+	return texts
+}
+
+// ProcessJob processes a job based on its type using the processor registry.
+func (w *WeeklySummaryWorker) ProcessJob(ctx context.Context, msg queue.MessageInterface) error {
+	job := msg.GetJob()
+	if job.IsExpired() {
+		w.logger.Debug("weekly_summary_job_expired",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+		)
+		return w.ack(msg, job)
+	}
+	ent, ok := w.registry[job.Type]
+	if !ok {
+		if nackErr := msg.Nack(false); nackErr != nil {
+			w.logger.Warn("failed_to_nack_unknown_job_type",
+				zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+				zap.String("error", logpkg.SanitizeError(nackErr)),
+			)
+		}
+		return fmt.Errorf("unknown job type: %s", job.Type)
+	}
+	if err := ent.proc(ctx, job); err != nil {
+		if w.retry(ctx, msg, job, err) {
+			return nil
+		}
+		w.logger.Error("weekly_summary_job_failed",
+			zap.String("operation", "process_job"),
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+			zap.String("error", logpkg.SanitizeError(err)),
+		)
+		if nackErr := msg.Nack(false); nackErr != nil {
+			w.logger.Warn("failed_to_nack_weekly_summary_job",
+				zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+				zap.String("error", logpkg.SanitizeError(nackErr)),
+			)
+		}
+		return fmt.Errorf("weekly summary failed: %w", err)
+	}
+	return w.ack(msg, job)
+}
+
+// retry re-enqueues a job that failed on a transient AI error with the backoff ai.GetRetryDelay gives, and
+// acks it. It reports false, leaving the job to the caller, when the error is not transient, the job's
+// retries are used up or it could not be re-enqueued.
+func (w *WeeklySummaryWorker) retry(ctx context.Context, msg queue.MessageInterface, job *queue.Job, err error) bool {
+	if w.jobQueue == nil || !ai.IsTransient(err) || !job.CanRetry() {
+		return false
+	}
+	delay := ai.GetRetryDelay(err, job.RetryCount)
+	delayed := buildDelayedJob(job, w.now().Add(delay))
+	if enqErr := w.jobQueue.Enqueue(ctx, delayed); enqErr != nil {
+		w.logger.Warn("failed_to_re_enqueue_weekly_summary_job",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("error", logpkg.SanitizeError(enqErr)),
+		)
+		return false
+	}
+	if ackErr := msg.Ack(); ackErr != nil {
+		w.logger.Warn("failed_to_ack_retried_weekly_summary_job",
+			zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+			zap.String("error", logpkg.SanitizeError(ackErr)),
+		)
+	}
+	w.logger.Warn("weekly_summary_job_re_enqueued",
+		zap.String("job_id", logpkg.SanitizeUserID(job.ID.String())),
+		zap.String("user_id", logpkg.SanitizeUserID(job.UserID.String())),
+		zap.Int("attempt", delayed.RetryCount),
+		zap.Duration("retry_delay", delay),
+		zap.String("error", logpkg.SanitizeError(err)),
+	)
+	return true
+}
+
+func (w *WeeklySummaryWorker) ack(msg queue.MessageInterface, job *queue.Job) error {
+	if err := msg.Ack(); err != nil {
+		return fmt.Errorf("failed to ack weekly summary job %s: %w", job.ID, err)
+	}
+	return nil
+}
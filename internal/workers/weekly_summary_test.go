@@ -0,0 +1,320 @@
+package workers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benvon/smart-todo/internal/models"
+	"github.com/benvon/smart-todo/internal/queue"
+	"github.com/benvon/smart-todo/internal/services/ai"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeWeeklySummaryClaimer claims the users the repository's query would: not yet scheduled this week, not
+// paused, not opted out and active since activeSince
+type fakeWeeklySummaryClaimer struct {
+	users       []weeklySummaryUser
+	locked      bool
+	weekStart   time.Time
+	activeSince time.Time
+	unclaimed   []uuid.UUID
+}
+
+type weeklySummaryUser struct {
+	id                 uuid.UUID
+	lastAPIInteraction time.Time
+	scheduledWeek      *time.Time
+	paused, optedOut   bool
+}
+
+func (f *fakeWeeklySummaryClaimer) ClaimWeeklySummaryUsers(ctx context.Context, weekStart, activeSince time.Time) ([]uuid.UUID, bool, error) {
+	f.weekStart, f.activeSince = weekStart, activeSince
+	if !f.locked {
+		return nil, false, nil
+	}
+	var claimed []uuid.UUID
+	for _, u := range f.users {
+		if u.paused || u.optedOut || (u.scheduledWeek != nil && !u.scheduledWeek.Before(weekStart)) {
+			continue
+		}
+		if !activeSince.IsZero() && u.lastAPIInteraction.Before(activeSince) {
+			continue
+		}
+		claimed = append(claimed, u.id)
+	}
+	return claimed, true, nil
+}
+
+func (f *fakeWeeklySummaryClaimer) UnclaimWeeklySummaryUser(ctx context.Context, userID uuid.UUID, weekStart time.Time) error {
+	f.unclaimed = append(f.unclaimed, userID)
+	return nil
+}
+
+func TestWeeklySummaryScheduler_Schedule(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday; the week started on Monday 2026-10-12
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	lastWeek := weekStart.Add(-models.WeeklySummaryPeriod)
+	active, stale, paused, optedOut, scheduled := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	active2 := uuid.New()
+	users := []weeklySummaryUser{
+		{id: active, lastAPIInteraction: now.Add(-time.Hour), scheduledWeek: &lastWeek},
+		{id: stale, lastAPIInteraction: now.Add(-60 * 24 * time.Hour)},
+		{id: paused, lastAPIInteraction: now, paused: true},
+		{id: optedOut, lastAPIInteraction: now, optedOut: true},
+		{id: scheduled, lastAPIInteraction: now, scheduledWeek: &weekStart},
+		{id: active2, lastAPIInteraction: now.Add(-48 * time.Hour)},
+	}
+
+	tests := []struct {
+		name          string
+		locked        bool
+		enqueueErrFor uuid.UUID
+		wantUsers     []uuid.UUID
+		wantUnclaimed []uuid.UUID
+	}{
+		{name: "enqueues eligible users", locked: true, wantUsers: []uuid.UUID{active, active2}},
+		{name: "lock held elsewhere", locked: false},
+		{name: "unclaims users whose job was not enqueued", locked: true, enqueueErrFor: active, wantUsers: []uuid.UUID{active2}, wantUnclaimed: []uuid.UUID{active}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			claimer := &fakeWeeklySummaryClaimer{users: users, locked: tt.locked}
+			var enqueued []*queue.Job
+			jobQueue := &mockJobQueue{t: t, enqueueFunc: func(ctx context.Context, job *queue.Job) error {
+				if job.UserID == tt.enqueueErrFor {
+					return errors.New("broker down")
+				}
+				enqueued = append(enqueued, job)
+				return nil
+			}}
+			scheduler := NewWeeklySummaryScheduler(claimer, jobQueue, 4*time.Hour, 30*24*time.Hour, zap.NewNop())
+			scheduler.now = func() time.Time { return now }
+
+			count, err := scheduler.Schedule(context.Background())
+			if err != nil {
+				t.Fatalf("Schedule() error = %v", err)
+			}
+			if !claimer.weekStart.Equal(weekStart) || !claimer.activeSince.Equal(now.Add(-30*24*time.Hour)) {
+				t.Errorf("claimed week %v active since %v, want %v active since 30 days ago", claimer.weekStart, claimer.activeSince, weekStart)
+			}
+			if count != len(tt.wantUsers) || len(enqueued) != len(tt.wantUsers) {
+				t.Fatalf("enqueued %d jobs (%d calls), want %d", count, len(enqueued), len(tt.wantUsers))
+			}
+			if len(claimer.unclaimed) != len(tt.wantUnclaimed) || (len(tt.wantUnclaimed) > 0 && claimer.unclaimed[0] != tt.wantUnclaimed[0]) {
+				t.Errorf("unclaimed %v, want %v", claimer.unclaimed, tt.wantUnclaimed)
+			}
+			for i, job := range enqueued {
+				if job.Type != queue.JobTypeWeeklySummary || job.UserID != tt.wantUsers[i] {
+					t.Errorf("job %d = %s for %s, want %s for %s", i, job.Type, job.UserID, queue.JobTypeWeeklySummary, tt.wantUsers[i])
+				}
+				if job.Metadata[queue.MetadataWeekStart] != "2026-10-12" {
+					t.Errorf("job %d week_start = %v, want 2026-10-12", i, job.Metadata[queue.MetadataWeekStart])
+				}
+				if job.NotAfter == nil || !job.NotAfter.Equal(weekStart.Add(models.WeeklySummaryPeriod)) {
+					t.Errorf("job %d not_after = %v, want end of week", i, job.NotAfter)
+				}
+			}
+			// Two jobs over a 4h spread go out at now and now+2h
+			if tt.enqueueErrFor == uuid.Nil {
+				for i, job := range enqueued {
+					if wantAt := now.Add(time.Duration(i) * 2 * time.Hour); job.NotBefore == nil || !job.NotBefore.Equal(wantAt) {
+						t.Errorf("job %d not_before = %v, want %v", i, job.NotBefore, wantAt)
+					}
+				}
+			}
+		})
+	}
+}
+
+// weeklySummaryProvider is an AI provider that also writes weekly summaries
+type weeklySummaryProvider struct {
+	mockAIProvider
+	summary   string
+	err       error
+	completed []string
+	pending   []string
+}
+
+func (p *weeklySummaryProvider) SummarizeWeek(ctx context.Context, completed, pending []string) (string, error) {
+	p.completed, p.pending = completed, pending
+	return p.summary, p.err
+}
+
+// fakeWeeklySummaryTodos returns fixed completed and open todos, recording the completed range asked for
+type fakeWeeklySummaryTodos struct {
+	completed []*models.Todo
+	open      []*models.Todo
+	from, to  time.Time
+}
+
+func (f *fakeWeeklySummaryTodos) ListCompletedBetween(ctx context.Context, userID uuid.UUID, from, to time.Time, limit int) ([]*models.Todo, error) {
+	f.from, f.to = from, to
+	return f.completed, nil
+}
+
+func (f *fakeWeeklySummaryTodos) ListOpenByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*models.Todo, error) {
+	return f.open, nil
+}
+
+type recordingWeeklySummaryStore struct {
+	saved []*models.WeeklySummary
+}
+
+func (s *recordingWeeklySummaryStore) Save(ctx context.Context, summary *models.WeeklySummary) error {
+	s.saved = append(s.saved, summary)
+	return nil
+}
+
+func TestWeeklySummaryWorker_ProcessWeeklySummaryJob(t *testing.T) {
+	t.Parallel()
+
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	todos := func() *fakeWeeklySummaryTodos {
+		return &fakeWeeklySummaryTodos{
+			completed: []*models.Todo{{Text: "ship release"}, {Text: "file taxes"}},
+			open:      []*models.Todo{{Text: "plan offsite"}},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		provider    *weeklySummaryProvider
+		preferences map[string]any
+		contextErr  error
+		wantSummary string
+		wantErr     bool
+		wantSkipped bool
+	}{
+		{name: "AI summary", provider: &weeklySummaryProvider{summary: "Great week!"}, wantSummary: "Great week!"},
+		{name: "provider without weekly summaries falls back", wantSummary: "You completed 2 todos last week and have 1 still open."},
+		{name: "opted out", provider: &weeklySummaryProvider{summary: "unused"}, preferences: map[string]any{models.PreferenceWeeklySummary: false}, wantSkipped: true},
+		{name: "no AI context keeps the defaults", provider: &weeklySummaryProvider{summary: "Great week!"}, contextErr: sql.ErrNoRows, wantSummary: "Great week!"},
+		{name: "AI context error is retried", provider: &weeklySummaryProvider{summary: "unused"}, contextErr: errors.New("db down"), wantErr: true},
+		{name: "AI error is retried", provider: &weeklySummaryProvider{err: errors.New("rate limited")}, wantErr: true},
+		{
+			name:        "rejected AI request falls back",
+			provider:    &weeklySummaryProvider{err: &ai.ProviderError{Kind: ai.AIErrorInvalidRequest, Err: errors.New("bad model")}},
+			wantSummary: "You completed 2 todos last week and have 1 still open.",
+		},
+		{
+			name:        "AI quota exhausted falls back",
+			provider:    &weeklySummaryProvider{err: &ai.ProviderError{Kind: ai.AIErrorQuota, Err: errors.New("quota")}},
+			wantSummary: "You completed 2 todos last week and have 1 still open.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			userID := uuid.New()
+			lister := todos()
+			store := &recordingWeeklySummaryStore{}
+			notifier := &recordingNotifier{}
+			resolver := &stubProviderResolver{fallback: &mockAIProvider{t: t}}
+			if tt.provider != nil {
+				resolver.fallback = tt.provider
+			}
+			contextRepo := &mockAIContextRepo{t: t, getByUserIDFunc: func(ctx context.Context, id uuid.UUID) (*models.AIContext, error) {
+				if tt.contextErr != nil {
+					return nil, tt.contextErr
+				}
+				return &models.AIContext{UserID: id, Preferences: tt.preferences}, nil
+			}}
+			worker := NewWeeklySummaryWorker(lister, contextRepo, store, &mockJobQueue{t: t}, resolver, notifier, zap.NewNop())
+
+			job := queue.NewWeeklySummaryJob(userID, weekStart, weekStart, weekStart.Add(models.WeeklySummaryPeriod))
+			err := worker.ProcessWeeklySummaryJob(context.Background(), job)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessWeeklySummaryJob() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr || tt.wantSkipped {
+				if len(store.saved) != 0 || len(notifier.sent) != 0 {
+					t.Errorf("saved %d summaries and sent %d notifications, want none", len(store.saved), len(notifier.sent))
+				}
+				return
+			}
+			if !lister.from.Equal(weekStart.Add(-models.WeeklySummaryPeriod)) || !lister.to.Equal(weekStart) {
+				t.Errorf("completed range = [%v, %v), want the week before %v", lister.from, lister.to, weekStart)
+			}
+			if len(store.saved) != 1 {
+				t.Fatalf("saved %d summaries, want 1", len(store.saved))
+			}
+			saved := store.saved[0]
+			if saved.Summary != tt.wantSummary || saved.CompletedCount != 2 || saved.PendingCount != 1 || !saved.WeekStart.Equal(weekStart) {
+				t.Errorf("saved = %+v, want %q with 2 completed and 1 pending for %v", saved, tt.wantSummary, weekStart)
+			}
+			if len(notifier.sent) != 1 || notifier.sent[0].Kind != WeeklySummaryNotificationKind || notifier.sent[0].Text != tt.wantSummary {
+				t.Errorf("notifications = %+v, want one weekly summary", notifier.sent)
+			}
+			if tt.provider != nil && (len(tt.provider.completed) != 2 || len(tt.provider.pending) != 1 || tt.provider.completed[0] != "ship release") {
+				t.Errorf("provider got completed %v and pending %v", tt.provider.completed, tt.provider.pending)
+			}
+		})
+	}
+}
+
+func TestWeeklySummaryWorker_ProcessJob_Retry(t *testing.T) {
+	t.Parallel()
+
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		err         error
+		usedUp      bool
+		wantRetried bool
+	}{
+		{name: "rate limit is re-enqueued with a delay", err: &ai.ProviderError{Kind: ai.AIErrorRateLimit, Err: errors.New("slow down")}, wantRetried: true},
+		{name: "server error is re-enqueued with a delay", err: &ai.ProviderError{Kind: ai.AIErrorServer, Err: errors.New("overloaded")}, wantRetried: true},
+		{name: "retries used up", err: &ai.ProviderError{Kind: ai.AIErrorRateLimit, Err: errors.New("slow down")}, usedUp: true},
+		{name: "unknown error is not retried", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			resolver := &stubProviderResolver{fallback: &weeklySummaryProvider{err: tt.err}}
+			contextRepo := &mockAIContextRepo{t: t, getByUserIDFunc: func(ctx context.Context, id uuid.UUID) (*models.AIContext, error) {
+				return &models.AIContext{UserID: id}, nil
+			}}
+			jobQueue := &mockJobQueue{t: t}
+			worker := NewWeeklySummaryWorker(&fakeWeeklySummaryTodos{completed: []*models.Todo{{Text: "ship release"}}}, contextRepo, &recordingWeeklySummaryStore{}, jobQueue, resolver, &recordingNotifier{}, zap.NewNop())
+			now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+			worker.now = func() time.Time { return now }
+
+			job := queue.NewWeeklySummaryJob(uuid.New(), weekStart, weekStart, time.Now().Add(time.Hour))
+			if tt.usedUp {
+				job.RetryCount = job.MaxRetries
+			}
+			var acked, nacked bool
+			msg := &mockMessage{
+				job:      job,
+				ackFunc:  func() error { acked = true; return nil },
+				nackFunc: func(requeue bool) error { nacked = true; return nil },
+			}
+			err := worker.ProcessJob(context.Background(), msg)
+
+			if tt.wantRetried {
+				if err != nil || !acked || nacked || len(jobQueue.enqueueCalls) != 1 {
+					t.Fatalf("ProcessJob() = %v, acked %v, nacked %v, %d enqueued; want the job acked and re-enqueued", err, acked, nacked, len(jobQueue.enqueueCalls))
+				}
+				retried := jobQueue.enqueueCalls[0]
+				if retried.ID != job.ID || retried.RetryCount != job.RetryCount+1 || retried.NotBefore == nil || !retried.NotBefore.After(now) {
+					t.Errorf("re-enqueued job = %+v, want the same job delayed past now with one more retry", retried)
+				}
+				return
+			}
+			if err == nil || acked || !nacked || len(jobQueue.enqueueCalls) != 0 {
+				t.Errorf("ProcessJob() = %v, acked %v, nacked %v, %d enqueued; want the job nacked", err, acked, nacked, len(jobQueue.enqueueCalls))
+			}
+		})
+	}
+}
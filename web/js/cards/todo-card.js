@@ -138,10 +138,10 @@ export function buildTodoCard(todo, handlers) {
             });
             metadataDiv.appendChild(tagsDiv);
         }
-        if (todo.metadata.priority) {
+        if (todo.priority) {
             const prioritySpan = document.createElement('span');
-            prioritySpan.className = `priority priority-${todo.metadata.priority.toLowerCase()}`;
-            prioritySpan.textContent = `Priority: ${todo.metadata.priority}`;
+            prioritySpan.className = `priority priority-${todo.priority}`;
+            prioritySpan.textContent = `Priority: ${todo.priority}`;
             metadataDiv.appendChild(prioritySpan);
         }
     }